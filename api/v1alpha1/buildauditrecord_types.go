@@ -0,0 +1,82 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BuildAuditRecordFinalizer prevents deletion of audit records until an administrator removes it.
+// The controller removes it only when the namespace of the record is being deleted.
+const BuildAuditRecordFinalizer = "build.appstudio.redhat.com/audit-record"
+
+// BuildAuditRecordSpec describes a submitted build. It is written once on the build submission.
+type BuildAuditRecordSpec struct {
+	// ComponentName is the name of the built Component
+	ComponentName string `json:"componentName"`
+
+	// ComponentNamespace is the namespace of the built Component
+	ComponentNamespace string `json:"componentNamespace"`
+
+	// PipelineRunName is the name of the submitted build PipelineRun
+	PipelineRunName string `json:"pipelineRunName"`
+
+	// Submitter is the user name of the build submitter, e.g. the controller service account
+	Submitter string `json:"submitter"`
+
+	// TriggerReason describes why the build has been submitted
+	TriggerReason string `json:"triggerReason"`
+
+	// GitURL is the git repository of the built source
+	// +optional
+	GitURL string `json:"gitURL,omitempty"`
+
+	// Revision is the git revision of the built source, empty means the default branch
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// SubmissionTime is the time the build PipelineRun has been created
+	SubmissionTime metav1.Time `json:"submissionTime"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Namespaced
+//+kubebuilder:printcolumn:name="Component",type=string,JSONPath=`.spec.componentName`
+//+kubebuilder:printcolumn:name="PipelineRun",type=string,JSONPath=`.spec.pipelineRunName`
+//+kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.spec.triggerReason`
+//+kubebuilder:printcolumn:name="Submitted",type=date,JSONPath=`.spec.submissionTime`
+
+// BuildAuditRecord is an append-only record of a build submission
+type BuildAuditRecord struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec BuildAuditRecordSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// BuildAuditRecordList contains a list of BuildAuditRecord
+type BuildAuditRecordList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BuildAuditRecord `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BuildAuditRecord{}, &BuildAuditRecordList{})
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BuildServiceConfigName is the name of the BuildServiceConfig which applies to its namespace, others are ignored
+const BuildServiceConfigName = "build-service"
+
+// BuildServiceConfigSpec overrides the build service defaults for Components in the namespace
+type BuildServiceConfigSpec struct {
+	// DefaultPipelineName is the name of the build pipeline used instead of the one selected for the Component
+	// +optional
+	DefaultPipelineName string `json:"defaultPipelineName,omitempty"`
+
+	// BuildTimeout is the timeout of the build PipelineRuns.
+	// The timeouts set on the Component take precedence.
+	// +optional
+	BuildTimeout *metav1.Duration `json:"buildTimeout,omitempty"`
+
+	// MaxConcurrentBuilds limits the number of pending and running builds in the namespace, 0 means no limit.
+	// The cluster-wide limit of the build service still applies.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxConcurrentBuilds int32 `json:"maxConcurrentBuilds,omitempty"`
+
+	// ResourceClass is the class of resources of the builds, e.g. small or large.
+	// It is set as a label on the build PipelineRuns.
+	// +kubebuilder:validation:Pattern=`^(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	ResourceClass string `json:"resourceClass,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Namespaced
+//+kubebuilder:printcolumn:name="Pipeline",type=string,JSONPath=`.spec.defaultPipelineName`
+//+kubebuilder:printcolumn:name="Timeout",type=string,JSONPath=`.spec.buildTimeout`
+//+kubebuilder:printcolumn:name="Max Builds",type=integer,JSONPath=`.spec.maxConcurrentBuilds`
+//+kubebuilder:printcolumn:name="Resource Class",type=string,JSONPath=`.spec.resourceClass`
+
+// BuildServiceConfig configures builds of Components in its namespace
+type BuildServiceConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec BuildServiceConfigSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// BuildServiceConfigList contains a list of BuildServiceConfig
+type BuildServiceConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BuildServiceConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BuildServiceConfig{}, &BuildServiceConfigList{})
+}
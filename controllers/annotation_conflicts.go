@@ -0,0 +1,89 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// AnnotationConflictConditionType is set on components with build annotations that request incompatible options
+	AnnotationConflictConditionType = "AnnotationConflict"
+
+	AnnotationConflictReasonResolved   = "ConflictResolved"
+	AnnotationConflictReasonNoConflict = "NoConflict"
+)
+
+// getAnnotationConflicts returns descriptions of conflicting build annotations of the component and how they are resolved.
+// The precedence is:
+//   - image tag format annotation wins over the tag of output-image set in pipeline parameters annotation,
+//     the image repository is taken from the pipeline parameter
+//   - blocking of vulnerable deployment is ignored if the image is not scanned on build
+func getAnnotationConflicts(component appstudiov1alpha1.Component) []string {
+	var conflicts []string
+
+	if component.Annotations[ImageTagFormatAnnotationName] != "" {
+		paramTemplates := map[string]string{}
+		// Invalid pipeline parameters are reported when the build is submitted
+		if err := json.Unmarshal([]byte(component.Annotations[PipelineParamsAnnotationName]), &paramTemplates); err == nil {
+			if _, setsOutputImage := paramTemplates["output-image"]; setsOutputImage {
+				conflicts = append(conflicts, fmt.Sprintf("%s overrides the tag of output-image parameter from %s",
+					ImageTagFormatAnnotationName, PipelineParamsAnnotationName))
+			}
+		}
+	}
+
+	if component.Annotations[BlockVulnerableDeploymentAnnotationName] == "true" && component.Annotations[ScanOnBuildAnnotationName] != "true" {
+		conflicts = append(conflicts, fmt.Sprintf("%s is ignored because %s is not enabled",
+			BlockVulnerableDeploymentAnnotationName, ScanOnBuildAnnotationName))
+	}
+
+	return conflicts
+}
+
+// updateAnnotationConflictCondition informs about conflicting build annotations of the component.
+// The condition is set to False once the conflicts are fixed.
+func updateAnnotationConflictCondition(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) error {
+	conflicts := getAnnotationConflicts(component)
+	if len(conflicts) == 0 {
+		if !meta.IsStatusConditionTrue(component.Status.Conditions, AnnotationConflictConditionType) {
+			return nil
+		}
+		return setComponentCondition(ctx, cli, component, metav1.Condition{
+			Type:    AnnotationConflictConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  AnnotationConflictReasonNoConflict,
+			Message: "Build annotations don't conflict",
+		})
+	}
+
+	return setComponentCondition(ctx, cli, component, metav1.Condition{
+		Type:    AnnotationConflictConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  AnnotationConflictReasonResolved,
+		Message: strings.Join(conflicts, "; "),
+	})
+}
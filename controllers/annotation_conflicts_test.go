@@ -0,0 +1,86 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetAnnotationConflicts(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        []string
+	}{
+		{
+			name: "no conflicts",
+			annotations: map[string]string{
+				ImageTagFormatAnnotationName: "{{.Component}}-{{.BuildNumber}}",
+				PipelineParamsAnnotationName: `{"cache": "true"}`,
+				ScanOnBuildAnnotationName:    "true",
+			},
+			want: nil,
+		},
+		{
+			name: "image tag format and output-image parameter",
+			annotations: map[string]string{
+				ImageTagFormatAnnotationName: "{{.Component}}-{{.BuildNumber}}",
+				PipelineParamsAnnotationName: `{"output-image": "quay.io/org/app:latest"}`,
+			},
+			want: []string{ImageTagFormatAnnotationName + " overrides the tag"},
+		},
+		{
+			name: "output-image parameter without image tag format",
+			annotations: map[string]string{
+				PipelineParamsAnnotationName: `{"output-image": "quay.io/org/app:latest"}`,
+			},
+			want: nil,
+		},
+		{
+			name: "blocking of vulnerable deployment without scan",
+			annotations: map[string]string{
+				BlockVulnerableDeploymentAnnotationName: "true",
+			},
+			want: []string{BlockVulnerableDeploymentAnnotationName + " is ignored"},
+		},
+		{
+			name: "both conflicts",
+			annotations: map[string]string{
+				ImageTagFormatAnnotationName:            "{{.BuildNumber}}",
+				PipelineParamsAnnotationName:            `{"output-image": "quay.io/org/app"}`,
+				BlockVulnerableDeploymentAnnotationName: "true",
+				ScanOnBuildAnnotationName:               "false",
+			},
+			want: []string{ImageTagFormatAnnotationName + " overrides the tag", BlockVulnerableDeploymentAnnotationName + " is ignored"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getAnnotationConflicts(getGitSourceComponent(tt.annotations, "version: 2.2.0"))
+			if len(got) != len(tt.want) {
+				t.Errorf("getAnnotationConflicts() = %v, want %v", got, tt.want)
+				return
+			}
+			for i := range got {
+				if !strings.HasPrefix(got[i], tt.want[i]) {
+					t.Errorf("getAnnotationConflicts()[%d] = %v, want prefix %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
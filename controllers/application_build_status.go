@@ -0,0 +1,233 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// Suffix of the name of the ConfigMap that holds build status of all components of an application
+	ApplicationBuildStatusConfigMapSuffix = "-build-status"
+	// Data key within the application build status ConfigMap that holds the status JSON
+	ApplicationBuildStatusConfigMapKey = "build-status.json"
+)
+
+// ApplicationBuildStatus counts components of an application by the state of their latest build.
+// It is stored as JSON in the application build status ConfigMap, so the format must be kept backward compatible.
+type ApplicationBuildStatus struct {
+	Application string `json:"application"`
+	Components  int    `json:"components"`
+	Succeeded   int    `json:"succeeded"`
+	Failed      int    `json:"failed"`
+	// Building counts components whose latest build is pending or running
+	Building int `json:"building"`
+	// NotBuilt counts components which have not been built yet
+	NotBuilt int `json:"notBuilt"`
+}
+
+// ApplicationBuildStatusReconciler maintains the build status ConfigMap of each application.
+// The application status is owned by the application service, so the aggregated build status is kept separately.
+type ApplicationBuildStatusReconciler struct {
+	Client client.Client
+	Scheme *runtime.Scheme
+	Log    logr.Logger
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// Events of all components and builds of an application are coalesced into a single reconcile of the application.
+func (r *ApplicationBuildStatusReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("applicationbuildstatus").
+		For(&appstudiov1alpha1.Application{}, builder.WithPredicates(predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				return true
+			},
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				return false
+			},
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				return false
+			},
+			GenericFunc: func(e event.GenericEvent) bool {
+				return false
+			},
+		})).
+		Watches(
+			&source.Kind{Type: &appstudiov1alpha1.Component{}},
+			handler.EnqueueRequestsFromMapFunc(getComponentApplication),
+			builder.WithPredicates(predicate.Funcs{
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					// Only moves of components between applications change the counts
+					return getComponentApplicationName(e.ObjectOld) != getComponentApplicationName(e.ObjectNew)
+				},
+			})).
+		Watches(
+			&source.Kind{Type: &tektonapi.PipelineRun{}},
+			handler.EnqueueRequestsFromMapFunc(r.getBuildApplication),
+			builder.WithPredicates(predicate.Funcs{
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					return isBuildStateChanged(e.ObjectOld, e.ObjectNew)
+				},
+			}, predicate.NewPredicateFuncs(func(object client.Object) bool {
+				_, isComponentBuild := object.GetLabels()[ComponentNameLabelName]
+				return isComponentBuild
+			}))).
+		Complete(r)
+}
+
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applications,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch
+
+// Reconcile writes the counts of the application components by their build state into the application build status ConfigMap.
+// The ConfigMap is owned by the application, so it is deleted together with the application.
+func (r *ApplicationBuildStatusReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("Application", req.NamespacedName)
+
+	application := &appstudiov1alpha1.Application{}
+	if err := r.Client.Get(ctx, req.NamespacedName, application); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	components := &appstudiov1alpha1.ComponentList{}
+	if err := r.Client.List(ctx, components, client.InNamespace(application.Namespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+	var latestBuilds []*tektonapi.PipelineRun
+	for _, component := range components.Items {
+		if component.Spec.Application != application.Name {
+			continue
+		}
+		latestPipelineRun, err := getLatestComponentPipelineRun(ctx, r.Client, component)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		latestBuilds = append(latestBuilds, latestPipelineRun)
+	}
+
+	statusJSON, err := json.Marshal(getApplicationBuildStatus(application.Name, latestBuilds))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      application.Name + ApplicationBuildStatusConfigMapSuffix,
+			Namespace: application.Namespace,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		configMap.Data = map[string]string{
+			ApplicationBuildStatusConfigMapKey: string(statusJSON),
+		}
+		return controllerutil.SetOwnerReference(application, configMap, r.Scheme)
+	}); err != nil {
+		log.Error(err, fmt.Sprintf("Failed to update build status of application %v", req.NamespacedName))
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// getApplicationBuildStatus counts the latest builds of the application components by their state,
+// nil build means the component has not been built yet.
+func getApplicationBuildStatus(applicationName string, latestBuilds []*tektonapi.PipelineRun) ApplicationBuildStatus {
+	status := ApplicationBuildStatus{
+		Application: applicationName,
+		Components:  len(latestBuilds),
+	}
+	for _, pipelineRun := range latestBuilds {
+		if pipelineRun == nil {
+			status.NotBuilt++
+			continue
+		}
+		switch getBuildState(*pipelineRun) {
+		case BuildStateSucceeded:
+			status.Succeeded++
+		case BuildStateFailed:
+			status.Failed++
+		default:
+			status.Building++
+		}
+	}
+	return status
+}
+
+// isBuildStateChanged returns true if the update of the PipelineRun changed its build state.
+// Other updates, e.g. of TaskRun statuses, are frequent and do not change the counts.
+func isBuildStateChanged(oldObject client.Object, newObject client.Object) bool {
+	oldPipelineRun, isOldPipelineRun := oldObject.(*tektonapi.PipelineRun)
+	newPipelineRun, isNewPipelineRun := newObject.(*tektonapi.PipelineRun)
+	if !isOldPipelineRun || !isNewPipelineRun {
+		return false
+	}
+	return getBuildState(*oldPipelineRun) != getBuildState(*newPipelineRun)
+}
+
+// getComponentApplication returns the application of the component.
+func getComponentApplication(object client.Object) []reconcile.Request {
+	applicationName := getComponentApplicationName(object)
+	if applicationName == "" {
+		return nil
+	}
+	return []reconcile.Request{{
+		NamespacedName: types.NamespacedName{Name: applicationName, Namespace: object.GetNamespace()},
+	}}
+}
+
+func getComponentApplicationName(object client.Object) string {
+	if component, ok := object.(*appstudiov1alpha1.Component); ok {
+		return component.Spec.Application
+	}
+	return ""
+}
+
+// getBuildApplication returns the application of the component built by the PipelineRun.
+func (r *ApplicationBuildStatusReconciler) getBuildApplication(object client.Object) []reconcile.Request {
+	component := &appstudiov1alpha1.Component{}
+	componentKey := types.NamespacedName{Name: object.GetLabels()[ComponentNameLabelName], Namespace: object.GetNamespace()}
+	if err := r.Client.Get(context.Background(), componentKey, component); err != nil {
+		if !errors.IsNotFound(err) {
+			r.Log.Error(err, fmt.Sprintf("Failed to get component %v", componentKey))
+		}
+		return nil
+	}
+	return getComponentApplication(component)
+}
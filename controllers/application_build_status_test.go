@@ -0,0 +1,84 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetApplicationBuildStatus(t *testing.T) {
+	startTime := metav1.Now()
+	succeededPipelineRun := getPipelineRunWithSucceededCondition(corev1.ConditionTrue)
+	failedPipelineRun := getPipelineRunWithSucceededCondition(corev1.ConditionFalse)
+	runningPipelineRun := getPipelineRunWithSucceededCondition(corev1.ConditionUnknown)
+	runningPipelineRun.Status.StartTime = &startTime
+	pendingPipelineRun := tektonapi.PipelineRun{}
+
+	tests := []struct {
+		name         string
+		latestBuilds []*tektonapi.PipelineRun
+		want         ApplicationBuildStatus
+	}{
+		{
+			name:         "no components",
+			latestBuilds: nil,
+			want:         ApplicationBuildStatus{Application: "my-application"},
+		},
+		{
+			name:         "components in various build states",
+			latestBuilds: []*tektonapi.PipelineRun{&succeededPipelineRun, &failedPipelineRun, &runningPipelineRun, &pendingPipelineRun, nil, &succeededPipelineRun},
+			want: ApplicationBuildStatus{
+				Application: "my-application",
+				Components:  6,
+				Succeeded:   2,
+				Failed:      1,
+				Building:    2,
+				NotBuilt:    1,
+			},
+		},
+		{
+			name:         "components not built yet",
+			latestBuilds: []*tektonapi.PipelineRun{nil, nil},
+			want:         ApplicationBuildStatus{Application: "my-application", Components: 2, NotBuilt: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getApplicationBuildStatus("my-application", tt.latestBuilds); got != tt.want {
+				t.Errorf("getApplicationBuildStatus() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsBuildStateChanged(t *testing.T) {
+	runningPipelineRun := getPipelineRunWithSucceededCondition(corev1.ConditionUnknown)
+	succeededPipelineRun := getPipelineRunWithSucceededCondition(corev1.ConditionTrue)
+	updatedRunningPipelineRun := runningPipelineRun.DeepCopy()
+	updatedRunningPipelineRun.Status.TaskRuns = map[string]*tektonapi.PipelineRunTaskRunStatus{"taskrun": {PipelineTaskName: "clone"}}
+
+	if !isBuildStateChanged(&runningPipelineRun, &succeededPipelineRun) {
+		t.Errorf("isBuildStateChanged() = false for completed build")
+	}
+	if isBuildStateChanged(&runningPipelineRun, updatedRunningPipelineRun) {
+		t.Errorf("isBuildStateChanged() = true for TaskRun status update")
+	}
+}
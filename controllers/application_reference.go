@@ -0,0 +1,136 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+// UnknownApplicationPolicy defines how builds of components which reference a nonexistent Application are handled.
+type UnknownApplicationPolicy string
+
+const (
+	// UnknownApplicationPolicyIgnore does not check the Application of the component
+	UnknownApplicationPolicyIgnore UnknownApplicationPolicy = "Ignore"
+	// UnknownApplicationPolicyProceed sets the UnknownApplication condition, but builds the component
+	UnknownApplicationPolicyProceed UnknownApplicationPolicy = "Proceed"
+	// UnknownApplicationPolicyBlock sets the UnknownApplication condition and builds the component once the Application is created
+	UnknownApplicationPolicyBlock UnknownApplicationPolicy = "Block"
+)
+
+const (
+	// UnknownApplicationConditionType is set on components whose Application does not exist
+	UnknownApplicationConditionType = "UnknownApplication"
+
+	UnknownApplicationReasonNotFound = "ApplicationNotFound"
+	UnknownApplicationReasonFound    = "ApplicationFound"
+)
+
+// ParseUnknownApplicationPolicy returns the unknown Application policy with the given name.
+// Empty name means the default Ignore policy.
+func ParseUnknownApplicationPolicy(name string) (UnknownApplicationPolicy, error) {
+	switch policy := UnknownApplicationPolicy(name); policy {
+	case "":
+		return UnknownApplicationPolicyIgnore, nil
+	case UnknownApplicationPolicyIgnore, UnknownApplicationPolicyProceed, UnknownApplicationPolicyBlock:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown Application policy %q, expected one of %s, %s, %s", name,
+			UnknownApplicationPolicyIgnore, UnknownApplicationPolicyProceed, UnknownApplicationPolicyBlock)
+	}
+}
+
+func (p UnknownApplicationPolicy) checksApplication() bool {
+	return p == UnknownApplicationPolicyProceed || p == UnknownApplicationPolicyBlock
+}
+
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=applications,verbs=get;list;watch
+
+// isApplicationMissing checks whether the component references an Application which does not exist.
+// Components without Application are not checked.
+func (r *ComponentBuildReconciler) isApplicationMissing(ctx context.Context, component appstudiov1alpha1.Component) (bool, error) {
+	if component.Spec.Application == "" {
+		return false, nil
+	}
+	application := &appstudiov1alpha1.Application{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: component.Spec.Application, Namespace: component.Namespace}, application); err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	return false, nil
+}
+
+func getUnknownApplicationCondition(component appstudiov1alpha1.Component, policy UnknownApplicationPolicy) metav1.Condition {
+	message := fmt.Sprintf("Application %s does not exist", component.Spec.Application)
+	if policy == UnknownApplicationPolicyBlock {
+		message += ", the build waits for its creation"
+	} else {
+		message += ", the component is built anyway"
+	}
+	return metav1.Condition{
+		Type:    UnknownApplicationConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  UnknownApplicationReasonNotFound,
+		Message: message,
+	}
+}
+
+// clearUnknownApplicationCondition marks the Application of the component which has been missing as found.
+func clearUnknownApplicationCondition(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) error {
+	if !meta.IsStatusConditionTrue(component.Status.Conditions, UnknownApplicationConditionType) {
+		return nil
+	}
+	return setComponentCondition(ctx, cli, component, metav1.Condition{
+		Type:    UnknownApplicationConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  UnknownApplicationReasonFound,
+		Message: fmt.Sprintf("Application %s exists", component.Spec.Application),
+	})
+}
+
+// getApplicationComponents returns reconcile requests for not yet built components of the Application.
+func (r *ComponentBuildReconciler) getApplicationComponents(object client.Object) []reconcile.Request {
+	components := &appstudiov1alpha1.ComponentList{}
+	if err := r.Client.List(context.Background(), components, client.InNamespace(object.GetNamespace()),
+		client.MatchingFields{buildSpecChangedIndexKey: buildSpecChangedIndexValue}); err != nil {
+		r.Log.Error(err, fmt.Sprintf("Failed to list components in namespace %s", object.GetNamespace()))
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, component := range components.Items {
+		if component.Spec.Application != object.GetName() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: component.Name, Namespace: component.Namespace},
+		})
+	}
+	return requests
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+func TestParseUnknownApplicationPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    UnknownApplicationPolicy
+		wantErr bool
+	}{
+		{name: "", want: UnknownApplicationPolicyIgnore},
+		{name: "Ignore", want: UnknownApplicationPolicyIgnore},
+		{name: "Proceed", want: UnknownApplicationPolicyProceed},
+		{name: "Block", want: UnknownApplicationPolicyBlock},
+		{name: "block", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseUnknownApplicationPolicy(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseUnknownApplicationPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseUnknownApplicationPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// applicationClient returns the Application with the given name only
+type applicationClient struct {
+	client.Client
+	applicationName string
+}
+
+func (c *applicationClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if key.Name != c.applicationName {
+		return errors.NewNotFound(schema.GroupResource{Group: "appstudio.redhat.com", Resource: "applications"}, key.Name)
+	}
+	obj.(*appstudiov1alpha1.Application).Name = key.Name
+	return nil
+}
+
+func TestIsApplicationMissing(t *testing.T) {
+	tests := []struct {
+		name        string
+		application string
+		want        bool
+	}{
+		{
+			name:        "existing Application",
+			application: "my-application",
+			want:        false,
+		},
+		{
+			name:        "missing Application",
+			application: "deleted-application",
+			want:        true,
+		},
+		{
+			name:        "no Application",
+			application: "",
+			want:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := getGitSourceComponent(nil, "version: 2.2.0")
+			component.Spec.Application = tt.application
+			r := &ComponentBuildReconciler{Client: &applicationClient{applicationName: "my-application"}}
+
+			got, err := r.isApplicationMissing(context.TODO(), component)
+			if err != nil {
+				t.Fatalf("isApplicationMissing() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("isApplicationMissing() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetUnknownApplicationCondition(t *testing.T) {
+	component := getGitSourceComponent(nil, "version: 2.2.0")
+	component.Spec.Application = "deleted-application"
+
+	tests := []struct {
+		policy      UnknownApplicationPolicy
+		wantMessage string
+	}{
+		{
+			policy:      UnknownApplicationPolicyBlock,
+			wantMessage: "Application deleted-application does not exist, the build waits for its creation",
+		},
+		{
+			policy:      UnknownApplicationPolicyProceed,
+			wantMessage: "Application deleted-application does not exist, the component is built anyway",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			got := getUnknownApplicationCondition(component, tt.policy)
+			if got.Type != UnknownApplicationConditionType || got.Reason != UnknownApplicationReasonNotFound || got.Message != tt.wantMessage {
+				t.Errorf("getUnknownApplicationCondition() = %v, want %s condition with message %q", got, UnknownApplicationConditionType, tt.wantMessage)
+			}
+		})
+	}
+}
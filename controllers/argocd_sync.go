@@ -0,0 +1,116 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// Name of the Argo CD Application which syncs gitops resources of the component, in [namespace/]name format.
+	// The build is submitted only when the Application is synced. The component namespace is used if none is given.
+	ArgoCDAppNameAnnotationName = "build.appstudio.openshift.io/argocd-app-name"
+
+	// WaitingForGitOpsSyncConditionType is set on components whose build waits for sync of the Argo CD Application
+	WaitingForGitOpsSyncConditionType = "WaitingForGitOpsSync"
+
+	WaitingForGitOpsSyncReasonNotSynced = "ArgoCDApplicationNotSynced"
+	WaitingForGitOpsSyncReasonSynced    = "ArgoCDApplicationSynced"
+
+	argoCDApplicationSyncedStatus = "Synced"
+
+	argoCDSyncPollInterval = 15 * time.Second
+)
+
+var argoCDApplicationGVK = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Application"}
+
+//+kubebuilder:rbac:groups=argoproj.io,resources=applications,verbs=get
+
+// getPendingGitOpsSync returns the reason why the build of the component waits for its Argo CD Application,
+// or empty string if the component has no Argo CD Application set or the Application is synced.
+func (r *ComponentBuildReconciler) getPendingGitOpsSync(ctx context.Context, component appstudiov1alpha1.Component) (string, error) {
+	appName := component.Annotations[ArgoCDAppNameAnnotationName]
+	if appName == "" {
+		return "", nil
+	}
+	appKey := getArgoCDApplicationKey(component, appName)
+
+	// Argo CD Applications are read directly to avoid caching all of them in the controller
+	app := &unstructured.Unstructured{}
+	app.SetGroupVersionKind(argoCDApplicationGVK)
+	if err := r.NonCachingClient.Get(ctx, appKey, app); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Sprintf("Argo CD Application %v does not exist", appKey), nil
+		}
+		return "", err
+	}
+
+	syncStatus, _, err := unstructured.NestedString(app.Object, "status", "sync", "status")
+	if err != nil {
+		return "", err
+	}
+	if syncStatus != argoCDApplicationSyncedStatus {
+		if syncStatus == "" {
+			syncStatus = "Unknown"
+		}
+		return fmt.Sprintf("Argo CD Application %v sync status is %s", appKey, syncStatus), nil
+	}
+	return "", nil
+}
+
+// getArgoCDApplicationKey returns the key of the Argo CD Application given in [namespace/]name format.
+func getArgoCDApplicationKey(component appstudiov1alpha1.Component, appName string) types.NamespacedName {
+	if parts := strings.SplitN(appName, "/", 2); len(parts) == 2 {
+		return types.NamespacedName{Namespace: parts[0], Name: parts[1]}
+	}
+	return types.NamespacedName{Namespace: component.Namespace, Name: appName}
+}
+
+func getWaitingForGitOpsSyncCondition(message string) metav1.Condition {
+	return metav1.Condition{
+		Type:    WaitingForGitOpsSyncConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  WaitingForGitOpsSyncReasonNotSynced,
+		Message: message,
+	}
+}
+
+// clearWaitingForGitOpsSyncCondition marks the build of the component which waited for its Argo CD Application as submitted.
+func clearWaitingForGitOpsSyncCondition(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) error {
+	if !meta.IsStatusConditionTrue(component.Status.Conditions, WaitingForGitOpsSyncConditionType) {
+		return nil
+	}
+	return setComponentCondition(ctx, cli, component, metav1.Condition{
+		Type:    WaitingForGitOpsSyncConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  WaitingForGitOpsSyncReasonSynced,
+		Message: "Argo CD Application has been synced, the build has been submitted",
+	})
+}
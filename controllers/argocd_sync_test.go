@@ -0,0 +1,111 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// argoCDApplicationClient returns the given Argo CD Applications
+type argoCDApplicationClient struct {
+	client.Client
+	applications map[types.NamespacedName]map[string]interface{}
+}
+
+func (c *argoCDApplicationClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	application, exists := c.applications[key]
+	if !exists {
+		return errors.NewNotFound(schema.GroupResource{Group: "argoproj.io", Resource: "applications"}, key.Name)
+	}
+	obj.(*unstructured.Unstructured).Object = application
+	return nil
+}
+
+func getArgoCDApplication(syncStatus string) map[string]interface{} {
+	return map[string]interface{}{
+		"status": map[string]interface{}{
+			"sync": map[string]interface{}{"status": syncStatus},
+		},
+	}
+}
+
+func TestGetPendingGitOpsSync(t *testing.T) {
+	apiClient := &argoCDApplicationClient{
+		applications: map[types.NamespacedName]map[string]interface{}{
+			{Name: "synced-app", Namespace: "my-namespace"}:      getArgoCDApplication("Synced"),
+			{Name: "out-of-sync-app", Namespace: "my-namespace"}: getArgoCDApplication("OutOfSync"),
+			{Name: "new-app", Namespace: "my-namespace"}:         {},
+			{Name: "synced-app", Namespace: "argocd"}:            getArgoCDApplication("Synced"),
+		},
+	}
+	tests := []struct {
+		name        string
+		appName     string
+		wantMessage string
+	}{
+		{
+			name:        "no Argo CD Application",
+			appName:     "",
+			wantMessage: "",
+		},
+		{
+			name:        "synced Application",
+			appName:     "synced-app",
+			wantMessage: "",
+		},
+		{
+			name:        "synced Application in another namespace",
+			appName:     "argocd/synced-app",
+			wantMessage: "",
+		},
+		{
+			name:        "out of sync Application",
+			appName:     "out-of-sync-app",
+			wantMessage: "Argo CD Application my-namespace/out-of-sync-app sync status is OutOfSync",
+		},
+		{
+			name:        "Application without status",
+			appName:     "new-app",
+			wantMessage: "Argo CD Application my-namespace/new-app sync status is Unknown",
+		},
+		{
+			name:        "missing Application",
+			appName:     "argocd/missing-app",
+			wantMessage: "Argo CD Application argocd/missing-app does not exist",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := getGitSourceComponent(map[string]string{ArgoCDAppNameAnnotationName: tt.appName}, "version: 2.2.0")
+
+			got, err := (&ComponentBuildReconciler{NonCachingClient: apiClient}).getPendingGitOpsSync(context.TODO(), component)
+			if err != nil {
+				t.Fatalf("getPendingGitOpsSync() error = %v", err)
+			}
+			if got != tt.wantMessage {
+				t.Errorf("getPendingGitOpsSync() = %q, want %q", got, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// If set to "true", the build of the component is submitted only after it is approved by the build approver
+	RequireBuildApprovalAnnotationName = "build.appstudio.openshift.io/require-build-approval"
+	// URL of the webhook which approves builds of the component, overrides the build approver of the controller
+	ApprovalWebhookURLAnnotationName = "build.appstudio.openshift.io/approval-webhook-url"
+
+	// PendingApprovalConditionType is set on components whose build waits for the approval
+	PendingApprovalConditionType = "PendingApproval"
+
+	PendingApprovalReasonPending  = "ApprovalPending"
+	PendingApprovalReasonDenied   = "BuildDenied"
+	PendingApprovalReasonApproved = "BuildApproved"
+
+	buildApprovalPollInterval = 30 * time.Second
+)
+
+// Build approval states returned by build approvers
+const (
+	BuildApprovalStateApproved = "approved"
+	BuildApprovalStateDenied   = "denied"
+	BuildApprovalStatePending  = "pending"
+)
+
+// BuildApprovalRequest describes the build which waits for the approval.
+// The build spec hash identifies the state of the component the build decision was made for.
+type BuildApprovalRequest struct {
+	Namespace     string `json:"namespace"`
+	Component     string `json:"component"`
+	Application   string `json:"application"`
+	GitURL        string `json:"gitUrl,omitempty"`
+	Reason        string `json:"reason"`
+	BuildSpecHash string `json:"buildSpecHash"`
+	// Params are the string parameters of the proposed build PipelineRun, values of sensitive parameters are redacted
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// BuildApprovalResponse is the decision of the build approver.
+// Approved is an alternative to State for simple approvers, false means the build is pending.
+type BuildApprovalResponse struct {
+	State    string `json:"state"`
+	Approved *bool  `json:"approved,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// BuildApprover decides whether builds of components which require approval could be submitted.
+type BuildApprover interface {
+	Approve(ctx context.Context, request BuildApprovalRequest) (BuildApprovalResponse, error)
+}
+
+// isBuildApprovalRequired checks whether the build of the component must be approved before submission.
+func (r *ComponentBuildReconciler) isBuildApprovalRequired(component appstudiov1alpha1.Component) bool {
+	if component.Annotations[ApprovalWebhookURLAnnotationName] != "" {
+		return true
+	}
+	return r.BuildApprover != nil && component.Annotations[RequireBuildApprovalAnnotationName] == "true"
+}
+
+// getBuildApprover returns the approval webhook of the component if set, otherwise the build approver of the controller.
+// The approval webhook of the component must be on one of the hosts allowed by the operator and must not resolve
+// to an internal address.
+func (r *ComponentBuildReconciler) getBuildApprover(component appstudiov1alpha1.Component) (BuildApprover, error) {
+	webhookURL := component.Annotations[ApprovalWebhookURLAnnotationName]
+	if webhookURL == "" {
+		return r.BuildApprover, nil
+	}
+	if err := validateTenantURL(webhookURL, r.ApprovalWebhookAllowedHosts); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", ApprovalWebhookURLAnnotationName, err)
+	}
+	httpClient := r.webhookHTTPClient
+	if httpClient == nil {
+		httpClient = tenantHTTPClient
+	}
+	return &WebhookBuildApprover{URL: webhookURL, HTTPClient: httpClient}, nil
+}
+
+// getBuildApprovalPollInterval returns the interval the approval of pending builds is requested again.
+func (r *ComponentBuildReconciler) getBuildApprovalPollInterval() time.Duration {
+	if r.BuildApprovalPollInterval > 0 {
+		return r.BuildApprovalPollInterval
+	}
+	return buildApprovalPollInterval
+}
+
+// getBuildApproval asks the build approver whether the build of the component could be submitted.
+// Builds of components with an invalid approval webhook are denied until the webhook is fixed.
+func (r *ComponentBuildReconciler) getBuildApproval(ctx context.Context, component appstudiov1alpha1.Component, decision InitialBuildDecision, proposedBuild tektonapi.PipelineRun) (BuildApprovalResponse, error) {
+	approver, err := r.getBuildApprover(component)
+	if err != nil {
+		return BuildApprovalResponse{State: BuildApprovalStateDenied, Message: err.Error()}, nil
+	}
+
+	request := BuildApprovalRequest{
+		Namespace:     component.Namespace,
+		Component:     component.Name,
+		Application:   component.Spec.Application,
+		Reason:        decision.Reason,
+		BuildSpecHash: getBuildSpecHash(component),
+	}
+	if gitSource := getGitSource(component); gitSource != nil {
+		request.GitURL = gitSource.URL
+	}
+	params, err := r.getProposedBuildParams(component, proposedBuild)
+	if err != nil {
+		return BuildApprovalResponse{}, err
+	}
+	request.Params = params
+
+	response, err := approver.Approve(ctx, request)
+	if err != nil {
+		return BuildApprovalResponse{}, err
+	}
+	if response.State == "" && response.Approved != nil {
+		response.State = BuildApprovalStatePending
+		if *response.Approved {
+			response.State = BuildApprovalStateApproved
+		}
+	}
+	switch response.State {
+	case BuildApprovalStateApproved, BuildApprovalStateDenied, BuildApprovalStatePending:
+		return response, nil
+	default:
+		return BuildApprovalResponse{}, fmt.Errorf("unknown build approval state %q", response.State)
+	}
+}
+
+// getProposedBuildParams returns the string parameters of the generated build PipelineRun of the component for the approver.
+// The parameters set from the cluster and namespace configuration during the submission are not included.
+func (r *ComponentBuildReconciler) getProposedBuildParams(component appstudiov1alpha1.Component, proposedBuild tektonapi.PipelineRun) (map[string]string, error) {
+	build := *proposedBuild.DeepCopy()
+	r.applyImageName(component, &build)
+	additionalParams, err := getPipelineParamsFromAnnotation(component)
+	if err != nil {
+		return nil, err
+	}
+	mergePipelineParams(&build, additionalParams)
+
+	params := map[string]string{}
+	for _, param := range build.Spec.Params {
+		if param.Value.Type != tektonapi.ParamTypeString {
+			continue
+		}
+		if isSensitiveParam(param.Name) {
+			params[param.Name] = redactedValue
+		} else {
+			params[param.Name] = param.Value.StringVal
+		}
+	}
+	return params, nil
+}
+
+// getPendingApprovalCondition returns the condition which informs about the build approval state
+// of not approved build.
+func getPendingApprovalCondition(response BuildApprovalResponse) metav1.Condition {
+	if response.State == BuildApprovalStateDenied {
+		message := "The build has been denied"
+		if response.Message != "" {
+			message += ": " + response.Message
+		}
+		return metav1.Condition{
+			Type:    PendingApprovalConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  PendingApprovalReasonDenied,
+			Message: message,
+		}
+	}
+
+	message := "The build waits for approval"
+	if response.Message != "" {
+		message += ": " + response.Message
+	}
+	return metav1.Condition{
+		Type:    PendingApprovalConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  PendingApprovalReasonPending,
+		Message: message,
+	}
+}
+
+// clearPendingApprovalCondition marks the build of previously pending component as approved.
+func clearPendingApprovalCondition(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) error {
+	if !meta.IsStatusConditionTrue(component.Status.Conditions, PendingApprovalConditionType) {
+		return nil
+	}
+	return setComponentCondition(ctx, cli, component, metav1.Condition{
+		Type:    PendingApprovalConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  PendingApprovalReasonApproved,
+		Message: "The build has been approved and submitted",
+	})
+}
+
+// WebhookBuildApprover posts the build approval request JSON to the configured URL
+// and expects the build approval response JSON. Requests time out after 30 seconds by default.
+type WebhookBuildApprover struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+func (a *WebhookBuildApprover) Approve(ctx context.Context, request BuildApprovalRequest) (BuildApprovalResponse, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return BuildApprovalResponse{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.URL, bytes.NewReader(body))
+	if err != nil {
+		return BuildApprovalResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := a.HTTPClient
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return BuildApprovalResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return BuildApprovalResponse{}, fmt.Errorf("build approval webhook responded with status %d", resp.StatusCode)
+	}
+	response := BuildApprovalResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return BuildApprovalResponse{}, fmt.Errorf("invalid build approval webhook response: %v", err)
+	}
+	return response, nil
+}
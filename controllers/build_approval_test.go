@@ -0,0 +1,274 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/redhat-appstudio/application-service/gitops"
+	"github.com/redhat-appstudio/application-service/gitops/prepare"
+)
+
+func TestWebhookBuildApprover(t *testing.T) {
+	var received []BuildApprovalRequest
+	responseStatus := http.StatusOK
+	responseBody := `{"state": "approved"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		request := BuildApprovalRequest{}
+		if req.Method != http.MethodPost || json.NewDecoder(req.Body).Decode(&request) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, request)
+		w.WriteHeader(responseStatus)
+		_, _ = w.Write([]byte(responseBody))
+	}))
+	defer server.Close()
+
+	reconciler := &ComponentBuildReconciler{BuildApprover: &WebhookBuildApprover{URL: server.URL}}
+	component := getGitSourceComponent(map[string]string{RequireBuildApprovalAnnotationName: "true"}, "version: 2.2.0")
+	if !reconciler.isBuildApprovalRequired(component) {
+		t.Fatalf("isBuildApprovalRequired() = false, want true")
+	}
+	decision := getInitialBuildDecision(component)
+	proposedBuild := gitops.GenerateInitialBuildPipelineRun(component, prepare.GitopsConfig{})
+
+	tests := []struct {
+		name           string
+		responseStatus int
+		responseBody   string
+		wantState      string
+		wantErr        bool
+	}{
+		{
+			name:           "approved",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"state": "approved"}`,
+			wantState:      BuildApprovalStateApproved,
+		},
+		{
+			name:           "denied",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"state": "denied", "message": "release freeze"}`,
+			wantState:      BuildApprovalStateDenied,
+		},
+		{
+			name:           "pending",
+			responseStatus: http.StatusAccepted,
+			responseBody:   `{"state": "pending"}`,
+			wantState:      BuildApprovalStatePending,
+		},
+		{
+			name:           "unknown state",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"state": "maybe"}`,
+			wantErr:        true,
+		},
+		{
+			name:           "invalid response",
+			responseStatus: http.StatusOK,
+			responseBody:   `approved`,
+			wantErr:        true,
+		},
+		{
+			name:           "webhook failure",
+			responseStatus: http.StatusInternalServerError,
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = nil
+			responseStatus = tt.responseStatus
+			responseBody = tt.responseBody
+
+			got, err := reconciler.getBuildApproval(context.TODO(), component, decision, proposedBuild)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getBuildApproval() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.State != tt.wantState {
+				t.Errorf("getBuildApproval() = %v, want %v", got.State, tt.wantState)
+			}
+			if len(received) != 1 || received[0].Component != component.Name || received[0].Namespace != component.Namespace ||
+				received[0].Reason != BuildDecisionReasonRequired || received[0].BuildSpecHash != getBuildSpecHash(component) {
+				t.Errorf("getBuildApproval() sent %v, want request for %s/%s", received, component.Namespace, component.Name)
+			}
+		})
+	}
+}
+
+func TestApprovalWebhookAnnotation(t *testing.T) {
+	var received []BuildApprovalRequest
+	responseBody := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		request := BuildApprovalRequest{}
+		if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, request)
+		_, _ = w.Write([]byte(responseBody))
+	}))
+	defer server.Close()
+
+	// The component webhook is used even if the controller has no build approver.
+	// The client of the test server connects to loopback, which is refused for webhooks of components otherwise.
+	reconciler := &ComponentBuildReconciler{ApprovalWebhookAllowedHosts: []string{"127.0.0.1"}, webhookHTTPClient: server.Client()}
+	component := getGitSourceComponent(map[string]string{
+		ApprovalWebhookURLAnnotationName: server.URL,
+		PipelineParamsAnnotationName:     `{"registry-token": "s3cr3t"}`,
+	}, "")
+	if !reconciler.isBuildApprovalRequired(component) {
+		t.Fatalf("isBuildApprovalRequired() = false, want true")
+	}
+	decision := getInitialBuildDecision(component)
+	proposedBuild := gitops.GenerateInitialBuildPipelineRun(component, prepare.GitopsConfig{})
+
+	tests := []struct {
+		name         string
+		responseBody string
+		wantState    string
+	}{
+		{name: "approved", responseBody: `{"approved": true}`, wantState: BuildApprovalStateApproved},
+		{name: "not approved", responseBody: `{"approved": false, "message": "waiting for review"}`, wantState: BuildApprovalStatePending},
+		{name: "state takes precedence", responseBody: `{"state": "denied", "approved": true}`, wantState: BuildApprovalStateDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = nil
+			responseBody = tt.responseBody
+
+			got, err := reconciler.getBuildApproval(context.TODO(), component, decision, proposedBuild)
+			if err != nil {
+				t.Fatalf("getBuildApproval() error = %v", err)
+			}
+			if got.State != tt.wantState {
+				t.Errorf("getBuildApproval() = %v, want %v", got.State, tt.wantState)
+			}
+			if len(received) != 1 {
+				t.Fatalf("getBuildApproval() sent %d requests, want 1", len(received))
+			}
+			params := received[0].Params
+			if params["git-url"] != "https://github.com/foo/bar" || params["output-image"] != DefaultImageName(component) ||
+				params["registry-token"] != redactedValue {
+				t.Errorf("getBuildApproval() sent params %v, want proposed PipelineRun params", params)
+			}
+		})
+	}
+}
+
+func TestGetBuildApprover(t *testing.T) {
+	controllerApprover := &WebhookBuildApprover{URL: "https://approver.example.com"}
+	reconciler := &ComponentBuildReconciler{
+		BuildApprover:               controllerApprover,
+		ApprovalWebhookAllowedHosts: []string{"approval.corp.com", "*.approvers.com"},
+	}
+
+	tests := []struct {
+		name    string
+		url     string
+		wantURL string
+		wantErr bool
+	}{
+		{name: "controller approver", url: "", wantURL: "https://approver.example.com"},
+		{name: "component webhook", url: "https://approval.corp.com/builds", wantURL: "https://approval.corp.com/builds"},
+		{name: "unsupported scheme", url: "ftp://approval.corp.com", wantErr: true},
+		{name: "relative URL", url: "approval.corp.com/builds", wantErr: true},
+		{name: "subdomain of allowed domain", url: "https://team.approvers.com/builds", wantURL: "https://team.approvers.com/builds"},
+		{name: "host not allowed", url: "http://kubernetes.default.svc/api", wantErr: true},
+		{name: "allowed domain without subdomain", url: "https://approvers.com/builds", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := getGitSourceComponent(map[string]string{ApprovalWebhookURLAnnotationName: tt.url}, "")
+			got, err := reconciler.getBuildApprover(component)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getBuildApprover() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			webhookApprover, ok := got.(*WebhookBuildApprover)
+			if !ok || webhookApprover.URL != tt.wantURL {
+				t.Fatalf("getBuildApprover() = %v, want webhook %v", got, tt.wantURL)
+			}
+			if tt.url != "" && webhookApprover.HTTPClient != tenantHTTPClient {
+				t.Errorf("getBuildApprover() webhook of component does not use the tenant HTTP client")
+			}
+		})
+	}
+}
+
+func TestApprovalWebhookNotAllowed(t *testing.T) {
+	reconciler := &ComponentBuildReconciler{ApprovalWebhookAllowedHosts: []string{"approval.corp.com"}}
+	component := getGitSourceComponent(map[string]string{ApprovalWebhookURLAnnotationName: "http://169.254.169.254/latest"}, "")
+
+	got, err := reconciler.getBuildApproval(context.TODO(), component, getInitialBuildDecision(component), gitops.GenerateInitialBuildPipelineRun(component, prepare.GitopsConfig{}))
+	if err != nil {
+		t.Fatalf("getBuildApproval() error = %v", err)
+	}
+	if got.State != BuildApprovalStateDenied || got.Message == "" {
+		t.Errorf("getBuildApproval() = %v, want denied build with the reason", got)
+	}
+}
+
+func TestIsBuildApprovalRequired(t *testing.T) {
+	component := getGitSourceComponent(map[string]string{RequireBuildApprovalAnnotationName: "true"}, "")
+	if (&ComponentBuildReconciler{}).isBuildApprovalRequired(component) {
+		t.Errorf("isBuildApprovalRequired() = true without approver, want false")
+	}
+	reconciler := &ComponentBuildReconciler{BuildApprover: &WebhookBuildApprover{}}
+	if reconciler.isBuildApprovalRequired(getGitSourceComponent(nil, "")) {
+		t.Errorf("isBuildApprovalRequired() = true without annotation, want false")
+	}
+}
+
+func TestGetPendingApprovalCondition(t *testing.T) {
+	tests := []struct {
+		name       string
+		response   BuildApprovalResponse
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{
+			name:       "pending",
+			response:   BuildApprovalResponse{State: BuildApprovalStatePending},
+			wantStatus: metav1.ConditionTrue,
+			wantReason: PendingApprovalReasonPending,
+		},
+		{
+			name:       "denied",
+			response:   BuildApprovalResponse{State: BuildApprovalStateDenied, Message: "release freeze"},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: PendingApprovalReasonDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getPendingApprovalCondition(tt.response)
+			if got.Status != tt.wantStatus || got.Reason != tt.wantReason {
+				t.Errorf("getPendingApprovalCondition() = %v/%v, want %v/%v", got.Status, got.Reason, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	buildv1alpha1 "github.com/redhat-appstudio/build-service/api/v1alpha1"
+)
+
+// Reason of the build, set on the build PipelineRuns by the controller.
+// Controller paths which resubmit the build set it on the component too, so the next build tells why it has been submitted.
+const BuildTriggerAnnotationName = "build.appstudio.openshift.io/build-trigger"
+
+// Build audit trigger reasons
+const (
+	BuildTriggerReasonInitialBuild      = "InitialBuild"
+	BuildTriggerReasonSpecChange        = "SpecChange"
+	BuildTriggerReasonManualBuild       = "ManualBuild"
+	BuildTriggerReasonDisruptionRetry   = "DisruptionRetry"
+	BuildTriggerReasonDeletedBuild      = "DeletedBuildResubmission"
+	BuildTriggerReasonGitSecretRotation = "GitSecretRotation"
+)
+
+// Submitter of the audited builds if the controller identity is unknown
+const defaultBuildSubmitter = "build-service"
+
+// Event reason of builds submitted without audit record
+const BuildAuditRecordFailedEventReason = "BuildAuditRecordFailed"
+
+// Audit records are updated only to remove the finalizer from records of deleted namespaces
+//+kubebuilder:rbac:groups=build.appstudio.redhat.com,resources=buildauditrecords,verbs=get;list;watch;create;update
+
+// recordBuildSubmission creates the audit record of the build PipelineRun just created by the controller.
+// Records are created only here, so PipelineRuns created by anybody else are never recorded.
+// The record has the same name as the PipelineRun and is not owned by the component, so it outlives the component.
+// Failed creation is retried with a short backoff, as the record cannot be created later.
+func (r *ComponentBuildReconciler) recordBuildSubmission(ctx context.Context, component appstudiov1alpha1.Component, pipelineRun tektonapi.PipelineRun) error {
+	auditRecord := getBuildAuditRecord(component, pipelineRun, r.BuildSubmitter)
+	return retry.OnError(retry.DefaultBackoff, isRetriableAuditRecordError, func() error {
+		if err := r.Client.Create(ctx, auditRecord.DeepCopy()); err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+		return nil
+	})
+}
+
+func isRetriableAuditRecordError(err error) bool {
+	return !errors.IsInvalid(err) && !errors.IsForbidden(err) && !errors.IsNotFound(err)
+}
+
+func getBuildAuditRecord(component appstudiov1alpha1.Component, pipelineRun tektonapi.PipelineRun, submitter string) buildv1alpha1.BuildAuditRecord {
+	if submitter == "" {
+		submitter = defaultBuildSubmitter
+	}
+	submissionTime := pipelineRun.CreationTimestamp
+	if submissionTime.IsZero() {
+		submissionTime = metav1.Now()
+	}
+
+	return buildv1alpha1.BuildAuditRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       pipelineRun.Name,
+			Namespace:  component.Namespace,
+			Labels:     map[string]string{ComponentNameLabelName: component.Name},
+			Finalizers: []string{buildv1alpha1.BuildAuditRecordFinalizer},
+		},
+		Spec: buildv1alpha1.BuildAuditRecordSpec{
+			ComponentName:      component.Name,
+			ComponentNamespace: component.Namespace,
+			PipelineRunName:    pipelineRun.Name,
+			Submitter:          submitter,
+			TriggerReason:      pipelineRun.Annotations[BuildTriggerAnnotationName],
+			GitURL:             getPipelineRunParam(pipelineRun, "git-url"),
+			Revision:           getPipelineRunParam(pipelineRun, "revision"),
+			SubmissionTime:     submissionTime,
+		},
+	}
+}
+
+// getBuildTriggerReason returns the reason of the build about to be submitted for the component.
+// It has to be called before the initial build annotation of the component is set for the build.
+func getBuildTriggerReason(component appstudiov1alpha1.Component) string {
+	if reason := component.Annotations[BuildTriggerAnnotationName]; reason != "" {
+		// Requested by the controller
+		return reason
+	}
+	value, built := component.Annotations[InitialBuildAnnotationName]
+	if !built {
+		return BuildTriggerReasonInitialBuild
+	}
+	if value != "true" {
+		// The annotation has been reset to request a new build
+		return BuildTriggerReasonManualBuild
+	}
+	return BuildTriggerReasonSpecChange
+}
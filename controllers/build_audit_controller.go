@@ -0,0 +1,111 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	buildv1alpha1 "github.com/redhat-appstudio/build-service/api/v1alpha1"
+)
+
+// BuildAuditRecordReconciler removes the finalizer of build audit records in namespaces being deleted.
+// The finalizer protects single records, but it would block the deletion of their namespace forever.
+// Records deleted while their namespace exists keep the finalizer until an administrator removes it.
+type BuildAuditRecordReconciler struct {
+	Client client.Client
+	Log    logr.Logger
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BuildAuditRecordReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	isDeleted := predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return !object.GetDeletionTimestamp().IsZero()
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("buildauditrecord").
+		For(&buildv1alpha1.BuildAuditRecord{}, builder.WithPredicates(isDeleted)).
+		// Records deleted before their namespace are released once the namespace deletion starts
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, handler.EnqueueRequestsFromMapFunc(r.getNamespaceAuditRecords),
+			builder.WithPredicates(isDeleted)).
+		Complete(r)
+}
+
+// Reconcile removes the finalizer of the deleted build audit record if its namespace is being deleted.
+func (r *BuildAuditRecordReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("BuildAuditRecord", req.NamespacedName)
+
+	auditRecord := &buildv1alpha1.BuildAuditRecord{}
+	if err := r.Client.Get(ctx, req.NamespacedName, auditRecord); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if auditRecord.DeletionTimestamp.IsZero() || !controllerutil.ContainsFinalizer(auditRecord, buildv1alpha1.BuildAuditRecordFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: auditRecord.Namespace}, namespace); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if namespace.DeletionTimestamp.IsZero() {
+		// Only an administrator can delete a single record
+		return ctrl.Result{}, nil
+	}
+
+	controllerutil.RemoveFinalizer(auditRecord, buildv1alpha1.BuildAuditRecordFinalizer)
+	if err := r.Client.Update(ctx, auditRecord); err != nil {
+		log.Error(err, fmt.Sprintf("Failed to remove finalizer of build audit record in deleted namespace %s", auditRecord.Namespace))
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	log.Info(fmt.Sprintf("Released build audit record of deleted namespace %s", auditRecord.Namespace))
+	return ctrl.Result{}, nil
+}
+
+// getNamespaceAuditRecords returns reconcile requests for the deleted build audit records of the given namespace.
+func (r *BuildAuditRecordReconciler) getNamespaceAuditRecords(object client.Object) []reconcile.Request {
+	auditRecords := &buildv1alpha1.BuildAuditRecordList{}
+	if err := r.Client.List(context.Background(), auditRecords, client.InNamespace(object.GetName())); err != nil {
+		r.Log.Error(err, fmt.Sprintf("Failed to list build audit records in namespace %s", object.GetName()))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, auditRecord := range auditRecords.Items {
+		if !auditRecord.DeletionTimestamp.IsZero() {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: auditRecord.Name, Namespace: auditRecord.Namespace},
+			})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	buildv1alpha1 "github.com/redhat-appstudio/build-service/api/v1alpha1"
+)
+
+// deletedAuditRecordClient returns the given audit record and namespace and stores the updated record
+type deletedAuditRecordClient struct {
+	client.Client
+	auditRecord buildv1alpha1.BuildAuditRecord
+	namespace   corev1.Namespace
+	updated     *buildv1alpha1.BuildAuditRecord
+}
+
+func (c *deletedAuditRecordClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	switch obj := obj.(type) {
+	case *buildv1alpha1.BuildAuditRecord:
+		c.auditRecord.DeepCopyInto(obj)
+	case *corev1.Namespace:
+		c.namespace.DeepCopyInto(obj)
+	}
+	return nil
+}
+
+func (c *deletedAuditRecordClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.updated = obj.(*buildv1alpha1.BuildAuditRecord)
+	return nil
+}
+
+func TestBuildAuditRecordReconcile(t *testing.T) {
+	deletionTimestamp := metav1.Now()
+
+	tests := []struct {
+		name               string
+		recordDeleted      bool
+		namespaceDeleted   bool
+		wantFinalizerFreed bool
+	}{
+		{
+			name:               "record in deleted namespace",
+			recordDeleted:      true,
+			namespaceDeleted:   true,
+			wantFinalizerFreed: true,
+		},
+		{
+			name:          "record deleted in existing namespace",
+			recordDeleted: true,
+		},
+		{
+			name:             "record not deleted",
+			namespaceDeleted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := &deletedAuditRecordClient{
+				auditRecord: buildv1alpha1.BuildAuditRecord{ObjectMeta: metav1.ObjectMeta{
+					Name:       "my-component-abcde",
+					Namespace:  "my-namespace",
+					Finalizers: []string{buildv1alpha1.BuildAuditRecordFinalizer},
+				}},
+				namespace: corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "my-namespace"}},
+			}
+			if tt.recordDeleted {
+				cli.auditRecord.DeletionTimestamp = &deletionTimestamp
+			}
+			if tt.namespaceDeleted {
+				cli.namespace.DeletionTimestamp = &deletionTimestamp
+			}
+			r := &BuildAuditRecordReconciler{Client: cli, Log: logr.Discard()}
+
+			request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "my-component-abcde", Namespace: "my-namespace"}}
+			if _, err := r.Reconcile(context.TODO(), request); err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if tt.wantFinalizerFreed != (cli.updated != nil) {
+				t.Fatalf("Reconcile() updated record = %v, want %v", cli.updated != nil, tt.wantFinalizerFreed)
+			}
+			if cli.updated != nil && len(cli.updated.Finalizers) > 0 {
+				t.Errorf("Reconcile() kept finalizers %v", cli.updated.Finalizers)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	buildv1alpha1 "github.com/redhat-appstudio/build-service/api/v1alpha1"
+)
+
+func getAuditedPipelineRun(annotations map[string]string) tektonapi.PipelineRun {
+	return tektonapi.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "my-component-abcde",
+			Namespace:   "my-namespace",
+			Labels:      map[string]string{ComponentNameLabelName: "my-component"},
+			Annotations: annotations,
+		},
+		Spec: tektonapi.PipelineRunSpec{
+			Params: []tektonapi.Param{
+				{Name: "git-url", Value: *tektonapi.NewArrayOrString("https://github.com/foo/bar")},
+				{Name: "revision", Value: *tektonapi.NewArrayOrString("main")},
+			},
+		},
+	}
+}
+
+func TestGetBuildAuditRecord(t *testing.T) {
+	tests := []struct {
+		name           string
+		buildNamespace string
+		submitter      string
+		wantSubmitter  string
+	}{
+		{
+			name:          "build in the component namespace",
+			submitter:     "system:serviceaccount:build-service:controller-manager",
+			wantSubmitter: "system:serviceaccount:build-service:controller-manager",
+		},
+		{
+			name:           "build in a build namespace",
+			buildNamespace: "builds",
+			submitter:      "system:serviceaccount:build-service:controller-manager",
+			wantSubmitter:  "system:serviceaccount:build-service:controller-manager",
+		},
+		{
+			name:          "unknown submitter",
+			submitter:     "",
+			wantSubmitter: defaultBuildSubmitter,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := getGitSourceComponent(nil, "")
+			pipelineRun := getAuditedPipelineRun(map[string]string{BuildTriggerAnnotationName: BuildTriggerReasonDisruptionRetry})
+			if tt.buildNamespace != "" {
+				applyBuildNamespace(component, tt.buildNamespace, &pipelineRun)
+			}
+			got := getBuildAuditRecord(component, pipelineRun, tt.submitter)
+			want := buildv1alpha1.BuildAuditRecordSpec{
+				ComponentName:      "my-component",
+				ComponentNamespace: "my-namespace",
+				PipelineRunName:    "my-component-abcde",
+				Submitter:          tt.wantSubmitter,
+				TriggerReason:      BuildTriggerReasonDisruptionRetry,
+				GitURL:             "https://github.com/foo/bar",
+				Revision:           "main",
+				SubmissionTime:     got.Spec.SubmissionTime,
+			}
+			if got.Spec != want {
+				t.Errorf("getBuildAuditRecord() spec = %+v, want %+v", got.Spec, want)
+			}
+			if got.Name != pipelineRun.Name || got.Namespace != "my-namespace" {
+				t.Errorf("getBuildAuditRecord() name = %s/%s, want my-namespace/%s", got.Namespace, got.Name, pipelineRun.Name)
+			}
+			if len(got.Finalizers) != 1 || got.Finalizers[0] != buildv1alpha1.BuildAuditRecordFinalizer {
+				t.Errorf("getBuildAuditRecord() finalizers = %v, want %v", got.Finalizers, buildv1alpha1.BuildAuditRecordFinalizer)
+			}
+		})
+	}
+}
+
+func TestGetBuildTriggerReason(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        string
+	}{
+		{
+			name: "never built component",
+			want: BuildTriggerReasonInitialBuild,
+		},
+		{
+			name:        "changed build spec",
+			annotations: map[string]string{InitialBuildAnnotationName: "true"},
+			want:        BuildTriggerReasonSpecChange,
+		},
+		{
+			name:        "reset initial build annotation",
+			annotations: map[string]string{InitialBuildAnnotationName: "false"},
+			want:        BuildTriggerReasonManualBuild,
+		},
+		{
+			name: "retry of disrupted build",
+			annotations: map[string]string{
+				InitialBuildAnnotationName: "false",
+				BuildTriggerAnnotationName: BuildTriggerReasonDisruptionRetry,
+			},
+			want: BuildTriggerReasonDisruptionRetry,
+		},
+		{
+			name: "manual build after a retried build",
+			annotations: map[string]string{
+				InitialBuildAnnotationName:      "false",
+				DisruptionRetriesAnnotationName: "1",
+			},
+			want: BuildTriggerReasonManualBuild,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getBuildTriggerReason(getGitSourceComponent(tt.annotations, "")); got != tt.want {
+				t.Errorf("getBuildTriggerReason() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// auditRecordClient stores the created audit records and returns the given errors on subsequent creations
+type auditRecordClient struct {
+	client.Client
+	createErrs []error
+	attempts   int
+	created    []buildv1alpha1.BuildAuditRecord
+}
+
+func (c *auditRecordClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.attempts++
+	if len(c.createErrs) > 0 {
+		err := c.createErrs[0]
+		c.createErrs = c.createErrs[1:]
+		return err
+	}
+	c.created = append(c.created, *obj.(*buildv1alpha1.BuildAuditRecord))
+	return nil
+}
+
+func TestRecordBuildSubmission(t *testing.T) {
+	submittedBuild := getAuditedPipelineRun(map[string]string{BuildTriggerAnnotationName: BuildTriggerReasonInitialBuild})
+	groupResource := schema.GroupResource{Group: "build.appstudio.redhat.com", Resource: "buildauditrecords"}
+	connectionRefused := fmt.Errorf("connection refused")
+
+	tests := []struct {
+		name         string
+		client       *auditRecordClient
+		wantCreated  int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{
+			name:         "should create the record of submitted build",
+			client:       &auditRecordClient{},
+			wantCreated:  1,
+			wantAttempts: 1,
+		},
+		{
+			name:         "should ignore existing record",
+			client:       &auditRecordClient{createErrs: []error{errors.NewAlreadyExists(groupResource, submittedBuild.Name)}},
+			wantAttempts: 1,
+		},
+		{
+			name:         "should retry failed creation",
+			client:       &auditRecordClient{createErrs: []error{connectionRefused, connectionRefused}},
+			wantCreated:  1,
+			wantAttempts: 3,
+		},
+		{
+			name:         "should return creation error after retries",
+			client:       &auditRecordClient{createErrs: []error{connectionRefused, connectionRefused, connectionRefused, connectionRefused}},
+			wantAttempts: 4,
+			wantErr:      true,
+		},
+		{
+			name:         "should not retry forbidden creation",
+			client:       &auditRecordClient{createErrs: []error{errors.NewForbidden(groupResource, submittedBuild.Name, connectionRefused)}},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ComponentBuildReconciler{Client: tt.client, BuildSubmitter: "build-service-controller"}
+			err := r.recordBuildSubmission(context.TODO(), getGitSourceComponent(nil, ""), submittedBuild)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("recordBuildSubmission() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(tt.client.created) != tt.wantCreated {
+				t.Errorf("recordBuildSubmission() created %d records, want %d", len(tt.client.created), tt.wantCreated)
+			}
+			if tt.client.attempts != tt.wantAttempts {
+				t.Errorf("recordBuildSubmission() attempted %d creations, want %d", tt.client.attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	buildv1alpha1 "github.com/redhat-appstudio/build-service/api/v1alpha1"
+)
+
+// BuildAuditRecordWebhookPath is the path the BuildAuditRecord validating webhook is served at
+const BuildAuditRecordWebhookPath = "/validate-build-appstudio-redhat-com-v1alpha1-buildauditrecord"
+
+//+kubebuilder:webhook:path=/validate-build-appstudio-redhat-com-v1alpha1-buildauditrecord,mutating=false,failurePolicy=fail,sideEffects=None,groups=build.appstudio.redhat.com,resources=buildauditrecords,verbs=update,versions=v1alpha1,name=vbuildauditrecord.build.appstudio.redhat.com,admissionReviewVersions=v1
+
+// BuildAuditRecordValidator rejects changes of the recorded build submissions, so the audit trail is append-only.
+// Metadata, e.g. the finalizer, could still be changed, so an administrator is able to delete the records.
+type BuildAuditRecordValidator struct {
+	decoder *admission.Decoder
+}
+
+// InjectDecoder is called by the webhook server to set the decoder of the admission requests.
+func (v *BuildAuditRecordValidator) InjectDecoder(decoder *admission.Decoder) error {
+	v.decoder = decoder
+	return nil
+}
+
+// Handle rejects the BuildAuditRecord UPDATE request if it changes the spec of the record.
+func (v *BuildAuditRecordValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+
+	oldRecord := buildv1alpha1.BuildAuditRecord{}
+	if err := v.decoder.DecodeRaw(req.OldObject, &oldRecord); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	newRecord := buildv1alpha1.BuildAuditRecord{}
+	if err := v.decoder.DecodeRaw(req.Object, &newRecord); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if !equality.Semantic.DeepEqual(oldRecord.Spec, newRecord.Spec) {
+		return admission.Denied(fmt.Sprintf("BuildAuditRecord %s is immutable, its spec cannot be changed", req.Name))
+	}
+	return admission.Allowed("")
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	buildv1alpha1 "github.com/redhat-appstudio/build-service/api/v1alpha1"
+)
+
+func getBuildAuditRecordUpdateRequest(t *testing.T, oldRecord, newRecord buildv1alpha1.BuildAuditRecord) admission.Request {
+	oldRecordJSON, err := json.Marshal(oldRecord)
+	if err != nil {
+		t.Fatalf("failed to marshal audit record: %v", err)
+	}
+	newRecordJSON, err := json.Marshal(newRecord)
+	if err != nil {
+		t.Fatalf("failed to marshal audit record: %v", err)
+	}
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Update,
+		Name:      newRecord.Name,
+		Namespace: newRecord.Namespace,
+		OldObject: runtime.RawExtension{Raw: oldRecordJSON},
+		Object:    runtime.RawExtension{Raw: newRecordJSON},
+	}}
+}
+
+func TestBuildAuditRecordValidator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := buildv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to set up scheme: %v", err)
+	}
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatalf("failed to create decoder: %v", err)
+	}
+
+	record := getBuildAuditRecord(getGitSourceComponent(nil, ""), getAuditedPipelineRun(map[string]string{BuildTriggerAnnotationName: BuildTriggerReasonInitialBuild}), "")
+	record.Spec.SubmissionTime = metav1.Unix(1650000000, 0)
+	changedReason := *record.DeepCopy()
+	changedReason.Spec.TriggerReason = BuildTriggerReasonManualBuild
+	changedSubmitter := *record.DeepCopy()
+	changedSubmitter.Spec.Submitter = "someone"
+	removedFinalizer := *record.DeepCopy()
+	removedFinalizer.Finalizers = nil
+	addedLabel := *record.DeepCopy()
+	addedLabel.Labels["reviewed"] = "true"
+
+	tests := []struct {
+		name        string
+		newRecord   buildv1alpha1.BuildAuditRecord
+		wantAllowed bool
+	}{
+		{
+			name:        "should deny change of trigger reason",
+			newRecord:   changedReason,
+			wantAllowed: false,
+		},
+		{
+			name:        "should deny change of submitter",
+			newRecord:   changedSubmitter,
+			wantAllowed: false,
+		},
+		{
+			name:        "should allow removal of finalizer",
+			newRecord:   removedFinalizer,
+			wantAllowed: true,
+		},
+		{
+			name:        "should allow change of labels",
+			newRecord:   addedLabel,
+			wantAllowed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &BuildAuditRecordValidator{}
+			if err := v.InjectDecoder(decoder); err != nil {
+				t.Fatalf("failed to inject decoder: %v", err)
+			}
+			got := v.Handle(context.TODO(), getBuildAuditRecordUpdateRequest(t, record, tt.newRecord))
+			if got.Allowed != tt.wantAllowed {
+				t.Errorf("Handle() allowed = %v, want %v: %s", got.Allowed, tt.wantAllowed, got.Result.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// BuildBlockedConditionType is set on components whose build waits for a free build slot
+	BuildBlockedConditionType = "BuildBlocked"
+
+	BuildBlockedReasonConcurrencyLimit = "ConcurrencyLimitReached"
+	BuildBlockedReasonSubmitted        = "BuildSubmitted"
+
+	// Index of build PipelineRuns which are not finished yet
+	activeBuildIndexKey   = "build.appstudio.openshift.io/active"
+	activeBuildIndexValue = "true"
+
+	blockedBuildRequeueInterval = 10 * time.Second
+)
+
+// indexActiveBuild returns the index value for component build PipelineRuns which are pending or running.
+// Indexing allows to count active builds without listing all PipelineRuns in the cluster.
+func indexActiveBuild(object client.Object) []string {
+	pipelineRun, ok := object.(*tektonapi.PipelineRun)
+	if !ok {
+		return nil
+	}
+	if _, isComponentBuild := pipelineRun.Labels[ComponentNameLabelName]; !isComponentBuild {
+		return nil
+	}
+	switch getBuildState(*pipelineRun) {
+	case BuildStatePending, BuildStateRunning:
+		return []string{activeBuildIndexValue}
+	default:
+		return nil
+	}
+}
+
+// countActiveBuilds returns the number of pending and running component builds in the whole cluster,
+// the given options, e.g. client.InNamespace, restrict the count.
+// The count is taken from the cache, so builds submitted just before might be missing.
+func (r *ComponentBuildReconciler) countActiveBuilds(ctx context.Context, opts ...client.ListOption) (int, error) {
+	activeBuilds := &tektonapi.PipelineRunList{}
+	opts = append(opts, client.MatchingFields{activeBuildIndexKey: activeBuildIndexValue})
+	if err := r.Client.List(ctx, activeBuilds, opts...); err != nil {
+		return 0, err
+	}
+	return len(activeBuilds.Items), nil
+}
+
+func getBuildBlockedCondition(activeBuilds int, maxConcurrentBuilds int) metav1.Condition {
+	return metav1.Condition{
+		Type:    BuildBlockedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  BuildBlockedReasonConcurrencyLimit,
+		Message: fmt.Sprintf("%d builds are running in the cluster, the limit is %d. The build is queued", activeBuilds, maxConcurrentBuilds),
+	}
+}
+
+// clearBuildBlockedCondition marks the build of previously blocked component as submitted.
+func clearBuildBlockedCondition(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) error {
+	if !meta.IsStatusConditionTrue(component.Status.Conditions, BuildBlockedConditionType) {
+		return nil
+	}
+	return setComponentCondition(ctx, cli, component, metav1.Condition{
+		Type:    BuildBlockedConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  BuildBlockedReasonSubmitted,
+		Message: "The build has been submitted",
+	})
+}
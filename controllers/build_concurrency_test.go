@@ -0,0 +1,84 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestIndexActiveBuild(t *testing.T) {
+	buildLabels := map[string]string{ComponentNameLabelName: "my-component"}
+
+	pendingBuild := tektonapi.PipelineRun{}
+	runningBuild := tektonapi.PipelineRun{}
+	runningBuild.Status.StartTime = &metav1.Time{}
+
+	tests := []struct {
+		name   string
+		object client.Object
+		want   []string
+	}{
+		{
+			name:   "pending build",
+			object: withLabels(pendingBuild, buildLabels),
+			want:   []string{activeBuildIndexValue},
+		},
+		{
+			name:   "running build",
+			object: withLabels(runningBuild, buildLabels),
+			want:   []string{activeBuildIndexValue},
+		},
+		{
+			name:   "succeeded build",
+			object: withLabels(getPipelineRunWithSucceededCondition(corev1.ConditionTrue), buildLabels),
+			want:   nil,
+		},
+		{
+			name:   "failed build",
+			object: withLabels(getPipelineRunWithSucceededCondition(corev1.ConditionFalse), buildLabels),
+			want:   nil,
+		},
+		{
+			name:   "not a component build",
+			object: withLabels(pendingBuild, map[string]string{ImageScanComponentLabelName: "my-component"}),
+			want:   nil,
+		},
+		{
+			name:   "not a PipelineRun",
+			object: &corev1.ConfigMap{},
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := indexActiveBuild(tt.object); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("indexActiveBuild() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func withLabels(pipelineRun tektonapi.PipelineRun, labels map[string]string) *tektonapi.PipelineRun {
+	pipelineRun.Labels = labels
+	return &pipelineRun
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+// Reasons of initial build decisions
+const (
+	BuildDecisionReasonRequired          = "InitialBuildRequired"
+	BuildDecisionReasonContainerImage    = "ContainerImageComponent"
+	BuildDecisionReasonWaitingForDevfile = "WaitingForDevfileModel"
+	BuildDecisionReasonAlreadySubmitted  = "InitialBuildAlreadySubmitted"
+	BuildDecisionReasonNoSource          = "NoBuildableSource"
+)
+
+const (
+	// ImageSourceIgnoredConditionType is set on components that have both git and image sources
+	ImageSourceIgnoredConditionType = "ImageSourceIgnored"
+
+	ImageSourceIgnoredReasonGitPreferred = "GitSourcePreferred"
+)
+
+// InitialBuildDecision describes whether the initial build should be submitted for a component and why.
+type InitialBuildDecision struct {
+	BuildRequired bool   `json:"buildRequired"`
+	Reason        string `json:"reason"`
+}
+
+// getInitialBuildDecision decides whether the initial build should be submitted for the component.
+// It doesn't do any changes, so it could be used to preview reconcile results.
+func getInitialBuildDecision(component appstudiov1alpha1.Component) InitialBuildDecision {
+	// Git source takes precedence, do not run any builds for container-image only components
+	if getGitSource(component) == nil {
+		if getImageSource(component) != nil {
+			return InitialBuildDecision{BuildRequired: false, Reason: BuildDecisionReasonContainerImage}
+		}
+		return InitialBuildDecision{BuildRequired: false, Reason: BuildDecisionReasonNoSource}
+	}
+
+	if component.Status.Devfile == "" {
+		// The component has been just created.
+		// Component controller must set devfile model, wait for it.
+		return InitialBuildDecision{BuildRequired: false, Reason: BuildDecisionReasonWaitingForDevfile}
+	}
+
+	if component.Annotations[InitialBuildAnnotationName] == "true" {
+		return InitialBuildDecision{BuildRequired: false, Reason: BuildDecisionReasonAlreadySubmitted}
+	}
+
+	return InitialBuildDecision{BuildRequired: true, Reason: BuildDecisionReasonRequired}
+}
+
+// getGitSource returns git source of the component or nil if the component is not built from git.
+func getGitSource(component appstudiov1alpha1.Component) *appstudiov1alpha1.GitSource {
+	if component.Spec.Source.GitSource == nil || component.Spec.Source.GitSource.URL == "" {
+		return nil
+	}
+	return component.Spec.Source.GitSource
+}
+
+// getImageSource returns image source of the component or nil if the component doesn't refer to a container image.
+func getImageSource(component appstudiov1alpha1.Component) *appstudiov1alpha1.ImageSource {
+	if component.Spec.Source.ImageSource == nil || component.Spec.Source.ImageSource.ContainerImage == "" {
+		return nil
+	}
+	return component.Spec.Source.ImageSource
+}
+
+// getImageSourceIgnoredCondition returns the condition to inform that image source of the component is not used for builds.
+// Returns nil if the component doesn't have both git and image sources.
+func getImageSourceIgnoredCondition(component appstudiov1alpha1.Component) *metav1.Condition {
+	gitSource := getGitSource(component)
+	imageSource := getImageSource(component)
+	if gitSource == nil || imageSource == nil {
+		return nil
+	}
+	return &metav1.Condition{
+		Type:   ImageSourceIgnoredConditionType,
+		Status: metav1.ConditionTrue,
+		Reason: ImageSourceIgnoredReasonGitPreferred,
+		Message: fmt.Sprintf("The component is built from git repository %s, image source %s is ignored",
+			gitSource.URL, imageSource.ContainerImage),
+	}
+}
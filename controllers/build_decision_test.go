@@ -0,0 +1,148 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+func getGitSourceComponent(annotations map[string]string, devfile string) appstudiov1alpha1.Component {
+	return appstudiov1alpha1.Component{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "my-component",
+			Namespace:   "my-namespace",
+			Annotations: annotations,
+		},
+		Spec: appstudiov1alpha1.ComponentSpec{
+			Source: appstudiov1alpha1.ComponentSource{
+				ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+					GitSource: &appstudiov1alpha1.GitSource{URL: "https://github.com/foo/bar"},
+				},
+			},
+		},
+		Status: appstudiov1alpha1.ComponentStatus{
+			Devfile: devfile,
+		},
+	}
+}
+
+func TestGetInitialBuildDecision(t *testing.T) {
+	containerImageComponent := getGitSourceComponent(nil, "version: 2.2.0")
+	containerImageComponent.Spec.Source.GitSource = nil
+	containerImageComponent.Spec.Source.ImageSource = &appstudiov1alpha1.ImageSource{ContainerImage: "quay.io/foo/bar"}
+
+	gitAndImageSourceComponent := getGitSourceComponent(nil, "version: 2.2.0")
+	gitAndImageSourceComponent.Spec.Source.ImageSource = &appstudiov1alpha1.ImageSource{ContainerImage: "quay.io/foo/bar"}
+
+	noSourceComponent := getGitSourceComponent(nil, "version: 2.2.0")
+	noSourceComponent.Spec.Source.GitSource = nil
+
+	tests := []struct {
+		name      string
+		component appstudiov1alpha1.Component
+		want      InitialBuildDecision
+	}{
+		{
+			name:      "build required",
+			component: getGitSourceComponent(nil, "version: 2.2.0"),
+			want:      InitialBuildDecision{BuildRequired: true, Reason: BuildDecisionReasonRequired},
+		},
+		{
+			name:      "build required after failed attempt",
+			component: getGitSourceComponent(map[string]string{InitialBuildAnnotationName: "false"}, "version: 2.2.0"),
+			want:      InitialBuildDecision{BuildRequired: true, Reason: BuildDecisionReasonRequired},
+		},
+		{
+			name:      "container image component",
+			component: containerImageComponent,
+			want:      InitialBuildDecision{BuildRequired: false, Reason: BuildDecisionReasonContainerImage},
+		},
+		{
+			name:      "git and image sources",
+			component: gitAndImageSourceComponent,
+			want:      InitialBuildDecision{BuildRequired: true, Reason: BuildDecisionReasonRequired},
+		},
+		{
+			name:      "no source",
+			component: noSourceComponent,
+			want:      InitialBuildDecision{BuildRequired: false, Reason: BuildDecisionReasonNoSource},
+		},
+		{
+			name:      "no devfile model",
+			component: getGitSourceComponent(nil, ""),
+			want:      InitialBuildDecision{BuildRequired: false, Reason: BuildDecisionReasonWaitingForDevfile},
+		},
+		{
+			name:      "initial build submitted",
+			component: getGitSourceComponent(map[string]string{InitialBuildAnnotationName: "true"}, "version: 2.2.0"),
+			want:      InitialBuildDecision{BuildRequired: false, Reason: BuildDecisionReasonAlreadySubmitted},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getInitialBuildDecision(tt.component); got != tt.want {
+				t.Errorf("getInitialBuildDecision() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetImageSourceIgnoredCondition(t *testing.T) {
+	gitAndImageSourceComponent := getGitSourceComponent(nil, "version: 2.2.0")
+	gitAndImageSourceComponent.Spec.Source.ImageSource = &appstudiov1alpha1.ImageSource{ContainerImage: "quay.io/foo/bar"}
+
+	emptyImageSourceComponent := getGitSourceComponent(nil, "version: 2.2.0")
+	emptyImageSourceComponent.Spec.Source.ImageSource = &appstudiov1alpha1.ImageSource{}
+
+	tests := []struct {
+		name          string
+		component     appstudiov1alpha1.Component
+		wantCondition bool
+	}{
+		{
+			name:          "git source only",
+			component:     getGitSourceComponent(nil, "version: 2.2.0"),
+			wantCondition: false,
+		},
+		{
+			name:          "empty image source",
+			component:     emptyImageSourceComponent,
+			wantCondition: false,
+		},
+		{
+			name:          "git and image sources",
+			component:     gitAndImageSourceComponent,
+			wantCondition: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getImageSourceIgnoredCondition(tt.component)
+			if (got != nil) != tt.wantCondition {
+				t.Errorf("getImageSourceIgnoredCondition() = %v, want condition %v", got, tt.wantCondition)
+				return
+			}
+			if got != nil && (got.Type != ImageSourceIgnoredConditionType || got.Status != metav1.ConditionTrue || got.Reason != ImageSourceIgnoredReasonGitPreferred) {
+				t.Errorf("getImageSourceIgnoredCondition() = %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// Comma-separated names of components of the same namespace which must be built successfully before the component
+	WaitForComponentsAnnotationName = "build.appstudio.openshift.io/wait-for-components"
+
+	// WaitingForComponentsConditionType is set on components whose build waits for builds of their dependencies
+	WaitingForComponentsConditionType = "WaitingForComponents"
+
+	WaitingForComponentsReasonNotBuilt = "DependenciesNotBuilt"
+	WaitingForComponentsReasonCycle    = "DependencyCycle"
+	WaitingForComponentsReasonBuilt    = "DependenciesBuilt"
+)
+
+// getBuildDependencies returns names of the components listed in the wait-for-components annotation.
+func getBuildDependencies(component appstudiov1alpha1.Component) []string {
+	var dependencies []string
+	for _, name := range strings.Split(component.Annotations[WaitForComponentsAnnotationName], ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || name == component.Name {
+			continue
+		}
+		dependencies = append(dependencies, name)
+	}
+	return dependencies
+}
+
+// getPendingDependencies returns names of the component dependencies which are not built successfully yet.
+// The latest successful build of a dependency must be of its current git URL and of the revision requested
+// in its pipeline parameters, if any.
+func (r *ComponentBuildReconciler) getPendingDependencies(ctx context.Context, component appstudiov1alpha1.Component) ([]string, error) {
+	var pendingDependencies []string
+	for _, name := range getBuildDependencies(component) {
+		var dependency appstudiov1alpha1.Component
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: component.Namespace}, &dependency); err != nil {
+			if errors.IsNotFound(err) {
+				pendingDependencies = append(pendingDependencies, name)
+				continue
+			}
+			return nil, err
+		}
+
+		successfulBuild, err := getLastSuccessfulBuild(ctx, r.Client, dependency)
+		if err != nil {
+			return nil, err
+		}
+		if successfulBuild == nil || !isBuildOfCurrentSource(*successfulBuild, dependency) {
+			pendingDependencies = append(pendingDependencies, name)
+		}
+	}
+	return pendingDependencies, nil
+}
+
+// isBuildOfCurrentSource checks whether the successful build built the current git source of the component.
+func isBuildOfCurrentSource(successfulBuild SuccessfulBuild, component appstudiov1alpha1.Component) bool {
+	gitSource := getGitSource(component)
+	if gitSource == nil {
+		return true
+	}
+	if successfulBuild.GitURL != gitSource.URL {
+		return false
+	}
+	requestedRevision := getRequestedRevision(component)
+	return requestedRevision == "" || successfulBuild.Revision == requestedRevision
+}
+
+// getRequestedRevision returns the revision set in the pipeline parameters of the component,
+// empty string means the default branch.
+func getRequestedRevision(component appstudiov1alpha1.Component) string {
+	params, err := getPipelineParamsFromAnnotation(component)
+	if err != nil {
+		return ""
+	}
+	for _, param := range params {
+		if param.Name == "revision" {
+			return param.Value.StringVal
+		}
+	}
+	return ""
+}
+
+// getDependencyCycle returns the components of a dependency cycle the component is part of,
+// starting and ending with the component, or nil if there is no cycle.
+func (r *ComponentBuildReconciler) getDependencyCycle(ctx context.Context, component appstudiov1alpha1.Component) ([]string, error) {
+	visited := map[string]bool{}
+	var findPath func(name string, path []string) ([]string, error)
+	findPath = func(name string, path []string) ([]string, error) {
+		if name == component.Name && len(path) > 0 {
+			return append(path, name), nil
+		}
+		if visited[name] {
+			return nil, nil
+		}
+		visited[name] = true
+
+		dependency := component
+		if name != component.Name {
+			if err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: component.Namespace}, &dependency); err != nil {
+				if errors.IsNotFound(err) {
+					return nil, nil
+				}
+				return nil, err
+			}
+		}
+		for _, dependencyName := range getBuildDependencies(dependency) {
+			cycle, err := findPath(dependencyName, append(path, name))
+			if cycle != nil || err != nil {
+				return cycle, err
+			}
+		}
+		return nil, nil
+	}
+	return findPath(component.Name, nil)
+}
+
+// getDependentComponents returns reconcile requests for all components waiting for the given component.
+// They are reconciled when a successful build of the component is recorded or when its dependencies change,
+// which might resolve a dependency cycle.
+func (r *ComponentBuildReconciler) getDependentComponents(object client.Object) []reconcile.Request {
+	components := &appstudiov1alpha1.ComponentList{}
+	if err := r.Client.List(context.Background(), components, client.InNamespace(object.GetNamespace())); err != nil {
+		r.Log.Error(err, fmt.Sprintf("Failed to list components in namespace %s", object.GetNamespace()))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, component := range components.Items {
+		for _, dependency := range getBuildDependencies(component) {
+			if dependency == object.GetName() {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: component.Name, Namespace: component.Namespace},
+				})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+// isDependencyUpdate checks whether the update of the component might unblock components waiting for it.
+func isDependencyUpdate(e event.UpdateEvent) bool {
+	oldAnnotations := e.ObjectOld.GetAnnotations()
+	newAnnotations := e.ObjectNew.GetAnnotations()
+	return oldAnnotations[LastSuccessfulBuildAnnotationName] != newAnnotations[LastSuccessfulBuildAnnotationName] ||
+		oldAnnotations[WaitForComponentsAnnotationName] != newAnnotations[WaitForComponentsAnnotationName]
+}
+
+func getWaitingForComponentsCondition(pendingDependencies []string) metav1.Condition {
+	return metav1.Condition{
+		Type:    WaitingForComponentsConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  WaitingForComponentsReasonNotBuilt,
+		Message: fmt.Sprintf("Waiting for successful build of components: %s", strings.Join(pendingDependencies, ", ")),
+	}
+}
+
+func getDependencyCycleCondition(cycle []string) metav1.Condition {
+	return metav1.Condition{
+		Type:    WaitingForComponentsConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  WaitingForComponentsReasonCycle,
+		Message: fmt.Sprintf("Components wait for each other, the build is not submitted until the cycle is removed: %s", strings.Join(cycle, " -> ")),
+	}
+}
+
+// clearWaitingForComponentsCondition marks the build of previously waiting component as submitted.
+func clearWaitingForComponentsCondition(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) error {
+	if !meta.IsStatusConditionTrue(component.Status.Conditions, WaitingForComponentsConditionType) {
+		return nil
+	}
+	return setComponentCondition(ctx, cli, component, metav1.Condition{
+		Type:    WaitingForComponentsConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  WaitingForComponentsReasonBuilt,
+		Message: "All components the build waits for have been built, the build has been submitted",
+	})
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+func TestGetBuildDependencies(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		want       []string
+	}{
+		{
+			name:       "no dependencies",
+			annotation: "",
+			want:       nil,
+		},
+		{
+			name:       "single dependency",
+			annotation: "backend",
+			want:       []string{"backend"},
+		},
+		{
+			name:       "multiple dependencies with spaces",
+			annotation: "backend, database ,cache",
+			want:       []string{"backend", "database", "cache"},
+		},
+		{
+			name:       "empty items and the component itself are ignored",
+			annotation: "backend,,my-component,",
+			want:       []string{"backend"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := getGitSourceComponent(map[string]string{WaitForComponentsAnnotationName: tt.annotation}, "")
+			if got := getBuildDependencies(component); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getBuildDependencies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// dependencyClient returns the given components by name and the given PipelineRuns of all components
+type dependencyClient struct {
+	client.Client
+	components   map[string]appstudiov1alpha1.Component
+	pipelineRuns []tektonapi.PipelineRun
+}
+
+func (c *dependencyClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	component, ok := c.components[key.Name]
+	if !ok {
+		return errors.NewNotFound(appstudiov1alpha1.GroupVersion.WithResource("components").GroupResource(), key.Name)
+	}
+	component.DeepCopyInto(obj.(*appstudiov1alpha1.Component))
+	return nil
+}
+
+func (c *dependencyClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	switch list := list.(type) {
+	case *tektonapi.PipelineRunList:
+		list.Items = c.pipelineRuns
+	case *appstudiov1alpha1.ComponentList:
+		for _, component := range c.components {
+			list.Items = append(list.Items, component)
+		}
+	}
+	return nil
+}
+
+func getDependencyTestComponent(name string, dependencies string, successfulBuild *SuccessfulBuild) appstudiov1alpha1.Component {
+	annotations := map[string]string{WaitForComponentsAnnotationName: dependencies}
+	if successfulBuild != nil {
+		recordJSON, _ := json.Marshal(successfulBuild)
+		annotations[LastSuccessfulBuildAnnotationName] = string(recordJSON)
+	}
+	component := getGitSourceComponent(annotations, "")
+	component.Name = name
+	return component
+}
+
+func TestIsBuildOfCurrentSource(t *testing.T) {
+	component := getGitSourceComponent(nil, "")
+	revisionComponent := getGitSourceComponent(map[string]string{PipelineParamsAnnotationName: `{"revision": "release-1"}`}, "")
+
+	tests := []struct {
+		name            string
+		successfulBuild SuccessfulBuild
+		component       appstudiov1alpha1.Component
+		want            bool
+	}{
+		{
+			name:            "build of current source",
+			successfulBuild: SuccessfulBuild{PipelineRun: "my-component-a", GitURL: "https://github.com/foo/bar", Revision: "main"},
+			component:       component,
+			want:            true,
+		},
+		{
+			name:            "build of previous source",
+			successfulBuild: SuccessfulBuild{PipelineRun: "my-component-a", GitURL: "https://github.com/foo/old"},
+			component:       component,
+			want:            false,
+		},
+		{
+			name:            "build of requested revision",
+			successfulBuild: SuccessfulBuild{PipelineRun: "my-component-a", GitURL: "https://github.com/foo/bar", Revision: "release-1"},
+			component:       revisionComponent,
+			want:            true,
+		},
+		{
+			name:            "build of another revision",
+			successfulBuild: SuccessfulBuild{PipelineRun: "my-component-a", GitURL: "https://github.com/foo/bar", Revision: "main"},
+			component:       revisionComponent,
+			want:            false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBuildOfCurrentSource(tt.successfulBuild, tt.component); got != tt.want {
+				t.Errorf("isBuildOfCurrentSource() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPendingDependencies(t *testing.T) {
+	builtBackend := getDependencyTestComponent("backend", "",
+		&SuccessfulBuild{PipelineRun: "backend-a", GitURL: "https://github.com/foo/bar"})
+	// The successful build is recorded although its PipelineRun has been deleted
+	builtDatabase := getDependencyTestComponent("database", "",
+		&SuccessfulBuild{PipelineRun: "database-a", GitURL: "https://github.com/foo/bar"})
+	unbuiltCache := getDependencyTestComponent("cache", "", nil)
+	component := getDependencyTestComponent("my-component", "backend,database,cache,queue", nil)
+
+	r := &ComponentBuildReconciler{Client: &dependencyClient{components: map[string]appstudiov1alpha1.Component{
+		"backend": builtBackend, "database": builtDatabase, "cache": unbuiltCache, "my-component": component,
+	}}}
+	got, err := r.getPendingDependencies(context.TODO(), component)
+	if err != nil {
+		t.Fatalf("getPendingDependencies() error = %v", err)
+	}
+	if want := []string{"cache", "queue"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("getPendingDependencies() = %v, want %v", got, want)
+	}
+}
+
+func TestGetDependencyCycle(t *testing.T) {
+	tests := []struct {
+		name       string
+		components []appstudiov1alpha1.Component
+		want       []string
+	}{
+		{
+			name: "no cycle in diamond dependencies",
+			components: []appstudiov1alpha1.Component{
+				getDependencyTestComponent("my-component", "backend,frontend", nil),
+				getDependencyTestComponent("backend", "database", nil),
+				getDependencyTestComponent("frontend", "database", nil),
+				getDependencyTestComponent("database", "", nil),
+			},
+			want: nil,
+		},
+		{
+			name: "components wait for each other",
+			components: []appstudiov1alpha1.Component{
+				getDependencyTestComponent("my-component", "backend", nil),
+				getDependencyTestComponent("backend", "my-component", nil),
+			},
+			want: []string{"my-component", "backend", "my-component"},
+		},
+		{
+			name: "transitive cycle",
+			components: []appstudiov1alpha1.Component{
+				getDependencyTestComponent("my-component", "missing,backend", nil),
+				getDependencyTestComponent("backend", "database", nil),
+				getDependencyTestComponent("database", "my-component", nil),
+			},
+			want: []string{"my-component", "backend", "database", "my-component"},
+		},
+		{
+			name: "cycle of dependencies does not block the component",
+			components: []appstudiov1alpha1.Component{
+				getDependencyTestComponent("my-component", "backend", nil),
+				getDependencyTestComponent("backend", "database", nil),
+				getDependencyTestComponent("database", "backend", nil),
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := &dependencyClient{components: map[string]appstudiov1alpha1.Component{}}
+			for _, component := range tt.components {
+				cli.components[component.Name] = component
+			}
+			r := &ComponentBuildReconciler{Client: cli}
+			got, err := r.getDependencyCycle(context.TODO(), tt.components[0])
+			if err != nil {
+				t.Fatalf("getDependencyCycle() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getDependencyCycle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetDependentComponents(t *testing.T) {
+	cli := &dependencyClient{components: map[string]appstudiov1alpha1.Component{
+		"my-component": getDependencyTestComponent("my-component", "backend", nil),
+		"frontend":     getDependencyTestComponent("frontend", "database", nil),
+		"backend":      getDependencyTestComponent("backend", "", nil),
+	}}
+	r := &ComponentBuildReconciler{Client: cli}
+
+	backend := cli.components["backend"]
+	got := r.getDependentComponents(&backend)
+	want := []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "my-component", Namespace: "my-namespace"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getDependentComponents() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// Set on failed build PipelineRuns to the category of the failure once it has been counted in the metrics
+	BuildFailureCategoryAnnotationName = "build.appstudio.openshift.io/failure-category"
+
+	// BuildFailedConditionType is set on components whose latest build failed, the reason is the failure category
+	BuildFailedConditionType = "BuildFailed"
+
+	BuildFailedReasonSucceeded = "BuildSucceeded"
+)
+
+// Build failure categories, the list is bounded to keep the metric cardinality low
+const (
+	BuildFailureCategoryClone      = "clone"
+	BuildFailureCategoryPush       = "push"
+	BuildFailureCategoryCompile    = "compile"
+	BuildFailureCategoryTimeout    = "timeout"
+	BuildFailureCategoryDisruption = "disruption"
+	BuildFailureCategoryOther      = "other"
+)
+
+// buildFailureReasons maps build failure categories to the BuildFailed condition reasons
+var buildFailureReasons = map[string]string{
+	BuildFailureCategoryClone:      "CloneFailed",
+	BuildFailureCategoryPush:       "PushFailed",
+	BuildFailureCategoryCompile:    "CompileFailed",
+	BuildFailureCategoryTimeout:    "TimedOut",
+	BuildFailureCategoryDisruption: "Disrupted",
+	BuildFailureCategoryOther:      "Failed",
+}
+
+// Lowercase fragments of buildah failure messages caused by a failed image push.
+// Registry authorization errors are matched by their full messages, so other permission errors of the build steps
+// are not taken for push failures.
+var pushFailureMessages = []string{
+	"error pushing image",
+	"pushing image",
+	"writing blob",
+	"unauthorized: access to the requested resource is not authorized",
+	"unauthorized: authentication required",
+	"denied: requested access to the resource is denied",
+}
+
+// TaskRun failure reasons caused by cluster disruptions, e.g. a node drain, rather than by the build itself
+var disruptionFailureReasons = map[string]bool{
+	"TaskRunImagePullFailed": true,
+	"Evicted":                true,
+	"Preempted":              true,
+}
+
+// Lowercase fragments of TaskRun failure messages caused by cluster disruptions
+var disruptionFailureMessages = []string{
+	"evicted",
+	"preempted",
+	"node shutdown",
+	"the node was low on resource",
+}
+
+// isDisruptionFailure checks whether the PipelineRun failed because a pod of one of its TaskRuns was disrupted.
+func isDisruptionFailure(pipelineRun tektonapi.PipelineRun) bool {
+	for _, taskRun := range pipelineRun.Status.TaskRuns {
+		if taskRun == nil || taskRun.Status == nil {
+			continue
+		}
+		condition := taskRun.Status.GetCondition(apis.ConditionSucceeded)
+		if !condition.IsFalse() {
+			continue
+		}
+		if disruptionFailureReasons[condition.Reason] {
+			return true
+		}
+		message := strings.ToLower(condition.Message)
+		for _, disruptionMessage := range disruptionFailureMessages {
+			if strings.Contains(message, disruptionMessage) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getBuildFailureCategory classifies the failure of the PipelineRun by the failed task and its message.
+func getBuildFailureCategory(pipelineRun tektonapi.PipelineRun) string {
+	if isDisruptionFailure(pipelineRun) {
+		return BuildFailureCategoryDisruption
+	}
+	if condition := pipelineRun.Status.GetCondition(apis.ConditionSucceeded); condition != nil &&
+		condition.Reason == tektonapi.PipelineRunReasonTimedOut.String() {
+		return BuildFailureCategoryTimeout
+	}
+
+	taskName, message := getFailedTask(pipelineRun)
+	taskName = strings.ToLower(taskName)
+	message = strings.ToLower(message)
+	// The image is built and pushed by the buildah task, other tasks do not push the built image
+	isImageBuildTask := strings.Contains(taskName, "build")
+	switch {
+	case strings.Contains(taskName, "clone"):
+		return BuildFailureCategoryClone
+	case strings.Contains(taskName, "push") || (isImageBuildTask && containsAny(message, pushFailureMessages)):
+		return BuildFailureCategoryPush
+	case isImageBuildTask:
+		return BuildFailureCategoryCompile
+	default:
+		return BuildFailureCategoryOther
+	}
+}
+
+// getFailedTask returns the pipeline task name and the failure message of the first failed TaskRun of the PipelineRun.
+// Failed TaskRuns are ordered by their completion time, then by their start time, so the failure which caused
+// failures of the parallel tasks is returned. TaskRuns without the times go last, ties are broken by name
+// to get the same result for the same PipelineRun.
+func getFailedTask(pipelineRun tektonapi.PipelineRun) (string, string) {
+	var failedTaskRuns []string
+	for name, taskRun := range pipelineRun.Status.TaskRuns {
+		if taskRun == nil || taskRun.Status == nil {
+			continue
+		}
+		if taskRun.Status.GetCondition(apis.ConditionSucceeded).IsFalse() {
+			failedTaskRuns = append(failedTaskRuns, name)
+		}
+	}
+	if len(failedTaskRuns) == 0 {
+		return "", ""
+	}
+
+	sort.Slice(failedTaskRuns, func(i, j int) bool {
+		first := pipelineRun.Status.TaskRuns[failedTaskRuns[i]].Status
+		second := pipelineRun.Status.TaskRuns[failedTaskRuns[j]].Status
+		if before, ordered := isTimeBefore(first.CompletionTime, second.CompletionTime); ordered {
+			return before
+		}
+		if before, ordered := isTimeBefore(first.StartTime, second.StartTime); ordered {
+			return before
+		}
+		return failedTaskRuns[i] < failedTaskRuns[j]
+	})
+	taskRun := pipelineRun.Status.TaskRuns[failedTaskRuns[0]]
+	return taskRun.PipelineTaskName, taskRun.Status.GetCondition(apis.ConditionSucceeded).Message
+}
+
+// isTimeBefore compares the optional times, missing times go last.
+// The second returned value is false if the times do not order the values.
+func isTimeBefore(first *metav1.Time, second *metav1.Time) (bool, bool) {
+	switch {
+	case first == nil && second == nil:
+		return false, false
+	case first == nil:
+		return false, true
+	case second == nil:
+		return true, true
+	case first.Equal(second):
+		return false, false
+	default:
+		return first.Before(second), true
+	}
+}
+
+func containsAny(s string, fragments []string) bool {
+	for _, fragment := range fragments {
+		if strings.Contains(s, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordBuildFailure counts the failure of the build by its category once and reflects the result of the latest build
+// of the component in the BuildFailed condition.
+func (r *BuildPipelineRunReconciler) recordBuildFailure(ctx context.Context, component appstudiov1alpha1.Component, pipelineRun *tektonapi.PipelineRun) error {
+	state := getBuildState(*pipelineRun)
+	if state != BuildStateSucceeded && state != BuildStateFailed {
+		return nil
+	}
+
+	latestPipelineRun, err := getLatestComponentPipelineRun(ctx, r.Client, component)
+	if err != nil {
+		return err
+	}
+	isLatestBuild := latestPipelineRun != nil && latestPipelineRun.Name == pipelineRun.Name
+
+	if state == BuildStateSucceeded {
+		if !isLatestBuild || !meta.IsStatusConditionTrue(component.Status.Conditions, BuildFailedConditionType) {
+			return nil
+		}
+		return setComponentCondition(ctx, r.Client, component, metav1.Condition{
+			Type:    BuildFailedConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  BuildFailedReasonSucceeded,
+			Message: fmt.Sprintf("The latest build %s succeeded", pipelineRun.Name),
+		})
+	}
+
+	category, isRecorded := pipelineRun.Annotations[BuildFailureCategoryAnnotationName]
+	if !isRecorded {
+		category = getBuildFailureCategory(*pipelineRun)
+		if pipelineRun.Annotations == nil {
+			pipelineRun.Annotations = map[string]string{}
+		}
+		pipelineRun.Annotations[BuildFailureCategoryAnnotationName] = category
+		if err := r.Client.Update(ctx, pipelineRun); err != nil {
+			return err
+		}
+		buildFailuresTotal.WithLabelValues(category).Inc()
+	}
+
+	if !isLatestBuild {
+		return nil
+	}
+	_, message := getFailedTask(*pipelineRun)
+	if message == "" {
+		message = pipelineRun.Status.GetCondition(apis.ConditionSucceeded).GetMessage()
+	}
+	return setComponentCondition(ctx, r.Client, component, metav1.Condition{
+		Type:    BuildFailedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  buildFailureReasons[category],
+		Message: fmt.Sprintf("The latest build %s failed: %s", pipelineRun.Name, message),
+	})
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
+)
+
+// getFailedPipelineRun returns a failed PipelineRun with a single failed TaskRun of the given task.
+func getFailedPipelineRun(taskName string, reason string, message string) tektonapi.PipelineRun {
+	pipelineRun := getPipelineRunWithSucceededCondition(corev1.ConditionFalse)
+	pipelineRun.Status.TaskRuns = map[string]*tektonapi.PipelineRunTaskRunStatus{
+		"taskrun-" + taskName: {
+			PipelineTaskName: taskName,
+			Status: &tektonapi.TaskRunStatus{
+				Status: duckv1beta1.Status{
+					Conditions: duckv1beta1.Conditions{
+						{
+							Type:    apis.ConditionSucceeded,
+							Status:  corev1.ConditionFalse,
+							Reason:  reason,
+							Message: message,
+						},
+					},
+				},
+			},
+		},
+	}
+	return pipelineRun
+}
+
+func TestIsDisruptionFailure(t *testing.T) {
+	tests := []struct {
+		name        string
+		pipelineRun tektonapi.PipelineRun
+		want        bool
+	}{
+		{
+			name:        "image pull failure",
+			pipelineRun: getFailedPipelineRun("build-container", "TaskRunImagePullFailed", "failed to pull image"),
+			want:        true,
+		},
+		{
+			name:        "evicted pod",
+			pipelineRun: getFailedPipelineRun("build-container", "Failed", "The node was low on resource: memory."),
+			want:        true,
+		},
+		{
+			name:        "node shutdown",
+			pipelineRun: getFailedPipelineRun("clone-repository", "Failed", "Pod was terminated in response to imminent node shutdown."),
+			want:        true,
+		},
+		{
+			name:        "failed build step",
+			pipelineRun: getFailedPipelineRun("build-container", "Failed", `"step-build" exited with code 1`),
+			want:        false,
+		},
+		{
+			name:        "no task runs",
+			pipelineRun: getPipelineRunWithSucceededCondition(corev1.ConditionFalse),
+			want:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDisruptionFailure(tt.pipelineRun); got != tt.want {
+				t.Errorf("isDisruptionFailure() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBuildFailureCategory(t *testing.T) {
+	timedOutPipelineRun := getPipelineRunWithSucceededCondition(corev1.ConditionFalse)
+	timedOutPipelineRun.Status.SetCondition(&apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionFalse,
+		Reason: tektonapi.PipelineRunReasonTimedOut.String(),
+	})
+
+	tests := []struct {
+		name        string
+		pipelineRun tektonapi.PipelineRun
+		want        string
+	}{
+		{
+			name:        "clone failure",
+			pipelineRun: getFailedPipelineRun("clone-repository", "Failed", "fatal: could not read Username for 'https://github.com'"),
+			want:        BuildFailureCategoryClone,
+		},
+		{
+			name:        "push failure",
+			pipelineRun: getFailedPipelineRun("build-container", "Failed", "Error: error pushing image \"quay.io/foo/bar\": unauthorized"),
+			want:        BuildFailureCategoryPush,
+		},
+		{
+			name: "registry authorization failure",
+			pipelineRun: getFailedPipelineRun("build-container", "Failed",
+				"Error: writing blob: initiating layer upload to /v2/foo/bar/blobs/uploads/ in quay.io: unauthorized: access to the requested resource is not authorized"),
+			want: BuildFailureCategoryPush,
+		},
+		{
+			name:        "permission denied in build step",
+			pipelineRun: getFailedPipelineRun("build-container", "Failed", "mkdir /var/lib/app: permission denied"),
+			want:        BuildFailureCategoryCompile,
+		},
+		{
+			name:        "push message of other task",
+			pipelineRun: getFailedPipelineRun("sanity-inspect-image", "Failed", "denied: requested access to the resource is denied"),
+			want:        BuildFailureCategoryOther,
+		},
+		{
+			name:        "push task failure",
+			pipelineRun: getFailedPipelineRun("push-image", "Failed", `"step-push" exited with code 1`),
+			want:        BuildFailureCategoryPush,
+		},
+		{
+			name:        "compile failure",
+			pipelineRun: getFailedPipelineRun("build-container", "Failed", `"step-build" exited with code 1`),
+			want:        BuildFailureCategoryCompile,
+		},
+		{
+			name:        "disruption",
+			pipelineRun: getFailedPipelineRun("build-container", "Failed", "The node was low on resource: memory."),
+			want:        BuildFailureCategoryDisruption,
+		},
+		{
+			name:        "timeout",
+			pipelineRun: timedOutPipelineRun,
+			want:        BuildFailureCategoryTimeout,
+		},
+		{
+			name:        "unknown task failure",
+			pipelineRun: getFailedPipelineRun("show-summary", "Failed", `"step-appstudio-summary" exited with code 1`),
+			want:        BuildFailureCategoryOther,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getBuildFailureCategory(tt.pipelineRun); got != tt.want {
+				t.Errorf("getBuildFailureCategory() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetFailedTask(t *testing.T) {
+	now := time.Now()
+	getTaskRun := func(taskName string, status corev1.ConditionStatus, start *time.Time, completion *time.Time) *tektonapi.PipelineRunTaskRunStatus {
+		taskRun := &tektonapi.PipelineRunTaskRunStatus{
+			PipelineTaskName: taskName,
+			Status: &tektonapi.TaskRunStatus{
+				Status: duckv1beta1.Status{
+					Conditions: duckv1beta1.Conditions{
+						{Type: apis.ConditionSucceeded, Status: status, Message: taskName + " message"},
+					},
+				},
+			},
+		}
+		if start != nil {
+			startTime := metav1.NewTime(*start)
+			taskRun.Status.StartTime = &startTime
+		}
+		if completion != nil {
+			completionTime := metav1.NewTime(*completion)
+			taskRun.Status.CompletionTime = &completionTime
+		}
+		return taskRun
+	}
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+
+	tests := []struct {
+		name     string
+		taskRuns map[string]*tektonapi.PipelineRunTaskRunStatus
+		want     string
+	}{
+		{
+			name: "earliest completed failure",
+			taskRuns: map[string]*tektonapi.PipelineRunTaskRunStatus{
+				"a-taskrun": getTaskRun("sanity-check", corev1.ConditionFalse, at(0), at(3*time.Minute)),
+				"b-taskrun": getTaskRun("build-container", corev1.ConditionFalse, at(0), at(time.Minute)),
+				"c-taskrun": getTaskRun("clone-repository", corev1.ConditionTrue, at(0), at(30*time.Second)),
+			},
+			want: "build-container",
+		},
+		{
+			name: "earliest started failure without completion times",
+			taskRuns: map[string]*tektonapi.PipelineRunTaskRunStatus{
+				"a-taskrun": getTaskRun("sanity-check", corev1.ConditionFalse, at(time.Minute), nil),
+				"b-taskrun": getTaskRun("build-container", corev1.ConditionFalse, at(0), nil),
+			},
+			want: "build-container",
+		},
+		{
+			name: "failures without times go last",
+			taskRuns: map[string]*tektonapi.PipelineRunTaskRunStatus{
+				"a-taskrun": getTaskRun("sanity-check", corev1.ConditionFalse, nil, nil),
+				"b-taskrun": getTaskRun("build-container", corev1.ConditionFalse, at(0), at(time.Minute)),
+			},
+			want: "build-container",
+		},
+		{
+			name: "name order of simultaneous failures",
+			taskRuns: map[string]*tektonapi.PipelineRunTaskRunStatus{
+				"b-taskrun": getTaskRun("build-container", corev1.ConditionFalse, at(0), at(time.Minute)),
+				"a-taskrun": getTaskRun("sanity-check", corev1.ConditionFalse, at(0), at(time.Minute)),
+			},
+			want: "sanity-check",
+		},
+		{
+			name: "no failure",
+			taskRuns: map[string]*tektonapi.PipelineRunTaskRunStatus{
+				"a-taskrun": getTaskRun("build-container", corev1.ConditionTrue, at(0), at(time.Minute)),
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipelineRun := getPipelineRunWithSucceededCondition(corev1.ConditionFalse)
+			pipelineRun.Status.TaskRuns = tt.taskRuns
+			taskName, message := getFailedTask(pipelineRun)
+			if taskName != tt.want {
+				t.Errorf("getFailedTask() task = %v, want %v", taskName, tt.want)
+			}
+			if tt.want != "" && message != tt.want+" message" {
+				t.Errorf("getFailedTask() message = %v, want %v message", message, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// Duration the build logs of the component are kept for, e.g. 72h. It is stamped on the build PipelineRuns
+	// under the same key for log pruners and the PipelineRun retention cleanup keeps the PipelineRuns at least that long.
+	BuildLogRetentionAnnotationName = "build.appstudio.openshift.io/log-retention"
+
+	// MaxBuildLogRetention is the longest allowed build log retention, so PipelineRuns do not pile up in the cluster
+	MaxBuildLogRetention = 90 * 24 * time.Hour
+)
+
+// getBuildLogRetention returns the build log retention requested in the component annotation
+// or the given default retention if there is no annotation, 0 means no retention.
+func getBuildLogRetention(component appstudiov1alpha1.Component, defaultRetention time.Duration) (time.Duration, error) {
+	annotation := component.Annotations[BuildLogRetentionAnnotationName]
+	if annotation == "" {
+		return defaultRetention, nil
+	}
+	retention, err := parseBuildLogRetention(annotation)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s annotation: %v", BuildLogRetentionAnnotationName, err)
+	}
+	return retention, nil
+}
+
+// parseBuildLogRetention parses the positive duration of the build log retention not longer than MaxBuildLogRetention.
+func parseBuildLogRetention(value string) (time.Duration, error) {
+	retention, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if retention <= 0 {
+		return 0, fmt.Errorf("log retention %s must be positive", value)
+	}
+	if retention > MaxBuildLogRetention {
+		return 0, fmt.Errorf("log retention %s exceeds the maximum of %s", value, MaxBuildLogRetention)
+	}
+	return retention, nil
+}
+
+// applyBuildLogRetention stamps the build log retention on the build PipelineRun.
+func applyBuildLogRetention(retention time.Duration, pipelineRun *tektonapi.PipelineRun) {
+	if retention == 0 {
+		return
+	}
+	if pipelineRun.Annotations == nil {
+		pipelineRun.Annotations = map[string]string{}
+	}
+	pipelineRun.Annotations[BuildLogRetentionAnnotationName] = retention.String()
+}
+
+// getPipelineRunRetention returns how long the PipelineRun is kept. Logs are gone with the PipelineRun pods,
+// so the PipelineRun is kept at least for its build log retention.
+func getPipelineRunRetention(pipelineRun tektonapi.PipelineRun, retention time.Duration) time.Duration {
+	logRetention, err := parseBuildLogRetention(pipelineRun.Annotations[BuildLogRetentionAnnotationName])
+	if err != nil || logRetention < retention {
+		return retention
+	}
+	return logRetention
+}
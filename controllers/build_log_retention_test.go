@@ -0,0 +1,95 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+func TestGetBuildLogRetention(t *testing.T) {
+	tests := []struct {
+		name             string
+		annotations      map[string]string
+		defaultRetention time.Duration
+		want             time.Duration
+		wantErr          bool
+	}{
+		{
+			name: "no retention",
+			want: 0,
+		},
+		{
+			name:             "default retention from config",
+			defaultRetention: 24 * time.Hour,
+			want:             24 * time.Hour,
+		},
+		{
+			name:             "component retention takes precedence",
+			annotations:      map[string]string{BuildLogRetentionAnnotationName: "2h"},
+			defaultRetention: 24 * time.Hour,
+			want:             2 * time.Hour,
+		},
+		{
+			name:        "invalid retention",
+			annotations: map[string]string{BuildLogRetentionAnnotationName: "a week"},
+			wantErr:     true,
+		},
+		{
+			name:        "negative retention",
+			annotations: map[string]string{BuildLogRetentionAnnotationName: "-1h"},
+			wantErr:     true,
+		},
+		{
+			name:        "too long retention",
+			annotations: map[string]string{BuildLogRetentionAnnotationName: "8760h"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getBuildLogRetention(getGitSourceComponent(tt.annotations, "version: 2.2.0"), tt.defaultRetention)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getBuildLogRetention() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("getBuildLogRetention() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyBuildLogRetention(t *testing.T) {
+	pipelineRun := tektonapi.PipelineRun{}
+	applyBuildLogRetention(0, &pipelineRun)
+	if _, exists := pipelineRun.Annotations[BuildLogRetentionAnnotationName]; exists {
+		t.Errorf("applyBuildLogRetention() set annotation for no retention")
+	}
+
+	applyBuildLogRetention(72*time.Hour, &pipelineRun)
+	if got := pipelineRun.Annotations[BuildLogRetentionAnnotationName]; got != "72h0m0s" {
+		t.Errorf("applyBuildLogRetention() = %v, want %v", got, "72h0m0s")
+	}
+	if got := getPipelineRunRetention(pipelineRun, time.Hour); got != 72*time.Hour {
+		t.Errorf("getPipelineRunRetention() = %v, want %v", got, 72*time.Hour)
+	}
+	if got := getPipelineRunRetention(pipelineRun, DefaultPipelineRunRetention); got != DefaultPipelineRunRetention {
+		t.Errorf("getPipelineRunRetention() = %v, want %v", got, DefaultPipelineRunRetention)
+	}
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"github.com/go-logr/logr"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+// Phases of the component build logged in the phase field
+const (
+	buildPhaseReconcile = "reconcile"
+	buildPhaseSubmit    = "submit"
+)
+
+// withBuildFields returns the logger with the structured fields identifying the build of the component,
+// so log lines of the same build can be correlated by log aggregation, e.g. with JSON output enabled by --zap-encoder=json.
+func withBuildFields(log logr.Logger, component appstudiov1alpha1.Component, phase string) logr.Logger {
+	return log.WithValues(
+		"component", component.Name,
+		"namespace", component.Namespace,
+		"application", component.Spec.Application,
+		"build-id", getBuildID(component),
+		"phase", phase,
+	)
+}
+
+// getBuildID returns a short identifier of the build spec of the component.
+// All reconciles and the build submission of the same build spec share the identifier.
+func getBuildID(component appstudiov1alpha1.Component) string {
+	return getBuildSpecHash(component)[:buildSpecFieldChecksumLength]
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+// getJSONLogLines returns the JSON log lines written to the buffer by a production zap logger
+func getJSONLogLines(t *testing.T, buffer *bytes.Buffer) []map[string]interface{} {
+	var logLines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		logLine := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &logLine); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", line, err)
+		}
+		logLines = append(logLines, logLine)
+	}
+	return logLines
+}
+
+func assertBuildFields(t *testing.T, logLine map[string]interface{}, component appstudiov1alpha1.Component, phase string) {
+	wantFields := map[string]string{
+		"component":   component.Name,
+		"namespace":   component.Namespace,
+		"application": component.Spec.Application,
+		"build-id":    getBuildID(component),
+		"phase":       phase,
+	}
+	for field, want := range wantFields {
+		if got := logLine[field]; got != want {
+			t.Errorf("log line %v has %s = %v, want %v", logLine, field, got, want)
+		}
+	}
+}
+
+func TestWithBuildFields(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	component := getGitSourceComponent(nil, "version: 2.2.0")
+	component.Spec.Application = "my-application"
+
+	withBuildFields(zap.New(zap.WriteTo(buffer)), component, buildPhaseSubmit).Info("Build submitted")
+
+	logLines := getJSONLogLines(t, buffer)
+	if len(logLines) != 1 {
+		t.Fatalf("withBuildFields() logged %d lines, want 1", len(logLines))
+	}
+	assertBuildFields(t, logLines[0], component, buildPhaseSubmit)
+	if got := logLines[0]["msg"]; got != "Build submitted" {
+		t.Errorf("withBuildFields() logged %v, want %v", got, "Build submitted")
+	}
+}
+
+func TestGetBuildID(t *testing.T) {
+	component := getGitSourceComponent(nil, "version: 2.2.0")
+	buildID := getBuildID(component)
+	if len(buildID) != buildSpecFieldChecksumLength {
+		t.Errorf("getBuildID() = %v, want %d characters", buildID, buildSpecFieldChecksumLength)
+	}
+
+	component.Status.Conditions = nil
+	component.Annotations = map[string]string{"unrelated": "annotation"}
+	if got := getBuildID(component); got != buildID {
+		t.Errorf("getBuildID() = %v, want %v for unchanged build spec", got, buildID)
+	}
+
+	component.Spec.Source.GitSource.URL = "https://github.com/foo/baz"
+	if got := getBuildID(component); got == buildID {
+		t.Errorf("getBuildID() = %v, want a new ID for changed build spec", got)
+	}
+}
+
+// componentClient returns the given component and ignores its updates
+type componentClient struct {
+	client.Client
+	component appstudiov1alpha1.Component
+}
+
+func (c *componentClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	c.component.DeepCopyInto(obj.(*appstudiov1alpha1.Component))
+	return nil
+}
+
+func (c *componentClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return nil
+}
+
+func TestReconcileLogsBuildFields(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	// The component is waiting for its devfile model
+	component := getGitSourceComponent(nil, "")
+	component.Spec.Application = "my-application"
+	r := &ComponentBuildReconciler{
+		Client: &componentClient{component: component},
+		Log:    zap.New(zap.WriteTo(buffer)),
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: component.Name, Namespace: component.Namespace}}
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	logLines := getJSONLogLines(t, buffer)
+	if len(logLines) == 0 {
+		t.Fatalf("Reconcile() logged nothing")
+	}
+	for _, logLine := range logLines {
+		assertBuildFields(t, logLine, component, buildPhaseReconcile)
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// JSON object with names of array pipeline parameters fanned out by Tekton matrix and their values,
+	// e.g. {"platform": ["linux/amd64", "linux/arm64"], "go-version": ["1.17", "1.18"]}
+	BuildMatrixAnnotationName = "build.appstudio.openshift.io/matrix"
+
+	// Maximum number of combinations of the matrix parameters, the same as the Tekton default
+	// of default-max-matrix-combinations-count
+	maxBuildMatrixCombinations = 256
+)
+
+// Tekton parameter names consist of alphanumeric characters, hyphens and underscores and start with a letter or an underscore
+var matrixParamNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// getBuildMatrixParams returns array pipeline parameters the build pipeline fans out its tasks with,
+// or nil if the component has no matrix configured. Each parameter is a dimension of the matrix
+// and the number of combinations of all parameter values is limited.
+func getBuildMatrixParams(component appstudiov1alpha1.Component) ([]tektonapi.Param, error) {
+	matrixJSON := component.Annotations[BuildMatrixAnnotationName]
+	if matrixJSON == "" {
+		return nil, nil
+	}
+
+	matrix := map[string][]string{}
+	if err := json.Unmarshal([]byte(matrixJSON), &matrix); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation, JSON object of string arrays expected: %v", BuildMatrixAnnotationName, err)
+	}
+
+	names := make([]string, 0, len(matrix))
+	for name := range matrix {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := make([]tektonapi.Param, 0, len(names))
+	combinations := 1
+	for _, name := range names {
+		values := matrix[name]
+		if !matrixParamNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf("invalid %s annotation, %q is not a valid parameter name", BuildMatrixAnnotationName, name)
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("invalid %s annotation, parameter %s has no values", BuildMatrixAnnotationName, name)
+		}
+		seen := map[string]bool{}
+		for _, value := range values {
+			if seen[value] {
+				return nil, fmt.Errorf("invalid %s annotation, parameter %s has duplicate value %q", BuildMatrixAnnotationName, name, value)
+			}
+			seen[value] = true
+		}
+
+		// Checked for each dimension, so the product cannot overflow
+		combinations *= len(values)
+		if combinations > maxBuildMatrixCombinations {
+			return nil, fmt.Errorf("invalid %s annotation, the matrix exceeds the limit of %d combinations", BuildMatrixAnnotationName, maxBuildMatrixCombinations)
+		}
+
+		params = append(params, tektonapi.Param{
+			Name: name,
+			Value: tektonapi.ArrayOrString{
+				Type:     tektonapi.ParamTypeArray,
+				ArrayVal: values,
+			},
+		})
+	}
+	return params, nil
+}
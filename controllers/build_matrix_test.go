@@ -0,0 +1,120 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+func getArrayParam(name string, values ...string) tektonapi.Param {
+	return tektonapi.Param{Name: name, Value: tektonapi.ArrayOrString{Type: tektonapi.ParamTypeArray, ArrayVal: values}}
+}
+
+func TestGetBuildMatrixParams(t *testing.T) {
+	// 3 dimensions with 7 values each have 343 combinations
+	largeMatrix := map[string][]string{}
+	for _, name := range []string{"a", "b", "c"} {
+		for i := 0; i < 7; i++ {
+			largeMatrix[name] = append(largeMatrix[name], strconv.Itoa(i))
+		}
+	}
+	largeMatrixJSON, _ := json.Marshal(largeMatrix)
+
+	tests := []struct {
+		name    string
+		matrix  string
+		want    []tektonapi.Param
+		wantErr bool
+	}{
+		{
+			name: "matrix is not configured",
+		},
+		{
+			name:   "parameters are sorted by name",
+			matrix: `{"platform": ["linux/amd64", "linux/arm64"], "go-version": ["1.17"]}`,
+			want: []tektonapi.Param{
+				getArrayParam("go-version", "1.17"),
+				getArrayParam("platform", "linux/amd64", "linux/arm64"),
+			},
+		},
+		{
+			name:   "matrix at the combinations limit",
+			matrix: `{"a": ["0", "1", "2", "3"], "b": ["0", "1", "2", "3"], "c": ["0", "1", "2", "3"], "d": ["0", "1", "2", "3"]}`,
+			want: []tektonapi.Param{
+				getArrayParam("a", "0", "1", "2", "3"),
+				getArrayParam("b", "0", "1", "2", "3"),
+				getArrayParam("c", "0", "1", "2", "3"),
+				getArrayParam("d", "0", "1", "2", "3"),
+			},
+		},
+		{
+			name:    "matrix over the combinations limit",
+			matrix:  string(largeMatrixJSON),
+			wantErr: true,
+		},
+		{
+			name:    "parameter without values",
+			matrix:  `{"platform": []}`,
+			wantErr: true,
+		},
+		{
+			name:    "duplicate parameter value",
+			matrix:  `{"platform": ["linux/amd64", "linux/amd64"]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid parameter name",
+			matrix:  `{"go version": ["1.17"]}`,
+			wantErr: true,
+		},
+		{
+			name:    "string parameter value",
+			matrix:  `{"platform": "linux/amd64"}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations := map[string]string{}
+			if tt.matrix != "" {
+				annotations[BuildMatrixAnnotationName] = tt.matrix
+			}
+			got, err := getBuildMatrixParams(getGitSourceComponent(annotations, ""))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getBuildMatrixParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && len(got)+len(tt.want) > 0 && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getBuildMatrixParams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildMatrixChangeRequiresBuild(t *testing.T) {
+	component := getGitSourceComponent(map[string]string{BuildMatrixAnnotationName: `{"platform": ["linux/amd64"]}`}, "version: 2.2.0")
+	setBuildSpecHash(&component)
+
+	component.Annotations[BuildMatrixAnnotationName] = `{"platform": ["linux/amd64", "linux/arm64"]}`
+	if !isBuildSpecChanged(component) {
+		t.Errorf("isBuildSpecChanged() = false after matrix change, want true")
+	}
+}
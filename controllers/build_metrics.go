@@ -0,0 +1,108 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// Set on build PipelineRuns whose duration has been recorded in the metrics
+	BuildDurationRecordedAnnotationName = "build.appstudio.openshift.io/duration-recorded"
+
+	// Pipeline types for pipelines which are not known build pipelines
+	pipelineTypeInline = "inline"
+	pipelineTypeOther  = "other"
+)
+
+// knownPipelineTypes maps build pipeline names to the pipeline type label values.
+// Unknown pipelines are reported as other to keep the metric cardinality bounded.
+var knownPipelineTypes = map[string]string{
+	"docker-build":   "docker",
+	"java-builder":   "java",
+	"nodejs-builder": "nodejs",
+	"noop":           "noop",
+}
+
+var buildDurationSeconds = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "build_service_build_duration_seconds",
+		Help:    "Duration of completed component builds by build pipeline type.",
+		Buckets: []float64{30, 60, 120, 180, 300, 450, 600, 900, 1200, 1800, 3600},
+	},
+	[]string{"pipeline_type", "state"},
+)
+
+var buildFailuresTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "build_service_build_failures_total",
+		Help: "Number of failed component builds by failure category.",
+	},
+	[]string{"category"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(buildDurationSeconds, buildFailuresTotal, componentsByBuildPhase)
+}
+
+// recordBuildDuration observes duration of the completed build once.
+// The PipelineRun is annotated to not record it again after controller restart.
+// The given PipelineRun is updated in place, so subsequent updates of it do not conflict.
+func (r *BuildPipelineRunReconciler) recordBuildDuration(ctx context.Context, pipelineRun *tektonapi.PipelineRun) error {
+	state := getBuildState(*pipelineRun)
+	if state != BuildStateSucceeded && state != BuildStateFailed {
+		return nil
+	}
+	if pipelineRun.Annotations[BuildDurationRecordedAnnotationName] == "true" {
+		return nil
+	}
+	if pipelineRun.Status.StartTime == nil || pipelineRun.Status.CompletionTime == nil {
+		return nil
+	}
+
+	if pipelineRun.Annotations == nil {
+		pipelineRun.Annotations = map[string]string{}
+	}
+	pipelineRun.Annotations[BuildDurationRecordedAnnotationName] = "true"
+	if err := r.Client.Update(ctx, pipelineRun); err != nil {
+		return err
+	}
+
+	observeBuildDuration(*pipelineRun)
+	return nil
+}
+
+func observeBuildDuration(pipelineRun tektonapi.PipelineRun) {
+	duration := pipelineRun.Status.CompletionTime.Sub(pipelineRun.Status.StartTime.Time)
+	buildDurationSeconds.WithLabelValues(getPipelineType(pipelineRun), getBuildState(pipelineRun)).Observe(duration.Seconds())
+}
+
+// getPipelineType returns normalized type of the build pipeline.
+func getPipelineType(pipelineRun tektonapi.PipelineRun) string {
+	if pipelineRun.Spec.PipelineRef == nil {
+		return pipelineTypeInline
+	}
+	if pipelineType, isKnown := knownPipelineTypes[strings.ToLower(pipelineRun.Spec.PipelineRef.Name)]; isKnown {
+		return pipelineType
+	}
+	return pipelineTypeOther
+}
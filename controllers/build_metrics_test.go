@@ -0,0 +1,99 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetPipelineType(t *testing.T) {
+	tests := []struct {
+		name        string
+		pipelineRef *tektonapi.PipelineRef
+		want        string
+	}{
+		{
+			name:        "docker build",
+			pipelineRef: &tektonapi.PipelineRef{Name: "docker-build", Bundle: "quay.io/redhat-appstudio/build-templates-bundle:v0.1"},
+			want:        "docker",
+		},
+		{
+			name:        "java builder",
+			pipelineRef: &tektonapi.PipelineRef{Name: "java-builder"},
+			want:        "java",
+		},
+		{
+			name:        "unknown pipeline",
+			pipelineRef: &tektonapi.PipelineRef{Name: "my-custom-pipeline-1234"},
+			want:        pipelineTypeOther,
+		},
+		{
+			name:        "inline pipeline",
+			pipelineRef: nil,
+			want:        pipelineTypeInline,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipelineRun := tektonapi.PipelineRun{}
+			pipelineRun.Spec.PipelineRef = tt.pipelineRef
+			if got := getPipelineType(pipelineRun); got != tt.want {
+				t.Errorf("getPipelineType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func getBuildDurationHistogram(t *testing.T, pipelineType string, state string) *dto.Histogram {
+	metric := &dto.Metric{}
+	if err := buildDurationSeconds.WithLabelValues(pipelineType, state).(prometheus.Histogram).Write(metric); err != nil {
+		t.Fatalf("failed to read build duration metric: %v", err)
+	}
+	return metric.GetHistogram()
+}
+
+func TestObserveBuildDuration(t *testing.T) {
+	startTime := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	pipelineRun := getPipelineRunWithSucceededCondition(corev1.ConditionTrue)
+	pipelineRun.Spec.PipelineRef = &tektonapi.PipelineRef{Name: "docker-build"}
+	pipelineRun.Status.StartTime = &metav1.Time{Time: startTime}
+	pipelineRun.Status.CompletionTime = &metav1.Time{Time: startTime.Add(5 * time.Minute)}
+
+	dockerBefore := getBuildDurationHistogram(t, "docker", BuildStateSucceeded)
+	javaBefore := getBuildDurationHistogram(t, "java", BuildStateSucceeded)
+
+	observeBuildDuration(pipelineRun)
+
+	dockerAfter := getBuildDurationHistogram(t, "docker", BuildStateSucceeded)
+	if dockerAfter.GetSampleCount() != dockerBefore.GetSampleCount()+1 {
+		t.Errorf("observeBuildDuration() sample count = %d, want %d", dockerAfter.GetSampleCount(), dockerBefore.GetSampleCount()+1)
+	}
+	if dockerAfter.GetSampleSum()-dockerBefore.GetSampleSum() != 300 {
+		t.Errorf("observeBuildDuration() observed %v seconds, want 300", dockerAfter.GetSampleSum()-dockerBefore.GetSampleSum())
+	}
+	if javaAfter := getBuildDurationHistogram(t, "java", BuildStateSucceeded); javaAfter.GetSampleCount() != javaBefore.GetSampleCount() {
+		t.Errorf("observeBuildDuration() recorded observation with wrong pipeline type")
+	}
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// Namespace the build PipelineRun of the component is created in instead of the component namespace.
+	// The namespace has to provide the pipeline Service Account and the Secrets used by the build.
+	BuildNamespaceAnnotationName = "build.appstudio.openshift.io/build-namespace"
+	// Label with the namespace of the component built by a PipelineRun in another namespace
+	ComponentNamespaceLabelName = "build.appstudio.openshift.io/component-namespace"
+	// Annotation of the build namespace with comma separated namespaces whose components are allowed to build in it
+	AllowedComponentNamespacesAnnotationName = "build.appstudio.openshift.io/allowed-component-namespaces"
+
+	// BuildNamespaceForbiddenConditionType is set on components whose build cannot be created in the build namespace
+	// because the build namespace does not allow builds of components from the component namespace
+	// or it misses resources the build uses
+	BuildNamespaceForbiddenConditionType = "BuildNamespaceForbidden"
+
+	BuildNamespaceForbiddenReasonForbidden = "PipelineRunCreationForbidden"
+	BuildNamespaceForbiddenReasonAllowed   = "PipelineRunCreationAllowed"
+)
+
+// getBuildNamespace returns the namespace the build PipelineRun of the component is created in.
+func getBuildNamespace(component appstudiov1alpha1.Component) (string, error) {
+	buildNamespace := component.Annotations[BuildNamespaceAnnotationName]
+	if buildNamespace == "" {
+		return component.Namespace, nil
+	}
+	if errs := validation.IsDNS1123Label(buildNamespace); len(errs) > 0 {
+		return "", fmt.Errorf("invalid %s annotation, %s is not a valid namespace name: %s", BuildNamespaceAnnotationName, buildNamespace, strings.Join(errs, ", "))
+	}
+	return buildNamespace, nil
+}
+
+// applyBuildNamespace moves the build PipelineRun of the component into the given namespace.
+// PipelineRuns in another namespace are labeled with the namespace of the component, as owner references
+// cannot point across namespaces.
+func applyBuildNamespace(component appstudiov1alpha1.Component, buildNamespace string, build *tektonapi.PipelineRun) {
+	build.Namespace = buildNamespace
+	if buildNamespace == component.Namespace {
+		return
+	}
+	if build.Labels == nil {
+		build.Labels = map[string]string{}
+	}
+	build.Labels[ComponentNamespaceLabelName] = component.Namespace
+}
+
+// getPipelineRunComponentNamespace returns the namespace of the component built or checked by the PipelineRun.
+// The component namespace label can be set by anybody allowed to create PipelineRuns in the PipelineRun namespace,
+// so it has to be confirmed by isTrustedComponentPipelineRun before the PipelineRun is attributed to the component.
+func getPipelineRunComponentNamespace(pipelineRun tektonapi.PipelineRun) string {
+	if componentNamespace := pipelineRun.Labels[ComponentNamespaceLabelName]; componentNamespace != "" {
+		return componentNamespace
+	}
+	return pipelineRun.Namespace
+}
+
+// isTrustedComponentPipelineRun checks whether the PipelineRun labeled with the component can be attributed to it.
+// PipelineRuns without the component namespace label are in the component namespace and are trusted.
+// PipelineRuns in another namespace are trusted only if it is the build namespace of the component
+// and it allows builds of components from the component namespace.
+func isTrustedComponentPipelineRun(ctx context.Context, cli client.Client, pipelineRun tektonapi.PipelineRun, component appstudiov1alpha1.Component) (bool, error) {
+	if getPipelineRunComponentNamespace(pipelineRun) == pipelineRun.Namespace {
+		return true, nil
+	}
+	if component.Annotations[BuildNamespaceAnnotationName] != pipelineRun.Namespace {
+		return false, nil
+	}
+	allowed, _, err := isBuildNamespaceAllowed(ctx, cli, pipelineRun.Namespace, component.Namespace)
+	return allowed, err
+}
+
+// getPipelineRunComponent returns the component with the given name built or checked by the PipelineRun.
+// False is returned if the component doesn't exist or the PipelineRun cannot be attributed to it.
+func getPipelineRunComponent(ctx context.Context, cli client.Client, pipelineRun tektonapi.PipelineRun, componentName string) (appstudiov1alpha1.Component, bool, error) {
+	var component appstudiov1alpha1.Component
+	componentKey := types.NamespacedName{Name: componentName, Namespace: getPipelineRunComponentNamespace(pipelineRun)}
+	if err := cli.Get(ctx, componentKey, &component); err != nil {
+		if errors.IsNotFound(err) {
+			return component, false, nil
+		}
+		return component, false, err
+	}
+	trusted, err := isTrustedComponentPipelineRun(ctx, cli, pipelineRun, component)
+	return component, trusted, err
+}
+
+// isBuildNamespaceAllowed checks whether the build namespace allows builds of components from the given namespace.
+// The controller can create PipelineRuns in any namespace, so the owner of the build namespace has to opt in
+// by listing the component namespace in the allowed component namespaces annotation.
+// The reason of the denial is returned if the builds are not allowed.
+func isBuildNamespaceAllowed(ctx context.Context, cli client.Client, buildNamespace string, componentNamespace string) (bool, string, error) {
+	namespace := &corev1.Namespace{}
+	if err := cli.Get(ctx, types.NamespacedName{Name: buildNamespace}, namespace); err != nil {
+		if errors.IsNotFound(err) {
+			return false, "the namespace does not exist", nil
+		}
+		return false, "", err
+	}
+	for _, allowedNamespace := range strings.Split(namespace.Annotations[AllowedComponentNamespacesAnnotationName], ",") {
+		if strings.TrimSpace(allowedNamespace) == componentNamespace {
+			return true, "", nil
+		}
+	}
+	return false, fmt.Sprintf("namespace %s is not listed in its %s annotation", componentNamespace, AllowedComponentNamespacesAnnotationName), nil
+}
+
+// resolveBuildNamespace returns the namespace the build PipelineRun of the component is created in.
+// The controller doesn't copy credentials across namespaces, so another build namespace than the component namespace
+// has to provide the pipeline Service Account and the Secrets the build of the component uses under the same names,
+// besides allowing builds of components from the component namespace.
+// Otherwise the BuildNamespaceForbidden condition is set and an error is returned, so the build is retried with backoff.
+func (r *ComponentBuildReconciler) resolveBuildNamespace(ctx context.Context, component appstudiov1alpha1.Component) (string, error) {
+	buildNamespace, err := getBuildNamespace(component)
+	if err != nil || buildNamespace == component.Namespace {
+		return buildNamespace, err
+	}
+
+	allowed, reason, err := isBuildNamespaceAllowed(ctx, r.Client, buildNamespace, component.Namespace)
+	if err != nil {
+		return "", err
+	}
+	if allowed {
+		missingResources, err := r.getMissingBuildNamespaceResources(ctx, component, buildNamespace)
+		if err != nil {
+			return "", err
+		}
+		if len(missingResources) == 0 {
+			return buildNamespace, nil
+		}
+		reason = fmt.Sprintf("the namespace doesn't contain %s", strings.Join(missingResources, ", "))
+	}
+
+	condition := getBuildNamespaceForbiddenCondition(buildNamespace, reason)
+	if err := setComponentCondition(ctx, r.Client, component, condition); err != nil {
+		return "", err
+	}
+	// Retried with backoff, the build namespace might allow the builds later
+	return "", fmt.Errorf("%s", condition.Message)
+}
+
+// getMissingBuildNamespaceResources returns the resources used by the build of the component which are missing in the build namespace.
+func (r *ComponentBuildReconciler) getMissingBuildNamespaceResources(ctx context.Context, component appstudiov1alpha1.Component, buildNamespace string) ([]string, error) {
+	var missingResources []string
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: "pipeline", Namespace: buildNamespace}, &corev1.ServiceAccount{}); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		missingResources = append(missingResources, "Service Account pipeline")
+	}
+
+	var secretNames []string
+	if component.Spec.Secret != "" {
+		secretNames = append(secretNames, component.Spec.Secret)
+	}
+	if knownHostsSecretName := component.Annotations[SSHKnownHostsSecretAnnotationName]; knownHostsSecretName != "" {
+		secretNames = append(secretNames, knownHostsSecretName)
+	}
+	if isGHCRBuild(component) {
+		ghcrSecretName := component.Annotations[RegistrySecretAnnotationName]
+		if ghcrSecretName == "" {
+			ghcrSecretName = defaultGHCRSecretName
+		}
+		secretNames = append(secretNames, ghcrSecretName)
+	}
+	for _, secretName := range secretNames {
+		if err := r.NonCachingClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: buildNamespace}, &corev1.Secret{}); err != nil {
+			if !errors.IsNotFound(err) {
+				return nil, err
+			}
+			missingResources = append(missingResources, "Secret "+secretName)
+		}
+	}
+	return missingResources, nil
+}
+
+func getBuildNamespaceForbiddenCondition(buildNamespace string, reason string) metav1.Condition {
+	message := fmt.Sprintf("The component is not allowed to build in namespace %s", buildNamespace)
+	if reason != "" {
+		message += ": " + reason
+	}
+	return metav1.Condition{
+		Type:    BuildNamespaceForbiddenConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  BuildNamespaceForbiddenReasonForbidden,
+		Message: message,
+	}
+}
+
+// clearBuildNamespaceForbiddenCondition marks the build of the component, which could not be created in the build namespace, as submitted.
+func clearBuildNamespaceForbiddenCondition(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) error {
+	if !meta.IsStatusConditionTrue(component.Status.Conditions, BuildNamespaceForbiddenConditionType) {
+		return nil
+	}
+	return setComponentCondition(ctx, cli, component, metav1.Condition{
+		Type:    BuildNamespaceForbiddenConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  BuildNamespaceForbiddenReasonAllowed,
+		Message: "The build has been submitted in the build namespace",
+	})
+}
@@ -0,0 +1,294 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// namespaceClient returns the given namespaces
+type namespaceClient struct {
+	client.Client
+	namespaces map[string]corev1.Namespace
+}
+
+func (c *namespaceClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	namespace, ok := c.namespaces[key.Name]
+	if !ok {
+		return errors.NewNotFound(corev1.Resource("namespaces"), key.Name)
+	}
+	namespace.DeepCopyInto(obj.(*corev1.Namespace))
+	return nil
+}
+
+func TestGetBuildNamespace(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        string
+		wantErr     bool
+	}{
+		{
+			name: "component namespace by default",
+			want: "my-namespace",
+		},
+		{
+			name:        "build namespace from annotation",
+			annotations: map[string]string{BuildNamespaceAnnotationName: "builds"},
+			want:        "builds",
+		},
+		{
+			name:        "invalid build namespace",
+			annotations: map[string]string{BuildNamespaceAnnotationName: "Builds_Namespace"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := getGitSourceComponent(tt.annotations, "")
+			component.Namespace = "my-namespace"
+			got, err := getBuildNamespace(component)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getBuildNamespace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("getBuildNamespace() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyBuildNamespace(t *testing.T) {
+	component := getGitSourceComponent(nil, "")
+	component.Namespace = "my-namespace"
+
+	build := tektonapi.PipelineRun{}
+	applyBuildNamespace(component, "my-namespace", &build)
+	if build.Namespace != "my-namespace" || getPipelineRunComponentNamespace(build) != "my-namespace" {
+		t.Errorf("applyBuildNamespace() = %v, want build in the component namespace", build.ObjectMeta)
+	}
+	if _, labeled := build.Labels[ComponentNamespaceLabelName]; labeled {
+		t.Errorf("applyBuildNamespace() labeled build in the component namespace")
+	}
+
+	build = tektonapi.PipelineRun{}
+	applyBuildNamespace(component, "builds", &build)
+	if build.Namespace != "builds" || getPipelineRunComponentNamespace(build) != "my-namespace" {
+		t.Errorf("applyBuildNamespace() = %v, want build in namespace builds of component in my-namespace", build.ObjectMeta)
+	}
+}
+
+func TestIsBuildNamespaceAllowed(t *testing.T) {
+	cli := &namespaceClient{namespaces: map[string]corev1.Namespace{
+		"builds": {ObjectMeta: metav1.ObjectMeta{
+			Name:        "builds",
+			Annotations: map[string]string{AllowedComponentNamespacesAnnotationName: "other-namespace, my-namespace"},
+		}},
+		"kube-system": {ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+	}}
+
+	tests := []struct {
+		name           string
+		buildNamespace string
+		want           bool
+	}{
+		{
+			name:           "component namespace allowed by build namespace",
+			buildNamespace: "builds",
+			want:           true,
+		},
+		{
+			name:           "build namespace without allowed namespaces",
+			buildNamespace: "kube-system",
+			want:           false,
+		},
+		{
+			name:           "missing build namespace",
+			buildNamespace: "missing",
+			want:           false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, reason, err := isBuildNamespaceAllowed(context.TODO(), cli, tt.buildNamespace, "my-namespace")
+			if err != nil {
+				t.Fatalf("isBuildNamespaceAllowed() error = %v", err)
+			}
+			if allowed != tt.want {
+				t.Errorf("isBuildNamespaceAllowed() = %v, want %v", allowed, tt.want)
+			}
+			if !allowed && reason == "" {
+				t.Errorf("isBuildNamespaceAllowed() returned no reason of the denial")
+			}
+		})
+	}
+
+	if allowed, _, _ := isBuildNamespaceAllowed(context.TODO(), cli, "builds", "namespace"); allowed {
+		t.Errorf("isBuildNamespaceAllowed() allowed namespace matching only a part of an allowed namespace")
+	}
+}
+
+func TestIsTrustedComponentPipelineRun(t *testing.T) {
+	cli := &namespaceClient{namespaces: map[string]corev1.Namespace{
+		"builds": {ObjectMeta: metav1.ObjectMeta{
+			Name:        "builds",
+			Annotations: map[string]string{AllowedComponentNamespacesAnnotationName: "my-namespace"},
+		}},
+		"tenant": {ObjectMeta: metav1.ObjectMeta{Name: "tenant"}},
+	}}
+
+	tests := []struct {
+		name                 string
+		pipelineRunNamespace string
+		componentNamespace   string
+		buildNamespace       string
+		want                 bool
+	}{
+		{
+			name:                 "PipelineRun in the component namespace",
+			pipelineRunNamespace: "my-namespace",
+			want:                 true,
+		},
+		{
+			name:                 "PipelineRun in the build namespace of the component",
+			pipelineRunNamespace: "builds",
+			componentNamespace:   "my-namespace",
+			buildNamespace:       "builds",
+			want:                 true,
+		},
+		{
+			name:                 "PipelineRun in another namespace than the build namespace of the component",
+			pipelineRunNamespace: "tenant",
+			componentNamespace:   "my-namespace",
+			buildNamespace:       "builds",
+			want:                 false,
+		},
+		{
+			name:                 "PipelineRun in another namespace of component without build namespace",
+			pipelineRunNamespace: "builds",
+			componentNamespace:   "my-namespace",
+			want:                 false,
+		},
+		{
+			name:                 "build namespace not allowing builds of the component namespace",
+			pipelineRunNamespace: "tenant",
+			componentNamespace:   "my-namespace",
+			buildNamespace:       "tenant",
+			want:                 false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := getGitSourceComponent(nil, "")
+			component.Namespace = "my-namespace"
+			if tt.buildNamespace != "" {
+				component.Annotations = map[string]string{BuildNamespaceAnnotationName: tt.buildNamespace}
+			}
+			pipelineRun := tektonapi.PipelineRun{}
+			pipelineRun.Namespace = tt.pipelineRunNamespace
+			if tt.componentNamespace != "" {
+				pipelineRun.Labels = map[string]string{ComponentNamespaceLabelName: tt.componentNamespace}
+			}
+
+			got, err := isTrustedComponentPipelineRun(context.TODO(), cli, pipelineRun, component)
+			if err != nil {
+				t.Fatalf("isTrustedComponentPipelineRun() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("isTrustedComponentPipelineRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// buildNamespaceResourcesClient returns the given Service Accounts and Secrets, keyed by namespace/name
+type buildNamespaceResourcesClient struct {
+	client.Client
+	serviceAccounts map[string]bool
+	secrets         map[string]bool
+}
+
+func (c *buildNamespaceResourcesClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	switch obj.(type) {
+	case *corev1.ServiceAccount:
+		if !c.serviceAccounts[key.String()] {
+			return errors.NewNotFound(corev1.Resource("serviceaccounts"), key.Name)
+		}
+	case *corev1.Secret:
+		if !c.secrets[key.String()] {
+			return errors.NewNotFound(corev1.Resource("secrets"), key.Name)
+		}
+	}
+	return nil
+}
+
+func TestGetMissingBuildNamespaceResources(t *testing.T) {
+	tests := []struct {
+		name            string
+		annotations     map[string]string
+		gitSecret       string
+		serviceAccounts map[string]bool
+		secrets         map[string]bool
+		want            []string
+	}{
+		{
+			name:            "all resources in the build namespace",
+			annotations:     map[string]string{SSHKnownHostsSecretAnnotationName: "known-hosts"},
+			gitSecret:       "git-secret",
+			serviceAccounts: map[string]bool{"builds/pipeline": true},
+			secrets:         map[string]bool{"builds/git-secret": true, "builds/known-hosts": true},
+		},
+		{
+			name:            "Secrets only in the component namespace",
+			annotations:     map[string]string{SSHKnownHostsSecretAnnotationName: "known-hosts"},
+			gitSecret:       "git-secret",
+			serviceAccounts: map[string]bool{"builds/pipeline": true},
+			secrets:         map[string]bool{"my-namespace/git-secret": true, "my-namespace/known-hosts": true},
+			want:            []string{"Secret git-secret", "Secret known-hosts"},
+		},
+		{
+			name:            "missing Service Account and GitHub Packages credentials",
+			annotations:     map[string]string{RegistryAnnotationName: RegistryGHCR},
+			serviceAccounts: map[string]bool{"my-namespace/pipeline": true},
+			want:            []string{"Service Account pipeline", "Secret " + defaultGHCRSecretName},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := &buildNamespaceResourcesClient{serviceAccounts: tt.serviceAccounts, secrets: tt.secrets}
+			r := &ComponentBuildReconciler{Client: cli, NonCachingClient: cli}
+			component := getGitSourceComponent(tt.annotations, "")
+			component.Spec.Secret = tt.gitSecret
+
+			got, err := r.getMissingBuildNamespaceResources(context.TODO(), component, "builds")
+			if err != nil {
+				t.Fatalf("getMissingBuildNamespaceResources() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getMissingBuildNamespaceResources() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
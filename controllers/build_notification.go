@@ -0,0 +1,101 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// FirstBuildSucceededConditionType is set on the component once its first successful build has been notified
+	FirstBuildSucceededConditionType = "FirstBuildSucceeded"
+
+	FirstBuildSucceededReason = "FirstBuildSucceeded"
+)
+
+// BuildNotifier sends notifications about component builds.
+type BuildNotifier interface {
+	Notify(ctx context.Context, summary BuildSummary) error
+}
+
+// notifyFirstSuccessfulBuild sends the notification if the PipelineRun is the first successful build of the component.
+// The notification is recorded in the component status condition to suppress notifications of subsequent builds.
+func (r *BuildPipelineRunReconciler) notifyFirstSuccessfulBuild(ctx context.Context, component appstudiov1alpha1.Component, pipelineRun tektonapi.PipelineRun) error {
+	if getBuildState(pipelineRun) != BuildStateSucceeded || isFirstSuccessfulBuildNotified(component) {
+		return nil
+	}
+
+	if err := r.Notifier.Notify(ctx, getBuildSummary(component, pipelineRun)); err != nil {
+		return err
+	}
+
+	return setComponentCondition(ctx, r.Client, component, metav1.Condition{
+		Type:    FirstBuildSucceededConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  FirstBuildSucceededReason,
+		Message: fmt.Sprintf("The first successful build is %s", pipelineRun.Name),
+	})
+}
+
+// isFirstSuccessfulBuildNotified checks whether the notification about the first successful build has been sent.
+func isFirstSuccessfulBuildNotified(component appstudiov1alpha1.Component) bool {
+	return meta.IsStatusConditionTrue(component.Status.Conditions, FirstBuildSucceededConditionType)
+}
+
+// WebhookBuildNotifier posts the build summary JSON to the configured URL.
+type WebhookBuildNotifier struct {
+	URL string
+	// HTTPClient posts the summaries, nil means the shared client with a timeout
+	HTTPClient *http.Client
+}
+
+func (n *WebhookBuildNotifier) Notify(ctx context.Context, summary BuildSummary) error {
+	body, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := n.HTTPClient
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("build notification webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+func TestIsFirstSuccessfulBuildNotified(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []metav1.Condition
+		want       bool
+	}{
+		{
+			name: "no conditions",
+			want: false,
+		},
+		{
+			name: "notified",
+			conditions: []metav1.Condition{
+				{Type: "Created", Status: metav1.ConditionTrue},
+				{Type: FirstBuildSucceededConditionType, Status: metav1.ConditionTrue},
+			},
+			want: true,
+		},
+		{
+			name: "other conditions only",
+			conditions: []metav1.Condition{
+				{Type: "Created", Status: metav1.ConditionTrue},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := appstudiov1alpha1.Component{}
+			component.Status.Conditions = tt.conditions
+			if got := isFirstSuccessfulBuildNotified(component); got != tt.want {
+				t.Errorf("isFirstSuccessfulBuildNotified() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookBuildNotifier(t *testing.T) {
+	var received []BuildSummary
+	responseStatus := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		summary := BuildSummary{}
+		if req.Method != http.MethodPost || json.NewDecoder(req.Body).Decode(&summary) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, summary)
+		w.WriteHeader(responseStatus)
+	}))
+	defer server.Close()
+
+	notifier := &WebhookBuildNotifier{URL: server.URL}
+	summary := BuildSummary{Component: "my-component", PipelineRun: "my-component-abcde", State: BuildStateSucceeded}
+	if err := notifier.Notify(context.TODO(), summary); err != nil {
+		t.Errorf("Notify() error = %v", err)
+	}
+	if len(received) != 1 || received[0].Component != summary.Component || received[0].PipelineRun != summary.PipelineRun {
+		t.Errorf("Notify() sent %v, want %v", received, summary)
+	}
+
+	responseStatus = http.StatusInternalServerError
+	if err := notifier.Notify(context.TODO(), summary); err == nil {
+		t.Errorf("Notify() expected error on webhook failure")
+	}
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// BuildPipelineRunReconciler watches build PipelineRuns of AppStudio Components in order to track build progress
+type BuildPipelineRunReconciler struct {
+	Client           client.Client
+	NonCachingClient client.Client
+	Scheme           *runtime.Scheme
+	Log              logr.Logger
+	// BuildSummaryEnabled turns on maintaining of the build summary ConfigMap for each component
+	BuildSummaryEnabled bool
+	// Notifier is used to notify about the first successful build of each component, nil disables notifications
+	Notifier BuildNotifier
+	// GitStatusReporter reports outcomes of completed builds as git commit statuses, nil disables the reports
+	GitStatusReporter GitStatusReporter
+	// GitStatusSecretName is the name of the Secret in each namespace with the git provider token, empty means DefaultGitStatusSecretName
+	GitStatusSecretName string
+	// RetryableFailureMessages are additional lowercase fragments of failure messages which mark build failures as transient,
+	// so the build is retried. Builds failed because of cluster disruptions or common network failures are retried always
+	RetryableFailureMessages []string
+	// SlackNotifier posts build failures to Slack webhooks set in annotations of Components or their namespaces,
+	// nil disables the notifications
+	SlackNotifier *SlackBuildFailureNotifier
+	// BuildProvenanceEnabled turns on recording of the provenance attestation reference of the latest successful build
+	// in the BuildProvenance condition of the component
+	BuildProvenanceEnabled bool
+	// SignatureVerificationKey is the public key or KMS URI signed images of successful builds are verified with
+	// in a follow-up PipelineRun. Empty value disables the verification.
+	SignatureVerificationKey string
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BuildPipelineRunReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&tektonapi.PipelineRun{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
+			// Watch only PipelineRuns that build a component, scan its image or verify the image signature
+			_, isComponentBuild := object.GetLabels()[ComponentNameLabelName]
+			_, isImageScan := object.GetLabels()[ImageScanComponentLabelName]
+			_, isSignatureVerification := object.GetLabels()[SignatureVerificationComponentLabelName]
+			return isComponentBuild || isImageScan || isSignatureVerification
+		}))).
+		Complete(r)
+}
+
+//+kubebuilder:rbac:groups=tekton.dev,resources=pipelineruns,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch
+
+// Reconcile updates build related data of the component the PipelineRun belongs to.
+func (r *BuildPipelineRunReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("PipelineRun", req.NamespacedName)
+
+	var pipelineRun tektonapi.PipelineRun
+	if err := r.Client.Get(ctx, req.NamespacedName, &pipelineRun); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !pipelineRun.DeletionTimestamp.IsZero() {
+		if err := r.handleBuildDeletion(ctx, pipelineRun); err != nil {
+			log.Error(err, fmt.Sprintf("Failed to handle deletion of build %s", pipelineRun.Name))
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	componentName, isImageScan := pipelineRun.Labels[ImageScanComponentLabelName]
+	verifiedComponentName, isSignatureVerification := pipelineRun.Labels[SignatureVerificationComponentLabelName]
+	if isSignatureVerification {
+		componentName = verifiedComponentName
+	} else if !isImageScan {
+		componentName = pipelineRun.Labels[ComponentNameLabelName]
+	}
+
+	componentKey := types.NamespacedName{Name: componentName, Namespace: getPipelineRunComponentNamespace(pipelineRun)}
+	component, found, err := getPipelineRunComponent(ctx, r.Client, pipelineRun, componentName)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !found {
+		// The component has been deleted and its PipelineRuns will be garbage collected,
+		// or the PipelineRun is in another namespace which is not the build namespace of the component
+		return ctrl.Result{}, nil
+	}
+	if isStalePipelineRun(pipelineRun, component) {
+		return ctrl.Result{}, nil
+	}
+
+	if isImageScan {
+		if err := r.updateImageScanResult(ctx, component, pipelineRun); err != nil {
+			log.Error(err, fmt.Sprintf("Failed to update image scan result of component %v", componentKey))
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+	if isSignatureVerification {
+		if err := r.updateSignatureVerificationResult(ctx, component, pipelineRun); err != nil {
+			log.Error(err, fmt.Sprintf("Failed to update signature verification result of component %v", componentKey))
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.recordBuildDuration(ctx, &pipelineRun); err != nil {
+		log.Error(err, fmt.Sprintf("Failed to record duration of build %s", pipelineRun.Name))
+		return ctrl.Result{}, err
+	}
+
+	_, isFailureRecorded := pipelineRun.Annotations[BuildFailureCategoryAnnotationName]
+	if err := r.recordBuildFailure(ctx, component, &pipelineRun); err != nil {
+		log.Error(err, fmt.Sprintf("Failed to record build failure of component %v", componentKey))
+		return ctrl.Result{}, err
+	}
+	var result ctrl.Result
+	_, isFailureNew := pipelineRun.Annotations[BuildFailureCategoryAnnotationName]
+	if r.SlackNotifier != nil && (isFailureNew && !isFailureRecorded || r.SlackNotifier.isRetryPending(getSlackNotificationKey(pipelineRun))) {
+		// The failure is recorded once, so the notification is not repeated on subsequent reconciles other than retries
+		retryAfter, err := r.notifyBuildFailure(ctx, component, pipelineRun)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("Failed to post build failure of component %v to Slack", componentKey))
+		}
+		// Retried by requeue, so the worker is not blocked while waiting for the next attempt
+		result.RequeueAfter = retryAfter
+	}
+
+	if getBuildState(pipelineRun) == BuildStateFailed {
+		if err := r.retryFailedBuild(ctx, component, pipelineRun); err != nil {
+			log.Error(err, fmt.Sprintf("Failed to retry failed build of component %v", componentKey))
+			return ctrl.Result{}, err
+		}
+	} else if getBuildState(pipelineRun) == BuildStateSucceeded {
+		message := fmt.Sprintf("The latest build %s succeeded", pipelineRun.Name)
+		if err := clearBuildFailureTerminalCondition(ctx, r.Client, component, BuildFailureTerminalReasonSucceeded, message); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to clear %s condition for component %v", BuildFailureTerminalConditionType, componentKey))
+			return ctrl.Result{}, err
+		}
+		if err := r.recordSuccessfulBuild(ctx, component, pipelineRun); err != nil {
+			log.Error(err, fmt.Sprintf("Failed to record successful build of component %v", componentKey))
+			return ctrl.Result{}, err
+		}
+	}
+
+	if r.BuildProvenanceEnabled {
+		if err := r.recordBuildProvenance(ctx, component, pipelineRun); err != nil {
+			log.Error(err, fmt.Sprintf("Failed to record build provenance of component %v", componentKey))
+			return ctrl.Result{}, err
+		}
+	}
+
+	if getBuildState(pipelineRun) == BuildStateSucceeded && component.Annotations[ScanOnBuildAnnotationName] == "true" {
+		if err := r.submitImageScan(ctx, component, pipelineRun); err != nil {
+			log.Error(err, fmt.Sprintf("Failed to submit image scan of component %v", componentKey))
+			return ctrl.Result{}, err
+		}
+	}
+
+	if getBuildState(pipelineRun) == BuildStateSucceeded && r.SignatureVerificationKey != "" && isSignedBuild(pipelineRun) {
+		if err := r.submitSignatureVerification(ctx, component, pipelineRun); err != nil {
+			log.Error(err, fmt.Sprintf("Failed to submit signature verification of component %v", componentKey))
+			return ctrl.Result{}, err
+		}
+	}
+
+	if r.Notifier != nil {
+		if err := r.notifyFirstSuccessfulBuild(ctx, component, pipelineRun); err != nil {
+			log.Error(err, fmt.Sprintf("Failed to notify about the first successful build of component %v", componentKey))
+			return ctrl.Result{}, err
+		}
+	}
+
+	if r.GitStatusReporter != nil {
+		// Failed reports are retried a few times by requeue and do not block the remaining updates of the build
+		retryAfter, err := r.reportGitStatus(ctx, component, pipelineRun)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("Failed to report git commit status of build %s", pipelineRun.Name))
+		}
+		if retryAfter > 0 && (result.RequeueAfter == 0 || retryAfter < result.RequeueAfter) {
+			result.RequeueAfter = retryAfter
+		}
+	}
+
+	if r.BuildSummaryEnabled {
+		if err := r.updateBuildSummary(ctx, component); err != nil {
+			log.Error(err, fmt.Sprintf("Failed to update build summary of component %v", componentKey))
+			return ctrl.Result{}, err
+		}
+	}
+
+	return result, nil
+}
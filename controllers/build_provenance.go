@@ -0,0 +1,112 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// ProvenanceRefPipelineResultName is the result of build pipelines with the reference of the provenance attestation of the image
+	ProvenanceRefPipelineResultName = "PROVENANCE_REF"
+	// ChainsSignedAnnotationName is set to "true" by Tekton Chains on PipelineRuns whose artifacts have been signed and attested
+	ChainsSignedAnnotationName = "chains.tekton.dev/signed"
+	// ChainsTransparencyAnnotationName is set by Tekton Chains to the transparency log entry of the attestation
+	ChainsTransparencyAnnotationName = "chains.tekton.dev/transparency"
+
+	// BuildProvenanceConditionType is set on components with the provenance attestation reference of their latest successful build
+	BuildProvenanceConditionType = "BuildProvenance"
+
+	BuildProvenanceReasonAttested     = "ProvenanceAttested"
+	BuildProvenanceReasonNotAvailable = "ProvenanceNotAvailable"
+)
+
+// BuildProvenance locates the provenance attestation of a built image.
+type BuildProvenance struct {
+	// Reference of the attestation, e.g. the attestation image in the image repository
+	Reference string `json:"reference"`
+	// TransparencyLogEntry is the URL of the attestation in the transparency log, if it is uploaded there
+	TransparencyLogEntry string `json:"transparencyLogEntry,omitempty"`
+}
+
+// getBuildProvenance returns the location of the provenance attestation of the image built by the PipelineRun,
+// nil means the build has no provenance. The reference in the pipeline result takes precedence.
+// Otherwise the attestation stored by Tekton Chains next to the image is referenced, following the cosign tag convention.
+func getBuildProvenance(pipelineRun tektonapi.PipelineRun) *BuildProvenance {
+	provenance := &BuildProvenance{TransparencyLogEntry: pipelineRun.Annotations[ChainsTransparencyAnnotationName]}
+	for _, result := range pipelineRun.Status.PipelineResults {
+		if result.Name == ProvenanceRefPipelineResultName && strings.TrimSpace(result.Value) != "" {
+			provenance.Reference = strings.TrimSpace(result.Value)
+			return provenance
+		}
+	}
+
+	if pipelineRun.Annotations[ChainsSignedAnnotationName] != "true" {
+		return nil
+	}
+	image, imageDigest := getBuiltImage(pipelineRun)
+	digest := strings.SplitN(imageDigest, ":", 2)
+	if image == "" || len(digest) != 2 || digest[1] == "" {
+		return nil
+	}
+	provenance.Reference = fmt.Sprintf("%s:%s-%s.att", getImageRepository(image), digest[0], digest[1])
+	return provenance
+}
+
+func getBuildProvenanceCondition(pipelineRunName string, provenance *BuildProvenance) metav1.Condition {
+	if provenance == nil {
+		return metav1.Condition{
+			Type:    BuildProvenanceConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  BuildProvenanceReasonNotAvailable,
+			Message: fmt.Sprintf("Build %s has no provenance attestation", pipelineRunName),
+		}
+	}
+	message := fmt.Sprintf("Provenance of build %s is attested in %s", pipelineRunName, provenance.Reference)
+	if provenance.TransparencyLogEntry != "" {
+		message += ", transparency log entry " + provenance.TransparencyLogEntry
+	}
+	return metav1.Condition{
+		Type:    BuildProvenanceConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  BuildProvenanceReasonAttested,
+		Message: message,
+	}
+}
+
+// recordBuildProvenance sets the provenance attestation reference of the succeeded build to the component condition.
+// Only the latest build of the component is recorded, so older builds completed later do not override it.
+func (r *BuildPipelineRunReconciler) recordBuildProvenance(ctx context.Context, component appstudiov1alpha1.Component, pipelineRun tektonapi.PipelineRun) error {
+	if getBuildState(pipelineRun) != BuildStateSucceeded {
+		return nil
+	}
+	latestPipelineRun, err := getLatestComponentPipelineRun(ctx, r.Client, component)
+	if err != nil {
+		return err
+	}
+	if latestPipelineRun != nil && latestPipelineRun.Name != pipelineRun.Name {
+		return nil
+	}
+	return setComponentCondition(ctx, r.Client, component, getBuildProvenanceCondition(pipelineRun.Name, getBuildProvenance(pipelineRun)))
+}
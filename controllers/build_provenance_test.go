@@ -0,0 +1,148 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const testImageDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func getProvenanceTestPipelineRun(name string, annotations map[string]string, results map[string]string) tektonapi.PipelineRun {
+	pipelineRun := getCaptureTestPipelineRun()
+	pipelineRun.Name = name
+	pipelineRun.Annotations = annotations
+	pipelineRun.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue})
+	for name, value := range results {
+		pipelineRun.Status.PipelineResults = append(pipelineRun.Status.PipelineResults, tektonapi.PipelineRunResult{Name: name, Value: value})
+	}
+	return pipelineRun
+}
+
+func TestGetBuildProvenance(t *testing.T) {
+	imageResults := map[string]string{"IMAGE_URL": "quay.io/foo/bar:latest", "IMAGE_DIGEST": testImageDigest}
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		results     map[string]string
+		want        *BuildProvenance
+	}{
+		{
+			name:    "no provenance",
+			results: imageResults,
+			want:    nil,
+		},
+		{
+			name:    "provenance result",
+			results: map[string]string{ProvenanceRefPipelineResultName: "oci://quay.io/foo/bar-attestations@sha256:abc"},
+			want:    &BuildProvenance{Reference: "oci://quay.io/foo/bar-attestations@sha256:abc"},
+		},
+		{
+			name: "signed by Tekton Chains",
+			annotations: map[string]string{
+				ChainsSignedAnnotationName:       "true",
+				ChainsTransparencyAnnotationName: "https://rekor.sigstore.dev/api/v1/log/entries?logIndex=42",
+			},
+			results: imageResults,
+			want: &BuildProvenance{
+				Reference:            "quay.io/foo/bar:sha256-0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef.att",
+				TransparencyLogEntry: "https://rekor.sigstore.dev/api/v1/log/entries?logIndex=42",
+			},
+		},
+		{
+			name:        "signed without image digest",
+			annotations: map[string]string{ChainsSignedAnnotationName: "true"},
+			want:        nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getBuildProvenance(getProvenanceTestPipelineRun("my-component-x7b2k", tt.annotations, tt.results))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getBuildProvenance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// provenanceClient lists the given PipelineRuns and records status updates of the component
+type provenanceClient struct {
+	*conflictingStatusClient
+	pipelineRuns []tektonapi.PipelineRun
+}
+
+func (c *provenanceClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	list.(*tektonapi.PipelineRunList).Items = c.pipelineRuns
+	return nil
+}
+
+func TestRecordBuildProvenance(t *testing.T) {
+	component := getGitSourceComponent(nil, "version: 2.2.0")
+	pipelineRun := getProvenanceTestPipelineRun("my-component-x7b2k", nil,
+		map[string]string{ProvenanceRefPipelineResultName: "quay.io/foo/bar:sha256-abc.att"})
+	pipelineRun.CreationTimestamp = metav1.NewTime(time.Now())
+	olderPipelineRun := getProvenanceTestPipelineRun("my-component-a1b2c", nil, nil)
+	olderPipelineRun.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+
+	cli := &provenanceClient{
+		conflictingStatusClient: &conflictingStatusClient{component: *component.DeepCopy()},
+		pipelineRuns:            []tektonapi.PipelineRun{olderPipelineRun, pipelineRun},
+	}
+	r := &BuildPipelineRunReconciler{Client: cli}
+
+	if err := r.recordBuildProvenance(context.TODO(), component, pipelineRun); err != nil {
+		t.Fatalf("recordBuildProvenance() error = %v", err)
+	}
+	if len(cli.updated) != 1 {
+		t.Fatalf("recordBuildProvenance() updated status %d times, want 1", len(cli.updated))
+	}
+	AssertComponentConditions(t, &cli.updated[0], []metav1.Condition{{
+		Type:    BuildProvenanceConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  BuildProvenanceReasonAttested,
+		Message: "Provenance of build my-component-x7b2k is attested in quay.io/foo/bar:sha256-abc.att",
+	}})
+
+	// Older builds do not override the provenance of the latest build
+	if err := r.recordBuildProvenance(context.TODO(), component, olderPipelineRun); err != nil {
+		t.Fatalf("recordBuildProvenance() error = %v", err)
+	}
+	if len(cli.updated) != 1 {
+		t.Errorf("recordBuildProvenance() recorded provenance of an older build")
+	}
+
+	// Builds without provenance are recorded as such
+	cli.pipelineRuns = []tektonapi.PipelineRun{olderPipelineRun}
+	if err := r.recordBuildProvenance(context.TODO(), component, olderPipelineRun); err != nil {
+		t.Fatalf("recordBuildProvenance() error = %v", err)
+	}
+	if len(cli.updated) != 2 {
+		t.Fatalf("recordBuildProvenance() updated status %d times, want 2", len(cli.updated))
+	}
+	AssertComponentConditions(t, &cli.updated[1], []metav1.Condition{
+		{Type: BuildProvenanceConditionType, Status: metav1.ConditionFalse, Reason: BuildProvenanceReasonNotAvailable},
+	})
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	"github.com/redhat-appstudio/build-service/pkg/tektonresults"
+)
+
+const (
+	// Annotations set by the Tekton Results watcher on PipelineRuns stored in Tekton Results
+	ResultsRecordAnnotationName = "results.tekton.dev/record"
+	ResultsLogAnnotationName    = "results.tekton.dev/log"
+
+	// BuildResultsConditionType is set on components whose latest build has been stored in Tekton Results
+	BuildResultsConditionType = "BuildResults"
+
+	BuildResultsReasonStored = "BuildResultsStored"
+)
+
+// BuildResultsClient reads build records stored in Tekton Results.
+type BuildResultsClient interface {
+	GetRecord(ctx context.Context, name string) (*tektonresults.Record, error)
+}
+
+// recordBuildResults writes the image digest and the log URL of the completed latest build of the component,
+// as stored in Tekton Results, into the BuildResults condition of the component.
+// It returns true if the condition has been updated.
+func (r *ComponentBuildReconciler) recordBuildResults(ctx context.Context, component appstudiov1alpha1.Component) (bool, error) {
+	latestPipelineRun, err := getLatestComponentPipelineRun(ctx, r.Client, component)
+	if err != nil || latestPipelineRun == nil || !isStoredInResults(latestPipelineRun) {
+		return false, err
+	}
+	if isBuildResultsRecorded(component, latestPipelineRun.Name) {
+		return false, nil
+	}
+
+	record, err := r.tektonResultsClient.GetRecord(ctx, latestPipelineRun.Annotations[ResultsRecordAnnotationName])
+	if err != nil {
+		return false, err
+	}
+	storedPipelineRun := &tektonapi.PipelineRun{}
+	if err := json.Unmarshal(record.Data, storedPipelineRun); err != nil {
+		return false, fmt.Errorf("failed to parse PipelineRun stored in Tekton Results record %s: %w", record.Name, err)
+	}
+
+	var logURL string
+	if logName := latestPipelineRun.Annotations[ResultsLogAnnotationName]; logName != "" {
+		logURL = tektonresults.LogURL(r.TektonResultsAPIAddress, logName)
+	}
+	condition := getBuildResultsCondition(latestPipelineRun.Name, getBuildSummary(component, *storedPipelineRun).ImageDigest, logURL)
+	return true, setComponentCondition(ctx, r.Client, component, condition)
+}
+
+// isStoredInResults returns true if the PipelineRun has completed and has been stored in Tekton Results.
+func isStoredInResults(pipelineRun *tektonapi.PipelineRun) bool {
+	if state := getBuildState(*pipelineRun); state != BuildStateSucceeded && state != BuildStateFailed {
+		return false
+	}
+	return pipelineRun.Annotations[ResultsRecordAnnotationName] != ""
+}
+
+// isBuildResultsRecorded returns true if the BuildResults condition of the component describes the given PipelineRun.
+func isBuildResultsRecorded(component appstudiov1alpha1.Component, pipelineRunName string) bool {
+	condition := meta.FindStatusCondition(component.Status.Conditions, BuildResultsConditionType)
+	return condition != nil && strings.HasPrefix(condition.Message, getBuildResultsMessagePrefix(pipelineRunName))
+}
+
+func getBuildResultsMessagePrefix(pipelineRunName string) string {
+	return fmt.Sprintf("Build %s is stored in Tekton Results", pipelineRunName)
+}
+
+func getBuildResultsCondition(pipelineRunName string, imageDigest string, logURL string) metav1.Condition {
+	message := getBuildResultsMessagePrefix(pipelineRunName)
+	var details []string
+	if imageDigest != "" {
+		details = append(details, "image digest "+imageDigest)
+	}
+	if logURL != "" {
+		details = append(details, "logs "+logURL)
+	}
+	if len(details) > 0 {
+		message += ": " + strings.Join(details, ", ")
+	}
+
+	return metav1.Condition{
+		Type:    BuildResultsConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  BuildResultsReasonStored,
+		Message: message,
+	}
+}
+
+// getStoredBuildComponent returns the component built by the PipelineRun stored in Tekton Results.
+func getStoredBuildComponent(object client.Object) []reconcile.Request {
+	pipelineRun, ok := object.(*tektonapi.PipelineRun)
+	if !ok || !isStoredInResults(pipelineRun) {
+		return nil
+	}
+	return []reconcile.Request{{
+		NamespacedName: types.NamespacedName{Name: pipelineRun.Labels[ComponentNameLabelName], Namespace: getPipelineRunComponentNamespace(*pipelineRun)},
+	}}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestIsStoredInResults(t *testing.T) {
+	recordAnnotations := map[string]string{ResultsRecordAnnotationName: "my-namespace/results/1234/records/1234"}
+
+	succeededPipelineRun := getPipelineRunWithSucceededCondition(corev1.ConditionTrue)
+	succeededPipelineRun.Annotations = recordAnnotations
+	failedPipelineRun := getPipelineRunWithSucceededCondition(corev1.ConditionFalse)
+	failedPipelineRun.Annotations = recordAnnotations
+	runningPipelineRun := getPipelineRunWithSucceededCondition(corev1.ConditionUnknown)
+	runningPipelineRun.Annotations = recordAnnotations
+	notStoredPipelineRun := getPipelineRunWithSucceededCondition(corev1.ConditionTrue)
+
+	tests := []struct {
+		name        string
+		pipelineRun tektonapi.PipelineRun
+		want        bool
+	}{
+		{name: "succeeded and stored", pipelineRun: succeededPipelineRun, want: true},
+		{name: "failed and stored", pipelineRun: failedPipelineRun, want: true},
+		{name: "running", pipelineRun: runningPipelineRun, want: false},
+		{name: "not stored", pipelineRun: notStoredPipelineRun, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isStoredInResults(&tt.pipelineRun); got != tt.want {
+				t.Errorf("isStoredInResults() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBuildResultsCondition(t *testing.T) {
+	tests := []struct {
+		name        string
+		imageDigest string
+		logURL      string
+		wantMessage string
+	}{
+		{
+			name:        "digest and logs",
+			imageDigest: "sha256:abcd",
+			logURL:      "https://results.example.com/logs/1234",
+			wantMessage: "Build my-component-abcde is stored in Tekton Results: image digest sha256:abcd, logs https://results.example.com/logs/1234",
+		},
+		{
+			name:        "digest only",
+			imageDigest: "sha256:abcd",
+			wantMessage: "Build my-component-abcde is stored in Tekton Results: image digest sha256:abcd",
+		},
+		{
+			name:        "no details",
+			wantMessage: "Build my-component-abcde is stored in Tekton Results",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition := getBuildResultsCondition("my-component-abcde", tt.imageDigest, tt.logURL)
+			if condition.Message != tt.wantMessage {
+				t.Errorf("getBuildResultsCondition() message = %v, want %v", condition.Message, tt.wantMessage)
+			}
+			if condition.Type != BuildResultsConditionType || condition.Status != metav1.ConditionTrue {
+				t.Errorf("getBuildResultsCondition() = %v, want true %s condition", condition, BuildResultsConditionType)
+			}
+		})
+	}
+}
+
+func TestIsBuildResultsRecorded(t *testing.T) {
+	component := getGitSourceComponent(nil, "")
+	if isBuildResultsRecorded(component, "my-component-abcde") {
+		t.Errorf("isBuildResultsRecorded() = true for component without %s condition", BuildResultsConditionType)
+	}
+
+	component.Status.Conditions = []metav1.Condition{getBuildResultsCondition("my-component-abcde", "sha256:abcd", "")}
+	if !isBuildResultsRecorded(component, "my-component-abcde") {
+		t.Errorf("isBuildResultsRecorded() = false for the recorded build")
+	}
+	if isBuildResultsRecorded(component, "my-component-fghij") {
+		t.Errorf("isBuildResultsRecorded() = true for another build")
+	}
+}
+
+func TestGetStoredBuildComponent(t *testing.T) {
+	pipelineRun := getPipelineRunWithSucceededCondition(corev1.ConditionTrue)
+	pipelineRun.Namespace = "my-namespace"
+	pipelineRun.Labels = map[string]string{ComponentNameLabelName: "my-component"}
+
+	if got := getStoredBuildComponent(&pipelineRun); got != nil {
+		t.Errorf("getStoredBuildComponent() = %v, want nil for PipelineRun not stored in Tekton Results", got)
+	}
+
+	pipelineRun.Annotations = map[string]string{ResultsRecordAnnotationName: "my-namespace/results/1234/records/1234"}
+	want := []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "my-component", Namespace: "my-namespace"}}}
+	if got := getStoredBuildComponent(&pipelineRun); !reflect.DeepEqual(got, want) {
+		t.Errorf("getStoredBuildComponent() = %v, want %v", got, want)
+	}
+}
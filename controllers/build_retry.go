@@ -0,0 +1,166 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// BuildFailureTerminalConditionType is set on components whose latest build failed and is not retried,
+	// either because the failure is caused by the built code or because the retries have been used up
+	BuildFailureTerminalConditionType = "BuildFailureTerminal"
+
+	BuildFailureTerminalReasonNonRetryable    = "NonRetryableFailure"
+	BuildFailureTerminalReasonRetriesExceeded = "RetriesExceeded"
+	BuildFailureTerminalReasonRetried         = "BuildRetried"
+	BuildFailureTerminalReasonSucceeded       = "BuildSucceeded"
+)
+
+// Lowercase fragments of TaskRun failure messages caused by transient network or registry failures
+var retryableFailureMessages = []string{
+	"connection reset",
+	"connection refused",
+	"connection timed out",
+	"i/o timeout",
+	"tls handshake timeout",
+	"no such host",
+	"temporary failure in name resolution",
+	"unexpected eof",
+	"bad gateway",
+	"service unavailable",
+	"gateway timeout",
+	"too many requests",
+}
+
+// isRetryableFailure checks whether the PipelineRun failed because of the infrastructure, e.g. a disruption of the
+// cluster or a network failure, so building the same source again may succeed. Other failures, e.g. compile errors
+// or an invalid Dockerfile, are deterministic and are not retried.
+// The additional lowercase message fragments extend the built-in list of transient failure messages.
+func isRetryableFailure(pipelineRun tektonapi.PipelineRun, additionalMessages []string) bool {
+	if isDisruptionFailure(pipelineRun) {
+		return true
+	}
+
+	_, message := getFailedTask(pipelineRun)
+	if message == "" {
+		message = pipelineRun.Status.GetCondition(apis.ConditionSucceeded).GetMessage()
+	}
+	message = strings.ToLower(message)
+	return containsAny(message, retryableFailureMessages) || containsAny(message, additionalMessages)
+}
+
+// retryFailedBuild resubmits the build of the component if the given PipelineRun is its latest build,
+// the failure is retryable and the build has not been retried yet. The retry is recorded in the component annotation.
+// Builds which are not retried are reflected in the BuildFailureTerminal condition of the component.
+func (r *BuildPipelineRunReconciler) retryFailedBuild(ctx context.Context, component appstudiov1alpha1.Component, pipelineRun tektonapi.PipelineRun) error {
+	log := r.Log.WithValues("Namespace", component.Namespace, "Component", component.Name)
+
+	if component.Annotations[InitialBuildAnnotationName] == "false" {
+		// The build has been resubmitted already
+		return nil
+	}
+	latestPipelineRun, err := getLatestComponentPipelineRun(ctx, r.Client, component)
+	if err != nil {
+		return err
+	}
+	if latestPipelineRun == nil || latestPipelineRun.Name != pipelineRun.Name {
+		// A newer build has been submitted already
+		return nil
+	}
+
+	if !isRetryableFailure(pipelineRun, r.RetryableFailureMessages) {
+		return setComponentCondition(ctx, r.Client, component, getBuildFailureTerminalCondition(pipelineRun, BuildFailureTerminalReasonNonRetryable))
+	}
+	retries, _ := strconv.Atoi(component.Annotations[DisruptionRetriesAnnotationName])
+	if retries >= maxDisruptionRetries {
+		return setComponentCondition(ctx, r.Client, component, getBuildFailureTerminalCondition(pipelineRun, BuildFailureTerminalReasonRetriesExceeded))
+	}
+
+	// Allow the component build controller to submit the build again
+	if component.Annotations == nil {
+		component.Annotations = make(map[string]string)
+	}
+	component.Annotations[InitialBuildAnnotationName] = "false"
+	component.Annotations[BuildTriggerAnnotationName] = BuildTriggerReasonDisruptionRetry
+	component.Annotations[DisruptionRetriesAnnotationName] = strconv.Itoa(retries + 1)
+	if err := r.Client.Update(ctx, &component); err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("Build PipelineRun %s failed with a retryable failure, resubmitting the build", pipelineRun.Name))
+	return clearBuildFailureTerminalCondition(ctx, r.Client, component, BuildFailureTerminalReasonRetried,
+		fmt.Sprintf("The failed build %s has been resubmitted", pipelineRun.Name))
+}
+
+// resetDisruptionRetries removes the disruption retries counter from the component annotations.
+// The counter is a part of the build spec, so the recorded build spec hash is updated too, unless another build
+// relevant change is waiting for the reconcile. The component is not updated in the cluster.
+func resetDisruptionRetries(component *appstudiov1alpha1.Component) {
+	if _, retried := component.Annotations[DisruptionRetriesAnnotationName]; !retried {
+		return
+	}
+	pendingChange := isBuildSpecChanged(*component)
+	delete(component.Annotations, DisruptionRetriesAnnotationName)
+	if !pendingChange {
+		setBuildSpecHash(component)
+	}
+}
+
+func getBuildFailureTerminalCondition(pipelineRun tektonapi.PipelineRun, reason string) metav1.Condition {
+	_, message := getFailedTask(pipelineRun)
+	if message == "" {
+		message = pipelineRun.Status.GetCondition(apis.ConditionSucceeded).GetMessage()
+	}
+
+	var explanation string
+	switch reason {
+	case BuildFailureTerminalReasonNonRetryable:
+		explanation = "the failure is not caused by the infrastructure and building the same source again would fail too"
+	case BuildFailureTerminalReasonRetriesExceeded:
+		explanation = fmt.Sprintf("the build has been retried %d times already", maxDisruptionRetries)
+	}
+	return metav1.Condition{
+		Type:    BuildFailureTerminalConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: fmt.Sprintf("The build %s is not retried as %s: %s", pipelineRun.Name, explanation, message),
+	}
+}
+
+// clearBuildFailureTerminalCondition marks the terminal build failure of the component as resolved.
+func clearBuildFailureTerminalCondition(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component, reason string, message string) error {
+	if !meta.IsStatusConditionTrue(component.Status.Conditions, BuildFailureTerminalConditionType) {
+		return nil
+	}
+	return setComponentCondition(ctx, cli, component, metav1.Condition{
+		Type:    BuildFailureTerminalConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	})
+}
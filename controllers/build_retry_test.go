@@ -0,0 +1,148 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+)
+
+func TestIsRetryableFailure(t *testing.T) {
+	timedOutPipelineRun := getPipelineRunWithSucceededCondition(corev1.ConditionFalse)
+	timedOutPipelineRun.Status.SetCondition(&apis.Condition{
+		Type:    apis.ConditionSucceeded,
+		Status:  corev1.ConditionFalse,
+		Reason:  tektonapi.PipelineRunReasonTimedOut.String(),
+		Message: `PipelineRun "my-component-abcde" failed to finish within "1h0m0s"`,
+	})
+
+	tests := []struct {
+		name               string
+		pipelineRun        tektonapi.PipelineRun
+		additionalMessages []string
+		want               bool
+	}{
+		{
+			name:        "disruption",
+			pipelineRun: getFailedPipelineRun("build-container", "Failed", "The node was low on resource: memory."),
+			want:        true,
+		},
+		{
+			name:        "clone network failure",
+			pipelineRun: getFailedPipelineRun("clone-repository", "Failed", "fatal: unable to access 'https://github.com/foo/bar/': Could not resolve host: github.com; Temporary failure in name resolution"),
+			want:        true,
+		},
+		{
+			name:        "registry unavailable",
+			pipelineRun: getFailedPipelineRun("build-container", "Failed", "Error: writing blob: received unexpected HTTP status: 503 Service Unavailable"),
+			want:        true,
+		},
+		{
+			name:        "dependency download failure",
+			pipelineRun: getFailedPipelineRun("build-container", "Failed", "npm ERR! network read ECONNRESET: connection reset by peer"),
+			want:        true,
+		},
+		{
+			name:        "compile failure",
+			pipelineRun: getFailedPipelineRun("build-container", "Failed", `"step-build" exited with code 1`),
+			want:        false,
+		},
+		{
+			name:        "invalid Dockerfile",
+			pipelineRun: getFailedPipelineRun("build-container", "Failed", "Error: error parsing Dockerfile: unknown instruction: RUNN"),
+			want:        false,
+		},
+		{
+			name:        "push denied",
+			pipelineRun: getFailedPipelineRun("build-container", "Failed", "Error: error pushing image \"quay.io/foo/bar\": unauthorized"),
+			want:        false,
+		},
+		{
+			name:        "timeout",
+			pipelineRun: timedOutPipelineRun,
+			want:        false,
+		},
+		{
+			name:               "additional retryable message",
+			pipelineRun:        getFailedPipelineRun("build-container", "Failed", "Error: Mirror sync in progress"),
+			additionalMessages: []string{"mirror sync in progress"},
+			want:               true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableFailure(tt.pipelineRun, tt.additionalMessages); got != tt.want {
+				t.Errorf("isRetryableFailure() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBuildFailureTerminalCondition(t *testing.T) {
+	pipelineRun := getFailedPipelineRun("build-container", "Failed", `"step-build" exited with code 1`)
+	pipelineRun.Name = "my-component-abcde"
+
+	condition := getBuildFailureTerminalCondition(pipelineRun, BuildFailureTerminalReasonNonRetryable)
+	if condition.Type != BuildFailureTerminalConditionType || condition.Reason != BuildFailureTerminalReasonNonRetryable {
+		t.Errorf("getBuildFailureTerminalCondition() = %v, want %s condition with %s reason", condition, BuildFailureTerminalConditionType, BuildFailureTerminalReasonNonRetryable)
+	}
+	if !strings.Contains(condition.Message, "my-component-abcde") || !strings.HasSuffix(condition.Message, `"step-build" exited with code 1`) {
+		t.Errorf("getBuildFailureTerminalCondition() message = %v, want the build name and the failure message", condition.Message)
+	}
+}
+
+func TestResetDisruptionRetries(t *testing.T) {
+	tests := []struct {
+		name          string
+		pendingChange bool
+		wantHash      bool
+	}{
+		{
+			name:     "should keep the build spec hash in sync",
+			wantHash: true,
+		},
+		{
+			name:          "should not hide pending build spec change",
+			pendingChange: true,
+			wantHash:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := getGitSourceComponent(map[string]string{
+				InitialBuildAnnotationName:      "true",
+				DisruptionRetriesAnnotationName: "1",
+			}, "")
+			setBuildSpecHash(&component)
+			if tt.pendingChange {
+				component.Spec.Context = "./backend"
+			}
+
+			resetDisruptionRetries(&component)
+			if _, retried := component.Annotations[DisruptionRetriesAnnotationName]; retried {
+				t.Errorf("resetDisruptionRetries() kept %s annotation", DisruptionRetriesAnnotationName)
+			}
+			if got := !isBuildSpecChanged(component); got != tt.wantHash {
+				t.Errorf("resetDisruptionRetries() build spec hash in sync = %v, want %v", got, tt.wantHash)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	buildv1alpha1 "github.com/redhat-appstudio/build-service/api/v1alpha1"
+)
+
+const (
+	// ResourceClassLabelName is set on build PipelineRuns to the resource class from the namespace BuildServiceConfig
+	ResourceClassLabelName = "build.appstudio.openshift.io/resource-class"
+
+	BuildBlockedReasonNamespaceConcurrencyLimit = "NamespaceConcurrencyLimitReached"
+)
+
+//+kubebuilder:rbac:groups=build.appstudio.redhat.com,resources=buildserviceconfigs,verbs=get;list;watch
+
+// getBuildServiceConfig returns the build service configuration of the namespace or nil if there is none.
+// The configuration is read from the manager cache, which keeps BuildServiceConfigs per namespace.
+func (r *ComponentBuildReconciler) getBuildServiceConfig(ctx context.Context, namespace string) (*buildv1alpha1.BuildServiceConfigSpec, error) {
+	if !r.BuildServiceConfigEnabled {
+		return nil, nil
+	}
+
+	config := &buildv1alpha1.BuildServiceConfig{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: buildv1alpha1.BuildServiceConfigName, Namespace: namespace}, config); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &config.Spec, nil
+}
+
+// applyBuildServiceConfig overrides the build PipelineRun defaults with the namespace build service configuration.
+func applyBuildServiceConfig(config *buildv1alpha1.BuildServiceConfigSpec, pipelineRun *tektonapi.PipelineRun) {
+	if config == nil {
+		return
+	}
+
+	if config.DefaultPipelineName != "" && pipelineRun.Spec.PipelineRef != nil {
+		pipelineRun.Spec.PipelineRef.Name = config.DefaultPipelineName
+	}
+
+	if config.BuildTimeout != nil {
+		pipelineRun.Spec.Timeout = &metav1.Duration{Duration: config.BuildTimeout.Duration}
+	}
+
+	if config.ResourceClass != "" {
+		if pipelineRun.Labels == nil {
+			pipelineRun.Labels = map[string]string{}
+		}
+		pipelineRun.Labels[ResourceClassLabelName] = config.ResourceClass
+	}
+}
+
+func getNamespaceBuildBlockedCondition(activeBuilds int, maxConcurrentBuilds int32) metav1.Condition {
+	return metav1.Condition{
+		Type:    BuildBlockedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  BuildBlockedReasonNamespaceConcurrencyLimit,
+		Message: fmt.Sprintf("%d builds are running in the namespace, the limit is %d. The build is queued", activeBuilds, maxConcurrentBuilds),
+	}
+}
+
+// getBuildServiceConfigComponents returns reconcile requests for not yet reconciled components
+// in the namespace of the given BuildServiceConfig, so the changed configuration is applied to their builds.
+func (r *ComponentBuildReconciler) getBuildServiceConfigComponents(object client.Object) []reconcile.Request {
+	if object.GetName() != buildv1alpha1.BuildServiceConfigName {
+		return nil
+	}
+
+	components := &appstudiov1alpha1.ComponentList{}
+	if err := r.Client.List(context.Background(), components, client.InNamespace(object.GetNamespace()),
+		client.MatchingFields{buildSpecChangedIndexKey: buildSpecChangedIndexValue}); err != nil {
+		r.Log.Error(err, fmt.Sprintf("Failed to list components in namespace %s", object.GetNamespace()))
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(components.Items))
+	for _, component := range components.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: component.Name, Namespace: component.Namespace},
+		})
+	}
+	return requests
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	buildv1alpha1 "github.com/redhat-appstudio/build-service/api/v1alpha1"
+)
+
+func TestApplyBuildServiceConfig(t *testing.T) {
+	getPipelineRun := func() tektonapi.PipelineRun {
+		return tektonapi.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{ComponentNameLabelName: "my-component"},
+			},
+			Spec: tektonapi.PipelineRunSpec{
+				PipelineRef: &tektonapi.PipelineRef{Name: "docker-build", Bundle: "quay.io/redhat-appstudio/build-templates-bundle:v0.1.3"},
+				Timeout:     &metav1.Duration{Duration: time.Hour},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		config *buildv1alpha1.BuildServiceConfigSpec
+		want   func(*tektonapi.PipelineRun)
+	}{
+		{
+			name:   "no configuration",
+			config: nil,
+			want:   func(*tektonapi.PipelineRun) {},
+		},
+		{
+			name:   "empty configuration",
+			config: &buildv1alpha1.BuildServiceConfigSpec{},
+			want:   func(*tektonapi.PipelineRun) {},
+		},
+		{
+			name:   "default pipeline name",
+			config: &buildv1alpha1.BuildServiceConfigSpec{DefaultPipelineName: "java-builder"},
+			want: func(pipelineRun *tektonapi.PipelineRun) {
+				pipelineRun.Spec.PipelineRef.Name = "java-builder"
+			},
+		},
+		{
+			name:   "build timeout",
+			config: &buildv1alpha1.BuildServiceConfigSpec{BuildTimeout: &metav1.Duration{Duration: 2 * time.Hour}},
+			want: func(pipelineRun *tektonapi.PipelineRun) {
+				pipelineRun.Spec.Timeout = &metav1.Duration{Duration: 2 * time.Hour}
+			},
+		},
+		{
+			name:   "resource class",
+			config: &buildv1alpha1.BuildServiceConfigSpec{ResourceClass: "large"},
+			want: func(pipelineRun *tektonapi.PipelineRun) {
+				pipelineRun.Labels[ResourceClassLabelName] = "large"
+			},
+		},
+		{
+			name:   "concurrency limit only",
+			config: &buildv1alpha1.BuildServiceConfigSpec{MaxConcurrentBuilds: 2},
+			want:   func(*tektonapi.PipelineRun) {},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getPipelineRun()
+			applyBuildServiceConfig(tt.config, &got)
+			want := getPipelineRun()
+			tt.want(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("applyBuildServiceConfig() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
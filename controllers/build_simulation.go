@@ -0,0 +1,182 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops/prepare"
+)
+
+// Limit of the proposed component spec size
+const maxSimulateBuildRequestSize = 1 << 20
+
+// BuildSimulation is the response of the build simulation API.
+type BuildSimulation struct {
+	Component string `json:"component"`
+	Namespace string `json:"namespace"`
+	// InitialBuildDecision tells whether the initial build would be submitted for the proposed spec
+	InitialBuildDecision
+	// TriggerTemplateChanges lists fields of the TriggerTemplate that would be changed by the proposed spec
+	TriggerTemplateChanges []FieldChange `json:"triggerTemplateChanges"`
+}
+
+// FieldChange describes a changed field. Path is a dot separated JSON path, e.g. spec.params.0.name
+type FieldChange struct {
+	Path     string      `json:"path"`
+	Current  interface{} `json:"current,omitempty"`
+	Proposed interface{} `json:"proposed,omitempty"`
+}
+
+func (s *BuildStatusServer) serveSimulateBuild(w http.ResponseWriter, req *http.Request, componentKey types.NamespacedName) {
+	proposedSpec := appstudiov1alpha1.ComponentSpec{}
+	decoder := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxSimulateBuildRequestSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&proposedSpec); err != nil {
+		http.Error(w, fmt.Sprintf("invalid component spec: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	simulation, err := s.simulateBuild(req.Context(), componentKey, proposedSpec)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			http.Error(w, fmt.Sprintf("component %v not found", componentKey), http.StatusNotFound)
+			return
+		}
+		s.Log.Error(err, fmt.Sprintf("Failed to simulate build of component %v", componentKey))
+		http.Error(w, "failed to simulate build", http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSON(w, simulation)
+}
+
+// simulateBuild compares build resources of the component with the ones generated for the proposed spec.
+// Nothing is changed in the cluster.
+func (s *BuildStatusServer) simulateBuild(ctx context.Context, componentKey types.NamespacedName, proposedSpec appstudiov1alpha1.ComponentSpec) (*BuildSimulation, error) {
+	var component appstudiov1alpha1.Component
+	if err := s.Client.Get(ctx, componentKey, &component); err != nil {
+		return nil, err
+	}
+	proposedComponent := *component.DeepCopy()
+	proposedComponent.Spec = proposedSpec
+
+	simulation := &BuildSimulation{
+		Component:              component.Name,
+		Namespace:              component.Namespace,
+		InitialBuildDecision:   getInitialBuildDecision(proposedComponent),
+		TriggerTemplateChanges: []FieldChange{},
+	}
+	// Container image components don't have build resources
+	if getGitSource(component) == nil || getGitSource(proposedComponent) == nil {
+		return simulation, nil
+	}
+
+	gitopsConfig := prepare.PrepareGitopsConfig(ctx, s.Client, component)
+	currentTriggerTemplate, err := getBuildTriggerTemplate(ctx, s.Client, component, gitopsConfig)
+	if err != nil {
+		return nil, err
+	}
+	proposedTriggerTemplate, err := getBuildTriggerTemplate(ctx, s.Client, proposedComponent, gitopsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := diffJSON(currentTriggerTemplate, proposedTriggerTemplate)
+	if err != nil {
+		return nil, err
+	}
+	simulation.TriggerTemplateChanges = changes
+	return simulation, nil
+}
+
+// diffJSON returns changes between JSON representations of the given objects sorted by path.
+// Embedded JSON documents, e.g. TriggerTemplate resource templates, are compared field by field.
+func diffJSON(current interface{}, proposed interface{}) ([]FieldChange, error) {
+	currentFields, err := flattenJSON(current)
+	if err != nil {
+		return nil, err
+	}
+	proposedFields, err := flattenJSON(proposed)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []FieldChange{}
+	for path, currentValue := range currentFields {
+		proposedValue, exists := proposedFields[path]
+		if !exists || !reflect.DeepEqual(currentValue, proposedValue) {
+			changes = append(changes, FieldChange{Path: path, Current: currentValue, Proposed: proposedValue})
+		}
+	}
+	for path, proposedValue := range proposedFields {
+		if _, exists := currentFields[path]; !exists {
+			changes = append(changes, FieldChange{Path: path, Proposed: proposedValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// flattenJSON converts the object into a map of dot separated JSON paths to scalar values.
+// Null values and empty objects or arrays are omitted to not report nil and empty fields as different.
+func flattenJSON(object interface{}) (map[string]interface{}, error) {
+	objectJSON, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(objectJSON, &value); err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	flattenJSONValue("", value, fields)
+	return fields, nil
+}
+
+func flattenJSONValue(path string, value interface{}, fields map[string]interface{}) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			flattenJSONValue(join(key), item, fields)
+		}
+	case []interface{}:
+		for i, item := range v {
+			flattenJSONValue(join(strconv.Itoa(i)), item, fields)
+		}
+	case nil:
+		return
+	default:
+		fields[path] = v
+	}
+}
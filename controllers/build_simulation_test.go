@@ -0,0 +1,84 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffJSON(t *testing.T) {
+	type param struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	type spec struct {
+		Bundle string            `json:"bundle,omitempty"`
+		Params []param           `json:"params"`
+		Labels map[string]string `json:"labels"`
+	}
+
+	tests := []struct {
+		name     string
+		current  interface{}
+		proposed interface{}
+		want     []FieldChange
+	}{
+		{
+			name:     "no changes",
+			current:  spec{Params: []param{{Name: "git-url", Value: "https://github.com/foo/bar"}}},
+			proposed: spec{Params: []param{{Name: "git-url", Value: "https://github.com/foo/bar"}}},
+			want:     []FieldChange{},
+		},
+		{
+			name:     "nil and empty fields",
+			current:  spec{Params: nil, Labels: nil},
+			proposed: spec{Params: []param{}, Labels: map[string]string{}},
+			want:     []FieldChange{},
+		},
+		{
+			name:     "changed value",
+			current:  spec{Params: []param{{Name: "git-url", Value: "https://github.com/foo/bar"}}},
+			proposed: spec{Params: []param{{Name: "git-url", Value: "https://gitlab.com/foo/bar"}}},
+			want: []FieldChange{
+				{Path: "params.0.value", Current: "https://github.com/foo/bar", Proposed: "https://gitlab.com/foo/bar"},
+			},
+		},
+		{
+			name:     "added and removed fields",
+			current:  spec{Bundle: "quay.io/foo/bundle:1", Params: []param{{Name: "a", Value: "1"}}},
+			proposed: spec{Params: []param{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}}},
+			want: []FieldChange{
+				{Path: "bundle", Current: "quay.io/foo/bundle:1"},
+				{Path: "params.1.name", Proposed: "b"},
+				{Path: "params.1.value", Proposed: "2"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := diffJSON(tt.current, tt.proposed)
+			if err != nil {
+				t.Errorf("diffJSON() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// BuildSLOConfigMapKey is the key of the build SLO ConfigMap with YAML list of the build SLOs
+	BuildSLOConfigMapKey = "slos.yaml"
+	// Name of the PrometheusRule generated from the build SLOs in the namespace of the build SLO ConfigMap
+	BuildSLOPrometheusRuleName = "build-service-slo"
+
+	defaultBuildSLOWindow   = "1h"
+	defaultBuildSLOFor      = "10m"
+	defaultBuildSLOSeverity = "warning"
+	defaultBuildSLOQuantile = 0.95
+)
+
+// Prometheus durations used in the rules, compound durations like 1h30m are not supported by older Prometheus versions
+var prometheusDurationRegexp = regexp.MustCompile(`^[0-9]+(ms|s|m|h|d|w|y)$`)
+
+var prometheusRuleGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PrometheusRule"}
+
+// BuildSLO is an objective of the build service which is alerted on when violated.
+// Exactly one of the maximum failure ratio and the maximum duration must be set.
+type BuildSLO struct {
+	// Name of the SLO, used as the alert name
+	Name string `json:"name"`
+	// MaxFailureRatio is the maximum ratio of failed builds to all completed builds, e.g. 0.1
+	MaxFailureRatio *float64 `json:"maxFailureRatio,omitempty"`
+	// MaxDuration is the maximum duration of the given quantile of completed builds, e.g. 15m
+	MaxDuration string `json:"maxDuration,omitempty"`
+	// Quantile of the build duration checked against MaxDuration, 0.95 by default
+	Quantile float64 `json:"quantile,omitempty"`
+	// Window is the range the rates are computed over, 1h by default
+	Window string `json:"window,omitempty"`
+	// For is the time the SLO must be violated before the alert fires, 10m by default
+	For string `json:"for,omitempty"`
+	// Severity label of the alert, warning by default
+	Severity string `json:"severity,omitempty"`
+}
+
+// BuildSLOReconciler generates PrometheusRule with alerts of the build SLOs configured in the build SLO ConfigMap.
+type BuildSLOReconciler struct {
+	Client client.Client
+	Scheme *runtime.Scheme
+	Log    logr.Logger
+	// ConfigMap is the build SLO ConfigMap
+	ConfigMap types.NamespacedName
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BuildSLOReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("buildslo").
+		For(&corev1.ConfigMap{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
+			return object.GetName() == r.ConfigMap.Name && object.GetNamespace() == r.ConfigMap.Namespace
+		}))).
+		Complete(r)
+}
+
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheusrules,verbs=get;list;watch;create;update
+
+// Reconcile keeps the build SLO PrometheusRule in sync with the build SLO ConfigMap.
+// The PrometheusRule is owned by the ConfigMap, so it is deleted together with the ConfigMap.
+func (r *BuildSLOReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("ConfigMap", req.NamespacedName)
+
+	configMap := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, req.NamespacedName, configMap); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	slos, err := getBuildSLOs(configMap.Data[BuildSLOConfigMapKey])
+	if err != nil {
+		// The ConfigMap has to be fixed, do not requeue
+		log.Error(err, fmt.Sprintf("Invalid build SLOs in ConfigMap %v", req.NamespacedName))
+		return ctrl.Result{}, nil
+	}
+	rules, err := getBuildSLOAlertRules(slos)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Invalid build SLOs in ConfigMap %v", req.NamespacedName))
+		return ctrl.Result{}, nil
+	}
+
+	prometheusRule := &unstructured.Unstructured{}
+	prometheusRule.SetGroupVersionKind(prometheusRuleGVK)
+	prometheusRule.SetName(BuildSLOPrometheusRuleName)
+	prometheusRule.SetNamespace(configMap.Namespace)
+	operation, err := controllerutil.CreateOrUpdate(ctx, r.Client, prometheusRule, func() error {
+		if err := unstructured.SetNestedSlice(prometheusRule.Object, []interface{}{
+			map[string]interface{}{
+				"name":  "build-service-slo",
+				"rules": rules,
+			},
+		}, "spec", "groups"); err != nil {
+			return err
+		}
+		return controllerutil.SetOwnerReference(configMap, prometheusRule, r.Scheme)
+	})
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Unable to create or update PrometheusRule %s", BuildSLOPrometheusRuleName))
+		return ctrl.Result{}, err
+	}
+	if operation != controllerutil.OperationResultNone {
+		log.Info(fmt.Sprintf("PrometheusRule %s %s with %d build SLO alerts", BuildSLOPrometheusRuleName, operation, len(rules)))
+	}
+	return ctrl.Result{}, nil
+}
+
+// getBuildSLOs parses and validates YAML list of the build SLOs, defaults are set for optional fields.
+func getBuildSLOs(slosYAML string) ([]BuildSLO, error) {
+	var slos []BuildSLO
+	if err := yaml.UnmarshalStrict([]byte(slosYAML), &slos); err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for i := range slos {
+		slo := &slos[i]
+		if slo.Name == "" {
+			return nil, fmt.Errorf("build SLO %d has no name", i)
+		}
+		if names[slo.Name] {
+			return nil, fmt.Errorf("build SLO %s is defined more than once", slo.Name)
+		}
+		names[slo.Name] = true
+
+		if (slo.MaxFailureRatio == nil) == (slo.MaxDuration == "") {
+			return nil, fmt.Errorf("build SLO %s must have exactly one of maxFailureRatio and maxDuration", slo.Name)
+		}
+		if slo.MaxFailureRatio != nil && (*slo.MaxFailureRatio < 0 || *slo.MaxFailureRatio > 1) {
+			return nil, fmt.Errorf("build SLO %s: maxFailureRatio must be between 0 and 1", slo.Name)
+		}
+		if slo.Quantile == 0 {
+			slo.Quantile = defaultBuildSLOQuantile
+		}
+		if slo.Quantile <= 0 || slo.Quantile >= 1 {
+			return nil, fmt.Errorf("build SLO %s: quantile must be between 0 and 1", slo.Name)
+		}
+		if slo.Window == "" {
+			slo.Window = defaultBuildSLOWindow
+		}
+		if slo.For == "" {
+			slo.For = defaultBuildSLOFor
+		}
+		if slo.Severity == "" {
+			slo.Severity = defaultBuildSLOSeverity
+		}
+	}
+	return slos, nil
+}
+
+// getBuildSLOAlertRules returns PrometheusRule alerting rules of the given build SLOs.
+func getBuildSLOAlertRules(slos []BuildSLO) ([]interface{}, error) {
+	rules := make([]interface{}, 0, len(slos))
+	for _, slo := range slos {
+		for _, duration := range []string{slo.Window, slo.For} {
+			if !prometheusDurationRegexp.MatchString(duration) {
+				return nil, fmt.Errorf("build SLO %s: invalid duration %s, a single unit duration is expected, e.g. 30m", slo.Name, duration)
+			}
+		}
+
+		var expr, description string
+		if slo.MaxFailureRatio != nil {
+			ratio := strconv.FormatFloat(*slo.MaxFailureRatio, 'f', -1, 64)
+			expr = fmt.Sprintf("sum(rate(build_service_build_failures_total[%s])) / sum(rate(build_service_build_duration_seconds_count[%s])) > %s",
+				slo.Window, slo.Window, ratio)
+			description = fmt.Sprintf("More than %s of component builds failed in the last %s.", ratio, slo.Window)
+		} else {
+			maxDuration, err := time.ParseDuration(slo.MaxDuration)
+			if err != nil {
+				return nil, fmt.Errorf("build SLO %s: invalid maxDuration: %v", slo.Name, err)
+			}
+			quantile := strconv.FormatFloat(slo.Quantile, 'f', -1, 64)
+			expr = fmt.Sprintf("histogram_quantile(%s, sum by (le) (rate(build_service_build_duration_seconds_bucket[%s]))) > %s",
+				quantile, slo.Window, strconv.FormatFloat(maxDuration.Seconds(), 'f', -1, 64))
+			description = fmt.Sprintf("The %s quantile of component build duration exceeded %s in the last %s.", quantile, maxDuration, slo.Window)
+		}
+
+		rules = append(rules, map[string]interface{}{
+			"alert": slo.Name,
+			"expr":  expr,
+			"for":   slo.For,
+			"labels": map[string]interface{}{
+				"severity": slo.Severity,
+			},
+			"annotations": map[string]interface{}{
+				"summary":     fmt.Sprintf("Build SLO %s is violated", slo.Name),
+				"description": description,
+			},
+		})
+	}
+	return rules, nil
+}
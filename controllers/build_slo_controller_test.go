@@ -0,0 +1,142 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetBuildSLOs(t *testing.T) {
+	tests := []struct {
+		name    string
+		slos    string
+		wantErr bool
+	}{
+		{
+			name: "failure ratio and duration SLOs",
+			slos: `
+- name: BuildFailureRatioHigh
+  maxFailureRatio: 0.1
+- name: BuildDurationHigh
+  maxDuration: 15m
+  quantile: 0.9
+  window: 6h
+  for: 30m
+  severity: critical
+`,
+		},
+		{
+			name: "no SLOs",
+			slos: "",
+		},
+		{
+			name:    "no name",
+			slos:    "- maxFailureRatio: 0.1",
+			wantErr: true,
+		},
+		{
+			name:    "duplicate name",
+			slos:    "[{name: Foo, maxFailureRatio: 0.1}, {name: Foo, maxDuration: 1m}]",
+			wantErr: true,
+		},
+		{
+			name:    "no objective",
+			slos:    "- name: Foo",
+			wantErr: true,
+		},
+		{
+			name:    "both objectives",
+			slos:    "- {name: Foo, maxFailureRatio: 0.1, maxDuration: 1m}",
+			wantErr: true,
+		},
+		{
+			name:    "failure ratio out of range",
+			slos:    "- {name: Foo, maxFailureRatio: 10}",
+			wantErr: true,
+		},
+		{
+			name:    "quantile out of range",
+			slos:    "- {name: Foo, maxDuration: 1m, quantile: 95}",
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			slos:    "- {name: Foo, maxFailureRate: 0.1}",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := getBuildSLOs(tt.slos)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getBuildSLOs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetBuildSLOAlertRules(t *testing.T) {
+	slos, err := getBuildSLOs(`
+- name: BuildFailureRatioHigh
+  maxFailureRatio: 0.1
+- name: BuildDurationHigh
+  maxDuration: 15m
+  window: 6h
+  for: 30m
+  severity: critical
+`)
+	if err != nil {
+		t.Fatalf("getBuildSLOs() error = %v", err)
+	}
+
+	rules, err := getBuildSLOAlertRules(slos)
+	if err != nil {
+		t.Fatalf("getBuildSLOAlertRules() error = %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("getBuildSLOAlertRules() returned %d rules, want 2", len(rules))
+	}
+
+	failureRule := rules[0].(map[string]interface{})
+	wantFailureExpr := "sum(rate(build_service_build_failures_total[1h])) / sum(rate(build_service_build_duration_seconds_count[1h])) > 0.1"
+	if failureRule["alert"] != "BuildFailureRatioHigh" || failureRule["expr"] != wantFailureExpr || failureRule["for"] != defaultBuildSLOFor {
+		t.Errorf("getBuildSLOAlertRules() = %v, want alert with expression %s", failureRule, wantFailureExpr)
+	}
+	if labels := failureRule["labels"]; !reflect.DeepEqual(labels, map[string]interface{}{"severity": defaultBuildSLOSeverity}) {
+		t.Errorf("getBuildSLOAlertRules() labels = %v, want default severity", labels)
+	}
+
+	durationRule := rules[1].(map[string]interface{})
+	wantDurationExpr := "histogram_quantile(0.95, sum by (le) (rate(build_service_build_duration_seconds_bucket[6h]))) > 900"
+	if durationRule["alert"] != "BuildDurationHigh" || durationRule["expr"] != wantDurationExpr || durationRule["for"] != "30m" {
+		t.Errorf("getBuildSLOAlertRules() = %v, want alert with expression %s", durationRule, wantDurationExpr)
+	}
+	if labels := durationRule["labels"]; !reflect.DeepEqual(labels, map[string]interface{}{"severity": "critical"}) {
+		t.Errorf("getBuildSLOAlertRules() labels = %v, want critical severity", labels)
+	}
+
+	for _, invalidSLO := range []BuildSLO{
+		{Name: "Foo", MaxDuration: "15 minutes", Window: "1h", For: "5m"},
+		{Name: "Foo", MaxDuration: "15m", Window: "1h30m", For: "5m"},
+		{Name: "Foo", MaxDuration: "15m", Window: "1h", For: "five minutes"},
+	} {
+		if _, err := getBuildSLOAlertRules([]BuildSLO{invalidSLO}); err == nil {
+			t.Errorf("getBuildSLOAlertRules(%v) error = nil, want error", invalidSLO)
+		}
+	}
+}
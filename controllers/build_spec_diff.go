@@ -0,0 +1,135 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// Reason of the Component event which tells the build relevant fields that changed and caused a rebuild
+	RebuildEventReason = "RebuildTriggered"
+
+	// Short checksums are enough to detect changes and keep the annotation small
+	buildSpecFieldChecksumLength = 8
+	// Rebuild events are kept short to stay readable in kubectl describe output
+	maxRebuildEventMessageLength = 256
+)
+
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// getBuildSpecFields returns short checksums of the build relevant fields of the component by their JSON path,
+// e.g. source.git.revision. Only checksums are kept, so the field values are not revealed by the annotation or events.
+func getBuildSpecFields(component appstudiov1alpha1.Component) map[string]string {
+	data, _ := json.Marshal(getBuildSpec(component))
+	var spec interface{}
+	_ = json.Unmarshal(data, &spec)
+
+	fields := map[string]string{}
+	addBuildSpecFields("", spec, fields)
+	return fields
+}
+
+func addBuildSpecFields(path string, value interface{}, fields map[string]string) {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		for key, nestedValue := range typedValue {
+			addBuildSpecFields(getBuildSpecFieldPath(path, key), nestedValue, fields)
+		}
+	case []interface{}:
+		for i, nestedValue := range typedValue {
+			addBuildSpecFields(getBuildSpecFieldPath(path, strconv.Itoa(i)), nestedValue, fields)
+		}
+	default:
+		data, _ := json.Marshal(typedValue)
+		fields[path] = getChecksum(data)[:buildSpecFieldChecksumLength]
+	}
+}
+
+func getBuildSpecFieldPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// getChangedBuildSpecFields returns sorted paths of the build relevant fields which have changed since the last reconcile
+// of the component. Returns nil if the fields have not been recorded before.
+func getChangedBuildSpecFields(component appstudiov1alpha1.Component) []string {
+	recordedFieldsJSON, exists := component.Annotations[BuildSpecFieldsAnnotationName]
+	if !exists {
+		return nil
+	}
+	recordedFields := map[string]string{}
+	if err := json.Unmarshal([]byte(recordedFieldsJSON), &recordedFields); err != nil {
+		return nil
+	}
+
+	currentFields := getBuildSpecFields(component)
+	changedFields := []string{}
+	for path, checksum := range currentFields {
+		if recordedFields[path] != checksum {
+			changedFields = append(changedFields, path)
+		}
+	}
+	for path := range recordedFields {
+		if _, exists := currentFields[path]; !exists {
+			changedFields = append(changedFields, path)
+		}
+	}
+	sort.Strings(changedFields)
+	return changedFields
+}
+
+// getRebuildEventMessage returns a concise summary of the changed fields, e.g. "rebuild: source.git.revision changed".
+// Fields which do not fit into the message length limit are counted only.
+func getRebuildEventMessage(changedFields []string) string {
+	shownFields := changedFields
+	message := formatRebuildEventMessage(shownFields, 0)
+	for len(message) > maxRebuildEventMessageLength && len(shownFields) > 1 {
+		shownFields = shownFields[:len(shownFields)-1]
+		message = formatRebuildEventMessage(shownFields, len(changedFields)-len(shownFields))
+	}
+	if len(message) > maxRebuildEventMessageLength {
+		message = message[:maxRebuildEventMessageLength-3] + "..."
+	}
+	return message
+}
+
+func formatRebuildEventMessage(fields []string, hiddenFieldsCount int) string {
+	message := fmt.Sprintf("rebuild: %s changed", strings.Join(fields, ", "))
+	if hiddenFieldsCount > 0 {
+		message += fmt.Sprintf(" (and %d more)", hiddenFieldsCount)
+	}
+	return message
+}
+
+// recordRebuildEvent emits a Normal event on the component with the build relevant fields that caused its rebuild.
+func (r *ComponentBuildReconciler) recordRebuildEvent(component *appstudiov1alpha1.Component, changedFields []string) {
+	if r.Recorder == nil || len(changedFields) == 0 {
+		return
+	}
+	r.Recorder.Event(component, corev1.EventTypeNormal, RebuildEventReason, getRebuildEventMessage(changedFields))
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/tools/record"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+func TestGetChangedBuildSpecFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(component *appstudiov1alpha1.Component)
+		want   []string
+	}{
+		{
+			name:   "no changes",
+			update: func(component *appstudiov1alpha1.Component) {},
+			want:   []string{},
+		},
+		{
+			name: "git url change",
+			update: func(component *appstudiov1alpha1.Component) {
+				component.Spec.Source.GitSource.URL = "https://github.com/foo/baz"
+			},
+			want: []string{"source.git.url"},
+		},
+		{
+			name: "dockerfile set",
+			update: func(component *appstudiov1alpha1.Component) {
+				component.Spec.Source.GitSource.DockerfileURL = "https://github.com/foo/bar/Dockerfile"
+			},
+			want: []string{"source.git.dockerfileUrl"},
+		},
+		{
+			name:   "devfile change",
+			update: func(component *appstudiov1alpha1.Component) { component.Status.Devfile = "version: 2.2.1" },
+			want:   []string{"devfileChecksum"},
+		},
+		{
+			name: "build annotations change",
+			update: func(component *appstudiov1alpha1.Component) {
+				component.Annotations[InitialBuildAnnotationName] = "false"
+				component.Annotations[ImageTagFormatAnnotationName] = "{{.Component}}"
+			},
+			want: []string{
+				"annotations." + ImageTagFormatAnnotationName,
+				"annotations." + InitialBuildAnnotationName,
+			},
+		},
+		{
+			name:   "unrelated annotation change",
+			update: func(component *appstudiov1alpha1.Component) { component.Annotations["foo"] = "bar" },
+			want:   []string{},
+		},
+		{
+			name:   "secret removed",
+			update: func(component *appstudiov1alpha1.Component) { component.Spec.Secret = "" },
+			want:   []string{"secret"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := getGitSourceComponent(map[string]string{InitialBuildAnnotationName: "true"}, "version: 2.2.0")
+			component.Spec.Secret = "my-git-secret"
+			setBuildSpecHash(&component)
+			tt.update(&component)
+
+			if got := getChangedBuildSpecFields(component); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getChangedBuildSpecFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	notRecordedComponent := getGitSourceComponent(nil, "version: 2.2.0")
+	if got := getChangedBuildSpecFields(notRecordedComponent); got != nil {
+		t.Errorf("getChangedBuildSpecFields() = %v, want nil for not recorded fields", got)
+	}
+}
+
+func TestBuildSpecFieldsAnnotationRedacted(t *testing.T) {
+	component := getGitSourceComponent(nil, "version: 2.2.0")
+	component.Spec.Secret = "my-git-secret"
+	setBuildSpecHash(&component)
+
+	fields := component.Annotations[BuildSpecFieldsAnnotationName]
+	if strings.Contains(fields, "my-git-secret") || strings.Contains(fields, "github.com") {
+		t.Errorf("setBuildSpecHash() recorded field values %v, want checksums only", fields)
+	}
+}
+
+func TestGetRebuildEventMessage(t *testing.T) {
+	longField := "annotations.build.appstudio.openshift.io/" + strings.Repeat("a", maxRebuildEventMessageLength)
+	tests := []struct {
+		name          string
+		changedFields []string
+		want          string
+	}{
+		{
+			name:          "single field",
+			changedFields: []string{"source.git.url"},
+			want:          "rebuild: source.git.url changed",
+		},
+		{
+			name:          "several fields",
+			changedFields: []string{"devfileChecksum", "source.git.url"},
+			want:          "rebuild: devfileChecksum, source.git.url changed",
+		},
+		{
+			name:          "fields over the limit",
+			changedFields: []string{"devfileChecksum", "source.git.url", longField, "secret"},
+			want:          "rebuild: devfileChecksum, source.git.url changed (and 2 more)",
+		},
+		{
+			name:          "too long field",
+			changedFields: []string{longField},
+			want:          ("rebuild: " + longField)[:maxRebuildEventMessageLength-3] + "...",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getRebuildEventMessage(tt.changedFields)
+			if got != tt.want {
+				t.Errorf("getRebuildEventMessage() = %v, want %v", got, tt.want)
+			}
+			if len(got) > maxRebuildEventMessageLength {
+				t.Errorf("getRebuildEventMessage() returned %d characters, want at most %d", len(got), maxRebuildEventMessageLength)
+			}
+		})
+	}
+}
+
+func TestRecordRebuildEvent(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	r := &ComponentBuildReconciler{Recorder: recorder}
+
+	component := getGitSourceComponent(map[string]string{InitialBuildAnnotationName: "true"}, "version: 2.2.0")
+	setBuildSpecHash(&component)
+	component.Spec.Source.GitSource.URL = "https://github.com/foo/baz"
+
+	r.recordRebuildEvent(&component, getChangedBuildSpecFields(component))
+	r.recordRebuildEvent(&component, nil)
+
+	if len(recorder.Events) != 1 {
+		t.Fatalf("recordRebuildEvent() emitted %d events, want 1", len(recorder.Events))
+	}
+	want := "Normal " + RebuildEventReason + " rebuild: source.git.url changed"
+	if got := <-recorder.Events; got != want {
+		t.Errorf("recordRebuildEvent() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// Hash of the build relevant fields of the component at the time they were reconciled last time
+	BuildSpecHashAnnotationName = "build.appstudio.openshift.io/build-spec-hash"
+	// JSON object with short checksums of each build relevant field of the component at the time they were reconciled last time
+	BuildSpecFieldsAnnotationName = "build.appstudio.openshift.io/build-spec-fields"
+
+	// Index of components whose build relevant fields have changed since the last reconcile
+	buildSpecChangedIndexKey   = "build.appstudio.openshift.io/build-spec-changed"
+	buildSpecChangedIndexValue = "true"
+
+	buildAnnotationPrefix = "build.appstudio.openshift.io/"
+)
+
+// buildSpec holds the fields of a component which affect its build
+type buildSpec struct {
+	Source          appstudiov1alpha1.ComponentSource `json:"source"`
+	Context         string                            `json:"context,omitempty"`
+	Secret          string                            `json:"secret,omitempty"`
+	Build           appstudiov1alpha1.Build           `json:"build,omitempty"`
+	ContainerImage  string                            `json:"containerImage,omitempty"`
+	DevfileChecksum string                            `json:"devfileChecksum,omitempty"`
+	Annotations     map[string]string                 `json:"annotations,omitempty"`
+}
+
+// getBuildSpec returns the fields of the component which affect its build:
+// the source, the build configuration, the devfile model and the build annotations.
+func getBuildSpec(component appstudiov1alpha1.Component) buildSpec {
+	spec := buildSpec{
+		Source:         component.Spec.Source,
+		Context:        component.Spec.Context,
+		Secret:         component.Spec.Secret,
+		Build:          component.Spec.Build,
+		ContainerImage: component.Status.ContainerImage,
+		Annotations:    map[string]string{},
+	}
+	if component.Status.Devfile != "" {
+		spec.DevfileChecksum = getChecksum([]byte(component.Status.Devfile))
+	}
+	for name, value := range component.Annotations {
+		if name == BuildSpecHashAnnotationName || name == BuildSpecFieldsAnnotationName || name == LastSuccessfulBuildAnnotationName ||
+			name == BuildNumberAnnotationName || name == BuildTriggerAnnotationName {
+			// Records of the controller do not configure the build
+			continue
+		}
+		if name == InitialBuildAnnotationName || strings.HasPrefix(name, buildAnnotationPrefix) {
+			spec.Annotations[name] = value
+		}
+	}
+	return spec
+}
+
+// getBuildSpecHash returns hash of the build relevant fields of the component.
+func getBuildSpecHash(component appstudiov1alpha1.Component) string {
+	spec := getBuildSpec(component)
+	// Map keys are sorted by the JSON encoder, so the same fields always give the same hash
+	data, _ := json.Marshal(spec)
+	return getChecksum(data)
+}
+
+func getChecksum(data []byte) string {
+	checksum := sha256.Sum256(data)
+	return hex.EncodeToString(checksum[:])
+}
+
+// isBuildSpecChanged checks whether the build relevant fields of the component have changed since the last reconcile.
+func isBuildSpecChanged(component appstudiov1alpha1.Component) bool {
+	return component.Annotations[BuildSpecHashAnnotationName] != getBuildSpecHash(component)
+}
+
+// setBuildSpecHash records the current hash and checksums of the build relevant fields in the component annotations.
+// The component is not updated in the cluster.
+func setBuildSpecHash(component *appstudiov1alpha1.Component) {
+	if component.Annotations == nil {
+		component.Annotations = make(map[string]string)
+	}
+	component.Annotations[BuildSpecHashAnnotationName] = getBuildSpecHash(*component)
+	fields, _ := json.Marshal(getBuildSpecFields(*component))
+	component.Annotations[BuildSpecFieldsAnnotationName] = string(fields)
+}
+
+// indexBuildSpecChanged returns the index value for components whose build relevant fields have not been reconciled yet.
+func indexBuildSpecChanged(object client.Object) []string {
+	component, ok := object.(*appstudiov1alpha1.Component)
+	if !ok || !isBuildSpecChanged(*component) {
+		return nil
+	}
+	return []string{buildSpecChangedIndexValue}
+}
+
+// isBuildRelevantUpdate filters out component updates which do not change any build relevant field,
+// e.g. status conditions or deployment settings.
+func isBuildRelevantUpdate(e event.UpdateEvent) bool {
+	oldComponent, isOldComponent := e.ObjectOld.(*appstudiov1alpha1.Component)
+	newComponent, isNewComponent := e.ObjectNew.(*appstudiov1alpha1.Component)
+	if !isOldComponent || !isNewComponent {
+		return true
+	}
+	return getBuildSpecHash(*oldComponent) != getBuildSpecHash(*newComponent)
+}
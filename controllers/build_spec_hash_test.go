@@ -0,0 +1,123 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+func TestIsBuildRelevantUpdate(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(component *appstudiov1alpha1.Component)
+		want   bool
+	}{
+		{
+			name:   "replicas change",
+			update: func(component *appstudiov1alpha1.Component) { component.Spec.Replicas = 3 },
+			want:   false,
+		},
+		{
+			name: "status condition change",
+			update: func(component *appstudiov1alpha1.Component) {
+				component.Status.Conditions = []metav1.Condition{{Type: BuildBlockedConditionType, Status: metav1.ConditionTrue}}
+			},
+			want: false,
+		},
+		{
+			name:   "unrelated annotation change",
+			update: func(component *appstudiov1alpha1.Component) { component.Annotations["foo"] = "bar" },
+			want:   false,
+		},
+		{
+			name:   "build spec hash change",
+			update: func(component *appstudiov1alpha1.Component) { setBuildSpecHash(component) },
+			want:   false,
+		},
+		{
+			name: "source change",
+			update: func(component *appstudiov1alpha1.Component) {
+				component.Spec.Source.GitSource.URL = "https://github.com/foo/baz"
+			},
+			want: true,
+		},
+		{
+			name:   "devfile change",
+			update: func(component *appstudiov1alpha1.Component) { component.Status.Devfile = "version: 2.2.1" },
+			want:   true,
+		},
+		{
+			name:   "build configuration change",
+			update: func(component *appstudiov1alpha1.Component) { component.Spec.Build.ContainerImage = "quay.io/foo/bar" },
+			want:   true,
+		},
+		{
+			name: "build annotation change",
+			update: func(component *appstudiov1alpha1.Component) {
+				component.Annotations[ImageTagFormatAnnotationName] = "{{.Component}}"
+			},
+			want: true,
+		},
+		{
+			name: "initial build annotation change",
+			update: func(component *appstudiov1alpha1.Component) {
+				component.Annotations[InitialBuildAnnotationName] = "false"
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldComponent := getGitSourceComponent(map[string]string{InitialBuildAnnotationName: "true"}, "version: 2.2.0")
+			newComponent := *oldComponent.DeepCopy()
+			tt.update(&newComponent)
+
+			e := event.UpdateEvent{ObjectOld: &oldComponent, ObjectNew: &newComponent}
+			if got := isBuildRelevantUpdate(e); got != tt.want {
+				t.Errorf("isBuildRelevantUpdate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsBuildSpecChanged(t *testing.T) {
+	component := getGitSourceComponent(nil, "version: 2.2.0")
+	if !isBuildSpecChanged(component) {
+		t.Errorf("isBuildSpecChanged() = false for not reconciled component, want true")
+	}
+	if got := indexBuildSpecChanged(&component); len(got) != 1 || got[0] != buildSpecChangedIndexValue {
+		t.Errorf("indexBuildSpecChanged() = %v, want [%s]", got, buildSpecChangedIndexValue)
+	}
+
+	setBuildSpecHash(&component)
+	if isBuildSpecChanged(component) {
+		t.Errorf("isBuildSpecChanged() = true for reconciled component, want false")
+	}
+	if got := indexBuildSpecChanged(&component); got != nil {
+		t.Errorf("indexBuildSpecChanged() = %v, want nil", got)
+	}
+
+	component.Spec.Source.GitSource.URL = "https://github.com/foo/baz"
+	if !isBuildSpecChanged(component) {
+		t.Errorf("isBuildSpecChanged() = false after source change, want true")
+	}
+}
@@ -0,0 +1,277 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	buildStatusPathPrefix    = "/api/v1/namespaces/"
+	buildCandidatesPath      = "/api/v1/diagnostics/build-candidates"
+	buildCandidatesNamespace = "namespace"
+	simulateBuildPathPrefix  = "/apis/build.appstudio.openshift.io/v1alpha1/namespaces/"
+)
+
+// ComponentBuildStatus is the response of the build status API.
+type ComponentBuildStatus struct {
+	Component  string             `json:"component"`
+	Namespace  string             `json:"namespace"`
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// LatestBuild is not set if the component has not been built yet
+	LatestBuild *BuildSummary `json:"latestBuild,omitempty"`
+}
+
+// BuildCandidate is an entry of the build candidates diagnostic report.
+type BuildCandidate struct {
+	Component string `json:"component"`
+	Namespace string `json:"namespace"`
+	InitialBuildDecision
+}
+
+// BuildStatusServer serves read-only build status of components via
+// GET /api/v1/namespaces/{namespace}/components/{name}/build-status
+// and the report of components which would be built on the next reconcile via
+// GET /api/v1/diagnostics/build-candidates[?namespace={namespace}]
+// Changes of a component spec could be checked in advance via
+// POST /apis/build.appstudio.openshift.io/v1alpha1/namespaces/{namespace}/components/{name}/simulate-build
+// Requests are authenticated by the bearer token of the caller and authorized against the Component
+// the caller reads or simulates changes of.
+type BuildStatusServer struct {
+	Client      client.Client
+	Log         logr.Logger
+	BindAddress string
+}
+
+// Start runs the server until the context is done.
+func (s *BuildStatusServer) Start(ctx context.Context) error {
+	server := &http.Server{Addr: s.BindAddress, Handler: s}
+	go func() {
+		<-ctx.Done()
+		if err := server.Shutdown(context.Background()); err != nil {
+			s.Log.Error(err, "Failed to shutdown build status server")
+		}
+	}()
+
+	s.Log.Info(fmt.Sprintf("Starting build status server on %s", s.BindAddress))
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+//+kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// NeedLeaderElection returns false as the build status could be served by every replica.
+func (s *BuildStatusServer) NeedLeaderElection() bool {
+	return false
+}
+
+func (s *BuildStatusServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if componentKey, ok := parseSimulateBuildPath(req.URL.Path); ok {
+		if req.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !s.authorize(w, req, getComponentResourceAttributes("update", componentKey.Namespace, componentKey.Name)) {
+			return
+		}
+		s.serveSimulateBuild(w, req, componentKey)
+		return
+	}
+
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if req.URL.Path == buildCandidatesPath {
+		if !s.authorize(w, req, getComponentResourceAttributes("list", req.URL.Query().Get(buildCandidatesNamespace), "")) {
+			return
+		}
+		s.serveBuildCandidates(w, req)
+		return
+	}
+
+	componentKey, ok := parseBuildStatusPath(req.URL.Path)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	if !s.authorize(w, req, getComponentResourceAttributes("get", componentKey.Namespace, componentKey.Name)) {
+		return
+	}
+
+	buildStatus, err := s.getComponentBuildStatus(req.Context(), componentKey)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			http.Error(w, fmt.Sprintf("component %v not found", componentKey), http.StatusNotFound)
+			return
+		}
+		s.Log.Error(err, fmt.Sprintf("Failed to get build status of component %v", componentKey))
+		http.Error(w, "failed to get build status", http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSON(w, buildStatus)
+}
+
+// authorize checks that the bearer token of the request belongs to a user allowed to access the given resource.
+// The error response is written if the request is not authorized.
+func (s *BuildStatusServer) authorize(w http.ResponseWriter, req *http.Request, attributes authorizationv1.ResourceAttributes) bool {
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if token == "" || token == req.Header.Get("Authorization") {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+
+	tokenReview := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}
+	if err := s.Client.Create(req.Context(), tokenReview); err != nil {
+		s.Log.Error(err, "Failed to review token")
+		http.Error(w, "failed to authenticate", http.StatusInternalServerError)
+		return false
+	}
+	if !tokenReview.Status.Authenticated {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+
+	user := tokenReview.Status.User
+	accessReview := &authorizationv1.SubjectAccessReview{Spec: authorizationv1.SubjectAccessReviewSpec{
+		ResourceAttributes: &attributes,
+		User:               user.Username,
+		Groups:             user.Groups,
+		UID:                user.UID,
+	}}
+	if len(user.Extra) > 0 {
+		accessReview.Spec.Extra = map[string]authorizationv1.ExtraValue{}
+		for key, value := range user.Extra {
+			accessReview.Spec.Extra[key] = authorizationv1.ExtraValue(value)
+		}
+	}
+	if err := s.Client.Create(req.Context(), accessReview); err != nil {
+		s.Log.Error(err, fmt.Sprintf("Failed to review access of user %s", user.Username))
+		http.Error(w, "failed to authorize", http.StatusInternalServerError)
+		return false
+	}
+	if !accessReview.Status.Allowed {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// getComponentResourceAttributes returns attributes of the access to components, empty namespace means all namespaces.
+func getComponentResourceAttributes(verb string, namespace string, name string) authorizationv1.ResourceAttributes {
+	return authorizationv1.ResourceAttributes{
+		Namespace: namespace,
+		Verb:      verb,
+		Group:     appstudiov1alpha1.GroupVersion.Group,
+		Resource:  "components",
+		Name:      name,
+	}
+}
+
+func (s *BuildStatusServer) serveBuildCandidates(w http.ResponseWriter, req *http.Request) {
+	components := &appstudiov1alpha1.ComponentList{}
+	if err := s.Client.List(req.Context(), components, client.InNamespace(req.URL.Query().Get(buildCandidatesNamespace))); err != nil {
+		s.Log.Error(err, "Failed to list components")
+		http.Error(w, "failed to list components", http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSON(w, getBuildCandidates(components.Items))
+}
+
+func (s *BuildStatusServer) writeJSON(w http.ResponseWriter, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.Log.Error(err, "Failed to write response")
+	}
+}
+
+// getBuildCandidates returns build decisions for the given components without submitting any builds.
+func getBuildCandidates(components []appstudiov1alpha1.Component) []BuildCandidate {
+	candidates := make([]BuildCandidate, 0, len(components))
+	for _, component := range components {
+		candidates = append(candidates, BuildCandidate{
+			Component:            component.Name,
+			Namespace:            component.Namespace,
+			InitialBuildDecision: getInitialBuildDecision(component),
+		})
+	}
+	return candidates
+}
+
+func (s *BuildStatusServer) getComponentBuildStatus(ctx context.Context, componentKey types.NamespacedName) (*ComponentBuildStatus, error) {
+	var component appstudiov1alpha1.Component
+	if err := s.Client.Get(ctx, componentKey, &component); err != nil {
+		return nil, err
+	}
+
+	buildStatus := &ComponentBuildStatus{
+		Component:  component.Name,
+		Namespace:  component.Namespace,
+		Conditions: component.Status.Conditions,
+	}
+
+	latestPipelineRun, err := getLatestComponentPipelineRun(ctx, s.Client, component)
+	if err != nil {
+		return nil, err
+	}
+	if latestPipelineRun != nil {
+		latestBuild := getBuildSummary(component, *latestPipelineRun)
+		buildStatus.LatestBuild = &latestBuild
+	}
+	return buildStatus, nil
+}
+
+// parseBuildStatusPath extracts the component namespace and name from the build status API path.
+func parseBuildStatusPath(path string) (types.NamespacedName, bool) {
+	return parseComponentPath(path, buildStatusPathPrefix, "build-status")
+}
+
+// parseSimulateBuildPath extracts the component namespace and name from the build simulation API path.
+func parseSimulateBuildPath(path string) (types.NamespacedName, bool) {
+	return parseComponentPath(path, simulateBuildPathPrefix, "simulate-build")
+}
+
+// parseComponentPath parses {prefix}{namespace}/components/{name}/{action} path.
+func parseComponentPath(path string, prefix string, action string) (types.NamespacedName, bool) {
+	if !strings.HasPrefix(path, prefix) {
+		return types.NamespacedName{}, false
+	}
+	parts := strings.Split(strings.TrimPrefix(path, prefix), "/")
+	if len(parts) != 4 || parts[1] != "components" || parts[3] != action || parts[0] == "" || parts[2] == "" {
+		return types.NamespacedName{}, false
+	}
+	return types.NamespacedName{Namespace: parts[0], Name: parts[2]}, true
+}
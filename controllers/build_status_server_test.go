@@ -0,0 +1,249 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+func TestParseBuildStatusPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		wantOk bool
+		want   types.NamespacedName
+	}{
+		{
+			name:   "valid path",
+			path:   "/api/v1/namespaces/my-namespace/components/my-component/build-status",
+			wantOk: true,
+			want:   types.NamespacedName{Namespace: "my-namespace", Name: "my-component"},
+		},
+		{
+			name:   "wrong prefix",
+			path:   "/api/v2/namespaces/my-namespace/components/my-component/build-status",
+			wantOk: false,
+		},
+		{
+			name:   "wrong resource",
+			path:   "/api/v1/namespaces/my-namespace/applications/my-app/build-status",
+			wantOk: false,
+		},
+		{
+			name:   "missing build-status suffix",
+			path:   "/api/v1/namespaces/my-namespace/components/my-component",
+			wantOk: false,
+		},
+		{
+			name:   "empty component name",
+			path:   "/api/v1/namespaces/my-namespace/components//build-status",
+			wantOk: false,
+		},
+		{
+			name:   "trailing path",
+			path:   "/api/v1/namespaces/my-namespace/components/my-component/build-status/extra",
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseBuildStatusPath(tt.path)
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("parseBuildStatusPath() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestParseSimulateBuildPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		wantOk bool
+		want   types.NamespacedName
+	}{
+		{
+			name:   "valid path",
+			path:   "/apis/build.appstudio.openshift.io/v1alpha1/namespaces/my-namespace/components/my-component/simulate-build",
+			wantOk: true,
+			want:   types.NamespacedName{Namespace: "my-namespace", Name: "my-component"},
+		},
+		{
+			name:   "build status path",
+			path:   "/api/v1/namespaces/my-namespace/components/my-component/build-status",
+			wantOk: false,
+		},
+		{
+			name:   "wrong action",
+			path:   "/apis/build.appstudio.openshift.io/v1alpha1/namespaces/my-namespace/components/my-component/build-status",
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseSimulateBuildPath(tt.path)
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("parseSimulateBuildPath() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestGetBuildCandidates(t *testing.T) {
+	builtComponent := getGitSourceComponent(map[string]string{InitialBuildAnnotationName: "true"}, "version: 2.2.0")
+	builtComponent.Name = "built-component"
+	newComponent := getGitSourceComponent(nil, "version: 2.2.0")
+	newComponent.Name = "new-component"
+
+	got := getBuildCandidates([]appstudiov1alpha1.Component{builtComponent, newComponent})
+	want := []BuildCandidate{
+		{
+			Component:            "built-component",
+			Namespace:            "my-namespace",
+			InitialBuildDecision: InitialBuildDecision{BuildRequired: false, Reason: BuildDecisionReasonAlreadySubmitted},
+		},
+		{
+			Component:            "new-component",
+			Namespace:            "my-namespace",
+			InitialBuildDecision: InitialBuildDecision{BuildRequired: true, Reason: BuildDecisionReasonRequired},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getBuildCandidates() = %v, want %v", got, want)
+	}
+}
+
+// reviewClient authenticates the given tokens and allows access of users to the given namespaces,
+// other objects are created by the embedded client
+type reviewClient struct {
+	client.Client
+	users             map[string]string
+	allowedNamespaces map[string][]string
+	reviews           []authorizationv1.ResourceAttributes
+}
+
+func (c *reviewClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	switch review := obj.(type) {
+	case *authenticationv1.TokenReview:
+		username, ok := c.users[review.Spec.Token]
+		review.Status.Authenticated = ok
+		review.Status.User.Username = username
+	case *authorizationv1.SubjectAccessReview:
+		attributes := *review.Spec.ResourceAttributes
+		c.reviews = append(c.reviews, attributes)
+		for _, namespace := range c.allowedNamespaces[review.Spec.User] {
+			if namespace == attributes.Namespace {
+				review.Status.Allowed = true
+			}
+		}
+	default:
+		return c.Client.Create(ctx, obj, opts...)
+	}
+	return nil
+}
+
+func TestBuildStatusServerAuthorization(t *testing.T) {
+	cli := &reviewClient{
+		users:             map[string]string{"user-token": "user"},
+		allowedNamespaces: map[string][]string{"user": {"my-namespace"}},
+	}
+	server := &BuildStatusServer{Client: cli, Log: logr.Discard()}
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		want          int
+	}{
+		{
+			name:   "no token",
+			method: http.MethodGet,
+			path:   "/api/v1/namespaces/my-namespace/components/my-component/build-status",
+			want:   http.StatusUnauthorized,
+		},
+		{
+			name:          "not a bearer token",
+			method:        http.MethodGet,
+			path:          "/api/v1/namespaces/my-namespace/components/my-component/build-status",
+			authorization: "Basic dXNlcjpwYXNz",
+			want:          http.StatusUnauthorized,
+		},
+		{
+			name:          "invalid token",
+			method:        http.MethodGet,
+			path:          "/api/v1/namespaces/my-namespace/components/my-component/build-status",
+			authorization: "Bearer other-token",
+			want:          http.StatusUnauthorized,
+		},
+		{
+			name:          "build status of component in another namespace",
+			method:        http.MethodGet,
+			path:          "/api/v1/namespaces/other-namespace/components/my-component/build-status",
+			authorization: "Bearer user-token",
+			want:          http.StatusForbidden,
+		},
+		{
+			name:          "build candidates of all namespaces",
+			method:        http.MethodGet,
+			path:          "/api/v1/diagnostics/build-candidates",
+			authorization: "Bearer user-token",
+			want:          http.StatusForbidden,
+		},
+		{
+			name:          "build simulation of component in another namespace",
+			method:        http.MethodPost,
+			path:          "/apis/build.appstudio.openshift.io/v1alpha1/namespaces/other-namespace/components/my-component/simulate-build",
+			authorization: "Bearer user-token",
+			want:          http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+			if recorder.Code != tt.want {
+				t.Errorf("ServeHTTP() status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+
+	want := []authorizationv1.ResourceAttributes{
+		{Namespace: "other-namespace", Verb: "get", Group: "appstudio.redhat.com", Resource: "components", Name: "my-component"},
+		{Namespace: "", Verb: "list", Group: "appstudio.redhat.com", Resource: "components"},
+		{Namespace: "other-namespace", Verb: "update", Group: "appstudio.redhat.com", Resource: "components", Name: "my-component"},
+	}
+	if !reflect.DeepEqual(cli.reviews, want) {
+		t.Errorf("ServeHTTP() reviewed access %v, want %v", cli.reviews, want)
+	}
+}
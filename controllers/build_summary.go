@@ -0,0 +1,159 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// Suffix of the name of the ConfigMap that holds build summary of a component
+	BuildSummaryConfigMapSuffix = "-build-summary"
+	// Data key within the build summary ConfigMap that holds the summary JSON
+	BuildSummaryConfigMapKey = "build-summary.json"
+)
+
+// Build states used in the build summary
+const (
+	BuildStatePending   = "Pending"
+	BuildStateRunning   = "Running"
+	BuildStateSucceeded = "Succeeded"
+	BuildStateFailed    = "Failed"
+)
+
+// BuildSummary describes the latest build of a component.
+// It is stored as JSON in the build summary ConfigMap, so the format must be kept backward compatible.
+type BuildSummary struct {
+	Component      string       `json:"component"`
+	Application    string       `json:"application"`
+	PipelineRun    string       `json:"pipelineRun"`
+	State          string       `json:"state"`
+	Image          string       `json:"image,omitempty"`
+	ImageDigest    string       `json:"imageDigest,omitempty"`
+	CreationTime   metav1.Time  `json:"creationTime"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Provenance locates the provenance attestation of the image, nil if the build has none
+	Provenance *BuildProvenance `json:"provenance,omitempty"`
+}
+
+// getLatestComponentPipelineRun returns the most recently created PipelineRun of the component or nil if there is none.
+func getLatestComponentPipelineRun(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) (*tektonapi.PipelineRun, error) {
+	pipelineRuns, err := listComponentPipelineRuns(ctx, cli, component)
+	if err != nil {
+		return nil, err
+	}
+	if len(pipelineRuns) == 0 {
+		return nil, nil
+	}
+
+	latestPipelineRun := pipelineRuns[0]
+	for _, pipelineRun := range pipelineRuns[1:] {
+		if latestPipelineRun.CreationTimestamp.Before(&pipelineRun.CreationTimestamp) {
+			latestPipelineRun = pipelineRun
+		}
+	}
+	return &latestPipelineRun, nil
+}
+
+// updateBuildSummary writes summary of the latest build of the component into the build summary ConfigMap.
+func (r *BuildPipelineRunReconciler) updateBuildSummary(ctx context.Context, component appstudiov1alpha1.Component) error {
+	latestPipelineRun, err := getLatestComponentPipelineRun(ctx, r.Client, component)
+	if err != nil || latestPipelineRun == nil {
+		return err
+	}
+
+	summaryJSON, err := json.Marshal(getBuildSummary(component, *latestPipelineRun))
+	if err != nil {
+		return err
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      component.Name + BuildSummaryConfigMapSuffix,
+			Namespace: component.Namespace,
+		},
+	}
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		configMap.Data = map[string]string{
+			BuildSummaryConfigMapKey: string(summaryJSON),
+		}
+		return controllerutil.SetOwnerReference(&component, configMap, r.Scheme)
+	})
+	return err
+}
+
+// getBuildSummary returns summary of the build done by the given PipelineRun.
+func getBuildSummary(component appstudiov1alpha1.Component, pipelineRun tektonapi.PipelineRun) BuildSummary {
+	summary := BuildSummary{
+		Component:      component.Name,
+		Application:    component.Spec.Application,
+		PipelineRun:    pipelineRun.Name,
+		State:          getBuildState(pipelineRun),
+		CreationTime:   pipelineRun.CreationTimestamp,
+		StartTime:      pipelineRun.Status.StartTime,
+		CompletionTime: pipelineRun.Status.CompletionTime,
+	}
+	summary.Image, summary.ImageDigest = getBuiltImage(pipelineRun)
+
+	if summary.State == BuildStateSucceeded {
+		summary.Provenance = getBuildProvenance(pipelineRun)
+	}
+
+	return summary
+}
+
+// getBuiltImage returns the image and its digest reported by the build PipelineRun.
+// The output image parameter is returned if the build has not reported the image.
+func getBuiltImage(pipelineRun tektonapi.PipelineRun) (string, string) {
+	image := getPipelineRunParam(pipelineRun, "output-image")
+	var digest string
+	for _, result := range pipelineRun.Status.PipelineResults {
+		switch result.Name {
+		case "IMAGE_URL":
+			image = result.Value
+		case "IMAGE_DIGEST":
+			digest = result.Value
+		}
+	}
+	return image, digest
+}
+
+// getBuildState returns build state of the given PipelineRun.
+func getBuildState(pipelineRun tektonapi.PipelineRun) string {
+	condition := pipelineRun.Status.GetCondition(apis.ConditionSucceeded)
+	switch {
+	case condition.IsTrue():
+		return BuildStateSucceeded
+	case condition.IsFalse():
+		return BuildStateFailed
+	case pipelineRun.HasStarted():
+		return BuildStateRunning
+	default:
+		return BuildStatePending
+	}
+}
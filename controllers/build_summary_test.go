@@ -0,0 +1,109 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+func getPipelineRunWithSucceededCondition(status corev1.ConditionStatus) tektonapi.PipelineRun {
+	pipelineRun := tektonapi.PipelineRun{}
+	pipelineRun.Status.Status = duckv1beta1.Status{
+		Conditions: duckv1beta1.Conditions{
+			{
+				Type:   apis.ConditionSucceeded,
+				Status: status,
+			},
+		},
+	}
+	return pipelineRun
+}
+
+func TestGetBuildState(t *testing.T) {
+	startTime := metav1.Now()
+	runningPipelineRun := getPipelineRunWithSucceededCondition(corev1.ConditionUnknown)
+	runningPipelineRun.Status.StartTime = &startTime
+
+	tests := []struct {
+		name        string
+		pipelineRun tektonapi.PipelineRun
+		want        string
+	}{
+		{
+			name:        "not started",
+			pipelineRun: tektonapi.PipelineRun{},
+			want:        BuildStatePending,
+		},
+		{
+			name:        "running",
+			pipelineRun: runningPipelineRun,
+			want:        BuildStateRunning,
+		},
+		{
+			name:        "succeeded",
+			pipelineRun: getPipelineRunWithSucceededCondition(corev1.ConditionTrue),
+			want:        BuildStateSucceeded,
+		},
+		{
+			name:        "failed",
+			pipelineRun: getPipelineRunWithSucceededCondition(corev1.ConditionFalse),
+			want:        BuildStateFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getBuildState(tt.pipelineRun); got != tt.want {
+				t.Errorf("getBuildState() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBuildSummary(t *testing.T) {
+	component := appstudiov1alpha1.Component{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-component"},
+		Spec:       appstudiov1alpha1.ComponentSpec{Application: "my-app"},
+	}
+	pipelineRun := getPipelineRunWithSucceededCondition(corev1.ConditionTrue)
+	pipelineRun.Name = "my-component-abcde"
+	pipelineRun.Spec.Params = []tektonapi.Param{
+		{Name: "git-url", Value: tektonapi.ArrayOrString{Type: tektonapi.ParamTypeString, StringVal: "https://github.com/foo/bar"}},
+		{Name: "output-image", Value: tektonapi.ArrayOrString{Type: tektonapi.ParamTypeString, StringVal: "quay.io/foo/bar:tag"}},
+	}
+	pipelineRun.Status.PipelineResults = []tektonapi.PipelineRunResult{
+		{Name: "IMAGE_DIGEST", Value: "sha256:0123"},
+	}
+
+	summary := getBuildSummary(component, pipelineRun)
+	if summary.Component != "my-component" || summary.Application != "my-app" || summary.PipelineRun != "my-component-abcde" {
+		t.Errorf("getBuildSummary() has wrong identification: %+v", summary)
+	}
+	if summary.State != BuildStateSucceeded {
+		t.Errorf("getBuildSummary() state = %v, want %v", summary.State, BuildStateSucceeded)
+	}
+	if summary.Image != "quay.io/foo/bar:tag" || summary.ImageDigest != "sha256:0123" {
+		t.Errorf("getBuildSummary() image = %v@%v, want quay.io/foo/bar:tag@sha256:0123", summary.Image, summary.ImageDigest)
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// BuildTierLabelName is the label of a Component or its namespace with the tier of the component builds, e.g. premium.
+	// The label of the Component takes precedence.
+	BuildTierLabelName = "build.appstudio.openshift.io/tier"
+	// BuildTiersConfigMapKey is the key of the build tiers ConfigMap with YAML object of build profiles by tier names
+	BuildTiersConfigMapKey = "tiers.yaml"
+	// DefaultBuildTier is the tier of components without the tier label and with an unknown tier
+	DefaultBuildTier = "base"
+)
+
+// BuildTierProfile describes the resources, timeout and priority of builds of a tier.
+type BuildTierProfile struct {
+	// Timeout of the build PipelineRun, the timeouts set on the Component take precedence
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// PriorityClassName of the build pods
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// StepResources are resource requirements of the build pipeline steps.
+	// Tekton requires enable-api-fields feature flag set to alpha to override step resources.
+	StepResources []BuildTierStepResources `json:"stepResources,omitempty"`
+}
+
+// BuildTierStepResources are resource requirements of a step of a build pipeline task.
+type BuildTierStepResources struct {
+	// PipelineTaskName is the name of the task in the build pipeline, e.g. build-container
+	PipelineTaskName string `json:"pipelineTaskName"`
+	// StepName is the name of the step in the task
+	StepName string `json:"stepName"`
+	// Resources are the resource requirements of the step
+	Resources corev1.ResourceRequirements `json:"resources"`
+}
+
+// getBuildTier returns the build tier from the label of the component or its namespace.
+func (r *ComponentBuildReconciler) getBuildTier(ctx context.Context, component appstudiov1alpha1.Component) (string, error) {
+	if tier := component.Labels[BuildTierLabelName]; tier != "" {
+		return tier, nil
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: component.Namespace}, namespace); err != nil {
+		return "", err
+	}
+	if tier := namespace.Labels[BuildTierLabelName]; tier != "" {
+		return tier, nil
+	}
+	return DefaultBuildTier, nil
+}
+
+// getBuildTierProfile returns the build tier of the component and its profile from the build tiers ConfigMap.
+// The profile of the default tier is returned for unknown tiers, nil profile means no build overrides.
+func (r *ComponentBuildReconciler) getBuildTierProfile(ctx context.Context, component appstudiov1alpha1.Component) (string, *BuildTierProfile, error) {
+	if r.BuildTiersConfigMap == nil {
+		return "", nil, nil
+	}
+
+	tier, err := r.getBuildTier(ctx, component)
+	if err != nil {
+		return "", nil, err
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, *r.BuildTiersConfigMap, configMap); err != nil {
+		if errors.IsNotFound(err) {
+			return tier, nil, nil
+		}
+		return "", nil, err
+	}
+	profiles, err := getBuildTierProfiles(configMap.Data[BuildTiersConfigMapKey])
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid build tiers in ConfigMap %v: %v", *r.BuildTiersConfigMap, err)
+	}
+
+	if profile, exists := profiles[tier]; exists {
+		return tier, &profile, nil
+	}
+	r.Log.Info(fmt.Sprintf("Unknown build tier %s of component %s in namespace %s, using %s tier", tier, component.Name, component.Namespace, DefaultBuildTier))
+	if profile, exists := profiles[DefaultBuildTier]; exists {
+		return DefaultBuildTier, &profile, nil
+	}
+	return DefaultBuildTier, nil, nil
+}
+
+// getBuildTierProfiles parses the YAML object of build profiles by tier names.
+func getBuildTierProfiles(profilesYAML string) (map[string]BuildTierProfile, error) {
+	profiles := map[string]BuildTierProfile{}
+	if err := yaml.UnmarshalStrict([]byte(profilesYAML), &profiles); err != nil {
+		return nil, err
+	}
+	for tier, profile := range profiles {
+		for _, stepResources := range profile.StepResources {
+			if stepResources.PipelineTaskName == "" || stepResources.StepName == "" {
+				return nil, fmt.Errorf("step resources of %s tier must have pipelineTaskName and stepName", tier)
+			}
+		}
+	}
+	return profiles, nil
+}
+
+// applyBuildTierProfile sets the resources, timeout and priority of the build tier profile to the build PipelineRun.
+// The build PipelineRun is labeled with the tier.
+func applyBuildTierProfile(tier string, profile *BuildTierProfile, pipelineRun *tektonapi.PipelineRun) {
+	if tier == "" {
+		return
+	}
+	if pipelineRun.Labels == nil {
+		pipelineRun.Labels = map[string]string{}
+	}
+	pipelineRun.Labels[BuildTierLabelName] = tier
+
+	if profile == nil {
+		return
+	}
+
+	if profile.Timeout != nil {
+		pipelineRun.Spec.Timeout = &metav1.Duration{Duration: profile.Timeout.Duration}
+	}
+
+	if profile.PriorityClassName != "" {
+		if pipelineRun.Spec.PodTemplate == nil {
+			pipelineRun.Spec.PodTemplate = &tektonapi.PodTemplate{}
+		}
+		priorityClassName := profile.PriorityClassName
+		pipelineRun.Spec.PodTemplate.PriorityClassName = &priorityClassName
+	}
+
+	for _, stepResources := range profile.StepResources {
+		taskRunSpec := getPipelineTaskRunSpec(pipelineRun, stepResources.PipelineTaskName)
+		taskRunSpec.StepOverrides = append(taskRunSpec.StepOverrides, tektonapi.TaskRunStepOverride{
+			Name:      stepResources.StepName,
+			Resources: *stepResources.Resources.DeepCopy(),
+		})
+	}
+}
+
+// getPipelineTaskRunSpec returns the TaskRun spec of the pipeline task in the PipelineRun, a missing spec is added.
+func getPipelineTaskRunSpec(pipelineRun *tektonapi.PipelineRun, pipelineTaskName string) *tektonapi.PipelineTaskRunSpec {
+	for i := range pipelineRun.Spec.TaskRunSpecs {
+		if pipelineRun.Spec.TaskRunSpecs[i].PipelineTaskName == pipelineTaskName {
+			return &pipelineRun.Spec.TaskRunSpecs[i]
+		}
+	}
+	pipelineRun.Spec.TaskRunSpecs = append(pipelineRun.Spec.TaskRunSpecs, tektonapi.PipelineTaskRunSpec{PipelineTaskName: pipelineTaskName})
+	return &pipelineRun.Spec.TaskRunSpecs[len(pipelineRun.Spec.TaskRunSpecs)-1]
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testBuildTiersYAML = `
+base:
+  timeout: 1h
+premium:
+  timeout: 3h
+  priorityClassName: build-premium
+  stepResources:
+  - pipelineTaskName: build-container
+    stepName: build
+    resources:
+      requests:
+        cpu: "2"
+        memory: 4Gi
+      limits:
+        memory: 8Gi
+`
+
+func getPremiumStepResources() corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("2"),
+			corev1.ResourceMemory: resource.MustParse("4Gi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("8Gi"),
+		},
+	}
+}
+
+func TestGetBuildTierProfiles(t *testing.T) {
+	tests := []struct {
+		name         string
+		profilesYAML string
+		want         map[string]BuildTierProfile
+		wantErr      bool
+	}{
+		{
+			name:         "no tiers",
+			profilesYAML: "",
+			want:         map[string]BuildTierProfile{},
+		},
+		{
+			name:         "base and premium tiers",
+			profilesYAML: testBuildTiersYAML,
+			want: map[string]BuildTierProfile{
+				"base": {Timeout: &metav1.Duration{Duration: time.Hour}},
+				"premium": {
+					Timeout:           &metav1.Duration{Duration: 3 * time.Hour},
+					PriorityClassName: "build-premium",
+					StepResources: []BuildTierStepResources{
+						{PipelineTaskName: "build-container", StepName: "build", Resources: getPremiumStepResources()},
+					},
+				},
+			},
+		},
+		{
+			name:         "invalid YAML",
+			profilesYAML: "- base",
+			wantErr:      true,
+		},
+		{
+			name:         "unknown field",
+			profilesYAML: "base:\n  cpu: 2",
+			wantErr:      true,
+		},
+		{
+			name:         "step resources without step name",
+			profilesYAML: "base:\n  stepResources:\n  - pipelineTaskName: build-container",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getBuildTierProfiles(tt.profilesYAML)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getBuildTierProfiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getBuildTierProfiles() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyBuildTierProfile(t *testing.T) {
+	profiles, err := getBuildTierProfiles(testBuildTiersYAML)
+	if err != nil {
+		t.Fatalf("getBuildTierProfiles() error = %v", err)
+	}
+	premiumProfile := profiles["premium"]
+	priorityClassName := "build-premium"
+
+	tests := []struct {
+		name         string
+		tier         string
+		profile      *BuildTierProfile
+		taskRunSpecs []tektonapi.PipelineTaskRunSpec
+		want         func(*tektonapi.PipelineRun)
+	}{
+		{
+			name:    "build tiers disabled",
+			tier:    "",
+			profile: nil,
+			want:    func(*tektonapi.PipelineRun) {},
+		},
+		{
+			name:    "tier without profile",
+			tier:    DefaultBuildTier,
+			profile: nil,
+			want: func(pipelineRun *tektonapi.PipelineRun) {
+				pipelineRun.Labels[BuildTierLabelName] = DefaultBuildTier
+			},
+		},
+		{
+			name:    "premium tier",
+			tier:    "premium",
+			profile: &premiumProfile,
+			want: func(pipelineRun *tektonapi.PipelineRun) {
+				pipelineRun.Labels[BuildTierLabelName] = "premium"
+				pipelineRun.Spec.Timeout = &metav1.Duration{Duration: 3 * time.Hour}
+				pipelineRun.Spec.PodTemplate = &tektonapi.PodTemplate{PriorityClassName: &priorityClassName}
+				pipelineRun.Spec.TaskRunSpecs = []tektonapi.PipelineTaskRunSpec{
+					{
+						PipelineTaskName: "build-container",
+						StepOverrides:    []tektonapi.TaskRunStepOverride{{Name: "build", Resources: getPremiumStepResources()}},
+					},
+				}
+			},
+		},
+		{
+			name:         "premium tier with existing task run spec",
+			tier:         "premium",
+			profile:      &premiumProfile,
+			taskRunSpecs: []tektonapi.PipelineTaskRunSpec{{PipelineTaskName: "build-container", TaskServiceAccountName: "builder"}},
+			want: func(pipelineRun *tektonapi.PipelineRun) {
+				pipelineRun.Labels[BuildTierLabelName] = "premium"
+				pipelineRun.Spec.Timeout = &metav1.Duration{Duration: 3 * time.Hour}
+				pipelineRun.Spec.PodTemplate = &tektonapi.PodTemplate{PriorityClassName: &priorityClassName}
+				pipelineRun.Spec.TaskRunSpecs = []tektonapi.PipelineTaskRunSpec{
+					{
+						PipelineTaskName:       "build-container",
+						TaskServiceAccountName: "builder",
+						StepOverrides:          []tektonapi.TaskRunStepOverride{{Name: "build", Resources: getPremiumStepResources()}},
+					},
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getPipelineRun := func() tektonapi.PipelineRun {
+				pipelineRun := tektonapi.PipelineRun{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{ComponentNameLabelName: "my-component"},
+					},
+					Spec: tektonapi.PipelineRunSpec{
+						PipelineRef: &tektonapi.PipelineRef{Name: "docker-build"},
+						Timeout:     &metav1.Duration{Duration: time.Hour},
+					},
+				}
+				for _, taskRunSpec := range tt.taskRunSpecs {
+					pipelineRun.Spec.TaskRunSpecs = append(pipelineRun.Spec.TaskRunSpecs, *taskRunSpec.DeepCopy())
+				}
+				return pipelineRun
+			}
+
+			got := getPipelineRun()
+			applyBuildTierProfile(tt.tier, tt.profile, &got)
+			want := getPipelineRun()
+			tt.want(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("applyBuildTierProfile() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
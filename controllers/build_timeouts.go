@@ -0,0 +1,109 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// JSON object with timeouts of the build PipelineRun, e.g. {"pipeline": "1h", "tasks": "50m", "finally": "10m"}.
+	// Tekton supports the timeouts with enable-api-fields feature flag set to alpha only.
+	BuildTimeoutsAnnotationName = "build.appstudio.openshift.io/timeouts"
+)
+
+// buildTimeouts holds durations of the build timeouts, see https://tekton.dev/docs/pipelines/pipelineruns/#configuring-a-failure-timeout
+type buildTimeouts struct {
+	// Pipeline is the timeout of the whole PipelineRun, 0 means no timeout
+	Pipeline string `json:"pipeline,omitempty"`
+	// Tasks is the timeout of the pipeline tasks
+	Tasks string `json:"tasks,omitempty"`
+	// Finally is the timeout of the finally tasks, which run even if the pipeline tasks time out
+	Finally string `json:"finally,omitempty"`
+}
+
+// getBuildTimeouts returns the timeouts of the build PipelineRun requested in the component annotation
+// or nil if there is no annotation.
+func getBuildTimeouts(component appstudiov1alpha1.Component) (*tektonapi.TimeoutFields, error) {
+	annotation := component.Annotations[BuildTimeoutsAnnotationName]
+	if annotation == "" {
+		return nil, nil
+	}
+
+	annotationTimeouts := buildTimeouts{}
+	if err := json.Unmarshal([]byte(annotation), &annotationTimeouts); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", BuildTimeoutsAnnotationName, err)
+	}
+
+	timeouts := &tektonapi.TimeoutFields{}
+	var err error
+	if timeouts.Pipeline, err = parseBuildTimeout("pipeline", annotationTimeouts.Pipeline); err != nil {
+		return nil, err
+	}
+	if timeouts.Tasks, err = parseBuildTimeout("tasks", annotationTimeouts.Tasks); err != nil {
+		return nil, err
+	}
+	if timeouts.Finally, err = parseBuildTimeout("finally", annotationTimeouts.Finally); err != nil {
+		return nil, err
+	}
+	if err := validateBuildTimeouts(timeouts); err != nil {
+		return nil, err
+	}
+	return timeouts, nil
+}
+
+func parseBuildTimeout(name string, value string) (*metav1.Duration, error) {
+	if value == "" {
+		return nil, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s timeout: %v", name, err)
+	}
+	if duration < 0 {
+		return nil, fmt.Errorf("invalid %s timeout %s: must not be negative", name, value)
+	}
+	return &metav1.Duration{Duration: duration}, nil
+}
+
+// validateBuildTimeouts checks that the tasks and finally timeouts fit into the pipeline timeout the way Tekton requires.
+func validateBuildTimeouts(timeouts *tektonapi.TimeoutFields) error {
+	if timeouts.Pipeline == nil || timeouts.Pipeline.Duration == 0 {
+		// No limit for the tasks and finally timeouts
+		return nil
+	}
+	pipelineTimeout := timeouts.Pipeline.Duration
+
+	if timeouts.Tasks != nil && timeouts.Tasks.Duration > pipelineTimeout {
+		return fmt.Errorf("tasks timeout %s exceeds pipeline timeout %s", timeouts.Tasks.Duration, pipelineTimeout)
+	}
+	if timeouts.Finally != nil && timeouts.Finally.Duration > pipelineTimeout {
+		return fmt.Errorf("finally timeout %s exceeds pipeline timeout %s", timeouts.Finally.Duration, pipelineTimeout)
+	}
+	if timeouts.Tasks != nil && timeouts.Finally != nil && timeouts.Tasks.Duration+timeouts.Finally.Duration > pipelineTimeout {
+		return fmt.Errorf("sum of tasks timeout %s and finally timeout %s exceeds pipeline timeout %s",
+			timeouts.Tasks.Duration, timeouts.Finally.Duration, pipelineTimeout)
+	}
+	return nil
+}
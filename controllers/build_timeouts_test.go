@@ -0,0 +1,109 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetBuildTimeouts(t *testing.T) {
+	duration := func(d time.Duration) *metav1.Duration {
+		return &metav1.Duration{Duration: d}
+	}
+
+	tests := []struct {
+		name       string
+		annotation string
+		want       *tektonapi.TimeoutFields
+		wantErr    bool
+	}{
+		{
+			name: "no annotation",
+			want: nil,
+		},
+		{
+			name:       "all timeouts",
+			annotation: `{"pipeline": "1h", "tasks": "50m", "finally": "10m"}`,
+			want:       &tektonapi.TimeoutFields{Pipeline: duration(time.Hour), Tasks: duration(50 * time.Minute), Finally: duration(10 * time.Minute)},
+		},
+		{
+			name:       "finally timeout only",
+			annotation: `{"finally": "5m"}`,
+			want:       &tektonapi.TimeoutFields{Finally: duration(5 * time.Minute)},
+		},
+		{
+			name:       "finally timeout equal to pipeline timeout",
+			annotation: `{"pipeline": "30m", "finally": "30m"}`,
+			want:       &tektonapi.TimeoutFields{Pipeline: duration(30 * time.Minute), Finally: duration(30 * time.Minute)},
+		},
+		{
+			name:       "no pipeline timeout",
+			annotation: `{"pipeline": "0", "tasks": "2h", "finally": "1h"}`,
+			want:       &tektonapi.TimeoutFields{Pipeline: duration(0), Tasks: duration(2 * time.Hour), Finally: duration(time.Hour)},
+		},
+		{
+			name:       "finally timeout exceeds pipeline timeout",
+			annotation: `{"pipeline": "10m", "finally": "15m"}`,
+			wantErr:    true,
+		},
+		{
+			name:       "tasks timeout exceeds pipeline timeout",
+			annotation: `{"pipeline": "10m", "tasks": "1h"}`,
+			wantErr:    true,
+		},
+		{
+			name:       "tasks and finally timeouts exceed pipeline timeout",
+			annotation: `{"pipeline": "1h", "tasks": "50m", "finally": "20m"}`,
+			wantErr:    true,
+		},
+		{
+			name:       "negative timeout",
+			annotation: `{"finally": "-5m"}`,
+			wantErr:    true,
+		},
+		{
+			name:       "invalid duration",
+			annotation: `{"pipeline": "one hour"}`,
+			wantErr:    true,
+		},
+		{
+			name:       "invalid JSON",
+			annotation: `{"pipeline": 3600}`,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var annotations map[string]string
+			if tt.annotation != "" {
+				annotations = map[string]string{BuildTimeoutsAnnotationName: tt.annotation}
+			}
+			got, err := getBuildTimeouts(getGitSourceComponent(annotations, ""))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getBuildTimeouts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getBuildTimeouts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
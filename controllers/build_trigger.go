@@ -0,0 +1,474 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	triggersapi "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/diff"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops"
+	"github.com/redhat-appstudio/application-service/gitops/prepare"
+)
+
+const (
+	// Name of the ConfigMap in the component namespace with the TriggerTemplate to use instead of the generated one
+	PipelineTemplateConfigMapAnnotationName = "build.appstudio.openshift.io/pipeline-template-configmap"
+	// Index of components by the namespace and name of the pipeline template ConfigMap
+	pipelineTemplateConfigMapIndexKey = "build.appstudio.openshift.io/pipeline-template-configmap"
+	// Data key within the pipeline template ConfigMap that holds the TriggerTemplate YAML
+	PipelineTemplateConfigMapKey = "triggertemplate.yaml"
+	// Name of the pipeline, from the bundle of the build pipeline, to run on pull request events,
+	// e.g. a test build. If set, the EventListener gets a pull request trigger in addition to the push trigger
+	PRPipelineNameAnnotationName = "build.appstudio.openshift.io/pr-pipeline-name"
+
+	// Suffix of the name of the TriggerTemplate of the pull request trigger
+	PRTriggerTemplateSuffix = "-pr"
+
+	// Reason of the Component event with the diff of its TriggerTemplate which differed from the generated one and was updated
+	TriggerTemplateDriftedEventReason = "TriggerTemplateDrifted"
+
+	// Only the beginning of the diff is kept, so the event stays reasonably small. The length is in bytes.
+	maxTriggerTemplateDiffLength = 2048
+)
+
+// Push and pull request event ClusterTriggerBindings shipped with OpenShift Pipelines
+const (
+	githubPushBinding           = "github-push"
+	gitlabPushBinding           = "gitlab-push"
+	bitbucketPushBinding        = "bitbucket-push"
+	githubPullRequestBinding    = "github-pullreq"
+	gitlabMergeRequestBinding   = "gitlab-mergereq"
+	bitbucketPullRequestBinding = "bitbucket-pullreq"
+)
+
+// Git events the component builds are triggered by
+const (
+	buildTriggerEventPush        = "push"
+	buildTriggerEventPullRequest = "pull-request"
+)
+
+// gitProviderTriggers describes how events of a git provider are handled by the EventListener.
+type gitProviderTriggers struct {
+	// Interceptor is the ClusterInterceptor which filters the events by their type
+	Interceptor string
+	// Bindings are the ClusterTriggerBindings of the build trigger events
+	Bindings map[string]string
+	// EventTypes are the provider event types of the build trigger events
+	EventTypes map[string][]string
+}
+
+var (
+	githubTriggers = gitProviderTriggers{
+		Interceptor: "github",
+		Bindings:    map[string]string{buildTriggerEventPush: githubPushBinding, buildTriggerEventPullRequest: githubPullRequestBinding},
+		EventTypes:  map[string][]string{buildTriggerEventPush: {"push"}, buildTriggerEventPullRequest: {"pull_request"}},
+	}
+	gitlabTriggers = gitProviderTriggers{
+		Interceptor: "gitlab",
+		Bindings:    map[string]string{buildTriggerEventPush: gitlabPushBinding, buildTriggerEventPullRequest: gitlabMergeRequestBinding},
+		EventTypes:  map[string][]string{buildTriggerEventPush: {"Push Hook"}, buildTriggerEventPullRequest: {"Merge Request Hook"}},
+	}
+	// Event types of both Bitbucket Cloud and Bitbucket Server are accepted
+	bitbucketTriggers = gitProviderTriggers{
+		Interceptor: "bitbucket",
+		Bindings:    map[string]string{buildTriggerEventPush: bitbucketPushBinding, buildTriggerEventPullRequest: bitbucketPullRequestBinding},
+		EventTypes: map[string][]string{
+			buildTriggerEventPush:        {"repo:push", "repo:refs_changed"},
+			buildTriggerEventPullRequest: {"pullrequest:created", "pullrequest:updated", "pr:opened", "pr:from_ref_updated"},
+		},
+	}
+)
+
+// buildTrigger configures a trigger of the component EventListener.
+type buildTrigger struct {
+	// Name of the trigger within the EventListener
+	Name string
+	// Event is the git event which triggers the build
+	Event string
+	// TriggerTemplateName is the name of the TriggerTemplate which creates the build PipelineRun
+	TriggerTemplateName string
+	// PipelineName replaces the pipeline of the build PipelineRun, empty means the build pipeline is used
+	PipelineName string
+}
+
+//+kubebuilder:rbac:groups=triggers.tekton.dev,resources=triggertemplates;eventlisteners,verbs=get;list;watch;create;update
+
+// ensureBuildTrigger creates the TriggerTemplate and the EventListener which submit a new build
+// of the component on push events from its git provider. Existing resources are left untouched,
+// except for the TriggerTemplate spec which follows the pipeline template ConfigMap if the component has one.
+func (r *ComponentBuildReconciler) ensureBuildTrigger(ctx context.Context, component appstudiov1alpha1.Component, gitopsConfig prepare.GitopsConfig) error {
+	log := r.Log.WithValues("Namespace", component.Namespace, "Component", component.Name)
+
+	triggerTemplate, err := getBuildTriggerTemplate(ctx, r.Client, component, gitopsConfig)
+	if err != nil {
+		log.Error(err, "Unable to get build TriggerTemplate")
+		return err
+	}
+	if hasPipelineTemplateConfigMap(component) {
+		err = r.createOrUpdateTriggerTemplateSpec(ctx, component, triggerTemplate)
+	} else {
+		err = r.createIfNotExists(ctx, component, triggerTemplate)
+	}
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Unable to create TriggerTemplate %s", triggerTemplate.Name))
+		return err
+	}
+	if err := r.createOrUpdatePipelineTriggerTemplates(ctx, component, triggerTemplate); err != nil {
+		return err
+	}
+
+	eventListener := gitops.GenerateEventListener(component, *triggerTemplate)
+	eventListener.Spec.Triggers = getEventListenerTriggers(getGitSource(component).URL, getBuildTriggers(component))
+	if err := r.createOrUpdateEventListenerTriggers(ctx, component, &eventListener); err != nil {
+		log.Error(err, fmt.Sprintf("Unable to create or update EventListener %s", eventListener.Name))
+		return err
+	}
+
+	return nil
+}
+
+// syncConfigMapTriggerTemplates updates the build TriggerTemplates of the component from its pipeline template ConfigMap.
+// The ConfigMap is the source of truth, so its changes are applied to built components without a new build.
+// A missing or invalid ConfigMap is only logged, the component is requeued when the ConfigMap changes.
+func (r *ComponentBuildReconciler) syncConfigMapTriggerTemplates(ctx context.Context, component appstudiov1alpha1.Component) error {
+	log := r.Log.WithValues("Namespace", component.Namespace, "Component", component.Name)
+
+	configMapName := component.Annotations[PipelineTemplateConfigMapAnnotationName]
+	triggerTemplate, err := getConfigMapTriggerTemplate(ctx, r.Client, component, configMapName)
+	if err != nil {
+		if _, isAPIError := err.(errors.APIStatus); isAPIError && !errors.IsNotFound(err) {
+			return err
+		}
+		log.Error(err, fmt.Sprintf("Unable to get TriggerTemplate from ConfigMap %s, keeping the current TriggerTemplates", configMapName))
+		return nil
+	}
+	if err := r.createOrUpdateTriggerTemplateSpec(ctx, component, triggerTemplate); err != nil {
+		return err
+	}
+	return r.createOrUpdatePipelineTriggerTemplates(ctx, component, triggerTemplate)
+}
+
+// createOrUpdatePipelineTriggerTemplates creates or updates copies of the build TriggerTemplate
+// for the triggers which run another pipeline, e.g. the pull request pipeline.
+func (r *ComponentBuildReconciler) createOrUpdatePipelineTriggerTemplates(ctx context.Context, component appstudiov1alpha1.Component, triggerTemplate *triggersapi.TriggerTemplate) error {
+	log := r.Log.WithValues("Namespace", component.Namespace, "Component", component.Name)
+
+	for _, trigger := range getBuildTriggers(component) {
+		if trigger.TriggerTemplateName == triggerTemplate.Name {
+			continue
+		}
+		pipelineTriggerTemplate, err := getPipelineTriggerTemplate(triggerTemplate, trigger)
+		if err != nil {
+			return err
+		}
+		if err := r.createOrUpdateTriggerTemplateSpec(ctx, component, pipelineTriggerTemplate); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to create or update TriggerTemplate %s", pipelineTriggerTemplate.Name))
+			return err
+		}
+	}
+	return nil
+}
+
+// hasPipelineTemplateConfigMap checks whether the build trigger of the component is created from a pipeline template ConfigMap.
+func hasPipelineTemplateConfigMap(component appstudiov1alpha1.Component) bool {
+	return component.Annotations[CreateEventListenerAnnotationName] == "true" && component.Annotations[PipelineTemplateConfigMapAnnotationName] != ""
+}
+
+// indexPipelineTemplateConfigMap returns the index value for components whose build trigger is created
+// from a pipeline template ConfigMap, so changes of other ConfigMaps are dropped without listing components.
+func indexPipelineTemplateConfigMap(object client.Object) []string {
+	component, ok := object.(*appstudiov1alpha1.Component)
+	if !ok || !hasPipelineTemplateConfigMap(*component) {
+		return nil
+	}
+	return []string{getPipelineTemplateConfigMapIndexValue(component.Namespace, component.Annotations[PipelineTemplateConfigMapAnnotationName])}
+}
+
+func getPipelineTemplateConfigMapIndexValue(namespace string, configMapName string) string {
+	return types.NamespacedName{Namespace: namespace, Name: configMapName}.String()
+}
+
+// getPipelineTemplateComponents returns reconcile requests for components whose build trigger is created from the given ConfigMap.
+func (r *ComponentBuildReconciler) getPipelineTemplateComponents(object client.Object) []reconcile.Request {
+	components := &appstudiov1alpha1.ComponentList{}
+	indexValue := getPipelineTemplateConfigMapIndexValue(object.GetNamespace(), object.GetName())
+	if err := r.Client.List(context.Background(), components, client.MatchingFields{pipelineTemplateConfigMapIndexKey: indexValue}); err != nil {
+		r.Log.Error(err, fmt.Sprintf("Failed to list components using pipeline template ConfigMap %s in namespace %s", object.GetName(), object.GetNamespace()))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, component := range components.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: component.Name, Namespace: component.Namespace},
+		})
+	}
+	return requests
+}
+
+// getBuildTriggers returns the triggers of the component EventListener.
+// Pushes are built always, pull requests only if the component has the pull request pipeline set.
+func getBuildTriggers(component appstudiov1alpha1.Component) []buildTrigger {
+	triggers := []buildTrigger{
+		{
+			Name:                buildTriggerEventPush,
+			Event:               buildTriggerEventPush,
+			TriggerTemplateName: component.Name,
+		},
+	}
+	if pipelineName := component.Annotations[PRPipelineNameAnnotationName]; pipelineName != "" {
+		triggers = append(triggers, buildTrigger{
+			Name:                buildTriggerEventPullRequest,
+			Event:               buildTriggerEventPullRequest,
+			TriggerTemplateName: component.Name + PRTriggerTemplateSuffix,
+			PipelineName:        pipelineName,
+		})
+	}
+	return triggers
+}
+
+// getPipelineTriggerTemplate returns a copy of the build TriggerTemplate for the given trigger.
+// The pipeline of the PipelineRun resource templates is replaced by the pipeline of the trigger.
+func getPipelineTriggerTemplate(triggerTemplate *triggersapi.TriggerTemplate, trigger buildTrigger) (*triggersapi.TriggerTemplate, error) {
+	pipelineTriggerTemplate := triggerTemplate.DeepCopy()
+	pipelineTriggerTemplate.Name = trigger.TriggerTemplateName
+	pipelineTriggerTemplate.OwnerReferences = nil
+
+	for i, resourceTemplate := range pipelineTriggerTemplate.Spec.ResourceTemplates {
+		pipelineRun := &tektonapi.PipelineRun{}
+		if err := json.Unmarshal(resourceTemplate.Raw, pipelineRun); err != nil {
+			return nil, fmt.Errorf("invalid resource template of TriggerTemplate %s: %v", triggerTemplate.Name, err)
+		}
+		if pipelineRun.Kind != "PipelineRun" || pipelineRun.Spec.PipelineRef == nil {
+			continue
+		}
+		pipelineRun.Spec.PipelineRef.Name = trigger.PipelineName
+
+		pipelineRunJSON, err := json.Marshal(pipelineRun)
+		if err != nil {
+			return nil, err
+		}
+		pipelineTriggerTemplate.Spec.ResourceTemplates[i].Raw = pipelineRunJSON
+	}
+	return pipelineTriggerTemplate, nil
+}
+
+// getEventListenerTriggers returns EventListener triggers which pass only the events of their type
+// from the git provider of the given repository to their TriggerTemplates.
+func getEventListenerTriggers(gitURL string, buildTriggers []buildTrigger) []triggersapi.EventListenerTrigger {
+	providerTriggers := getGitProviderTriggers(gitURL)
+
+	triggers := make([]triggersapi.EventListenerTrigger, 0, len(buildTriggers))
+	for _, buildTrigger := range buildTriggers {
+		eventTypesJSON, _ := json.Marshal(providerTriggers.EventTypes[buildTrigger.Event])
+		templateName := buildTrigger.TriggerTemplateName
+		triggers = append(triggers, triggersapi.EventListenerTrigger{
+			Name: buildTrigger.Name,
+			Interceptors: []*triggersapi.EventInterceptor{
+				{
+					Ref: triggersapi.InterceptorRef{Name: providerTriggers.Interceptor, Kind: triggersapi.ClusterInterceptorKind},
+					Params: []triggersapi.InterceptorParams{
+						{Name: "eventTypes", Value: apiextensionsv1.JSON{Raw: eventTypesJSON}},
+					},
+				},
+			},
+			Bindings: []*triggersapi.EventListenerBinding{
+				{Ref: providerTriggers.Bindings[buildTrigger.Event], Kind: triggersapi.ClusterTriggerBindingKind},
+			},
+			Template: &triggersapi.EventListenerTemplate{Ref: &templateName},
+		})
+	}
+	return triggers
+}
+
+// createOrUpdateEventListenerTriggers creates the given EventListener owned by the component
+// or updates triggers of the existing one if they differ.
+func (r *ComponentBuildReconciler) createOrUpdateEventListenerTriggers(ctx context.Context, component appstudiov1alpha1.Component, eventListener *triggersapi.EventListener) error {
+	existing := &triggersapi.EventListener{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: eventListener.Name, Namespace: eventListener.Namespace}, existing)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		if err := controllerutil.SetOwnerReference(&component, eventListener, r.Scheme); err != nil {
+			return err
+		}
+		return r.Client.Create(ctx, eventListener)
+	}
+
+	if equality.Semantic.DeepEqual(existing.Spec.Triggers, eventListener.Spec.Triggers) {
+		return nil
+	}
+	existing.Spec.Triggers = eventListener.Spec.Triggers
+	return r.Client.Update(ctx, existing)
+}
+
+// createIfNotExists creates the given object owned by the component unless an object with the same name exists.
+func (r *ComponentBuildReconciler) createIfNotExists(ctx context.Context, component appstudiov1alpha1.Component, object client.Object) error {
+	existing := object.DeepCopyObject().(client.Object)
+	err := r.Client.Get(ctx, types.NamespacedName{Name: object.GetName(), Namespace: object.GetNamespace()}, existing)
+	if err == nil || !errors.IsNotFound(err) {
+		return err
+	}
+
+	if err := controllerutil.SetOwnerReference(&component, object, r.Scheme); err != nil {
+		return err
+	}
+	return r.Client.Create(ctx, object)
+}
+
+// getBuildTriggerTemplate returns the TriggerTemplate of the component build trigger.
+// It is read from the pipeline template ConfigMap if the component has one, otherwise it is generated.
+func getBuildTriggerTemplate(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component, gitopsConfig prepare.GitopsConfig) (*triggersapi.TriggerTemplate, error) {
+	if hasPipelineTemplateConfigMap(component) {
+		return getConfigMapTriggerTemplate(ctx, cli, component, component.Annotations[PipelineTemplateConfigMapAnnotationName])
+	}
+	return gitops.GenerateTriggerTemplate(component, gitopsConfig)
+}
+
+// getConfigMapTriggerTemplate returns the TriggerTemplate stored in the given ConfigMap in the component namespace.
+func getConfigMapTriggerTemplate(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component, configMapName string) (*triggersapi.TriggerTemplate, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := cli.Get(ctx, types.NamespacedName{Name: configMapName, Namespace: component.Namespace}, configMap); err != nil {
+		return nil, err
+	}
+	triggerTemplateYAML, ok := configMap.Data[PipelineTemplateConfigMapKey]
+	if !ok {
+		return nil, fmt.Errorf("ConfigMap %s has no %s key", configMapName, PipelineTemplateConfigMapKey)
+	}
+	return parseTriggerTemplate(component, triggerTemplateYAML)
+}
+
+// parseTriggerTemplate returns the TriggerTemplate from the given YAML named and placed as the generated one.
+func parseTriggerTemplate(component appstudiov1alpha1.Component, triggerTemplateYAML string) (*triggersapi.TriggerTemplate, error) {
+	triggerTemplate := &triggersapi.TriggerTemplate{}
+	if err := yaml.Unmarshal([]byte(triggerTemplateYAML), triggerTemplate); err != nil {
+		return nil, fmt.Errorf("invalid TriggerTemplate: %v", err)
+	}
+	if len(triggerTemplate.Spec.ResourceTemplates) == 0 {
+		return nil, fmt.Errorf("invalid TriggerTemplate: no resource templates")
+	}
+
+	triggerTemplate.TypeMeta = metav1.TypeMeta{
+		Kind:       "TriggerTemplate",
+		APIVersion: triggersapi.SchemeGroupVersion.String(),
+	}
+	triggerTemplate.ObjectMeta = metav1.ObjectMeta{
+		Name:        component.Name,
+		Namespace:   component.Namespace,
+		Labels:      triggerTemplate.Labels,
+		Annotations: triggerTemplate.Annotations,
+	}
+	return triggerTemplate, nil
+}
+
+// createOrUpdateTriggerTemplateSpec creates the given TriggerTemplate owned by the component
+// or updates spec of the existing one if it differs.
+func (r *ComponentBuildReconciler) createOrUpdateTriggerTemplateSpec(ctx context.Context, component appstudiov1alpha1.Component, triggerTemplate *triggersapi.TriggerTemplate) error {
+	existing := &triggersapi.TriggerTemplate{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: triggerTemplate.Name, Namespace: triggerTemplate.Namespace}, existing)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		if err := controllerutil.SetOwnerReference(&component, triggerTemplate, r.Scheme); err != nil {
+			return err
+		}
+		return r.Client.Create(ctx, triggerTemplate)
+	}
+
+	if equality.Semantic.DeepEqual(existing.Spec, triggerTemplate.Spec) {
+		return nil
+	}
+	specDiff := getTriggerTemplateSpecDiff(existing.Spec, triggerTemplate.Spec)
+	existing.Spec = triggerTemplate.Spec
+	if err := r.Client.Update(ctx, existing); err != nil {
+		return err
+	}
+	r.recordTriggerTemplateDriftEvent(&component, existing.Name, specDiff)
+	return nil
+}
+
+// getTriggerTemplateSpecDiff returns the diff of the existing and the desired TriggerTemplate spec.
+// The specs are compared as JSON objects, so the embedded resource templates are shown as fields and not as raw bytes.
+func getTriggerTemplateSpecDiff(existing, desired triggersapi.TriggerTemplateSpec) string {
+	return diff.ObjectReflectDiff(toJSONValue(existing), toJSONValue(desired))
+}
+
+func toJSONValue(object interface{}) interface{} {
+	data, _ := json.Marshal(object)
+	var value interface{}
+	_ = json.Unmarshal(data, &value)
+	return value
+}
+
+// getTriggerTemplateDriftEventMessage returns the event message with the beginning of the TriggerTemplate diff.
+func getTriggerTemplateDriftEventMessage(triggerTemplateName string, specDiff string) string {
+	if len(specDiff) > maxTriggerTemplateDiffLength {
+		// Cut on a rune boundary, so a multi-byte character of the diff is not split
+		end := maxTriggerTemplateDiffLength
+		for end > 0 && !utf8.RuneStart(specDiff[end]) {
+			end--
+		}
+		specDiff = specDiff[:end]
+	}
+	return fmt.Sprintf("TriggerTemplate %s differed from the generated one and was updated (-existing +generated):\n%s", triggerTemplateName, specDiff)
+}
+
+// recordTriggerTemplateDriftEvent emits a Normal event on the component with the diff of its updated TriggerTemplate.
+func (r *ComponentBuildReconciler) recordTriggerTemplateDriftEvent(component *appstudiov1alpha1.Component, triggerTemplateName string, specDiff string) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Event(component, corev1.EventTypeNormal, TriggerTemplateDriftedEventReason, getTriggerTemplateDriftEventMessage(triggerTemplateName, specDiff))
+}
+
+// getPushEventBinding returns the name of the ClusterTriggerBinding for push events of the given git repository.
+func getPushEventBinding(gitURL string) string {
+	return getGitProviderTriggers(gitURL).Bindings[buildTriggerEventPush]
+}
+
+// getGitProviderTriggers returns the event handling of the git provider of the given repository, GitHub by default.
+func getGitProviderTriggers(gitURL string) gitProviderTriggers {
+	gitProvider, _ := getGitProvider(gitURL)
+	switch {
+	case strings.Contains(gitProvider, "gitlab"):
+		return gitlabTriggers
+	case strings.Contains(gitProvider, "bitbucket"):
+		return bitbucketTriggers
+	default:
+		return githubTriggers
+	}
+}
@@ -0,0 +1,434 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	triggersapi "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const testTriggerTemplateYAML = `apiVersion: triggers.tekton.dev/v1alpha1
+kind: TriggerTemplate
+metadata:
+  name: custom-template
+  namespace: other-namespace
+  labels:
+    team: foo
+spec:
+  params:
+  - name: git-revision
+  resourcetemplates:
+  - apiVersion: tekton.dev/v1beta1
+    kind: PipelineRun
+    metadata:
+      generateName: custom-
+    spec:
+      pipelineRef:
+        name: custom-build
+`
+
+func TestGetPushEventBinding(t *testing.T) {
+	tests := []struct {
+		name   string
+		gitURL string
+		want   string
+	}{
+		{
+			name:   "github",
+			gitURL: "https://github.com/devfile-samples/devfile-sample-java-springboot-basic",
+			want:   githubPushBinding,
+		},
+		{
+			name:   "gitlab",
+			gitURL: "https://gitlab.com/devfile-samples/devfile-sample-java-springboot-basic",
+			want:   gitlabPushBinding,
+		},
+		{
+			name:   "self-hosted gitlab",
+			gitURL: "https://gitlab.example.com/devfile-samples/devfile-sample-java-springboot-basic",
+			want:   gitlabPushBinding,
+		},
+		{
+			name:   "bitbucket",
+			gitURL: "https://bitbucket.org/devfile-samples/devfile-sample-java-springboot-basic",
+			want:   bitbucketPushBinding,
+		},
+		{
+			name:   "invalid url",
+			gitURL: "github.com/devfile-samples/devfile-sample-java-springboot-basic",
+			want:   githubPushBinding,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getPushEventBinding(tt.gitURL); got != tt.want {
+				t.Errorf("getPushEventBinding() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTriggerTemplate(t *testing.T) {
+	component := getGitSourceComponent(nil, "")
+
+	triggerTemplate, err := parseTriggerTemplate(component, testTriggerTemplateYAML)
+	if err != nil {
+		t.Fatalf("parseTriggerTemplate() error = %v", err)
+	}
+	if triggerTemplate.Name != component.Name || triggerTemplate.Namespace != component.Namespace {
+		t.Errorf("parseTriggerTemplate() = %s/%s, want %s/%s", triggerTemplate.Namespace, triggerTemplate.Name, component.Namespace, component.Name)
+	}
+	if triggerTemplate.Labels["team"] != "foo" {
+		t.Errorf("parseTriggerTemplate() labels = %v, want team label", triggerTemplate.Labels)
+	}
+	if len(triggerTemplate.Spec.Params) != 1 || triggerTemplate.Spec.Params[0].Name != "git-revision" {
+		t.Errorf("parseTriggerTemplate() params = %v, want git-revision", triggerTemplate.Spec.Params)
+	}
+	if len(triggerTemplate.Spec.ResourceTemplates) != 1 {
+		t.Errorf("parseTriggerTemplate() resource templates = %v, want 1", len(triggerTemplate.Spec.ResourceTemplates))
+	}
+
+	for _, invalidYAML := range []string{"spec: [", "spec:\n  params:\n  - name: git-revision\n"} {
+		if _, err := parseTriggerTemplate(component, invalidYAML); err == nil {
+			t.Errorf("parseTriggerTemplate(%q) error = nil, want error", invalidYAML)
+		}
+	}
+}
+
+func TestGetBuildTriggers(t *testing.T) {
+	pushTrigger := buildTrigger{Name: "push", Event: buildTriggerEventPush, TriggerTemplateName: "my-component"}
+
+	triggers := getBuildTriggers(getGitSourceComponent(nil, ""))
+	if len(triggers) != 1 || triggers[0] != pushTrigger {
+		t.Errorf("getBuildTriggers() = %v, want only %v", triggers, pushTrigger)
+	}
+
+	triggers = getBuildTriggers(getGitSourceComponent(map[string]string{PRPipelineNameAnnotationName: "docker-test"}, ""))
+	pullRequestTrigger := buildTrigger{Name: "pull-request", Event: buildTriggerEventPullRequest, TriggerTemplateName: "my-component-pr", PipelineName: "docker-test"}
+	if len(triggers) != 2 || triggers[0] != pushTrigger || triggers[1] != pullRequestTrigger {
+		t.Errorf("getBuildTriggers() = %v, want %v and %v", triggers, pushTrigger, pullRequestTrigger)
+	}
+}
+
+func TestGetPipelineTriggerTemplate(t *testing.T) {
+	pipelineRunJSON, _ := json.Marshal(tektonapi.PipelineRun{
+		TypeMeta: metav1.TypeMeta{Kind: "PipelineRun", APIVersion: "tekton.dev/v1beta1"},
+		Spec: tektonapi.PipelineRunSpec{
+			PipelineRef: &tektonapi.PipelineRef{Name: "docker-build", Bundle: "quay.io/redhat-appstudio/build-templates-bundle:v0.1.3"},
+		},
+	})
+	triggerTemplate := &triggersapi.TriggerTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-component", Namespace: "my-namespace"},
+		Spec: triggersapi.TriggerTemplateSpec{
+			ResourceTemplates: []triggersapi.TriggerResourceTemplate{{RawExtension: runtime.RawExtension{Raw: pipelineRunJSON}}},
+		},
+	}
+	trigger := buildTrigger{Name: "pull-request", Event: buildTriggerEventPullRequest, TriggerTemplateName: "my-component-pr", PipelineName: "docker-test"}
+
+	got, err := getPipelineTriggerTemplate(triggerTemplate, trigger)
+	if err != nil {
+		t.Fatalf("getPipelineTriggerTemplate() error = %v", err)
+	}
+	if got.Name != "my-component-pr" || got.Namespace != "my-namespace" {
+		t.Errorf("getPipelineTriggerTemplate() = %s/%s, want my-namespace/my-component-pr", got.Namespace, got.Name)
+	}
+	pipelineRun := tektonapi.PipelineRun{}
+	if err := json.Unmarshal(got.Spec.ResourceTemplates[0].Raw, &pipelineRun); err != nil {
+		t.Fatalf("failed to parse resource template: %v", err)
+	}
+	wantPipelineRef := tektonapi.PipelineRef{Name: "docker-test", Bundle: "quay.io/redhat-appstudio/build-templates-bundle:v0.1.3"}
+	if !reflect.DeepEqual(*pipelineRun.Spec.PipelineRef, wantPipelineRef) {
+		t.Errorf("getPipelineTriggerTemplate() pipelineRef = %v, want %v", *pipelineRun.Spec.PipelineRef, wantPipelineRef)
+	}
+	if string(triggerTemplate.Spec.ResourceTemplates[0].Raw) != string(pipelineRunJSON) {
+		t.Errorf("getPipelineTriggerTemplate() modified the given TriggerTemplate")
+	}
+}
+
+func TestGetEventListenerTriggers(t *testing.T) {
+	buildTriggers := getBuildTriggers(getGitSourceComponent(map[string]string{PRPipelineNameAnnotationName: "docker-test"}, ""))
+
+	tests := []struct {
+		name               string
+		gitURL             string
+		wantInterceptor    string
+		wantBindings       []string
+		wantPushEventTypes string
+	}{
+		{
+			name:               "github",
+			gitURL:             "https://github.com/foo/bar",
+			wantInterceptor:    "github",
+			wantBindings:       []string{githubPushBinding, githubPullRequestBinding},
+			wantPushEventTypes: `["push"]`,
+		},
+		{
+			name:               "gitlab",
+			gitURL:             "https://gitlab.com/foo/bar",
+			wantInterceptor:    "gitlab",
+			wantBindings:       []string{gitlabPushBinding, gitlabMergeRequestBinding},
+			wantPushEventTypes: `["Push Hook"]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			triggers := getEventListenerTriggers(tt.gitURL, buildTriggers)
+			if len(triggers) != len(buildTriggers) {
+				t.Fatalf("getEventListenerTriggers() = %v, want %d triggers", triggers, len(buildTriggers))
+			}
+			for i, trigger := range triggers {
+				if trigger.Name != buildTriggers[i].Name || *trigger.Template.Ref != buildTriggers[i].TriggerTemplateName {
+					t.Errorf("getEventListenerTriggers() trigger %s template = %s, want %s", trigger.Name, *trigger.Template.Ref, buildTriggers[i].TriggerTemplateName)
+				}
+				if trigger.Bindings[0].Ref != tt.wantBindings[i] {
+					t.Errorf("getEventListenerTriggers() trigger %s binding = %s, want %s", trigger.Name, trigger.Bindings[0].Ref, tt.wantBindings[i])
+				}
+				if trigger.Interceptors[0].Ref.Name != tt.wantInterceptor {
+					t.Errorf("getEventListenerTriggers() trigger %s interceptor = %s, want %s", trigger.Name, trigger.Interceptors[0].Ref.Name, tt.wantInterceptor)
+				}
+			}
+			if eventTypes := string(triggers[0].Interceptors[0].Params[0].Value.Raw); eventTypes != tt.wantPushEventTypes {
+				t.Errorf("getEventListenerTriggers() push event types = %s, want %s", eventTypes, tt.wantPushEventTypes)
+			}
+		})
+	}
+}
+
+type triggerTemplateClient struct {
+	client.Client
+	existing *triggersapi.TriggerTemplate
+	updated  *triggersapi.TriggerTemplate
+}
+
+func (c *triggerTemplateClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	c.existing.DeepCopyInto(obj.(*triggersapi.TriggerTemplate))
+	return nil
+}
+
+func (c *triggerTemplateClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.updated = obj.(*triggersapi.TriggerTemplate)
+	return nil
+}
+
+func TestCreateOrUpdateTriggerTemplateSpecDrift(t *testing.T) {
+	component := getGitSourceComponent(nil, "version: 2.2.0")
+	desired, err := parseTriggerTemplate(component, testTriggerTemplateYAML)
+	if err != nil {
+		t.Fatalf("parseTriggerTemplate() error = %v", err)
+	}
+	existing := desired.DeepCopy()
+	existing.Spec.Params[0].Name = "git-commit"
+
+	recorder := record.NewFakeRecorder(10)
+	cli := &triggerTemplateClient{existing: existing}
+	r := &ComponentBuildReconciler{Client: cli, Recorder: recorder}
+
+	if err := r.createOrUpdateTriggerTemplateSpec(context.TODO(), component, desired.DeepCopy()); err != nil {
+		t.Fatalf("createOrUpdateTriggerTemplateSpec() error = %v", err)
+	}
+	if cli.updated == nil || !reflect.DeepEqual(cli.updated.Spec, desired.Spec) {
+		t.Errorf("createOrUpdateTriggerTemplateSpec() updated %v, want %v", cli.updated, desired.Spec)
+	}
+	if len(recorder.Events) != 1 {
+		t.Fatalf("createOrUpdateTriggerTemplateSpec() emitted %d events, want 1", len(recorder.Events))
+	}
+	event := <-recorder.Events
+	if !strings.HasPrefix(event, "Normal "+TriggerTemplateDriftedEventReason+" ") ||
+		!strings.Contains(event, `"git-commit"`) || !strings.Contains(event, `"git-revision"`) {
+		t.Errorf("createOrUpdateTriggerTemplateSpec() event = %v, want diff of the parameter name", event)
+	}
+
+	// Unchanged TriggerTemplate is not updated
+	cli = &triggerTemplateClient{existing: desired.DeepCopy()}
+	r.Client = cli
+	if err := r.createOrUpdateTriggerTemplateSpec(context.TODO(), component, desired.DeepCopy()); err != nil {
+		t.Fatalf("createOrUpdateTriggerTemplateSpec() error = %v", err)
+	}
+	if cli.updated != nil || len(recorder.Events) != 0 {
+		t.Errorf("createOrUpdateTriggerTemplateSpec() updated unchanged TriggerTemplate")
+	}
+}
+
+// pipelineTemplateComponentsClient lists the given components, the pipeline template ConfigMap index is evaluated on the fly
+type pipelineTemplateComponentsClient struct {
+	client.Client
+	components []appstudiov1alpha1.Component
+}
+
+func (c *pipelineTemplateComponentsClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOptions := &client.ListOptions{}
+	listOptions.ApplyOptions(opts)
+	componentList := list.(*appstudiov1alpha1.ComponentList)
+	for i := range c.components {
+		if listOptions.FieldSelector != nil {
+			indexValues := indexPipelineTemplateConfigMap(&c.components[i])
+			if len(indexValues) == 0 || !listOptions.FieldSelector.Matches(fields.Set{pipelineTemplateConfigMapIndexKey: indexValues[0]}) {
+				continue
+			}
+		}
+		componentList.Items = append(componentList.Items, c.components[i])
+	}
+	return nil
+}
+
+func TestGetPipelineTemplateComponents(t *testing.T) {
+	withName := func(component appstudiov1alpha1.Component, name string) appstudiov1alpha1.Component {
+		component.Name = name
+		return component
+	}
+	components := []appstudiov1alpha1.Component{
+		withName(getGitSourceComponent(map[string]string{
+			CreateEventListenerAnnotationName:       "true",
+			PipelineTemplateConfigMapAnnotationName: "pipeline-template",
+		}, ""), "with-template"),
+		withName(getGitSourceComponent(map[string]string{
+			CreateEventListenerAnnotationName:       "true",
+			PipelineTemplateConfigMapAnnotationName: "other-template",
+		}, ""), "with-other-template"),
+		withName(getGitSourceComponent(map[string]string{
+			PipelineTemplateConfigMapAnnotationName: "pipeline-template",
+		}, ""), "without-event-listener"),
+		withName(getGitSourceComponent(nil, ""), "without-template"),
+	}
+	otherNamespaceComponent := withName(getGitSourceComponent(map[string]string{
+		CreateEventListenerAnnotationName:       "true",
+		PipelineTemplateConfigMapAnnotationName: "pipeline-template",
+	}, ""), "in-other-namespace")
+	otherNamespaceComponent.Namespace = "other-namespace"
+	components = append(components, otherNamespaceComponent)
+	r := &ComponentBuildReconciler{Client: &pipelineTemplateComponentsClient{components: components}}
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "pipeline-template", Namespace: "my-namespace"}}
+	requests := r.getPipelineTemplateComponents(configMap)
+	if len(requests) != 1 || requests[0].Name != "with-template" || requests[0].Namespace != "my-namespace" {
+		t.Errorf("getPipelineTemplateComponents() = %v, want only the component using the ConfigMap", requests)
+	}
+}
+
+func TestGetTriggerTemplateDriftEventMessage(t *testing.T) {
+	got := getTriggerTemplateDriftEventMessage("my-component", strings.Repeat("z", 3000))
+	if !strings.HasPrefix(got, "TriggerTemplate my-component ") {
+		t.Errorf("getTriggerTemplateDriftEventMessage() = %v, want the TriggerTemplate name", got)
+	}
+	if diffLength := strings.Count(got, "z"); diffLength != maxTriggerTemplateDiffLength {
+		t.Errorf("getTriggerTemplateDriftEventMessage() contains %d characters of the diff, want %d", diffLength, maxTriggerTemplateDiffLength)
+	}
+
+	// The limit falls into the middle of a two-byte character, which is left out as a whole
+	got = getTriggerTemplateDriftEventMessage("my-component", "z"+strings.Repeat("é", 2000))
+	if !utf8.ValidString(got) {
+		t.Errorf("getTriggerTemplateDriftEventMessage() = %q, want valid UTF-8", got)
+	}
+	if diffLength := strings.Count(got, "é"); diffLength != (maxTriggerTemplateDiffLength-1)/2 {
+		t.Errorf("getTriggerTemplateDriftEventMessage() contains %d characters of the diff, want %d", diffLength, (maxTriggerTemplateDiffLength-1)/2)
+	}
+}
+
+// getBenchmarkTriggerTemplate returns a TriggerTemplate with the given number of PipelineRun resource templates,
+// each with an embedded pipeline of the given number of tasks with the given number of parameters.
+func getBenchmarkTriggerTemplate(b *testing.B, resourceTemplates, tasks, params int) *triggersapi.TriggerTemplate {
+	triggerTemplate := &triggersapi.TriggerTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-component", Namespace: "my-namespace"},
+		Spec: triggersapi.TriggerTemplateSpec{
+			Params: []triggersapi.ParamSpec{{Name: "git-revision"}},
+		},
+	}
+	for i := 0; i < resourceTemplates; i++ {
+		pipelineSpec := &tektonapi.PipelineSpec{}
+		for j := 0; j < tasks; j++ {
+			task := tektonapi.PipelineTask{
+				Name:    fmt.Sprintf("task-%d", j),
+				TaskRef: &tektonapi.TaskRef{Name: fmt.Sprintf("task-%d", j), Bundle: "quay.io/redhat-appstudio/appstudio-tasks:v0.1.3"},
+			}
+			for k := 0; k < params; k++ {
+				task.Params = append(task.Params, tektonapi.Param{
+					Name:  fmt.Sprintf("param-%d", k),
+					Value: *tektonapi.NewArrayOrString(fmt.Sprintf("$(params.value-%d)-%s", k, strings.Repeat("v", 64))),
+				})
+			}
+			pipelineSpec.Tasks = append(pipelineSpec.Tasks, task)
+		}
+		pipelineRun := tektonapi.PipelineRun{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "tekton.dev/v1beta1", Kind: "PipelineRun"},
+			ObjectMeta: metav1.ObjectMeta{GenerateName: fmt.Sprintf("my-component-%d-", i), Namespace: "my-namespace"},
+			Spec:       tektonapi.PipelineRunSpec{PipelineSpec: pipelineSpec},
+		}
+		raw, err := json.Marshal(pipelineRun)
+		if err != nil {
+			b.Fatalf("failed to marshal PipelineRun: %v", err)
+		}
+		triggerTemplate.Spec.ResourceTemplates = append(triggerTemplate.Spec.ResourceTemplates,
+			triggersapi.TriggerResourceTemplate{RawExtension: runtime.RawExtension{Raw: raw}})
+	}
+	return triggerTemplate
+}
+
+// BenchmarkCreateOrUpdateTriggerTemplateSpec measures the comparison of the existing TriggerTemplate with the generated one
+// which decides whether the build trigger is updated. Drifted TriggerTemplates are also diffed as JSON objects for the event.
+func BenchmarkCreateOrUpdateTriggerTemplateSpec(b *testing.B) {
+	component := getGitSourceComponent(nil, "version: 2.2.0")
+	sizes := []struct {
+		resourceTemplates int
+		tasks             int
+		params            int
+	}{
+		{resourceTemplates: 1, tasks: 1, params: 5},
+		{resourceTemplates: 1, tasks: 10, params: 50},
+		{resourceTemplates: 5, tasks: 10, params: 50},
+	}
+	for _, size := range sizes {
+		desired := getBenchmarkTriggerTemplate(b, size.resourceTemplates, size.tasks, size.params)
+		drifted := desired.DeepCopy()
+		drifted.Spec.Params[0].Name = "git-commit"
+
+		for _, existing := range []struct {
+			name            string
+			triggerTemplate *triggersapi.TriggerTemplate
+		}{
+			{name: "unchanged", triggerTemplate: desired},
+			{name: "drifted", triggerTemplate: drifted},
+		} {
+			name := fmt.Sprintf("templates=%d,tasks=%d,params=%d,%s", size.resourceTemplates, size.tasks, size.params, existing.name)
+			b.Run(name, func(b *testing.B) {
+				r := &ComponentBuildReconciler{Client: &triggerTemplateClient{existing: existing.triggerTemplate}}
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if err := r.createOrUpdateTriggerTemplateSpec(context.TODO(), component, desired.DeepCopy()); err != nil {
+						b.Fatalf("createOrUpdateTriggerTemplateSpec() error = %v", err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// BuildsDisabledConditionType is set on components whose build has not been submitted because builds are disabled in the controller
+	BuildsDisabledConditionType = "BuildsDisabled"
+
+	BuildsDisabledReasonDisabled = "DisabledByController"
+	BuildsDisabledReasonEnabled  = "EnabledByController"
+)
+
+func getBuildsDisabledCondition() metav1.Condition {
+	return metav1.Condition{
+		Type:   BuildsDisabledConditionType,
+		Status: metav1.ConditionTrue,
+		Reason: BuildsDisabledReasonDisabled,
+		Message: "Builds are disabled in the build controller. " +
+			"The build is submitted when the controller is restarted with builds enabled",
+	}
+}
+
+// clearBuildsDisabledCondition marks the build of the component, which has not been submitted while builds were disabled, as submitted.
+func clearBuildsDisabledCondition(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) error {
+	if !meta.IsStatusConditionTrue(component.Status.Conditions, BuildsDisabledConditionType) {
+		return nil
+	}
+	return setComponentCondition(ctx, cli, component, metav1.Condition{
+		Type:    BuildsDisabledConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  BuildsDisabledReasonEnabled,
+		Message: "Builds have been enabled, the build has been submitted",
+	})
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClearBuildsDisabledCondition(t *testing.T) {
+	component := getGitSourceComponent(nil, "version: 2.2.0")
+	cli := &conflictingStatusClient{component: *component.DeepCopy()}
+
+	// Components built while builds were enabled are not updated
+	if err := clearBuildsDisabledCondition(context.TODO(), cli, component); err != nil {
+		t.Fatalf("clearBuildsDisabledCondition() error = %v", err)
+	}
+	if len(cli.updated) != 0 {
+		t.Errorf("clearBuildsDisabledCondition() updated component without %s condition", BuildsDisabledConditionType)
+	}
+
+	meta.SetStatusCondition(&component.Status.Conditions, getBuildsDisabledCondition())
+	cli.component = *component.DeepCopy()
+	if err := clearBuildsDisabledCondition(context.TODO(), cli, component); err != nil {
+		t.Fatalf("clearBuildsDisabledCondition() error = %v", err)
+	}
+	if len(cli.updated) != 1 {
+		t.Fatalf("clearBuildsDisabledCondition() updated status %d times, want 1", len(cli.updated))
+	}
+	AssertComponentConditions(t, &cli.updated[0], []metav1.Condition{
+		{Type: BuildsDisabledConditionType, Status: metav1.ConditionFalse, Reason: BuildsDisabledReasonEnabled},
+	})
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const BuiltReasonSkippedBuiltCommit = "SkippedBuiltCommit"
+
+// getSucceededCommitBuild returns the name of a succeeded PipelineRun of the component which built the same commit
+// into the same image with the same pipeline as the given build PipelineRun. Empty name is returned
+// if the revision of the build is not an exact commit SHA, as branches and tags might point to new commits.
+func (r *ComponentBuildReconciler) getSucceededCommitBuild(ctx context.Context, component appstudiov1alpha1.Component, build tektonapi.PipelineRun) (string, error) {
+	if !commitSHARegexp.MatchString(getPipelineRunParam(build, "revision")) {
+		return "", nil
+	}
+
+	pipelineRuns, err := listComponentPipelineRuns(ctx, r.Client, component)
+	if err != nil {
+		return "", err
+	}
+	for _, pipelineRun := range pipelineRuns {
+		if getBuildState(pipelineRun) == BuildStateSucceeded && isSameCommitBuild(pipelineRun, build) {
+			return pipelineRun.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// isSameCommitBuild checks whether both PipelineRuns build the same commit of the same repository into the same image
+// with the same pipeline.
+func isSameCommitBuild(pipelineRun tektonapi.PipelineRun, build tektonapi.PipelineRun) bool {
+	for _, param := range []string{"revision", "git-url", "output-image"} {
+		if getPipelineRunParam(pipelineRun, param) != getPipelineRunParam(build, param) {
+			return false
+		}
+	}
+	return reflect.DeepEqual(pipelineRun.Spec.PipelineRef, build.Spec.PipelineRef)
+}
+
+func getSkippedBuiltCommitCondition(revision string, pipelineRunName string) metav1.Condition {
+	return metav1.Condition{
+		Type:    BuiltConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  BuiltReasonSkippedBuiltCommit,
+		Message: fmt.Sprintf("Commit %s has been built by PipelineRun %s already, the build is skipped", revision, pipelineRunName),
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+)
+
+func TestGetSucceededCommitBuild(t *testing.T) {
+	const commit = "0123456789abcdef0123456789abcdef01234567"
+	getBuild := func(name string, revision string, status corev1.ConditionStatus) tektonapi.PipelineRun {
+		pipelineRun := getCaptureTestPipelineRun()
+		pipelineRun.Name = name
+		pipelineRun.Spec.Params = append(pipelineRun.Spec.Params, tektonapi.Param{Name: "revision", Value: *tektonapi.NewArrayOrString(revision)})
+		if status != "" {
+			pipelineRun.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: status})
+		}
+		return pipelineRun
+	}
+	otherPipelineBuild := getBuild("other-pipeline", commit, corev1.ConditionTrue)
+	otherPipelineBuild.Spec.PipelineRef = &tektonapi.PipelineRef{Name: "nodejs-builder", Bundle: "quay.io/redhat-appstudio/build-templates-bundle:v0.1.3"}
+
+	tests := []struct {
+		name         string
+		revision     string
+		pipelineRuns []tektonapi.PipelineRun
+		want         string
+	}{
+		{
+			name:         "commit built",
+			revision:     commit,
+			pipelineRuns: []tektonapi.PipelineRun{getBuild("failed", commit, corev1.ConditionFalse), getBuild("succeeded", commit, corev1.ConditionTrue)},
+			want:         "succeeded",
+		},
+		{
+			name:         "commit build running",
+			revision:     commit,
+			pipelineRuns: []tektonapi.PipelineRun{getBuild("running", commit, "")},
+			want:         "",
+		},
+		{
+			name:         "other commit built",
+			revision:     commit,
+			pipelineRuns: []tektonapi.PipelineRun{getBuild("succeeded", "fedcba9876543210fedcba9876543210fedcba98", corev1.ConditionTrue)},
+			want:         "",
+		},
+		{
+			name:         "commit built with other pipeline",
+			revision:     commit,
+			pipelineRuns: []tektonapi.PipelineRun{otherPipelineBuild},
+			want:         "",
+		},
+		{
+			name:         "branch",
+			revision:     "main",
+			pipelineRuns: []tektonapi.PipelineRun{getBuild("succeeded", "main", corev1.ConditionTrue)},
+			want:         "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ComponentBuildReconciler{Client: &pipelineRunListClient{pipelineRuns: tt.pipelineRuns}}
+			got, err := r.getSucceededCommitBuild(context.TODO(), getGitSourceComponent(nil, ""), getBuild("", tt.revision, ""))
+			if err != nil {
+				t.Fatalf("getSucceededCommitBuild() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("getSucceededCommitBuild() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// BundleVerifiedConditionType reflects the result of signature verification of the build pipeline bundle
+	BundleVerifiedConditionType = "BundleVerified"
+
+	BundleVerifiedReasonVerified = "SignatureVerified"
+	BundleVerifiedReasonFailed   = "VerificationFailed"
+)
+
+// BundleVerifier verifies signatures of pipeline bundles.
+type BundleVerifier interface {
+	// Verify returns an error if the signature of the given bundle image is missing or invalid.
+	// Otherwise it returns the bundle reference pinned to the digest of the verified image.
+	Verify(ctx context.Context, bundle string) (string, error)
+}
+
+// verifyPipelineBundle verifies the signature of the pipeline bundle the build refers to
+// and reflects the result in the component condition.
+// The build is pinned to the digest of the verified bundle, so a tag moved after the verification is not used.
+// Builds which don't refer to a bundle are not verified.
+func (r *ComponentBuildReconciler) verifyPipelineBundle(ctx context.Context, component appstudiov1alpha1.Component, build *tektonapi.PipelineRun) error {
+	if build.Spec.PipelineRef == nil || build.Spec.PipelineRef.Bundle == "" {
+		return nil
+	}
+	bundle := build.Spec.PipelineRef.Bundle
+
+	verifiedBundle, verificationErr := r.BundleVerifier.Verify(ctx, bundle)
+	if verificationErr != nil {
+		if err := setComponentCondition(ctx, r.Client, component, metav1.Condition{
+			Type:    BundleVerifiedConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  BundleVerifiedReasonFailed,
+			Message: fmt.Sprintf("Signature verification of pipeline bundle %s failed: %v", bundle, verificationErr),
+		}); err != nil {
+			return err
+		}
+		return fmt.Errorf("signature verification of pipeline bundle %s failed: %v", bundle, verificationErr)
+	}
+	build.Spec.PipelineRef.Bundle = verifiedBundle
+
+	return setComponentCondition(ctx, r.Client, component, metav1.Condition{
+		Type:    BundleVerifiedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  BundleVerifiedReasonVerified,
+		Message: fmt.Sprintf("Signature of pipeline bundle %s is verified", verifiedBundle),
+	})
+}
+
+// CosignBundleVerifier verifies pipeline bundle signatures with cosign CLI and the configured public key.
+// The cosign binary is shipped in the controller image.
+type CosignBundleVerifier struct {
+	// CosignPath is the path to the cosign binary, empty means cosign from PATH
+	CosignPath string
+	// KeyPath is the path to the public key file or a KMS URI of the key the bundles are signed with
+	KeyPath string
+}
+
+// cosignVerificationPayload is the part of the signed payload printed by cosign verify for each verified signature
+type cosignVerificationPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+func (v *CosignBundleVerifier) Verify(ctx context.Context, bundle string) (string, error) {
+	cosignPath := v.CosignPath
+	if cosignPath == "" {
+		cosignPath = "cosign"
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, cosignPath, "verify", "--key", v.KeyPath, "--output", "json", bundle)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("%v: %s", err, message)
+		}
+		return "", err
+	}
+	return getVerifiedBundle(bundle, stdout.Bytes())
+}
+
+// getVerifiedBundle returns the bundle reference pinned to the digest the signatures printed by cosign verify are for.
+func getVerifiedBundle(bundle string, cosignOutput []byte) (string, error) {
+	var payloads []cosignVerificationPayload
+	if err := json.Unmarshal(cosignOutput, &payloads); err != nil {
+		return "", fmt.Errorf("unable to parse cosign verify output: %v", err)
+	}
+
+	digest := ""
+	for _, payload := range payloads {
+		payloadDigest := payload.Critical.Image.DockerManifestDigest
+		if payloadDigest == "" || (digest != "" && payloadDigest != digest) {
+			return "", fmt.Errorf("unable to get the digest of the verified bundle from cosign verify output")
+		}
+		digest = payloadDigest
+	}
+	if digest == "" {
+		return "", fmt.Errorf("cosign verify returned no verified signatures")
+	}
+	if i := strings.Index(bundle, "@"); i != -1 && bundle[i+1:] != digest {
+		return "", fmt.Errorf("verified digest %s does not match the bundle digest", digest)
+	}
+	return getImageRepository(bundle) + "@" + digest, nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCosignBundleVerifier(t *testing.T) {
+	// Fake cosign accepts only bundles signed with the key, i.e. with "signed" in the name
+	cosignPath := filepath.Join(t.TempDir(), "cosign")
+	cosignScript := `#!/bin/sh
+if [ "$1" != "verify" ] || [ "$2" != "--key" ] || [ "$3" != "cosign.pub" ] || [ "$4" != "--output" ] || [ "$5" != "json" ]; then
+  echo "unexpected arguments: $*" >&2
+  exit 2
+fi
+case "$6" in
+  *unsigned*) echo "no matching signatures" >&2; exit 1 ;;
+  *) echo '[{"critical":{"image":{"docker-manifest-digest":"sha256:abc"},"type":"cosign container image signature"}}]' ;;
+esac
+`
+	if err := os.WriteFile(cosignPath, []byte(cosignScript), 0700); err != nil {
+		t.Fatalf("failed to create fake cosign: %v", err)
+	}
+	verifier := &CosignBundleVerifier{CosignPath: cosignPath, KeyPath: "cosign.pub"}
+
+	verifiedBundle, err := verifier.Verify(context.TODO(), "quay.io/foo/signed-bundle:v1")
+	if err != nil || verifiedBundle != "quay.io/foo/signed-bundle@sha256:abc" {
+		t.Errorf("Verify() = %v, %v, want bundle pinned to the verified digest", verifiedBundle, err)
+	}
+	_, err = verifier.Verify(context.TODO(), "quay.io/foo/unsigned-bundle:v1")
+	if err == nil || !strings.Contains(err.Error(), "no matching signatures") {
+		t.Errorf("Verify() error = %v, want cosign verification error", err)
+	}
+
+	missingCosign := &CosignBundleVerifier{CosignPath: filepath.Join(t.TempDir(), "cosign"), KeyPath: "cosign.pub"}
+	if _, err := missingCosign.Verify(context.TODO(), "quay.io/foo/signed-bundle:v1"); err == nil {
+		t.Errorf("Verify() error = nil without cosign, want error")
+	}
+}
+
+func TestGetVerifiedBundle(t *testing.T) {
+	tests := []struct {
+		name    string
+		bundle  string
+		output  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "tag pinned to digest",
+			bundle: "quay.io/foo/bundle:v1",
+			output: `[{"critical":{"image":{"docker-manifest-digest":"sha256:abc"}}},{"critical":{"image":{"docker-manifest-digest":"sha256:abc"}}}]`,
+			want:   "quay.io/foo/bundle@sha256:abc",
+		},
+		{
+			name:   "registry with port",
+			bundle: "registry.local:5000/foo/bundle:v1",
+			output: `[{"critical":{"image":{"docker-manifest-digest":"sha256:abc"}}}]`,
+			want:   "registry.local:5000/foo/bundle@sha256:abc",
+		},
+		{
+			name:   "digest reference",
+			bundle: "quay.io/foo/bundle@sha256:abc",
+			output: `[{"critical":{"image":{"docker-manifest-digest":"sha256:abc"}}}]`,
+			want:   "quay.io/foo/bundle@sha256:abc",
+		},
+		{
+			name:    "digest mismatch",
+			bundle:  "quay.io/foo/bundle@sha256:abc",
+			output:  `[{"critical":{"image":{"docker-manifest-digest":"sha256:def"}}}]`,
+			wantErr: true,
+		},
+		{
+			name:    "signatures of different digests",
+			bundle:  "quay.io/foo/bundle:v1",
+			output:  `[{"critical":{"image":{"docker-manifest-digest":"sha256:abc"}}},{"critical":{"image":{"docker-manifest-digest":"sha256:def"}}}]`,
+			wantErr: true,
+		},
+		{
+			name:    "no signatures",
+			bundle:  "quay.io/foo/bundle:v1",
+			output:  `[]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid output",
+			bundle:  "quay.io/foo/bundle:v1",
+			output:  `Verification for quay.io/foo/bundle:v1 --`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getVerifiedBundle(tt.bundle, []byte(tt.output))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getVerifiedBundle() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("getVerifiedBundle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
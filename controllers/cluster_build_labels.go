@@ -0,0 +1,84 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// getClusterBuildLabels returns the labels from the data of the cluster build labels ConfigMap.
+// Missing ConfigMap means no labels.
+func (r *ComponentBuildReconciler) getClusterBuildLabels(ctx context.Context) (map[string]string, error) {
+	if r.ClusterBuildLabels == nil {
+		return nil, nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, *r.ClusterBuildLabels, configMap); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := validateClusterBuildLabels(configMap.Data); err != nil {
+		return nil, fmt.Errorf("invalid cluster build labels in ConfigMap %v: %v", *r.ClusterBuildLabels, err)
+	}
+	return configMap.Data, nil
+}
+
+// validateClusterBuildLabels checks that the keys and values are valid label keys and values.
+func validateClusterBuildLabels(labels map[string]string) error {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid label key %s: %s", key, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(labels[key]); len(errs) > 0 {
+			return fmt.Errorf("invalid value %s of label %s: %s", labels[key], key, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// applyClusterBuildLabels adds the cluster build labels to the build PipelineRun.
+// Labels set by the build service, e.g. the component name, are not overridden.
+func applyClusterBuildLabels(labels map[string]string, pipelineRun *tektonapi.PipelineRun) {
+	if len(labels) == 0 {
+		return
+	}
+	if pipelineRun.Labels == nil {
+		pipelineRun.Labels = map[string]string{}
+	}
+	for key, value := range labels {
+		if _, exists := pipelineRun.Labels[key]; !exists {
+			pipelineRun.Labels[key] = value
+		}
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateClusterBuildLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "no labels",
+			labels: map[string]string{},
+		},
+		{
+			name:   "valid labels",
+			labels: map[string]string{"example.com/cluster": "member-1", "region": "us-east-1", "empty": ""},
+		},
+		{
+			name:    "invalid key",
+			labels:  map[string]string{"cluster name": "member-1"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			labels:  map[string]string{"cluster": "member 1"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateClusterBuildLabels(tt.labels); (err != nil) != tt.wantErr {
+				t.Errorf("validateClusterBuildLabels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyClusterBuildLabels(t *testing.T) {
+	tests := []struct {
+		name              string
+		labels            map[string]string
+		pipelineRunLabels map[string]string
+		want              map[string]string
+	}{
+		{
+			name:              "no labels",
+			labels:            nil,
+			pipelineRunLabels: map[string]string{ComponentNameLabelName: "my-component"},
+			want:              map[string]string{ComponentNameLabelName: "my-component"},
+		},
+		{
+			name:              "labels added",
+			labels:            map[string]string{"example.com/cluster": "member-1"},
+			pipelineRunLabels: nil,
+			want:              map[string]string{"example.com/cluster": "member-1"},
+		},
+		{
+			name:              "build service labels are not overridden",
+			labels:            map[string]string{"example.com/cluster": "member-1", ComponentNameLabelName: "other", BuildTierLabelName: "premium"},
+			pipelineRunLabels: map[string]string{ComponentNameLabelName: "my-component", BuildTierLabelName: "base"},
+			want:              map[string]string{"example.com/cluster": "member-1", ComponentNameLabelName: "my-component", BuildTierLabelName: "base"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipelineRun := tektonapi.PipelineRun{ObjectMeta: metav1.ObjectMeta{Labels: tt.pipelineRunLabels}}
+			applyClusterBuildLabels(tt.labels, &pipelineRun)
+			if !reflect.DeepEqual(pipelineRun.Labels, tt.want) {
+				t.Errorf("applyClusterBuildLabels() = %v, want %v", pipelineRun.Labels, tt.want)
+			}
+		})
+	}
+}
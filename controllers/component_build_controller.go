@@ -19,29 +19,59 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
+	"regexp"
+	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/go-logr/logr"
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 
 	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
 	"github.com/redhat-appstudio/application-service/gitops"
 	"github.com/redhat-appstudio/application-service/gitops/prepare"
+	buildv1alpha1 "github.com/redhat-appstudio/build-service/api/v1alpha1"
+	"github.com/redhat-appstudio/build-service/pkg/tektonresults"
 )
 
 const (
 	InitialBuildAnnotationName = "com.redhat.appstudio/component-initial-build-happend"
+	// Go template for the output image tag, e.g. {{.Component}}-{{.BuildNumber}}
+	ImageTagFormatAnnotationName = "build.appstudio.openshift.io/image-tag-format"
+	// Sequence number of the last build of the component, recorded when the image tag format is set
+	BuildNumberAnnotationName = "build.appstudio.openshift.io/build-number"
+	// JSON object with additional pipeline parameters, values could reference component fields, e.g. {{.Namespace}}
+	PipelineParamsAnnotationName = "build.appstudio.openshift.io/pipeline-params"
+	// Number of builds resubmitted because the previous build failed with a retryable failure, e.g. a node drain or a network error
+	DisruptionRetriesAnnotationName = "build.appstudio.openshift.io/disruption-retries"
+	// If set to "true", a TriggerTemplate and an EventListener are created to build the component on git push events
+	CreateEventListenerAnnotationName = "build.appstudio.openshift.io/create-event-listener"
+	// Name of the Secret with known_hosts file of the git server, e.g. for on-premise git servers
+	SSHKnownHostsSecretAnnotationName = "build.appstudio.openshift.io/ssh-known-hosts-secret"
+
+	ComponentNameLabelName = "build.appstudio.openshift.io/component"
+
+	maxDisruptionRetries = 1
 )
 
 // ComponentBuildReconciler watches AppStudio Component object in order to submit builds
@@ -50,26 +80,286 @@ type ComponentBuildReconciler struct {
 	NonCachingClient client.Client
 	Scheme           *runtime.Scheme
 	Log              logr.Logger
+	// NamespaceSelector restricts builds to components in namespaces with matching labels, nil means all namespaces
+	NamespaceSelector labels.Selector
+	// KeepStalePipelineRuns disables deletion of PipelineRuns left from a previous component with the same name
+	KeepStalePipelineRuns bool
+	// ImageRepositoryClient is used to check the build output image repository exists, nil disables the check
+	ImageRepositoryClient ImageRepositoryClient
+	// AutoCreateImageRepository enables creation of missing build output image repositories
+	AutoCreateImageRepository bool
+	// MaxConcurrentBuilds limits the number of active builds in the whole cluster, 0 means no limit
+	MaxConcurrentBuilds int
+	// LegacyComponentLabelName is the previous key of the component label, PipelineRuns labeled with it are relabeled
+	LegacyComponentLabelName string
+	// BuildsDisabled turns off submission of all builds until the controller is restarted without it,
+	// the components are still reconciled and report the reasons their build would wait for in conditions
+	BuildsDisabled bool
+	// GitSecretRotationPolicy defines whether components are annotated again or rebuilt when their git Secret is rotated
+	GitSecretRotationPolicy GitSecretRotationPolicy
+	// MaxPipelineRunSize is the maximum size in bytes of the serialized build PipelineRun, larger builds are not submitted
+	// and the component gets PipelineRunTooLarge condition instead of an opaque create failure. 0 disables the check
+	MaxPipelineRunSize int
+	// MaintenanceConfigMap is the ConfigMap which pauses submission of all builds when in maintenance mode, nil disables the check
+	MaintenanceConfigMap *types.NamespacedName
+	// maintenanceConfigMapCache caches only the maintenance ConfigMap, it is set up with the manager
+	maintenanceConfigMapCache cache.Cache
+	// TektonNamespace is the namespace with Tekton feature-flags ConfigMap, empty means the upstream default namespace
+	TektonNamespace string
+	// featureFlagsConfigMapCache caches only the Tekton feature-flags ConfigMap, it is set up with the manager
+	featureFlagsConfigMapCache cache.Cache
+	// SkipExistingImageBuild disables the initial build of a commit if the registry has the output image tagged with the commit already
+	SkipExistingImageBuild bool
+	// BuildAuditEnabled turns on creation of BuildAuditRecord for each submitted build
+	BuildAuditEnabled bool
+	// BuildSubmitter is the user name of the controller recorded in the build audit records
+	BuildSubmitter string
+	// ImageNameFunc returns the output image of the component build, nil means DefaultImageName
+	ImageNameFunc ImageNameFunc
+	// BuildApprover approves builds of components which require approval, nil disables the approval
+	BuildApprover BuildApprover
+	// BuildApprovalPollInterval is the interval the approval of pending builds is requested again, 0 means 30 seconds
+	BuildApprovalPollInterval time.Duration
+	// ApprovalWebhookAllowedHosts are hosts of approval webhooks allowed in Component annotations,
+	// *.domain allows subdomains of the domain. Approval webhooks of Components are denied if empty.
+	ApprovalWebhookAllowedHosts []string
+	// webhookHTTPClient overrides the client for approval webhooks of Components in tests
+	webhookHTTPClient *http.Client
+	// DevfileWaitTimeout is the time after creation of a git source component its missing devfile model
+	// is reported as a warning, 0 means the component waits for the devfile model silently
+	DevfileWaitTimeout time.Duration
+	// DevfileRecheckInterval is the interval components whose devfile model wait timed out are checked again, 0 disables the rechecks
+	DevfileRecheckInterval time.Duration
+	// BundleVerifier verifies signature of the build pipeline bundle before the build, nil disables the verification
+	BundleVerifier BundleVerifier
+	// BuildTiersConfigMap is the ConfigMap with build profiles of the component tiers, nil disables the build tiers
+	BuildTiersConfigMap *types.NamespacedName
+	// RepoSizeScalingConfigMap is the ConfigMap with scaling of builds by the git repository size,
+	// nil disables the scaling
+	RepoSizeScalingConfigMap *types.NamespacedName
+	// ClusterBuildLabels is the ConfigMap with labels added to all build PipelineRuns in its data,
+	// e.g. the cluster identity for multi-cluster monitoring, nil disables the labels
+	ClusterBuildLabels *types.NamespacedName
+	// BuildEnvironmentsNamespace is the namespace with ConfigMaps of pipeline parameters of build environments
+	// namespaces are labeled with, empty disables the environment parameters
+	BuildEnvironmentsNamespace string
+	// BuildLogRetention is the default build log retention stamped on build PipelineRuns, 0 means no retention annotation
+	BuildLogRetention time.Duration
+	// ResubmitDeletedBuilds turns on resubmission of builds whose PipelineRun is deleted before completion
+	ResubmitDeletedBuilds bool
+	// BuildServiceConfigEnabled turns on overrides of the build defaults by BuildServiceConfig of the component namespace
+	BuildServiceConfigEnabled bool
+	// SecretCacheTTL is the time git Secrets are cached for to reduce reads of Secrets shared by many components, 0 disables the cache
+	SecretCacheTTL time.Duration
+	// PipelineRunGenerationWorkers is the number of workers generating build PipelineRuns in background,
+	// so large devfiles do not block reconciles, 0 means the PipelineRuns are generated within the reconcile
+	PipelineRunGenerationWorkers int
+	// UnknownApplicationPolicy defines whether components referencing a nonexistent Application are built, empty means Ignore
+	UnknownApplicationPolicy UnknownApplicationPolicy
+	// Recorder emits events on components, e.g. with the changes which caused a rebuild, nil disables the events
+	Recorder record.EventRecorder
+	// SecretLinkingStrategy defines the pipeline Service Account fields build Secrets are linked in, empty means SecretField
+	SecretLinkingStrategy SecretLinkingStrategy
+	// PruneServiceAccountSecrets turns on removal of links to nonexistent Secrets from the pipeline Service Account
+	PruneServiceAccountSecrets bool
+	// StrictGitSecretValidation skips builds of components whose git Secret has no usable credentials,
+	// otherwise the builds are submitted with the InvalidGitSecret condition set
+	StrictGitSecretValidation bool
+	// ServiceAccountTokenClient refreshes expiring pipeline Service Account tokens of components which request it, nil disables the refresh
+	ServiceAccountTokenClient ServiceAccountTokenClient
+	// TektonResultsAPIAddress is the host:port of the Tekton Results API completed builds are read from
+	// into the BuildResults condition of components, empty disables reading of the build results
+	TektonResultsAPIAddress string
+	// PipelineRunAPIVersion is the Tekton API version build PipelineRuns are created with,
+	// empty means the preferred version served by the cluster
+	PipelineRunAPIVersion PipelineRunAPIVersion
+	// ReconcileAllOnStartup turns on reconcile of all components whose build relevant fields have not been reconciled
+	// once the controller starts, e.g. components created while the controller was down
+	ReconcileAllOnStartup bool
+	// BuildPipelinesConfigMap is the ConfigMap with build pipeline names of devfile languages in its data,
+	// e.g. java: java-build-pipeline, nil means the pipeline detected by the build generator
+	BuildPipelinesConfigMap *types.NamespacedName
+	// ImmutableTagPolicy defines how builds whose output image tag exists already and is immutable are handled,
+	// the check requires ImageRepositoryClient supporting immutable tags, empty means Ignore
+	ImmutableTagPolicy ImmutableTagPolicy
+
+	secretCache          *secretCache
+	pipelineRunGenerator *pipelineRunGenerator
+	tektonResultsClient  BuildResultsClient
+	startupEvents        chan event.GenericEvent
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ComponentBuildReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	if r.SecretCacheTTL > 0 {
+		r.secretCache = newSecretCache(r.NonCachingClient, r.SecretCacheTTL)
+	}
+	if r.PipelineRunGenerationWorkers > 0 {
+		r.pipelineRunGenerator = newPipelineRunGenerator(r.PipelineRunGenerationWorkers)
+	}
+	if r.TektonResultsAPIAddress != "" {
+		tektonResultsClient, err := tektonresults.Dial(r.TektonResultsAPIAddress, tektonresults.DefaultTokenFile)
+		if err != nil {
+			return err
+		}
+		r.tektonResultsClient = tektonResultsClient
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &tektonapi.PipelineRun{}, activeBuildIndexKey, indexActiveBuild); err != nil {
+		return err
+	}
+	// The index is used also by the build PipelineRun reconciler and the build status server
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &tektonapi.PipelineRun{}, componentPipelineRunIndexKey, indexComponentPipelineRun); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &appstudiov1alpha1.Component{}, buildSpecChangedIndexKey, indexBuildSpecChanged); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &appstudiov1alpha1.Component{}, pipelineTemplateConfigMapIndexKey, indexPipelineTemplateConfigMap); err != nil {
+		return err
+	}
+
+	controllerBuilder := ctrl.NewControllerManagedBy(mgr).
 		For(&appstudiov1alpha1.Component{}, builder.WithPredicates(predicate.Funcs{
 			CreateFunc: func(e event.CreateEvent) bool {
 				return true
 			},
 			UpdateFunc: func(e event.UpdateEvent) bool {
-				return true
+				// Do not reconcile on status conditions or deployment settings changes
+				return isBuildRelevantUpdate(e)
 			},
 			DeleteFunc: func(e event.DeleteEvent) bool {
+				// Stop counting deleted components in the build phase metric
+				return true
+			},
+			GenericFunc: func(e event.GenericEvent) bool {
 				return false
 			},
+		}, predicate.NewPredicateFuncs(r.isInSelectedNamespace)))
+
+	if r.NamespaceSelector != nil {
+		// Build components of a namespace when it gets onboarded
+		controllerBuilder = controllerBuilder.Watches(
+			&source.Kind{Type: &corev1.Namespace{}},
+			handler.EnqueueRequestsFromMapFunc(r.getSelectedNamespaceComponents),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}))
+	}
+
+	if r.GitSecretRotationPolicy.watchesSecrets() {
+		// Reconcile components using a git Secret when it is rotated, only metadata of the Secrets is cached
+		controllerBuilder = controllerBuilder.Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(r.getGitSecretComponents),
+			builder.OnlyMetadata,
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}))
+	}
+
+	// Build components waiting for the component whose successful build has been just recorded
+	controllerBuilder = controllerBuilder.Watches(
+		&source.Kind{Type: &appstudiov1alpha1.Component{}},
+		handler.EnqueueRequestsFromMapFunc(r.getDependentComponents),
+		builder.WithPredicates(predicate.Funcs{
+			UpdateFunc: isDependencyUpdate,
 			GenericFunc: func(e event.GenericEvent) bool {
 				return false
 			},
-		})).
-		Complete(r)
+		}))
+
+	if r.TektonResultsAPIAddress != "" {
+		// Record results of completed builds once they are stored in Tekton Results
+		controllerBuilder = controllerBuilder.Watches(
+			&source.Kind{Type: &tektonapi.PipelineRun{}},
+			handler.EnqueueRequestsFromMapFunc(getStoredBuildComponent),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
+				_, isComponentBuild := object.GetLabels()[ComponentNameLabelName]
+				return isComponentBuild
+			})))
+	}
+
+	if r.MaintenanceConfigMap != nil {
+		maintenanceConfigMapCache, err := newConfigMapCache(mgr, *r.MaintenanceConfigMap)
+		if err != nil {
+			return err
+		}
+		r.maintenanceConfigMapCache = maintenanceConfigMapCache
+		// Submit paused builds when the maintenance mode is lifted
+		controllerBuilder = controllerBuilder.Watches(
+			source.NewKindWithCache(&corev1.ConfigMap{}, maintenanceConfigMapCache),
+			handler.EnqueueRequestsFromMapFunc(r.getPausedComponents),
+			builder.WithPredicates(predicate.NewPredicateFuncs(r.isMaintenanceConfigMap)))
+	}
+
+	featureFlagsConfigMapCache, err := newConfigMapCache(mgr, r.getTektonFeatureFlagsConfigMap())
+	if err != nil {
+		return err
+	}
+	r.featureFlagsConfigMapCache = featureFlagsConfigMapCache
+	// Build components waiting for Tekton feature flags when the flags change
+	controllerBuilder = controllerBuilder.Watches(
+		source.NewKindWithCache(&corev1.ConfigMap{}, featureFlagsConfigMapCache),
+		handler.EnqueueRequestsFromMapFunc(r.getFeatureFlagsComponents),
+		builder.WithPredicates(predicate.NewPredicateFuncs(r.isTektonFeatureFlagsConfigMap)))
+
+	// Update build TriggerTemplates of components when their pipeline template ConfigMap changes,
+	// only metadata of the ConfigMaps is cached and the components are looked up by the index
+	controllerBuilder = controllerBuilder.Watches(
+		&source.Kind{Type: &corev1.ConfigMap{}},
+		handler.EnqueueRequestsFromMapFunc(r.getPipelineTemplateComponents),
+		builder.OnlyMetadata,
+		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}))
+
+	if r.BuildServiceConfigEnabled {
+		// Apply changed namespace build configuration to builds which have not been submitted yet
+		controllerBuilder = controllerBuilder.Watches(
+			&source.Kind{Type: &buildv1alpha1.BuildServiceConfig{}},
+			handler.EnqueueRequestsFromMapFunc(r.getBuildServiceConfigComponents))
+	}
+
+	if r.UnknownApplicationPolicy == UnknownApplicationPolicyBlock {
+		// Build components waiting for creation of their Application
+		controllerBuilder = controllerBuilder.Watches(
+			&source.Kind{Type: &appstudiov1alpha1.Application{}},
+			handler.EnqueueRequestsFromMapFunc(r.getApplicationComponents),
+			builder.WithPredicates(predicate.Funcs{
+				CreateFunc: func(e event.CreateEvent) bool {
+					return true
+				},
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					return false
+				},
+				DeleteFunc: func(e event.DeleteEvent) bool {
+					return false
+				},
+				GenericFunc: func(e event.GenericEvent) bool {
+					return false
+				},
+			}))
+	}
+
+	if r.pipelineRunGenerator != nil {
+		// Submit builds of components whose build PipelineRun has been generated in background
+		controllerBuilder = controllerBuilder.Watches(
+			&source.Channel{Source: r.pipelineRunGenerator.events},
+			&handler.EnqueueRequestForObject{})
+	}
+
+	if r.ReconcileAllOnStartup {
+		// Build components created or updated while the controller was down
+		r.startupEvents = make(chan event.GenericEvent)
+		if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			if !mgr.GetCache().WaitForCacheSync(ctx) {
+				return nil
+			}
+			return r.reconcileStartupComponents(ctx)
+		})); err != nil {
+			return err
+		}
+		controllerBuilder = controllerBuilder.Watches(
+			&source.Channel{Source: r.startupEvents},
+			&handler.EnqueueRequestForObject{})
+	}
+
+	return controllerBuilder.Complete(r)
 }
 
 //+kubebuilder:rbac:groups=appstudio.redhat.com,resources=components,verbs=get;list;watch;update;patch
@@ -91,44 +381,377 @@ func (r *ComponentBuildReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			componentPhases.Delete(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
 		return ctrl.Result{}, err
 	}
+	log = withBuildFields(r.Log, component, buildPhaseReconcile)
+
+	selected, err := r.isSelectedNamespace(ctx, component.Namespace)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Failed to get namespace %s", component.Namespace))
+		return ctrl.Result{}, err
+	}
+	if !selected {
+		// Enqueued by a watch of another resource, the component is reconciled once its namespace is onboarded
+		return ctrl.Result{}, nil
+	}
+
+	if r.LegacyComponentLabelName != "" {
+		if err := r.relabelLegacyPipelineRuns(ctx, component); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if r.tektonResultsClient != nil {
+		recorded, err := r.recordBuildResults(ctx, component)
+		if err != nil {
+			// Build results are informational, do not block builds when Tekton Results is unavailable
+			log.Error(err, fmt.Sprintf("Failed to record build results of component %v", req.NamespacedName))
+		}
+		if recorded {
+			// Continue with the updated component
+			if err := r.Client.Get(ctx, req.NamespacedName, &component); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	if err := r.handleGitSecretRotation(ctx, log, &component); err != nil {
+		log.Error(err, fmt.Sprintf("Failed to handle git Secret rotation of component %v", req.NamespacedName))
+		return ctrl.Result{}, err
+	}
+
+	if hasPipelineTemplateConfigMap(component) && component.Annotations[InitialBuildAnnotationName] == "true" {
+		// The build trigger has been created by the build submission, keep it in sync with the ConfigMap
+		if err := r.syncConfigMapTriggerTemplates(ctx, component); err != nil {
+			log.Error(err, fmt.Sprintf("Failed to update build TriggerTemplates of component %v", req.NamespacedName))
+			return ctrl.Result{}, err
+		}
+	}
+
+	decision := getInitialBuildDecision(component)
+	componentPhases.Set(req.NamespacedName, getComponentBuildPhase(decision))
+
+	if !isBuildSpecChanged(component) {
+		if decision.Reason == BuildDecisionReasonWaitingForDevfile {
+			// Requeued to check whether the devfile model is still missing
+			return r.waitForDevfile(ctx, log, component), nil
+		}
+		// The same build relevant state has been reconciled already
+		return ctrl.Result{}, nil
+	}
+
+	if !decision.BuildRequired {
+		switch decision.Reason {
+		case BuildDecisionReasonContainerImage:
+			log.Info(fmt.Sprintf("Nothing to do for container image component: %v", req.NamespacedName))
+		case BuildDecisionReasonNoSource:
+			log.Info(fmt.Sprintf("Component %v has neither git nor image source", req.NamespacedName))
+		case BuildDecisionReasonWaitingForDevfile:
+			log.Info(fmt.Sprintf("Waiting for devfile model in component: %v", req.NamespacedName))
+		}
+		// Initial build have already happend or is not needed, nothing to do.
+		setBuildSpecHash(&component)
+		if err := r.Client.Update(ctx, &component); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to record build spec hash of component %v", req.NamespacedName))
+			return ctrl.Result{}, err
+		}
+		if decision.Reason == BuildDecisionReasonWaitingForDevfile {
+			return r.waitForDevfile(ctx, log, component), nil
+		}
+		if err := clearWaitingForDevfileCondition(ctx, r.Client, component); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to clear %s condition for component %v", WaitingForDevfileConditionType, req.NamespacedName))
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if err := clearWaitingForDevfileCondition(ctx, r.Client, component); err != nil {
+		log.Error(err, fmt.Sprintf("Unable to clear %s condition for component %v", WaitingForDevfileConditionType, req.NamespacedName))
+	}
+
+	maintenanceMode, err := r.isMaintenanceMode(ctx)
+	if err != nil {
+		log.Error(err, "Failed to check maintenance mode")
+		return ctrl.Result{}, err
+	}
+	if maintenanceMode {
+		condition := getMaintenanceModeCondition(*r.MaintenanceConfigMap)
+		log.Info(fmt.Sprintf("Build of component %v is paused: %s", req.NamespacedName, condition.Message))
+		if err := setComponentCondition(ctx, r.Client, component, condition); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to set %s condition for component %v", condition.Type, req.NamespacedName))
+		}
+		// The component is requeued when the maintenance ConfigMap changes
+		return ctrl.Result{}, nil
+	}
+
+	freezeWindows, err := getFreezeWindows(component)
+	if err != nil {
+		// Invalid freeze windows do not freeze builds, the annotation might be fixed later
+		log.Error(err, fmt.Sprintf("Ignoring freeze windows of component %v", req.NamespacedName))
+	}
+	if freezeWindows != nil && !(freezeWindows.AllowManual && isManualBuild(component)) {
+		if freezeEnd := getFreezeEnd(*freezeWindows, time.Now()); !freezeEnd.IsZero() {
+			condition := getFreezeActiveCondition(freezeEnd)
+			log.Info(fmt.Sprintf("Build of component %v is suppressed: %s", req.NamespacedName, condition.Message))
+			if err := setComponentCondition(ctx, r.Client, component, condition); err != nil {
+				log.Error(err, fmt.Sprintf("Unable to set %s condition for component %v", condition.Type, req.NamespacedName))
+			}
+			return ctrl.Result{RequeueAfter: time.Until(freezeEnd)}, nil
+		}
+	}
 
-	// Do not run any builds for any container-image components
-	if component.Spec.Source.ImageSource != nil && component.Spec.Source.ImageSource.ContainerImage != "" {
-		log.Info(fmt.Sprintf("Nothing to do for container image component: %v", req.NamespacedName))
+	if r.UnknownApplicationPolicy.checksApplication() {
+		applicationMissing, err := r.isApplicationMissing(ctx, component)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("Failed to get Application %s of component %v", component.Spec.Application, req.NamespacedName))
+			return ctrl.Result{}, err
+		}
+		if applicationMissing {
+			condition := getUnknownApplicationCondition(component, r.UnknownApplicationPolicy)
+			log.Info(fmt.Sprintf("Component %v references unknown Application: %s", req.NamespacedName, condition.Message))
+			if err := setComponentCondition(ctx, r.Client, component, condition); err != nil {
+				log.Error(err, fmt.Sprintf("Unable to set %s condition for component %v", condition.Type, req.NamespacedName))
+			}
+			if r.UnknownApplicationPolicy == UnknownApplicationPolicyBlock {
+				// The component is requeued when the Application is created
+				return ctrl.Result{}, nil
+			}
+		} else if err := clearUnknownApplicationCondition(ctx, r.Client, component); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to clear %s condition for component %v", UnknownApplicationConditionType, req.NamespacedName))
+		}
+	}
+
+	dependencyCycle, err := r.getDependencyCycle(ctx, component)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Failed to check build dependencies of component %v", req.NamespacedName))
+		return ctrl.Result{}, err
+	}
+	if dependencyCycle != nil {
+		condition := getDependencyCycleCondition(dependencyCycle)
+		log.Info(fmt.Sprintf("Build of component %v is postponed: %s", req.NamespacedName, condition.Message))
+		if err := setComponentCondition(ctx, r.Client, component, condition); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to set %s condition for component %v", condition.Type, req.NamespacedName))
+		}
+		// The component is requeued when the dependencies of the components in the cycle change
 		return ctrl.Result{}, nil
 	}
 
-	if component.Status.Devfile == "" {
-		// The component has been just created.
-		// Component controller must set devfile model, wait for it.
-		log.Info(fmt.Sprintf("Waiting for devfile model in component: %v", req.NamespacedName))
-		// Do not requeue as after model update a new update event will trigger a new reconcile
+	pendingDependencies, err := r.getPendingDependencies(ctx, component)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Failed to check build dependencies of component %v", req.NamespacedName))
+		return ctrl.Result{}, err
+	}
+	if len(pendingDependencies) > 0 {
+		condition := getWaitingForComponentsCondition(pendingDependencies)
+		log.Info(fmt.Sprintf("Build of component %v is postponed: %s", req.NamespacedName, condition.Message))
+		if err := setComponentCondition(ctx, r.Client, component, condition); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to set %s condition for component %v", condition.Type, req.NamespacedName))
+		}
+		// The component is requeued when a build of the components it waits for succeeds
 		return ctrl.Result{}, nil
 	}
 
+	missingCredentials, err := r.getMissingGitCredentials(ctx, component)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Failed to check git credentials of component %v", req.NamespacedName))
+		return ctrl.Result{}, err
+	}
+	if missingCredentials != "" {
+		condition := getMissingCredentialsCondition(missingCredentials)
+		log.Info(fmt.Sprintf("Build of component %v is skipped: %s", req.NamespacedName, condition.Message))
+		if err := setComponentCondition(ctx, r.Client, component, condition); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to set %s condition for component %v", condition.Type, req.NamespacedName))
+		}
+		if component.Spec.Secret == "" {
+			// The component is reconciled again when the git Secret is set
+			return ctrl.Result{}, nil
+		}
+		// Secrets are not watched, check whether the git Secret has been created later
+		return ctrl.Result{RequeueAfter: missingCredentialsRequeueInterval}, nil
+	}
+
+	invalidGitSecret, err := r.getInvalidGitSecret(ctx, component)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Failed to validate git Secret of component %v", req.NamespacedName))
+		return ctrl.Result{}, err
+	}
+	if invalidGitSecret != "" {
+		condition := getInvalidGitSecretCondition(invalidGitSecret)
+		if err := setComponentCondition(ctx, r.Client, component, condition); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to set %s condition for component %v", condition.Type, req.NamespacedName))
+		}
+		if r.StrictGitSecretValidation {
+			log.Info(fmt.Sprintf("Build of component %v is skipped: %s", req.NamespacedName, condition.Message))
+			// Secrets are not watched, check whether the git Secret has been fixed later
+			return ctrl.Result{RequeueAfter: missingCredentialsRequeueInterval}, nil
+		}
+		log.Info(condition.Message)
+	} else if err := clearInvalidGitSecretCondition(ctx, r.Client, component); err != nil {
+		log.Error(err, fmt.Sprintf("Unable to clear %s condition for component %v", InvalidGitSecretConditionType, req.NamespacedName))
+	}
+
+	featureFlagsCondition, err := r.getFeatureFlagsNotSatisfiedCondition(ctx, component)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Failed to check Tekton feature flags required by component %v", req.NamespacedName))
+		return ctrl.Result{}, err
+	}
+	if featureFlagsCondition != nil {
+		log.Info(fmt.Sprintf("Build of component %v is postponed: %s", req.NamespacedName, featureFlagsCondition.Message))
+		if err := setComponentCondition(ctx, r.Client, component, *featureFlagsCondition); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to set %s condition for component %v", featureFlagsCondition.Type, req.NamespacedName))
+		}
+		// The component is requeued when the Tekton feature flags or the annotation change
+		return ctrl.Result{}, nil
+	} else if err := clearFeatureFlagsNotSatisfiedCondition(ctx, r.Client, component); err != nil {
+		log.Error(err, fmt.Sprintf("Unable to clear %s condition for component %v", FeatureFlagsNotSatisfiedConditionType, req.NamespacedName))
+	}
+
+	if r.isBuildApprovalRequired(component) {
+		gitopsConfig := prepare.PrepareGitopsConfig(ctx, r.NonCachingClient, component)
+		if !r.pipelineRunGenerator.Schedule(component, gitopsConfig) {
+			log.Info(fmt.Sprintf("Generating build PipelineRun of component %v for approval", req.NamespacedName))
+			// The component is requeued when the generation finishes
+			return ctrl.Result{RequeueAfter: pipelineRunGenerationRequeueInterval}, nil
+		}
+		proposedBuild := r.pipelineRunGenerator.Peek(component, gitopsConfig)
+		approval, err := r.getBuildApproval(ctx, component, decision, proposedBuild)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("Failed to get build approval of component %v", req.NamespacedName))
+			return ctrl.Result{}, err
+		}
+		if approval.State != BuildApprovalStateApproved {
+			condition := getPendingApprovalCondition(approval)
+			log.Info(fmt.Sprintf("Build of component %v is not approved: %s", req.NamespacedName, condition.Message))
+			if err := setComponentCondition(ctx, r.Client, component, condition); err != nil {
+				log.Error(err, fmt.Sprintf("Unable to set %s condition for component %v", condition.Type, req.NamespacedName))
+			}
+			if approval.State == BuildApprovalStateDenied {
+				// The approval is requested again on the next build relevant change of the component
+				return ctrl.Result{}, nil
+			}
+			return ctrl.Result{RequeueAfter: r.getBuildApprovalPollInterval()}, nil
+		}
+	}
+
+	if r.MaxConcurrentBuilds > 0 {
+		activeBuilds, err := r.countActiveBuilds(ctx)
+		if err != nil {
+			log.Error(err, "Failed to count active builds")
+			return ctrl.Result{}, err
+		}
+		if activeBuilds >= r.MaxConcurrentBuilds {
+			condition := getBuildBlockedCondition(activeBuilds, r.MaxConcurrentBuilds)
+			log.Info(fmt.Sprintf("Build of component %v is blocked: %s", req.NamespacedName, condition.Message))
+			if err := setComponentCondition(ctx, r.Client, component, condition); err != nil {
+				log.Error(err, fmt.Sprintf("Unable to set %s condition for component %v", condition.Type, req.NamespacedName))
+			}
+			return ctrl.Result{RequeueAfter: blockedBuildRequeueInterval}, nil
+		}
+	}
+
+	buildServiceConfig, err := r.getBuildServiceConfig(ctx, component.Namespace)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Failed to get build service configuration of namespace %s", component.Namespace))
+		return ctrl.Result{}, err
+	}
+	if buildServiceConfig != nil && buildServiceConfig.MaxConcurrentBuilds > 0 {
+		buildNamespace, err := getBuildNamespace(component)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("Failed to get build namespace of component %v", req.NamespacedName))
+			return ctrl.Result{}, err
+		}
+		// The builds are counted where they run
+		activeBuilds, err := r.countActiveBuilds(ctx, client.InNamespace(buildNamespace))
+		if err != nil {
+			log.Error(err, fmt.Sprintf("Failed to count active builds in namespace %s", buildNamespace))
+			return ctrl.Result{}, err
+		}
+		if activeBuilds >= int(buildServiceConfig.MaxConcurrentBuilds) {
+			condition := getNamespaceBuildBlockedCondition(activeBuilds, buildServiceConfig.MaxConcurrentBuilds)
+			log.Info(fmt.Sprintf("Build of component %v is blocked: %s", req.NamespacedName, condition.Message))
+			if err := setComponentCondition(ctx, r.Client, component, condition); err != nil {
+				log.Error(err, fmt.Sprintf("Unable to set %s condition for component %v", condition.Type, req.NamespacedName))
+			}
+			return ctrl.Result{RequeueAfter: blockedBuildRequeueInterval}, nil
+		}
+	}
+
+	pendingGitOpsSync, err := r.getPendingGitOpsSync(ctx, component)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Failed to check Argo CD Application sync status of component %v", req.NamespacedName))
+		return ctrl.Result{}, err
+	}
+	if pendingGitOpsSync != "" {
+		componentPhases.Set(req.NamespacedName, componentBuildPhaseWaitingForGitOpsSync)
+		condition := getWaitingForGitOpsSyncCondition(pendingGitOpsSync)
+		log.Info(fmt.Sprintf("Build of component %v is postponed: %s", req.NamespacedName, condition.Message))
+		if err := setComponentCondition(ctx, r.Client, component, condition); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to set %s condition for component %v", condition.Type, req.NamespacedName))
+		}
+		// Argo CD Applications are not watched
+		return ctrl.Result{RequeueAfter: argoCDSyncPollInterval}, nil
+	}
+
+	if r.BuildsDisabled {
+		condition := getBuildsDisabledCondition()
+		log.Info(fmt.Sprintf("Build of component %v is not submitted: %s", req.NamespacedName, condition.Message))
+		if err := setComponentCondition(ctx, r.Client, component, condition); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to set %s condition for component %v", condition.Type, req.NamespacedName))
+		}
+		// The build spec hash is not recorded, so the component is built after restart of the controller with builds enabled
+		return ctrl.Result{}, nil
+	}
+
+	if r.pipelineRunGenerator != nil {
+		gitopsConfig := prepare.PrepareGitopsConfig(ctx, r.NonCachingClient, component)
+		if !r.pipelineRunGenerator.Schedule(component, gitopsConfig) {
+			log.Info(fmt.Sprintf("Generating build PipelineRun of component %v", req.NamespacedName))
+			// The component is requeued when the generation finishes
+			return ctrl.Result{RequeueAfter: pipelineRunGenerationRequeueInterval}, nil
+		}
+	}
+
 	if len(component.Annotations) == 0 {
 		component.Annotations = make(map[string]string)
 	}
-	if component.Annotations[InitialBuildAnnotationName] == "true" {
-		// Initial build have already happend, nothing to do.
-		return ctrl.Result{}, nil
+
+	var changedFields []string
+	if _, built := component.Annotations[InitialBuildAnnotationName]; built {
+		// A build has been submitted before, tell which changes caused the rebuild
+		changedFields = getChangedBuildSpecFields(component)
+	}
+
+	// The pending trigger is consumed by this build
+	triggerReason := getBuildTriggerReason(component)
+	delete(component.Annotations, BuildTriggerAnnotationName)
+	if triggerReason != BuildTriggerReasonDisruptionRetry {
+		// Retries of disrupted builds are counted per build spec
+		delete(component.Annotations, DisruptionRetriesAnnotationName)
 	}
 
 	// Set initial build annotation to prevent next builds
 	component.Annotations[InitialBuildAnnotationName] = "true"
+	setBuildSpecHash(&component)
+	if component.Annotations[ImageTagFormatAnnotationName] != "" {
+		// The build number is persisted together with the build spec hash, so it is never reused
+		if err := r.setNextBuildNumber(ctx, &component); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to set build number of component %v", req.NamespacedName))
+			return ctrl.Result{}, err
+		}
+	}
 	if err := r.Client.Update(ctx, &component); err != nil {
 		return ctrl.Result{}, err
 	}
 
-	if err := r.SubmitNewBuild(ctx, component); err != nil {
-		// Try to revert the annotation
+	submitted, err := r.SubmitNewBuild(ctx, component, triggerReason)
+	if err != nil && !submitted {
+		// Try to revert the annotations
 		if err := r.Client.Get(ctx, req.NamespacedName, &component); err == nil {
 			component.Annotations[InitialBuildAnnotationName] = "false"
+			component.Annotations[BuildTriggerAnnotationName] = triggerReason
 			if err := r.Client.Update(ctx, &component); err != nil {
 				log.Error(err, fmt.Sprintf("Failed to schedule initial build for component: %v", req.NamespacedName))
 			}
@@ -138,16 +761,67 @@ func (r *ComponentBuildReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 
 		return ctrl.Result{}, err
 	}
+	if !submitted {
+		// The build is skipped or blocked, the reason is in the component conditions which must not be cleared
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		// The build PipelineRun has been created, so the annotations are kept to not submit it again
+		log.Error(err, fmt.Sprintf("Failed to finish submission of build for component: %v", req.NamespacedName))
+	}
+	r.recordRebuildEvent(&component, changedFields)
+
+	if err := clearBuildBlockedCondition(ctx, r.Client, component); err != nil {
+		log.Error(err, fmt.Sprintf("Unable to clear %s condition for component %v", BuildBlockedConditionType, req.NamespacedName))
+	}
+	if err := clearMaintenanceModeCondition(ctx, r.Client, component); err != nil {
+		log.Error(err, fmt.Sprintf("Unable to clear %s condition for component %v", MaintenanceModeConditionType, req.NamespacedName))
+	}
+	if err := clearWaitingForComponentsCondition(ctx, r.Client, component); err != nil {
+		log.Error(err, fmt.Sprintf("Unable to clear %s condition for component %v", WaitingForComponentsConditionType, req.NamespacedName))
+	}
+	if err := clearPendingApprovalCondition(ctx, r.Client, component); err != nil {
+		log.Error(err, fmt.Sprintf("Unable to clear %s condition for component %v", PendingApprovalConditionType, req.NamespacedName))
+	}
+	if err := clearMissingCredentialsCondition(ctx, r.Client, component); err != nil {
+		log.Error(err, fmt.Sprintf("Unable to clear %s condition for component %v", MissingCredentialsConditionType, req.NamespacedName))
+	}
+	if err := clearWaitingForGitOpsSyncCondition(ctx, r.Client, component); err != nil {
+		log.Error(err, fmt.Sprintf("Unable to clear %s condition for component %v", WaitingForGitOpsSyncConditionType, req.NamespacedName))
+	}
+	if err := clearFreezeActiveCondition(ctx, r.Client, component); err != nil {
+		log.Error(err, fmt.Sprintf("Unable to clear %s condition for component %v", FreezeActiveConditionType, req.NamespacedName))
+	}
+	if err := clearBuildsDisabledCondition(ctx, r.Client, component); err != nil {
+		log.Error(err, fmt.Sprintf("Unable to clear %s condition for component %v", BuildsDisabledConditionType, req.NamespacedName))
+	}
 
 	return ctrl.Result{}, nil
 }
 
 // SubmitNewBuild creates a new PipelineRun to build a new image for the given component.
-func (r *ComponentBuildReconciler) SubmitNewBuild(ctx context.Context, component appstudiov1alpha1.Component) error {
-	log := r.Log.WithValues("Namespace", component.Namespace, "Application", component.Spec.Application, "Component", component.Name)
+// The trigger reason is recorded in the PipelineRun annotations.
+// Returns false if no PipelineRun has been created because the build is skipped or blocked,
+// the reason is set in the component conditions then.
+func (r *ComponentBuildReconciler) SubmitNewBuild(ctx context.Context, component appstudiov1alpha1.Component, triggerReason string) (bool, error) {
+	log := withBuildFields(r.Log, component, buildPhaseSubmit)
+
+	if !r.KeepStalePipelineRuns {
+		if err := r.cleanupStalePipelineRuns(ctx, component); err != nil {
+			return false, err
+		}
+	}
+
+	// All resources used by the build have to be in the namespace of the build PipelineRun
+	buildNamespace, err := r.resolveBuildNamespace(ctx, component)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Unable to resolve build namespace for component %s", component.Name))
+		return false, err
+	}
 
 	// TODO delete this block which is workaround for delayed sync of pvc
 	workspaceStorage := gitops.GenerateCommonStorage(component, "appstudio")
+	workspaceStorage.Namespace = buildNamespace
 	existingPvc := &corev1.PersistentVolumeClaim{}
 	if err := r.Client.Get(ctx, types.NamespacedName{Name: workspaceStorage.Name, Namespace: workspaceStorage.Namespace}, existingPvc); err != nil {
 		if errors.IsNotFound(err) {
@@ -157,75 +831,393 @@ func (r *ComponentBuildReconciler) SubmitNewBuild(ctx context.Context, component
 			err = r.Client.Create(ctx, workspaceStorage)
 			if err != nil {
 				log.Error(err, fmt.Sprintf("Unable to create common storage %v", workspaceStorage))
-				return err
+				return false, err
 			}
 			log.Info(fmt.Sprintf("PV is now present : %v", workspaceStorage.Name))
 		} else {
 			log.Error(err, fmt.Sprintf("Unable to get common storage %v", workspaceStorage))
-			return err
+			return false, err
 		}
 	}
 
+	if err := updateAnnotationConflictCondition(ctx, r.Client, component); err != nil {
+		log.Error(err, fmt.Sprintf("Unable to set %s condition for component %s", AnnotationConflictConditionType, component.Name))
+		return false, err
+	}
+
+	if condition := getImageSourceIgnoredCondition(component); condition != nil {
+		if err := setComponentCondition(ctx, r.Client, component, *condition); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to set %s condition for component %s", condition.Type, component.Name))
+			return false, err
+		}
+		log.Info(condition.Message)
+	}
+
 	gitSecretName := component.Spec.Secret
 	// Make the Secret ready for consumption by Tekton.
 	if gitSecretName != "" {
 		gitSecret := corev1.Secret{}
-		err := r.NonCachingClient.Get(ctx, types.NamespacedName{Name: gitSecretName, Namespace: component.Namespace}, &gitSecret)
-		if err != nil {
+		gitSecretKey := types.NamespacedName{Name: gitSecretName, Namespace: buildNamespace}
+		if err := r.secretCache.Get(ctx, r.NonCachingClient, gitSecretKey, &gitSecret); err != nil {
 			log.Error(err, fmt.Sprintf("Secret %s is missing", gitSecretName))
-			return err
-		} else {
-			if gitSecret.Annotations == nil {
-				gitSecret.Annotations = map[string]string{}
-			}
-
-			gitHost, _ := getGitProvider(component.Spec.Source.GitSource.URL)
-
-			// Doesn't matter if it was present, we will always override.
-			gitSecret.Annotations["tekton.dev/git-0"] = gitHost
-			err = r.Client.Update(ctx, &gitSecret)
-			if err != nil {
-				log.Error(err, fmt.Sprintf("Secret %s update failed", gitSecretName))
-				return err
-			}
+			return false, err
+		}
+		if err := r.annotateGitSecret(ctx, component, &gitSecret); err != nil {
+			log.Error(err, fmt.Sprintf("Secret %s update failed", gitSecretName))
+			return false, err
 		}
 	}
 
 	pipelinesServiceAccount := corev1.ServiceAccount{}
-	err := r.Client.Get(ctx, types.NamespacedName{Name: "pipeline", Namespace: component.Namespace}, &pipelinesServiceAccount)
+	err = r.Client.Get(ctx, types.NamespacedName{Name: "pipeline", Namespace: buildNamespace}, &pipelinesServiceAccount)
 	if err != nil {
-		log.Error(err, fmt.Sprintf("OpenShift Pipelines-created Service account 'pipeline' is missing in namespace %s", component.Namespace))
-		return err
+		log.Error(err, fmt.Sprintf("OpenShift Pipelines-created Service account 'pipeline' is missing in namespace %s", buildNamespace))
+		return false, err
 	} else {
-		updateRequired := updateServiceAccountIfSecretNotLinked(gitSecretName, &pipelinesServiceAccount)
+		updateRequired := updateServiceAccountIfSecretNotLinked(gitSecretName, &pipelinesServiceAccount, r.SecretLinkingStrategy)
+		if dedupServiceAccountSecrets(&pipelinesServiceAccount) {
+			updateRequired = true
+		}
+		if r.PruneServiceAccountSecrets {
+			pruned, err := r.pruneServiceAccountSecrets(ctx, &pipelinesServiceAccount)
+			if err != nil {
+				log.Error(err, fmt.Sprintf("Unable to prune Secrets of pipeline service account in namespace %s", buildNamespace))
+				return false, err
+			}
+			updateRequired = updateRequired || pruned
+		}
 		if updateRequired {
 			err = r.Client.Update(ctx, &pipelinesServiceAccount)
 			if err != nil {
 				log.Error(err, fmt.Sprintf("Unable to update pipeline service account %v", pipelinesServiceAccount))
-				return err
+				return false, err
 			}
 			log.Info(fmt.Sprintf("Service Account updated %v", pipelinesServiceAccount))
 		}
 	}
 
+	if r.ServiceAccountTokenClient != nil && isServiceAccountTokenRefreshRequested(component) {
+		refreshedSecrets, err := r.refreshServiceAccountTokens(ctx, pipelinesServiceAccount)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("Unable to refresh tokens of pipeline service account in namespace %s", buildNamespace))
+			return false, err
+		}
+		if len(refreshedSecrets) > 0 {
+			log.Info(fmt.Sprintf("Pipeline service account tokens refreshed in Secrets %s", strings.Join(refreshedSecrets, ", ")))
+		}
+	}
+
 	gitopsConfig := prepare.PrepareGitopsConfig(ctx, r.NonCachingClient, component)
-	initialBuild := gitops.GenerateInitialBuildPipelineRun(component, gitopsConfig)
-	err = controllerutil.SetOwnerReference(&component, &initialBuild, r.Scheme)
+	if component.Annotations[CreateEventListenerAnnotationName] == "true" {
+		if err := r.ensureBuildTrigger(ctx, component, gitopsConfig); err != nil {
+			return false, err
+		}
+	}
+
+	initialBuild := r.pipelineRunGenerator.Generate(component, gitopsConfig)
+	metav1.SetMetaDataAnnotation(&initialBuild.ObjectMeta, BuildTriggerAnnotationName, triggerReason)
+	applyBuildNamespace(component, buildNamespace, &initialBuild)
+	r.applyImageName(component, &initialBuild)
+
+	buildPipelines, err := r.getBuildPipelines(ctx)
+	if err != nil {
+		log.Error(err, "Unable to get build pipelines of devfile languages")
+		return false, err
+	}
+	applyLanguageBuildPipeline(component, buildPipelines, &initialBuild)
+
+	tier, tierProfile, err := r.getBuildTierProfile(ctx, component)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Unable to get build tier profile for component %s", component.Name))
+		return false, err
+	}
+	applyBuildTierProfile(tier, tierProfile, &initialBuild)
+
+	clusterBuildLabels, err := r.getClusterBuildLabels(ctx)
+	if err != nil {
+		log.Error(err, "Unable to get cluster build labels")
+		return false, err
+	}
+	applyClusterBuildLabels(clusterBuildLabels, &initialBuild)
+
+	buildServiceConfig, err := r.getBuildServiceConfig(ctx, component.Namespace)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Unable to get build service configuration of namespace %s", component.Namespace))
+		return false, err
+	}
+	applyBuildServiceConfig(buildServiceConfig, &initialBuild)
+
+	environmentParams, err := r.getEnvironmentBuildParams(ctx, component)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Unable to get environment build parameters of namespace %s", component.Namespace))
+		return false, err
+	}
+	mergePipelineParams(&initialBuild, environmentParams)
+
+	environment, environmentProfile, err := r.getEnvironmentBuildProfile(ctx, component)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Unable to get environment build profile for component %s", component.Name))
+		return false, err
+	}
+	applyEnvironmentBuildProfile(environment, environmentProfile, &initialBuild)
+
+	if err := r.applyPipelineVersion(ctx, component, &initialBuild); err != nil {
+		log.Error(err, fmt.Sprintf("Unable to select build pipeline version for component %s", component.Name))
+		return false, err
+	}
+
+	if isGHCRBuild(component) {
+		if err := r.applyGHCRImage(ctx, component, &initialBuild, &pipelinesServiceAccount); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to set GitHub Packages output image for component %s", component.Name))
+			return false, err
+		}
+	}
+
+	reproducibleBuildParams, err := getReproducibleBuildParams(component)
+	if err != nil {
+		return false, r.rejectInvalidConfiguration(ctx, component, err)
+	}
+	mergePipelineParams(&initialBuild, reproducibleBuildParams)
+
+	matrixParams, err := getBuildMatrixParams(component)
+	if err != nil {
+		return false, r.rejectInvalidConfiguration(ctx, component, err)
+	}
+	mergePipelineParams(&initialBuild, matrixParams)
+
+	cloneOptions, err := getGitCloneOptions(component)
 	if err != nil {
-		log.Error(err, fmt.Sprintf("Unable to set owner reference for %v", initialBuild))
+		return false, r.rejectInvalidConfiguration(ctx, component, err)
+	}
+	var cloneOptionsCorrections []string
+	if cloneOptions != nil {
+		cloneOptionsCorrections = normalizeGitCloneOptions(cloneOptions)
+		mergePipelineParams(&initialBuild, getGitCloneParams(*cloneOptions))
 	}
-	err = r.Client.Create(ctx, &initialBuild)
+	if len(cloneOptionsCorrections) > 0 {
+		condition := getGitCloneOptionsAdjustedCondition(cloneOptionsCorrections)
+		log.Info(condition.Message)
+		if err := setComponentCondition(ctx, r.Client, component, condition); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to set %s condition for component %s", condition.Type, component.Name))
+			return false, err
+		}
+	} else if err := clearGitCloneOptionsAdjustedCondition(ctx, r.Client, component); err != nil {
+		log.Error(err, fmt.Sprintf("Unable to clear %s condition for component %s", GitCloneOptionsAdjustedConditionType, component.Name))
+		return false, err
+	}
+
+	repoSizeScaling, err := r.getRepoSizeScaling(ctx)
+	if err != nil {
+		log.Error(err, "Unable to get repository size scaling")
+		return false, err
+	}
+	if repoSizeScaling != nil {
+		repoSize, err := r.getRepoSize(ctx, component)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("Unable to get git repository size for component %s", component.Name))
+			return false, err
+		}
+		// Builds of repositories with unknown size are not scaled
+		if repoSize != nil {
+			factor := getRepoSizeScalingFactor(*repoSizeScaling, *repoSize)
+			log.Info(fmt.Sprintf("Git repository of component %s has %s, build is scaled by %d", component.Name, repoSize.String(), factor))
+			applyRepoSizeScaling(repoSizeScaling, factor, &initialBuild)
+		}
+	}
+
+	logRetention, err := getBuildLogRetention(component, r.BuildLogRetention)
+	if err != nil {
+		return false, r.rejectInvalidConfiguration(ctx, component, err)
+	}
+	applyBuildLogRetention(logRetention, &initialBuild)
+
+	workspaceStorageClass, err := getWorkspaceStorageClass(component)
+	if err != nil {
+		return false, r.rejectInvalidConfiguration(ctx, component, err)
+	}
+	workspaceSize, err := getWorkspaceStorageSize(component)
+	if err != nil {
+		return false, r.rejectInvalidConfiguration(ctx, component, err)
+	}
+	applyWorkspaceStorageClass(workspaceStorageClass, workspaceSize, &initialBuild)
+
+	timeouts, err := getBuildTimeouts(component)
+	if err != nil {
+		return false, r.rejectInvalidConfiguration(ctx, component, err)
+	}
+	if timeouts != nil {
+		// Tekton doesn't allow to set both the deprecated timeout and the timeouts
+		initialBuild.Spec.Timeout = nil
+		initialBuild.Spec.Timeouts = timeouts
+	}
+
+	// Parameters from the annotation take precedence
+	additionalParams, err := getPipelineParamsFromAnnotation(component)
+	if err != nil {
+		return false, r.rejectInvalidConfiguration(ctx, component, err)
+	}
+	mergePipelineParams(&initialBuild, additionalParams)
+
+	if knownHostsSecretName := component.Annotations[SSHKnownHostsSecretAnnotationName]; knownHostsSecretName != "" {
+		if err := r.addSSHKnownHostsWorkspace(ctx, &initialBuild, knownHostsSecretName); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to add SSH known hosts from Secret %s for component %s", knownHostsSecretName, component.Name))
+			return false, err
+		}
+	}
+
+	if component.Annotations[ImageTagFormatAnnotationName] != "" {
+		if err := r.applyImageTagFormat(ctx, component, &initialBuild); err != nil {
+			return false, r.rejectInvalidConfiguration(ctx, component, err)
+		}
+	}
+
+	if r.SkipExistingImageBuild {
+		revisionImage := getRevisionImage(getPipelineRunParam(initialBuild, "output-image"), getPipelineRunParam(initialBuild, "revision"))
+		exists, err := r.isExistingImage(ctx, revisionImage)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("Unable to check whether image %s exists for component %s", revisionImage, component.Name))
+			return false, err
+		}
+		if exists {
+			condition := getSkippedExistingImageCondition(revisionImage)
+			log.Info(condition.Message)
+			return false, setComponentCondition(ctx, r.Client, component, condition)
+		}
+	}
+
+	// Builds of exact commits are repeatable, do not rebuild a commit, e.g. when the build spec hash is lost
+	builtCommitPipelineRun, err := r.getSucceededCommitBuild(ctx, component, initialBuild)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Unable to check previous builds of component %s", component.Name))
+		return false, err
+	}
+	if builtCommitPipelineRun != "" {
+		condition := getSkippedBuiltCommitCondition(getPipelineRunParam(initialBuild, "revision"), builtCommitPipelineRun)
+		log.Info(condition.Message)
+		return false, setComponentCondition(ctx, r.Client, component, condition)
+	}
+
+	tagCollisionCondition, err := r.resolveImageTagCollision(ctx, component, &initialBuild)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Unable to check output image tag collision for component %s", component.Name))
+		return false, err
+	}
+	if tagCollisionCondition != nil {
+		log.Info(tagCollisionCondition.Message)
+		if err := setComponentCondition(ctx, r.Client, component, *tagCollisionCondition); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to set %s condition for component %s", tagCollisionCondition.Type, component.Name))
+			return false, err
+		}
+		if tagCollisionCondition.Status == metav1.ConditionTrue {
+			return false, nil
+		}
+	}
+
+	if r.ImageRepositoryClient != nil && !isGHCRBuild(component) {
+		if outputImage := getPipelineRunParam(initialBuild, "output-image"); outputImage != "" {
+			condition, err := r.ensureImageRepository(ctx, outputImage)
+			if err := setComponentCondition(ctx, r.Client, component, condition); err != nil {
+				log.Error(err, fmt.Sprintf("Unable to set %s condition for component %s", condition.Type, component.Name))
+			}
+			if err != nil {
+				log.Error(err, fmt.Sprintf("Unable to ensure image repository for component %s", component.Name))
+				return false, err
+			}
+			if condition.Status == metav1.ConditionFalse {
+				log.Info(condition.Message)
+			}
+		}
+	}
+
+	if r.BundleVerifier != nil {
+		if err := r.verifyPipelineBundle(ctx, component, &initialBuild); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to verify pipeline bundle for component %s", component.Name))
+			return false, err
+		}
+	}
+
+	if component.Annotations[CapturePipelineRunAnnotationName] == "true" {
+		if err := r.capturePipelineRun(ctx, component, initialBuild); err != nil {
+			// The capture is informative only, do not block the build
+			log.Error(err, fmt.Sprintf("Unable to capture the build PipelineRun for component %s", component.Name))
+		}
+	}
+
+	if r.ResubmitDeletedBuilds {
+		controllerutil.AddFinalizer(&initialBuild, BuildResubmissionFinalizer)
+	}
+
+	// Owner references cannot point across namespaces
+	if initialBuild.Namespace == component.Namespace {
+		err = controllerutil.SetOwnerReference(&component, &initialBuild, r.Scheme)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("Unable to set owner reference for %v", initialBuild))
+		}
+	}
+
+	tooLargeCondition, err := getPipelineRunTooLargeCondition(initialBuild, r.MaxPipelineRunSize)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Unable to get size of the build PipelineRun for component %s", component.Name))
+		return false, err
+	}
+	if tooLargeCondition != nil {
+		log.Info(tooLargeCondition.Message)
+		return false, setComponentCondition(ctx, r.Client, component, *tooLargeCondition)
+	}
+
+	pipelineRunAPIVersion, err := r.getPipelineRunAPIVersion(component)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Unable to get build PipelineRun API version for component %s", component.Name))
+		return false, err
+	}
+	err = r.createBuildPipelineRun(ctx, &initialBuild, pipelineRunAPIVersion)
 	if err != nil {
 		log.Error(err, fmt.Sprintf("Unable to create the build PipelineRun %v", initialBuild))
-		return err
+		return false, err
+	}
+	log.Info(fmt.Sprintf("Initial build pipeline created for component %s in %s namespace", component.Name, initialBuild.Namespace))
+
+	if r.BuildAuditEnabled {
+		if err := r.recordBuildSubmission(ctx, component, initialBuild); err != nil {
+			// Do not fail as the build is submitted already and would be submitted again
+			log.Error(err, fmt.Sprintf("Unable to create audit record of the build PipelineRun %s", initialBuild.Name))
+			if r.Recorder != nil {
+				r.Recorder.Event(&component, corev1.EventTypeWarning, BuildAuditRecordFailedEventReason,
+					fmt.Sprintf("Build PipelineRun %s has been submitted without audit record: %v", initialBuild.Name, err))
+			}
+		}
+	}
+
+	if err := clearPipelineRunTooLargeCondition(ctx, r.Client, component); err != nil {
+		log.Error(err, fmt.Sprintf("Unable to clear %s condition for component %s", PipelineRunTooLargeConditionType, component.Name))
+		return true, err
+	}
+	if err := clearBuildNamespaceForbiddenCondition(ctx, r.Client, component); err != nil {
+		log.Error(err, fmt.Sprintf("Unable to clear %s condition for component %s", BuildNamespaceForbiddenConditionType, component.Name))
+		return true, err
+	}
+	if err := clearInvalidConfigurationCondition(ctx, r.Client, component); err != nil {
+		log.Error(err, fmt.Sprintf("Unable to clear %s condition for component %s", InvalidConfigurationConditionType, component.Name))
+		return true, err
 	}
-	log.Info(fmt.Sprintf("Initial build pipeline created for component %s in %s namespace", component.Name, component.Namespace))
 
-	return nil
+	return true, nil
 }
 
+// SSH git URL in the scp-like syntax user@host:path, e.g. git@github.com:foo/bar.git
+var scpLikeGitURLRegexp = regexp.MustCompile(`^[A-Za-z0-9._-]+@([A-Za-z0-9.-]+):(.+)$`)
+
 // getGitProvider takes a Git URL of the format https://github.com/foo/bar and returns https://github.com
+// The port of self-hosted instances is kept, e.g. https://gitlab.example.com:8443/group/subgroup/repo.git
+// gives https://gitlab.example.com:8443
+// SSH URLs give the HTTPS URL of the host, e.g. git@github.com:foo/bar.git gives https://github.com
 func getGitProvider(gitURL string) (string, error) {
+	if !strings.Contains(gitURL, "://") {
+		if match := scpLikeGitURLRegexp.FindStringSubmatch(gitURL); match != nil {
+			return "https://" + match[1], nil
+		}
+	}
+
 	u, err := url.Parse(gitURL)
 
 	// We really need the format of the string to be correct.
@@ -233,18 +1225,46 @@ func getGitProvider(gitURL string) (string, error) {
 	if err != nil || u.Scheme == "" {
 		return "", fmt.Errorf("failed to parse string into a URL: %v or scheme is empty", err)
 	}
+	if u.Host == "" {
+		return "", fmt.Errorf("git URL %s has no host", gitURL)
+	}
+	if u.Scheme == "ssh" {
+		// The port of SSH URLs is the SSH port, not the HTTPS one
+		return "https://" + u.Hostname(), nil
+	}
 	return u.Scheme + "://" + u.Host, nil
 }
 
-func updateServiceAccountIfSecretNotLinked(gitSecretName string, serviceAccount *corev1.ServiceAccount) bool {
-	for _, credentialSecret := range serviceAccount.Secrets {
-		if credentialSecret.Name == gitSecretName {
-			// The secret is present in the service account, no updates needed
-			return false
+func updateServiceAccountIfSecretNotLinked(gitSecretName string, serviceAccount *corev1.ServiceAccount, strategy SecretLinkingStrategy) bool {
+	updateRequired := false
+
+	if strategy.linksSecretField() && !isSecretLinked(gitSecretName, serviceAccount.Secrets) {
+		serviceAccount.Secrets = append(serviceAccount.Secrets, corev1.ObjectReference{Name: gitSecretName})
+		updateRequired = true
+	}
+
+	if strategy.linksImagePullSecretField() && !isImagePullSecretLinked(gitSecretName, serviceAccount.ImagePullSecrets) {
+		serviceAccount.ImagePullSecrets = append(serviceAccount.ImagePullSecrets, corev1.LocalObjectReference{Name: gitSecretName})
+		updateRequired = true
+	}
+
+	return updateRequired
+}
+
+func isSecretLinked(secretName string, secrets []corev1.ObjectReference) bool {
+	for _, secret := range secrets {
+		if secret.Name == secretName {
+			return true
 		}
 	}
+	return false
+}
 
-	// Add the secret to secret account and return that update is needed
-	serviceAccount.Secrets = append(serviceAccount.Secrets, corev1.ObjectReference{Name: gitSecretName})
-	return true
+func isImagePullSecretLinked(secretName string, imagePullSecrets []corev1.LocalObjectReference) bool {
+	for _, imagePullSecret := range imagePullSecrets {
+		if imagePullSecret.Name == secretName {
+			return true
+		}
+	}
+	return false
 }
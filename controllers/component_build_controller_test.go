@@ -17,19 +17,36 @@ limitations under the License.
 package controllers
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 
 	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	triggersapi "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 
 	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
 	"github.com/redhat-appstudio/application-service/gitops/prepare"
+	buildv1alpha1 "github.com/redhat-appstudio/build-service/api/v1alpha1"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -44,6 +61,11 @@ const (
 	HASAppNamespace = "default"
 	SampleRepoLink  = "https://github.com/devfile-samples/devfile-sample-java-springboot-basic"
 	GitSecretName   = "git-secret"
+
+	BitbucketSampleRepoLink = "https://bitbucket.org/devfile-samples/devfile-sample-java-springboot-basic"
+	BitbucketGitSecretName  = "bitbucket-git-secret"
+
+	buildStatusServerToken = "build-status-token"
 )
 
 func isOwnedBy(resource []metav1.OwnerReference, component appstudiov1alpha1.Component) bool {
@@ -111,6 +133,13 @@ func getComponent(componentLookupKey types.NamespacedName) *appstudiov1alpha1.Co
 	return component
 }
 
+// getComponentConditions returns a function which reads conditions of the component, to be polled by Eventually
+func getComponentConditions(componentLookupKey types.NamespacedName) func() []metav1.Condition {
+	return func() []metav1.Condition {
+		return getComponent(componentLookupKey).Status.Conditions
+	}
+}
+
 func setComponentDevfileModel(componentLookupKey types.NamespacedName) {
 	component := &appstudiov1alpha1.Component{}
 	Eventually(func() error {
@@ -139,6 +168,22 @@ func deleteComponent(componentLookupKey types.NamespacedName) {
 	}, timeout, interval).ShouldNot(Succeed())
 }
 
+// getBuildStatusServerClient returns a client which authenticates buildStatusServerToken
+// for a user allowed to access components in the test namespace
+func getBuildStatusServerClient(cli client.Client) client.Client {
+	return &reviewClient{
+		Client:            cli,
+		users:             map[string]string{buildStatusServerToken: "test-user"},
+		allowedNamespaces: map[string][]string{"test-user": {HASAppNamespace}},
+	}
+}
+
+func newBuildStatusServerRequest(method string, target string, body io.Reader) *http.Request {
+	request := httptest.NewRequest(method, target, body)
+	request.Header.Set("Authorization", "Bearer "+buildStatusServerToken)
+	return request
+}
+
 func listComponentPipelienRuns(componentLookupKey types.NamespacedName) *tektonapi.PipelineRunList {
 	pipelineRuns := &tektonapi.PipelineRunList{}
 	labelSelectors := client.ListOptions{Raw: &metav1.ListOptions{
@@ -208,6 +253,17 @@ var _ = Describe("Component initial build controller", func() {
 			ensureNoPipelineRunsCreated(resourceKey)
 		})
 
+		It("should neither build nor requeue the component until its devfile model is set", func() {
+			Expect(getComponent(resourceKey).Status.Devfile).To(BeEmpty())
+
+			// The component is not requeued, the devfile model update triggers a new reconcile
+			result, err := componentBuildReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: resourceKey})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(ctrl.Result{}))
+
+			ensureNoPipelineRunsCreated(resourceKey)
+		})
+
 		It("should not submit initial build if initial build annotation exists on the component", func() {
 			component := getComponent(resourceKey)
 			component.Annotations = make(map[string]string)
@@ -353,4 +409,2621 @@ var _ = Describe("Component initial build controller", func() {
 			deleteComponent(resourceKey)
 		})
 	})
+
+	Context("Check if build objects are created for Bitbucket source", func() {
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			// There is no garbage collector in the test environment
+			Expect(k8sClient.DeleteAllOf(ctx, &triggersapi.EventListener{}, client.InNamespace(HASAppNamespace))).Should(Succeed())
+			Expect(k8sClient.DeleteAllOf(ctx, &triggersapi.TriggerTemplate{}, client.InNamespace(HASAppNamespace))).Should(Succeed())
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should create build objects for Bitbucket repository", func() {
+			// Pre-create git secret with Bitbucket app password
+			gitSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      BitbucketGitSecretName,
+					Namespace: HASAppNamespace,
+				},
+				Type: corev1.SecretTypeBasicAuth,
+				StringData: map[string]string{
+					corev1.BasicAuthUsernameKey: "bitbucket-user",
+					corev1.BasicAuthPasswordKey: "bitbucket-app-password",
+				},
+			}
+			Expect(k8sClient.Create(ctx, gitSecret)).Should(Succeed())
+
+			component := &appstudiov1alpha1.Component{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Component",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      HASCompName,
+					Namespace: HASAppNamespace,
+					Annotations: map[string]string{
+						CreateEventListenerAnnotationName: "true",
+					},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Secret:        BitbucketGitSecretName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: BitbucketSampleRepoLink,
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+
+			setComponentDevfileModel(resourceKey)
+
+			ensureOnePipelineRunCreated(resourceKey)
+
+			// Check that git credentials secret is annotated with Bitbucket host
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: BitbucketGitSecretName, Namespace: HASAppNamespace}, gitSecret)).Should(Succeed())
+			Expect(gitSecret.ObjectMeta.Annotations["tekton.dev/git-0"]).To(Equal("https://bitbucket.org"))
+
+			// Check that the pipeline service account has been linked with the Bitbucket authentication credentials
+			var pipelineSA corev1.ServiceAccount
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "pipeline", Namespace: HASAppNamespace}, &pipelineSA)).Should(Succeed())
+			secretFound := false
+			for _, secret := range pipelineSA.Secrets {
+				if secret.Name == BitbucketGitSecretName {
+					secretFound = true
+					break
+				}
+			}
+			Expect(secretFound).To(BeTrue())
+
+			pipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+			Expect(getPipelineRunParam(pipelineRun, "git-url")).To(Equal(BitbucketSampleRepoLink))
+
+			triggerTemplate := &triggersapi.TriggerTemplate{}
+			Expect(k8sClient.Get(ctx, resourceKey, triggerTemplate)).Should(Succeed())
+			Expect(triggerTemplate.Spec.ResourceTemplates).To(HaveLen(1))
+			triggeredPipelineRun := tektonapi.PipelineRun{}
+			Expect(json.Unmarshal(triggerTemplate.Spec.ResourceTemplates[0].Raw, &triggeredPipelineRun)).Should(Succeed())
+			Expect(getPipelineRunParam(triggeredPipelineRun, "git-url")).To(Equal(BitbucketSampleRepoLink))
+
+			eventListener := &triggersapi.EventListener{}
+			Expect(k8sClient.Get(ctx, resourceKey, eventListener)).Should(Succeed())
+			Expect(eventListener.Spec.Triggers[0].Bindings[0].Ref).To(Equal(bitbucketPushBinding))
+		})
+	})
+
+	Context("Test build summary", func() {
+
+		_ = BeforeEach(func() {
+			createComponent(resourceKey)
+		}, 30)
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		getBuildSummaryFromConfigMap := func() *BuildSummary {
+			configMap := &corev1.ConfigMap{}
+			configMapKey := types.NamespacedName{Name: HASCompName + BuildSummaryConfigMapSuffix, Namespace: HASAppNamespace}
+			if err := k8sClient.Get(ctx, configMapKey, configMap); err != nil {
+				return nil
+			}
+			summary := &BuildSummary{}
+			if err := json.Unmarshal([]byte(configMap.Data[BuildSummaryConfigMapKey]), summary); err != nil {
+				return nil
+			}
+			return summary
+		}
+
+		It("should maintain build summary ConfigMap", func() {
+			setComponentDevfileModel(resourceKey)
+			ensureOnePipelineRunCreated(resourceKey)
+			pipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+
+			Eventually(func() bool {
+				summary := getBuildSummaryFromConfigMap()
+				return summary != nil && summary.PipelineRun == pipelineRun.Name && summary.State == BuildStatePending
+			}, timeout, interval).Should(BeTrue())
+
+			completionTime := metav1.Now()
+			pipelineRun.Status.StartTime = &completionTime
+			pipelineRun.Status.CompletionTime = &completionTime
+			pipelineRun.Status.SetCondition(&apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionTrue,
+			})
+			Expect(k8sClient.Status().Update(ctx, &pipelineRun)).Should(Succeed())
+
+			Eventually(func() bool {
+				summary := getBuildSummaryFromConfigMap()
+				return summary != nil && summary.State == BuildStateSucceeded && summary.CompletionTime != nil
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("Test namespace label requirement", func() {
+
+		It("should select only components in namespaces with required label", func() {
+			onboardedNamespace := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "onboarded-namespace",
+					Labels: map[string]string{"build.appstudio.openshift.io/enabled": "true"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, onboardedNamespace)).Should(Succeed())
+			notOnboardedNamespace := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "not-onboarded-namespace",
+				},
+			}
+			Expect(k8sClient.Create(ctx, notOnboardedNamespace)).Should(Succeed())
+
+			namespaceSelector, err := labels.Parse("build.appstudio.openshift.io/enabled=true")
+			Expect(err).ToNot(HaveOccurred())
+			reconciler := &ComponentBuildReconciler{
+				Client:            k8sClient,
+				Log:               ctrl.Log.WithName("controllers").WithName("ComponentInitialBuild"),
+				NamespaceSelector: namespaceSelector,
+			}
+
+			onboardedComponent := &appstudiov1alpha1.Component{ObjectMeta: metav1.ObjectMeta{Name: HASCompName, Namespace: onboardedNamespace.Name}}
+			Expect(reconciler.isInSelectedNamespace(onboardedComponent)).To(BeTrue())
+			notOnboardedComponent := &appstudiov1alpha1.Component{ObjectMeta: metav1.ObjectMeta{Name: HASCompName, Namespace: notOnboardedNamespace.Name}}
+			Expect(reconciler.isInSelectedNamespace(notOnboardedComponent)).To(BeFalse())
+
+			Expect(reconciler.getSelectedNamespaceComponents(notOnboardedNamespace)).To(BeEmpty())
+		})
+	})
+
+	Context("Test build status API", func() {
+
+		_ = BeforeEach(func() {
+			createComponent(resourceKey)
+		}, 30)
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should return build status of the component", func() {
+			setComponentDevfileModel(resourceKey)
+			ensureOnePipelineRunCreated(resourceKey)
+			pipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+
+			// The server lists PipelineRuns using the index of the manager cache
+			server := &BuildStatusServer{Client: getBuildStatusServerClient(componentBuildReconciler.Client), Log: ctrl.Log.WithName("BuildStatusServer")}
+			buildStatus := &ComponentBuildStatus{}
+			Eventually(func() bool {
+				request := newBuildStatusServerRequest(http.MethodGet, "/api/v1/namespaces/"+HASAppNamespace+"/components/"+HASCompName+"/build-status", nil)
+				response := httptest.NewRecorder()
+				server.ServeHTTP(response, request)
+
+				Expect(response.Code).To(Equal(http.StatusOK))
+				Expect(json.Unmarshal(response.Body.Bytes(), buildStatus)).Should(Succeed())
+				return buildStatus.LatestBuild != nil
+			}, timeout, interval).Should(BeTrue())
+			Expect(buildStatus.Component).To(Equal(HASCompName))
+			Expect(buildStatus.LatestBuild.PipelineRun).To(Equal(pipelineRun.Name))
+		})
+
+		It("should return not found for unknown component", func() {
+			server := &BuildStatusServer{Client: getBuildStatusServerClient(componentBuildReconciler.Client), Log: ctrl.Log.WithName("BuildStatusServer")}
+			request := newBuildStatusServerRequest(http.MethodGet, "/api/v1/namespaces/"+HASAppNamespace+"/components/unknown-component/build-status", nil)
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			Expect(response.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("Test build simulation API", func() {
+
+		_ = BeforeEach(func() {
+			createComponent(resourceKey)
+		}, 30)
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		simulateBuild := func(spec interface{}) *httptest.ResponseRecorder {
+			specJSON, err := json.Marshal(spec)
+			Expect(err).ToNot(HaveOccurred())
+			server := &BuildStatusServer{Client: getBuildStatusServerClient(k8sClient), Log: ctrl.Log.WithName("BuildStatusServer")}
+			request := newBuildStatusServerRequest(http.MethodPost,
+				"/apis/build.appstudio.openshift.io/v1alpha1/namespaces/"+HASAppNamespace+"/components/"+HASCompName+"/simulate-build",
+				bytes.NewReader(specJSON))
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+			return response
+		}
+
+		It("should report changes of the proposed spec without applying them", func() {
+			setComponentDevfileModel(resourceKey)
+			ensureOnePipelineRunCreated(resourceKey)
+
+			component := getComponent(resourceKey)
+			proposedSpec := component.Spec.DeepCopy()
+			proposedSpec.Source.GitSource.URL = BitbucketSampleRepoLink
+
+			response := simulateBuild(proposedSpec)
+			Expect(response.Code).To(Equal(http.StatusOK))
+			simulation := &BuildSimulation{}
+			Expect(json.Unmarshal(response.Body.Bytes(), simulation)).Should(Succeed())
+			Expect(simulation.BuildRequired).To(BeFalse())
+			Expect(simulation.Reason).To(Equal(BuildDecisionReasonAlreadySubmitted))
+
+			gitURLChanged := false
+			for _, change := range simulation.TriggerTemplateChanges {
+				if change.Current == SampleRepoLink && change.Proposed == BitbucketSampleRepoLink {
+					gitURLChanged = true
+				}
+			}
+			Expect(gitURLChanged).To(BeTrue())
+
+			// Nothing is changed
+			Expect(getComponent(resourceKey).Spec.Source.GitSource.URL).To(Equal(SampleRepoLink))
+			Expect(listComponentPipelienRuns(resourceKey).Items).To(HaveLen(1))
+		})
+
+		It("should report no changes for the same spec", func() {
+			setComponentDevfileModel(resourceKey)
+
+			response := simulateBuild(getComponent(resourceKey).Spec)
+			Expect(response.Code).To(Equal(http.StatusOK))
+			simulation := &BuildSimulation{}
+			Expect(json.Unmarshal(response.Body.Bytes(), simulation)).Should(Succeed())
+			Expect(simulation.TriggerTemplateChanges).To(BeEmpty())
+		})
+
+		It("should reject invalid spec", func() {
+			response := simulateBuild(map[string]string{"unknownField": "value"})
+			Expect(response.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("Test first successful build notification", func() {
+
+		_ = BeforeEach(func() {
+			testNotifier.reset()
+			createComponent(resourceKey)
+		}, 30)
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		succeedPipelineRun := func(pipelineRun *tektonapi.PipelineRun) {
+			pipelineRun.Status.SetCondition(&apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionTrue,
+				Reason: "Succeeded",
+			})
+			Expect(k8sClient.Status().Update(ctx, pipelineRun)).Should(Succeed())
+		}
+
+		It("should notify only about the first successful build", func() {
+			setComponentDevfileModel(resourceKey)
+			ensureOnePipelineRunCreated(resourceKey)
+			Expect(testNotifier.getComponentNotifications(HASCompName)).To(BeEmpty())
+
+			firstPipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+			succeedPipelineRun(&firstPipelineRun)
+
+			Eventually(func() int {
+				return len(testNotifier.getComponentNotifications(HASCompName))
+			}, timeout, interval).Should(Equal(1))
+			Expect(testNotifier.getComponentNotifications(HASCompName)[0].PipelineRun).To(Equal(firstPipelineRun.Name))
+			Eventually(func() bool {
+				return isFirstSuccessfulBuildNotified(*getComponent(resourceKey))
+			}, timeout, interval).Should(BeTrue())
+
+			// Request one more build
+			Eventually(func() error {
+				component := getComponent(resourceKey)
+				component.Annotations[InitialBuildAnnotationName] = "false"
+				return k8sClient.Update(ctx, component)
+			}, timeout, interval).Should(Succeed())
+			Eventually(func() bool {
+				return len(listComponentPipelienRuns(resourceKey).Items) == 2
+			}, timeout, interval).Should(BeTrue())
+
+			for _, pipelineRun := range listComponentPipelienRuns(resourceKey).Items {
+				if pipelineRun.Name != firstPipelineRun.Name {
+					succeedPipelineRun(&pipelineRun)
+				}
+			}
+			Consistently(func() int {
+				return len(testNotifier.getComponentNotifications(HASCompName))
+			}, 5*time.Second, interval).Should(Equal(1))
+		})
+	})
+
+	Context("Test cluster-wide build concurrency limit", func() {
+
+		const otherComponentName = "other-component"
+
+		var activeBuild *tektonapi.PipelineRun
+
+		_ = BeforeEach(func() {
+			restartComponentBuildReconciler(func(r *ComponentBuildReconciler) {
+				r.MaxConcurrentBuilds = 1
+			})
+
+			// Simulate a running build of another component
+			activeBuild = &tektonapi.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: otherComponentName + "-",
+					Namespace:    HASAppNamespace,
+					Labels:       map[string]string{ComponentNameLabelName: otherComponentName},
+				},
+				Spec: tektonapi.PipelineRunSpec{
+					PipelineRef: &tektonapi.PipelineRef{Name: "noop"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, activeBuild)).Should(Succeed())
+		}, 30)
+
+		_ = AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, activeBuild)).Should(Succeed())
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+			restartComponentBuildReconciler(nil)
+		}, 30)
+
+		It("should queue the build until another build finishes", func() {
+			createComponent(resourceKey)
+			setComponentDevfileModel(resourceKey)
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: BuildBlockedConditionType, Status: metav1.ConditionTrue}))
+			ensureNoPipelineRunsCreated(resourceKey)
+
+			// Release the build slot
+			activeBuild.Status.SetCondition(&apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionTrue,
+				Reason: "Succeeded",
+			})
+			Expect(k8sClient.Status().Update(ctx, activeBuild)).Should(Succeed())
+
+			Eventually(func() bool {
+				return len(listComponentPipelienRuns(resourceKey).Items) == 1
+			}, 2*timeout, interval).Should(BeTrue())
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: BuildBlockedConditionType, Status: metav1.ConditionFalse}))
+		})
+	})
+
+	Context("Test relabeling of legacy PipelineRuns", func() {
+
+		const legacyComponentLabelName = "appstudio.openshift.io/component"
+
+		_ = BeforeEach(func() {
+			restartComponentBuildReconciler(func(r *ComponentBuildReconciler) {
+				r.LegacyComponentLabelName = legacyComponentLabelName
+			})
+		}, 30)
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+			restartComponentBuildReconciler(nil)
+		}, 30)
+
+		It("should relabel legacy PipelineRun of the component", func() {
+			legacyPipelineRun := &tektonapi.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: HASCompName + "-",
+					Namespace:    HASAppNamespace,
+					Labels:       map[string]string{legacyComponentLabelName: HASCompName},
+				},
+				Spec: tektonapi.PipelineRunSpec{
+					PipelineRef: &tektonapi.PipelineRef{Name: "noop"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, legacyPipelineRun)).Should(Succeed())
+
+			createComponent(resourceKey)
+
+			Eventually(func() bool {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: legacyPipelineRun.Name, Namespace: HASAppNamespace}, legacyPipelineRun)).Should(Succeed())
+				return legacyPipelineRun.Labels[ComponentNameLabelName] == HASCompName
+			}, timeout, interval).Should(BeTrue())
+			Expect(legacyPipelineRun.Labels[legacyComponentLabelName]).To(Equal(HASCompName))
+
+			// The relabeled PipelineRun is counted as a build of the component
+			component := getComponent(resourceKey)
+			// The PipelineRuns are listed from the cache of the manager
+			var pipelineRuns []tektonapi.PipelineRun
+			Eventually(func() bool {
+				var err error
+				pipelineRuns, err = listComponentPipelineRuns(ctx, componentBuildReconciler.Client, *component)
+				Expect(err).ToNot(HaveOccurred())
+				return len(pipelineRuns) == 1 && pipelineRuns[0].Labels[ComponentNameLabelName] == HASCompName
+			}, timeout, interval).Should(BeTrue())
+			Expect(pipelineRuns[0].Name).To(Equal(legacyPipelineRun.Name))
+		})
+	})
+
+	Context("Test SSH known hosts injection", func() {
+
+		const knownHostsSecretName = "git-known-hosts"
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should bind known hosts Secret as a workspace of the build", func() {
+			knownHostsSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      knownHostsSecretName,
+					Namespace: HASAppNamespace,
+				},
+				StringData: map[string]string{
+					sshKnownHostsSecretKey: "git.example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl",
+				},
+			}
+			Expect(k8sClient.Create(ctx, knownHostsSecret)).Should(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, knownHostsSecret)).Should(Succeed())
+			}()
+
+			component := &appstudiov1alpha1.Component{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Component",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      HASCompName,
+					Namespace: HASAppNamespace,
+					Annotations: map[string]string{
+						SSHKnownHostsSecretAnnotationName: knownHostsSecretName,
+					},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+			setComponentDevfileModel(resourceKey)
+
+			ensureOnePipelineRunCreated(resourceKey)
+			pipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+			workspaceFound := false
+			for _, w := range pipelineRun.Spec.Workspaces {
+				if w.Name == sshDirectoryWorkspaceName {
+					workspaceFound = true
+					Expect(w.Secret.SecretName).To(Equal(knownHostsSecretName))
+				}
+			}
+			Expect(workspaceFound).To(BeTrue())
+		})
+	})
+
+	Context("Test component with both git and image sources", func() {
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should build from git source and inform that image source is ignored", func() {
+			component := &appstudiov1alpha1.Component{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Component",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      HASCompName,
+					Namespace: HASAppNamespace,
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+							ImageSource: &appstudiov1alpha1.ImageSource{
+								ContainerImage: "quay.io/foo/bar:latest",
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+			setComponentDevfileModel(resourceKey)
+
+			ensureOnePipelineRunCreated(resourceKey)
+			pipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+			Expect(getPipelineRunParam(pipelineRun, "git-url")).To(Equal(SampleRepoLink))
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: ImageSourceIgnoredConditionType, Status: metav1.ConditionTrue}))
+		})
+	})
+
+	Context("Test image vulnerability scan", func() {
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			Expect(k8sClient.DeleteAllOf(ctx, &tektonapi.PipelineRun{}, client.InNamespace(HASAppNamespace),
+				client.MatchingLabels{ImageScanComponentLabelName: HASCompName})).Should(Succeed())
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should scan image after successful build and record the result", func() {
+			component := &appstudiov1alpha1.Component{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Component",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      HASCompName,
+					Namespace: HASAppNamespace,
+					Annotations: map[string]string{
+						ScanOnBuildAnnotationName:               "true",
+						BlockVulnerableDeploymentAnnotationName: "true",
+					},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+					Build: appstudiov1alpha1.Build{
+						ContainerImage: "docker.io/foo/customized:default-test-component",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+			setComponentDevfileModel(resourceKey)
+			ensureOnePipelineRunCreated(resourceKey)
+
+			buildPipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+			buildPipelineRun.Status.SetCondition(&apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionTrue,
+				Reason: "Succeeded",
+			})
+			buildPipelineRun.Status.PipelineResults = []tektonapi.PipelineRunResult{
+				{Name: "IMAGE_DIGEST", Value: "sha256:abcd"},
+			}
+			Expect(k8sClient.Status().Update(ctx, &buildPipelineRun)).Should(Succeed())
+
+			scanPipelineRun := &tektonapi.PipelineRun{}
+			scanKey := types.NamespacedName{Name: buildPipelineRun.Name + imageScanPipelineRunSuffix, Namespace: HASAppNamespace}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, scanKey, scanPipelineRun)
+			}, timeout, interval).Should(Succeed())
+			Expect(getPipelineRunParam(*scanPipelineRun, "image")).To(Equal("docker.io/foo/customized@sha256:abcd"))
+
+			scanPipelineRun.Status.SetCondition(&apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionTrue,
+				Reason: "Succeeded",
+			})
+			scanPipelineRun.Status.PipelineResults = []tektonapi.PipelineRunResult{
+				{Name: imageScanCriticalResult, Value: "1"},
+				{Name: imageScanHighResult, Value: "3"},
+			}
+			Expect(k8sClient.Status().Update(ctx, scanPipelineRun)).Should(Succeed())
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(And(
+				HaveComponentCondition(metav1.Condition{Type: ImageScanConditionType, Status: metav1.ConditionTrue}),
+				HaveComponentCondition(metav1.Condition{Type: DeploymentBlockedConditionType, Status: metav1.ConditionTrue})))
+			Expect(getComponent(resourceKey).Status.Conditions).To(HaveComponentConditionMessage(ImageScanConditionType, ContainSubstring("1 critical and 3 high")))
+
+			// The scan must not be counted as a build
+			Expect(listComponentPipelienRuns(resourceKey).Items).To(HaveLen(1))
+		})
+	})
+
+	Context("Test retry of disrupted builds", func() {
+
+		_ = BeforeEach(func() {
+			createComponent(resourceKey)
+		}, 30)
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		failPipelineRunWithEvictedPod := func(pipelineRun *tektonapi.PipelineRun) {
+			pipelineRun.Status.SetCondition(&apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionFalse,
+				Reason: "Failed",
+			})
+			pipelineRun.Status.TaskRuns = map[string]*tektonapi.PipelineRunTaskRunStatus{
+				pipelineRun.Name + "-build-container": {
+					PipelineTaskName: "build-container",
+					Status: &tektonapi.TaskRunStatus{
+						Status: duckv1beta1.Status{
+							Conditions: duckv1beta1.Conditions{
+								{
+									Type:    apis.ConditionSucceeded,
+									Status:  corev1.ConditionFalse,
+									Reason:  "Failed",
+									Message: "The node was low on resource: memory.",
+								},
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, pipelineRun)).Should(Succeed())
+		}
+
+		It("should resubmit build once if the build pod was evicted", func() {
+			setComponentDevfileModel(resourceKey)
+			ensureOnePipelineRunCreated(resourceKey)
+
+			pipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+			failPipelineRunWithEvictedPod(&pipelineRun)
+
+			Eventually(func() bool {
+				return len(listComponentPipelienRuns(resourceKey).Items) == 2
+			}, timeout, interval).Should(BeTrue())
+			component := getComponent(resourceKey)
+			Expect(component.Annotations[DisruptionRetriesAnnotationName]).To(Equal("1"))
+
+			// The second disruption should not trigger another build
+			for _, pipelineRun := range listComponentPipelienRuns(resourceKey).Items {
+				if pipelineRun.Status.GetCondition(apis.ConditionSucceeded) == nil {
+					failPipelineRunWithEvictedPod(&pipelineRun)
+				}
+			}
+			Consistently(func() bool {
+				return len(listComponentPipelienRuns(resourceKey).Items) == 2
+			}, 5*time.Second, interval).Should(BeTrue())
+		})
+	})
+
+	Context("Test stale resources of a recreated component", func() {
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should delete PipelineRuns left from a previous component with the same name", func() {
+			stalePipelineRun := &tektonapi.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      HASCompName + "-stale",
+					Namespace: HASAppNamespace,
+					Labels: map[string]string{
+						ComponentNameLabelName: HASCompName,
+					},
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion: "appstudio.redhat.com/v1alpha1",
+							Kind:       "Component",
+							Name:       HASCompName,
+							UID:        "previous-component-uid",
+						},
+					},
+				},
+				Spec: tektonapi.PipelineRunSpec{
+					PipelineRef: &tektonapi.PipelineRef{Name: "noop"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, stalePipelineRun)).Should(Succeed())
+
+			createComponent(resourceKey)
+			setComponentDevfileModel(resourceKey)
+
+			ensureOnePipelineRunCreated(resourceKey)
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: stalePipelineRun.Name, Namespace: HASAppNamespace}, &tektonapi.PipelineRun{})
+				return errors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("Test image tag format", func() {
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should use image tag rendered from the image tag format annotation", func() {
+			component := &appstudiov1alpha1.Component{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Component",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      HASCompName,
+					Namespace: HASAppNamespace,
+					Annotations: map[string]string{
+						ImageTagFormatAnnotationName: "{{.Application}}-{{.Component}}-{{.BuildNumber}}",
+					},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+					Build: appstudiov1alpha1.Build{
+						ContainerImage: "docker.io/foo/customized:default-test-component",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+
+			setComponentDevfileModel(resourceKey)
+
+			ensureOnePipelineRunCreated(resourceKey)
+
+			pipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+			outputImageFound := false
+			for _, p := range pipelineRun.Spec.Params {
+				if p.Name == "output-image" {
+					outputImageFound = true
+					Expect(p.Value.StringVal).To(Equal("docker.io/foo/customized:test-application-test-component-1"))
+				}
+			}
+			Expect(outputImageFound).To(BeTrue())
+		})
+
+		It("should render git revision and persist the build number", func() {
+			component := &appstudiov1alpha1.Component{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Component",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      HASCompName,
+					Namespace: HASAppNamespace,
+					Annotations: map[string]string{
+						ImageTagFormatAnnotationName: "{{.GitRevision}}-{{.BuildNumber}}",
+						BuildNumberAnnotationName:    "41",
+						PipelineParamsAnnotationName: `{"revision": "1a2b3c4"}`,
+					},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+					Build: appstudiov1alpha1.Build{
+						ContainerImage: "docker.io/foo/customized:default-test-component",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+
+			setComponentDevfileModel(resourceKey)
+
+			ensureOnePipelineRunCreated(resourceKey)
+
+			pipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+			Expect(getPipelineRunParam(pipelineRun, "output-image")).To(Equal("docker.io/foo/customized:1a2b3c4-42"))
+			Expect(getComponent(resourceKey).Annotations[BuildNumberAnnotationName]).To(Equal("42"))
+		})
+	})
+
+	Context("Test conflicting build annotations", func() {
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should apply image tag format to output image from pipeline parameters and inform about the conflict", func() {
+			component := &appstudiov1alpha1.Component{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Component",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      HASCompName,
+					Namespace: HASAppNamespace,
+					Annotations: map[string]string{
+						ImageTagFormatAnnotationName: "{{.Component}}-{{.BuildNumber}}",
+						PipelineParamsAnnotationName: `{"output-image": "quay.io/org/from-params:latest"}`,
+					},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+					Build: appstudiov1alpha1.Build{
+						ContainerImage: "docker.io/foo/customized:default-test-component",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+			setComponentDevfileModel(resourceKey)
+
+			ensureOnePipelineRunCreated(resourceKey)
+			pipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+			Expect(getPipelineRunParam(pipelineRun, "output-image")).To(Equal("quay.io/org/from-params:test-component-1"))
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: AnnotationConflictConditionType, Status: metav1.ConditionTrue}))
+		})
+	})
+
+	Context("Test event listener creation", func() {
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			// There is no garbage collector in the test environment
+			Expect(k8sClient.DeleteAllOf(ctx, &triggersapi.EventListener{}, client.InNamespace(HASAppNamespace))).Should(Succeed())
+			Expect(k8sClient.DeleteAllOf(ctx, &triggersapi.TriggerTemplate{}, client.InNamespace(HASAppNamespace))).Should(Succeed())
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should create event listener and trigger template if requested in the annotation", func() {
+			component := &appstudiov1alpha1.Component{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Component",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      HASCompName,
+					Namespace: HASAppNamespace,
+					Annotations: map[string]string{
+						CreateEventListenerAnnotationName: "true",
+					},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+
+			setComponentDevfileModel(resourceKey)
+
+			ensureOnePipelineRunCreated(resourceKey)
+
+			triggerTemplate := &triggersapi.TriggerTemplate{}
+			Expect(k8sClient.Get(ctx, resourceKey, triggerTemplate)).Should(Succeed())
+			Expect(isOwnedBy(triggerTemplate.OwnerReferences, *getComponent(resourceKey))).To(BeTrue())
+
+			eventListener := &triggersapi.EventListener{}
+			Expect(k8sClient.Get(ctx, resourceKey, eventListener)).Should(Succeed())
+			Expect(isOwnedBy(eventListener.OwnerReferences, *getComponent(resourceKey))).To(BeTrue())
+			Expect(eventListener.Spec.Triggers).To(HaveLen(1))
+			Expect(eventListener.Spec.Triggers[0].Bindings[0].Ref).To(Equal(githubPushBinding))
+			Expect(*eventListener.Spec.Triggers[0].Template.Ref).To(Equal(triggerTemplate.Name))
+		})
+
+		It("should create trigger template from the pipeline template ConfigMap", func() {
+			pipelineTemplateConfigMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pipeline-template",
+					Namespace: HASAppNamespace,
+				},
+				Data: map[string]string{PipelineTemplateConfigMapKey: testTriggerTemplateYAML},
+			}
+			Expect(k8sClient.Create(ctx, pipelineTemplateConfigMap)).Should(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, pipelineTemplateConfigMap)).Should(Succeed())
+			}()
+
+			component := &appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      HASCompName,
+					Namespace: HASAppNamespace,
+					Annotations: map[string]string{
+						CreateEventListenerAnnotationName:       "true",
+						PipelineTemplateConfigMapAnnotationName: pipelineTemplateConfigMap.Name,
+					},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+			setComponentDevfileModel(resourceKey)
+			ensureOnePipelineRunCreated(resourceKey)
+
+			triggerTemplate := &triggersapi.TriggerTemplate{}
+			Expect(k8sClient.Get(ctx, resourceKey, triggerTemplate)).Should(Succeed())
+			Expect(isOwnedBy(triggerTemplate.OwnerReferences, *getComponent(resourceKey))).To(BeTrue())
+			Expect(triggerTemplate.Labels).To(HaveKeyWithValue("team", "foo"))
+			Expect(triggerTemplate.Spec.ResourceTemplates).To(HaveLen(1))
+			Expect(string(triggerTemplate.Spec.ResourceTemplates[0].Raw)).To(ContainSubstring("custom-build"))
+
+			eventListener := &triggersapi.EventListener{}
+			Expect(k8sClient.Get(ctx, resourceKey, eventListener)).Should(Succeed())
+			Expect(*eventListener.Spec.Triggers[0].Template.Ref).To(Equal(triggerTemplate.Name))
+		})
+
+		It("should update trigger template when the pipeline template ConfigMap changes", func() {
+			pipelineTemplateConfigMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pipeline-template",
+					Namespace: HASAppNamespace,
+				},
+				Data: map[string]string{PipelineTemplateConfigMapKey: testTriggerTemplateYAML},
+			}
+			Expect(k8sClient.Create(ctx, pipelineTemplateConfigMap)).Should(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, pipelineTemplateConfigMap)).Should(Succeed())
+			}()
+
+			component := &appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      HASCompName,
+					Namespace: HASAppNamespace,
+					Annotations: map[string]string{
+						CreateEventListenerAnnotationName:       "true",
+						PipelineTemplateConfigMapAnnotationName: pipelineTemplateConfigMap.Name,
+					},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+			setComponentDevfileModel(resourceKey)
+			ensureOnePipelineRunCreated(resourceKey)
+
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: pipelineTemplateConfigMap.Name, Namespace: HASAppNamespace}, pipelineTemplateConfigMap)).Should(Succeed())
+			pipelineTemplateConfigMap.Data[PipelineTemplateConfigMapKey] = strings.Replace(testTriggerTemplateYAML, "custom-build", "updated-build", 1)
+			Expect(k8sClient.Update(ctx, pipelineTemplateConfigMap)).Should(Succeed())
+
+			Eventually(func() bool {
+				triggerTemplate := &triggersapi.TriggerTemplate{}
+				if err := k8sClient.Get(ctx, resourceKey, triggerTemplate); err != nil || len(triggerTemplate.Spec.ResourceTemplates) != 1 {
+					return false
+				}
+				return strings.Contains(string(triggerTemplate.Spec.ResourceTemplates[0].Raw), "updated-build")
+			}, timeout, interval).Should(BeTrue())
+			// The changed TriggerTemplate doesn't rebuild the component
+			ensureOnePipelineRunCreated(resourceKey)
+		})
+
+		It("should not create event listener if not requested", func() {
+			createComponent(resourceKey)
+			setComponentDevfileModel(resourceKey)
+
+			ensureOnePipelineRunCreated(resourceKey)
+
+			eventListener := &triggersapi.EventListener{}
+			err := k8sClient.Get(ctx, resourceKey, eventListener)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("Test maintenance mode", func() {
+
+		_ = BeforeEach(func() {
+			createConfigMap(maintenanceConfigMapKey.Name, maintenanceConfigMapKey.Namespace,
+				map[string]string{MaintenanceModeConfigMapKey: "true"})
+		}, 30)
+
+		_ = AfterEach(func() {
+			configMap := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, maintenanceConfigMapKey, configMap)).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, configMap)).Should(Succeed())
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should pause the build until the maintenance mode is lifted", func() {
+			createComponent(resourceKey)
+			setComponentDevfileModel(resourceKey)
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: MaintenanceModeConditionType, Status: metav1.ConditionTrue}))
+			ensureNoPipelineRunsCreated(resourceKey)
+
+			// Lift the maintenance mode
+			Eventually(func() error {
+				configMap := &corev1.ConfigMap{}
+				if err := k8sClient.Get(ctx, maintenanceConfigMapKey, configMap); err != nil {
+					return err
+				}
+				configMap.Data[MaintenanceModeConfigMapKey] = "false"
+				return k8sClient.Update(ctx, configMap)
+			}, timeout, interval).Should(Succeed())
+
+			ensureOnePipelineRunCreated(resourceKey)
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: MaintenanceModeConditionType, Status: metav1.ConditionFalse}))
+		})
+	})
+
+	Context("Test disabled builds", func() {
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+			restartComponentBuildReconciler(nil)
+		}, 30)
+
+		It("should not submit the build while builds are disabled", func() {
+			restartComponentBuildReconciler(func(r *ComponentBuildReconciler) {
+				r.BuildsDisabled = true
+			})
+			createComponent(resourceKey)
+			setComponentDevfileModel(resourceKey)
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: BuildsDisabledConditionType, Status: metav1.ConditionTrue}))
+			ensureNoPipelineRunsCreated(resourceKey)
+
+			// Enabled builds are submitted when the component is reconciled again after restart of the controller
+			restartComponentBuildReconciler(nil)
+
+			ensureOnePipelineRunCreated(resourceKey)
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: BuildsDisabledConditionType, Status: metav1.ConditionFalse}))
+		})
+	})
+
+	Context("Test oversized build PipelineRuns", func() {
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+			restartComponentBuildReconciler(nil)
+		}, 30)
+
+		It("should not submit the build PipelineRun exceeding the size limit", func() {
+			restartComponentBuildReconciler(func(r *ComponentBuildReconciler) {
+				r.MaxPipelineRunSize = 64 * 1024
+			})
+			component := &appstudiov1alpha1.Component{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Component",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      HASCompName,
+					Namespace: HASAppNamespace,
+					Annotations: map[string]string{
+						PipelineParamsAnnotationName: `{"large-config": "` + strings.Repeat("a", 100*1024) + `"}`,
+					},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+			setComponentDevfileModel(resourceKey)
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: PipelineRunTooLargeConditionType, Status: metav1.ConditionTrue}))
+			Expect(getComponent(resourceKey).Status.Conditions).To(HaveComponentConditionMessage(PipelineRunTooLargeConditionType, ContainSubstring("param large-config")))
+			ensureNoPipelineRunsCreated(resourceKey)
+		})
+	})
+
+	Context("Test invalid build annotations", func() {
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should not submit the build until the invalid annotation is fixed", func() {
+			component := &appstudiov1alpha1.Component{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Component",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      HASCompName,
+					Namespace: HASAppNamespace,
+					Annotations: map[string]string{
+						BuildTimeoutsAnnotationName: `{"pipeline": "1 hour"}`,
+					},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+			setComponentDevfileModel(resourceKey)
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: InvalidConfigurationConditionType, Status: metav1.ConditionTrue}))
+			Expect(getComponent(resourceKey).Status.Conditions).To(HaveComponentConditionMessage(InvalidConfigurationConditionType, ContainSubstring(BuildTimeoutsAnnotationName)))
+			ensureNoPipelineRunsCreated(resourceKey)
+
+			Eventually(func() error {
+				component := getComponent(resourceKey)
+				component.Annotations[BuildTimeoutsAnnotationName] = `{"pipeline": "1h"}`
+				return k8sClient.Update(ctx, component)
+			}, timeout, interval).Should(Succeed())
+			ensureOnePipelineRunCreated(resourceKey)
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: InvalidConfigurationConditionType, Status: metav1.ConditionFalse}))
+		})
+	})
+
+	Context("Test waiting for builds of other components", func() {
+
+		const dependencyComponentName = "dependency-component"
+
+		dependencyKey := types.NamespacedName{Name: dependencyComponentName, Namespace: HASAppNamespace}
+
+		_ = BeforeEach(func() {
+			// The dependency is a container image component, so it is not built by the controller
+			dependency := &appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      dependencyComponentName,
+					Namespace: HASAppNamespace,
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: dependencyComponentName,
+					Application:   HASAppName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							ImageSource: &appstudiov1alpha1.ImageSource{
+								ContainerImage: "quay.io/foo/dependency:latest",
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, dependency)).Should(Succeed())
+		}, 30)
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(dependencyKey)
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(dependencyKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should submit the build after the dependency is built", func() {
+			component := &appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      HASCompName,
+					Namespace: HASAppNamespace,
+					Annotations: map[string]string{
+						WaitForComponentsAnnotationName: dependencyComponentName,
+					},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+			setComponentDevfileModel(resourceKey)
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: WaitingForComponentsConditionType, Status: metav1.ConditionTrue}))
+			ensureNoPipelineRunsCreated(resourceKey)
+
+			// Simulate a successful build of the dependency
+			dependencyBuild := &tektonapi.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: dependencyComponentName + "-",
+					Namespace:    HASAppNamespace,
+					Labels:       map[string]string{ComponentNameLabelName: dependencyComponentName},
+				},
+				Spec: tektonapi.PipelineRunSpec{
+					PipelineRef: &tektonapi.PipelineRef{Name: "noop"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, dependencyBuild)).Should(Succeed())
+			dependencyBuild.Status.SetCondition(&apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionTrue,
+				Reason: "Succeeded",
+			})
+			Expect(k8sClient.Status().Update(ctx, dependencyBuild)).Should(Succeed())
+
+			ensureOnePipelineRunCreated(resourceKey)
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: WaitingForComponentsConditionType, Status: metav1.ConditionFalse}))
+		})
+
+		It("should report components waiting for each other", func() {
+			component := &appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      HASCompName,
+					Namespace: HASAppNamespace,
+					Annotations: map[string]string{
+						WaitForComponentsAnnotationName: dependencyComponentName,
+					},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+			setComponentDevfileModel(resourceKey)
+
+			Eventually(func() error {
+				dependency := getComponent(dependencyKey)
+				if dependency.Annotations == nil {
+					dependency.Annotations = map[string]string{}
+				}
+				dependency.Annotations[WaitForComponentsAnnotationName] = HASCompName
+				return k8sClient.Update(ctx, dependency)
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: WaitingForComponentsConditionType, Reason: WaitingForComponentsReasonCycle}))
+			ensureNoPipelineRunsCreated(resourceKey)
+		})
+	})
+
+	Context("Test PipelineRun retention cleanup", func() {
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+		}, 30)
+
+		It("should delete only completed build PipelineRuns older than the retention period", func() {
+			createPipelineRun := func() *tektonapi.PipelineRun {
+				pipelineRun := &tektonapi.PipelineRun{
+					ObjectMeta: metav1.ObjectMeta{
+						GenerateName: HASCompName + "-",
+						Namespace:    HASAppNamespace,
+						Labels:       map[string]string{ComponentNameLabelName: HASCompName},
+					},
+					Spec: tektonapi.PipelineRunSpec{
+						PipelineRef: &tektonapi.PipelineRef{Name: "noop"},
+					},
+				}
+				Expect(k8sClient.Create(ctx, pipelineRun)).Should(Succeed())
+				return pipelineRun
+			}
+			failedPipelineRun := createPipelineRun()
+			completionTime := metav1.Now()
+			failedPipelineRun.Status.StartTime = &completionTime
+			failedPipelineRun.Status.CompletionTime = &completionTime
+			failedPipelineRun.Status.SetCondition(&apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionFalse,
+			})
+			Expect(k8sClient.Status().Update(ctx, failedPipelineRun)).Should(Succeed())
+			pendingPipelineRun := createPipelineRun()
+
+			cleaner := &PipelineRunRetentionCleaner{
+				Client:    k8sClient,
+				Log:       ctrl.Log.WithName("PipelineRunRetentionCleaner"),
+				Retention: DefaultPipelineRunRetention,
+			}
+
+			Expect(cleaner.deleteExpiredPipelineRuns(ctx, time.Now())).Should(Succeed())
+			Expect(listComponentPipelienRuns(resourceKey).Items).To(HaveLen(2))
+
+			Expect(cleaner.deleteExpiredPipelineRuns(ctx, time.Now().Add(DefaultPipelineRunRetention+time.Hour))).Should(Succeed())
+			Eventually(func() bool {
+				pipelineRuns := listComponentPipelienRuns(resourceKey).Items
+				return len(pipelineRuns) == 1 && pipelineRuns[0].Name == pendingPipelineRun.Name
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("Test build failure classification", func() {
+
+		_ = BeforeEach(func() {
+			createComponent(resourceKey)
+		}, 30)
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should record the failure category of the build", func() {
+			setComponentDevfileModel(resourceKey)
+			ensureOnePipelineRunCreated(resourceKey)
+
+			pipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+			pipelineRun.Status.SetCondition(&apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionFalse,
+				Reason: "Failed",
+			})
+			pipelineRun.Status.TaskRuns = map[string]*tektonapi.PipelineRunTaskRunStatus{
+				pipelineRun.Name + "-clone-repository": {
+					PipelineTaskName: "clone-repository",
+					Status: &tektonapi.TaskRunStatus{
+						Status: duckv1beta1.Status{
+							Conditions: duckv1beta1.Conditions{
+								{
+									Type:    apis.ConditionSucceeded,
+									Status:  corev1.ConditionFalse,
+									Reason:  "Failed",
+									Message: "fatal: repository not found",
+								},
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, &pipelineRun)).Should(Succeed())
+
+			Eventually(func() string {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: pipelineRun.Name, Namespace: HASAppNamespace}, &pipelineRun)).Should(Succeed())
+				return pipelineRun.Annotations[BuildFailureCategoryAnnotationName]
+			}, timeout, interval).Should(Equal(BuildFailureCategoryClone))
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: BuildFailedConditionType, Status: metav1.ConditionTrue, Reason: "CloneFailed"}))
+		})
+	})
+
+	Context("Test required Tekton feature flags", func() {
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should submit the build only when required feature flags are enabled", func() {
+			component := &appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      HASCompName,
+					Namespace: HASAppNamespace,
+					Annotations: map[string]string{
+						RequireFeatureFlagsAnnotationName: `{"enable-api-fields": "alpha"}`,
+					},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+			setComponentDevfileModel(resourceKey)
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: FeatureFlagsNotSatisfiedConditionType, Status: metav1.ConditionTrue}))
+			ensureNoPipelineRunsCreated(resourceKey)
+
+			// The build is submitted once the feature flags are enabled
+			tektonNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: DefaultTektonNamespace}}
+			if err := k8sClient.Create(ctx, tektonNamespace); err != nil {
+				Expect(errors.IsAlreadyExists(err)).To(BeTrue())
+			}
+			createConfigMap(tektonFeatureFlagsConfigMapName, DefaultTektonNamespace, map[string]string{tektonAPIFieldsFeatureFlag: "alpha"})
+			defer func() {
+				featureFlags := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: tektonFeatureFlagsConfigMapName, Namespace: DefaultTektonNamespace}}
+				Expect(k8sClient.Delete(ctx, featureFlags)).Should(Succeed())
+			}()
+
+			ensureOnePipelineRunCreated(resourceKey)
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: FeatureFlagsNotSatisfiedConditionType, Status: metav1.ConditionFalse}))
+		})
+	})
+
+	Context("Test GitHub Packages target registry", func() {
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should push the image into GitHub Packages of the component repository", func() {
+			registrySecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      defaultGHCRSecretName,
+					Namespace: HASAppNamespace,
+				},
+				Type:       corev1.SecretTypeDockerConfigJson,
+				StringData: map[string]string{corev1.DockerConfigJsonKey: `{"auths":{"ghcr.io":{"auth":"Zm9vOmJhcg=="}}}`},
+			}
+			Expect(k8sClient.Create(ctx, registrySecret)).Should(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, registrySecret)).Should(Succeed())
+			}()
+
+			component := &appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        HASCompName,
+					Namespace:   HASAppNamespace,
+					Annotations: map[string]string{RegistryAnnotationName: RegistryGHCR},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+			setComponentDevfileModel(resourceKey)
+			ensureOnePipelineRunCreated(resourceKey)
+
+			pipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+			Expect(getPipelineRunParam(pipelineRun, "output-image")).To(HavePrefix("ghcr.io/devfile-samples/devfile-sample-java-springboot-basic:"))
+
+			pipelineServiceAccount := &corev1.ServiceAccount{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "pipeline", Namespace: HASAppNamespace}, pipelineServiceAccount)).Should(Succeed())
+			Expect(pipelineServiceAccount.Secrets).To(ContainElement(corev1.ObjectReference{Name: defaultGHCRSecretName}))
+		})
+	})
+
+	Context("Test skipping initial build of existing image", func() {
+
+		const outputImage = "quay.io/foo/existing:v1"
+		const commitSHA = "0123456789abcdef0123456789abcdef01234567"
+
+		var repositoryClient *mockImageRepositoryClient
+
+		_ = BeforeEach(func() {
+			repositoryClient = &mockImageRepositoryClient{
+				repositories: map[string]bool{"quay.io/foo/existing": true},
+				images:       map[string]bool{},
+			}
+			restartComponentBuildReconciler(func(r *ComponentBuildReconciler) {
+				r.SkipExistingImageBuild = true
+				r.ImageRepositoryClient = repositoryClient
+			})
+
+			component := &appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      HASCompName,
+					Namespace: HASAppNamespace,
+					Annotations: map[string]string{
+						PipelineParamsAnnotationName: `{"revision": "` + commitSHA + `"}`,
+					},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+					Build: appstudiov1alpha1.Build{
+						ContainerImage: outputImage,
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+		}, 30)
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+			restartComponentBuildReconciler(nil)
+		}, 30)
+
+		setComponentStatus := func() {
+			Eventually(func() error {
+				component := getComponent(resourceKey)
+				component.Status.Devfile = "version: 2.2.0"
+				// The status always holds the output image, it must not be mistaken for a built image
+				component.Status.ContainerImage = outputImage
+				return k8sClient.Status().Update(ctx, component)
+			}, timeout, interval).Should(Succeed())
+		}
+
+		It("should skip the build if the image of the commit exists", func() {
+			repositoryClient.images["quay.io/foo/existing:"+commitSHA] = true
+			setComponentStatus()
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: BuiltConditionType, Reason: BuiltReasonSkippedExistingImage}))
+			ensureNoPipelineRunsCreated(resourceKey)
+		})
+
+		It("should not report a rebuild if the rebuild is skipped", func() {
+			repositoryClient.images["quay.io/foo/existing:"+commitSHA] = true
+			setComponentStatus()
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: BuiltConditionType, Reason: BuiltReasonSkippedExistingImage}))
+			buildSpecHash := getComponent(resourceKey).Annotations[BuildSpecHashAnnotationName]
+
+			Eventually(func() error {
+				component := getComponent(resourceKey)
+				component.Spec.Context = "./"
+				return k8sClient.Update(ctx, component)
+			}, timeout, interval).Should(Succeed())
+			Eventually(func() bool {
+				component := getComponent(resourceKey)
+				return component.Annotations[BuildSpecHashAnnotationName] != buildSpecHash && !isBuildSpecChanged(*component)
+			}, timeout, interval).Should(BeTrue())
+
+			ensureNoPipelineRunsCreated(resourceKey)
+			Consistently(func() bool {
+				events := &corev1.EventList{}
+				Expect(k8sClient.List(ctx, events, client.InNamespace(HASAppNamespace))).Should(Succeed())
+				for _, event := range events.Items {
+					if event.InvolvedObject.Name == HASCompName && event.Reason == RebuildEventReason {
+						return true
+					}
+				}
+				return false
+			}, 3*time.Second, interval).Should(BeFalse())
+		})
+
+		It("should submit the build if the image of the commit is missing", func() {
+			repositoryClient.images[outputImage] = true
+			setComponentStatus()
+
+			ensureOnePipelineRunCreated(resourceKey)
+			Expect(getComponent(resourceKey).Status.Conditions).NotTo(HaveComponentCondition(metav1.Condition{Type: BuiltConditionType}))
+		})
+	})
+
+	Context("Test build audit records", func() {
+
+		_ = BeforeEach(func() {
+			createComponent(resourceKey)
+		}, 30)
+
+		_ = AfterEach(func() {
+			auditRecords := &buildv1alpha1.BuildAuditRecordList{}
+			Expect(k8sClient.List(ctx, auditRecords, client.InNamespace(HASAppNamespace))).Should(Succeed())
+			for i := range auditRecords.Items {
+				auditRecord := &auditRecords.Items[i]
+				// Only an administrator could remove the finalizer
+				auditRecord.Finalizers = nil
+				Expect(k8sClient.Update(ctx, auditRecord)).Should(Succeed())
+				Expect(k8sClient.Delete(ctx, auditRecord)).Should(Succeed())
+			}
+
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should record the build submission", func() {
+			setComponentDevfileModel(resourceKey)
+			ensureOnePipelineRunCreated(resourceKey)
+			pipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+
+			auditRecord := &buildv1alpha1.BuildAuditRecord{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: pipelineRun.Name, Namespace: HASAppNamespace}, auditRecord)
+			}, timeout, interval).Should(Succeed())
+			Expect(auditRecord.Spec.ComponentName).To(Equal(HASCompName))
+			Expect(auditRecord.Spec.ComponentNamespace).To(Equal(HASAppNamespace))
+			Expect(auditRecord.Spec.PipelineRunName).To(Equal(pipelineRun.Name))
+			Expect(auditRecord.Spec.TriggerReason).To(Equal(BuildTriggerReasonInitialBuild))
+			Expect(pipelineRun.Annotations[BuildTriggerAnnotationName]).To(Equal(BuildTriggerReasonInitialBuild))
+			Expect(auditRecord.Spec.GitURL).To(Equal(SampleRepoLink))
+			Expect(auditRecord.Finalizers).To(ContainElement(buildv1alpha1.BuildAuditRecordFinalizer))
+			Expect(auditRecord.OwnerReferences).To(BeEmpty())
+
+			// The record outlives the deleted component
+			deleteComponent(resourceKey)
+			Consistently(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: pipelineRun.Name, Namespace: HASAppNamespace}, auditRecord)
+			}, 5*time.Second, interval).Should(Succeed())
+			createComponent(resourceKey)
+		})
+
+		It("should record the reason of the manual rebuild", func() {
+			setComponentDevfileModel(resourceKey)
+			ensureOnePipelineRunCreated(resourceKey)
+			initialBuildName := listComponentPipelienRuns(resourceKey).Items[0].Name
+
+			component := getComponent(resourceKey)
+			component.Annotations[InitialBuildAnnotationName] = "false"
+			Expect(k8sClient.Update(ctx, component)).Should(Succeed())
+
+			var rebuildName string
+			Eventually(func() bool {
+				for _, pipelineRun := range listComponentPipelienRuns(resourceKey).Items {
+					if pipelineRun.Name != initialBuildName {
+						rebuildName = pipelineRun.Name
+						return true
+					}
+				}
+				return false
+			}, timeout, interval).Should(BeTrue())
+
+			auditRecord := &buildv1alpha1.BuildAuditRecord{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: rebuildName, Namespace: HASAppNamespace}, auditRecord)
+			}, timeout, interval).Should(Succeed())
+			Expect(auditRecord.Spec.TriggerReason).To(Equal(BuildTriggerReasonManualBuild))
+		})
+	})
+
+	Context("Test capture of generated build PipelineRun", func() {
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should store the generated PipelineRun in the capture ConfigMap", func() {
+			component := &appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        HASCompName,
+					Namespace:   HASAppNamespace,
+					Annotations: map[string]string{CapturePipelineRunAnnotationName: "true"},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+			setComponentDevfileModel(resourceKey)
+			ensureOnePipelineRunCreated(resourceKey)
+			pipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+
+			captureConfigMap := &corev1.ConfigMap{}
+			captureConfigMapKey := types.NamespacedName{Name: HASCompName + PipelineRunCaptureConfigMapSuffix, Namespace: HASAppNamespace}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, captureConfigMapKey, captureConfigMap)
+			}, timeout, interval).Should(Succeed())
+
+			capturedPipelineRun := &tektonapi.PipelineRun{}
+			Expect(yaml.Unmarshal([]byte(captureConfigMap.Data[PipelineRunCaptureConfigMapKey]), capturedPipelineRun)).Should(Succeed())
+			redactPipelineRun(&pipelineRun)
+			Expect(capturedPipelineRun.Spec).To(Equal(pipelineRun.Spec))
+			Expect(capturedPipelineRun.Labels).To(Equal(pipelineRun.Labels))
+		})
+	})
+
+	Context("Test reconcile of build relevant changes only", func() {
+
+		_ = BeforeEach(func() {
+			createComponent(resourceKey)
+		}, 30)
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should reconcile source change, but not unrelated changes", func() {
+			setComponentDevfileModel(resourceKey)
+			ensureOnePipelineRunCreated(resourceKey)
+			Eventually(func() bool {
+				return !isBuildSpecChanged(*getComponent(resourceKey))
+			}, timeout, interval).Should(BeTrue())
+			buildSpecHash := getComponent(resourceKey).Annotations[BuildSpecHashAnnotationName]
+
+			Eventually(func() error {
+				component := getComponent(resourceKey)
+				component.Spec.Replicas = 2
+				return k8sClient.Update(ctx, component)
+			}, timeout, interval).Should(Succeed())
+			Consistently(func() string {
+				return getComponent(resourceKey).Annotations[BuildSpecHashAnnotationName]
+			}, 3*time.Second, interval).Should(Equal(buildSpecHash))
+
+			Eventually(func() error {
+				component := getComponent(resourceKey)
+				component.Spec.Source.GitSource.URL = SampleRepoLink + "-changed"
+				return k8sClient.Update(ctx, component)
+			}, timeout, interval).Should(Succeed())
+			Eventually(func() bool {
+				component := getComponent(resourceKey)
+				return component.Annotations[BuildSpecHashAnnotationName] != buildSpecHash && !isBuildSpecChanged(*component)
+			}, timeout, interval).Should(BeTrue())
+			ensureOnePipelineRunCreated(resourceKey)
+		})
+	})
+
+	Context("Test build approval", func() {
+
+		approver := &stubBuildApprover{}
+
+		_ = BeforeEach(func() {
+			restartComponentBuildReconciler(func(r *ComponentBuildReconciler) {
+				r.BuildApprover = approver
+			})
+
+			component := &appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        HASCompName,
+					Namespace:   HASAppNamespace,
+					Annotations: map[string]string{RequireBuildApprovalAnnotationName: "true"},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+		}, 30)
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+			restartComponentBuildReconciler(nil)
+		}, 30)
+
+		It("should submit the build when approved", func() {
+			approver.setState(BuildApprovalStateApproved)
+			setComponentDevfileModel(resourceKey)
+
+			ensureOnePipelineRunCreated(resourceKey)
+		})
+
+		It("should not submit the build when denied", func() {
+			approver.setState(BuildApprovalStateDenied)
+			setComponentDevfileModel(resourceKey)
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: PendingApprovalConditionType, Reason: PendingApprovalReasonDenied}))
+			ensureNoPipelineRunsCreated(resourceKey)
+		})
+
+		It("should poll the approval while pending and submit the build once approved", func() {
+			approver.setState(BuildApprovalStatePending)
+			setComponentDevfileModel(resourceKey)
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: PendingApprovalConditionType, Status: metav1.ConditionTrue}))
+			ensureNoPipelineRunsCreated(resourceKey)
+
+			result, err := componentBuildReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: resourceKey})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(buildApprovalPollInterval))
+
+			approver.setState(BuildApprovalStateApproved)
+			Eventually(func() error {
+				_, err := componentBuildReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: resourceKey})
+				return err
+			}, timeout, interval).Should(Succeed())
+			ensureOnePipelineRunCreated(resourceKey)
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: PendingApprovalConditionType, Reason: PendingApprovalReasonApproved}))
+		})
+	})
+
+	Context("Test image name function", func() {
+
+		_ = BeforeEach(func() {
+			restartComponentBuildReconciler(func(r *ComponentBuildReconciler) {
+				r.ImageNameFunc = func(component appstudiov1alpha1.Component) string {
+					return "registry.example.com/" + component.Namespace + "/" + component.Name
+				}
+			})
+			createComponent(resourceKey)
+		}, 30)
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+			restartComponentBuildReconciler(nil)
+		}, 30)
+
+		It("should use the output image returned by the image name function", func() {
+			setComponentDevfileModel(resourceKey)
+			ensureOnePipelineRunCreated(resourceKey)
+
+			pipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+			Expect(getPipelineRunParam(pipelineRun, "output-image")).To(Equal("registry.example.com/" + HASAppNamespace + "/" + HASCompName))
+		})
+	})
+
+	Context("Test required git credentials", func() {
+
+		createComponentWithSecret := func(annotations map[string]string, secret string) {
+			component := &appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        HASCompName,
+					Namespace:   HASAppNamespace,
+					Annotations: annotations,
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Secret:        secret,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+		}
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should not build the component without git Secret if credentials are required", func() {
+			createComponentWithSecret(map[string]string{RequireGitCredentialsAnnotationName: "true"}, "")
+			setComponentDevfileModel(resourceKey)
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: MissingCredentialsConditionType, Status: metav1.ConditionTrue}))
+			ensureNoPipelineRunsCreated(resourceKey)
+		})
+
+		It("should build the component once the required git Secret is provided", func() {
+			createComponentWithSecret(map[string]string{RequireGitCredentialsAnnotationName: "true"}, "missing-git-secret")
+			setComponentDevfileModel(resourceKey)
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: MissingCredentialsConditionType, Status: metav1.ConditionTrue}))
+			ensureNoPipelineRunsCreated(resourceKey)
+
+			gitSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "missing-git-secret",
+					Namespace: HASAppNamespace,
+				},
+				Type:       corev1.SecretTypeBasicAuth,
+				StringData: map[string]string{corev1.BasicAuthUsernameKey: "foo", corev1.BasicAuthPasswordKey: "bar"},
+			}
+			Expect(k8sClient.Create(ctx, gitSecret)).Should(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, gitSecret)).Should(Succeed())
+			}()
+
+			// Secrets are not watched, do not wait for the requeue
+			Eventually(func() error {
+				_, err := componentBuildReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: resourceKey})
+				return err
+			}, timeout, interval).Should(Succeed())
+			ensureOnePipelineRunCreated(resourceKey)
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: MissingCredentialsConditionType, Reason: MissingCredentialsReasonProvided}))
+		})
+
+		It("should build the component without git Secret if anonymous clone is allowed", func() {
+			createComponentWithSecret(nil, "")
+			setComponentDevfileModel(resourceKey)
+
+			ensureOnePipelineRunCreated(resourceKey)
+			Expect(getComponent(resourceKey).Status.Conditions).NotTo(HaveComponentCondition(metav1.Condition{Type: MissingCredentialsConditionType}))
+		})
+	})
+
+	Context("Test pipeline bundle signature verification", func() {
+
+		verifier := &stubBundleVerifier{}
+
+		_ = BeforeEach(func() {
+			restartComponentBuildReconciler(func(r *ComponentBuildReconciler) {
+				r.BundleVerifier = verifier
+			})
+			createComponent(resourceKey)
+		}, 30)
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+			restartComponentBuildReconciler(nil)
+		}, 30)
+
+		It("should submit the build if the bundle signature is verified", func() {
+			verifier.setError(nil)
+			setComponentDevfileModel(resourceKey)
+
+			ensureOnePipelineRunCreated(resourceKey)
+			Expect(getComponent(resourceKey).Status.Conditions).To(HaveComponentCondition(metav1.Condition{Type: BundleVerifiedConditionType, Status: metav1.ConditionTrue}))
+			pipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+			Expect(pipelineRun.Spec.PipelineRef.Bundle).To(HaveSuffix("@" + stubBundleDigest))
+		})
+
+		It("should not submit the build if the bundle signature verification fails", func() {
+			verifier.setError(fmt.Errorf("no matching signatures"))
+			setComponentDevfileModel(resourceKey)
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: BundleVerifiedConditionType, Reason: BundleVerifiedReasonFailed}))
+			ensureNoPipelineRunsCreated(resourceKey)
+		})
+	})
+
+	Context("Test namespace build service configuration", func() {
+
+		const otherComponentName = "other-component"
+
+		var buildServiceConfig *buildv1alpha1.BuildServiceConfig
+
+		_ = BeforeEach(func() {
+			buildServiceConfig = &buildv1alpha1.BuildServiceConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      buildv1alpha1.BuildServiceConfigName,
+					Namespace: HASAppNamespace,
+				},
+			}
+		}, 30)
+
+		_ = AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, buildServiceConfig)).Should(Succeed())
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should override the build defaults", func() {
+			buildServiceConfig.Spec = buildv1alpha1.BuildServiceConfigSpec{
+				DefaultPipelineName: "java-builder",
+				BuildTimeout:        &metav1.Duration{Duration: 2 * time.Hour},
+				ResourceClass:       "large",
+			}
+			Expect(k8sClient.Create(ctx, buildServiceConfig)).Should(Succeed())
+
+			createComponent(resourceKey)
+			setComponentDevfileModel(resourceKey)
+
+			ensureOnePipelineRunCreated(resourceKey)
+			pipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+			Expect(pipelineRun.Spec.PipelineRef.Name).To(Equal("java-builder"))
+			Expect(pipelineRun.Spec.Timeout).ToNot(BeNil())
+			Expect(pipelineRun.Spec.Timeout.Duration).To(Equal(2 * time.Hour))
+			Expect(pipelineRun.Labels[ResourceClassLabelName]).To(Equal("large"))
+		})
+
+		It("should queue the build until the namespace build limit is raised", func() {
+			buildServiceConfig.Spec.MaxConcurrentBuilds = 1
+			Expect(k8sClient.Create(ctx, buildServiceConfig)).Should(Succeed())
+
+			// Simulate a running build of another component in the namespace
+			activeBuild := &tektonapi.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: otherComponentName + "-",
+					Namespace:    HASAppNamespace,
+					Labels:       map[string]string{ComponentNameLabelName: otherComponentName},
+				},
+				Spec: tektonapi.PipelineRunSpec{
+					PipelineRef: &tektonapi.PipelineRef{Name: "noop"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, activeBuild)).Should(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, activeBuild)).Should(Succeed())
+			}()
+
+			createComponent(resourceKey)
+			setComponentDevfileModel(resourceKey)
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: BuildBlockedConditionType, Reason: BuildBlockedReasonNamespaceConcurrencyLimit}))
+			ensureNoPipelineRunsCreated(resourceKey)
+
+			// The configuration change requeues the blocked component
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(buildServiceConfig), buildServiceConfig); err != nil {
+					return err
+				}
+				buildServiceConfig.Spec.MaxConcurrentBuilds = 2
+				return k8sClient.Update(ctx, buildServiceConfig)
+			}, timeout, interval).Should(Succeed())
+
+			ensureOnePipelineRunCreated(resourceKey)
+		})
+	})
+
+	Context("Test build tiers", func() {
+
+		buildTiersConfigMapKey := types.NamespacedName{Name: "build-tiers", Namespace: HASAppNamespace}
+
+		createComponentWithTier := func(tier string) {
+			component := &appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      HASCompName,
+					Namespace: HASAppNamespace,
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+				},
+			}
+			if tier != "" {
+				component.Labels = map[string]string{BuildTierLabelName: tier}
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+		}
+
+		_ = BeforeEach(func() {
+			restartComponentBuildReconciler(func(r *ComponentBuildReconciler) {
+				r.BuildTiersConfigMap = &buildTiersConfigMapKey
+			})
+			createConfigMap(buildTiersConfigMapKey.Name, buildTiersConfigMapKey.Namespace,
+				map[string]string{BuildTiersConfigMapKey: testBuildTiersYAML})
+		}, 30)
+
+		_ = AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: buildTiersConfigMapKey.Name, Namespace: buildTiersConfigMapKey.Namespace},
+			})).Should(Succeed())
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+			restartComponentBuildReconciler(nil)
+		}, 30)
+
+		It("should apply resources of the premium tier to the build", func() {
+			createComponentWithTier("premium")
+			setComponentDevfileModel(resourceKey)
+
+			ensureOnePipelineRunCreated(resourceKey)
+			pipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+			Expect(pipelineRun.Labels[BuildTierLabelName]).To(Equal("premium"))
+			Expect(pipelineRun.Spec.Timeout.Duration).To(Equal(3 * time.Hour))
+			Expect(pipelineRun.Spec.PodTemplate).ToNot(BeNil())
+			Expect(pipelineRun.Spec.PodTemplate.PriorityClassName).ToNot(BeNil())
+			Expect(*pipelineRun.Spec.PodTemplate.PriorityClassName).To(Equal("build-premium"))
+			Expect(pipelineRun.Spec.TaskRunSpecs).To(HaveLen(1))
+			Expect(pipelineRun.Spec.TaskRunSpecs[0].PipelineTaskName).To(Equal("build-container"))
+			Expect(pipelineRun.Spec.TaskRunSpecs[0].StepOverrides).To(HaveLen(1))
+			stepOverride := pipelineRun.Spec.TaskRunSpecs[0].StepOverrides[0]
+			Expect(stepOverride.Name).To(Equal("build"))
+			Expect(stepOverride.Resources.Requests.Cpu().String()).To(Equal("2"))
+			Expect(stepOverride.Resources.Requests.Memory().String()).To(Equal("4Gi"))
+			Expect(stepOverride.Resources.Limits.Memory().String()).To(Equal("8Gi"))
+		})
+
+		It("should apply the base tier to the build of a component without tier", func() {
+			createComponentWithTier("")
+			setComponentDevfileModel(resourceKey)
+
+			ensureOnePipelineRunCreated(resourceKey)
+			pipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+			Expect(pipelineRun.Labels[BuildTierLabelName]).To(Equal(DefaultBuildTier))
+			Expect(pipelineRun.Spec.Timeout.Duration).To(Equal(time.Hour))
+			Expect(pipelineRun.Spec.TaskRunSpecs).To(BeEmpty())
+		})
+	})
+
+	Context("Test resubmission of deleted builds", func() {
+
+		_ = BeforeEach(func() {
+			restartComponentBuildReconciler(func(r *ComponentBuildReconciler) {
+				r.ResubmitDeletedBuilds = true
+			})
+			createComponent(resourceKey)
+		}, 30)
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+			restartComponentBuildReconciler(nil)
+		}, 30)
+
+		It("should resubmit the build if its PipelineRun is deleted before completion", func() {
+			setComponentDevfileModel(resourceKey)
+			ensureOnePipelineRunCreated(resourceKey)
+			deletedPipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+			Expect(deletedPipelineRun.Finalizers).To(ContainElement(BuildResubmissionFinalizer))
+
+			Expect(k8sClient.Delete(ctx, &deletedPipelineRun)).Should(Succeed())
+
+			Eventually(func() bool {
+				pipelineRuns := listComponentPipelienRuns(resourceKey).Items
+				return len(pipelineRuns) == 1 && pipelineRuns[0].Name != deletedPipelineRun.Name
+			}, timeout, interval).Should(BeTrue())
+			Expect(getComponent(resourceKey).Annotations[DeletedBuildResubmissionsAnnotationName]).To(Equal("1"))
+		})
+
+		It("should not resubmit the build if its completed PipelineRun is pruned", func() {
+			setComponentDevfileModel(resourceKey)
+			ensureOnePipelineRunCreated(resourceKey)
+			prunedPipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+
+			prunedPipelineRun.Status.SetCondition(&apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionTrue,
+				Reason: "Succeeded",
+			})
+			Expect(k8sClient.Status().Update(ctx, &prunedPipelineRun)).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, &prunedPipelineRun)).Should(Succeed())
+
+			Eventually(func() bool {
+				return len(listComponentPipelienRuns(resourceKey).Items) == 0
+			}, timeout, interval).Should(BeTrue())
+			ensureNoPipelineRunsCreated(resourceKey)
+			Expect(getComponent(resourceKey).Annotations[DeletedBuildResubmissionsAnnotationName]).To(BeEmpty())
+		})
+	})
+
+	Context("Test build pipeline version constraint", func() {
+
+		var pipelines []*tektonapi.Pipeline
+
+		setPipelineVersionConstraint := func(constraint string) {
+			Eventually(func() error {
+				component := getComponent(resourceKey)
+				if component.Annotations == nil {
+					component.Annotations = map[string]string{}
+				}
+				component.Annotations[PipelineVersionConstraintAnnotationName] = constraint
+				return k8sClient.Update(ctx, component)
+			}, timeout, interval).Should(Succeed())
+		}
+
+		_ = BeforeEach(func() {
+			pipelines = nil
+			for name, version := range map[string]string{"noop-v1-0": "1.0.0", "noop-v1-5": "1.5.0", "noop-v2-0": "2.0.0"} {
+				pipeline := &tektonapi.Pipeline{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: HASAppNamespace,
+						Labels: map[string]string{
+							BuildPipelineLabelName:   "noop",
+							PipelineVersionLabelName: version,
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, pipeline)).Should(Succeed())
+				pipelines = append(pipelines, pipeline)
+			}
+			createComponent(resourceKey)
+		}, 30)
+
+		_ = AfterEach(func() {
+			for _, pipeline := range pipelines {
+				Expect(k8sClient.Delete(ctx, pipeline)).Should(Succeed())
+			}
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should build with the latest compatible pipeline version", func() {
+			setPipelineVersionConstraint("^1")
+			setComponentDevfileModel(resourceKey)
+
+			ensureOnePipelineRunCreated(resourceKey)
+			pipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+			Expect(pipelineRun.Spec.PipelineRef.Name).To(Equal("noop-v1-5"))
+			Expect(pipelineRun.Spec.PipelineRef.Bundle).To(BeEmpty())
+		})
+
+		It("should not build if there is no compatible pipeline version", func() {
+			setPipelineVersionConstraint(">=3")
+			setComponentDevfileModel(resourceKey)
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: PipelineVersionUnsatisfiedConditionType, Reason: PipelineVersionUnsatisfiedReasonNoCompatible}))
+			ensureNoPipelineRunsCreated(resourceKey)
+		})
+	})
+
+	Context("Test unknown Application policy", func() {
+
+		applicationKey := types.NamespacedName{Name: HASAppName, Namespace: HASAppNamespace}
+
+		_ = BeforeEach(func() {
+			createComponent(resourceKey)
+		}, 30)
+
+		_ = AfterEach(func() {
+			application := &appstudiov1alpha1.Application{}
+			if err := k8sClient.Get(ctx, applicationKey, application); err == nil {
+				Expect(k8sClient.Delete(ctx, application)).Should(Succeed())
+			}
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+			restartComponentBuildReconciler(nil)
+		}, 30)
+
+		It("should build component of missing Application with Proceed policy", func() {
+			// The policy is set before the manager starts, as it decides whether Applications are watched
+			restartComponentBuildReconciler(func(r *ComponentBuildReconciler) {
+				r.UnknownApplicationPolicy = UnknownApplicationPolicyProceed
+			})
+			setComponentDevfileModel(resourceKey)
+
+			ensureOnePipelineRunCreated(resourceKey)
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: UnknownApplicationConditionType, Status: metav1.ConditionTrue, Reason: UnknownApplicationReasonNotFound}))
+		})
+
+		It("should not build component of missing Application with Block policy", func() {
+			restartComponentBuildReconciler(func(r *ComponentBuildReconciler) {
+				r.UnknownApplicationPolicy = UnknownApplicationPolicyBlock
+			})
+			setComponentDevfileModel(resourceKey)
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: UnknownApplicationConditionType, Status: metav1.ConditionTrue}))
+			ensureNoPipelineRunsCreated(resourceKey)
+		})
+
+		It("should build blocked component once its Application is created", func() {
+			restartComponentBuildReconciler(func(r *ComponentBuildReconciler) {
+				r.UnknownApplicationPolicy = UnknownApplicationPolicyBlock
+			})
+			setComponentDevfileModel(resourceKey)
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: UnknownApplicationConditionType, Status: metav1.ConditionTrue}))
+			ensureNoPipelineRunsCreated(resourceKey)
+
+			Expect(k8sClient.Create(ctx, &appstudiov1alpha1.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: applicationKey.Name, Namespace: applicationKey.Namespace},
+				Spec:       appstudiov1alpha1.ApplicationSpec{DisplayName: applicationKey.Name},
+			})).Should(Succeed())
+
+			ensureOnePipelineRunCreated(resourceKey)
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: UnknownApplicationConditionType, Status: metav1.ConditionFalse}))
+		})
+
+		It("should build component of existing Application with Block policy", func() {
+			restartComponentBuildReconciler(func(r *ComponentBuildReconciler) {
+				r.UnknownApplicationPolicy = UnknownApplicationPolicyBlock
+			})
+			Expect(k8sClient.Create(ctx, &appstudiov1alpha1.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: applicationKey.Name, Namespace: applicationKey.Namespace},
+				Spec:       appstudiov1alpha1.ApplicationSpec{DisplayName: applicationKey.Name},
+			})).Should(Succeed())
+			setComponentDevfileModel(resourceKey)
+
+			ensureOnePipelineRunCreated(resourceKey)
+			Expect(getComponent(resourceKey).Status.Conditions).NotTo(HaveComponentCondition(metav1.Condition{Type: UnknownApplicationConditionType}))
+		})
+	})
+
+	Context("Test workspace PVCs cleanup of expired PipelineRuns", func() {
+
+		sharedPVCKey := types.NamespacedName{Name: "shared-workspace", Namespace: HASAppNamespace}
+
+		createPVC := func(name string, owner *tektonapi.PipelineRun) {
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: HASAppNamespace},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+					},
+				},
+			}
+			if owner != nil {
+				pvc.OwnerReferences = []metav1.OwnerReference{{
+					APIVersion: "tekton.dev/v1beta1",
+					Kind:       "PipelineRun",
+					Name:       owner.Name,
+					UID:        owner.UID,
+				}}
+			}
+			Expect(k8sClient.Create(ctx, pvc)).Should(Succeed())
+		}
+
+		// envtest runs no garbage collector, so the PVCs are checked for the owner reference which makes them collected
+		isPVCOwnedBy := func(name string, pipelineRun *tektonapi.PipelineRun) bool {
+			pvc := &corev1.PersistentVolumeClaim{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: HASAppNamespace}, pvc)).Should(Succeed())
+			return isPipelineRunWorkspacePVC(*pvc, *pipelineRun)
+		}
+
+		createExpiredBuild := func(workspacePVCName string) *tektonapi.PipelineRun {
+			pipelineRun := &tektonapi.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: HASCompName + "-",
+					Namespace:    HASAppNamespace,
+					Labels:       map[string]string{ComponentNameLabelName: HASCompName},
+				},
+				Spec: tektonapi.PipelineRunSpec{
+					PipelineRef: &tektonapi.PipelineRef{Name: "noop"},
+					Workspaces: []tektonapi.WorkspaceBinding{
+						{Name: "workspace", PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: sharedPVCKey.Name}},
+						{Name: "cache", VolumeClaimTemplate: &corev1.PersistentVolumeClaim{}},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pipelineRun)).Should(Succeed())
+			// Only completed builds are deleted, the build fails as the latest successful build of the component is kept
+			completionTime := metav1.Now()
+			pipelineRun.Status.StartTime = &completionTime
+			pipelineRun.Status.CompletionTime = &completionTime
+			pipelineRun.Status.SetCondition(&apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionFalse,
+				Reason: "Failed",
+			})
+			Expect(k8sClient.Status().Update(ctx, pipelineRun)).Should(Succeed())
+			createPVC(workspacePVCName, pipelineRun)
+			return pipelineRun
+		}
+
+		cleanup := func(keepWorkspacePVCs bool) {
+			cleaner := &PipelineRunRetentionCleaner{
+				Client:            k8sClient,
+				Log:               ctrl.Log.WithName("PipelineRunRetentionCleaner"),
+				Retention:         DefaultPipelineRunRetention,
+				KeepWorkspacePVCs: keepWorkspacePVCs,
+			}
+			Eventually(func() bool {
+				Expect(cleaner.deleteExpiredPipelineRuns(ctx, time.Now().Add(DefaultPipelineRunRetention+time.Hour))).Should(Succeed())
+				return len(listComponentPipelienRuns(resourceKey).Items) == 0
+			}, timeout, interval).Should(BeTrue())
+		}
+
+		_ = BeforeEach(func() {
+			createComponent(resourceKey)
+			createPVC(sharedPVCKey.Name, nil)
+		}, 30)
+
+		_ = AfterEach(func() {
+			for _, name := range []string{sharedPVCKey.Name, "pvc-expired", "pvc-kept", "pvc-component-kept"} {
+				pvc := &corev1.PersistentVolumeClaim{}
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: HASAppNamespace}, pvc); err != nil {
+					continue
+				}
+				pvc.Finalizers = nil
+				Expect(k8sClient.Update(ctx, pvc)).Should(Succeed())
+				Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, pvc))).Should(Succeed())
+			}
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should leave workspace PVCs of expired PipelineRuns to the garbage collector", func() {
+			pipelineRun := createExpiredBuild("pvc-expired")
+
+			cleanup(false)
+			Expect(isPVCOwnedBy("pvc-expired", pipelineRun)).To(BeTrue())
+			Expect(isPVCOwnedBy(sharedPVCKey.Name, pipelineRun)).To(BeFalse())
+		})
+
+		It("should keep workspace PVCs if the cleanup keeps them", func() {
+			pipelineRun := createExpiredBuild("pvc-kept")
+
+			cleanup(true)
+			Expect(isPVCOwnedBy("pvc-kept", pipelineRun)).To(BeFalse())
+		})
+
+		It("should keep workspace PVCs of components which keep them", func() {
+			component := getComponent(resourceKey)
+			component.Annotations = map[string]string{KeepWorkspacePVCsAnnotationName: "true"}
+			Expect(k8sClient.Update(ctx, component)).Should(Succeed())
+			pipelineRun := createExpiredBuild("pvc-component-kept")
+
+			cleanup(false)
+			Expect(isPVCOwnedBy("pvc-component-kept", pipelineRun)).To(BeFalse())
+		})
+	})
+
+	Context("Test retry classification of failed builds", func() {
+
+		_ = BeforeEach(func() {
+			createComponent(resourceKey)
+		}, 30)
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			deleteComponent(resourceKey)
+		}, 30)
+
+		failPipelineRun := func(pipelineRun *tektonapi.PipelineRun, message string) {
+			pipelineRun.Status.SetCondition(&apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionFalse,
+				Reason: "Failed",
+			})
+			pipelineRun.Status.TaskRuns = map[string]*tektonapi.PipelineRunTaskRunStatus{
+				pipelineRun.Name + "-build-container": {
+					PipelineTaskName: "build-container",
+					Status: &tektonapi.TaskRunStatus{
+						Status: duckv1beta1.Status{
+							Conditions: duckv1beta1.Conditions{
+								{
+									Type:    apis.ConditionSucceeded,
+									Status:  corev1.ConditionFalse,
+									Reason:  "Failed",
+									Message: message,
+								},
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, pipelineRun)).Should(Succeed())
+		}
+
+		It("should resubmit build failed because of a network failure", func() {
+			setComponentDevfileModel(resourceKey)
+			ensureOnePipelineRunCreated(resourceKey)
+
+			pipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+			failPipelineRun(&pipelineRun, "Error: writing blob: dial tcp 10.0.0.1:443: i/o timeout")
+
+			Eventually(func() bool {
+				return len(listComponentPipelienRuns(resourceKey).Items) == 2
+			}, timeout, interval).Should(BeTrue())
+			Expect(getComponent(resourceKey).Annotations[DisruptionRetriesAnnotationName]).To(Equal("1"))
+		})
+
+		It("should stop retrying when the retries are used up", func() {
+			setComponentDevfileModel(resourceKey)
+			ensureOnePipelineRunCreated(resourceKey)
+
+			for retry := 1; retry <= maxDisruptionRetries; retry++ {
+				for _, pipelineRun := range listComponentPipelienRuns(resourceKey).Items {
+					if pipelineRun.Status.GetCondition(apis.ConditionSucceeded) == nil {
+						failPipelineRun(&pipelineRun, "Error: writing blob: dial tcp 10.0.0.1:443: i/o timeout")
+					}
+				}
+				Eventually(func() bool {
+					return len(listComponentPipelienRuns(resourceKey).Items) == retry+1
+				}, timeout, interval).Should(BeTrue())
+				Expect(getComponent(resourceKey).Annotations[DisruptionRetriesAnnotationName]).To(Equal(strconv.Itoa(retry)))
+			}
+
+			// The failure of the last retry is terminal
+			for _, pipelineRun := range listComponentPipelienRuns(resourceKey).Items {
+				if pipelineRun.Status.GetCondition(apis.ConditionSucceeded) == nil {
+					failPipelineRun(&pipelineRun, "Error: writing blob: dial tcp 10.0.0.1:443: i/o timeout")
+				}
+			}
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: BuildFailureTerminalConditionType, Status: metav1.ConditionTrue, Reason: BuildFailureTerminalReasonRetriesExceeded}))
+
+			Consistently(func() bool {
+				return len(listComponentPipelienRuns(resourceKey).Items) == maxDisruptionRetries+1
+			}, 5*time.Second, interval).Should(BeTrue())
+			Expect(getComponent(resourceKey).Annotations[DisruptionRetriesAnnotationName]).To(Equal(strconv.Itoa(maxDisruptionRetries)))
+		})
+
+		It("should not resubmit build failed because of a compile error", func() {
+			setComponentDevfileModel(resourceKey)
+			ensureOnePipelineRunCreated(resourceKey)
+
+			pipelineRun := listComponentPipelienRuns(resourceKey).Items[0]
+			failPipelineRun(&pipelineRun, `"step-build" exited with code 1`)
+
+			Eventually(getComponentConditions(resourceKey), timeout, interval).Should(HaveComponentCondition(metav1.Condition{Type: BuildFailureTerminalConditionType, Status: metav1.ConditionTrue}))
+			Expect(getComponent(resourceKey).Status.Conditions).To(HaveComponentCondition(metav1.Condition{Type: BuildFailureTerminalConditionType, Reason: BuildFailureTerminalReasonNonRetryable}))
+
+			Consistently(func() bool {
+				return len(listComponentPipelienRuns(resourceKey).Items) == 1
+			}, 5*time.Second, interval).Should(BeTrue())
+			Expect(getComponent(resourceKey).Annotations[DisruptionRetriesAnnotationName]).To(BeEmpty())
+		})
+	})
+
+	Context("Test pull request trigger", func() {
+
+		_ = AfterEach(func() {
+			deleteComponentPipelienRuns(resourceKey)
+			// There is no garbage collector in the test environment
+			Expect(k8sClient.DeleteAllOf(ctx, &triggersapi.EventListener{}, client.InNamespace(HASAppNamespace))).Should(Succeed())
+			Expect(k8sClient.DeleteAllOf(ctx, &triggersapi.TriggerTemplate{}, client.InNamespace(HASAppNamespace))).Should(Succeed())
+			deleteComponent(resourceKey)
+		}, 30)
+
+		It("should create push and pull request triggers if the pull request pipeline is set", func() {
+			component := &appstudiov1alpha1.Component{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "appstudio.redhat.com/v1alpha1",
+					Kind:       "Component",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      HASCompName,
+					Namespace: HASAppNamespace,
+					Annotations: map[string]string{
+						CreateEventListenerAnnotationName: "true",
+						PRPipelineNameAnnotationName:      "docker-test",
+					},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: HASCompName,
+					Application:   HASAppName,
+					Source: appstudiov1alpha1.ComponentSource{
+						ComponentSourceUnion: appstudiov1alpha1.ComponentSourceUnion{
+							GitSource: &appstudiov1alpha1.GitSource{
+								URL: SampleRepoLink,
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, component)).Should(Succeed())
+
+			setComponentDevfileModel(resourceKey)
+
+			ensureOnePipelineRunCreated(resourceKey)
+
+			pullRequestTriggerTemplateKey := types.NamespacedName{Name: HASCompName + PRTriggerTemplateSuffix, Namespace: HASAppNamespace}
+			pullRequestTriggerTemplate := &triggersapi.TriggerTemplate{}
+			Expect(k8sClient.Get(ctx, pullRequestTriggerTemplateKey, pullRequestTriggerTemplate)).Should(Succeed())
+			Expect(isOwnedBy(pullRequestTriggerTemplate.OwnerReferences, *getComponent(resourceKey))).To(BeTrue())
+			triggeredPipelineRun := tektonapi.PipelineRun{}
+			Expect(json.Unmarshal(pullRequestTriggerTemplate.Spec.ResourceTemplates[0].Raw, &triggeredPipelineRun)).Should(Succeed())
+			Expect(triggeredPipelineRun.Spec.PipelineRef.Name).To(Equal("docker-test"))
+
+			eventListener := &triggersapi.EventListener{}
+			Expect(k8sClient.Get(ctx, resourceKey, eventListener)).Should(Succeed())
+			Expect(eventListener.Spec.Triggers).To(HaveLen(2))
+			Expect(eventListener.Spec.Triggers[0].Bindings[0].Ref).To(Equal(githubPushBinding))
+			Expect(*eventListener.Spec.Triggers[0].Template.Ref).To(Equal(HASCompName))
+			Expect(eventListener.Spec.Triggers[1].Bindings[0].Ref).To(Equal(githubPullRequestBinding))
+			Expect(*eventListener.Spec.Triggers[1].Template.Ref).To(Equal(pullRequestTriggerTemplateKey.Name))
+		})
+	})
 })
@@ -0,0 +1,104 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Build phases of components reported in the component build phase metric
+const (
+	componentBuildPhaseWaitingForDevfile    = "waiting_for_devfile"
+	componentBuildPhaseWaitingForGitOpsSync = "waiting_for_gitops_sync"
+	componentBuildPhaseReady                = "ready"
+)
+
+var componentBuildPhases = []string{
+	componentBuildPhaseWaitingForDevfile,
+	componentBuildPhaseWaitingForGitOpsSync,
+	componentBuildPhaseReady,
+}
+
+var componentsByBuildPhase = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "build_service_components",
+		Help: "Number of components by build phase as of their last reconcile.",
+	},
+	[]string{"phase"},
+)
+
+// componentPhaseTracker remembers the build phase of each reconciled component and keeps
+// the number of components in each phase in the gauge.
+// All components are reconciled on the informer initial list, so the gauge is complete shortly after startup.
+type componentPhaseTracker struct {
+	gauge  *prometheus.GaugeVec
+	mutex  sync.Mutex
+	phases map[types.NamespacedName]string
+}
+
+var componentPhases = newComponentPhaseTracker(componentsByBuildPhase)
+
+func newComponentPhaseTracker(gauge *prometheus.GaugeVec) *componentPhaseTracker {
+	// Report all phases, also the empty ones
+	for _, phase := range componentBuildPhases {
+		gauge.WithLabelValues(phase).Set(0)
+	}
+	return &componentPhaseTracker{
+		gauge:  gauge,
+		phases: map[types.NamespacedName]string{},
+	}
+}
+
+// Set records the current build phase of the component, empty phase stops counting the component.
+func (t *componentPhaseTracker) Set(componentKey types.NamespacedName, phase string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	previousPhase, tracked := t.phases[componentKey]
+	if tracked && previousPhase == phase {
+		return
+	}
+	if tracked {
+		t.gauge.WithLabelValues(previousPhase).Dec()
+		delete(t.phases, componentKey)
+	}
+	if phase != "" {
+		t.gauge.WithLabelValues(phase).Inc()
+		t.phases[componentKey] = phase
+	}
+}
+
+// Delete stops counting the deleted component.
+func (t *componentPhaseTracker) Delete(componentKey types.NamespacedName) {
+	t.Set(componentKey, "")
+}
+
+// getComponentBuildPhase returns the build phase of the component according to the initial build decision.
+// Components which are not built, e.g. container image components, have no build phase.
+func getComponentBuildPhase(decision InitialBuildDecision) string {
+	switch decision.Reason {
+	case BuildDecisionReasonWaitingForDevfile:
+		return componentBuildPhaseWaitingForDevfile
+	case BuildDecisionReasonRequired, BuildDecisionReasonAlreadySubmitted:
+		return componentBuildPhaseReady
+	default:
+		return ""
+	}
+}
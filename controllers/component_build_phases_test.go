@@ -0,0 +1,85 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func getComponentPhaseGaugeValues(t *testing.T, gauge *prometheus.GaugeVec) map[string]float64 {
+	values := map[string]float64{}
+	for _, phase := range componentBuildPhases {
+		metric := &dto.Metric{}
+		if err := gauge.WithLabelValues(phase).Write(metric); err != nil {
+			t.Fatalf("failed to read component build phase metric: %v", err)
+		}
+		values[phase] = metric.GetGauge().GetValue()
+	}
+	return values
+}
+
+func TestComponentPhaseTracker(t *testing.T) {
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_components"}, []string{"phase"})
+	tracker := newComponentPhaseTracker(gauge)
+
+	waitingForDevfile := getGitSourceComponent(nil, "")
+	ready := getGitSourceComponent(nil, "version: 2.2.0")
+	built := getGitSourceComponent(map[string]string{InitialBuildAnnotationName: "processed"}, "version: 2.2.0")
+	containerImage := getGitSourceComponent(nil, "version: 2.2.0")
+	containerImage.Spec.Source.GitSource = nil
+
+	key := func(name string) types.NamespacedName {
+		return types.NamespacedName{Name: name, Namespace: "my-namespace"}
+	}
+	tracker.Set(key("waiting-for-devfile"), getComponentBuildPhase(getInitialBuildDecision(waitingForDevfile)))
+	tracker.Set(key("ready"), getComponentBuildPhase(getInitialBuildDecision(ready)))
+	tracker.Set(key("built"), getComponentBuildPhase(getInitialBuildDecision(built)))
+	tracker.Set(key("container-image"), getComponentBuildPhase(getInitialBuildDecision(containerImage)))
+	tracker.Set(key("waiting-for-sync"), getComponentBuildPhase(getInitialBuildDecision(ready)))
+	tracker.Set(key("waiting-for-sync"), componentBuildPhaseWaitingForGitOpsSync)
+	// Repeated reconciles of a component do not change the counts
+	tracker.Set(key("ready"), componentBuildPhaseReady)
+
+	want := map[string]float64{
+		componentBuildPhaseWaitingForDevfile:    1,
+		componentBuildPhaseWaitingForGitOpsSync: 1,
+		componentBuildPhaseReady:                2,
+	}
+	if got := getComponentPhaseGaugeValues(t, gauge); !reflect.DeepEqual(got, want) {
+		t.Errorf("component build phases = %v, want %v", got, want)
+	}
+
+	// The devfile model is set and the component is synced and built
+	tracker.Set(key("waiting-for-devfile"), componentBuildPhaseReady)
+	tracker.Set(key("waiting-for-sync"), componentBuildPhaseReady)
+	tracker.Delete(key("built"))
+	tracker.Delete(key("never-reconciled"))
+
+	want = map[string]float64{
+		componentBuildPhaseWaitingForDevfile:    0,
+		componentBuildPhaseWaitingForGitOpsSync: 0,
+		componentBuildPhaseReady:                3,
+	}
+	if got := getComponentPhaseGaugeValues(t, gauge); !reflect.DeepEqual(got, want) {
+		t.Errorf("component build phases = %v, want %v", got, want)
+	}
+}
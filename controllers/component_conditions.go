@@ -0,0 +1,53 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+//+kubebuilder:rbac:groups=appstudio.redhat.com,resources=components/status,verbs=update;patch
+
+// setComponentCondition sets the given condition in the status of the latest version of the component.
+// The status is not updated if the condition hasn't changed.
+// On conflict with a concurrent update of the component, the condition is set again to the newly read version,
+// so only the condition is written and concurrent changes are kept.
+func setComponentCondition(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component, condition metav1.Condition) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latestComponent := &appstudiov1alpha1.Component{}
+		if err := cli.Get(ctx, types.NamespacedName{Name: component.Name, Namespace: component.Namespace}, latestComponent); err != nil {
+			return err
+		}
+
+		currentCondition := meta.FindStatusCondition(latestComponent.Status.Conditions, condition.Type)
+		if currentCondition != nil && currentCondition.Status == condition.Status &&
+			currentCondition.Reason == condition.Reason && currentCondition.Message == condition.Message {
+			return nil
+		}
+
+		meta.SetStatusCondition(&latestComponent.Status.Conditions, condition)
+		return cli.Status().Update(ctx, latestComponent)
+	})
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gstruct"
+	"github.com/onsi/gomega/types"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+// AssertComponentConditions asserts the component has each of the expected conditions.
+// Statuses, reasons and messages are compared only if set in the expected condition, other conditions of the component are ignored.
+func AssertComponentConditions(t *testing.T, component *appstudiov1alpha1.Component, expected []metav1.Condition) {
+	t.Helper()
+	g := NewWithT(t)
+	for _, condition := range expected {
+		g.Expect(component.Status.Conditions).To(HaveComponentCondition(condition),
+			"component %s has no matching %s condition", component.Name, condition.Type)
+	}
+}
+
+// HaveComponentCondition succeeds if the conditions contain a condition of the expected type.
+// Status, reason and message are compared only if set in the expected condition.
+// Envtest specs poll the conditions with Eventually, e.g. Eventually(getComponentConditions(key)).Should(HaveComponentCondition(c)).
+func HaveComponentCondition(expected metav1.Condition) types.GomegaMatcher {
+	fields := gstruct.Fields{
+		"Type": Equal(expected.Type),
+	}
+	if expected.Status != "" {
+		fields["Status"] = Equal(expected.Status)
+	}
+	if expected.Reason != "" {
+		fields["Reason"] = Equal(expected.Reason)
+	}
+	if expected.Message != "" {
+		fields["Message"] = Equal(expected.Message)
+	}
+	return ContainElement(gstruct.MatchFields(gstruct.IgnoreExtras, fields))
+}
+
+// HaveComponentConditionMessage succeeds if the conditions contain a condition of the given type
+// whose message matches, e.g. ContainSubstring.
+func HaveComponentConditionMessage(conditionType string, message types.GomegaMatcher) types.GomegaMatcher {
+	return ContainElement(gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
+		"Type":    Equal(conditionType),
+		"Message": message,
+	}))
+}
+
+// conflictingStatusClient simulates a concurrent update of the component before each of the first conflicts status updates
+type conflictingStatusClient struct {
+	client.Client
+	component appstudiov1alpha1.Component
+	conflicts int
+	updated   []appstudiov1alpha1.Component
+}
+
+func (c *conflictingStatusClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	c.component.DeepCopyInto(obj.(*appstudiov1alpha1.Component))
+	return nil
+}
+
+func (c *conflictingStatusClient) Status() client.StatusWriter {
+	return &conflictingStatusWriter{client: c}
+}
+
+type conflictingStatusWriter struct {
+	client.StatusWriter
+	client *conflictingStatusClient
+}
+
+func (w *conflictingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	component := obj.(*appstudiov1alpha1.Component)
+	if w.client.conflicts > 0 {
+		w.client.conflicts--
+		// Concurrent edit of the component spec
+		w.client.component.Spec.Source.GitSource.URL += "-edited"
+		return errors.NewConflict(schema.GroupResource{Resource: "components"}, component.Name, nil)
+	}
+	w.client.updated = append(w.client.updated, *component.DeepCopy())
+	return nil
+}
+
+func TestSetComponentConditionRetriesOnConflict(t *testing.T) {
+	component := getGitSourceComponent(nil, "version: 2.2.0")
+	cli := &conflictingStatusClient{component: *component.DeepCopy(), conflicts: 2}
+	condition := metav1.Condition{Type: "Test", Status: metav1.ConditionTrue, Reason: "Testing", Message: "test"}
+
+	if err := setComponentCondition(context.TODO(), cli, component, condition); err != nil {
+		t.Fatalf("setComponentCondition() error = %v", err)
+	}
+	if len(cli.updated) != 1 {
+		t.Fatalf("setComponentCondition() updated status %d times, want 1", len(cli.updated))
+	}
+	updated := cli.updated[0]
+	AssertComponentConditions(t, &updated, []metav1.Condition{condition})
+	// The concurrent edits must not be reverted
+	if want := "https://github.com/foo/bar-edited-edited"; updated.Spec.Source.GitSource.URL != want {
+		t.Errorf("setComponentCondition() updated component with git URL %v, want %v", updated.Spec.Source.GitSource.URL, want)
+	}
+}
+
+func TestSetComponentConditionUnchanged(t *testing.T) {
+	condition := metav1.Condition{Type: "Test", Status: metav1.ConditionTrue, Reason: "Testing", Message: "test"}
+	component := getGitSourceComponent(nil, "version: 2.2.0")
+	meta.SetStatusCondition(&component.Status.Conditions, condition)
+	cli := &conflictingStatusClient{component: component}
+
+	if err := setComponentCondition(context.TODO(), cli, component, condition); err != nil {
+		t.Fatalf("setComponentCondition() error = %v", err)
+	}
+	if len(cli.updated) != 0 {
+		t.Errorf("setComponentCondition() updated status of component with unchanged condition")
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+// ComponentDeletionWebhookPath is the path the Component deletion validating webhook is served at
+const ComponentDeletionWebhookPath = "/validate-appstudio-redhat-com-v1alpha1-component-deletion"
+
+//+kubebuilder:webhook:path=/validate-appstudio-redhat-com-v1alpha1-component-deletion,mutating=false,failurePolicy=ignore,sideEffects=None,groups=appstudio.redhat.com,resources=components,verbs=delete,versions=v1alpha1,name=vcomponentdeletion.build.appstudio.redhat.com,admissionReviewVersions=v1
+
+// ComponentDeletionValidator rejects deletion of Components whose builds are in progress,
+// so the build PipelineRuns are not left without their Component.
+type ComponentDeletionValidator struct {
+	// Client must have the component PipelineRun index registered by the Component build reconciler
+	Client  client.Client
+	decoder *admission.Decoder
+}
+
+// InjectDecoder is called by the webhook server to set the decoder of the admission requests.
+func (v *ComponentDeletionValidator) InjectDecoder(decoder *admission.Decoder) error {
+	v.decoder = decoder
+	return nil
+}
+
+// Handle rejects the Component DELETE request if any build PipelineRun of the Component has not completed.
+func (v *ComponentDeletionValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Delete {
+		return admission.Allowed("")
+	}
+
+	component := appstudiov1alpha1.Component{}
+	if len(req.OldObject.Raw) > 0 {
+		if err := v.decoder.DecodeRaw(req.OldObject, &component); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	} else if err := v.Client.Get(ctx, types.NamespacedName{Name: req.Name, Namespace: req.Namespace}, &component); err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	activeBuilds, err := getActiveComponentBuilds(ctx, v.Client, component)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if len(activeBuilds) > 0 {
+		return admission.Denied(fmt.Sprintf("Component %s has builds in progress, wait for them to complete or cancel them before deleting the Component: %s",
+			component.Name, strings.Join(activeBuilds, ", ")))
+	}
+	return admission.Allowed("")
+}
+
+// getActiveComponentBuilds returns sorted names of the pending and running build PipelineRuns of the component.
+func getActiveComponentBuilds(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) ([]string, error) {
+	pipelineRuns, err := listComponentPipelineRuns(ctx, cli, component)
+	if err != nil {
+		return nil, err
+	}
+
+	var activeBuilds []string
+	for _, pipelineRun := range pipelineRuns {
+		switch getBuildState(pipelineRun) {
+		case BuildStatePending, BuildStateRunning:
+			activeBuilds = append(activeBuilds, pipelineRun.Name)
+		}
+	}
+	sort.Strings(activeBuilds)
+	return activeBuilds, nil
+}
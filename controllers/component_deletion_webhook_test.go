@@ -0,0 +1,115 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+// pipelineRunListClient lists the given PipelineRuns regardless of the list options
+type pipelineRunListClient struct {
+	client.Client
+	pipelineRuns []tektonapi.PipelineRun
+}
+
+func (c *pipelineRunListClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	list.(*tektonapi.PipelineRunList).Items = c.pipelineRuns
+	return nil
+}
+
+func getComponentDeletionRequest(t *testing.T, component appstudiov1alpha1.Component) admission.Request {
+	componentJSON, err := json.Marshal(component)
+	if err != nil {
+		t.Fatalf("failed to marshal component: %v", err)
+	}
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Delete,
+		Name:      component.Name,
+		Namespace: component.Namespace,
+		OldObject: runtime.RawExtension{Raw: componentJSON},
+	}}
+}
+
+func TestComponentDeletionValidator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := appstudiov1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to set up scheme: %v", err)
+	}
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatalf("failed to create decoder: %v", err)
+	}
+
+	runningPipelineRun := getPipelineRunWithSucceededCondition(corev1.ConditionUnknown)
+	runningPipelineRun.Name = "my-component-b"
+	pendingPipelineRun := tektonapi.PipelineRun{}
+	pendingPipelineRun.Name = "my-component-a"
+	succeededPipelineRun := getPipelineRunWithSucceededCondition(corev1.ConditionTrue)
+	succeededPipelineRun.Name = "my-component-c"
+
+	tests := []struct {
+		name         string
+		pipelineRuns []tektonapi.PipelineRun
+		wantAllowed  bool
+		wantMessage  string
+	}{
+		{
+			name:         "no builds",
+			pipelineRuns: nil,
+			wantAllowed:  true,
+		},
+		{
+			name:         "completed builds",
+			pipelineRuns: []tektonapi.PipelineRun{succeededPipelineRun},
+			wantAllowed:  true,
+		},
+		{
+			name:         "builds in progress",
+			pipelineRuns: []tektonapi.PipelineRun{runningPipelineRun, succeededPipelineRun, pendingPipelineRun},
+			wantAllowed:  false,
+			wantMessage:  "my-component-a, my-component-b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := &ComponentDeletionValidator{Client: &pipelineRunListClient{pipelineRuns: tt.pipelineRuns}}
+			if err := validator.InjectDecoder(decoder); err != nil {
+				t.Fatalf("InjectDecoder() error = %v", err)
+			}
+
+			response := validator.Handle(context.TODO(), getComponentDeletionRequest(t, getGitSourceComponent(nil, "")))
+			if response.Allowed != tt.wantAllowed {
+				t.Errorf("Handle() allowed = %v, want %v: %v", response.Allowed, tt.wantAllowed, response.Result)
+			}
+			if tt.wantMessage != "" && !strings.HasSuffix(string(response.Result.Reason), tt.wantMessage) {
+				t.Errorf("Handle() reason = %v, want the active builds %v", response.Result.Reason, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// BuildResubmissionFinalizer is set on build PipelineRuns which are resubmitted if deleted before completion
+	BuildResubmissionFinalizer = "build.appstudio.openshift.io/resubmit-on-deletion"
+	// Number of builds resubmitted because the previous build PipelineRun was deleted before completion
+	DeletedBuildResubmissionsAnnotationName = "build.appstudio.openshift.io/deleted-build-resubmissions"
+
+	maxDeletedBuildResubmissions = 3
+)
+
+// isPrematureBuildDeletion checks whether the build PipelineRun has been deleted before completion.
+// Cancelled builds are deleted intentionally, as well as completed builds which are pruned.
+func isPrematureBuildDeletion(pipelineRun tektonapi.PipelineRun) bool {
+	switch pipelineRun.Spec.Status {
+	case tektonapi.PipelineRunSpecStatusCancelled, tektonapi.PipelineRunSpecStatusCancelledDeprecated,
+		tektonapi.PipelineRunSpecStatusCancelledRunFinally, tektonapi.PipelineRunSpecStatusStoppedRunFinally:
+		return false
+	}
+	switch getBuildState(pipelineRun) {
+	case BuildStatePending, BuildStateRunning:
+		return true
+	default:
+		return false
+	}
+}
+
+// handleBuildDeletion resubmits the build of the component if the deleted PipelineRun is its latest build
+// which has not completed, and releases the PipelineRun.
+func (r *BuildPipelineRunReconciler) handleBuildDeletion(ctx context.Context, pipelineRun tektonapi.PipelineRun) error {
+	if !controllerutil.ContainsFinalizer(&pipelineRun, BuildResubmissionFinalizer) {
+		return nil
+	}
+
+	if isPrematureBuildDeletion(pipelineRun) {
+		if err := r.resubmitDeletedBuild(ctx, pipelineRun); err != nil {
+			return err
+		}
+	}
+
+	controllerutil.RemoveFinalizer(&pipelineRun, BuildResubmissionFinalizer)
+	if err := r.Client.Update(ctx, &pipelineRun); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// resubmitDeletedBuild allows the component build controller to submit the build again.
+// The resubmission is recorded in the component annotation.
+func (r *BuildPipelineRunReconciler) resubmitDeletedBuild(ctx context.Context, pipelineRun tektonapi.PipelineRun) error {
+	component, found, err := getPipelineRunComponent(ctx, r.Client, pipelineRun, pipelineRun.Labels[ComponentNameLabelName])
+	if err != nil || !found {
+		// The PipelineRun is deleted together with its component or it doesn't build the component
+		return err
+	}
+	if !component.DeletionTimestamp.IsZero() || isStalePipelineRun(pipelineRun, component) {
+		return nil
+	}
+	log := r.Log.WithValues("Namespace", component.Namespace, "Component", component.Name)
+
+	resubmissions, _ := strconv.Atoi(component.Annotations[DeletedBuildResubmissionsAnnotationName])
+	if resubmissions >= maxDeletedBuildResubmissions {
+		log.Info(fmt.Sprintf("Build PipelineRun %s was deleted before completion, the build has been resubmitted %d times already", pipelineRun.Name, resubmissions))
+		return nil
+	}
+
+	latestPipelineRun, err := getLatestComponentPipelineRun(ctx, r.Client, component)
+	if err != nil {
+		return err
+	}
+	if latestPipelineRun != nil && latestPipelineRun.Name != pipelineRun.Name {
+		// A newer build has been submitted already
+		return nil
+	}
+
+	if component.Annotations == nil {
+		component.Annotations = make(map[string]string)
+	}
+	component.Annotations[InitialBuildAnnotationName] = "false"
+	component.Annotations[BuildTriggerAnnotationName] = BuildTriggerReasonDeletedBuild
+	component.Annotations[DeletedBuildResubmissionsAnnotationName] = strconv.Itoa(resubmissions + 1)
+	if err := r.Client.Update(ctx, &component); err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("Build PipelineRun %s was deleted before completion, resubmitting the build", pipelineRun.Name))
+	return nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsPrematureBuildDeletion(t *testing.T) {
+	runningBuild := tektonapi.PipelineRun{}
+	runningBuild.Status.StartTime = &metav1.Time{}
+
+	cancelledBuild := runningBuild
+	cancelledBuild.Spec.Status = tektonapi.PipelineRunSpecStatusCancelled
+
+	stoppedBuild := runningBuild
+	stoppedBuild.Spec.Status = tektonapi.PipelineRunSpecStatusStoppedRunFinally
+
+	tests := []struct {
+		name        string
+		pipelineRun tektonapi.PipelineRun
+		want        bool
+	}{
+		{
+			name:        "pending build",
+			pipelineRun: tektonapi.PipelineRun{},
+			want:        true,
+		},
+		{
+			name:        "running build",
+			pipelineRun: runningBuild,
+			want:        true,
+		},
+		{
+			name:        "succeeded build",
+			pipelineRun: getPipelineRunWithSucceededCondition(corev1.ConditionTrue),
+			want:        false,
+		},
+		{
+			name:        "failed build",
+			pipelineRun: getPipelineRunWithSucceededCondition(corev1.ConditionFalse),
+			want:        false,
+		},
+		{
+			name:        "cancelled build",
+			pipelineRun: cancelledBuild,
+			want:        false,
+		},
+		{
+			name:        "stopped build",
+			pipelineRun: stoppedBuild,
+			want:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPrematureBuildDeletion(tt.pipelineRun); got != tt.want {
+				t.Errorf("isPrematureBuildDeletion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// WaitingForDevfileConditionType is set on git source components whose devfile model has not been set by the Component controller yet
+	WaitingForDevfileConditionType = "WaitingForDevfile"
+
+	WaitingForDevfileReasonPending  = "DevfileModelPending"
+	WaitingForDevfileReasonTimedOut = "DevfileModelTimedOut"
+	WaitingForDevfileReasonReady    = "DevfileModelReady"
+
+	// Reason of the Warning event emitted when the devfile model of a component is not set in time
+	DevfileWaitTimedOutEventReason = "DevfileModelTimedOut"
+)
+
+// getWaitingForDevfileCondition returns the condition of the component which has been waiting for its devfile model
+// for the given time. The wait escalates to a warning after the timeout.
+func getWaitingForDevfileCondition(waited time.Duration, timeout time.Duration) metav1.Condition {
+	if waited >= timeout {
+		return metav1.Condition{
+			Type:   WaitingForDevfileConditionType,
+			Status: metav1.ConditionTrue,
+			Reason: WaitingForDevfileReasonTimedOut,
+			Message: fmt.Sprintf("Devfile model has not been set by the Component controller within %s, "+
+				"check the Component controller for errors", timeout),
+		}
+	}
+	return metav1.Condition{
+		Type:    WaitingForDevfileConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  WaitingForDevfileReasonPending,
+		Message: "Waiting for the Component controller to set the devfile model",
+	}
+}
+
+// waitForDevfile reports the component waiting for its devfile model in the WaitingForDevfile condition.
+// The component is requeued when the wait times out and then periodically if the recheck interval is set,
+// so components whose devfile model never comes do not get stuck silently.
+// The devfile model set later triggers a new reconcile regardless of the requeues.
+func (r *ComponentBuildReconciler) waitForDevfile(ctx context.Context, log logr.Logger, component appstudiov1alpha1.Component) ctrl.Result {
+	if r.DevfileWaitTimeout <= 0 {
+		// Do not requeue as after model update a new update event will trigger a new reconcile
+		return ctrl.Result{}
+	}
+
+	waited := time.Since(component.CreationTimestamp.Time)
+	condition := getWaitingForDevfileCondition(waited, r.DevfileWaitTimeout)
+	currentCondition := meta.FindStatusCondition(component.Status.Conditions, WaitingForDevfileConditionType)
+	isNewlyTimedOut := condition.Reason == WaitingForDevfileReasonTimedOut &&
+		(currentCondition == nil || currentCondition.Reason != WaitingForDevfileReasonTimedOut)
+
+	if err := setComponentCondition(ctx, r.Client, component, condition); err != nil {
+		log.Error(err, fmt.Sprintf("Unable to set %s condition for component %s", condition.Type, component.Name))
+	}
+
+	if condition.Reason == WaitingForDevfileReasonPending {
+		// Check the devfile model again when the wait times out
+		return ctrl.Result{RequeueAfter: r.DevfileWaitTimeout - waited}
+	}
+
+	if isNewlyTimedOut {
+		log.Info(fmt.Sprintf("Devfile model of component %s has not been set within %s", component.Name, r.DevfileWaitTimeout))
+		if r.Recorder != nil {
+			r.Recorder.Event(&component, corev1.EventTypeWarning, DevfileWaitTimedOutEventReason, condition.Message)
+		}
+	}
+	return ctrl.Result{RequeueAfter: r.DevfileRecheckInterval}
+}
+
+// clearWaitingForDevfileCondition marks the devfile model of the component which has been waited for as set.
+func clearWaitingForDevfileCondition(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) error {
+	if !meta.IsStatusConditionTrue(component.Status.Conditions, WaitingForDevfileConditionType) {
+		return nil
+	}
+	return setComponentCondition(ctx, cli, component, metav1.Condition{
+		Type:    WaitingForDevfileConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  WaitingForDevfileReasonReady,
+		Message: "Devfile model has been set",
+	})
+}
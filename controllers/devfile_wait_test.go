@@ -0,0 +1,130 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestGetWaitingForDevfileCondition(t *testing.T) {
+	tests := []struct {
+		name       string
+		waited     time.Duration
+		wantReason string
+	}{
+		{name: "just created", waited: 0, wantReason: WaitingForDevfileReasonPending},
+		{name: "within timeout", waited: 4 * time.Minute, wantReason: WaitingForDevfileReasonPending},
+		{name: "timed out", waited: 5 * time.Minute, wantReason: WaitingForDevfileReasonTimedOut},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getWaitingForDevfileCondition(tt.waited, 5*time.Minute)
+			if got.Type != WaitingForDevfileConditionType || got.Status != metav1.ConditionTrue || got.Reason != tt.wantReason {
+				t.Errorf("getWaitingForDevfileCondition() = %v, want reason %v", got, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestWaitForDevfile(t *testing.T) {
+	component := getGitSourceComponent(nil, "")
+	component.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Minute))
+	cli := &conflictingStatusClient{component: *component.DeepCopy()}
+	recorder := record.NewFakeRecorder(10)
+	r := &ComponentBuildReconciler{Client: cli, Recorder: recorder, DevfileWaitTimeout: 5 * time.Minute, DevfileRecheckInterval: time.Minute}
+
+	// The wait is bounded, the component is requeued when it times out
+	result := r.waitForDevfile(context.TODO(), logr.Discard(), component)
+	if result.RequeueAfter <= 3*time.Minute || result.RequeueAfter > 4*time.Minute {
+		t.Errorf("waitForDevfile() requeues after %v, want about 4m", result.RequeueAfter)
+	}
+	if len(cli.updated) != 1 {
+		t.Fatalf("waitForDevfile() updated status %d times, want 1", len(cli.updated))
+	}
+	AssertComponentConditions(t, &cli.updated[0], []metav1.Condition{
+		{Type: WaitingForDevfileConditionType, Status: metav1.ConditionTrue, Reason: WaitingForDevfileReasonPending},
+	})
+	if len(recorder.Events) != 0 {
+		t.Errorf("waitForDevfile() emitted an event before the timeout")
+	}
+
+	// The wait escalates to a warning after the timeout
+	component = cli.updated[0]
+	component.CreationTimestamp = metav1.NewTime(time.Now().Add(-10 * time.Minute))
+	cli.component = *component.DeepCopy()
+	result = r.waitForDevfile(context.TODO(), logr.Discard(), component)
+	if result.RequeueAfter != time.Minute {
+		t.Errorf("waitForDevfile() requeues after %v, want the recheck interval", result.RequeueAfter)
+	}
+	if len(cli.updated) != 2 {
+		t.Fatalf("waitForDevfile() updated status %d times, want 2", len(cli.updated))
+	}
+	AssertComponentConditions(t, &cli.updated[1], []metav1.Condition{
+		{Type: WaitingForDevfileConditionType, Status: metav1.ConditionTrue, Reason: WaitingForDevfileReasonTimedOut},
+	})
+	if len(recorder.Events) != 1 {
+		t.Fatalf("waitForDevfile() emitted %d events, want 1", len(recorder.Events))
+	}
+	if event := <-recorder.Events; !strings.HasPrefix(event, "Warning "+DevfileWaitTimedOutEventReason) {
+		t.Errorf("waitForDevfile() event = %v, want warning", event)
+	}
+
+	// The warning is not repeated on rechecks
+	component = cli.updated[1]
+	cli.component = *component.DeepCopy()
+	r.waitForDevfile(context.TODO(), logr.Discard(), component)
+	if len(cli.updated) != 2 || len(recorder.Events) != 0 {
+		t.Errorf("waitForDevfile() repeated the warning on recheck")
+	}
+
+	// The build resumes when the devfile model arrives
+	component.Status.Devfile = "version: 2.2.0"
+	cli.component = *component.DeepCopy()
+	if decision := getInitialBuildDecision(component); !decision.BuildRequired {
+		t.Errorf("getInitialBuildDecision() = %v, want build required", decision)
+	}
+	if err := clearWaitingForDevfileCondition(context.TODO(), cli, component); err != nil {
+		t.Fatalf("clearWaitingForDevfileCondition() error = %v", err)
+	}
+	if len(cli.updated) != 3 {
+		t.Fatalf("clearWaitingForDevfileCondition() updated status %d times, want 3", len(cli.updated))
+	}
+	AssertComponentConditions(t, &cli.updated[2], []metav1.Condition{
+		{Type: WaitingForDevfileConditionType, Status: metav1.ConditionFalse, Reason: WaitingForDevfileReasonReady},
+	})
+}
+
+func TestWaitForDevfileDisabled(t *testing.T) {
+	component := getGitSourceComponent(nil, "")
+	component.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	cli := &conflictingStatusClient{component: *component.DeepCopy()}
+	r := &ComponentBuildReconciler{Client: cli}
+
+	if result := r.waitForDevfile(context.TODO(), logr.Discard(), component); result.RequeueAfter != 0 || result.Requeue {
+		t.Errorf("waitForDevfile() = %v, want no requeue", result)
+	}
+	if len(cli.updated) != 0 {
+		t.Errorf("waitForDevfile() updated the component with disabled timeout")
+	}
+}
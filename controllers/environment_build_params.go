@@ -0,0 +1,191 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// BuildEnvironmentLabelName is the label of a namespace with the environment its components are built for, e.g. production
+	BuildEnvironmentLabelName = "build.appstudio.openshift.io/environment"
+	// BuildEnvironmentAnnotationName is the annotation of a component with the environment it is built for,
+	// it takes precedence over the namespace label. Changes of the environment trigger a new build.
+	BuildEnvironmentAnnotationName = "build.appstudio.openshift.io/environment"
+	// EnvironmentBuildParamsConfigMapSuffix is the suffix of the name of the ConfigMap with pipeline parameters of an environment,
+	// e.g. production-build-params
+	EnvironmentBuildParamsConfigMapSuffix = "-build-params"
+	// EnvironmentBuildProfileConfigMapSuffix is the suffix of the name of the ConfigMap with the build profile of an environment,
+	// e.g. production-build-profile
+	EnvironmentBuildProfileConfigMapSuffix = "-build-profile"
+	// EnvironmentBuildProfileConfigMapKey is the key of the environment build profile ConfigMap with the profile YAML
+	EnvironmentBuildProfileConfigMapKey = "profile.yaml"
+	// DefaultBuildEnvironment is the environment of components without the environment annotation in unlabeled namespaces
+	DefaultBuildEnvironment = "default"
+)
+
+// BuildEnvironmentProfile describes how components are built for an environment.
+type BuildEnvironmentProfile struct {
+	// Registry replaces the registry and the organization of the output image, e.g. quay.io/production-org
+	Registry string `json:"registry,omitempty"`
+	// Pipeline is the name of the build pipeline
+	Pipeline string `json:"pipeline,omitempty"`
+	// Params are pipeline parameters, they take precedence over the parameters in the environment build params ConfigMap
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// getBuildEnvironment returns the environment from the annotation of the component or the label of its namespace.
+func (r *ComponentBuildReconciler) getBuildEnvironment(ctx context.Context, component appstudiov1alpha1.Component) (string, error) {
+	if environment := component.Annotations[BuildEnvironmentAnnotationName]; environment != "" {
+		return environment, nil
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: component.Namespace}, namespace); err != nil {
+		return "", err
+	}
+	if environment := namespace.Labels[BuildEnvironmentLabelName]; environment != "" {
+		return environment, nil
+	}
+	return DefaultBuildEnvironment, nil
+}
+
+// getEnvironmentBuildParams returns the pipeline parameters of the environment of the component.
+// The parameters are read from the data of the environment ConfigMap, unknown environments have no parameters.
+func (r *ComponentBuildReconciler) getEnvironmentBuildParams(ctx context.Context, component appstudiov1alpha1.Component) ([]tektonapi.Param, error) {
+	if r.BuildEnvironmentsNamespace == "" {
+		return nil, nil
+	}
+
+	environment, err := r.getBuildEnvironment(ctx, component)
+	if err != nil {
+		return nil, err
+	}
+	data, err := r.getEnvironmentConfigMapData(ctx, environment, EnvironmentBuildParamsConfigMapSuffix)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil && environment != DefaultBuildEnvironment {
+		r.Log.Info(fmt.Sprintf("Unknown build environment %s of component %s in namespace %s, no ConfigMap %s%s",
+			environment, component.Name, component.Namespace, environment, EnvironmentBuildParamsConfigMapSuffix))
+	}
+	return getConfigMapPipelineParams(data), nil
+}
+
+// getEnvironmentBuildProfile returns the environment of the component and its build profile.
+// Nil profile means the environment has no build profile.
+func (r *ComponentBuildReconciler) getEnvironmentBuildProfile(ctx context.Context, component appstudiov1alpha1.Component) (string, *BuildEnvironmentProfile, error) {
+	if r.BuildEnvironmentsNamespace == "" {
+		return "", nil, nil
+	}
+
+	environment, err := r.getBuildEnvironment(ctx, component)
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := r.getEnvironmentConfigMapData(ctx, environment, EnvironmentBuildProfileConfigMapSuffix)
+	if err != nil || data == nil {
+		return environment, nil, err
+	}
+
+	profile := &BuildEnvironmentProfile{}
+	if err := yaml.UnmarshalStrict([]byte(data[EnvironmentBuildProfileConfigMapKey]), profile); err != nil {
+		return "", nil, fmt.Errorf("invalid build profile of environment %s: %v", environment, err)
+	}
+	return environment, profile, nil
+}
+
+// getEnvironmentConfigMapData returns data of the ConfigMap of the environment with the given suffix, nil if it does not exist.
+func (r *ComponentBuildReconciler) getEnvironmentConfigMapData(ctx context.Context, environment string, suffix string) (map[string]string, error) {
+	configMap := &corev1.ConfigMap{}
+	configMapName := types.NamespacedName{Name: environment + suffix, Namespace: r.BuildEnvironmentsNamespace}
+	if err := r.Client.Get(ctx, configMapName, configMap); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if configMap.Data == nil {
+		return map[string]string{}, nil
+	}
+	return configMap.Data, nil
+}
+
+// applyEnvironmentBuildProfile labels the build PipelineRun with the environment
+// and sets the registry, pipeline and parameters of the environment build profile.
+func applyEnvironmentBuildProfile(environment string, profile *BuildEnvironmentProfile, pipelineRun *tektonapi.PipelineRun) {
+	if environment == "" {
+		return
+	}
+	if pipelineRun.Labels == nil {
+		pipelineRun.Labels = map[string]string{}
+	}
+	pipelineRun.Labels[BuildEnvironmentLabelName] = environment
+
+	if profile == nil {
+		return
+	}
+
+	if profile.Registry != "" {
+		if outputImage := getPipelineRunParam(*pipelineRun, "output-image"); outputImage != "" {
+			mergePipelineParams(pipelineRun, []tektonapi.Param{
+				{Name: "output-image", Value: *tektonapi.NewArrayOrString(withImageRegistry(outputImage, profile.Registry))},
+			})
+		}
+	}
+	if profile.Pipeline != "" && pipelineRun.Spec.PipelineRef != nil {
+		pipelineRun.Spec.PipelineRef.Name = profile.Pipeline
+	}
+	mergePipelineParams(pipelineRun, getConfigMapPipelineParams(profile.Params))
+}
+
+// withImageRegistry replaces everything before the image name, e.g. the registry and the organization, with the given prefix.
+func withImageRegistry(image string, registry string) string {
+	return strings.TrimSuffix(registry, "/") + "/" + image[strings.LastIndex(image, "/")+1:]
+}
+
+// getConfigMapPipelineParams returns the ConfigMap data as string pipeline parameters sorted by name.
+func getConfigMapPipelineParams(data map[string]string) []tektonapi.Param {
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := make([]tektonapi.Param, 0, len(names))
+	for _, name := range names {
+		params = append(params, tektonapi.Param{
+			Name: name,
+			Value: tektonapi.ArrayOrString{
+				Type:      tektonapi.ParamTypeString,
+				StringVal: data[name],
+			},
+		})
+	}
+	return params
+}
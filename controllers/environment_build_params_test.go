@@ -0,0 +1,223 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// buildEnvironmentClient returns the component namespace with the given labels and ConfigMaps by their namespaced names
+type buildEnvironmentClient struct {
+	client.Client
+	namespaceLabels map[string]string
+	configMaps      map[client.ObjectKey]map[string]string
+}
+
+func (c *buildEnvironmentClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	switch obj := obj.(type) {
+	case *corev1.Namespace:
+		obj.Name = key.Name
+		obj.Labels = c.namespaceLabels
+	case *corev1.ConfigMap:
+		data, exists := c.configMaps[key]
+		if !exists {
+			return errors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, key.Name)
+		}
+		obj.Data = data
+	}
+	return nil
+}
+
+func TestGetEnvironmentBuildParams(t *testing.T) {
+	configMaps := map[client.ObjectKey]map[string]string{
+		{Name: "production-build-params", Namespace: "build-service"}: {"skip-checks": "false", "hermetic": "true"},
+	}
+	wantProductionParams := []tektonapi.Param{
+		{Name: "hermetic", Value: *tektonapi.NewArrayOrString("true")},
+		{Name: "skip-checks", Value: *tektonapi.NewArrayOrString("false")},
+	}
+
+	tests := []struct {
+		name            string
+		environmentsNS  string
+		namespaceLabels map[string]string
+		want            []tektonapi.Param
+	}{
+		{
+			name:            "disabled",
+			namespaceLabels: map[string]string{BuildEnvironmentLabelName: "production"},
+		},
+		{
+			name:           "namespace without environment",
+			environmentsNS: "build-service",
+		},
+		{
+			name:            "production environment",
+			environmentsNS:  "build-service",
+			namespaceLabels: map[string]string{BuildEnvironmentLabelName: "production"},
+			want:            wantProductionParams,
+		},
+		{
+			name:            "unknown environment",
+			environmentsNS:  "build-service",
+			namespaceLabels: map[string]string{BuildEnvironmentLabelName: "staging"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ComponentBuildReconciler{
+				Client:                     &buildEnvironmentClient{namespaceLabels: tt.namespaceLabels, configMaps: configMaps},
+				Log:                        logr.Discard(),
+				BuildEnvironmentsNamespace: tt.environmentsNS,
+			}
+			got, err := r.getEnvironmentBuildParams(context.TODO(), getGitSourceComponent(nil, "version: 2.2.0"))
+			if err != nil {
+				t.Fatalf("getEnvironmentBuildParams() error = %v", err)
+			}
+			if len(got) != 0 || len(tt.want) != 0 {
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("getEnvironmentBuildParams() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetEnvironmentBuildProfile(t *testing.T) {
+	configMaps := map[client.ObjectKey]map[string]string{
+		{Name: "production-build-profile", Namespace: "build-service"}: {
+			EnvironmentBuildProfileConfigMapKey: "registry: quay.io/production-org\npipeline: docker-build-signed\nparams:\n  hermetic: \"true\"\n",
+		},
+		{Name: "staging-build-profile", Namespace: "build-service"}: {
+			EnvironmentBuildProfileConfigMapKey: "registry: quay.io/staging-org\n",
+		},
+		{Name: "broken-build-profile", Namespace: "build-service"}: {
+			EnvironmentBuildProfileConfigMapKey: "registries: quay.io/org\n",
+		},
+	}
+
+	tests := []struct {
+		name                  string
+		componentEnvironment  string
+		namespaceLabels       map[string]string
+		wantEnvironment       string
+		wantProfile           *BuildEnvironmentProfile
+		wantErr               bool
+		wantOutputImage       string
+		wantPipeline          string
+		wantHermeticParameter string
+	}{
+		{
+			name:            "default environment",
+			wantEnvironment: DefaultBuildEnvironment,
+			wantOutputImage: "quay.io/foo/bar:build",
+			wantPipeline:    "docker-build",
+		},
+		{
+			name:            "namespace environment",
+			namespaceLabels: map[string]string{BuildEnvironmentLabelName: "staging"},
+			wantEnvironment: "staging",
+			wantProfile:     &BuildEnvironmentProfile{Registry: "quay.io/staging-org"},
+			wantOutputImage: "quay.io/staging-org/bar:build",
+			wantPipeline:    "docker-build",
+		},
+		{
+			name:                 "component environment takes precedence",
+			componentEnvironment: "production",
+			namespaceLabels:      map[string]string{BuildEnvironmentLabelName: "staging"},
+			wantEnvironment:      "production",
+			wantProfile: &BuildEnvironmentProfile{
+				Registry: "quay.io/production-org",
+				Pipeline: "docker-build-signed",
+				Params:   map[string]string{"hermetic": "true"},
+			},
+			wantOutputImage:       "quay.io/production-org/bar:build",
+			wantPipeline:          "docker-build-signed",
+			wantHermeticParameter: "true",
+		},
+		{
+			name:                 "environment without profile",
+			componentEnvironment: "development",
+			wantEnvironment:      "development",
+			wantOutputImage:      "quay.io/foo/bar:build",
+			wantPipeline:         "docker-build",
+		},
+		{
+			name:                 "invalid profile",
+			componentEnvironment: "broken",
+			wantErr:              true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ComponentBuildReconciler{
+				Client:                     &buildEnvironmentClient{namespaceLabels: tt.namespaceLabels, configMaps: configMaps},
+				Log:                        logr.Discard(),
+				BuildEnvironmentsNamespace: "build-service",
+			}
+			component := getGitSourceComponent(map[string]string{BuildEnvironmentAnnotationName: tt.componentEnvironment}, "version: 2.2.0")
+
+			environment, profile, err := r.getEnvironmentBuildProfile(context.TODO(), component)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getEnvironmentBuildProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if environment != tt.wantEnvironment || !reflect.DeepEqual(profile, tt.wantProfile) {
+				t.Errorf("getEnvironmentBuildProfile() = %v, %v, want %v, %v", environment, profile, tt.wantEnvironment, tt.wantProfile)
+			}
+
+			pipelineRun := &tektonapi.PipelineRun{}
+			pipelineRun.Spec.PipelineRef = &tektonapi.PipelineRef{Name: "docker-build"}
+			mergePipelineParams(pipelineRun, []tektonapi.Param{{Name: "output-image", Value: *tektonapi.NewArrayOrString("quay.io/foo/bar:build")}})
+			applyEnvironmentBuildProfile(environment, profile, pipelineRun)
+
+			if got := pipelineRun.Labels[BuildEnvironmentLabelName]; got != tt.wantEnvironment {
+				t.Errorf("applyEnvironmentBuildProfile() environment label = %v, want %v", got, tt.wantEnvironment)
+			}
+			if got := getPipelineRunParam(*pipelineRun, "output-image"); got != tt.wantOutputImage {
+				t.Errorf("applyEnvironmentBuildProfile() output image = %v, want %v", got, tt.wantOutputImage)
+			}
+			if got := pipelineRun.Spec.PipelineRef.Name; got != tt.wantPipeline {
+				t.Errorf("applyEnvironmentBuildProfile() pipeline = %v, want %v", got, tt.wantPipeline)
+			}
+			if got := getPipelineRunParam(*pipelineRun, "hermetic"); got != tt.wantHermeticParameter {
+				t.Errorf("applyEnvironmentBuildProfile() hermetic parameter = %v, want %v", got, tt.wantHermeticParameter)
+			}
+		})
+	}
+}
+
+func TestBuildEnvironmentChangeRequiresBuild(t *testing.T) {
+	component := getGitSourceComponent(map[string]string{BuildEnvironmentAnnotationName: "staging"}, "version: 2.2.0")
+	setBuildSpecHash(&component)
+
+	component.Annotations[BuildEnvironmentAnnotationName] = "production"
+	if !isBuildSpecChanged(component) {
+		t.Errorf("isBuildSpecChanged() = false after the environment change, want true")
+	}
+}
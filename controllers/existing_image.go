@@ -0,0 +1,59 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// BuiltConditionType is set on components whose initial build was not needed as the image had been built before
+	BuiltConditionType = "Built"
+
+	BuiltReasonSkippedExistingImage = "SkippedExistingImage"
+)
+
+// getRevisionImage returns the reference of the output image tagged with the built commit,
+// or empty string if the build is not of an exact commit.
+func getRevisionImage(outputImage string, revision string) string {
+	if outputImage == "" || !commitSHARegexp.MatchString(revision) {
+		return ""
+	}
+	return getImageRepository(outputImage) + ":" + revision
+}
+
+// isExistingImage checks whether the image of the built commit has been pushed already, e.g. by a previous build
+// of the re-imported component. Only the registry is trusted, as the output image tag is usually reused by builds
+// of other commits and the component status always holds the output image.
+func (r *ComponentBuildReconciler) isExistingImage(ctx context.Context, revisionImage string) (bool, error) {
+	if revisionImage == "" || r.ImageRepositoryClient == nil {
+		return false, nil
+	}
+	return r.ImageRepositoryClient.ImageExists(ctx, revisionImage)
+}
+
+func getSkippedExistingImageCondition(image string) metav1.Condition {
+	return metav1.Condition{
+		Type:    BuiltConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  BuiltReasonSkippedExistingImage,
+		Message: fmt.Sprintf("Image %s already exists, the initial build is skipped", image),
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGetRevisionImage(t *testing.T) {
+	const commitSHA = "0123456789abcdef0123456789abcdef01234567"
+	tests := []struct {
+		name        string
+		outputImage string
+		revision    string
+		want        string
+	}{
+		{
+			name:        "image tagged with the commit",
+			outputImage: "quay.io/org/app:latest",
+			revision:    commitSHA,
+			want:        "quay.io/org/app:" + commitSHA,
+		},
+		{
+			name:        "registry with port and digest",
+			outputImage: "registry.example.com:5000/org/app@sha256:abcd",
+			revision:    commitSHA,
+			want:        "registry.example.com:5000/org/app:" + commitSHA,
+		},
+		{
+			name:        "branch revision",
+			outputImage: "quay.io/org/app:latest",
+			revision:    "main",
+		},
+		{
+			name:        "no revision",
+			outputImage: "quay.io/org/app:latest",
+		},
+		{
+			name:     "no output image",
+			revision: commitSHA,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getRevisionImage(tt.outputImage, tt.revision); got != tt.want {
+				t.Errorf("getRevisionImage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsExistingImage(t *testing.T) {
+	repositoryClient := &mockImageRepositoryClient{
+		images: map[string]bool{"quay.io/org/app:v1": true},
+	}
+
+	tests := []struct {
+		name             string
+		repositoryClient ImageRepositoryClient
+		image            string
+		want             bool
+	}{
+		{
+			name:             "image exists in the registry",
+			repositoryClient: repositoryClient,
+			image:            "quay.io/org/app:v1",
+			want:             true,
+		},
+		{
+			name:             "image is missing in the registry",
+			repositoryClient: repositoryClient,
+			image:            "quay.io/org/app:v2",
+			want:             false,
+		},
+		{
+			name:             "registry is not configured",
+			repositoryClient: nil,
+			image:            "quay.io/org/app:v1",
+			want:             false,
+		},
+		{
+			name:             "no revision image",
+			repositoryClient: repositoryClient,
+			image:            "",
+			want:             false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ComponentBuildReconciler{ImageRepositoryClient: tt.repositoryClient}
+			got, err := r.isExistingImage(context.TODO(), tt.image)
+			if err != nil {
+				t.Fatalf("isExistingImage() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("isExistingImage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
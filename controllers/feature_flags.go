@@ -0,0 +1,195 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// JSON object with Tekton feature flags the build pipeline requires, e.g. {"enable-api-fields": "alpha"}
+	RequireFeatureFlagsAnnotationName = "build.appstudio.openshift.io/require-feature-flags"
+
+	// FeatureFlagsNotSatisfiedConditionType is set on components whose build requires Tekton feature flags not enabled in the cluster
+	FeatureFlagsNotSatisfiedConditionType = "FeatureFlagsNotSatisfied"
+
+	FeatureFlagsNotSatisfiedReasonMissing   = "FeatureFlagsMissing"
+	FeatureFlagsNotSatisfiedReasonInvalid   = "InvalidFeatureFlags"
+	FeatureFlagsNotSatisfiedReasonSatisfied = "FeatureFlagsSatisfied"
+
+	// DefaultTektonNamespace is the namespace of Tekton installation in upstream Kubernetes
+	DefaultTektonNamespace = "tekton-pipelines"
+
+	tektonFeatureFlagsConfigMapName = "feature-flags"
+	tektonAPIFieldsFeatureFlag      = "enable-api-fields"
+)
+
+// tektonFeatureFlagDefaults holds values Tekton uses for feature flags missing in the feature-flags ConfigMap
+var tektonFeatureFlagDefaults = map[string]string{
+	tektonAPIFieldsFeatureFlag: "stable",
+}
+
+// tektonAPIFieldsLevels orders values of the enable-api-fields feature flag, a level enables all lower levels
+var tektonAPIFieldsLevels = map[string]int{
+	"stable": 0,
+	"beta":   1,
+	"alpha":  2,
+}
+
+// getRequiredFeatureFlags returns Tekton feature flags requested in the component annotation.
+func getRequiredFeatureFlags(component appstudiov1alpha1.Component) (map[string]string, error) {
+	flagsJSON := component.Annotations[RequireFeatureFlagsAnnotationName]
+	if flagsJSON == "" {
+		return nil, nil
+	}
+	flags := map[string]string{}
+	if err := json.Unmarshal([]byte(flagsJSON), &flags); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation, JSON object of strings expected: %v", RequireFeatureFlagsAnnotationName, err)
+	}
+	return flags, nil
+}
+
+// getFeatureFlagsNotSatisfiedCondition returns the FeatureFlagsNotSatisfied condition if the Tekton feature flags
+// required by the component are not enabled in the cluster or the annotation is invalid, nil if the build could proceed.
+func (r *ComponentBuildReconciler) getFeatureFlagsNotSatisfiedCondition(ctx context.Context, component appstudiov1alpha1.Component) (*metav1.Condition, error) {
+	requiredFlags, err := getRequiredFeatureFlags(component)
+	if err != nil {
+		return &metav1.Condition{
+			Type:    FeatureFlagsNotSatisfiedConditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  FeatureFlagsNotSatisfiedReasonInvalid,
+			Message: err.Error(),
+		}, nil
+	}
+	if len(requiredFlags) == 0 {
+		return nil, nil
+	}
+
+	var reader client.Reader = r.Client
+	if r.featureFlagsConfigMapCache != nil {
+		reader = r.featureFlagsConfigMapCache
+	}
+	featureFlags := &corev1.ConfigMap{}
+	if err := reader.Get(ctx, r.getTektonFeatureFlagsConfigMap(), featureFlags); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		// Tekton uses default values of all feature flags
+	}
+
+	unsatisfiedFlags := getUnsatisfiedFeatureFlags(requiredFlags, featureFlags.Data)
+	if len(unsatisfiedFlags) == 0 {
+		return nil, nil
+	}
+	return &metav1.Condition{
+		Type:    FeatureFlagsNotSatisfiedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  FeatureFlagsNotSatisfiedReasonMissing,
+		Message: fmt.Sprintf("Tekton feature flags are not enabled in the cluster: %s", strings.Join(unsatisfiedFlags, ", ")),
+	}, nil
+}
+
+// clearFeatureFlagsNotSatisfiedCondition marks the Tekton feature flags required by the component as enabled.
+func clearFeatureFlagsNotSatisfiedCondition(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) error {
+	if !meta.IsStatusConditionTrue(component.Status.Conditions, FeatureFlagsNotSatisfiedConditionType) {
+		return nil
+	}
+	return setComponentCondition(ctx, cli, component, metav1.Condition{
+		Type:    FeatureFlagsNotSatisfiedConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  FeatureFlagsNotSatisfiedReasonSatisfied,
+		Message: "All required Tekton feature flags are enabled",
+	})
+}
+
+// getTektonFeatureFlagsConfigMap returns the key of the Tekton feature-flags ConfigMap.
+func (r *ComponentBuildReconciler) getTektonFeatureFlagsConfigMap() types.NamespacedName {
+	tektonNamespace := r.TektonNamespace
+	if tektonNamespace == "" {
+		tektonNamespace = DefaultTektonNamespace
+	}
+	return types.NamespacedName{Name: tektonFeatureFlagsConfigMapName, Namespace: tektonNamespace}
+}
+
+func (r *ComponentBuildReconciler) isTektonFeatureFlagsConfigMap(object client.Object) bool {
+	featureFlagsConfigMap := r.getTektonFeatureFlagsConfigMap()
+	return object.GetName() == featureFlagsConfigMap.Name && object.GetNamespace() == featureFlagsConfigMap.Namespace
+}
+
+// getFeatureFlagsComponents returns reconcile requests for all components which builds wait for Tekton feature flags.
+func (r *ComponentBuildReconciler) getFeatureFlagsComponents(object client.Object) []reconcile.Request {
+	components := &appstudiov1alpha1.ComponentList{}
+	if err := r.Client.List(context.Background(), components); err != nil {
+		r.Log.Error(err, "Failed to list components waiting for Tekton feature flags")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, component := range components.Items {
+		if !meta.IsStatusConditionTrue(component.Status.Conditions, FeatureFlagsNotSatisfiedConditionType) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: component.Name, Namespace: component.Namespace},
+		})
+	}
+	return requests
+}
+
+// getUnsatisfiedFeatureFlags returns sorted required feature flags in name=value format which are not enabled
+// by the given cluster feature flags.
+func getUnsatisfiedFeatureFlags(requiredFlags map[string]string, clusterFlags map[string]string) []string {
+	var unsatisfiedFlags []string
+	for name, requiredValue := range requiredFlags {
+		value, isSet := clusterFlags[name]
+		if !isSet {
+			value = tektonFeatureFlagDefaults[name]
+		}
+		if !isFeatureFlagSatisfied(name, requiredValue, value) {
+			unsatisfiedFlags = append(unsatisfiedFlags, name+"="+requiredValue)
+		}
+	}
+	sort.Strings(unsatisfiedFlags)
+	return unsatisfiedFlags
+}
+
+func isFeatureFlagSatisfied(name string, requiredValue string, value string) bool {
+	requiredValue = strings.TrimSpace(requiredValue)
+	value = strings.TrimSpace(value)
+	if name == tektonAPIFieldsFeatureFlag {
+		requiredLevel, isKnownRequired := tektonAPIFieldsLevels[requiredValue]
+		level, isKnown := tektonAPIFieldsLevels[value]
+		if isKnownRequired && isKnown {
+			return level >= requiredLevel
+		}
+	}
+	return requiredValue == value
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type featureFlagsClient struct {
+	client.Client
+	featureFlags *corev1.ConfigMap
+}
+
+func (c *featureFlagsClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if c.featureFlags == nil || key.Name != c.featureFlags.Name || key.Namespace != c.featureFlags.Namespace {
+		return errors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, key.Name)
+	}
+	c.featureFlags.DeepCopyInto(obj.(*corev1.ConfigMap))
+	return nil
+}
+
+func TestGetFeatureFlagsNotSatisfiedCondition(t *testing.T) {
+	alphaFeatureFlags := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: tektonFeatureFlagsConfigMapName, Namespace: DefaultTektonNamespace},
+		Data:       map[string]string{tektonAPIFieldsFeatureFlag: "alpha"},
+	}
+
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		featureFlags *corev1.ConfigMap
+		wantReason   string
+	}{
+		{
+			name: "no required feature flags",
+		},
+		{
+			name:         "required feature flags enabled",
+			annotations:  map[string]string{RequireFeatureFlagsAnnotationName: `{"enable-api-fields": "beta"}`},
+			featureFlags: alphaFeatureFlags,
+		},
+		{
+			name:        "required feature flags not enabled",
+			annotations: map[string]string{RequireFeatureFlagsAnnotationName: `{"enable-api-fields": "beta"}`},
+			wantReason:  FeatureFlagsNotSatisfiedReasonMissing,
+		},
+		{
+			name:         "invalid annotation",
+			annotations:  map[string]string{RequireFeatureFlagsAnnotationName: `["enable-api-fields"]`},
+			featureFlags: alphaFeatureFlags,
+			wantReason:   FeatureFlagsNotSatisfiedReasonInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ComponentBuildReconciler{Client: &featureFlagsClient{featureFlags: tt.featureFlags}}
+			condition, err := r.getFeatureFlagsNotSatisfiedCondition(context.TODO(), getGitSourceComponent(tt.annotations, ""))
+			if err != nil {
+				t.Fatalf("getFeatureFlagsNotSatisfiedCondition() error = %v", err)
+			}
+			if tt.wantReason == "" {
+				if condition != nil {
+					t.Errorf("getFeatureFlagsNotSatisfiedCondition() = %v, want nil", condition)
+				}
+				return
+			}
+			if condition == nil || condition.Status != metav1.ConditionTrue || condition.Reason != tt.wantReason {
+				t.Errorf("getFeatureFlagsNotSatisfiedCondition() = %v, want reason %s", condition, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestGetUnsatisfiedFeatureFlags(t *testing.T) {
+	tests := []struct {
+		name          string
+		requiredFlags map[string]string
+		clusterFlags  map[string]string
+		want          []string
+	}{
+		{
+			name:          "flag enabled",
+			requiredFlags: map[string]string{"enable-tekton-oci-bundles": "true"},
+			clusterFlags:  map[string]string{"enable-tekton-oci-bundles": "true"},
+			want:          nil,
+		},
+		{
+			name:          "flag disabled",
+			requiredFlags: map[string]string{"enable-tekton-oci-bundles": "true"},
+			clusterFlags:  map[string]string{"enable-tekton-oci-bundles": "false"},
+			want:          []string{"enable-tekton-oci-bundles=true"},
+		},
+		{
+			name:          "alpha API fields enable beta",
+			requiredFlags: map[string]string{"enable-api-fields": "beta"},
+			clusterFlags:  map[string]string{"enable-api-fields": "alpha"},
+			want:          nil,
+		},
+		{
+			name:          "stable API fields do not enable alpha",
+			requiredFlags: map[string]string{"enable-api-fields": "alpha"},
+			clusterFlags:  map[string]string{"enable-api-fields": "stable"},
+			want:          []string{"enable-api-fields=alpha"},
+		},
+		{
+			name:          "default API fields",
+			requiredFlags: map[string]string{"enable-api-fields": "stable"},
+			clusterFlags:  nil,
+			want:          nil,
+		},
+		{
+			name:          "several flags missing in the cluster",
+			requiredFlags: map[string]string{"enable-api-fields": "alpha", "enable-custom-tasks": "true"},
+			clusterFlags:  nil,
+			want:          []string{"enable-api-fields=alpha", "enable-custom-tasks=true"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getUnsatisfiedFeatureFlags(tt.requiredFlags, tt.clusterFlags); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getUnsatisfiedFeatureFlags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// FreezeWindowsAnnotationName is the annotation of a Component with JSON object of its build freeze windows, e.g.
+	// {"timezone": "Europe/Prague", "windows": [{"start": "2022-06-24T00:00", "end": "2022-07-01T00:00"}, {"weekdays": ["Sat", "Sun"]}]}
+	FreezeWindowsAnnotationName = "build.appstudio.openshift.io/freeze-windows"
+
+	// FreezeActiveConditionType is set on components whose build is suppressed by a freeze window
+	FreezeActiveConditionType = "FreezeActive"
+
+	FreezeActiveReasonBuildsFrozen = "BuildsFrozen"
+	FreezeActiveReasonFreezeEnded  = "FreezeEnded"
+
+	freezeDateTimeLayout = "2006-01-02T15:04"
+	freezeTimeLayout     = "15:04"
+	// End of day of weekly freeze windows
+	freezeEndOfDay = "24:00"
+
+	maxFreezeWindowMerges = 16
+)
+
+// FreezeWindows are time ranges builds of a component are not submitted in.
+type FreezeWindows struct {
+	// Timezone of the windows from the IANA time zone database, empty means UTC
+	Timezone string `json:"timezone,omitempty"`
+	// Windows are the one-off or weekly freeze windows
+	Windows []FreezeWindow `json:"windows"`
+	// AllowManual allows builds requested manually by resetting the initial build annotation during the freeze
+	AllowManual bool `json:"allowManual,omitempty"`
+}
+
+// FreezeWindow is either a one-off window from start to end, or a weekly window on the weekdays from the time to the time.
+type FreezeWindow struct {
+	// Start of a one-off window in 2006-01-02T15:04 format
+	Start string `json:"start,omitempty"`
+	// End of a one-off window in 2006-01-02T15:04 format
+	End string `json:"end,omitempty"`
+	// Weekdays of a weekly window, e.g. Sat
+	Weekdays []string `json:"weekdays,omitempty"`
+	// From is the start time of a weekly window in 15:04 format, empty means the start of the day
+	From string `json:"from,omitempty"`
+	// To is the end time of a weekly window in 15:04 format, empty means the end of the day
+	To string `json:"to,omitempty"`
+}
+
+var freezeWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// getFreezeWindows returns the validated freeze windows from the component annotation or nil if there are none.
+func getFreezeWindows(component appstudiov1alpha1.Component) (*FreezeWindows, error) {
+	windowsJSON := component.Annotations[FreezeWindowsAnnotationName]
+	if windowsJSON == "" {
+		return nil, nil
+	}
+	windows := &FreezeWindows{}
+	if err := json.Unmarshal([]byte(windowsJSON), windows); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", FreezeWindowsAnnotationName, err)
+	}
+	if _, err := time.LoadLocation(windows.Timezone); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: unknown timezone %s", FreezeWindowsAnnotationName, windows.Timezone)
+	}
+	for i, window := range windows.Windows {
+		if err := validateFreezeWindow(window); err != nil {
+			return nil, fmt.Errorf("invalid %s annotation: window %d %v", FreezeWindowsAnnotationName, i, err)
+		}
+	}
+	return windows, nil
+}
+
+func validateFreezeWindow(window FreezeWindow) error {
+	isOneOff := window.Start != "" || window.End != ""
+	isWeekly := len(window.Weekdays) > 0 || window.From != "" || window.To != ""
+	if isOneOff == isWeekly {
+		return fmt.Errorf("must have either start and end, or weekdays")
+	}
+
+	if isOneOff {
+		start, err := time.Parse(freezeDateTimeLayout, window.Start)
+		if err != nil {
+			return fmt.Errorf("start must be in %s format", freezeDateTimeLayout)
+		}
+		end, err := time.Parse(freezeDateTimeLayout, window.End)
+		if err != nil {
+			return fmt.Errorf("end must be in %s format", freezeDateTimeLayout)
+		}
+		if !start.Before(end) {
+			return fmt.Errorf("start must be before end")
+		}
+		return nil
+	}
+
+	if len(window.Weekdays) == 0 {
+		return fmt.Errorf("weekdays must be set")
+	}
+	for _, weekday := range window.Weekdays {
+		if _, valid := freezeWeekdays[strings.ToLower(weekday)]; !valid {
+			return fmt.Errorf("unknown weekday %s", weekday)
+		}
+	}
+	from, err := parseFreezeTimeOfDay(window.From, "00:00")
+	if err != nil {
+		return fmt.Errorf("from must be in %s format", freezeTimeLayout)
+	}
+	to, err := parseFreezeTimeOfDay(window.To, freezeEndOfDay)
+	if err != nil {
+		return fmt.Errorf("to must be in %s format", freezeTimeLayout)
+	}
+	if from >= to {
+		return fmt.Errorf("from must be before to")
+	}
+	return nil
+}
+
+// parseFreezeTimeOfDay returns the time since midnight, 24:00 is allowed as the end of the day.
+func parseFreezeTimeOfDay(value string, defaultValue string) (time.Duration, error) {
+	if value == "" {
+		value = defaultValue
+	}
+	if value == freezeEndOfDay {
+		return 24 * time.Hour, nil
+	}
+	timeOfDay, err := time.Parse(freezeTimeLayout, value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(timeOfDay.Hour())*time.Hour + time.Duration(timeOfDay.Minute())*time.Minute, nil
+}
+
+// getFreezeEnd returns the end of the freeze window active at the given time, zero time means no window is active.
+// Overlapping and adjacent windows are merged, so the returned end is the time builds are allowed again.
+func getFreezeEnd(windows FreezeWindows, now time.Time) time.Time {
+	location, _ := time.LoadLocation(windows.Timezone)
+	now = now.In(location)
+
+	var freezeEnd time.Time
+	// The merging is bounded, e.g. weekly windows might cover the whole week
+	for i := 0; i < maxFreezeWindowMerges; i++ {
+		end := getFreezeWindowsEnd(windows, location, now)
+		if end.IsZero() {
+			break
+		}
+		freezeEnd = end
+		// Continue with a window which starts right when the current one ends
+		now = end
+	}
+	return freezeEnd
+}
+
+// getFreezeWindowsEnd returns the latest end of the windows active at the given time, zero time means no window is active.
+func getFreezeWindowsEnd(windows FreezeWindows, location *time.Location, now time.Time) time.Time {
+	var latestEnd time.Time
+	for _, window := range windows.Windows {
+		end := getFreezeWindowEnd(window, location, now)
+		if end.After(latestEnd) {
+			latestEnd = end
+		}
+	}
+	return latestEnd
+}
+
+func getFreezeWindowEnd(window FreezeWindow, location *time.Location, now time.Time) time.Time {
+	if window.Start != "" {
+		start, _ := time.ParseInLocation(freezeDateTimeLayout, window.Start, location)
+		end, _ := time.ParseInLocation(freezeDateTimeLayout, window.End, location)
+		if !now.Before(start) && now.Before(end) {
+			return end
+		}
+		return time.Time{}
+	}
+
+	for _, weekday := range window.Weekdays {
+		if freezeWeekdays[strings.ToLower(weekday)] != now.Weekday() {
+			continue
+		}
+		from, _ := parseFreezeTimeOfDay(window.From, "00:00")
+		to, _ := parseFreezeTimeOfDay(window.To, freezeEndOfDay)
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+		if start, end := midnight.Add(from), midnight.Add(to); !now.Before(start) && now.Before(end) {
+			return end
+		}
+	}
+	return time.Time{}
+}
+
+// isManualBuild checks whether the build has been requested manually by resetting the initial build annotation.
+func isManualBuild(component appstudiov1alpha1.Component) bool {
+	value, exists := component.Annotations[InitialBuildAnnotationName]
+	return exists && value != "true"
+}
+
+func getFreezeActiveCondition(freezeEnd time.Time) metav1.Condition {
+	return metav1.Condition{
+		Type:    FreezeActiveConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  FreezeActiveReasonBuildsFrozen,
+		Message: fmt.Sprintf("Builds are frozen until %s, the build is submitted after the freeze", freezeEnd.Format(time.RFC3339)),
+	}
+}
+
+// clearFreezeActiveCondition marks the build of previously frozen component as submitted.
+func clearFreezeActiveCondition(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) error {
+	if !meta.IsStatusConditionTrue(component.Status.Conditions, FreezeActiveConditionType) {
+		return nil
+	}
+	return setComponentCondition(ctx, cli, component, metav1.Condition{
+		Type:    FreezeActiveConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  FreezeActiveReasonFreezeEnded,
+		Message: "The freeze has ended or the build is allowed during the freeze, the build has been submitted",
+	})
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetFreezeWindows(t *testing.T) {
+	tests := []struct {
+		name        string
+		windowsJSON string
+		wantErr     bool
+	}{
+		{name: "none", windowsJSON: ""},
+		{name: "one-off", windowsJSON: `{"timezone": "Europe/Prague", "windows": [{"start": "2022-06-24T00:00", "end": "2022-07-01T00:00"}]}`},
+		{name: "weekly", windowsJSON: `{"windows": [{"weekdays": ["Sat", "sun"]}, {"weekdays": ["Fri"], "from": "16:00", "to": "24:00"}]}`},
+		{name: "invalid JSON", windowsJSON: `{"windows": [`, wantErr: true},
+		{name: "unknown timezone", windowsJSON: `{"timezone": "Mars/Olympus", "windows": []}`, wantErr: true},
+		{name: "end before start", windowsJSON: `{"windows": [{"start": "2022-07-01T00:00", "end": "2022-06-24T00:00"}]}`, wantErr: true},
+		{name: "invalid start", windowsJSON: `{"windows": [{"start": "2022-06-24", "end": "2022-07-01T00:00"}]}`, wantErr: true},
+		{name: "unknown weekday", windowsJSON: `{"windows": [{"weekdays": ["Caturday"]}]}`, wantErr: true},
+		{name: "from after to", windowsJSON: `{"windows": [{"weekdays": ["Fri"], "from": "18:00", "to": "16:00"}]}`, wantErr: true},
+		{name: "one-off and weekly", windowsJSON: `{"windows": [{"start": "2022-06-24T00:00", "end": "2022-07-01T00:00", "weekdays": ["Fri"]}]}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := getGitSourceComponent(map[string]string{FreezeWindowsAnnotationName: tt.windowsJSON}, "")
+			got, err := getFreezeWindows(component)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getFreezeWindows() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (got == nil) != (tt.windowsJSON == "") {
+				t.Errorf("getFreezeWindows() = %v", got)
+			}
+		})
+	}
+}
+
+func TestGetFreezeEnd(t *testing.T) {
+	windows := FreezeWindows{
+		Timezone: "Europe/Prague",
+		Windows: []FreezeWindow{
+			// End of quarter freeze, Thursday to Thursday
+			{Start: "2022-06-23T00:00", End: "2022-06-30T12:00"},
+			// Friday afternoons and weekends
+			{Weekdays: []string{"Fri"}, From: "16:00"},
+			{Weekdays: []string{"Sat", "Sun"}},
+		},
+	}
+	prague, err := time.LoadLocation("Europe/Prague")
+	if err != nil {
+		t.Fatalf("failed to load timezone: %v", err)
+	}
+	at := func(value string) time.Time {
+		parsed, err := time.ParseInLocation(freezeDateTimeLayout, value, prague)
+		if err != nil {
+			t.Fatalf("failed to parse time: %v", err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{name: "before the freeze", now: at("2022-06-22T23:59"), want: time.Time{}},
+		{name: "within the freeze", now: at("2022-06-27T09:00"), want: at("2022-06-30T12:00")},
+		{name: "after the freeze", now: at("2022-06-30T12:00"), want: time.Time{}},
+		{name: "Friday morning", now: at("2022-07-01T09:00"), want: time.Time{}},
+		{name: "Friday afternoon merged with the weekend", now: at("2022-07-01T17:00"), want: at("2022-07-04T00:00")},
+		{name: "Sunday", now: at("2022-07-03T10:00"), want: at("2022-07-04T00:00")},
+		{name: "Monday", now: at("2022-07-04T00:00"), want: time.Time{}},
+		{name: "other timezone", now: at("2022-07-01T17:00").UTC(), want: at("2022-07-04T00:00")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getFreezeEnd(windows, tt.now); !got.Equal(tt.want) {
+				t.Errorf("getFreezeEnd() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetFreezeEndWholeWeek(t *testing.T) {
+	windows := FreezeWindows{Windows: []FreezeWindow{{Weekdays: []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}}}}
+	now := time.Date(2022, 6, 27, 9, 0, 0, 0, time.UTC)
+	if got := getFreezeEnd(windows, now); !got.After(now) {
+		t.Errorf("getFreezeEnd() = %v, want a time after %v", got, now)
+	}
+}
+
+func TestIsManualBuild(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+	}{
+		{name: "initial build", annotations: nil, want: false},
+		{name: "built", annotations: map[string]string{InitialBuildAnnotationName: "true"}, want: false},
+		{name: "rebuild requested", annotations: map[string]string{InitialBuildAnnotationName: "false"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isManualBuild(getGitSourceComponent(tt.annotations, "")); got != tt.want {
+				t.Errorf("isManualBuild() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
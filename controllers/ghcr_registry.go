@@ -0,0 +1,110 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// Target registry of the build output image, "ghcr" pushes the image into GitHub Packages of the component repository
+	RegistryAnnotationName = "build.appstudio.openshift.io/registry"
+	// Name of the Secret with credentials of the target registry, overrides the default name
+	RegistrySecretAnnotationName = "build.appstudio.openshift.io/registry-secret"
+
+	RegistryGHCR = "ghcr"
+
+	ghcrHost = "ghcr.io"
+	// Default name of the Secret with GitHub Packages credentials in the build namespace
+	defaultGHCRSecretName = "ghcr-auth"
+	defaultGHCRImageTag   = "latest"
+)
+
+// isGHCRBuild checks whether the build output image of the component is pushed into GitHub Packages.
+func isGHCRBuild(component appstudiov1alpha1.Component) bool {
+	return component.Annotations[RegistryAnnotationName] == RegistryGHCR
+}
+
+// applyGHCRImage sets the output image of the build into GitHub Packages of the component repository
+// and links the registry credentials Secret to the pipeline Service Account.
+// The tag of the original output image is preserved.
+func (r *ComponentBuildReconciler) applyGHCRImage(ctx context.Context, component appstudiov1alpha1.Component, build *tektonapi.PipelineRun, serviceAccount *corev1.ServiceAccount) error {
+	gitSource := getGitSource(component)
+	if gitSource == nil {
+		return fmt.Errorf("GitHub Packages registry requires git source")
+	}
+
+	secretName := component.Annotations[RegistrySecretAnnotationName]
+	if secretName == "" {
+		secretName = defaultGHCRSecretName
+	}
+	// The Secret has to be in the build namespace, where the Service Account is
+	if err := r.NonCachingClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: serviceAccount.Namespace}, &corev1.Secret{}); err != nil {
+		return fmt.Errorf("GitHub Packages credentials Secret %s: %v", secretName, err)
+	}
+	if updateServiceAccountIfSecretNotLinked(secretName, serviceAccount, r.SecretLinkingStrategy) {
+		if err := r.Client.Update(ctx, serviceAccount); err != nil {
+			return err
+		}
+	}
+
+	tag := defaultGHCRImageTag
+	if outputImage := getPipelineRunParam(*build, "output-image"); outputImage != "" {
+		if imageTag := getImageTag(outputImage); imageTag != "" {
+			tag = imageTag
+		}
+	}
+	image, err := getGHCRImage(gitSource.URL, tag)
+	if err != nil {
+		return err
+	}
+
+	mergePipelineParams(build, []tektonapi.Param{
+		{
+			Name: "output-image",
+			Value: tektonapi.ArrayOrString{
+				Type:      tektonapi.ParamTypeString,
+				StringVal: image,
+			},
+		},
+	})
+	return nil
+}
+
+// getGHCRImage returns GitHub Packages image of the given GitHub repository, e.g.
+// https://github.com/Foo/bar.git becomes ghcr.io/foo/bar:tag
+func getGHCRImage(gitURL string, tag string) (string, error) {
+	u, err := url.Parse(gitURL)
+	if err != nil || u.Host != "github.com" {
+		return "", fmt.Errorf("GitHub Packages registry requires GitHub repository, got %s", gitURL)
+	}
+	parts := strings.Split(strings.Trim(strings.TrimSuffix(u.Path, ".git"), "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("invalid GitHub repository URL %s, https://github.com/owner/repository expected", gitURL)
+	}
+	// GitHub Packages image names must be lowercase
+	return strings.ToLower(fmt.Sprintf("%s/%s/%s", ghcrHost, parts[0], parts[1])) + ":" + tag, nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+)
+
+func TestGetGHCRImage(t *testing.T) {
+	tests := []struct {
+		name    string
+		gitURL  string
+		tag     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "GitHub repository",
+			gitURL: "https://github.com/devfile-samples/devfile-sample-java-springboot-basic",
+			tag:    "latest",
+			want:   "ghcr.io/devfile-samples/devfile-sample-java-springboot-basic:latest",
+		},
+		{
+			name:   "uppercase owner and git suffix",
+			gitURL: "https://github.com/Devfile-Samples/Sample.git",
+			tag:    "v1",
+			want:   "ghcr.io/devfile-samples/sample:v1",
+		},
+		{
+			name:   "trailing slash",
+			gitURL: "https://github.com/foo/bar/",
+			tag:    "latest",
+			want:   "ghcr.io/foo/bar:latest",
+		},
+		{
+			name:    "not a GitHub repository",
+			gitURL:  "https://gitlab.com/foo/bar",
+			tag:     "latest",
+			wantErr: true,
+		},
+		{
+			name:    "GitHub URL without repository",
+			gitURL:  "https://github.com/foo",
+			tag:     "latest",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getGHCRImage(tt.gitURL, tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getGHCRImage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("getGHCRImage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// JSON object with git clone options of the build, e.g. {"depth": 1, "submodules": true}
+	GitCloneOptionsAnnotationName = "build.appstudio.openshift.io/git-clone-options"
+
+	// GitCloneOptionsAdjustedConditionType is set on components whose git clone options were inconsistent and have been corrected
+	GitCloneOptionsAdjustedConditionType = "GitCloneOptionsAdjusted"
+
+	GitCloneOptionsAdjustedReason           = "InconsistentOptions"
+	GitCloneOptionsAdjustedReasonConsistent = "ConsistentOptions"
+)
+
+// gitCloneOptions holds git clone options of the build, nil depth means the pipeline default.
+// Depth 0 means full history.
+type gitCloneOptions struct {
+	Depth *int `json:"depth,omitempty"`
+	// Submodules enables cloning of submodules
+	Submodules bool `json:"submodules,omitempty"`
+	// Recursive enables cloning of nested submodules
+	Recursive bool `json:"recursive,omitempty"`
+	// SubmodulesDepth is the history depth of submodules, nil means the same as the repository depth
+	SubmodulesDepth *int `json:"submodulesDepth,omitempty"`
+}
+
+// getGitCloneOptions returns git clone options requested in the component annotation or nil if there are none.
+func getGitCloneOptions(component appstudiov1alpha1.Component) (*gitCloneOptions, error) {
+	optionsJSON := component.Annotations[GitCloneOptionsAnnotationName]
+	if optionsJSON == "" {
+		return nil, nil
+	}
+	options := &gitCloneOptions{}
+	if err := json.Unmarshal([]byte(optionsJSON), options); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", GitCloneOptionsAnnotationName, err)
+	}
+	if options.Depth != nil && *options.Depth < 0 {
+		return nil, fmt.Errorf("invalid %s annotation: depth must not be negative", GitCloneOptionsAnnotationName)
+	}
+	if options.SubmodulesDepth != nil && *options.SubmodulesDepth < 0 {
+		return nil, fmt.Errorf("invalid %s annotation: submodulesDepth must not be negative", GitCloneOptionsAnnotationName)
+	}
+	return options, nil
+}
+
+// normalizeGitCloneOptions corrects inconsistent combinations of the options in place
+// and returns descriptions of the corrections made.
+// Depths of the repository and its submodules are independent, as submodules are separate repositories with their own history.
+func normalizeGitCloneOptions(options *gitCloneOptions) []string {
+	var corrections []string
+
+	if options.Recursive && !options.Submodules {
+		options.Submodules = true
+		corrections = append(corrections, "recursive clone requires submodules, submodules enabled")
+	}
+
+	if !options.Submodules && options.SubmodulesDepth != nil {
+		options.SubmodulesDepth = nil
+		corrections = append(corrections, "submodulesDepth is ignored as submodules are disabled")
+	}
+
+	return corrections
+}
+
+// getGitCloneParams returns pipeline parameters of the given git clone options.
+func getGitCloneParams(options gitCloneOptions) []tektonapi.Param {
+	var params []tektonapi.Param
+	addParam := func(name string, value string) {
+		params = append(params, tektonapi.Param{
+			Name: name,
+			Value: tektonapi.ArrayOrString{
+				Type:      tektonapi.ParamTypeString,
+				StringVal: value,
+			},
+		})
+	}
+
+	if options.Depth != nil {
+		addParam("depth", strconv.Itoa(*options.Depth))
+	}
+	addParam("submodules", strconv.FormatBool(options.Submodules))
+	if options.Submodules {
+		addParam("submodules-recursive", strconv.FormatBool(options.Recursive))
+		submodulesDepth := options.SubmodulesDepth
+		if submodulesDepth == nil {
+			submodulesDepth = options.Depth
+		}
+		if submodulesDepth != nil {
+			addParam("submodules-depth", strconv.Itoa(*submodulesDepth))
+		}
+	}
+	return params
+}
+
+func getGitCloneOptionsAdjustedCondition(corrections []string) metav1.Condition {
+	return metav1.Condition{
+		Type:    GitCloneOptionsAdjustedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  GitCloneOptionsAdjustedReason,
+		Message: fmt.Sprintf("Git clone options have been corrected: %s", strings.Join(corrections, "; ")),
+	}
+}
+
+// clearGitCloneOptionsAdjustedCondition marks git clone options of the component, which have been corrected before, as consistent.
+func clearGitCloneOptionsAdjustedCondition(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) error {
+	if !meta.IsStatusConditionTrue(component.Status.Conditions, GitCloneOptionsAdjustedConditionType) {
+		return nil
+	}
+	return setComponentCondition(ctx, cli, component, metav1.Condition{
+		Type:    GitCloneOptionsAdjustedConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  GitCloneOptionsAdjustedReasonConsistent,
+		Message: "Git clone options are used as requested",
+	})
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGitCloneOptions(t *testing.T) {
+	tests := []struct {
+		name            string
+		annotation      string
+		wantCorrections int
+		wantParams      map[string]string
+		wantErr         bool
+	}{
+		{
+			name:       "shallow clone without submodules",
+			annotation: `{"depth": 1}`,
+			wantParams: map[string]string{"depth": "1", "submodules": "false"},
+		},
+		{
+			name:       "shallow clone with shallow submodules",
+			annotation: `{"depth": 1, "submodules": true}`,
+			wantParams: map[string]string{"depth": "1", "submodules": "true", "submodules-recursive": "false", "submodules-depth": "1"},
+		},
+		{
+			name:       "full clone with full submodules history",
+			annotation: `{"depth": 0, "submodules": true, "recursive": true, "submodulesDepth": 0}`,
+			wantParams: map[string]string{"depth": "0", "submodules": "true", "submodules-recursive": "true", "submodules-depth": "0"},
+		},
+		{
+			name:       "shallow clone with full submodules history",
+			annotation: `{"depth": 1, "submodules": true, "submodulesDepth": 0}`,
+			wantParams: map[string]string{"depth": "1", "submodules": "true", "submodules-recursive": "false", "submodules-depth": "0"},
+		},
+		{
+			name:       "shallow clone with deeper submodules history",
+			annotation: `{"depth": 1, "submodules": true, "submodulesDepth": 50}`,
+			wantParams: map[string]string{"depth": "1", "submodules": "true", "submodules-recursive": "false", "submodules-depth": "50"},
+		},
+		{
+			name:            "recursive clone without submodules",
+			annotation:      `{"depth": 10, "recursive": true, "submodulesDepth": 50}`,
+			wantCorrections: 1,
+			wantParams:      map[string]string{"depth": "10", "submodules": "true", "submodules-recursive": "true", "submodules-depth": "50"},
+		},
+		{
+			name:            "submodules depth without submodules",
+			annotation:      `{"submodulesDepth": 1}`,
+			wantCorrections: 1,
+			wantParams:      map[string]string{"submodules": "false"},
+		},
+		{
+			name:       "negative depth",
+			annotation: `{"depth": -1}`,
+			wantErr:    true,
+		},
+		{
+			name:       "invalid JSON",
+			annotation: `{"depth": "1"}`,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options, err := getGitCloneOptions(getGitSourceComponent(map[string]string{GitCloneOptionsAnnotationName: tt.annotation}, ""))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getGitCloneOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if corrections := normalizeGitCloneOptions(options); len(corrections) != tt.wantCorrections {
+				t.Errorf("normalizeGitCloneOptions() = %v, want %d corrections", corrections, tt.wantCorrections)
+			}
+
+			params := map[string]string{}
+			for _, param := range getGitCloneParams(*options) {
+				params[param.Name] = param.Value.StringVal
+			}
+			if !reflect.DeepEqual(params, tt.wantParams) {
+				t.Errorf("getGitCloneParams() = %v, want %v", params, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestClearGitCloneOptionsAdjustedCondition(t *testing.T) {
+	component := getGitSourceComponent(nil, "version: 2.2.0")
+	cli := &conflictingStatusClient{component: *component.DeepCopy()}
+
+	// Components whose options have never been corrected are not updated
+	if err := clearGitCloneOptionsAdjustedCondition(context.TODO(), cli, component); err != nil {
+		t.Fatalf("clearGitCloneOptionsAdjustedCondition() error = %v", err)
+	}
+	if len(cli.updated) != 0 {
+		t.Errorf("clearGitCloneOptionsAdjustedCondition() updated component without %s condition", GitCloneOptionsAdjustedConditionType)
+	}
+
+	meta.SetStatusCondition(&component.Status.Conditions, getGitCloneOptionsAdjustedCondition([]string{"submodules enabled"}))
+	cli.component = *component.DeepCopy()
+	if err := clearGitCloneOptionsAdjustedCondition(context.TODO(), cli, component); err != nil {
+		t.Fatalf("clearGitCloneOptionsAdjustedCondition() error = %v", err)
+	}
+	if len(cli.updated) != 1 {
+		t.Fatalf("clearGitCloneOptionsAdjustedCondition() updated status %d times, want 1", len(cli.updated))
+	}
+	AssertComponentConditions(t, &cli.updated[0], []metav1.Condition{
+		{Type: GitCloneOptionsAdjustedConditionType, Status: metav1.ConditionFalse, Reason: GitCloneOptionsAdjustedReasonConsistent},
+	})
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// If set to "true", the component is built only with git credentials, anonymous clone of its repository is not allowed
+	RequireGitCredentialsAnnotationName = "build.appstudio.openshift.io/require-git-credentials"
+
+	// MissingCredentialsConditionType is set on components which require git credentials, but have none
+	MissingCredentialsConditionType = "MissingCredentials"
+
+	MissingCredentialsReasonMissing  = "GitCredentialsMissing"
+	MissingCredentialsReasonProvided = "GitCredentialsProvided"
+
+	missingCredentialsRequeueInterval = time.Minute
+)
+
+// getMissingGitCredentials returns the reason why the component which requires git credentials can't be built,
+// or empty string if the credentials are not required or the git Secret exists.
+func (r *ComponentBuildReconciler) getMissingGitCredentials(ctx context.Context, component appstudiov1alpha1.Component) (string, error) {
+	if component.Annotations[RequireGitCredentialsAnnotationName] != "true" {
+		return "", nil
+	}
+	if component.Spec.Secret == "" {
+		return "The component requires git credentials, but has no git Secret, anonymous clone is not allowed", nil
+	}
+
+	gitSecret := &corev1.Secret{}
+	if err := r.secretCache.Get(ctx, r.NonCachingClient, types.NamespacedName{Name: component.Spec.Secret, Namespace: component.Namespace}, gitSecret); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Sprintf("The component requires git credentials, but git Secret %s does not exist, anonymous clone is not allowed", component.Spec.Secret), nil
+		}
+		return "", err
+	}
+	return "", nil
+}
+
+func getMissingCredentialsCondition(message string) metav1.Condition {
+	return metav1.Condition{
+		Type:    MissingCredentialsConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  MissingCredentialsReasonMissing,
+		Message: message,
+	}
+}
+
+// clearMissingCredentialsCondition marks the build of the component which was missing git credentials as submitted.
+func clearMissingCredentialsCondition(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) error {
+	if !meta.IsStatusConditionTrue(component.Status.Conditions, MissingCredentialsConditionType) {
+		return nil
+	}
+	return setComponentCondition(ctx, cli, component, metav1.Condition{
+		Type:    MissingCredentialsConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  MissingCredentialsReasonProvided,
+		Message: "Git credentials have been provided, the build has been submitted",
+	})
+}
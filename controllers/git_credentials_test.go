@@ -0,0 +1,60 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGetMissingGitCredentials(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		secret      string
+		wantMissing bool
+	}{
+		{
+			name:        "anonymous clone allowed",
+			wantMissing: false,
+		},
+		{
+			name:        "anonymous clone explicitly allowed",
+			annotations: map[string]string{RequireGitCredentialsAnnotationName: "false"},
+			wantMissing: false,
+		},
+		{
+			name:        "credentials required, but no git Secret",
+			annotations: map[string]string{RequireGitCredentialsAnnotationName: "true"},
+			wantMissing: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := getGitSourceComponent(tt.annotations, "")
+			component.Spec.Secret = tt.secret
+
+			got, err := (&ComponentBuildReconciler{}).getMissingGitCredentials(context.TODO(), component)
+			if err != nil {
+				t.Fatalf("getMissingGitCredentials() error = %v", err)
+			}
+			if (got != "") != tt.wantMissing {
+				t.Errorf("getMissingGitCredentials() = %q, want missing %v", got, tt.wantMissing)
+			}
+		})
+	}
+}
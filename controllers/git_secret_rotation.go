@@ -0,0 +1,164 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+// Checksum of the data of the git Secret of the component at the time it was observed last time
+const GitSecretChecksumAnnotationName = "build.appstudio.openshift.io/git-secret-checksum"
+
+// GitSecretRotationPolicy defines how components react to rotation of the git Secret they use,
+// which is often shared by all components of a namespace.
+type GitSecretRotationPolicy string
+
+const (
+	// GitSecretRotationPolicyNone does not watch git Secrets
+	GitSecretRotationPolicyNone GitSecretRotationPolicy = "None"
+	// GitSecretRotationPolicyAnnotate annotates the rotated Secret for Tekton again and records the rotation in the components
+	GitSecretRotationPolicyAnnotate GitSecretRotationPolicy = "Annotate"
+	// GitSecretRotationPolicyRebuild annotates the rotated Secret and rebuilds the components which have been built already
+	GitSecretRotationPolicyRebuild GitSecretRotationPolicy = "Rebuild"
+)
+
+// ParseGitSecretRotationPolicy returns the git Secret rotation policy with the given name.
+// Empty name means the default None policy.
+func ParseGitSecretRotationPolicy(name string) (GitSecretRotationPolicy, error) {
+	switch policy := GitSecretRotationPolicy(name); policy {
+	case "":
+		return GitSecretRotationPolicyNone, nil
+	case GitSecretRotationPolicyNone, GitSecretRotationPolicyAnnotate, GitSecretRotationPolicyRebuild:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown git Secret rotation policy %q, expected one of %s, %s, %s", name,
+			GitSecretRotationPolicyNone, GitSecretRotationPolicyAnnotate, GitSecretRotationPolicyRebuild)
+	}
+}
+
+func (p GitSecretRotationPolicy) watchesSecrets() bool {
+	return p == GitSecretRotationPolicyAnnotate || p == GitSecretRotationPolicyRebuild
+}
+
+// getGitSecretChecksum returns checksum of the credentials in the Secret. Metadata changes, e.g. annotations, do not change it.
+func getGitSecretChecksum(secret corev1.Secret) string {
+	// Map keys are sorted by the JSON encoder
+	data, _ := json.Marshal(secret.Data)
+	return getChecksum(append([]byte(secret.Type), data...))
+}
+
+// getGitSecretComponents returns the components in the namespace of the Secret which use it as their git Secret.
+// The cached Secret is dropped, so the components read its rotated version.
+func (r *ComponentBuildReconciler) getGitSecretComponents(object client.Object) []reconcile.Request {
+	components := &appstudiov1alpha1.ComponentList{}
+	if err := r.Client.List(context.Background(), components, client.InNamespace(object.GetNamespace())); err != nil {
+		r.Log.Error(err, fmt.Sprintf("Failed to list components in namespace %s", object.GetNamespace()))
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, component := range components.Items {
+		if component.Spec.Secret != object.GetName() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: component.Name, Namespace: component.Namespace},
+		})
+	}
+	if len(requests) > 0 {
+		r.secretCache.Invalidate(types.NamespacedName{Name: object.GetName(), Namespace: object.GetNamespace()})
+	}
+	return requests
+}
+
+// handleGitSecretRotation records the checksum of the git Secret of the component in its annotation.
+// If the Secret has been rotated since the checksum was recorded, the Secret is annotated for Tekton again
+// and the component is rebuilt if the policy requests it. The component is updated in the cluster only if changed.
+func (r *ComponentBuildReconciler) handleGitSecretRotation(ctx context.Context, log logr.Logger, component *appstudiov1alpha1.Component) error {
+	secretName := component.Spec.Secret
+	if !r.GitSecretRotationPolicy.watchesSecrets() || secretName == "" {
+		return nil
+	}
+
+	secret := corev1.Secret{}
+	if err := r.secretCache.Get(ctx, r.NonCachingClient, types.NamespacedName{Name: secretName, Namespace: component.Namespace}, &secret); err != nil {
+		if errors.IsNotFound(err) {
+			// Missing Secret is reported when the build is submitted
+			return nil
+		}
+		return err
+	}
+
+	checksum := getGitSecretChecksum(secret)
+	recordedChecksum := component.Annotations[GitSecretChecksumAnnotationName]
+	if checksum == recordedChecksum {
+		return nil
+	}
+	if component.Annotations == nil {
+		component.Annotations = make(map[string]string)
+	}
+	component.Annotations[GitSecretChecksumAnnotationName] = checksum
+
+	// The first observed version of the Secret is not a rotation
+	if recordedChecksum != "" {
+		if err := r.annotateGitSecret(ctx, *component, &secret); err != nil {
+			return err
+		}
+		if r.GitSecretRotationPolicy == GitSecretRotationPolicyRebuild && component.Annotations[InitialBuildAnnotationName] == "true" {
+			// Allow the build to be submitted again with the rotated credentials
+			component.Annotations[InitialBuildAnnotationName] = "false"
+			component.Annotations[BuildTriggerAnnotationName] = BuildTriggerReasonGitSecretRotation
+			log.Info(fmt.Sprintf("Git Secret %s of component %s has been rotated, rebuilding the component", secretName, component.Name))
+		} else {
+			log.Info(fmt.Sprintf("Git Secret %s of component %s has been rotated", secretName, component.Name))
+		}
+	}
+	return r.Client.Update(ctx, component)
+}
+
+// annotateGitSecret sets the git host of the component in the Secret annotation Tekton selects git credentials by.
+func (r *ComponentBuildReconciler) annotateGitSecret(ctx context.Context, component appstudiov1alpha1.Component, gitSecret *corev1.Secret) error {
+	if gitSecret.Annotations == nil {
+		gitSecret.Annotations = map[string]string{}
+	}
+
+	gitHost, _ := getGitProvider(getGitSource(component).URL)
+
+	// Override the annotation if it was set for another git host.
+	if gitSecret.Annotations["tekton.dev/git-0"] == gitHost {
+		return nil
+	}
+	gitSecret.Annotations["tekton.dev/git-0"] = gitHost
+	if err := r.Client.Update(ctx, gitSecret); err != nil {
+		// The cached Secret might be outdated
+		r.secretCache.Invalidate(types.NamespacedName{Name: gitSecret.Name, Namespace: gitSecret.Namespace})
+		return err
+	}
+	r.secretCache.Set(gitSecret)
+	return nil
+}
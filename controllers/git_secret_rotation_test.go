@@ -0,0 +1,220 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+// gitSecretClient serves the git Secret and the components of its namespace and records their updates
+type gitSecretClient struct {
+	client.Client
+	secret            corev1.Secret
+	components        []appstudiov1alpha1.Component
+	updatedComponents []appstudiov1alpha1.Component
+	updatedSecrets    []corev1.Secret
+}
+
+func (c *gitSecretClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	c.secret.DeepCopyInto(obj.(*corev1.Secret))
+	return nil
+}
+
+func (c *gitSecretClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	list.(*appstudiov1alpha1.ComponentList).Items = c.components
+	return nil
+}
+
+func (c *gitSecretClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	switch object := obj.(type) {
+	case *appstudiov1alpha1.Component:
+		c.updatedComponents = append(c.updatedComponents, *object.DeepCopy())
+	case *corev1.Secret:
+		c.updatedSecrets = append(c.updatedSecrets, *object.DeepCopy())
+		c.secret = *object.DeepCopy()
+	}
+	return nil
+}
+
+func getSharedGitSecret(token string) corev1.Secret {
+	return corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "shared-git-secret",
+			Namespace:   "my-namespace",
+			Annotations: map[string]string{"tekton.dev/git-0": "https://github.com"},
+		},
+		Type: corev1.SecretTypeBasicAuth,
+		Data: map[string][]byte{"username": []byte("git"), "password": []byte(token)},
+	}
+}
+
+func TestParseGitSecretRotationPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    GitSecretRotationPolicy
+		wantErr bool
+	}{
+		{name: "", want: GitSecretRotationPolicyNone},
+		{name: "None", want: GitSecretRotationPolicyNone},
+		{name: "Annotate", want: GitSecretRotationPolicyAnnotate},
+		{name: "Rebuild", want: GitSecretRotationPolicyRebuild},
+		{name: "rebuild", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGitSecretRotationPolicy(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGitSecretRotationPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseGitSecretRotationPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetGitSecretChecksum(t *testing.T) {
+	secret := getSharedGitSecret("token")
+	checksum := getGitSecretChecksum(secret)
+
+	secret.Annotations["tekton.dev/git-0"] = "https://gitlab.com"
+	if getGitSecretChecksum(secret) != checksum {
+		t.Errorf("getGitSecretChecksum() changed with the Secret annotations")
+	}
+	if getGitSecretChecksum(getSharedGitSecret("rotated-token")) == checksum {
+		t.Errorf("getGitSecretChecksum() did not change with the Secret data")
+	}
+}
+
+func TestGetGitSecretComponents(t *testing.T) {
+	secret := getSharedGitSecret("token")
+	var components []appstudiov1alpha1.Component
+	for _, name := range []string{"frontend", "backend", "other"} {
+		component := getGitSourceComponent(nil, "version: 2.2.0")
+		component.Name = name
+		component.Spec.Secret = secret.Name
+		components = append(components, component)
+	}
+	components[2].Spec.Secret = "other-git-secret"
+
+	cache := newSecretCache(nil, time.Minute)
+	cache.Set(&secret)
+	r := &ComponentBuildReconciler{
+		Client:      &gitSecretClient{components: components},
+		Log:         logr.Discard(),
+		secretCache: cache,
+	}
+
+	got := r.getGitSecretComponents(&secret)
+	if len(got) != 2 || got[0].Name != "frontend" || got[1].Name != "backend" {
+		t.Errorf("getGitSecretComponents() = %v, want frontend and backend", got)
+	}
+	if _, cached := cache.secrets[types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}]; cached {
+		t.Errorf("getGitSecretComponents() kept the rotated Secret in the cache")
+	}
+}
+
+func TestHandleGitSecretRotation(t *testing.T) {
+	tests := []struct {
+		name             string
+		policy           GitSecretRotationPolicy
+		wantInitialBuild string
+	}{
+		{name: "annotate", policy: GitSecretRotationPolicyAnnotate, wantInitialBuild: "true"},
+		{name: "rebuild", policy: GitSecretRotationPolicyRebuild, wantInitialBuild: "false"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := &gitSecretClient{secret: getSharedGitSecret("token")}
+			r := &ComponentBuildReconciler{Client: cli, NonCachingClient: cli, GitSecretRotationPolicy: tt.policy}
+
+			var components []appstudiov1alpha1.Component
+			for _, name := range []string{"frontend", "backend"} {
+				component := getGitSourceComponent(map[string]string{InitialBuildAnnotationName: "true"}, "version: 2.2.0")
+				component.Name = name
+				component.Spec.Secret = "shared-git-secret"
+				components = append(components, component)
+			}
+
+			// The first observed version of the Secret is recorded only
+			for i := range components {
+				if err := r.handleGitSecretRotation(context.TODO(), logr.Discard(), &components[i]); err != nil {
+					t.Fatalf("handleGitSecretRotation() error = %v", err)
+				}
+				if components[i].Annotations[InitialBuildAnnotationName] != "true" {
+					t.Errorf("handleGitSecretRotation() requested rebuild of %s before rotation", components[i].Name)
+				}
+			}
+			if len(cli.updatedComponents) != 2 || len(cli.updatedSecrets) != 0 {
+				t.Fatalf("handleGitSecretRotation() updated %d components and %d Secrets, want 2 components", len(cli.updatedComponents), len(cli.updatedSecrets))
+			}
+
+			// Unchanged Secret does not update the components
+			if err := r.handleGitSecretRotation(context.TODO(), logr.Discard(), &components[0]); err != nil {
+				t.Fatalf("handleGitSecretRotation() error = %v", err)
+			}
+			if len(cli.updatedComponents) != 2 {
+				t.Errorf("handleGitSecretRotation() updated component with unchanged Secret")
+			}
+
+			// The rotated Secret has lost its annotation, it is annotated again for each dependent component
+			cli.secret = getSharedGitSecret("rotated-token")
+			delete(cli.secret.Annotations, "tekton.dev/git-0")
+			for i := range components {
+				if err := r.handleGitSecretRotation(context.TODO(), logr.Discard(), &components[i]); err != nil {
+					t.Fatalf("handleGitSecretRotation() error = %v", err)
+				}
+				if got := components[i].Annotations[InitialBuildAnnotationName]; got != tt.wantInitialBuild {
+					t.Errorf("handleGitSecretRotation() %s = %s for %s, want %s", InitialBuildAnnotationName, got, components[i].Name, tt.wantInitialBuild)
+				}
+				if got := components[i].Annotations[GitSecretChecksumAnnotationName]; got != getGitSecretChecksum(cli.secret) {
+					t.Errorf("handleGitSecretRotation() recorded checksum %s for %s, want the rotated one", got, components[i].Name)
+				}
+			}
+			if len(cli.updatedComponents) != 4 {
+				t.Errorf("handleGitSecretRotation() updated %d components, want 4", len(cli.updatedComponents))
+			}
+			if len(cli.updatedSecrets) != 1 || cli.secret.Annotations["tekton.dev/git-0"] != "https://github.com" {
+				t.Errorf("handleGitSecretRotation() updated Secrets %v, want it annotated once", cli.updatedSecrets)
+			}
+		})
+	}
+}
+
+func TestHandleGitSecretRotationDisabled(t *testing.T) {
+	cli := &gitSecretClient{secret: getSharedGitSecret("token")}
+	r := &ComponentBuildReconciler{Client: cli, NonCachingClient: cli, GitSecretRotationPolicy: GitSecretRotationPolicyNone}
+	component := getGitSourceComponent(nil, "version: 2.2.0")
+	component.Spec.Secret = "shared-git-secret"
+
+	if err := r.handleGitSecretRotation(context.TODO(), logr.Discard(), &component); err != nil {
+		t.Fatalf("handleGitSecretRotation() error = %v", err)
+	}
+	if len(cli.updatedComponents) != 0 {
+		t.Errorf("handleGitSecretRotation() updated component while the rotation is not handled")
+	}
+}
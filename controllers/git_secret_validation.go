@@ -0,0 +1,114 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// InvalidGitSecretConditionType is set on components whose git Secret has no usable credentials
+	InvalidGitSecretConditionType = "InvalidGitSecret"
+
+	InvalidGitSecretReasonInvalid = "GitSecretInvalid"
+	InvalidGitSecretReasonValid   = "GitSecretValid"
+)
+
+// getInvalidGitSecret returns the reason why the git Secret of the component has no usable credentials,
+// or empty string if the component has no git Secret or the Secret is valid.
+// Missing Secret is not reported here, it fails the build submission.
+func (r *ComponentBuildReconciler) getInvalidGitSecret(ctx context.Context, component appstudiov1alpha1.Component) (string, error) {
+	if component.Spec.Secret == "" {
+		return "", nil
+	}
+
+	gitSecret := &corev1.Secret{}
+	if err := r.secretCache.Get(ctx, r.NonCachingClient, types.NamespacedName{Name: component.Spec.Secret, Namespace: component.Namespace}, gitSecret); err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if err := validateGitSecret(gitSecret); err != nil {
+		return fmt.Sprintf("Git Secret %s can't be used to clone the component repository: %v", gitSecret.Name, err), nil
+	}
+	return "", nil
+}
+
+// validateGitSecret checks that the git Secret has the keys required by its type.
+// Secrets of other types than basic-auth and ssh-auth must have some data only.
+func validateGitSecret(gitSecret *corev1.Secret) error {
+	switch gitSecret.Type {
+	case corev1.SecretTypeBasicAuth:
+		var missingKeys []string
+		for _, key := range []string{corev1.BasicAuthUsernameKey, corev1.BasicAuthPasswordKey} {
+			if len(gitSecret.Data[key]) == 0 {
+				missingKeys = append(missingKeys, key)
+			}
+		}
+		if len(missingKeys) > 0 {
+			return fmt.Errorf("%s Secret has empty %s", gitSecret.Type, strings.Join(missingKeys, " and "))
+		}
+	case corev1.SecretTypeSSHAuth:
+		privateKey := gitSecret.Data[corev1.SSHAuthPrivateKey]
+		if len(privateKey) == 0 {
+			return fmt.Errorf("%s Secret has empty %s", gitSecret.Type, corev1.SSHAuthPrivateKey)
+		}
+		if block, _ := pem.Decode(privateKey); block == nil || !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+			return fmt.Errorf("%s of %s Secret is not a PEM encoded private key", corev1.SSHAuthPrivateKey, gitSecret.Type)
+		}
+	default:
+		if len(gitSecret.Data) == 0 && len(gitSecret.StringData) == 0 {
+			return fmt.Errorf("the Secret has no data")
+		}
+	}
+	return nil
+}
+
+func getInvalidGitSecretCondition(message string) metav1.Condition {
+	return metav1.Condition{
+		Type:    InvalidGitSecretConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  InvalidGitSecretReasonInvalid,
+		Message: message,
+	}
+}
+
+// clearInvalidGitSecretCondition marks the git Secret of the component which was invalid as fixed.
+func clearInvalidGitSecretCondition(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) error {
+	if !meta.IsStatusConditionTrue(component.Status.Conditions, InvalidGitSecretConditionType) {
+		return nil
+	}
+	return setComponentCondition(ctx, cli, component, metav1.Condition{
+		Type:    InvalidGitSecretConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  InvalidGitSecretReasonValid,
+		Message: "Git Secret has usable credentials",
+	})
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func getTestSSHPrivateKey(t *testing.T) []byte {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	privateKeyDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyDER})
+}
+
+func TestValidateGitSecret(t *testing.T) {
+	tests := []struct {
+		name       string
+		secretType corev1.SecretType
+		data       map[string][]byte
+		wantErr    bool
+	}{
+		{
+			name:       "valid basic-auth",
+			secretType: corev1.SecretTypeBasicAuth,
+			data:       map[string][]byte{"username": []byte("git"), "password": []byte("token")},
+		},
+		{
+			name:       "empty basic-auth",
+			secretType: corev1.SecretTypeBasicAuth,
+			data:       map[string][]byte{},
+			wantErr:    true,
+		},
+		{
+			name:       "basic-auth without password",
+			secretType: corev1.SecretTypeBasicAuth,
+			data:       map[string][]byte{"username": []byte("git"), "password": []byte("")},
+			wantErr:    true,
+		},
+		{
+			name:       "valid ssh-auth",
+			secretType: corev1.SecretTypeSSHAuth,
+			data:       map[string][]byte{"ssh-privatekey": getTestSSHPrivateKey(t)},
+		},
+		{
+			name:       "empty ssh-auth",
+			secretType: corev1.SecretTypeSSHAuth,
+			wantErr:    true,
+		},
+		{
+			name:       "malformed ssh-auth",
+			secretType: corev1.SecretTypeSSHAuth,
+			data:       map[string][]byte{"ssh-privatekey": []byte("ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAI public key")},
+			wantErr:    true,
+		},
+		{
+			name:       "valid opaque",
+			secretType: corev1.SecretTypeOpaque,
+			data:       map[string][]byte{"token": []byte("token")},
+		},
+		{
+			name:       "empty opaque",
+			secretType: corev1.SecretTypeOpaque,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitSecret := &corev1.Secret{Type: tt.secretType, Data: tt.data}
+			if err := validateGitSecret(gitSecret); (err != nil) != tt.wantErr {
+				t.Errorf("validateGitSecret() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
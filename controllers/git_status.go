@@ -0,0 +1,426 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// DefaultGitStatusSecretName is the default name of the Secret in the component namespace
+	// with the git provider token used to report commit statuses
+	DefaultGitStatusSecretName = "git-status-token"
+	// Data key within the git status Secret that holds the token
+	GitStatusSecretTokenKey = "token"
+	// Set to "true" on the build PipelineRun once its outcome has been reported to the git provider,
+	// or to "false" once the report failed and is not retried anymore
+	GitStatusReportedAnnotationName = "build.appstudio.openshift.io/git-status-reported"
+	// Number of failed attempts to report the outcome of the build PipelineRun
+	GitStatusAttemptsAnnotationName = "build.appstudio.openshift.io/git-status-attempts"
+	// RFC 3339 time of the next attempt to report the outcome of the build PipelineRun after a failed one
+	GitStatusNextAttemptAnnotationName = "build.appstudio.openshift.io/git-status-next-attempt"
+
+	// Prefix of the commit status context, the component name is appended
+	gitStatusContextPrefix = "appstudio-build/"
+
+	// Failed reports are retried with doubled interval, until the number of attempts is reached
+	maxGitStatusAttempts   = 3
+	gitStatusRetryInterval = time.Minute
+)
+
+// Commit states reported to git providers
+const (
+	GitCommitStateSuccess = "success"
+	GitCommitStateFailure = "failure"
+)
+
+// Names of the build pipeline results with the built commit SHA
+var commitPipelineResultNames = []string{"commit", "CHAINS-GIT_COMMIT"}
+
+var commitSHARegexp = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// GitCommitStatus is the build outcome reported for the built commit.
+type GitCommitStatus struct {
+	// RepositoryURL is the git URL of the component, e.g. https://github.com/owner/repository
+	RepositoryURL string
+	CommitSHA     string
+	// State is one of the GitCommitState values
+	State string
+	// Context distinguishes statuses of different components built from the same repository
+	Context     string
+	Description string
+}
+
+// GitStatusReporter posts commit statuses to a git provider.
+type GitStatusReporter interface {
+	ReportStatus(ctx context.Context, token string, status GitCommitStatus) error
+}
+
+// reportGitStatus posts the outcome of the completed build PipelineRun as the status of the built commit.
+// The report is recorded in the PipelineRun annotation to avoid duplicate reports.
+// Builds of unknown commits and namespaces without the git status Secret are skipped.
+// A failed report is retried after the returned delay until maxGitStatusAttempts is reached,
+// reports rejected by the git provider or of invalid repository URLs are not retried.
+// The failed attempts are recorded in the PipelineRun annotations, so the retries are bounded across restarts.
+func (r *BuildPipelineRunReconciler) reportGitStatus(ctx context.Context, component appstudiov1alpha1.Component, pipelineRun tektonapi.PipelineRun) (time.Duration, error) {
+	log := r.Log.WithValues("Namespace", component.Namespace, "Component", component.Name)
+
+	if pipelineRun.Annotations[GitStatusReportedAnnotationName] != "" {
+		return 0, nil
+	}
+	status, ok := getGitCommitStatus(component, pipelineRun)
+	if !ok {
+		return 0, nil
+	}
+	now := time.Now()
+	if nextAttempt, err := time.Parse(time.RFC3339, pipelineRun.Annotations[GitStatusNextAttemptAnnotationName]); err == nil && now.Before(nextAttempt) {
+		return nextAttempt.Sub(now), nil
+	}
+
+	secretName := r.GitStatusSecretName
+	if secretName == "" {
+		secretName = DefaultGitStatusSecretName
+	}
+	var reportErr error
+	secret := &corev1.Secret{}
+	if err := r.NonCachingClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: component.Namespace}, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return 0, nil
+		}
+		reportErr = err
+	} else if token := string(secret.Data[GitStatusSecretTokenKey]); token == "" {
+		reportErr = &permanentGitStatusError{fmt.Errorf("git status Secret %s has no %s key", secretName, GitStatusSecretTokenKey)}
+	} else {
+		reportErr = r.GitStatusReporter.ReportStatus(ctx, token, status)
+	}
+
+	if pipelineRun.Annotations == nil {
+		pipelineRun.Annotations = map[string]string{}
+	}
+	var retryAfter time.Duration
+	if reportErr == nil {
+		log.Info(fmt.Sprintf("Reported %s status of commit %s for build %s", status.State, status.CommitSHA, pipelineRun.Name))
+		pipelineRun.Annotations[GitStatusReportedAnnotationName] = "true"
+		delete(pipelineRun.Annotations, GitStatusAttemptsAnnotationName)
+		delete(pipelineRun.Annotations, GitStatusNextAttemptAnnotationName)
+	} else {
+		attempts, _ := strconv.Atoi(pipelineRun.Annotations[GitStatusAttemptsAnnotationName])
+		attempts++
+		pipelineRun.Annotations[GitStatusAttemptsAnnotationName] = strconv.Itoa(attempts)
+		if _, permanent := reportErr.(*permanentGitStatusError); permanent || attempts >= maxGitStatusAttempts {
+			log.Info(fmt.Sprintf("Giving up reporting git commit status of build %s after %d attempts", pipelineRun.Name, attempts))
+			pipelineRun.Annotations[GitStatusReportedAnnotationName] = "false"
+			delete(pipelineRun.Annotations, GitStatusNextAttemptAnnotationName)
+		} else {
+			retryAfter = gitStatusRetryInterval << (attempts - 1)
+			pipelineRun.Annotations[GitStatusNextAttemptAnnotationName] = now.Add(retryAfter).Format(time.RFC3339)
+		}
+	}
+	if err := r.Client.Update(ctx, &pipelineRun); err != nil {
+		if reportErr == nil {
+			// The report is repeated on the next reconcile, git providers keep the latest status of each context
+			return gitStatusRetryInterval, err
+		}
+		return retryAfter, fmt.Errorf("%v, unable to record the failed attempt: %v", reportErr, err)
+	}
+	return retryAfter, reportErr
+}
+
+// permanentGitStatusError is a report failure which does not go away when the report is retried,
+// e.g. an invalid repository URL or a token rejected by the git provider.
+type permanentGitStatusError struct {
+	error
+}
+
+// getGitCommitStatus returns the commit status to report for the PipelineRun.
+// Returns false if the build has not completed or the built commit is unknown.
+func getGitCommitStatus(component appstudiov1alpha1.Component, pipelineRun tektonapi.PipelineRun) (GitCommitStatus, bool) {
+	gitSource := getGitSource(component)
+	if gitSource == nil {
+		return GitCommitStatus{}, false
+	}
+
+	var state, description string
+	switch getBuildState(pipelineRun) {
+	case BuildStateSucceeded:
+		state = GitCommitStateSuccess
+		description = fmt.Sprintf("Build %s succeeded", pipelineRun.Name)
+	case BuildStateFailed:
+		state = GitCommitStateFailure
+		description = fmt.Sprintf("Build %s failed", pipelineRun.Name)
+	default:
+		return GitCommitStatus{}, false
+	}
+
+	commitSHA := getBuiltCommitSHA(pipelineRun)
+	if commitSHA == "" {
+		return GitCommitStatus{}, false
+	}
+
+	return GitCommitStatus{
+		RepositoryURL: gitSource.URL,
+		CommitSHA:     commitSHA,
+		State:         state,
+		Context:       gitStatusContextPrefix + component.Name,
+		Description:   description,
+	}, true
+}
+
+// getBuiltCommitSHA returns the commit SHA from the build pipeline results or the revision parameter
+// if it is a full commit SHA. Returns empty string if the built commit is unknown, e.g. a branch has been built.
+func getBuiltCommitSHA(pipelineRun tektonapi.PipelineRun) string {
+	for _, resultName := range commitPipelineResultNames {
+		for _, result := range pipelineRun.Status.PipelineResults {
+			if result.Name == resultName && commitSHARegexp.MatchString(result.Value) {
+				return result.Value
+			}
+		}
+	}
+	if revision := getPipelineRunParam(pipelineRun, "revision"); commitSHARegexp.MatchString(revision) {
+		return revision
+	}
+	return ""
+}
+
+// GitProviderStatusReporter reports commit statuses with the reporter of the git provider of the repository.
+// The provider is detected from the repository host, unknown hosts are considered GitHub Enterprise.
+type GitProviderStatusReporter struct {
+	GitHub    GitStatusReporter
+	GitLab    GitStatusReporter
+	Bitbucket GitStatusReporter
+}
+
+// NewGitProviderStatusReporter returns the reporter for GitHub, GitLab and Bitbucket repositories.
+// The internal hosts are self-hosted git servers allowed to resolve to private addresses.
+func NewGitProviderStatusReporter(internalHosts []string) *GitProviderStatusReporter {
+	return &GitProviderStatusReporter{
+		GitHub:    &GitHubStatusReporter{InternalHosts: internalHosts},
+		GitLab:    &GitLabStatusReporter{InternalHosts: internalHosts},
+		Bitbucket: &BitbucketStatusReporter{},
+	}
+}
+
+func (r *GitProviderStatusReporter) ReportStatus(ctx context.Context, token string, status GitCommitStatus) error {
+	gitProvider, err := getGitProvider(status.RepositoryURL)
+	if err != nil {
+		return &permanentGitStatusError{err}
+	}
+	switch {
+	case strings.Contains(gitProvider, "gitlab"):
+		return r.GitLab.ReportStatus(ctx, token, status)
+	case strings.Contains(gitProvider, "bitbucket"):
+		return r.Bitbucket.ReportStatus(ctx, token, status)
+	default:
+		return r.GitHub.ReportStatus(ctx, token, status)
+	}
+}
+
+// GitHubStatusReporter posts commit statuses via GitHub API.
+type GitHubStatusReporter struct {
+	// APIURL is the GitHub API endpoint, empty means https://api.github.com for github.com and /api/v3 of other hosts
+	APIURL string
+	// InternalHosts are GitHub Enterprise hosts allowed to resolve to private addresses,
+	// *.domain allows subdomains of the domain
+	InternalHosts []string
+	HTTPClient    *http.Client
+}
+
+type gitHubStatusRequest struct {
+	State       string `json:"state"`
+	Context     string `json:"context"`
+	Description string `json:"description"`
+}
+
+func (r *GitHubStatusReporter) ReportStatus(ctx context.Context, token string, status GitCommitStatus) error {
+	u, repository, err := splitGitRepositoryURL(status.RepositoryURL)
+	if err != nil {
+		return err
+	}
+	apiURL := r.APIURL
+	if apiURL == "" {
+		apiURL = u.Scheme + "://" + u.Host + "/api/v3"
+		if u.Host == "github.com" {
+			apiURL = "https://api.github.com"
+		}
+	}
+
+	request := gitHubStatusRequest{
+		State:       status.State,
+		Context:     status.Context,
+		Description: status.Description,
+	}
+	statusURL := fmt.Sprintf("%s/repos/%s/statuses/%s", apiURL, repository, status.CommitSHA)
+	httpClient := getGitStatusHTTPClient(r.HTTPClient, r.APIURL, u, r.InternalHosts)
+	return postGitStatus(ctx, httpClient, statusURL, map[string]string{"Authorization": "token " + token}, request)
+}
+
+// GitLabStatusReporter posts commit statuses via GitLab API.
+type GitLabStatusReporter struct {
+	// APIURL is the GitLab API endpoint, empty means /api/v4 of the repository host
+	APIURL string
+	// InternalHosts are self-hosted GitLab hosts allowed to resolve to private addresses,
+	// *.domain allows subdomains of the domain
+	InternalHosts []string
+	HTTPClient    *http.Client
+}
+
+type gitLabStatusRequest struct {
+	State       string `json:"state"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (r *GitLabStatusReporter) ReportStatus(ctx context.Context, token string, status GitCommitStatus) error {
+	u, repository, err := splitGitRepositoryURL(status.RepositoryURL)
+	if err != nil {
+		return err
+	}
+	apiURL := r.APIURL
+	if apiURL == "" {
+		apiURL = u.Scheme + "://" + u.Host + "/api/v4"
+	}
+
+	state := "success"
+	if status.State == GitCommitStateFailure {
+		state = "failed"
+	}
+	request := gitLabStatusRequest{
+		State:       state,
+		Name:        status.Context,
+		Description: status.Description,
+	}
+	statusURL := fmt.Sprintf("%s/projects/%s/statuses/%s", apiURL, url.PathEscape(repository), status.CommitSHA)
+	httpClient := getGitStatusHTTPClient(r.HTTPClient, r.APIURL, u, r.InternalHosts)
+	return postGitStatus(ctx, httpClient, statusURL, map[string]string{"PRIVATE-TOKEN": token}, request)
+}
+
+// BitbucketStatusReporter posts commit statuses via Bitbucket Cloud API.
+type BitbucketStatusReporter struct {
+	// APIURL is the Bitbucket API endpoint, empty means https://api.bitbucket.org/2.0
+	APIURL     string
+	HTTPClient *http.Client
+}
+
+type bitbucketStatusRequest struct {
+	State       string `json:"state"`
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+}
+
+func (r *BitbucketStatusReporter) ReportStatus(ctx context.Context, token string, status GitCommitStatus) error {
+	_, repository, err := splitGitRepositoryURL(status.RepositoryURL)
+	if err != nil {
+		return err
+	}
+	apiURL := r.APIURL
+	if apiURL == "" {
+		apiURL = "https://api.bitbucket.org/2.0"
+	}
+
+	state := "SUCCESSFUL"
+	if status.State == GitCommitStateFailure {
+		state = "FAILED"
+	}
+	request := bitbucketStatusRequest{
+		State:       state,
+		Key:         status.Context,
+		Name:        status.Context,
+		Description: status.Description,
+		// Bitbucket requires a link for each status
+		URL: status.RepositoryURL,
+	}
+	statusURL := fmt.Sprintf("%s/repositories/%s/commit/%s/statuses/build", apiURL, repository, status.CommitSHA)
+	httpClient := getGitStatusHTTPClient(r.HTTPClient, r.APIURL, nil, nil)
+	return postGitStatus(ctx, httpClient, statusURL, map[string]string{"Authorization": "Bearer " + token}, request)
+}
+
+// splitGitRepositoryURL returns the parsed repository URL and the repository path without .git suffix, e.g. owner/repository.
+// Only http and https URLs are supported, the API endpoint of scp-like URLs is unknown.
+func splitGitRepositoryURL(gitURL string) (*url.URL, string, error) {
+	u, err := url.Parse(gitURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, "", &permanentGitStatusError{fmt.Errorf("invalid git repository URL %s", gitURL)}
+	}
+	repository := strings.Trim(strings.TrimSuffix(u.Path, ".git"), "/")
+	if !strings.Contains(repository, "/") {
+		return nil, "", &permanentGitStatusError{fmt.Errorf("invalid git repository URL %s, owner and repository expected", gitURL)}
+	}
+	return u, repository, nil
+}
+
+// getGitStatusHTTPClient returns the client for requests to the git provider API.
+// The API endpoint derived from the repository URL is set by tenants, so internal addresses are refused,
+// unless the repository host is one of the internal hosts listed by the cluster operator.
+// API endpoints configured by the cluster operator may be in private networks.
+func getGitStatusHTTPClient(httpClient *http.Client, apiURL string, repositoryURL *url.URL, internalHosts []string) *http.Client {
+	if httpClient != nil {
+		return httpClient
+	}
+	if apiURL != "" || (repositoryURL != nil && isAllowedHost(repositoryURL.Hostname(), internalHosts)) {
+		return defaultHTTPClient
+	}
+	return tenantHTTPClient
+}
+
+func postGitStatus(ctx context.Context, httpClient *http.Client, statusURL string, headers map[string]string, request interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, statusURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err := fmt.Errorf("unexpected response status %d from %s", resp.StatusCode, req.URL)
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			// Revoked tokens, missing permissions or unknown repositories are not fixed by retries
+			return &permanentGitStatusError{err}
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,471 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const testCommitSHA = "0123456789abcdef0123456789abcdef01234567"
+
+func getGitStatusTestPipelineRun(status corev1.ConditionStatus, commitSHA string) tektonapi.PipelineRun {
+	pipelineRun := getPipelineRunWithSucceededCondition(status)
+	pipelineRun.Name = "my-component-abcde"
+	pipelineRun.Namespace = "my-namespace"
+	if commitSHA != "" {
+		pipelineRun.Status.PipelineResults = []tektonapi.PipelineRunResult{{Name: "commit", Value: commitSHA}}
+	}
+	return pipelineRun
+}
+
+func TestGetGitCommitStatus(t *testing.T) {
+	revisionPipelineRun := getGitStatusTestPipelineRun(corev1.ConditionFalse, "")
+	revisionPipelineRun.Spec.Params = []tektonapi.Param{{Name: "revision", Value: *tektonapi.NewArrayOrString(testCommitSHA)}}
+	branchPipelineRun := getGitStatusTestPipelineRun(corev1.ConditionTrue, "")
+	branchPipelineRun.Spec.Params = []tektonapi.Param{{Name: "revision", Value: *tektonapi.NewArrayOrString("main")}}
+
+	tests := []struct {
+		name        string
+		pipelineRun tektonapi.PipelineRun
+		want        GitCommitStatus
+		wantOk      bool
+	}{
+		{
+			name:        "succeeded build",
+			pipelineRun: getGitStatusTestPipelineRun(corev1.ConditionTrue, testCommitSHA),
+			want: GitCommitStatus{
+				RepositoryURL: "https://github.com/foo/bar",
+				CommitSHA:     testCommitSHA,
+				State:         GitCommitStateSuccess,
+				Context:       "appstudio-build/my-component",
+				Description:   "Build my-component-abcde succeeded",
+			},
+			wantOk: true,
+		},
+		{
+			name:        "failed build of revision",
+			pipelineRun: revisionPipelineRun,
+			want: GitCommitStatus{
+				RepositoryURL: "https://github.com/foo/bar",
+				CommitSHA:     testCommitSHA,
+				State:         GitCommitStateFailure,
+				Context:       "appstudio-build/my-component",
+				Description:   "Build my-component-abcde failed",
+			},
+			wantOk: true,
+		},
+		{
+			name:        "running build",
+			pipelineRun: getGitStatusTestPipelineRun(corev1.ConditionUnknown, testCommitSHA),
+			wantOk:      false,
+		},
+		{
+			name:        "unknown commit",
+			pipelineRun: branchPipelineRun,
+			wantOk:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := getGitCommitStatus(getGitSourceComponent(nil, "version: 2.2.0"), tt.pipelineRun)
+			if ok != tt.wantOk || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getGitCommitStatus() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestGitStatusReporters(t *testing.T) {
+	status := GitCommitStatus{
+		RepositoryURL: "https://example.com/foo/bar.git",
+		CommitSHA:     testCommitSHA,
+		State:         GitCommitStateFailure,
+		Context:       "appstudio-build/my-component",
+		Description:   "Build my-component-abcde failed",
+	}
+
+	var gotPath, gotAuthHeader string
+	var gotBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		gotPath = req.URL.EscapedPath()
+		gotAuthHeader = req.Header.Get("Authorization") + req.Header.Get("PRIVATE-TOKEN")
+		gotBody = map[string]interface{}{}
+		if err := json.NewDecoder(req.Body).Decode(&gotBody); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		reporter       GitStatusReporter
+		wantPath       string
+		wantAuthHeader string
+		wantBody       map[string]interface{}
+	}{
+		{
+			name:           "GitHub",
+			reporter:       &GitHubStatusReporter{APIURL: server.URL},
+			wantPath:       "/repos/foo/bar/statuses/" + testCommitSHA,
+			wantAuthHeader: "token s3cr3t",
+			wantBody: map[string]interface{}{
+				"state":       "failure",
+				"context":     "appstudio-build/my-component",
+				"description": "Build my-component-abcde failed",
+			},
+		},
+		{
+			name:           "GitLab",
+			reporter:       &GitLabStatusReporter{APIURL: server.URL},
+			wantPath:       "/projects/foo%2Fbar/statuses/" + testCommitSHA,
+			wantAuthHeader: "s3cr3t",
+			wantBody: map[string]interface{}{
+				"state":       "failed",
+				"name":        "appstudio-build/my-component",
+				"description": "Build my-component-abcde failed",
+			},
+		},
+		{
+			name:           "Bitbucket",
+			reporter:       &BitbucketStatusReporter{APIURL: server.URL},
+			wantPath:       "/repositories/foo/bar/commit/" + testCommitSHA + "/statuses/build",
+			wantAuthHeader: "Bearer s3cr3t",
+			wantBody: map[string]interface{}{
+				"state":       "FAILED",
+				"key":         "appstudio-build/my-component",
+				"name":        "appstudio-build/my-component",
+				"description": "Build my-component-abcde failed",
+				"url":         "https://example.com/foo/bar.git",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.reporter.ReportStatus(context.TODO(), "s3cr3t", status); err != nil {
+				t.Fatalf("ReportStatus() error = %v", err)
+			}
+			if gotPath != tt.wantPath {
+				t.Errorf("ReportStatus() requested %v, want %v", gotPath, tt.wantPath)
+			}
+			if gotAuthHeader != tt.wantAuthHeader {
+				t.Errorf("ReportStatus() authenticated with %v, want %v", gotAuthHeader, tt.wantAuthHeader)
+			}
+			if !reflect.DeepEqual(gotBody, tt.wantBody) {
+				t.Errorf("ReportStatus() posted %v, want %v", gotBody, tt.wantBody)
+			}
+		})
+	}
+}
+
+// recordingGitStatusReporter records the reported statuses
+type recordingGitStatusReporter struct {
+	name     string
+	reported []string
+	err      error
+}
+
+func (r *recordingGitStatusReporter) ReportStatus(ctx context.Context, token string, status GitCommitStatus) error {
+	r.reported = append(r.reported, r.name+" "+token+" "+status.CommitSHA)
+	return r.err
+}
+
+func TestGitProviderStatusReporter(t *testing.T) {
+	tests := []struct {
+		repositoryURL string
+		want          string
+	}{
+		{repositoryURL: "https://github.com/foo/bar", want: "github"},
+		{repositoryURL: "https://gitlab.com/foo/bar", want: "gitlab"},
+		{repositoryURL: "https://gitlab.example.com/foo/bar", want: "gitlab"},
+		{repositoryURL: "https://bitbucket.org/foo/bar", want: "bitbucket"},
+		{repositoryURL: "https://git.example.com/foo/bar", want: "github"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.repositoryURL, func(t *testing.T) {
+			recorder := &recordingGitStatusReporter{}
+			reporter := &GitProviderStatusReporter{
+				GitHub:    &recordingGitStatusReporter{name: "github"},
+				GitLab:    &recordingGitStatusReporter{name: "gitlab"},
+				Bitbucket: &recordingGitStatusReporter{name: "bitbucket"},
+			}
+			if err := reporter.ReportStatus(context.TODO(), "s3cr3t", GitCommitStatus{RepositoryURL: tt.repositoryURL, CommitSHA: testCommitSHA}); err != nil {
+				t.Fatalf("ReportStatus() error = %v", err)
+			}
+			for _, providerReporter := range []GitStatusReporter{reporter.GitHub, reporter.GitLab, reporter.Bitbucket} {
+				recorder.reported = append(recorder.reported, providerReporter.(*recordingGitStatusReporter).reported...)
+			}
+			want := []string{tt.want + " s3cr3t " + testCommitSHA}
+			if !reflect.DeepEqual(recorder.reported, want) {
+				t.Errorf("ReportStatus() reported %v, want %v", recorder.reported, want)
+			}
+		})
+	}
+}
+
+// gitStatusTestClient returns the given git status Secret and records PipelineRun updates
+type gitStatusTestClient struct {
+	client.Client
+	secret  *corev1.Secret
+	updated []tektonapi.PipelineRun
+}
+
+func (c *gitStatusTestClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if c.secret == nil || c.secret.Name != key.Name {
+		return errors.NewNotFound(schema.GroupResource{Resource: "secrets"}, key.Name)
+	}
+	c.secret.DeepCopyInto(obj.(*corev1.Secret))
+	return nil
+}
+
+func (c *gitStatusTestClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.updated = append(c.updated, *obj.(*tektonapi.PipelineRun))
+	return nil
+}
+
+func TestReportGitStatus(t *testing.T) {
+	component := getGitSourceComponent(nil, "version: 2.2.0")
+	tokenSecret := &corev1.Secret{Data: map[string][]byte{GitStatusSecretTokenKey: []byte("s3cr3t")}}
+	tokenSecret.Name = DefaultGitStatusSecretName
+	emptySecret := &corev1.Secret{}
+	emptySecret.Name = DefaultGitStatusSecretName
+	getPipelineRun := func(annotations map[string]string) tektonapi.PipelineRun {
+		pipelineRun := getGitStatusTestPipelineRun(corev1.ConditionTrue, testCommitSHA)
+		pipelineRun.Annotations = annotations
+		return pipelineRun
+	}
+	transientErr := fmt.Errorf("connection refused")
+	permanentErr := &permanentGitStatusError{fmt.Errorf("unexpected response status 401")}
+
+	tests := []struct {
+		name            string
+		secret          *corev1.Secret
+		pipelineRun     tektonapi.PipelineRun
+		reportErr       error
+		wantReported    bool
+		wantAnnotations map[string]string
+		wantRetry       bool
+		wantErr         bool
+	}{
+		{
+			name:            "completed build",
+			secret:          tokenSecret,
+			pipelineRun:     getPipelineRun(nil),
+			wantReported:    true,
+			wantAnnotations: map[string]string{GitStatusReportedAnnotationName: "true"},
+		},
+		{
+			name:         "reported already",
+			secret:       tokenSecret,
+			pipelineRun:  getPipelineRun(map[string]string{GitStatusReportedAnnotationName: "true"}),
+			wantReported: false,
+		},
+		{
+			name:         "given up already",
+			secret:       tokenSecret,
+			pipelineRun:  getPipelineRun(map[string]string{GitStatusReportedAnnotationName: "false"}),
+			wantReported: false,
+		},
+		{
+			name:         "running build",
+			secret:       tokenSecret,
+			pipelineRun:  getGitStatusTestPipelineRun(corev1.ConditionUnknown, testCommitSHA),
+			wantReported: false,
+		},
+		{
+			name:         "no git status Secret in namespace",
+			pipelineRun:  getPipelineRun(nil),
+			wantReported: false,
+		},
+		{
+			name:            "git status Secret without token",
+			secret:          emptySecret,
+			pipelineRun:     getPipelineRun(nil),
+			wantReported:    false,
+			wantAnnotations: map[string]string{GitStatusReportedAnnotationName: "false", GitStatusAttemptsAnnotationName: "1"},
+			wantErr:         true,
+		},
+		{
+			name:            "transient failure is retried",
+			secret:          tokenSecret,
+			pipelineRun:     getPipelineRun(nil),
+			reportErr:       transientErr,
+			wantReported:    true,
+			wantAnnotations: map[string]string{GitStatusAttemptsAnnotationName: "1"},
+			wantRetry:       true,
+			wantErr:         true,
+		},
+		{
+			name:         "retry is not due yet",
+			secret:       tokenSecret,
+			pipelineRun:  getPipelineRun(map[string]string{GitStatusAttemptsAnnotationName: "1", GitStatusNextAttemptAnnotationName: time.Now().Add(time.Hour).Format(time.RFC3339)}),
+			wantReported: false,
+			wantRetry:    true,
+		},
+		{
+			name:            "transient failure of the last attempt is not retried",
+			secret:          tokenSecret,
+			pipelineRun:     getPipelineRun(map[string]string{GitStatusAttemptsAnnotationName: "2", GitStatusNextAttemptAnnotationName: time.Now().Add(-time.Minute).Format(time.RFC3339)}),
+			reportErr:       transientErr,
+			wantReported:    true,
+			wantAnnotations: map[string]string{GitStatusReportedAnnotationName: "false", GitStatusAttemptsAnnotationName: "3"},
+			wantErr:         true,
+		},
+		{
+			name:            "permanent failure is not retried",
+			secret:          tokenSecret,
+			pipelineRun:     getPipelineRun(nil),
+			reportErr:       permanentErr,
+			wantReported:    true,
+			wantAnnotations: map[string]string{GitStatusReportedAnnotationName: "false", GitStatusAttemptsAnnotationName: "1"},
+			wantErr:         true,
+		},
+		{
+			name:            "retried report succeeds",
+			secret:          tokenSecret,
+			pipelineRun:     getPipelineRun(map[string]string{GitStatusAttemptsAnnotationName: "1", GitStatusNextAttemptAnnotationName: time.Now().Add(-time.Minute).Format(time.RFC3339)}),
+			wantReported:    true,
+			wantAnnotations: map[string]string{GitStatusReportedAnnotationName: "true"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiClient := &gitStatusTestClient{secret: tt.secret}
+			reporter := &recordingGitStatusReporter{err: tt.reportErr}
+			r := &BuildPipelineRunReconciler{
+				Client:            apiClient,
+				NonCachingClient:  apiClient,
+				Log:               logr.Discard(),
+				GitStatusReporter: reporter,
+			}
+
+			retryAfter, err := r.reportGitStatus(context.TODO(), component, tt.pipelineRun)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("reportGitStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (retryAfter > 0) != tt.wantRetry {
+				t.Errorf("reportGitStatus() retry after %v, want retry %v", retryAfter, tt.wantRetry)
+			}
+			if reported := len(reporter.reported) == 1; reported != tt.wantReported {
+				t.Errorf("reportGitStatus() reported %v, want reported %v", reporter.reported, tt.wantReported)
+			}
+			if tt.wantAnnotations == nil {
+				if len(apiClient.updated) != 0 {
+					t.Errorf("reportGitStatus() updated the PipelineRun %v", apiClient.updated[0].Annotations)
+				}
+				return
+			}
+			if len(apiClient.updated) != 1 {
+				t.Fatalf("reportGitStatus() did not record the report in the PipelineRun")
+			}
+			annotations := apiClient.updated[0].Annotations
+			if tt.wantRetry {
+				if _, err := time.Parse(time.RFC3339, annotations[GitStatusNextAttemptAnnotationName]); err != nil {
+					t.Errorf("reportGitStatus() did not record the next attempt: %v", err)
+				}
+				delete(annotations, GitStatusNextAttemptAnnotationName)
+			}
+			if !reflect.DeepEqual(annotations, tt.wantAnnotations) {
+				t.Errorf("reportGitStatus() recorded %v, want %v", annotations, tt.wantAnnotations)
+			}
+		})
+	}
+}
+
+func TestGitStatusReportErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/repos/foo/revoked/statuses/" + testCommitSHA:
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/repos/foo/limited/statuses/" + testCommitSHA:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		repositoryURL string
+		wantPermanent bool
+	}{
+		{name: "revoked token", repositoryURL: "https://github.com/foo/revoked", wantPermanent: true},
+		{name: "rate limit", repositoryURL: "https://github.com/foo/limited", wantPermanent: false},
+		{name: "server error", repositoryURL: "https://github.com/foo/bar", wantPermanent: false},
+		{name: "scp-like URL", repositoryURL: "git@github.com:foo/bar.git", wantPermanent: true},
+		{name: "URL without repository", repositoryURL: "https://github.com/foo", wantPermanent: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reporter := &GitHubStatusReporter{APIURL: server.URL}
+			err := reporter.ReportStatus(context.TODO(), "s3cr3t", GitCommitStatus{RepositoryURL: tt.repositoryURL, CommitSHA: testCommitSHA})
+			if err == nil {
+				t.Fatalf("ReportStatus() succeeded, error expected")
+			}
+			if _, permanent := err.(*permanentGitStatusError); permanent != tt.wantPermanent {
+				t.Errorf("ReportStatus() error = %v, want permanent %v", err, tt.wantPermanent)
+			}
+		})
+	}
+}
+
+func TestGetGitStatusHTTPClient(t *testing.T) {
+	ownClient := &http.Client{}
+	internalHosts := []string{"git.corp.example.com", "*.internal.example.com"}
+
+	tests := []struct {
+		name          string
+		httpClient    *http.Client
+		apiURL        string
+		repositoryURL string
+		want          *http.Client
+	}{
+		{name: "own client", httpClient: ownClient, repositoryURL: "https://github.com/foo/bar", want: ownClient},
+		{name: "configured API endpoint", apiURL: "https://github.corp.example.com/api/v3", repositoryURL: "https://github.com/foo/bar", want: defaultHTTPClient},
+		{name: "public host", repositoryURL: "https://github.com/foo/bar", want: tenantHTTPClient},
+		{name: "host set by tenant", repositoryURL: "https://10.0.0.1/foo/bar", want: tenantHTTPClient},
+		{name: "internal host", repositoryURL: "https://git.corp.example.com/foo/bar", want: defaultHTTPClient},
+		{name: "internal subdomain", repositoryURL: "https://gitlab.internal.example.com/foo/bar", want: defaultHTTPClient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repositoryURL, err := url.Parse(tt.repositoryURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := getGitStatusHTTPClient(tt.httpClient, tt.apiURL, repositoryURL, internalHosts); got != tt.want {
+				t.Errorf("getGitStatusHTTPClient() returned unexpected client for %s", tt.repositoryURL)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// Requests to external services are bounded, so a hung endpoint does not stall a reconcile worker
+	httpClientTimeout = 30 * time.Second
+	httpDialTimeout   = 10 * time.Second
+)
+
+// defaultHTTPClient is used for requests to services configured by the cluster operator.
+var defaultHTTPClient = &http.Client{Timeout: httpClientTimeout}
+
+// tenantHTTPClient is used for requests to URLs set by tenants in annotations.
+// It does not use proxies and refuses to connect to loopback, link-local and private addresses,
+// which are checked after the host name is resolved, including hosts of redirects.
+var tenantHTTPClient = &http.Client{
+	Timeout: httpClientTimeout,
+	Transport: &http.Transport{
+		DialContext:         (&net.Dialer{Timeout: httpDialTimeout, Control: rejectInternalAddress}).DialContext,
+		TLSHandshakeTimeout: httpDialTimeout,
+	},
+}
+
+// Shared address space of carrier-grade NAT, often used for cluster networks
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func rejectInternalAddress(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || isInternalIP(ip) {
+		return fmt.Errorf("connection to internal address %s is not allowed", host)
+	}
+	return nil
+}
+
+// isInternalIP checks whether the IP address belongs to the cluster or its host rather than to an external service.
+func isInternalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		sharedAddressSpace.Contains(ip)
+}
+
+// validateTenantURL checks that the URL set by a tenant is an http or https URL of one of the allowed hosts.
+// Allowed hosts are host names, or domains prefixed with *. which allow their subdomains.
+func validateTenantURL(rawURL string, allowedHosts []string) error {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return fmt.Errorf("http or https URL expected: %q", rawURL)
+	}
+	if !isAllowedHost(parsedURL.Hostname(), allowedHosts) {
+		return fmt.Errorf("host %s is not allowed", parsedURL.Hostname())
+	}
+	return nil
+}
+
+func isAllowedHost(host string, allowedHosts []string) bool {
+	host = strings.ToLower(host)
+	for _, allowedHost := range allowedHosts {
+		allowedHost = strings.ToLower(allowedHost)
+		if host == allowedHost {
+			return true
+		}
+		if strings.HasPrefix(allowedHost, "*.") && strings.HasSuffix(host, allowedHost[1:]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsInternalIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "127.0.0.1", want: true},
+		{ip: "::1", want: true},
+		{ip: "10.96.0.1", want: true},
+		{ip: "172.30.0.1", want: true},
+		{ip: "192.168.1.1", want: true},
+		{ip: "100.64.0.10", want: true},
+		{ip: "169.254.169.254", want: true},
+		{ip: "fe80::1", want: true},
+		{ip: "fd00::1", want: true},
+		{ip: "0.0.0.0", want: true},
+		{ip: "::ffff:127.0.0.1", want: true},
+		{ip: "52.1.2.3", want: false},
+		{ip: "2606:4700::1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := isInternalIP(net.ParseIP(tt.ip)); got != tt.want {
+				t.Errorf("isInternalIP(%s) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTenantURL(t *testing.T) {
+	allowedHosts := []string{"hooks.slack.com", "*.corp.com"}
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{name: "allowed host", url: "https://hooks.slack.com/services/T0/B0/X"},
+		{name: "allowed host in upper case", url: "https://Hooks.Slack.com/services/T0/B0/X"},
+		{name: "subdomain of allowed domain", url: "https://approval.corp.com/builds"},
+		{name: "allowed host with port", url: "https://approval.corp.com:8443/builds"},
+		{name: "allowed domain without subdomain", url: "https://corp.com/builds", wantErr: true},
+		{name: "suffix of allowed host", url: "https://evilhooks.slack.com/services", wantErr: true},
+		{name: "host not allowed", url: "https://example.com/", wantErr: true},
+		{name: "unsupported scheme", url: "file:///etc/passwd", wantErr: true},
+		{name: "relative URL", url: "hooks.slack.com/services", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateTenantURL(tt.url, allowedHosts); (err != nil) != tt.wantErr {
+				t.Errorf("validateTenantURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTenantHTTPClientRefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	if _, err := tenantHTTPClient.Get(server.URL); err == nil {
+		t.Errorf("tenant HTTP client connected to loopback address %s", server.URL)
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+// ImageNameFunc returns the output image of the build of the given component.
+type ImageNameFunc func(component appstudiov1alpha1.Component) string
+
+// DefaultImageName returns the output image the same way the gitops package generates it for the initial build.
+func DefaultImageName(component appstudiov1alpha1.Component) string {
+	return component.Spec.Build.ContainerImage
+}
+
+// applyImageName sets the output image returned by the reconciler image name function into the given build.
+func (r *ComponentBuildReconciler) applyImageName(component appstudiov1alpha1.Component, build *tektonapi.PipelineRun) {
+	imageNameFunc := r.ImageNameFunc
+	if imageNameFunc == nil {
+		imageNameFunc = DefaultImageName
+	}
+	mergePipelineParams(build, []tektonapi.Param{
+		{
+			Name: "output-image",
+			Value: tektonapi.ArrayOrString{
+				Type:      tektonapi.ParamTypeString,
+				StringVal: imageNameFunc(component),
+			},
+		},
+	})
+}
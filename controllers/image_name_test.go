@@ -0,0 +1,68 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+func TestApplyImageName(t *testing.T) {
+	component := getGitSourceComponent(nil, "")
+	component.Spec.Build.ContainerImage = "quay.io/foo/bar:latest"
+
+	tests := []struct {
+		name          string
+		imageNameFunc ImageNameFunc
+		want          string
+	}{
+		{
+			name: "default image name",
+			want: "quay.io/foo/bar:latest",
+		},
+		{
+			name: "custom image name",
+			imageNameFunc: func(component appstudiov1alpha1.Component) string {
+				return "registry.example.com/" + component.Namespace + "/" + component.Name
+			},
+			want: "registry.example.com/my-namespace/my-component",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			build := &tektonapi.PipelineRun{
+				Spec: tektonapi.PipelineRunSpec{
+					Params: []tektonapi.Param{
+						{Name: "git-url", Value: *tektonapi.NewArrayOrString("https://github.com/foo/bar")},
+						{Name: "output-image", Value: *tektonapi.NewArrayOrString("quay.io/foo/bar:latest")},
+					},
+				},
+			}
+			r := &ComponentBuildReconciler{ImageNameFunc: tt.imageNameFunc}
+			r.applyImageName(component, build)
+			if got := getPipelineRunParam(*build, "output-image"); got != tt.want {
+				t.Errorf("applyImageName() output-image = %v, want %v", got, tt.want)
+			}
+			if len(build.Spec.Params) != 2 {
+				t.Errorf("applyImageName() params = %v, want output-image replaced", build.Spec.Params)
+			}
+		})
+	}
+}
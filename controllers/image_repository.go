@@ -0,0 +1,277 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ImageRepositoryConditionType = "ImageRepositoryReady"
+
+	ImageRepositoryReasonExists   = "RepositoryExists"
+	ImageRepositoryReasonCreated  = "RepositoryCreated"
+	ImageRepositoryReasonMissing  = "RepositoryMissing"
+	ImageRepositoryReasonCheckErr = "RepositoryCheckFailed"
+)
+
+// ImageRepositoryClient checks and creates repositories in the registry the builds push images to.
+// The repository is the image reference without tag and digest, e.g. quay.io/org/app
+type ImageRepositoryClient interface {
+	RepositoryExists(ctx context.Context, repository string) (bool, error)
+	CreateRepository(ctx context.Context, repository string) error
+	// ImageExists checks whether the image with the tag of the given reference, latest by default, has been pushed
+	ImageExists(ctx context.Context, image string) (bool, error)
+}
+
+// ensureImageRepository checks that the repository of the build output image exists
+// and creates it if auto-creation is enabled. The returned condition describes the result.
+func (r *ComponentBuildReconciler) ensureImageRepository(ctx context.Context, image string) (metav1.Condition, error) {
+	repository := getImageRepository(image)
+
+	exists, err := r.ImageRepositoryClient.RepositoryExists(ctx, repository)
+	if err != nil {
+		return metav1.Condition{
+			Type:    ImageRepositoryConditionType,
+			Status:  metav1.ConditionUnknown,
+			Reason:  ImageRepositoryReasonCheckErr,
+			Message: fmt.Sprintf("Failed to check image repository %s: %v", repository, err),
+		}, err
+	}
+	if exists {
+		return metav1.Condition{
+			Type:    ImageRepositoryConditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  ImageRepositoryReasonExists,
+			Message: fmt.Sprintf("Image repository %s exists", repository),
+		}, nil
+	}
+
+	if !r.AutoCreateImageRepository {
+		return metav1.Condition{
+			Type:    ImageRepositoryConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  ImageRepositoryReasonMissing,
+			Message: fmt.Sprintf("Image repository %s does not exist and its auto-creation is disabled, the build will fail to push the image", repository),
+		}, nil
+	}
+
+	if err := r.ImageRepositoryClient.CreateRepository(ctx, repository); err != nil {
+		return metav1.Condition{
+			Type:    ImageRepositoryConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  ImageRepositoryReasonMissing,
+			Message: fmt.Sprintf("Failed to create image repository %s: %v", repository, err),
+		}, err
+	}
+	return metav1.Condition{
+		Type:    ImageRepositoryConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  ImageRepositoryReasonCreated,
+		Message: fmt.Sprintf("Image repository %s has been created", repository),
+	}, nil
+}
+
+// getImageRepository strips tag and digest from the given image reference.
+func getImageRepository(image string) string {
+	if i := strings.Index(image, "@"); i != -1 {
+		image = image[:i]
+	}
+	// A colon after the last slash separates the tag, otherwise it is the registry port
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+// getImageTag returns the tag of the given image reference or empty string if there is no tag.
+func getImageTag(image string) string {
+	if i := strings.Index(image, "@"); i != -1 {
+		image = image[:i]
+	}
+	// A colon after the last slash separates the tag, otherwise it is the registry port
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[i+1:]
+	}
+	return ""
+}
+
+// QuayImageRepositoryClient manages image repositories via Quay API.
+type QuayImageRepositoryClient struct {
+	// APIURL is the Quay API endpoint, e.g. https://quay.io/api/v1
+	APIURL string
+	Token  string
+	// HTTPClient calls the API, nil means the shared client with a timeout
+	HTTPClient *http.Client
+}
+
+type quayTagsResponse struct {
+	Tags []struct {
+		Name      string `json:"name"`
+		Immutable bool   `json:"immutable"`
+	} `json:"tags"`
+}
+
+type quayRepositoryRequest struct {
+	Namespace   string `json:"namespace"`
+	Repository  string `json:"repository"`
+	Visibility  string `json:"visibility"`
+	Description string `json:"description"`
+}
+
+func (c *QuayImageRepositoryClient) RepositoryExists(ctx context.Context, repository string) (bool, error) {
+	namespace, name, err := splitQuayRepository(repository)
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/repository/%s/%s", c.APIURL, namespace, name), nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected response status %d from %s", resp.StatusCode, req.URL)
+	}
+}
+
+func (c *QuayImageRepositoryClient) CreateRepository(ctx context.Context, repository string) error {
+	namespace, name, err := splitQuayRepository(repository)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(quayRepositoryRequest{
+		Namespace:  namespace,
+		Repository: name,
+		Visibility: "private",
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.APIURL+"/repository", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("unexpected response status %d from %s", resp.StatusCode, req.URL)
+	}
+	return nil
+}
+
+func (c *QuayImageRepositoryClient) ImageExists(ctx context.Context, image string) (bool, error) {
+	tags, err := c.getActiveTags(ctx, image)
+	if err != nil {
+		return false, err
+	}
+	return len(tags.Tags) > 0, nil
+}
+
+// IsImmutableTag checks whether the tag of the image, latest by default, has been pushed and is immutable.
+func (c *QuayImageRepositoryClient) IsImmutableTag(ctx context.Context, image string) (bool, error) {
+	tags, err := c.getActiveTags(ctx, image)
+	if err != nil {
+		return false, err
+	}
+	for _, tag := range tags.Tags {
+		if tag.Immutable {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getActiveTags returns the active tags of the image repository with the name of the image tag, latest by default.
+func (c *QuayImageRepositoryClient) getActiveTags(ctx context.Context, image string) (quayTagsResponse, error) {
+	namespace, name, err := splitQuayRepository(getImageRepository(image))
+	if err != nil {
+		return quayTagsResponse{}, err
+	}
+	tag := getImageTag(image)
+	if tag == "" {
+		tag = "latest"
+	}
+
+	tagsURL := fmt.Sprintf("%s/repository/%s/%s/tag/?onlyActiveTags=true&specificTag=%s", c.APIURL, namespace, name, url.QueryEscape(tag))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tagsURL, nil)
+	if err != nil {
+		return quayTagsResponse{}, err
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return quayTagsResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	tags := quayTagsResponse{}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+			return quayTagsResponse{}, fmt.Errorf("invalid response from %s: %v", req.URL, err)
+		}
+		return tags, nil
+	case http.StatusNotFound:
+		return tags, nil
+	default:
+		return quayTagsResponse{}, fmt.Errorf("unexpected response status %d from %s", resp.StatusCode, req.URL)
+	}
+}
+
+func (c *QuayImageRepositoryClient) do(req *http.Request) (*http.Response, error) {
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+	return httpClient.Do(req)
+}
+
+// splitQuayRepository splits quay.io/org/app into org and app.
+func splitQuayRepository(repository string) (string, string, error) {
+	parts := strings.SplitN(repository, "/", 3)
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return "", "", fmt.Errorf("invalid image repository %s, registry/namespace/name expected", repository)
+	}
+	return parts[1], parts[2], nil
+}
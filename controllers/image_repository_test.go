@@ -0,0 +1,238 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// mockImageRepositoryClient keeps created repositories and pushed images in memory.
+type mockImageRepositoryClient struct {
+	repositories map[string]bool
+	images       map[string]bool
+	createErr    error
+}
+
+func (c *mockImageRepositoryClient) RepositoryExists(ctx context.Context, repository string) (bool, error) {
+	return c.repositories[repository], nil
+}
+
+func (c *mockImageRepositoryClient) CreateRepository(ctx context.Context, repository string) error {
+	if c.createErr != nil {
+		return c.createErr
+	}
+	c.repositories[repository] = true
+	return nil
+}
+
+func (c *mockImageRepositoryClient) ImageExists(ctx context.Context, image string) (bool, error) {
+	return c.images[image], nil
+}
+
+func TestEnsureImageRepository(t *testing.T) {
+	tests := []struct {
+		name         string
+		existing     map[string]bool
+		autoCreate   bool
+		createErr    error
+		wantStatus   metav1.ConditionStatus
+		wantReason   string
+		wantErr      bool
+		wantRepoThen bool
+	}{
+		{
+			name:         "existing repository",
+			existing:     map[string]bool{"quay.io/org/app": true},
+			wantStatus:   metav1.ConditionTrue,
+			wantReason:   ImageRepositoryReasonExists,
+			wantRepoThen: true,
+		},
+		{
+			name:         "missing repository with auto-create",
+			existing:     map[string]bool{},
+			autoCreate:   true,
+			wantStatus:   metav1.ConditionTrue,
+			wantReason:   ImageRepositoryReasonCreated,
+			wantRepoThen: true,
+		},
+		{
+			name:         "missing repository without auto-create",
+			existing:     map[string]bool{},
+			wantStatus:   metav1.ConditionFalse,
+			wantReason:   ImageRepositoryReasonMissing,
+			wantRepoThen: false,
+		},
+		{
+			name:         "failed to create repository",
+			existing:     map[string]bool{},
+			autoCreate:   true,
+			createErr:    fmt.Errorf("unauthorized"),
+			wantStatus:   metav1.ConditionFalse,
+			wantReason:   ImageRepositoryReasonMissing,
+			wantErr:      true,
+			wantRepoThen: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repositoryClient := &mockImageRepositoryClient{repositories: tt.existing, createErr: tt.createErr}
+			r := &ComponentBuildReconciler{
+				ImageRepositoryClient:     repositoryClient,
+				AutoCreateImageRepository: tt.autoCreate,
+			}
+			got, err := r.ensureImageRepository(context.TODO(), "quay.io/org/app:build-1")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ensureImageRepository() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Type != ImageRepositoryConditionType || got.Status != tt.wantStatus || got.Reason != tt.wantReason {
+				t.Errorf("ensureImageRepository() = %v, want status %v and reason %v", got, tt.wantStatus, tt.wantReason)
+			}
+			if repositoryClient.repositories["quay.io/org/app"] != tt.wantRepoThen {
+				t.Errorf("ensureImageRepository() repository exists = %v, want %v", repositoryClient.repositories["quay.io/org/app"], tt.wantRepoThen)
+			}
+		})
+	}
+}
+
+func TestGetImageRepository(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: "quay.io/org/app", want: "quay.io/org/app"},
+		{image: "quay.io/org/app:latest", want: "quay.io/org/app"},
+		{image: "quay.io/org/app@sha256:abcd", want: "quay.io/org/app"},
+		{image: "quay.io/org/app:v1@sha256:abcd", want: "quay.io/org/app"},
+		{image: "registry.local:5000/org/app", want: "registry.local:5000/org/app"},
+		{image: "registry.local:5000/org/app:v1", want: "registry.local:5000/org/app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			if got := getImageRepository(tt.image); got != tt.want {
+				t.Errorf("getImageRepository() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetImageTag(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: "quay.io/foo/bar:v1", want: "v1"},
+		{image: "quay.io/foo/bar", want: ""},
+		{image: "registry:5000/foo/bar", want: ""},
+		{image: "registry:5000/foo/bar:v2@sha256:abcd", want: "v2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			if got := getImageTag(tt.image); got != tt.want {
+				t.Errorf("getImageTag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuayImageRepositoryClient(t *testing.T) {
+	repositories := map[string]bool{"org/existing": true}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case req.Method == http.MethodGet && strings.HasSuffix(req.URL.Path, "/tag/"):
+			if req.URL.Path == "/repository/org/existing/tag/" && req.URL.Query().Get("specificTag") == "v1" {
+				w.Write([]byte(`{"tags": [{"name": "v1"}]}`))
+			} else if req.URL.Path == "/repository/org/existing/tag/" && req.URL.Query().Get("specificTag") == "release" {
+				w.Write([]byte(`{"tags": [{"name": "release", "immutable": true}]}`))
+			} else {
+				w.Write([]byte(`{"tags": []}`))
+			}
+		case req.Method == http.MethodGet:
+			if repositories[req.URL.Path[len("/repository/"):]] {
+				w.WriteHeader(http.StatusOK)
+			} else {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case req.Method == http.MethodPost && req.URL.Path == "/repository":
+			request := quayRepositoryRequest{}
+			if err := json.NewDecoder(req.Body).Decode(&request); err != nil || request.Visibility != "private" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			repositories[request.Namespace+"/"+request.Repository] = true
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	quayClient := &QuayImageRepositoryClient{APIURL: server.URL, Token: "token"}
+	ctx := context.TODO()
+
+	if exists, err := quayClient.RepositoryExists(ctx, "quay.io/org/existing"); err != nil || !exists {
+		t.Errorf("RepositoryExists() = %v, %v, want true", exists, err)
+	}
+	if exists, err := quayClient.RepositoryExists(ctx, "quay.io/org/new"); err != nil || exists {
+		t.Errorf("RepositoryExists() = %v, %v, want false", exists, err)
+	}
+	if err := quayClient.CreateRepository(ctx, "quay.io/org/new"); err != nil {
+		t.Errorf("CreateRepository() error = %v", err)
+	}
+	if exists, err := quayClient.RepositoryExists(ctx, "quay.io/org/new"); err != nil || !exists {
+		t.Errorf("RepositoryExists() after creation = %v, %v, want true", exists, err)
+	}
+	if _, err := quayClient.RepositoryExists(ctx, "quay.io/app"); err == nil {
+		t.Errorf("RepositoryExists() expected error for repository without namespace")
+	}
+
+	if exists, err := quayClient.ImageExists(ctx, "quay.io/org/existing:v1"); err != nil || !exists {
+		t.Errorf("ImageExists() = %v, %v, want true", exists, err)
+	}
+	if exists, err := quayClient.ImageExists(ctx, "quay.io/org/existing:v2"); err != nil || exists {
+		t.Errorf("ImageExists() = %v, %v, want false", exists, err)
+	}
+	if exists, err := quayClient.ImageExists(ctx, "quay.io/org/existing"); err != nil || exists {
+		t.Errorf("ImageExists() for latest tag = %v, %v, want false", exists, err)
+	}
+
+	if immutable, err := quayClient.IsImmutableTag(ctx, "quay.io/org/existing:release"); err != nil || !immutable {
+		t.Errorf("IsImmutableTag() = %v, %v, want true", immutable, err)
+	}
+	if immutable, err := quayClient.IsImmutableTag(ctx, "quay.io/org/existing:v1"); err != nil || immutable {
+		t.Errorf("IsImmutableTag() for mutable tag = %v, %v, want false", immutable, err)
+	}
+	if immutable, err := quayClient.IsImmutableTag(ctx, "quay.io/org/existing:v2"); err != nil || immutable {
+		t.Errorf("IsImmutableTag() for missing tag = %v, %v, want false", immutable, err)
+	}
+
+	unauthorizedClient := &QuayImageRepositoryClient{APIURL: server.URL}
+	if _, err := unauthorizedClient.RepositoryExists(ctx, "quay.io/org/existing"); err == nil {
+		t.Errorf("RepositoryExists() expected error for unauthorized request")
+	}
+}
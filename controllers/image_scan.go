@@ -0,0 +1,227 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// If set to "true", the image is scanned for vulnerabilities after each successful build
+	ScanOnBuildAnnotationName = "build.appstudio.openshift.io/scan-on-build"
+	// If set to "true", DeploymentBlocked condition is set when the scan finds critical vulnerabilities
+	BlockVulnerableDeploymentAnnotationName = "build.appstudio.openshift.io/block-vulnerable-deployment"
+
+	// Label with the name of the component whose image is scanned by the PipelineRun
+	ImageScanComponentLabelName = "build.appstudio.openshift.io/scan-component"
+	// Label with the name of the build PipelineRun whose image is scanned by the PipelineRun
+	ImageScanBuildLabelName = "build.appstudio.openshift.io/scan-build"
+
+	ImageScanConditionType         = "ImageScanned"
+	DeploymentBlockedConditionType = "DeploymentBlocked"
+
+	ImageScanReasonCompleted              = "ScanCompleted"
+	ImageScanReasonFailed                 = "ScanFailed"
+	DeploymentBlockedReasonCriticalCVEs   = "CriticalVulnerabilitiesFound"
+	DeploymentBlockedReasonNoCriticalCVEs = "NoCriticalVulnerabilities"
+
+	imageScanPipelineRunSuffix = "-scan"
+	imageScannerImage          = "docker.io/aquasec/trivy:0.28.1"
+
+	imageScanCriticalResult = "CRITICAL_CVES"
+	imageScanHighResult     = "HIGH_CVES"
+)
+
+// Counts vulnerabilities of the given severity in the Trivy JSON report
+const countVulnerabilitiesScript = `#!/bin/sh
+set -e
+trivy image --quiet --no-progress --format json --output /tmp/report.json "$(params.image)"
+grep -o '"Severity": "CRITICAL"' /tmp/report.json | wc -l | tr -d ' \n' > $(results.CRITICAL_CVES.path)
+grep -o '"Severity": "HIGH"' /tmp/report.json | wc -l | tr -d ' \n' > $(results.HIGH_CVES.path)
+`
+
+// ImageScanResult is the number of vulnerabilities found in the image.
+type ImageScanResult struct {
+	Critical int
+	High     int
+}
+
+// submitImageScan creates PipelineRun which scans the image built by the given PipelineRun.
+// Nothing is done if the scan has been submitted already.
+func (r *BuildPipelineRunReconciler) submitImageScan(ctx context.Context, component appstudiov1alpha1.Component, buildPipelineRun tektonapi.PipelineRun) error {
+	log := r.Log.WithValues("Namespace", component.Namespace, "Component", component.Name)
+
+	summary := getBuildSummary(component, buildPipelineRun)
+	image := summary.Image
+	if image == "" {
+		return fmt.Errorf("unable to scan image of build %s: output image is unknown", buildPipelineRun.Name)
+	}
+	if summary.ImageDigest != "" {
+		image = getImageRepository(image) + "@" + summary.ImageDigest
+	}
+
+	scanPipelineRun := generateImageScanPipelineRun(component, buildPipelineRun, image)
+	if err := controllerutil.SetOwnerReference(&component, &scanPipelineRun, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Client.Create(ctx, &scanPipelineRun); err != nil {
+		if errors.IsAlreadyExists(err) {
+			return nil
+		}
+		return err
+	}
+	log.Info(fmt.Sprintf("Submitted vulnerability scan %s of image %s", scanPipelineRun.Name, image))
+	return nil
+}
+
+// generateImageScanPipelineRun returns PipelineRun which scans the given image with Trivy.
+func generateImageScanPipelineRun(component appstudiov1alpha1.Component, buildPipelineRun tektonapi.PipelineRun, image string) tektonapi.PipelineRun {
+	return tektonapi.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      buildPipelineRun.Name + imageScanPipelineRunSuffix,
+			Namespace: component.Namespace,
+			Labels: map[string]string{
+				"pipelines.appstudio.openshift.io/type": "scan",
+				ImageScanComponentLabelName:             component.Name,
+				ImageScanBuildLabelName:                 buildPipelineRun.Name,
+			},
+		},
+		Spec: tektonapi.PipelineRunSpec{
+			ServiceAccountName: "pipeline",
+			Params: []tektonapi.Param{
+				{Name: "image", Value: tektonapi.ArrayOrString{Type: tektonapi.ParamTypeString, StringVal: image}},
+			},
+			PipelineSpec: &tektonapi.PipelineSpec{
+				Params: []tektonapi.ParamSpec{{Name: "image", Type: tektonapi.ParamTypeString}},
+				Tasks: []tektonapi.PipelineTask{
+					{
+						Name: "scan",
+						Params: []tektonapi.Param{
+							{Name: "image", Value: tektonapi.ArrayOrString{Type: tektonapi.ParamTypeString, StringVal: "$(params.image)"}},
+						},
+						TaskSpec: &tektonapi.EmbeddedTask{
+							TaskSpec: tektonapi.TaskSpec{
+								Params:  []tektonapi.ParamSpec{{Name: "image", Type: tektonapi.ParamTypeString}},
+								Results: []tektonapi.TaskResult{{Name: imageScanCriticalResult}, {Name: imageScanHighResult}},
+								Steps: []tektonapi.Step{
+									{
+										Container: corev1.Container{Name: "trivy", Image: imageScannerImage},
+										Script:    countVulnerabilitiesScript,
+									},
+								},
+							},
+						},
+					},
+				},
+				Results: []tektonapi.PipelineResult{
+					{Name: imageScanCriticalResult, Value: "$(tasks.scan.results." + imageScanCriticalResult + ")"},
+					{Name: imageScanHighResult, Value: "$(tasks.scan.results." + imageScanHighResult + ")"},
+				},
+			},
+		},
+	}
+}
+
+// updateImageScanResult records the result of the finished image scan in the component status conditions.
+func (r *BuildPipelineRunReconciler) updateImageScanResult(ctx context.Context, component appstudiov1alpha1.Component, scanPipelineRun tektonapi.PipelineRun) error {
+	switch getBuildState(scanPipelineRun) {
+	case BuildStateSucceeded:
+	case BuildStateFailed:
+		return setComponentCondition(ctx, r.Client, component, metav1.Condition{
+			Type:    ImageScanConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  ImageScanReasonFailed,
+			Message: fmt.Sprintf("Vulnerability scan %s failed", scanPipelineRun.Name),
+		})
+	default:
+		// The scan is still in progress
+		return nil
+	}
+
+	result, err := getImageScanResult(scanPipelineRun)
+	if err != nil {
+		return err
+	}
+	if err := setComponentCondition(ctx, r.Client, component, metav1.Condition{
+		Type:   ImageScanConditionType,
+		Status: metav1.ConditionTrue,
+		Reason: ImageScanReasonCompleted,
+		Message: fmt.Sprintf("Found %d critical and %d high vulnerabilities in the image of build %s",
+			result.Critical, result.High, scanPipelineRun.Labels[ImageScanBuildLabelName]),
+	}); err != nil {
+		return err
+	}
+
+	if component.Annotations[BlockVulnerableDeploymentAnnotationName] != "true" {
+		return nil
+	}
+	return setComponentCondition(ctx, r.Client, component, getDeploymentBlockedCondition(result))
+}
+
+// getImageScanResult reads vulnerability counts from the results of the scan PipelineRun.
+func getImageScanResult(scanPipelineRun tektonapi.PipelineRun) (ImageScanResult, error) {
+	result := ImageScanResult{}
+	found := 0
+	for _, pipelineResult := range scanPipelineRun.Status.PipelineResults {
+		var count *int
+		switch pipelineResult.Name {
+		case imageScanCriticalResult:
+			count = &result.Critical
+		case imageScanHighResult:
+			count = &result.High
+		default:
+			continue
+		}
+		value, err := strconv.Atoi(pipelineResult.Value)
+		if err != nil {
+			return result, fmt.Errorf("invalid %s result of vulnerability scan %s: %v", pipelineResult.Name, scanPipelineRun.Name, err)
+		}
+		*count = value
+		found++
+	}
+	if found != 2 {
+		return result, fmt.Errorf("vulnerability scan %s has no %s and %s results", scanPipelineRun.Name, imageScanCriticalResult, imageScanHighResult)
+	}
+	return result, nil
+}
+
+func getDeploymentBlockedCondition(result ImageScanResult) metav1.Condition {
+	if result.Critical > 0 {
+		return metav1.Condition{
+			Type:    DeploymentBlockedConditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  DeploymentBlockedReasonCriticalCVEs,
+			Message: fmt.Sprintf("The image has %d critical vulnerabilities", result.Critical),
+		}
+	}
+	return metav1.Condition{
+		Type:    DeploymentBlockedConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  DeploymentBlockedReasonNoCriticalCVEs,
+		Message: "The image has no critical vulnerabilities",
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+func TestGetImageScanResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []tektonapi.PipelineRunResult
+		want    ImageScanResult
+		wantErr bool
+	}{
+		{
+			name: "vulnerabilities found",
+			results: []tektonapi.PipelineRunResult{
+				{Name: imageScanCriticalResult, Value: "2"},
+				{Name: imageScanHighResult, Value: "7"},
+			},
+			want: ImageScanResult{Critical: 2, High: 7},
+		},
+		{
+			name: "no vulnerabilities",
+			results: []tektonapi.PipelineRunResult{
+				{Name: imageScanHighResult, Value: "0"},
+				{Name: imageScanCriticalResult, Value: "0"},
+			},
+			want: ImageScanResult{},
+		},
+		{
+			name: "missing result",
+			results: []tektonapi.PipelineRunResult{
+				{Name: imageScanCriticalResult, Value: "1"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid result",
+			results: []tektonapi.PipelineRunResult{
+				{Name: imageScanCriticalResult, Value: "many"},
+				{Name: imageScanHighResult, Value: "1"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanPipelineRun := tektonapi.PipelineRun{}
+			scanPipelineRun.Status.PipelineResults = tt.results
+			got, err := getImageScanResult(scanPipelineRun)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getImageScanResult() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("getImageScanResult() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetDeploymentBlockedCondition(t *testing.T) {
+	tests := []struct {
+		name       string
+		result     ImageScanResult
+		wantStatus metav1.ConditionStatus
+	}{
+		{
+			name:       "critical vulnerabilities",
+			result:     ImageScanResult{Critical: 1},
+			wantStatus: metav1.ConditionTrue,
+		},
+		{
+			name:       "high vulnerabilities only",
+			result:     ImageScanResult{High: 10},
+			wantStatus: metav1.ConditionFalse,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getDeploymentBlockedCondition(tt.result)
+			if got.Type != DeploymentBlockedConditionType || got.Status != tt.wantStatus {
+				t.Errorf("getDeploymentBlockedCondition() = %v, want status %v", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestGenerateImageScanPipelineRun(t *testing.T) {
+	component := appstudiov1alpha1.Component{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-component",
+			Namespace: "my-namespace",
+		},
+	}
+	buildPipelineRun := tektonapi.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "my-component-abcde",
+		},
+	}
+	image := "quay.io/org/app@sha256:abcd"
+
+	got := generateImageScanPipelineRun(component, buildPipelineRun, image)
+	if got.Name != "my-component-abcde-scan" || got.Namespace != "my-namespace" {
+		t.Errorf("generateImageScanPipelineRun() name = %s/%s, want my-namespace/my-component-abcde-scan", got.Namespace, got.Name)
+	}
+	if got.Labels[ImageScanComponentLabelName] != "my-component" || got.Labels[ImageScanBuildLabelName] != "my-component-abcde" {
+		t.Errorf("generateImageScanPipelineRun() labels = %v", got.Labels)
+	}
+	if _, isBuild := got.Labels[ComponentNameLabelName]; isBuild {
+		t.Errorf("generateImageScanPipelineRun() must not be labeled as component build")
+	}
+	if getPipelineRunParam(got, "image") != image {
+		t.Errorf("generateImageScanPipelineRun() image = %s, want %s", getPipelineRunParam(got, "image"), image)
+	}
+	if got.Spec.PipelineSpec == nil || len(got.Spec.PipelineSpec.Results) != 2 {
+		t.Errorf("generateImageScanPipelineRun() must define critical and high vulnerabilities results")
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+// ImmutableTagPolicy defines how builds whose output image tag has been pushed already and is immutable are handled.
+type ImmutableTagPolicy string
+
+const (
+	// ImmutableTagPolicyIgnore does not check the output image tag, the push of the build fails on collision
+	ImmutableTagPolicyIgnore ImmutableTagPolicy = "Ignore"
+	// ImmutableTagPolicyBlock sets the ImageTagCollision condition and does not submit the build
+	ImmutableTagPolicyBlock ImmutableTagPolicy = "Block"
+	// ImmutableTagPolicyUniquify pushes the image with a unique tag derived from the colliding one
+	ImmutableTagPolicyUniquify ImmutableTagPolicy = "Uniquify"
+)
+
+const (
+	// ImageTagCollisionConditionType is set on components whose output image tag collides with an existing immutable tag
+	ImageTagCollisionConditionType = "ImageTagCollision"
+
+	ImageTagCollisionReasonBlocked    = "ImmutableTagExists"
+	ImageTagCollisionReasonUniquified = "ImmutableTagUniquified"
+	ImageTagCollisionReasonNone       = "NoCollision"
+
+	// Number of unique tag candidates tried before the build is given up
+	maxUniqueImageTagAttempts = 5
+	// Maximum length of image tags as defined by the distribution spec
+	maxImageTagLength = 128
+)
+
+// ImmutableTagClient is implemented by image repository clients of registries which support immutable tags.
+type ImmutableTagClient interface {
+	// IsImmutableTag checks whether the tag of the given image reference, latest by default, has been pushed and is immutable
+	IsImmutableTag(ctx context.Context, image string) (bool, error)
+}
+
+// ParseImmutableTagPolicy returns the immutable tag policy with the given name.
+// Empty name means the default Ignore policy.
+func ParseImmutableTagPolicy(name string) (ImmutableTagPolicy, error) {
+	switch policy := ImmutableTagPolicy(name); policy {
+	case "":
+		return ImmutableTagPolicyIgnore, nil
+	case ImmutableTagPolicyIgnore, ImmutableTagPolicyBlock, ImmutableTagPolicyUniquify:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown immutable tag policy %q, expected one of %s, %s, %s", name,
+			ImmutableTagPolicyIgnore, ImmutableTagPolicyBlock, ImmutableTagPolicyUniquify)
+	}
+}
+
+// getImmutableTagClient returns the client which checks immutable tags or nil if the check is disabled.
+func (r *ComponentBuildReconciler) getImmutableTagClient() ImmutableTagClient {
+	if r.ImmutableTagPolicy != ImmutableTagPolicyBlock && r.ImmutableTagPolicy != ImmutableTagPolicyUniquify {
+		return nil
+	}
+	immutableTagClient, ok := r.ImageRepositoryClient.(ImmutableTagClient)
+	if !ok {
+		return nil
+	}
+	return immutableTagClient
+}
+
+// resolveImageTagCollision checks whether the output image tag of the build collides with an existing immutable tag
+// and handles the collision according to the immutable tag policy. Uniquified output image is set in the build.
+// The returned condition describes the result, true status means the build must not be submitted.
+func (r *ComponentBuildReconciler) resolveImageTagCollision(ctx context.Context, component appstudiov1alpha1.Component, build *tektonapi.PipelineRun) (*metav1.Condition, error) {
+	immutableTagClient := r.getImmutableTagClient()
+	outputImage := getPipelineRunParam(*build, "output-image")
+	if immutableTagClient == nil || outputImage == "" {
+		return nil, nil
+	}
+
+	collision, err := immutableTagClient.IsImmutableTag(ctx, outputImage)
+	if err != nil {
+		return nil, err
+	}
+	if !collision {
+		if !meta.IsStatusConditionTrue(component.Status.Conditions, ImageTagCollisionConditionType) {
+			return nil, nil
+		}
+		return &metav1.Condition{
+			Type:    ImageTagCollisionConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  ImageTagCollisionReasonNone,
+			Message: fmt.Sprintf("Image %s does not collide with an immutable tag", outputImage),
+		}, nil
+	}
+
+	if r.ImmutableTagPolicy == ImmutableTagPolicyUniquify {
+		uniqueImage, err := r.getUniqueImage(ctx, component, outputImage)
+		if err != nil {
+			return nil, err
+		}
+		if uniqueImage != "" {
+			mergePipelineParams(build, []tektonapi.Param{
+				{Name: "output-image", Value: *tektonapi.NewArrayOrString(uniqueImage)},
+			})
+			return &metav1.Condition{
+				Type:    ImageTagCollisionConditionType,
+				Status:  metav1.ConditionFalse,
+				Reason:  ImageTagCollisionReasonUniquified,
+				Message: fmt.Sprintf("Immutable image %s exists already, the build pushes %s", outputImage, uniqueImage),
+			}, nil
+		}
+	}
+
+	return &metav1.Condition{
+		Type:    ImageTagCollisionConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  ImageTagCollisionReasonBlocked,
+		Message: fmt.Sprintf("Immutable image %s exists already, the build is not submitted", outputImage),
+	}, nil
+}
+
+// getUniqueImage returns the image with a tag derived from the tag of the given image which has not been pushed yet.
+// Empty string is returned if all the candidate tags have been pushed.
+func (r *ComponentBuildReconciler) getUniqueImage(ctx context.Context, component appstudiov1alpha1.Component, image string) (string, error) {
+	tag := getImageTag(image)
+	if tag == "" {
+		tag = "latest"
+	}
+	suffix := "-" + getBuildID(component)
+	for attempt := 1; attempt <= maxUniqueImageTagAttempts; attempt++ {
+		if attempt > 1 {
+			suffix = fmt.Sprintf("-%s-%d", getBuildID(component), attempt)
+		}
+		uniqueTag := tag
+		if len(uniqueTag)+len(suffix) > maxImageTagLength {
+			uniqueTag = uniqueTag[:maxImageTagLength-len(suffix)]
+		}
+		uniqueImage := withImageTag(image, uniqueTag+suffix)
+
+		exists, err := r.ImageRepositoryClient.ImageExists(ctx, uniqueImage)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return uniqueImage, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// mockImmutableTagClient is an image repository client of a registry with immutable tags
+type mockImmutableTagClient struct {
+	mockImageRepositoryClient
+	immutableImages map[string]bool
+}
+
+func (c *mockImmutableTagClient) IsImmutableTag(ctx context.Context, image string) (bool, error) {
+	return c.immutableImages[image], nil
+}
+
+func TestParseImmutableTagPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    ImmutableTagPolicy
+		wantErr bool
+	}{
+		{name: "", want: ImmutableTagPolicyIgnore},
+		{name: "Ignore", want: ImmutableTagPolicyIgnore},
+		{name: "Block", want: ImmutableTagPolicyBlock},
+		{name: "Uniquify", want: ImmutableTagPolicyUniquify},
+		{name: "Overwrite", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseImmutableTagPolicy(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseImmutableTagPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseImmutableTagPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveImageTagCollision(t *testing.T) {
+	component := getGitSourceComponent(nil, "version: 2.2.0")
+	uniqueImage := "quay.io/org/app:v1-" + getBuildID(component)
+
+	tests := []struct {
+		name            string
+		policy          ImmutableTagPolicy
+		outputImage     string
+		images          map[string]bool
+		immutableImages map[string]bool
+		conditions      []metav1.Condition
+		wantStatus      metav1.ConditionStatus
+		wantReason      string
+		wantImage       string
+	}{
+		{
+			name:            "no collision",
+			policy:          ImmutableTagPolicyBlock,
+			outputImage:     "quay.io/org/app:v2",
+			images:          map[string]bool{"quay.io/org/app:v1": true},
+			immutableImages: map[string]bool{"quay.io/org/app:v1": true},
+			wantImage:       "quay.io/org/app:v2",
+		},
+		{
+			name:        "collision with mutable tag",
+			policy:      ImmutableTagPolicyBlock,
+			outputImage: "quay.io/org/app:latest",
+			images:      map[string]bool{"quay.io/org/app:latest": true},
+			wantImage:   "quay.io/org/app:latest",
+		},
+		{
+			name:            "collision blocked",
+			policy:          ImmutableTagPolicyBlock,
+			outputImage:     "quay.io/org/app:v1",
+			images:          map[string]bool{"quay.io/org/app:v1": true},
+			immutableImages: map[string]bool{"quay.io/org/app:v1": true},
+			wantStatus:      metav1.ConditionTrue,
+			wantReason:      ImageTagCollisionReasonBlocked,
+			wantImage:       "quay.io/org/app:v1",
+		},
+		{
+			name:            "collision uniquified",
+			policy:          ImmutableTagPolicyUniquify,
+			outputImage:     "quay.io/org/app:v1",
+			images:          map[string]bool{"quay.io/org/app:v1": true},
+			immutableImages: map[string]bool{"quay.io/org/app:v1": true},
+			wantStatus:      metav1.ConditionFalse,
+			wantReason:      ImageTagCollisionReasonUniquified,
+			wantImage:       uniqueImage,
+		},
+		{
+			name:            "collision uniquified with pushed unique tag",
+			policy:          ImmutableTagPolicyUniquify,
+			outputImage:     "quay.io/org/app:v1",
+			images:          map[string]bool{"quay.io/org/app:v1": true, uniqueImage: true},
+			immutableImages: map[string]bool{"quay.io/org/app:v1": true},
+			wantStatus:      metav1.ConditionFalse,
+			wantReason:      ImageTagCollisionReasonUniquified,
+			wantImage:       uniqueImage + "-2",
+		},
+		{
+			name:            "collision ignored",
+			policy:          ImmutableTagPolicyIgnore,
+			outputImage:     "quay.io/org/app:v1",
+			images:          map[string]bool{"quay.io/org/app:v1": true},
+			immutableImages: map[string]bool{"quay.io/org/app:v1": true},
+			wantImage:       "quay.io/org/app:v1",
+		},
+		{
+			name:        "collision resolved",
+			policy:      ImmutableTagPolicyBlock,
+			outputImage: "quay.io/org/app:v2",
+			conditions: []metav1.Condition{
+				{Type: ImageTagCollisionConditionType, Status: metav1.ConditionTrue, Reason: ImageTagCollisionReasonBlocked},
+			},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: ImageTagCollisionReasonNone,
+			wantImage:  "quay.io/org/app:v2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ComponentBuildReconciler{
+				ImageRepositoryClient: &mockImmutableTagClient{
+					mockImageRepositoryClient: mockImageRepositoryClient{images: tt.images},
+					immutableImages:           tt.immutableImages,
+				},
+				ImmutableTagPolicy: tt.policy,
+			}
+			component := component
+			component.Status.Conditions = tt.conditions
+			build := &tektonapi.PipelineRun{}
+			mergePipelineParams(build, []tektonapi.Param{{Name: "output-image", Value: *tektonapi.NewArrayOrString(tt.outputImage)}})
+
+			condition, err := r.resolveImageTagCollision(context.TODO(), component, build)
+			if err != nil {
+				t.Fatalf("resolveImageTagCollision() error = %v", err)
+			}
+			if tt.wantReason == "" && condition != nil {
+				t.Errorf("resolveImageTagCollision() = %v, want no condition", condition)
+			}
+			if tt.wantReason != "" && (condition == nil || condition.Status != tt.wantStatus || condition.Reason != tt.wantReason) {
+				t.Errorf("resolveImageTagCollision() = %v, want %v/%v", condition, tt.wantStatus, tt.wantReason)
+			}
+			if got := getPipelineRunParam(*build, "output-image"); got != tt.wantImage {
+				t.Errorf("resolveImageTagCollision() output image = %v, want %v", got, tt.wantImage)
+			}
+		})
+	}
+}
+
+func TestResolveImageTagCollisionWithoutImmutableTagSupport(t *testing.T) {
+	r := &ComponentBuildReconciler{
+		ImageRepositoryClient: &mockImageRepositoryClient{images: map[string]bool{"quay.io/org/app:v1": true}},
+		ImmutableTagPolicy:    ImmutableTagPolicyBlock,
+	}
+	build := &tektonapi.PipelineRun{}
+	mergePipelineParams(build, []tektonapi.Param{{Name: "output-image", Value: *tektonapi.NewArrayOrString("quay.io/org/app:v1")}})
+
+	condition, err := r.resolveImageTagCollision(context.TODO(), getGitSourceComponent(nil, ""), build)
+	if err != nil || condition != nil {
+		t.Errorf("resolveImageTagCollision() = %v, %v, want no check", condition, err)
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+// imageTagRegex matches valid container image tags as defined by the distribution spec.
+var imageTagRegex = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+
+// imageTagFormatData holds the values that could be used in the image tag format template.
+type imageTagFormatData struct {
+	Component   string
+	Application string
+	Namespace   string
+	// GitRevision is the revision requested in the build pipeline parameters, empty for the default branch
+	GitRevision string
+	// BuildNumber is the sequence number of the build being submitted for the component
+	BuildNumber int
+}
+
+// applyImageTagFormat renders the image tag format template from the component annotation
+// and sets the result as the tag of the output-image parameter of the given build.
+func (r *ComponentBuildReconciler) applyImageTagFormat(ctx context.Context, component appstudiov1alpha1.Component, build *tektonapi.PipelineRun) error {
+	tagFormat := component.Annotations[ImageTagFormatAnnotationName]
+
+	tag, err := renderImageTag(tagFormat, imageTagFormatData{
+		Component:   component.Name,
+		Application: component.Spec.Application,
+		Namespace:   component.Namespace,
+		GitRevision: getPipelineRunParam(*build, "revision"),
+		BuildNumber: getBuildNumber(component),
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, param := range build.Spec.Params {
+		if param.Name == "output-image" {
+			if param.Value.StringVal == "" {
+				return fmt.Errorf("unable to apply image tag format: output image is not set")
+			}
+			build.Spec.Params[i].Value.StringVal = withImageTag(param.Value.StringVal, tag)
+			return nil
+		}
+	}
+	return fmt.Errorf("unable to apply image tag format: output-image parameter is missing")
+}
+
+// setNextBuildNumber increments the build number recorded in the component annotation.
+// The number is persisted in the component, so it doesn't go down when old builds are deleted.
+// Components without a valid record continue from the number of their existing builds.
+func (r *ComponentBuildReconciler) setNextBuildNumber(ctx context.Context, component *appstudiov1alpha1.Component) error {
+	pipelineRuns, err := listComponentPipelineRuns(ctx, r.Client, *component)
+	if err != nil {
+		return err
+	}
+
+	buildNumber := getBuildNumber(*component)
+	if len(pipelineRuns) > buildNumber {
+		buildNumber = len(pipelineRuns)
+	}
+	component.Annotations[BuildNumberAnnotationName] = strconv.Itoa(buildNumber + 1)
+	return nil
+}
+
+// getBuildNumber returns the build number recorded in the component annotation, 0 if there is no valid record.
+func getBuildNumber(component appstudiov1alpha1.Component) int {
+	buildNumber, err := strconv.Atoi(component.Annotations[BuildNumberAnnotationName])
+	if err != nil || buildNumber < 0 {
+		return 0
+	}
+	return buildNumber
+}
+
+// renderImageTag executes the given Go template against the data and validates the result as an image tag.
+func renderImageTag(tagFormat string, data imageTagFormatData) (string, error) {
+	tag, err := renderTemplate(tagFormat, data)
+	if err != nil {
+		return "", fmt.Errorf("image tag format: %v", err)
+	}
+
+	if !imageTagRegex.MatchString(tag) {
+		return "", fmt.Errorf("image tag format %q produced invalid tag %q", tagFormat, tag)
+	}
+	return tag, nil
+}
+
+// renderTemplate executes the given Go template against the data.
+// Referencing a field that the data does not have is an error.
+func renderTemplate(text string, data interface{}) (string, error) {
+	tmpl, err := template.New("").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %q: %v", text, err)
+	}
+
+	var result bytes.Buffer
+	if err := tmpl.Execute(&result, data); err != nil {
+		return "", fmt.Errorf("failed to render template %q: %v", text, err)
+	}
+	return result.String(), nil
+}
+
+// withImageTag returns the given image reference with its tag replaced by the given one.
+// Both quay.io/foo/bar and quay.io/foo/bar:mytag become quay.io/foo/bar:tag
+// A digest is dropped, as it pins the image regardless of the tag, so quay.io/foo/bar@sha256:abc becomes quay.io/foo/bar:tag
+func withImageTag(image string, tag string) string {
+	repository := image
+	if digestIndex := strings.Index(repository, "@"); digestIndex >= 0 {
+		repository = repository[:digestIndex]
+	}
+	// A colon after the last slash separates the tag, otherwise it is a registry port
+	if tagIndex := strings.LastIndex(repository, ":"); tagIndex > strings.LastIndex(repository, "/") {
+		repository = repository[:tagIndex]
+	}
+	return repository + ":" + tag
+}
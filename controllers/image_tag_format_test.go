@@ -0,0 +1,199 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+func TestRenderImageTag(t *testing.T) {
+	data := imageTagFormatData{
+		Component:   "my-component",
+		Application: "my-app",
+		Namespace:   "my-namespace",
+		GitRevision: "1a2b3c4",
+		BuildNumber: 3,
+	}
+	tests := []struct {
+		name      string
+		tagFormat string
+		wantErr   bool
+		wantTag   string
+	}{
+		{
+			name:      "component and build number",
+			tagFormat: "{{.Component}}-{{.BuildNumber}}",
+			wantErr:   false,
+			wantTag:   "my-component-3",
+		},
+		{
+			name:      "component, git revision and build number",
+			tagFormat: "{{.Component}}-{{.GitRevision}}-{{.BuildNumber}}",
+			wantErr:   false,
+			wantTag:   "my-component-1a2b3c4-3",
+		},
+		{
+			name:      "static tag",
+			tagFormat: "latest",
+			wantErr:   false,
+			wantTag:   "latest",
+		},
+		{
+			name:      "unknown field",
+			tagFormat: "{{.Component}}-{{.GitBranch}}",
+			wantErr:   true,
+		},
+		{
+			name:      "broken template",
+			tagFormat: "{{.Component",
+			wantErr:   true,
+		},
+		{
+			name:      "invalid tag characters",
+			tagFormat: "{{.Component}}/{{.Namespace}}",
+			wantErr:   true,
+		},
+		{
+			name:      "empty tag",
+			tagFormat: "{{if false}}x{{end}}",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderImageTag(tt.tagFormat, data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("renderImageTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.wantTag {
+				t.Errorf("renderImageTag() = %v, want %v", got, tt.wantTag)
+			}
+		})
+	}
+}
+
+func TestWithImageTag(t *testing.T) {
+	tests := []struct {
+		name  string
+		image string
+		tag   string
+		want  string
+	}{
+		{
+			name:  "image without tag",
+			image: "quay.io/foo/bar",
+			tag:   "v1",
+			want:  "quay.io/foo/bar:v1",
+		},
+		{
+			name:  "image with tag",
+			image: "quay.io/foo/bar:old",
+			tag:   "v1",
+			want:  "quay.io/foo/bar:v1",
+		},
+		{
+			name:  "registry with port",
+			image: "registry.local:5000/foo/bar",
+			tag:   "v1",
+			want:  "registry.local:5000/foo/bar:v1",
+		},
+		{
+			name:  "registry with port and tag",
+			image: "registry.local:5000/foo/bar:old",
+			tag:   "v1",
+			want:  "registry.local:5000/foo/bar:v1",
+		},
+		{
+			name:  "image with digest",
+			image: "quay.io/foo/bar@sha256:4d2b7b4f0bb2ef7b8d4a7f0e2e6a1c1c8b3b0f5c8b6e4c7d1a9f2e3b4c5d6e7f",
+			tag:   "v1",
+			want:  "quay.io/foo/bar:v1",
+		},
+		{
+			name:  "registry with port, tag and digest",
+			image: "registry.local:5000/foo/bar:old@sha256:4d2b7b4f0bb2ef7b8d4a7f0e2e6a1c1c8b3b0f5c8b6e4c7d1a9f2e3b4c5d6e7f",
+			tag:   "v1",
+			want:  "registry.local:5000/foo/bar:v1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withImageTag(tt.image, tt.tag); got != tt.want {
+				t.Errorf("withImageTag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetNextBuildNumber(t *testing.T) {
+	withBuildNumber := func(buildNumber string) appstudiov1alpha1.Component {
+		component := appstudiov1alpha1.Component{ObjectMeta: metav1.ObjectMeta{Name: "my-component", Namespace: "my-namespace", Annotations: map[string]string{}}}
+		if buildNumber != "" {
+			component.Annotations[BuildNumberAnnotationName] = buildNumber
+		}
+		return component
+	}
+
+	tests := []struct {
+		name            string
+		component       appstudiov1alpha1.Component
+		pipelineRuns    []tektonapi.PipelineRun
+		wantBuildNumber string
+	}{
+		{
+			name:            "first build",
+			component:       withBuildNumber(""),
+			wantBuildNumber: "1",
+		},
+		{
+			name:            "recorded build number",
+			component:       withBuildNumber("5"),
+			pipelineRuns:    make([]tektonapi.PipelineRun, 2),
+			wantBuildNumber: "6",
+		},
+		{
+			name:            "no record for existing builds",
+			component:       withBuildNumber(""),
+			pipelineRuns:    make([]tektonapi.PipelineRun, 2),
+			wantBuildNumber: "3",
+		},
+		{
+			name:            "invalid record",
+			component:       withBuildNumber("five"),
+			pipelineRuns:    make([]tektonapi.PipelineRun, 1),
+			wantBuildNumber: "2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ComponentBuildReconciler{Client: &pipelineRunListClient{pipelineRuns: tt.pipelineRuns}}
+			component := tt.component
+			if err := r.setNextBuildNumber(context.TODO(), &component); err != nil {
+				t.Errorf("setNextBuildNumber() error = %v", err)
+			}
+			if got := component.Annotations[BuildNumberAnnotationName]; got != tt.wantBuildNumber {
+				t.Errorf("setNextBuildNumber() = %v, want %v", got, tt.wantBuildNumber)
+			}
+		})
+	}
+}
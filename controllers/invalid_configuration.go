@@ -0,0 +1,76 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// InvalidConfigurationConditionType is set on components whose build has not been submitted
+	// because of an invalid build annotation. The build is submitted once the component is fixed.
+	InvalidConfigurationConditionType = "InvalidConfiguration"
+
+	InvalidConfigurationReasonInvalidAnnotation = "InvalidAnnotation"
+	InvalidConfigurationReasonValid             = "ValidConfiguration"
+)
+
+// getInvalidConfigurationCondition returns the condition explaining the build is not submitted because of the given error
+// in the build annotations of the component.
+func getInvalidConfigurationCondition(err error) metav1.Condition {
+	return metav1.Condition{
+		Type:    InvalidConfigurationConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  InvalidConfigurationReasonInvalidAnnotation,
+		Message: fmt.Sprintf("The build has not been submitted: %v. Fix the component to submit the build", err),
+	}
+}
+
+// rejectInvalidConfiguration sets the InvalidConfiguration condition on the component instead of failing the build submission.
+// Retries would fail the same way, so the build is not requeued and waits for a change of the component.
+func (r *ComponentBuildReconciler) rejectInvalidConfiguration(ctx context.Context, component appstudiov1alpha1.Component, err error) error {
+	log := withBuildFields(r.Log, component, buildPhaseSubmit)
+
+	condition := getInvalidConfigurationCondition(err)
+	log.Info(condition.Message)
+	if err := setComponentCondition(ctx, r.Client, component, condition); err != nil {
+		log.Error(err, fmt.Sprintf("Unable to set %s condition for component %s", condition.Type, component.Name))
+		return err
+	}
+	return nil
+}
+
+// clearInvalidConfigurationCondition marks the build of the component, which has not been submitted because of
+// an invalid build annotation, as submitted.
+func clearInvalidConfigurationCondition(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) error {
+	if !meta.IsStatusConditionTrue(component.Status.Conditions, InvalidConfigurationConditionType) {
+		return nil
+	}
+	return setComponentCondition(ctx, cli, component, metav1.Condition{
+		Type:    InvalidConfigurationConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  InvalidConfigurationReasonValid,
+		Message: "The build configuration is valid, the build has been submitted",
+	})
+}
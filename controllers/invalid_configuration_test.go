@@ -0,0 +1,74 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRejectInvalidConfiguration(t *testing.T) {
+	component := getGitSourceComponent(map[string]string{BuildTimeoutsAnnotationName: `{"pipeline": "1 hour"}`}, "version: 2.2.0")
+	_, configErr := getBuildTimeouts(component)
+	if configErr == nil {
+		t.Fatalf("getBuildTimeouts() succeeded, error expected")
+	}
+	cli := &conflictingStatusClient{component: *component.DeepCopy()}
+	r := &ComponentBuildReconciler{Client: cli, Log: logr.Discard()}
+
+	if err := r.rejectInvalidConfiguration(context.TODO(), component, configErr); err != nil {
+		t.Fatalf("rejectInvalidConfiguration() error = %v", err)
+	}
+	if len(cli.updated) != 1 {
+		t.Fatalf("rejectInvalidConfiguration() updated status %d times, want 1", len(cli.updated))
+	}
+	rejected := cli.updated[0]
+	AssertComponentConditions(t, &rejected, []metav1.Condition{{
+		Type:    InvalidConfigurationConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  InvalidConfigurationReasonInvalidAnnotation,
+		Message: "The build has not been submitted: " + configErr.Error() + ". Fix the component to submit the build",
+	}})
+
+	cli.component = rejected
+	if err := clearInvalidConfigurationCondition(context.TODO(), cli, rejected); err != nil {
+		t.Fatalf("clearInvalidConfigurationCondition() error = %v", err)
+	}
+	if len(cli.updated) != 2 {
+		t.Fatalf("clearInvalidConfigurationCondition() did not update status")
+	}
+	AssertComponentConditions(t, &cli.updated[1], []metav1.Condition{{
+		Type:   InvalidConfigurationConditionType,
+		Status: metav1.ConditionFalse,
+		Reason: InvalidConfigurationReasonValid,
+	}})
+}
+
+func TestClearInvalidConfigurationConditionNotSet(t *testing.T) {
+	component := getGitSourceComponent(nil, "version: 2.2.0")
+	cli := &conflictingStatusClient{component: *component.DeepCopy()}
+
+	if err := clearInvalidConfigurationCondition(context.TODO(), cli, component); err != nil {
+		t.Fatalf("clearInvalidConfigurationCondition() error = %v", err)
+	}
+	if len(cli.updated) != 0 {
+		t.Errorf("clearInvalidConfigurationCondition() updated status of component without the condition")
+	}
+}
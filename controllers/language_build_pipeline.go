@@ -0,0 +1,118 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/pkg/devfile"
+)
+
+const (
+	// Set on build PipelineRuns with the language detected in the devfile of the component
+	DevfileLanguageAnnotationName = "build.appstudio.openshift.io/devfile-language"
+	// Set on build PipelineRuns with the schema version of the devfile of the component
+	DevfileSchemaVersionAnnotationName = "build.appstudio.openshift.io/devfile-schema-version"
+
+	// Build pipeline of components with a Dockerfile, it is not replaced by the language pipeline
+	dockerBuildPipelineName = "docker-build"
+)
+
+// Aliases of devfile languages, so the build pipelines ConfigMap needs only the canonical names
+var devfileLanguageAliases = map[string]string{
+	"node": "nodejs",
+}
+
+// devfileInfo holds the devfile fields the build pipeline is selected by
+type devfileInfo struct {
+	SchemaVersion string
+	Language      string
+}
+
+// getDevfileInfo returns the schema version and the normalized language of the devfile model of the component.
+// False is returned if the component has no valid devfile model.
+func getDevfileInfo(component appstudiov1alpha1.Component) (devfileInfo, bool) {
+	if component.Status.Devfile == "" {
+		return devfileInfo{}, false
+	}
+	devfileData, err := devfile.ParseDevfileModel(component.Status.Devfile)
+	if err != nil {
+		return devfileInfo{}, false
+	}
+
+	language := strings.ToLower(strings.TrimSpace(devfileData.GetMetadata().Language))
+	if alias, isAlias := devfileLanguageAliases[language]; isAlias {
+		language = alias
+	}
+	return devfileInfo{
+		SchemaVersion: devfileData.GetSchemaVersion(),
+		Language:      language,
+	}, true
+}
+
+// getBuildPipelines returns the mapping of devfile languages to build pipeline names from the build pipelines ConfigMap.
+// Missing ConfigMap means no mapping.
+func (r *ComponentBuildReconciler) getBuildPipelines(ctx context.Context) (map[string]string, error) {
+	if r.BuildPipelinesConfigMap == nil {
+		return nil, nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, *r.BuildPipelinesConfigMap, configMap); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	buildPipelines := make(map[string]string, len(configMap.Data))
+	for language, pipelineName := range configMap.Data {
+		buildPipelines[strings.ToLower(language)] = strings.TrimSpace(pipelineName)
+	}
+	return buildPipelines, nil
+}
+
+// applyLanguageBuildPipeline replaces the build pipeline with the one mapped to the language of the devfile.
+// The build pipeline is kept for components with a Dockerfile and for languages without a mapping.
+func applyLanguageBuildPipeline(component appstudiov1alpha1.Component, buildPipelines map[string]string, pipelineRun *tektonapi.PipelineRun) {
+	info, valid := getDevfileInfo(component)
+	if !valid {
+		return
+	}
+
+	if pipelineRun.Annotations == nil {
+		pipelineRun.Annotations = map[string]string{}
+	}
+	pipelineRun.Annotations[DevfileSchemaVersionAnnotationName] = info.SchemaVersion
+	if info.Language != "" {
+		pipelineRun.Annotations[DevfileLanguageAnnotationName] = info.Language
+	}
+
+	pipelineRef := pipelineRun.Spec.PipelineRef
+	if pipelineRef == nil || pipelineRef.Name == dockerBuildPipelineName {
+		return
+	}
+	if pipelineName := buildPipelines[info.Language]; pipelineName != "" {
+		pipelineRef.Name = pipelineName
+	}
+}
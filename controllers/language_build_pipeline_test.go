@@ -0,0 +1,143 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/redhat-appstudio/application-service/gitops"
+	"github.com/redhat-appstudio/application-service/gitops/prepare"
+)
+
+const javaDevfile = `schemaVersion: 2.2.0
+metadata:
+  name: java-component
+  language: Java
+`
+
+const nodeDevfile = `schemaVersion: 2.1.0
+metadata:
+  name: node-component
+  language: node
+`
+
+const pythonDevfile = `schemaVersion: 2.2.0
+metadata:
+  name: python-component
+  language: Python
+`
+
+const dockerfileDevfile = `schemaVersion: 2.2.0
+metadata:
+  name: dockerfile-component
+  language: Java
+components:
+  - name: outerloop-build
+    image:
+      imageName: java-component-image:latest
+      dockerfile:
+        uri: Dockerfile
+`
+
+func TestGetDevfileInfo(t *testing.T) {
+	tests := []struct {
+		name      string
+		devfile   string
+		want      devfileInfo
+		wantValid bool
+	}{
+		{name: "java", devfile: javaDevfile, want: devfileInfo{SchemaVersion: "2.2.0", Language: "java"}, wantValid: true},
+		{name: "node alias", devfile: nodeDevfile, want: devfileInfo{SchemaVersion: "2.1.0", Language: "nodejs"}, wantValid: true},
+		{name: "no devfile", devfile: "", wantValid: false},
+		{name: "invalid devfile", devfile: "metadata: [", wantValid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, valid := getDevfileInfo(getGitSourceComponent(nil, tt.devfile))
+			if valid != tt.wantValid || got != tt.want {
+				t.Errorf("getDevfileInfo() = %v, %v, want %v, %v", got, valid, tt.want, tt.wantValid)
+			}
+		})
+	}
+}
+
+func TestApplyLanguageBuildPipeline(t *testing.T) {
+	buildPipelines := map[string]string{
+		"java":   "java-build-pipeline",
+		"nodejs": "nodejs-build-pipeline",
+	}
+
+	tests := []struct {
+		name             string
+		devfile          string
+		buildPipelines   map[string]string
+		wantPipelineName string
+		wantLanguage     string
+	}{
+		{
+			name:             "java",
+			devfile:          javaDevfile,
+			buildPipelines:   buildPipelines,
+			wantPipelineName: "java-build-pipeline",
+			wantLanguage:     "java",
+		},
+		{
+			name:             "node alias",
+			devfile:          nodeDevfile,
+			buildPipelines:   buildPipelines,
+			wantPipelineName: "nodejs-build-pipeline",
+			wantLanguage:     "nodejs",
+		},
+		{
+			name:             "language without mapping",
+			devfile:          pythonDevfile,
+			buildPipelines:   buildPipelines,
+			wantPipelineName: "noop",
+			wantLanguage:     "python",
+		},
+		{
+			name:             "dockerfile",
+			devfile:          dockerfileDevfile,
+			buildPipelines:   buildPipelines,
+			wantPipelineName: dockerBuildPipelineName,
+			wantLanguage:     "java",
+		},
+		{
+			name:             "no mapping",
+			devfile:          javaDevfile,
+			wantPipelineName: "java-builder",
+			wantLanguage:     "java",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := getGitSourceComponent(nil, tt.devfile)
+			pipelineRun := gitops.GenerateInitialBuildPipelineRun(component, prepare.GitopsConfig{})
+
+			applyLanguageBuildPipeline(component, tt.buildPipelines, &pipelineRun)
+			if got := pipelineRun.Spec.PipelineRef.Name; got != tt.wantPipelineName {
+				t.Errorf("applyLanguageBuildPipeline() pipeline = %v, want %v", got, tt.wantPipelineName)
+			}
+			if got := pipelineRun.Annotations[DevfileLanguageAnnotationName]; got != tt.wantLanguage {
+				t.Errorf("applyLanguageBuildPipeline() language = %v, want %v", got, tt.wantLanguage)
+			}
+			if got := pipelineRun.Annotations[DevfileSchemaVersionAnnotationName]; got == "" {
+				t.Errorf("applyLanguageBuildPipeline() did not set the devfile schema version")
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+//+kubebuilder:rbac:groups=tekton.dev,resources=pipelineruns,verbs=get;list;watch;update
+
+// relabelLegacyPipelineRuns adds the component label to PipelineRuns of the component labeled only with the legacy key,
+// so they are found by the build controllers. Already relabeled PipelineRuns are not updated.
+func (r *ComponentBuildReconciler) relabelLegacyPipelineRuns(ctx context.Context, component appstudiov1alpha1.Component) error {
+	log := r.Log.WithValues("Namespace", component.Namespace, "Component", component.Name)
+
+	pipelineRuns := &tektonapi.PipelineRunList{}
+	if err := r.Client.List(ctx, pipelineRuns, client.InNamespace(component.Namespace), client.MatchingLabels{r.LegacyComponentLabelName: component.Name}); err != nil {
+		return err
+	}
+
+	for i := range pipelineRuns.Items {
+		pipelineRun := &pipelineRuns.Items[i]
+		if !relabelLegacyPipelineRun(pipelineRun, r.LegacyComponentLabelName) {
+			continue
+		}
+		if err := r.Client.Update(ctx, pipelineRun); err != nil {
+			log.Error(err, fmt.Sprintf("Unable to relabel legacy PipelineRun %s", pipelineRun.Name))
+			return err
+		}
+		log.Info(fmt.Sprintf("Relabeled legacy PipelineRun %s", pipelineRun.Name))
+	}
+	return nil
+}
+
+// relabelLegacyPipelineRun sets the component label from the legacy label value.
+// Returns false if the PipelineRun doesn't need to be updated.
+func relabelLegacyPipelineRun(pipelineRun *tektonapi.PipelineRun, legacyLabelName string) bool {
+	componentName, isLegacy := pipelineRun.Labels[legacyLabelName]
+	if !isLegacy || legacyLabelName == ComponentNameLabelName {
+		return false
+	}
+	if _, isRelabeled := pipelineRun.Labels[ComponentNameLabelName]; isRelabeled {
+		return false
+	}
+	pipelineRun.Labels[ComponentNameLabelName] = componentName
+	return true
+}
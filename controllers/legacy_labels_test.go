@@ -0,0 +1,76 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+func TestRelabelLegacyPipelineRun(t *testing.T) {
+	const legacyLabelName = "appstudio.openshift.io/component"
+
+	tests := []struct {
+		name       string
+		labels     map[string]string
+		legacyName string
+		wantUpdate bool
+		wantLabel  string
+	}{
+		{
+			name:       "legacy PipelineRun",
+			labels:     map[string]string{legacyLabelName: "my-component"},
+			legacyName: legacyLabelName,
+			wantUpdate: true,
+			wantLabel:  "my-component",
+		},
+		{
+			name:       "already relabeled PipelineRun",
+			labels:     map[string]string{legacyLabelName: "my-component", ComponentNameLabelName: "my-component"},
+			legacyName: legacyLabelName,
+			wantUpdate: false,
+			wantLabel:  "my-component",
+		},
+		{
+			name:       "current PipelineRun",
+			labels:     map[string]string{ComponentNameLabelName: "my-component"},
+			legacyName: legacyLabelName,
+			wantUpdate: false,
+			wantLabel:  "my-component",
+		},
+		{
+			name:       "legacy key equals current key",
+			labels:     map[string]string{ComponentNameLabelName: "my-component"},
+			legacyName: ComponentNameLabelName,
+			wantUpdate: false,
+			wantLabel:  "my-component",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipelineRun := &tektonapi.PipelineRun{}
+			pipelineRun.Labels = tt.labels
+			if got := relabelLegacyPipelineRun(pipelineRun, tt.legacyName); got != tt.wantUpdate {
+				t.Errorf("relabelLegacyPipelineRun() = %v, want %v", got, tt.wantUpdate)
+			}
+			if got := pipelineRun.Labels[ComponentNameLabelName]; got != tt.wantLabel {
+				t.Errorf("relabelLegacyPipelineRun() label = %v, want %v", got, tt.wantLabel)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// MaintenanceModeConfigMapKey is the key of the maintenance ConfigMap, "true" pauses submission of all builds
+	MaintenanceModeConfigMapKey = "maintenance-mode"
+
+	// MaintenanceModeConditionType is set on components whose build waits for the end of the maintenance
+	MaintenanceModeConditionType = "MaintenanceMode"
+
+	MaintenanceModeReasonBuildsPaused  = "BuildsPaused"
+	MaintenanceModeReasonBuildsResumed = "BuildsResumed"
+)
+
+// newConfigMapCache returns a cache of the given ConfigMap only, e.g. the maintenance ConfigMap,
+// so the controller does not have to watch all ConfigMaps of the cluster. The cache is started by the manager.
+func newConfigMapCache(mgr ctrl.Manager, configMap types.NamespacedName) (cache.Cache, error) {
+	configMapCache, err := cache.New(mgr.GetConfig(), cache.Options{
+		Scheme:    mgr.GetScheme(),
+		Mapper:    mgr.GetRESTMapper(),
+		Namespace: configMap.Namespace,
+		SelectorsByObject: cache.SelectorsByObject{
+			&corev1.ConfigMap{}: {Field: fields.OneTermEqualSelector("metadata.name", configMap.Name)},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := mgr.Add(configMapCache); err != nil {
+		return nil, err
+	}
+	return configMapCache, nil
+}
+
+// isMaintenanceMode checks whether submission of builds is paused in the whole cluster.
+// Missing maintenance ConfigMap means the maintenance mode is off.
+func (r *ComponentBuildReconciler) isMaintenanceMode(ctx context.Context) (bool, error) {
+	if r.MaintenanceConfigMap == nil {
+		return false, nil
+	}
+	var reader client.Reader = r.Client
+	if r.maintenanceConfigMapCache != nil {
+		reader = r.maintenanceConfigMapCache
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := reader.Get(ctx, *r.MaintenanceConfigMap, configMap); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return configMap.Data[MaintenanceModeConfigMapKey] == "true", nil
+}
+
+// isMaintenanceConfigMap filters events of the maintenance ConfigMap.
+func (r *ComponentBuildReconciler) isMaintenanceConfigMap(object client.Object) bool {
+	return r.MaintenanceConfigMap != nil &&
+		object.GetName() == r.MaintenanceConfigMap.Name && object.GetNamespace() == r.MaintenanceConfigMap.Namespace
+}
+
+// getPausedComponents returns reconcile requests for all components which builds were paused by the maintenance mode.
+// This makes paused builds to be submitted right after the maintenance mode is lifted.
+func (r *ComponentBuildReconciler) getPausedComponents(object client.Object) []reconcile.Request {
+	components := &appstudiov1alpha1.ComponentList{}
+	if err := r.Client.List(context.Background(), components); err != nil {
+		r.Log.Error(err, "Failed to list components paused by the maintenance mode")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, component := range components.Items {
+		if !meta.IsStatusConditionTrue(component.Status.Conditions, MaintenanceModeConditionType) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: component.Name, Namespace: component.Namespace},
+		})
+	}
+	return requests
+}
+
+func getMaintenanceModeCondition(configMap types.NamespacedName) metav1.Condition {
+	return metav1.Condition{
+		Type:    MaintenanceModeConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  MaintenanceModeReasonBuildsPaused,
+		Message: fmt.Sprintf("Builds are paused by the maintenance mode set in ConfigMap %v. The build is queued", configMap),
+	}
+}
+
+// clearMaintenanceModeCondition marks the build of previously paused component as resumed.
+func clearMaintenanceModeCondition(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) error {
+	if !meta.IsStatusConditionTrue(component.Status.Conditions, MaintenanceModeConditionType) {
+		return nil
+	}
+	return setComponentCondition(ctx, cli, component, metav1.Condition{
+		Type:    MaintenanceModeConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  MaintenanceModeReasonBuildsResumed,
+		Message: "The maintenance mode has been lifted, the build has been submitted",
+	})
+}
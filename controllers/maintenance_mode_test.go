@@ -0,0 +1,69 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestIsMaintenanceConfigMap(t *testing.T) {
+	maintenanceConfigMap := types.NamespacedName{Name: "maintenance", Namespace: "build-service"}
+
+	tests := []struct {
+		name                 string
+		maintenanceConfigMap *types.NamespacedName
+		configMap            metav1.ObjectMeta
+		want                 bool
+	}{
+		{
+			name:                 "maintenance ConfigMap",
+			maintenanceConfigMap: &maintenanceConfigMap,
+			configMap:            metav1.ObjectMeta{Name: "maintenance", Namespace: "build-service"},
+			want:                 true,
+		},
+		{
+			name:                 "ConfigMap in another namespace",
+			maintenanceConfigMap: &maintenanceConfigMap,
+			configMap:            metav1.ObjectMeta{Name: "maintenance", Namespace: "user-namespace"},
+			want:                 false,
+		},
+		{
+			name:                 "another ConfigMap",
+			maintenanceConfigMap: &maintenanceConfigMap,
+			configMap:            metav1.ObjectMeta{Name: "build-summary", Namespace: "build-service"},
+			want:                 false,
+		},
+		{
+			name:                 "maintenance mode disabled",
+			maintenanceConfigMap: nil,
+			configMap:            metav1.ObjectMeta{Name: "maintenance", Namespace: "build-service"},
+			want:                 false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ComponentBuildReconciler{MaintenanceConfigMap: tt.maintenanceConfigMap}
+			if got := r.isMaintenanceConfigMap(&corev1.ConfigMap{ObjectMeta: tt.configMap}); got != tt.want {
+				t.Errorf("isMaintenanceConfigMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
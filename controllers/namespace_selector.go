@@ -0,0 +1,80 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+
+// isInSelectedNamespace checks whether the namespace of the given object matches the reconciler namespace selector.
+func (r *ComponentBuildReconciler) isInSelectedNamespace(object client.Object) bool {
+	selected, err := r.isSelectedNamespace(context.Background(), object.GetNamespace())
+	if err != nil {
+		r.Log.Error(err, fmt.Sprintf("Failed to get namespace %s", object.GetNamespace()))
+		return false
+	}
+	return selected
+}
+
+// isSelectedNamespace checks whether the given namespace matches the reconciler namespace selector.
+// Components are enqueued by watches of other resources too, so the selector is checked on reconcile as well.
+func (r *ComponentBuildReconciler) isSelectedNamespace(ctx context.Context, namespaceName string) (bool, error) {
+	if r.NamespaceSelector == nil {
+		return true, nil
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: namespaceName}, namespace); err != nil {
+		return false, err
+	}
+	return r.NamespaceSelector.Matches(labels.Set(namespace.Labels)), nil
+}
+
+// getSelectedNamespaceComponents returns reconcile requests for not yet reconciled components of the given namespace
+// if the namespace matches the reconciler namespace selector.
+// This makes components to be built right after their namespace is onboarded.
+func (r *ComponentBuildReconciler) getSelectedNamespaceComponents(object client.Object) []reconcile.Request {
+	if r.NamespaceSelector == nil || !r.NamespaceSelector.Matches(labels.Set(object.GetLabels())) {
+		return nil
+	}
+
+	components := &appstudiov1alpha1.ComponentList{}
+	if err := r.Client.List(context.Background(), components, client.InNamespace(object.GetName()),
+		client.MatchingFields{buildSpecChangedIndexKey: buildSpecChangedIndexValue}); err != nil {
+		r.Log.Error(err, fmt.Sprintf("Failed to list components in namespace %s", object.GetName()))
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(components.Items))
+	for _, component := range components.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: component.Name, Namespace: component.Namespace},
+		})
+	}
+	return requests
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+// selectedNamespaceClient returns the given component and namespace and records any other access
+type selectedNamespaceClient struct {
+	client.Client
+	component appstudiov1alpha1.Component
+	namespace corev1.Namespace
+	calls     []string
+}
+
+func (c *selectedNamespaceClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	switch obj := obj.(type) {
+	case *appstudiov1alpha1.Component:
+		c.component.DeepCopyInto(obj)
+	case *corev1.Namespace:
+		c.namespace.DeepCopyInto(obj)
+	default:
+		c.calls = append(c.calls, "get")
+	}
+	return nil
+}
+
+func (c *selectedNamespaceClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if components, ok := list.(*appstudiov1alpha1.ComponentList); ok {
+		components.Items = []appstudiov1alpha1.Component{c.component}
+		return nil
+	}
+	c.calls = append(c.calls, "list")
+	return nil
+}
+
+func (c *selectedNamespaceClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.calls = append(c.calls, "create")
+	return nil
+}
+
+func (c *selectedNamespaceClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.calls = append(c.calls, "update")
+	return nil
+}
+
+func (c *selectedNamespaceClient) Status() client.StatusWriter {
+	c.calls = append(c.calls, "status")
+	return nil
+}
+
+func TestReconcileComponentOfNotOnboardedNamespace(t *testing.T) {
+	component := getGitSourceComponent(nil, "version: 2.2.0")
+	component.Namespace = "not-onboarded-namespace"
+	component.Spec.Application = "my-application"
+	cli := &selectedNamespaceClient{
+		component: component,
+		namespace: corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "not-onboarded-namespace"}},
+	}
+	namespaceSelector, err := labels.Parse("build.appstudio.openshift.io/enabled=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &ComponentBuildReconciler{Client: cli, Log: ctrl.Log, NamespaceSelector: namespaceSelector}
+
+	// The component is enqueued by the Application watch, which is not filtered by the namespace selector
+	application := &appstudiov1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Name: "my-application", Namespace: component.Namespace}}
+	requests := r.getApplicationComponents(application)
+	if len(requests) != 1 {
+		t.Fatalf("getApplicationComponents() = %v, want the component", requests)
+	}
+
+	result, err := r.Reconcile(context.TODO(), requests[0])
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if result != (ctrl.Result{}) {
+		t.Errorf("Reconcile() = %v, want no requeue", result)
+	}
+	if len(cli.calls) > 0 {
+		t.Errorf("Reconcile() accessed %v of component in namespace not matching the selector", cli.calls)
+	}
+}
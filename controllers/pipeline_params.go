@@ -0,0 +1,118 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+// componentTemplateView is the restricted view of a component available to pipeline parameter templates.
+// Only fields listed here could be referenced, e.g. {{.Namespace}} or {{.Spec.ComponentName}}
+type componentTemplateView struct {
+	Name      string
+	Namespace string
+	Spec      componentSpecTemplateView
+}
+
+type componentSpecTemplateView struct {
+	ComponentName string
+	Application   string
+	Context       string
+}
+
+func getComponentTemplateView(component appstudiov1alpha1.Component) componentTemplateView {
+	return componentTemplateView{
+		Name:      component.Name,
+		Namespace: component.Namespace,
+		Spec: componentSpecTemplateView{
+			ComponentName: component.Spec.ComponentName,
+			Application:   component.Spec.Application,
+			Context:       component.Spec.Context,
+		},
+	}
+}
+
+// getPipelineParamsFromAnnotation returns additional pipeline parameters requested in the component annotation.
+// Parameter values are Go templates rendered against the restricted view of the component.
+// The parameters are sorted by name to produce the same PipelineRun for the same component.
+func getPipelineParamsFromAnnotation(component appstudiov1alpha1.Component) ([]tektonapi.Param, error) {
+	paramsJSON := component.Annotations[PipelineParamsAnnotationName]
+	if paramsJSON == "" {
+		return nil, nil
+	}
+
+	paramTemplates := map[string]string{}
+	if err := json.Unmarshal([]byte(paramsJSON), &paramTemplates); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation, JSON object of strings expected: %v", PipelineParamsAnnotationName, err)
+	}
+
+	names := make([]string, 0, len(paramTemplates))
+	for name := range paramTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	componentView := getComponentTemplateView(component)
+	params := make([]tektonapi.Param, 0, len(names))
+	for _, name := range names {
+		value, err := renderTemplate(paramTemplates[name], componentView)
+		if err != nil {
+			return nil, fmt.Errorf("pipeline parameter %s: %v", name, err)
+		}
+		params = append(params, tektonapi.Param{
+			Name: name,
+			Value: tektonapi.ArrayOrString{
+				Type:      tektonapi.ParamTypeString,
+				StringVal: value,
+			},
+		})
+	}
+	return params, nil
+}
+
+// mergePipelineParams sets the given parameters into the build, replacing parameters with the same name.
+func mergePipelineParams(build *tektonapi.PipelineRun, params []tektonapi.Param) {
+	for _, param := range params {
+		replaced := false
+		for i := range build.Spec.Params {
+			if build.Spec.Params[i].Name == param.Name {
+				build.Spec.Params[i] = param
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			build.Spec.Params = append(build.Spec.Params, param)
+		}
+	}
+}
+
+// getPipelineRunParam returns the string value of the given parameter of the PipelineRun or empty string if it is not set.
+func getPipelineRunParam(pipelineRun tektonapi.PipelineRun, name string) string {
+	for _, param := range pipelineRun.Spec.Params {
+		if param.Name == name {
+			return param.Value.StringVal
+		}
+	}
+	return ""
+}
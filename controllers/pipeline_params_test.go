@@ -0,0 +1,120 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+func getStringParam(name string, value string) tektonapi.Param {
+	return tektonapi.Param{
+		Name:  name,
+		Value: tektonapi.ArrayOrString{Type: tektonapi.ParamTypeString, StringVal: value},
+	}
+}
+
+func TestGetPipelineParamsFromAnnotation(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		wantErr    bool
+		want       []tektonapi.Param
+	}{
+		{
+			name:       "no annotation",
+			annotation: "",
+			wantErr:    false,
+			want:       nil,
+		},
+		{
+			name:       "static and interpolated values",
+			annotation: `{"profile": "{{.Namespace}}-{{.Spec.ComponentName}}", "cache": "true"}`,
+			wantErr:    false,
+			want: []tektonapi.Param{
+				getStringParam("cache", "true"),
+				getStringParam("profile", "my-namespace-my-component"),
+			},
+		},
+		{
+			name:       "disallowed field",
+			annotation: `{"secret": "{{.Spec.Secret}}"}`,
+			wantErr:    true,
+		},
+		{
+			name:       "status is not accessible",
+			annotation: `{"devfile": "{{.Status.Devfile}}"}`,
+			wantErr:    true,
+		},
+		{
+			name:       "not a JSON object of strings",
+			annotation: `{"depth": 1}`,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := appstudiov1alpha1.Component{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "my-component",
+					Namespace:   "my-namespace",
+					Annotations: map[string]string{PipelineParamsAnnotationName: tt.annotation},
+				},
+				Spec: appstudiov1alpha1.ComponentSpec{
+					ComponentName: "my-component",
+					Secret:        "my-secret",
+				},
+			}
+			got, err := getPipelineParamsFromAnnotation(component)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getPipelineParamsFromAnnotation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getPipelineParamsFromAnnotation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergePipelineParams(t *testing.T) {
+	build := &tektonapi.PipelineRun{
+		Spec: tektonapi.PipelineRunSpec{
+			Params: []tektonapi.Param{
+				getStringParam("git-url", "https://github.com/foo/bar"),
+				getStringParam("output-image", "quay.io/foo/bar"),
+			},
+		},
+	}
+	mergePipelineParams(build, []tektonapi.Param{
+		getStringParam("output-image", "quay.io/foo/baz"),
+		getStringParam("extra", "value"),
+	})
+
+	want := []tektonapi.Param{
+		getStringParam("git-url", "https://github.com/foo/bar"),
+		getStringParam("output-image", "quay.io/foo/baz"),
+		getStringParam("extra", "value"),
+	}
+	if !reflect.DeepEqual(build.Spec.Params, want) {
+		t.Errorf("mergePipelineParams() = %v, want %v", build.Spec.Params, want)
+	}
+}
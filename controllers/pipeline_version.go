@@ -0,0 +1,297 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"golang.org/x/mod/semver"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// Semver constraint of the build pipeline version, e.g. ">=1.2, <2" or "~1.4"
+	PipelineVersionConstraintAnnotationName = "build.appstudio.openshift.io/pipeline-version-constraint"
+	// PipelineVersionLabelName is the label of Pipelines with their semver version
+	PipelineVersionLabelName = "pipeline.tekton.dev/version"
+	// BuildPipelineLabelName is the label of versioned Pipelines with the name of the build pipeline they are a version of
+	BuildPipelineLabelName = "build.appstudio.openshift.io/pipeline"
+
+	// PipelineVersionUnsatisfiedConditionType is set on components without a build pipeline version satisfying their constraint
+	PipelineVersionUnsatisfiedConditionType = "PipelineVersionUnsatisfied"
+
+	PipelineVersionUnsatisfiedReasonInvalidConstraint = "InvalidConstraint"
+	PipelineVersionUnsatisfiedReasonNoCompatible      = "NoCompatibleVersion"
+	PipelineVersionUnsatisfiedReasonSelected          = "VersionSelected"
+)
+
+// Operators of the version comparators, longer operators go first
+var versionOperators = []string{">=", "<=", "!=", ">", "<", "=", "~", "^"}
+
+// versionComparator compares versions to the canonical semver version, e.g. v1.2.0
+type versionComparator struct {
+	operator string
+	version  string
+}
+
+// versionConstraint is a disjunction of conjunctions of version comparators
+type versionConstraint [][]versionComparator
+
+//+kubebuilder:rbac:groups=tekton.dev,resources=pipelines,verbs=get;list;watch
+
+// applyPipelineVersion replaces the build pipeline with the latest version of it satisfying the constraint
+// in the component annotation. The versions are Pipelines in the component namespace labeled with their version
+// and the name of the build pipeline.
+// An error is returned if there is no compatible version, so the build is retried later.
+func (r *ComponentBuildReconciler) applyPipelineVersion(ctx context.Context, component appstudiov1alpha1.Component, pipelineRun *tektonapi.PipelineRun) error {
+	constraintString := component.Annotations[PipelineVersionConstraintAnnotationName]
+	if constraintString == "" || pipelineRun.Spec.PipelineRef == nil {
+		return nil
+	}
+
+	constraint, err := parseVersionConstraint(constraintString)
+	if err != nil {
+		return r.setPipelineVersionUnsatisfied(ctx, component, PipelineVersionUnsatisfiedReasonInvalidConstraint,
+			fmt.Sprintf("Invalid %s annotation: %v", PipelineVersionConstraintAnnotationName, err))
+	}
+
+	pipelineName := pipelineRun.Spec.PipelineRef.Name
+	pipelines := &tektonapi.PipelineList{}
+	if err := r.Client.List(ctx, pipelines, client.InNamespace(component.Namespace),
+		client.MatchingLabels{BuildPipelineLabelName: pipelineName}, client.HasLabels{PipelineVersionLabelName}); err != nil {
+		return err
+	}
+
+	pipeline := selectPipelineVersion(pipelines.Items, constraint)
+	if pipeline == nil {
+		return r.setPipelineVersionUnsatisfied(ctx, component, PipelineVersionUnsatisfiedReasonNoCompatible,
+			fmt.Sprintf("No version of pipeline %s satisfies constraint %s", pipelineName, constraintString))
+	}
+	pipelineRun.Spec.PipelineRef = &tektonapi.PipelineRef{Name: pipeline.Name}
+
+	if !meta.IsStatusConditionTrue(component.Status.Conditions, PipelineVersionUnsatisfiedConditionType) {
+		return nil
+	}
+	return setComponentCondition(ctx, r.Client, component, metav1.Condition{
+		Type:    PipelineVersionUnsatisfiedConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  PipelineVersionUnsatisfiedReasonSelected,
+		Message: fmt.Sprintf("Pipeline %s version %s is selected", pipeline.Name, pipeline.Labels[PipelineVersionLabelName]),
+	})
+}
+
+// setPipelineVersionUnsatisfied sets the PipelineVersionUnsatisfied condition and returns the message as an error.
+func (r *ComponentBuildReconciler) setPipelineVersionUnsatisfied(ctx context.Context, component appstudiov1alpha1.Component, reason string, message string) error {
+	if err := setComponentCondition(ctx, r.Client, component, metav1.Condition{
+		Type:    PipelineVersionUnsatisfiedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	}); err != nil {
+		return err
+	}
+	return fmt.Errorf("%s", message)
+}
+
+// selectPipelineVersion returns the Pipeline with the latest version satisfying the constraint or nil if there is none.
+// Pipelines with invalid versions are ignored.
+func selectPipelineVersion(pipelines []tektonapi.Pipeline, constraint versionConstraint) *tektonapi.Pipeline {
+	var selected *tektonapi.Pipeline
+	selectedVersion := ""
+	for i := range pipelines {
+		version, _, err := canonicalVersion(pipelines[i].Labels[PipelineVersionLabelName])
+		if err != nil || !constraint.matches(version) {
+			continue
+		}
+		if selected == nil || semver.Compare(version, selectedVersion) > 0 {
+			selected = &pipelines[i]
+			selectedVersion = version
+		}
+	}
+	return selected
+}
+
+// parseVersionConstraint parses alternatives separated by || of comparators separated by commas or spaces.
+// Supported operators are =, !=, >, >=, <, <=, ~ (patch updates) and ^ (compatible updates),
+// a partial version without operator matches all versions with the given prefix, e.g. 1.2 matches 1.2.5.
+func parseVersionConstraint(constraint string) (versionConstraint, error) {
+	var parsed versionConstraint
+	for _, alternative := range strings.Split(constraint, "||") {
+		tokens := strings.Fields(strings.ReplaceAll(alternative, ",", " "))
+		var comparators []versionComparator
+		for i := 0; i < len(tokens); i++ {
+			token := tokens[i]
+			if isVersionOperator(token) && i+1 < len(tokens) {
+				// The operator is separated from the version by a space, e.g. ">= 1.2"
+				i++
+				token += tokens[i]
+			}
+			tokenComparators, err := parseVersionComparator(token)
+			if err != nil {
+				return nil, err
+			}
+			comparators = append(comparators, tokenComparators...)
+		}
+		if len(comparators) == 0 {
+			return nil, fmt.Errorf("empty version constraint alternative")
+		}
+		parsed = append(parsed, comparators)
+	}
+	return parsed, nil
+}
+
+func isVersionOperator(token string) bool {
+	for _, operator := range versionOperators {
+		if token == operator {
+			return true
+		}
+	}
+	return false
+}
+
+// parseVersionComparator returns comparators of a single operator and version, ranges result in two comparators.
+func parseVersionComparator(token string) ([]versionComparator, error) {
+	operator := "="
+	for _, versionOperator := range versionOperators {
+		if strings.HasPrefix(token, versionOperator) {
+			operator = versionOperator
+			token = strings.TrimPrefix(token, versionOperator)
+			break
+		}
+	}
+	version, parts, err := canonicalVersion(token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Index of the version part bumped to get the exclusive upper bound of the range
+	bumpedPart := -1
+	switch operator {
+	case "=":
+		if parts < 3 {
+			bumpedPart = parts - 1
+		}
+	case "~":
+		bumpedPart = parts - 1
+		if bumpedPart > 1 {
+			bumpedPart = 1
+		}
+	case "^":
+		bumpedPart = getCaretBumpedPart(version, parts)
+	}
+	if bumpedPart < 0 {
+		return []versionComparator{{operator: operator, version: version}}, nil
+	}
+	return []versionComparator{
+		{operator: ">=", version: version},
+		{operator: "<", version: bumpVersion(version, bumpedPart)},
+	}, nil
+}
+
+// canonicalVersion returns the canonical semver form of the version with optional v prefix, e.g. 1.2 is v1.2.0,
+// and the number of its numeric parts.
+func canonicalVersion(version string) (string, int, error) {
+	version = "v" + strings.TrimPrefix(version, "v")
+	if !semver.IsValid(version) {
+		return "", 0, fmt.Errorf("invalid version %s", strings.TrimPrefix(version, "v"))
+	}
+	core := strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+	return semver.Canonical(version), len(strings.Split(core, ".")), nil
+}
+
+// getCaretBumpedPart returns the index of the left-most non-zero part of the version within the given parts,
+// changes of the parts left to it are not compatible.
+func getCaretBumpedPart(version string, parts int) int {
+	numbers := getVersionNumbers(version)
+	for i := 0; i < parts-1; i++ {
+		if numbers[i] != 0 {
+			return i
+		}
+	}
+	return parts - 1
+}
+
+// bumpVersion increments the part of the canonical version with the given index and zeroes the following parts.
+func bumpVersion(version string, part int) string {
+	numbers := getVersionNumbers(version)
+	numbers[part]++
+	for i := part + 1; i < len(numbers); i++ {
+		numbers[i] = 0
+	}
+	return fmt.Sprintf("v%d.%d.%d", numbers[0], numbers[1], numbers[2])
+}
+
+// getVersionNumbers returns major, minor and patch numbers of the canonical version.
+func getVersionNumbers(version string) []int {
+	core := strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+	numbers := make([]int, 3)
+	for i, number := range strings.SplitN(core, ".", 3) {
+		numbers[i], _ = strconv.Atoi(number)
+	}
+	return numbers
+}
+
+// matches checks whether the canonical version satisfies the constraint.
+func (c versionConstraint) matches(version string) bool {
+	for _, comparators := range c {
+		satisfied := true
+		for _, comparator := range comparators {
+			if !comparator.matches(version) {
+				satisfied = false
+				break
+			}
+		}
+		if satisfied {
+			return true
+		}
+	}
+	return false
+}
+
+func (c versionComparator) matches(version string) bool {
+	result := semver.Compare(version, c.version)
+	switch c.operator {
+	case "=":
+		return result == 0
+	case "!=":
+		return result != 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	default:
+		return false
+	}
+}
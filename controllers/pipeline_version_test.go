@@ -0,0 +1,184 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseVersionConstraint(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint string
+		matching   []string
+		other      []string
+		wantErr    bool
+	}{
+		{
+			name:       "exact version",
+			constraint: "1.2.3",
+			matching:   []string{"v1.2.3"},
+			other:      []string{"v1.2.4", "v1.3.0"},
+		},
+		{
+			name:       "partial version",
+			constraint: "1.2",
+			matching:   []string{"v1.2.0", "v1.2.9"},
+			other:      []string{"v1.1.9", "v1.3.0"},
+		},
+		{
+			name:       "range",
+			constraint: ">=1.2, <2",
+			matching:   []string{"v1.2.0", "v1.9.9"},
+			other:      []string{"v1.1.9", "v2.0.0"},
+		},
+		{
+			name:       "operators separated by spaces",
+			constraint: ">= 1.2 < 2 != 1.5.0",
+			matching:   []string{"v1.2.0", "v1.5.1"},
+			other:      []string{"v1.5.0", "v2.0.0"},
+		},
+		{
+			name:       "tilde",
+			constraint: "~1.4.2",
+			matching:   []string{"v1.4.2", "v1.4.9"},
+			other:      []string{"v1.4.1", "v1.5.0"},
+		},
+		{
+			name:       "tilde major version",
+			constraint: "~1",
+			matching:   []string{"v1.0.0", "v1.9.0"},
+			other:      []string{"v2.0.0"},
+		},
+		{
+			name:       "caret",
+			constraint: "^1.2.3",
+			matching:   []string{"v1.2.3", "v1.9.0"},
+			other:      []string{"v1.2.2", "v2.0.0"},
+		},
+		{
+			name:       "caret zero major version",
+			constraint: "^0.2.3",
+			matching:   []string{"v0.2.3", "v0.2.9"},
+			other:      []string{"v0.3.0"},
+		},
+		{
+			name:       "caret zero minor version",
+			constraint: "^0.0.3",
+			matching:   []string{"v0.0.3"},
+			other:      []string{"v0.0.4"},
+		},
+		{
+			name:       "alternatives",
+			constraint: "<1 || >=2.1",
+			matching:   []string{"v0.9.0", "v2.1.0"},
+			other:      []string{"v1.0.0", "v2.0.9"},
+		},
+		{
+			name:       "v prefix",
+			constraint: ">v1.2",
+			matching:   []string{"v1.2.1"},
+			other:      []string{"v1.2.0"},
+		},
+		{
+			name:       "invalid version",
+			constraint: ">=1.x",
+			wantErr:    true,
+		},
+		{
+			name:       "empty alternative",
+			constraint: "1.2 ||",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			constraint, err := parseVersionConstraint(tt.constraint)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseVersionConstraint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, version := range tt.matching {
+				if !constraint.matches(version) {
+					t.Errorf("parseVersionConstraint(%q).matches(%s) = false, want true", tt.constraint, version)
+				}
+			}
+			for _, version := range tt.other {
+				if constraint.matches(version) {
+					t.Errorf("parseVersionConstraint(%q).matches(%s) = true, want false", tt.constraint, version)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectPipelineVersion(t *testing.T) {
+	getPipeline := func(name string, version string) tektonapi.Pipeline {
+		return tektonapi.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{PipelineVersionLabelName: version},
+			},
+		}
+	}
+	pipelines := []tektonapi.Pipeline{
+		getPipeline("docker-build-v1-2", "1.2.0"),
+		getPipeline("docker-build-v1-10", "1.10.0"),
+		getPipeline("docker-build-v2", "2.0.0"),
+		getPipeline("docker-build-invalid", "latest"),
+	}
+
+	tests := []struct {
+		name       string
+		constraint string
+		want       string
+	}{
+		{
+			name:       "latest compatible version",
+			constraint: "^1.0",
+			want:       "docker-build-v1-10",
+		},
+		{
+			name:       "latest version",
+			constraint: ">=1",
+			want:       "docker-build-v2",
+		},
+		{
+			name:       "no compatible version",
+			constraint: "^3",
+			want:       "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			constraint, err := parseVersionConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("parseVersionConstraint() error = %v", err)
+			}
+			got := selectPipelineVersion(pipelines, constraint)
+			gotName := ""
+			if got != nil {
+				gotName = got.Name
+			}
+			if gotName != tt.want {
+				t.Errorf("selectPipelineVersion() = %s, want %s", gotName, tt.want)
+			}
+		})
+	}
+}
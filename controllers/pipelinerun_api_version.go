@@ -0,0 +1,193 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+// PipelineRunAPIVersion is the Tekton API version the build PipelineRuns are created with.
+// The PipelineRuns are generated as v1beta1 and converted to v1 before creation if needed.
+type PipelineRunAPIVersion string
+
+const (
+	// PipelineRunAPIVersionV1Beta1 creates the build PipelineRuns as tekton.dev/v1beta1
+	PipelineRunAPIVersionV1Beta1 PipelineRunAPIVersion = "v1beta1"
+	// PipelineRunAPIVersionV1 creates the build PipelineRuns as tekton.dev/v1
+	PipelineRunAPIVersionV1 PipelineRunAPIVersion = "v1"
+
+	// PipelineRunAPIVersionAnnotationName is the annotation of a Component with the API version of its build PipelineRuns,
+	// it takes precedence over the version configured for the whole cluster
+	PipelineRunAPIVersionAnnotationName = "build.appstudio.openshift.io/pipelinerun-api-version"
+)
+
+// ParsePipelineRunAPIVersion returns the PipelineRun API version with the given name.
+// Empty name means the preferred version served by the cluster.
+func ParsePipelineRunAPIVersion(name string) (PipelineRunAPIVersion, error) {
+	switch version := PipelineRunAPIVersion(name); version {
+	case "", PipelineRunAPIVersionV1Beta1, PipelineRunAPIVersionV1:
+		return version, nil
+	default:
+		return "", fmt.Errorf("unknown PipelineRun API version %q, expected one of %s, %s", name,
+			PipelineRunAPIVersionV1Beta1, PipelineRunAPIVersionV1)
+	}
+}
+
+// getPipelineRunAPIVersion returns the API version the build PipelineRun of the component is created with.
+func (r *ComponentBuildReconciler) getPipelineRunAPIVersion(component appstudiov1alpha1.Component) (PipelineRunAPIVersion, error) {
+	if annotation, exists := component.Annotations[PipelineRunAPIVersionAnnotationName]; exists {
+		version, err := ParsePipelineRunAPIVersion(annotation)
+		if err != nil {
+			return "", fmt.Errorf("invalid annotation %s: %v", PipelineRunAPIVersionAnnotationName, err)
+		}
+		if version != "" {
+			return version, nil
+		}
+	}
+	if r.PipelineRunAPIVersion != "" {
+		return r.PipelineRunAPIVersion, nil
+	}
+	return r.getPreferredPipelineRunAPIVersion()
+}
+
+// getPreferredPipelineRunAPIVersion returns the preferred PipelineRun API version served by the cluster.
+// Versions the build service can't generate fall back to v1beta1.
+func (r *ComponentBuildReconciler) getPreferredPipelineRunAPIVersion() (PipelineRunAPIVersion, error) {
+	mapping, err := r.Client.RESTMapper().RESTMapping(schema.GroupKind{Group: tektonapi.SchemeGroupVersion.Group, Kind: "PipelineRun"})
+	if err != nil {
+		return "", err
+	}
+	if version := PipelineRunAPIVersion(mapping.GroupVersionKind.Version); version == PipelineRunAPIVersionV1 {
+		return version, nil
+	}
+	return PipelineRunAPIVersionV1Beta1, nil
+}
+
+// createBuildPipelineRun creates the build PipelineRun with the given API version.
+// The metadata assigned on creation, e.g. the generated name, are set to the given PipelineRun.
+func (r *ComponentBuildReconciler) createBuildPipelineRun(ctx context.Context, pipelineRun *tektonapi.PipelineRun, version PipelineRunAPIVersion) error {
+	if version != PipelineRunAPIVersionV1 {
+		return r.Client.Create(ctx, pipelineRun)
+	}
+
+	v1PipelineRun, err := convertPipelineRunToV1(*pipelineRun)
+	if err != nil {
+		return err
+	}
+	if err := r.Client.Create(ctx, v1PipelineRun); err != nil {
+		return err
+	}
+	pipelineRun.Name = v1PipelineRun.GetName()
+	pipelineRun.UID = v1PipelineRun.GetUID()
+	pipelineRun.ResourceVersion = v1PipelineRun.GetResourceVersion()
+	pipelineRun.CreationTimestamp = v1PipelineRun.GetCreationTimestamp()
+	return nil
+}
+
+// convertPipelineRunToV1 returns the v1beta1 PipelineRun as tekton.dev/v1 PipelineRun.
+// Only the fields the build service generates are converted, PipelineResources are not supported by v1.
+func convertPipelineRunToV1(pipelineRun tektonapi.PipelineRun) (*unstructured.Unstructured, error) {
+	if len(pipelineRun.Spec.Resources) > 0 || len(pipelineRun.Spec.ServiceAccountNames) > 0 {
+		return nil, fmt.Errorf("PipelineRun %s uses resources or serviceAccountNames not supported by tekton.dev/v1", pipelineRun.Name)
+	}
+
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&pipelineRun)
+	if err != nil {
+		return nil, err
+	}
+	v1PipelineRun := &unstructured.Unstructured{Object: object}
+	v1PipelineRun.SetAPIVersion(tektonapi.SchemeGroupVersion.Group + "/" + string(PipelineRunAPIVersionV1))
+	v1PipelineRun.SetKind("PipelineRun")
+	unstructured.RemoveNestedField(object, "status")
+
+	spec, _ := object["spec"].(map[string]interface{})
+	if spec == nil {
+		return v1PipelineRun, nil
+	}
+
+	if pipelineRef, _ := spec["pipelineRef"].(map[string]interface{}); pipelineRef != nil {
+		convertPipelineRefToV1(pipelineRef)
+	}
+
+	// Pipeline level Service Account and pod template are moved to the TaskRun template
+	taskRunTemplate := map[string]interface{}{}
+	moveField(spec, "serviceAccountName", taskRunTemplate, "serviceAccountName")
+	moveField(spec, "podTemplate", taskRunTemplate, "podTemplate")
+	if len(taskRunTemplate) > 0 {
+		spec["taskRunTemplate"] = taskRunTemplate
+	}
+
+	// The deprecated timeout is replaced by the pipeline timeout
+	if timeout, exists := spec["timeout"]; exists {
+		delete(spec, "timeout")
+		timeouts, _ := spec["timeouts"].(map[string]interface{})
+		if timeouts == nil {
+			timeouts = map[string]interface{}{}
+			spec["timeouts"] = timeouts
+		}
+		if _, exists := timeouts["pipeline"]; !exists {
+			timeouts["pipeline"] = timeout
+		}
+	}
+
+	taskRunSpecs, _ := spec["taskRunSpecs"].([]interface{})
+	for _, taskRunSpec := range taskRunSpecs {
+		if taskRunSpec, ok := taskRunSpec.(map[string]interface{}); ok {
+			moveField(taskRunSpec, "taskServiceAccountName", taskRunSpec, "serviceAccountName")
+			moveField(taskRunSpec, "taskPodTemplate", taskRunSpec, "podTemplate")
+			moveField(taskRunSpec, "stepOverrides", taskRunSpec, "stepSpecs")
+			moveField(taskRunSpec, "sidecarOverrides", taskRunSpec, "sidecarSpecs")
+		}
+	}
+
+	return v1PipelineRun, nil
+}
+
+// convertPipelineRefToV1 replaces the bundle of the pipeline reference with the bundles resolver.
+func convertPipelineRefToV1(pipelineRef map[string]interface{}) {
+	moveField(pipelineRef, "resource", pipelineRef, "params")
+
+	bundle, _ := pipelineRef["bundle"].(string)
+	if bundle == "" {
+		return
+	}
+	name, _ := pipelineRef["name"].(string)
+	delete(pipelineRef, "bundle")
+	delete(pipelineRef, "name")
+	pipelineRef["resolver"] = "bundles"
+	pipelineRef["params"] = []interface{}{
+		map[string]interface{}{"name": "bundle", "value": bundle},
+		map[string]interface{}{"name": "name", "value": name},
+		map[string]interface{}{"name": "kind", "value": "pipeline"},
+	}
+}
+
+// moveField moves the field to the given field of the target object, if the field is set.
+func moveField(source map[string]interface{}, field string, target map[string]interface{}, targetField string) {
+	if value, exists := source[field]; exists {
+		delete(source, field)
+		target[targetField] = value
+	}
+}
@@ -0,0 +1,300 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const testV1PipelineRunYAML = `
+apiVersion: tekton.dev/v1
+kind: PipelineRun
+metadata:
+  generateName: my-component-
+  namespace: my-namespace
+  creationTimestamp: null
+  labels:
+    build.appstudio.openshift.io/component: my-component
+spec:
+  pipelineRef:
+    resolver: bundles
+    params:
+    - name: bundle
+      value: quay.io/redhat-appstudio/build-templates-bundle:v0.1.3
+    - name: name
+      value: docker-build
+    - name: kind
+      value: pipeline
+  params:
+  - name: git-url
+    value: https://github.com/foo/bar
+  taskRunTemplate:
+    serviceAccountName: pipeline
+    podTemplate:
+      nodeSelector:
+        build: "true"
+  timeouts:
+    pipeline: 1h0m0s
+  taskRunSpecs:
+  - pipelineTaskName: build-container
+    stepSpecs:
+    - name: build
+      resources:
+        requests:
+          memory: 4Gi
+  workspaces:
+  - name: workspace
+    persistentVolumeClaim:
+      claimName: appstudio
+`
+
+func getAPIVersionTestPipelineRun() tektonapi.PipelineRun {
+	return tektonapi.PipelineRun{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "tekton.dev/v1beta1",
+			Kind:       "PipelineRun",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "my-component-",
+			Namespace:    "my-namespace",
+			Labels:       map[string]string{ComponentNameLabelName: "my-component"},
+		},
+		Spec: tektonapi.PipelineRunSpec{
+			PipelineRef:        &tektonapi.PipelineRef{Name: "docker-build", Bundle: "quay.io/redhat-appstudio/build-templates-bundle:v0.1.3"},
+			Params:             []tektonapi.Param{{Name: "git-url", Value: *tektonapi.NewArrayOrString("https://github.com/foo/bar")}},
+			ServiceAccountName: "pipeline",
+			PodTemplate:        &tektonapi.PodTemplate{NodeSelector: map[string]string{"build": "true"}},
+			Timeout:            &metav1.Duration{Duration: time.Hour},
+			TaskRunSpecs: []tektonapi.PipelineTaskRunSpec{{
+				PipelineTaskName: "build-container",
+				StepOverrides: []tektonapi.TaskRunStepOverride{{
+					Name:      "build",
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")}},
+				}},
+			}},
+			Workspaces: []tektonapi.WorkspaceBinding{
+				{Name: "workspace", PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "appstudio"}},
+			},
+		},
+	}
+}
+
+// toJSONObject returns the object as generic JSON object, so objects of different types are comparable
+func toJSONObject(t *testing.T, object interface{}) map[string]interface{} {
+	objectJSON, err := json.Marshal(object)
+	if err != nil {
+		t.Fatalf("failed to marshal object: %v", err)
+	}
+	jsonObject := map[string]interface{}{}
+	if err := json.Unmarshal(objectJSON, &jsonObject); err != nil {
+		t.Fatalf("failed to unmarshal object: %v", err)
+	}
+	return jsonObject
+}
+
+func TestConvertPipelineRunToV1(t *testing.T) {
+	got, err := convertPipelineRunToV1(getAPIVersionTestPipelineRun())
+	if err != nil {
+		t.Fatalf("convertPipelineRunToV1() error = %v", err)
+	}
+
+	want := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(testV1PipelineRunYAML), &want); err != nil {
+		t.Fatalf("failed to parse expected PipelineRun: %v", err)
+	}
+	if gotObject := toJSONObject(t, got.Object); !reflect.DeepEqual(gotObject, want) {
+		t.Errorf("convertPipelineRunToV1() = %v, want %v", gotObject, want)
+	}
+
+	pipelineRun := getAPIVersionTestPipelineRun()
+	pipelineRun.Spec.Resources = []tektonapi.PipelineResourceBinding{{Name: "source"}}
+	if _, err := convertPipelineRunToV1(pipelineRun); err == nil {
+		t.Errorf("convertPipelineRunToV1() error = %v, want error for PipelineResources", err)
+	}
+}
+
+type pipelineRunCreateClient struct {
+	client.Client
+	created    client.Object
+	restMapper meta.RESTMapper
+}
+
+func (c *pipelineRunCreateClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	obj.SetName(obj.GetGenerateName() + "abcde")
+	c.created = obj
+	return nil
+}
+
+func (c *pipelineRunCreateClient) RESTMapper() meta.RESTMapper {
+	return c.restMapper
+}
+
+func TestCreateBuildPipelineRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		version PipelineRunAPIVersion
+		want    string
+	}{
+		{name: "v1beta1", version: PipelineRunAPIVersionV1Beta1, want: "tekton.dev/v1beta1"},
+		{name: "v1", version: PipelineRunAPIVersionV1, want: "tekton.dev/v1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := &pipelineRunCreateClient{}
+			r := &ComponentBuildReconciler{Client: cli}
+			pipelineRun := getAPIVersionTestPipelineRun()
+			if err := r.createBuildPipelineRun(context.TODO(), &pipelineRun, tt.version); err != nil {
+				t.Fatalf("createBuildPipelineRun() error = %v", err)
+			}
+
+			if got := cli.created.GetObjectKind().GroupVersionKind().GroupVersion().String(); got != tt.want {
+				t.Errorf("createBuildPipelineRun() created %v, want %v", got, tt.want)
+			}
+			if pipelineRun.Name != "my-component-abcde" {
+				t.Errorf("createBuildPipelineRun() name = %v, want %v", pipelineRun.Name, "my-component-abcde")
+			}
+
+			// Both versions have the same metadata and parameters
+			created := toJSONObject(t, cli.created)
+			if got, want := created["metadata"], toJSONObject(t, pipelineRun)["metadata"]; !reflect.DeepEqual(got, want) {
+				t.Errorf("createBuildPipelineRun() metadata = %v, want %v", got, want)
+			}
+			params, _, _ := unstructured.NestedSlice(created, "spec", "params")
+			if want := toJSONObject(t, pipelineRun)["spec"].(map[string]interface{})["params"]; !reflect.DeepEqual(params, want) {
+				t.Errorf("createBuildPipelineRun() params = %v, want %v", params, want)
+			}
+		})
+	}
+}
+
+func TestGetPipelineRunAPIVersion(t *testing.T) {
+	getRESTMapper := func(versions ...string) meta.RESTMapper {
+		var groupVersions []schema.GroupVersion
+		for _, version := range versions {
+			groupVersions = append(groupVersions, schema.GroupVersion{Group: "tekton.dev", Version: version})
+		}
+		restMapper := meta.NewDefaultRESTMapper(groupVersions)
+		for _, groupVersion := range groupVersions {
+			restMapper.Add(groupVersion.WithKind("PipelineRun"), meta.RESTScopeNamespace)
+		}
+		return restMapper
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		option      PipelineRunAPIVersion
+		served      []string
+		want        PipelineRunAPIVersion
+		wantErr     bool
+	}{
+		{
+			name:   "preferred v1beta1",
+			served: []string{"v1beta1", "v1"},
+			want:   PipelineRunAPIVersionV1Beta1,
+		},
+		{
+			name:   "preferred v1",
+			served: []string{"v1", "v1beta1"},
+			want:   PipelineRunAPIVersionV1,
+		},
+		{
+			name:   "unsupported preferred version",
+			served: []string{"v2"},
+			want:   PipelineRunAPIVersionV1Beta1,
+		},
+		{
+			name:   "option",
+			option: PipelineRunAPIVersionV1,
+			served: []string{"v1beta1", "v1"},
+			want:   PipelineRunAPIVersionV1,
+		},
+		{
+			name:        "annotation takes precedence",
+			annotations: map[string]string{PipelineRunAPIVersionAnnotationName: "v1beta1"},
+			option:      PipelineRunAPIVersionV1,
+			want:        PipelineRunAPIVersionV1Beta1,
+		},
+		{
+			name:        "empty annotation",
+			annotations: map[string]string{PipelineRunAPIVersionAnnotationName: ""},
+			option:      PipelineRunAPIVersionV1,
+			want:        PipelineRunAPIVersionV1,
+		},
+		{
+			name:        "invalid annotation",
+			annotations: map[string]string{PipelineRunAPIVersionAnnotationName: "v1alpha1"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ComponentBuildReconciler{
+				Client:                &pipelineRunCreateClient{restMapper: getRESTMapper(tt.served...)},
+				PipelineRunAPIVersion: tt.option,
+			}
+			component := appstudiov1alpha1.Component{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			got, err := r.getPipelineRunAPIVersion(component)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getPipelineRunAPIVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("getPipelineRunAPIVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePipelineRunAPIVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    PipelineRunAPIVersion
+		wantErr bool
+	}{
+		{name: "", want: ""},
+		{name: "v1beta1", want: PipelineRunAPIVersionV1Beta1},
+		{name: "v1", want: PipelineRunAPIVersionV1},
+		{name: "v1alpha1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePipelineRunAPIVersion(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePipelineRunAPIVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePipelineRunAPIVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
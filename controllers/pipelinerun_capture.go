@@ -0,0 +1,122 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/yaml"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// If set to "true", the generated build PipelineRun is stored as YAML in the PipelineRun capture ConfigMap
+	CapturePipelineRunAnnotationName = "build.appstudio.openshift.io/capture-pipelinerun"
+	// Suffix of the name of the ConfigMap that holds the last generated build PipelineRun of a component
+	PipelineRunCaptureConfigMapSuffix = "-pipelinerun"
+	// Data key within the PipelineRun capture ConfigMap that holds the PipelineRun YAML
+	PipelineRunCaptureConfigMapKey = "pipelinerun.yaml"
+
+	// Captured PipelineRuns are kept well below the ConfigMap size limit
+	maxCapturedPipelineRunSize = 256 * 1024
+
+	redactedValue = "REDACTED"
+)
+
+// Lowercase fragments of names of pipeline parameters whose values are redacted in the captured PipelineRun
+var sensitiveParamNameFragments = []string{
+	"secret",
+	"token",
+	"password",
+	"credential",
+}
+
+// capturePipelineRun stores the generated build PipelineRun with redacted secret references
+// in the PipelineRun capture ConfigMap of the component.
+func (r *ComponentBuildReconciler) capturePipelineRun(ctx context.Context, component appstudiov1alpha1.Component, pipelineRun tektonapi.PipelineRun) error {
+	pipelineRunYAML, err := getCapturedPipelineRun(pipelineRun)
+	if err != nil {
+		return err
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      component.Name + PipelineRunCaptureConfigMapSuffix,
+			Namespace: component.Namespace,
+		},
+	}
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		configMap.Data = map[string]string{
+			PipelineRunCaptureConfigMapKey: pipelineRunYAML,
+		}
+		return controllerutil.SetOwnerReference(&component, configMap, r.Scheme)
+	})
+	return err
+}
+
+// getCapturedPipelineRun returns YAML of the PipelineRun with redacted secret references.
+// A comment is returned instead of the YAML if the PipelineRun is too large.
+func getCapturedPipelineRun(pipelineRun tektonapi.PipelineRun) (string, error) {
+	capturedPipelineRun := pipelineRun.DeepCopy()
+	redactPipelineRun(capturedPipelineRun)
+
+	pipelineRunYAML, err := yaml.Marshal(capturedPipelineRun)
+	if err != nil {
+		return "", err
+	}
+	if len(pipelineRunYAML) > maxCapturedPipelineRunSize {
+		return fmt.Sprintf("# The PipelineRun is not captured as its size %d bytes exceeds the limit of %d bytes\n",
+			len(pipelineRunYAML), maxCapturedPipelineRunSize), nil
+	}
+	return string(pipelineRunYAML), nil
+}
+
+// redactPipelineRun replaces Secret names and values of sensitive parameters in the PipelineRun.
+func redactPipelineRun(pipelineRun *tektonapi.PipelineRun) {
+	for i := range pipelineRun.Spec.Workspaces {
+		if secret := pipelineRun.Spec.Workspaces[i].Secret; secret != nil {
+			secret.SecretName = redactedValue
+		}
+	}
+
+	if podTemplate := pipelineRun.Spec.PodTemplate; podTemplate != nil {
+		for i := range podTemplate.ImagePullSecrets {
+			podTemplate.ImagePullSecrets[i].Name = redactedValue
+		}
+	}
+
+	for i := range pipelineRun.Spec.Params {
+		if !isSensitiveParam(pipelineRun.Spec.Params[i].Name) {
+			continue
+		}
+		pipelineRun.Spec.Params[i].Value = tektonapi.ArrayOrString{
+			Type:      tektonapi.ParamTypeString,
+			StringVal: redactedValue,
+		}
+	}
+}
+
+func isSensitiveParam(name string) bool {
+	return containsAny(strings.ToLower(name), sensitiveParamNameFragments)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/pod"
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func getCaptureTestPipelineRun() tektonapi.PipelineRun {
+	return tektonapi.PipelineRun{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "tekton.dev/v1beta1",
+			Kind:       "PipelineRun",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "my-component-",
+			Namespace:    "my-namespace",
+			Labels:       map[string]string{ComponentNameLabelName: "my-component"},
+		},
+		Spec: tektonapi.PipelineRunSpec{
+			PipelineRef: &tektonapi.PipelineRef{Name: "docker-build", Bundle: "quay.io/redhat-appstudio/build-templates-bundle:v0.1.3"},
+			Params: []tektonapi.Param{
+				{Name: "git-url", Value: *tektonapi.NewArrayOrString("https://github.com/foo/bar")},
+				{Name: "output-image", Value: *tektonapi.NewArrayOrString("quay.io/foo/bar:latest")},
+				{Name: "access-token", Value: *tektonapi.NewArrayOrString("s3cr3t")},
+			},
+			Workspaces: []tektonapi.WorkspaceBinding{
+				{Name: "workspace", PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "appstudio"}},
+				{Name: "git-auth", Secret: &corev1.SecretVolumeSource{SecretName: "my-git-secret"}},
+			},
+			PodTemplate: &pod.Template{
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "my-pull-secret"}},
+			},
+		},
+	}
+}
+
+func TestGetCapturedPipelineRun(t *testing.T) {
+	pipelineRun := getCaptureTestPipelineRun()
+
+	capturedYAML, err := getCapturedPipelineRun(pipelineRun)
+	if err != nil {
+		t.Fatalf("getCapturedPipelineRun() error = %v", err)
+	}
+	if strings.Contains(capturedYAML, "s3cr3t") || strings.Contains(capturedYAML, "my-git-secret") || strings.Contains(capturedYAML, "my-pull-secret") {
+		t.Errorf("getCapturedPipelineRun() = %v, want secret references redacted", capturedYAML)
+	}
+
+	var capturedPipelineRun tektonapi.PipelineRun
+	if err := yaml.Unmarshal([]byte(capturedYAML), &capturedPipelineRun); err != nil {
+		t.Fatalf("failed to parse captured PipelineRun: %v", err)
+	}
+
+	// The captured PipelineRun must match the submitted one except for the redacted fields
+	wantPipelineRun := getCaptureTestPipelineRun()
+	wantPipelineRun.Spec.Params[2].Value = *tektonapi.NewArrayOrString(redactedValue)
+	wantPipelineRun.Spec.Workspaces[1].Secret.SecretName = redactedValue
+	wantPipelineRun.Spec.PodTemplate.ImagePullSecrets[0].Name = redactedValue
+	if !reflect.DeepEqual(capturedPipelineRun, wantPipelineRun) {
+		t.Errorf("getCapturedPipelineRun() = %v, want %v", capturedPipelineRun, wantPipelineRun)
+	}
+
+	// The submitted PipelineRun must not be modified
+	if !reflect.DeepEqual(pipelineRun, getCaptureTestPipelineRun()) {
+		t.Errorf("getCapturedPipelineRun() modified the given PipelineRun")
+	}
+}
+
+func TestGetCapturedPipelineRunSizeLimit(t *testing.T) {
+	pipelineRun := getCaptureTestPipelineRun()
+	pipelineRun.Annotations = map[string]string{"large": strings.Repeat("a", maxCapturedPipelineRunSize)}
+
+	capturedYAML, err := getCapturedPipelineRun(pipelineRun)
+	if err != nil {
+		t.Fatalf("getCapturedPipelineRun() error = %v", err)
+	}
+	if len(capturedYAML) > maxCapturedPipelineRunSize || !strings.HasPrefix(capturedYAML, "#") {
+		t.Errorf("getCapturedPipelineRun() returned %d bytes, want a comment within the size limit", len(capturedYAML))
+	}
+}
+
+func TestIsSensitiveParam(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "git-url", want: false},
+		{name: "output-image", want: false},
+		{name: "ACCESS_TOKEN", want: true},
+		{name: "registry-password", want: true},
+		{name: "pull-secret", want: true},
+		{name: "credentials-file", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSensitiveParam(tt.name); got != tt.want {
+				t.Errorf("isSensitiveParam() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
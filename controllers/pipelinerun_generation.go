@@ -0,0 +1,181 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops"
+	"github.com/redhat-appstudio/application-service/gitops/prepare"
+)
+
+const (
+	// Fallback requeue of a component whose build PipelineRun is being generated, in case the completion event is lost
+	pipelineRunGenerationRequeueInterval = 30 * time.Second
+	// Generated PipelineRuns which have not been submitted within the time, e.g. of deleted components, are dropped
+	pipelineRunGenerationTTL = 10 * time.Minute
+
+	pipelineRunGenerationEventsBufferSize = 1024
+)
+
+type pipelineRunGeneration struct {
+	key         string
+	done        bool
+	pipelineRun tektonapi.PipelineRun
+	started     time.Time
+}
+
+// pipelineRunGenerator generates initial build PipelineRuns of components in a bounded pool of workers,
+// so parsing of large devfiles does not tie up reconcile workers. A generated PipelineRun is kept
+// until it is submitted and is used only for the same generation, devfile and gitops config of the component.
+type pipelineRunGenerator struct {
+	generate func(appstudiov1alpha1.Component, prepare.GitopsConfig) tektonapi.PipelineRun
+	now      func() time.Time
+	// workers bounds the number of concurrent generations
+	workers chan struct{}
+	// events notifies the controller about finished generations, so the components are reconciled again
+	events chan event.GenericEvent
+
+	mutex       sync.Mutex
+	generations map[types.NamespacedName]*pipelineRunGeneration
+}
+
+func newPipelineRunGenerator(workers int) *pipelineRunGenerator {
+	return &pipelineRunGenerator{
+		generate:    gitops.GenerateInitialBuildPipelineRun,
+		now:         time.Now,
+		workers:     make(chan struct{}, workers),
+		events:      make(chan event.GenericEvent, pipelineRunGenerationEventsBufferSize),
+		generations: map[types.NamespacedName]*pipelineRunGeneration{},
+	}
+}
+
+// Schedule returns true if the build PipelineRun of the component is generated already.
+// Otherwise it starts the generation in background, unless it is in progress, and returns false.
+// A nil generator always returns true as the PipelineRun is generated on submission.
+func (g *pipelineRunGenerator) Schedule(component appstudiov1alpha1.Component, gitopsConfig prepare.GitopsConfig) bool {
+	if g == nil {
+		return true
+	}
+	componentKey := types.NamespacedName{Name: component.Name, Namespace: component.Namespace}
+	key := getPipelineRunGenerationKey(component, gitopsConfig)
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if generation, exists := g.generations[componentKey]; exists && generation.key == key {
+		return generation.done
+	}
+
+	g.removeExpired()
+	// A generation for a previous state of the component is replaced, its result is discarded
+	generation := &pipelineRunGeneration{key: key, started: g.now()}
+	g.generations[componentKey] = generation
+	go g.run(componentKey, generation, *component.DeepCopy(), gitopsConfig)
+	return false
+}
+
+// Generate returns the build PipelineRun generated in background and drops it from the generator.
+// The PipelineRun is generated synchronously if there is no finished generation for the current state of the component.
+func (g *pipelineRunGenerator) Generate(component appstudiov1alpha1.Component, gitopsConfig prepare.GitopsConfig) tektonapi.PipelineRun {
+	if g == nil {
+		return gitops.GenerateInitialBuildPipelineRun(component, gitopsConfig)
+	}
+	componentKey := types.NamespacedName{Name: component.Name, Namespace: component.Namespace}
+	key := getPipelineRunGenerationKey(component, gitopsConfig)
+
+	g.mutex.Lock()
+	generation, exists := g.generations[componentKey]
+	if exists && generation.key == key && generation.done {
+		delete(g.generations, componentKey)
+		g.mutex.Unlock()
+		return generation.pipelineRun
+	}
+	g.mutex.Unlock()
+
+	return g.generate(component, gitopsConfig)
+}
+
+// Peek returns the build PipelineRun generated in background and keeps it for the submission.
+// The PipelineRun is generated synchronously if there is no finished generation for the current state of the component.
+func (g *pipelineRunGenerator) Peek(component appstudiov1alpha1.Component, gitopsConfig prepare.GitopsConfig) tektonapi.PipelineRun {
+	if g == nil {
+		return gitops.GenerateInitialBuildPipelineRun(component, gitopsConfig)
+	}
+	componentKey := types.NamespacedName{Name: component.Name, Namespace: component.Namespace}
+	key := getPipelineRunGenerationKey(component, gitopsConfig)
+
+	g.mutex.Lock()
+	generation, exists := g.generations[componentKey]
+	if exists && generation.key == key && generation.done {
+		g.mutex.Unlock()
+		return *generation.pipelineRun.DeepCopy()
+	}
+	g.mutex.Unlock()
+
+	return g.generate(component, gitopsConfig)
+}
+
+func (g *pipelineRunGenerator) run(componentKey types.NamespacedName, generation *pipelineRunGeneration, component appstudiov1alpha1.Component, gitopsConfig prepare.GitopsConfig) {
+	g.workers <- struct{}{}
+	pipelineRun := g.generate(component, gitopsConfig)
+	<-g.workers
+
+	g.mutex.Lock()
+	if g.generations[componentKey] != generation {
+		// The component has changed meanwhile
+		g.mutex.Unlock()
+		return
+	}
+	generation.pipelineRun = pipelineRun
+	generation.done = true
+	g.mutex.Unlock()
+
+	generatedComponent := &appstudiov1alpha1.Component{
+		ObjectMeta: metav1.ObjectMeta{Name: componentKey.Name, Namespace: componentKey.Namespace},
+	}
+	select {
+	case g.events <- event.GenericEvent{Object: generatedComponent}:
+	default:
+		// The component is requeued after pipelineRunGenerationRequeueInterval anyway
+	}
+}
+
+// removeExpired drops generations which have not been submitted for a long time.
+// The caller must hold the mutex.
+func (g *pipelineRunGenerator) removeExpired() {
+	expired := g.now().Add(-pipelineRunGenerationTTL)
+	for componentKey, generation := range g.generations {
+		if generation.done && generation.started.Before(expired) {
+			delete(g.generations, componentKey)
+		}
+	}
+}
+
+// getPipelineRunGenerationKey identifies the state of the component the build PipelineRun is generated from.
+// Labels and annotations do not affect the generated PipelineRun.
+func getPipelineRunGenerationKey(component appstudiov1alpha1.Component, gitopsConfig prepare.GitopsConfig) string {
+	return fmt.Sprintf("%s/%d/%s/%s", component.UID, component.Generation,
+		getChecksum([]byte(component.Status.Devfile)), gitopsConfig.BuildBundle)
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	"github.com/redhat-appstudio/application-service/gitops/prepare"
+)
+
+// countingPipelineRunGenerator returns a generator whose PipelineRuns are named after the component generation.
+func countingPipelineRunGenerator(workers int, calls *int32) *pipelineRunGenerator {
+	generator := newPipelineRunGenerator(workers)
+	generator.generate = func(component appstudiov1alpha1.Component, gitopsConfig prepare.GitopsConfig) tektonapi.PipelineRun {
+		atomic.AddInt32(calls, 1)
+		return tektonapi.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-%d", component.Name, component.Generation)},
+		}
+	}
+	return generator
+}
+
+func waitForPipelineRunGeneration(t *testing.T, generator *pipelineRunGenerator) {
+	select {
+	case <-generator.events:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("PipelineRun generation has not finished")
+	}
+}
+
+func getGenerationTestComponent(name string, generation int64) appstudiov1alpha1.Component {
+	return appstudiov1alpha1.Component{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "my-namespace", UID: types.UID("uid-" + name), Generation: generation},
+	}
+}
+
+func TestPipelineRunGeneratorSchedule(t *testing.T) {
+	var calls int32
+	generator := countingPipelineRunGenerator(2, &calls)
+	component := getGenerationTestComponent("my-component", 1)
+
+	if generator.Schedule(component, prepare.GitopsConfig{}) {
+		t.Fatalf("Schedule() = true, want false before the generation")
+	}
+	waitForPipelineRunGeneration(t, generator)
+	if !generator.Schedule(component, prepare.GitopsConfig{}) {
+		t.Fatalf("Schedule() = false, want true after the generation")
+	}
+
+	pipelineRun := generator.Generate(component, prepare.GitopsConfig{})
+	if pipelineRun.Name != "my-component-1" {
+		t.Errorf("Generate() = %v, want my-component-1", pipelineRun.Name)
+	}
+	if calls != 1 {
+		t.Errorf("PipelineRun generated %d times, want 1", calls)
+	}
+	if len(generator.generations) != 0 {
+		t.Errorf("Generate() kept the submitted PipelineRun")
+	}
+}
+
+func TestPipelineRunGeneratorPeek(t *testing.T) {
+	var calls int32
+	generator := countingPipelineRunGenerator(2, &calls)
+	component := getGenerationTestComponent("my-component", 1)
+
+	generator.Schedule(component, prepare.GitopsConfig{})
+	waitForPipelineRunGeneration(t, generator)
+
+	if pipelineRun := generator.Peek(component, prepare.GitopsConfig{}); pipelineRun.Name != "my-component-1" {
+		t.Errorf("Peek() = %v, want my-component-1", pipelineRun.Name)
+	}
+	if pipelineRun := generator.Generate(component, prepare.GitopsConfig{}); pipelineRun.Name != "my-component-1" {
+		t.Errorf("Generate() = %v, want my-component-1", pipelineRun.Name)
+	}
+	if calls != 1 {
+		t.Errorf("PipelineRun generated %d times, want 1", calls)
+	}
+}
+
+func TestPipelineRunGeneratorConcurrentSchedule(t *testing.T) {
+	var calls int32
+	generator := countingPipelineRunGenerator(2, &calls)
+	component := getGenerationTestComponent("my-component", 1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			generator.Schedule(component, prepare.GitopsConfig{})
+		}()
+	}
+	wg.Wait()
+	waitForPipelineRunGeneration(t, generator)
+
+	if !generator.Schedule(component, prepare.GitopsConfig{}) {
+		t.Errorf("Schedule() = false, want true after the generation")
+	}
+	if calls != 1 {
+		t.Errorf("PipelineRun generated %d times, want 1", calls)
+	}
+}
+
+func TestPipelineRunGeneratorWorkersLimit(t *testing.T) {
+	const workers = 2
+	var running, maxRunning int32
+	release := make(chan struct{})
+	generator := newPipelineRunGenerator(workers)
+	generator.generate = func(component appstudiov1alpha1.Component, gitopsConfig prepare.GitopsConfig) tektonapi.PipelineRun {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&running, -1)
+		return tektonapi.PipelineRun{}
+	}
+
+	for i := 0; i < 10; i++ {
+		generator.Schedule(getGenerationTestComponent(fmt.Sprintf("component-%d", i), 1), prepare.GitopsConfig{})
+	}
+	// Schedule does not wait for the generations
+	for i := 0; i < 10; i++ {
+		release <- struct{}{}
+	}
+	for i := 0; i < 10; i++ {
+		waitForPipelineRunGeneration(t, generator)
+	}
+
+	if maxRunning > workers {
+		t.Errorf("%d PipelineRuns generated concurrently, want at most %d", maxRunning, workers)
+	}
+}
+
+func TestPipelineRunGeneratorComponentChange(t *testing.T) {
+	var calls int32
+	generator := countingPipelineRunGenerator(1, &calls)
+	component := getGenerationTestComponent("my-component", 1)
+
+	generator.Schedule(component, prepare.GitopsConfig{})
+	waitForPipelineRunGeneration(t, generator)
+
+	tests := []struct {
+		name         string
+		component    appstudiov1alpha1.Component
+		gitopsConfig prepare.GitopsConfig
+	}{
+		{
+			name:      "new generation",
+			component: getGenerationTestComponent("my-component", 2),
+		},
+		{
+			name: "devfile change",
+			component: func() appstudiov1alpha1.Component {
+				changed := getGenerationTestComponent("my-component", 1)
+				changed.Status.Devfile = "schemaVersion: 2.2.0"
+				return changed
+			}(),
+		},
+		{
+			name:         "gitops config change",
+			component:    getGenerationTestComponent("my-component", 1),
+			gitopsConfig: prepare.GitopsConfig{BuildBundle: "quay.io/foo/bundle:v2"},
+		},
+		{
+			name: "recreated component",
+			component: func() appstudiov1alpha1.Component {
+				recreated := getGenerationTestComponent("my-component", 1)
+				recreated.UID = "new-uid"
+				return recreated
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getPipelineRunGenerationKey(tt.component, tt.gitopsConfig); got == getPipelineRunGenerationKey(component, prepare.GitopsConfig{}) {
+				t.Errorf("getPipelineRunGenerationKey() = %v, want a different key", got)
+			}
+		})
+	}
+
+	// The PipelineRun of the previous generation must not be used
+	changed := getGenerationTestComponent("my-component", 2)
+	if generator.Schedule(changed, prepare.GitopsConfig{}) {
+		t.Fatalf("Schedule() = true, want false for a new generation")
+	}
+	waitForPipelineRunGeneration(t, generator)
+	if pipelineRun := generator.Generate(changed, prepare.GitopsConfig{}); pipelineRun.Name != "my-component-2" {
+		t.Errorf("Generate() = %v, want my-component-2", pipelineRun.Name)
+	}
+	if calls != 2 {
+		t.Errorf("PipelineRun generated %d times, want 2", calls)
+	}
+}
+
+func TestPipelineRunGeneratorGenerateWithoutSchedule(t *testing.T) {
+	var calls int32
+	generator := countingPipelineRunGenerator(1, &calls)
+
+	pipelineRun := generator.Generate(getGenerationTestComponent("my-component", 3), prepare.GitopsConfig{})
+	if pipelineRun.Name != "my-component-3" || calls != 1 {
+		t.Errorf("Generate() = %v, want my-component-3 generated synchronously", pipelineRun.Name)
+	}
+
+	var nilGenerator *pipelineRunGenerator
+	if !nilGenerator.Schedule(getGenerationTestComponent("my-component", 3), prepare.GitopsConfig{}) {
+		t.Errorf("Schedule() = false, want true for disabled background generation")
+	}
+}
+
+func TestPipelineRunGeneratorRemoveExpired(t *testing.T) {
+	var calls int32
+	generator := countingPipelineRunGenerator(1, &calls)
+	now := time.Now()
+	generator.now = func() time.Time { return now }
+
+	generator.Schedule(getGenerationTestComponent("deleted-component", 1), prepare.GitopsConfig{})
+	waitForPipelineRunGeneration(t, generator)
+
+	now = now.Add(pipelineRunGenerationTTL + time.Minute)
+	generator.Schedule(getGenerationTestComponent("my-component", 1), prepare.GitopsConfig{})
+	waitForPipelineRunGeneration(t, generator)
+
+	generator.mutex.Lock()
+	defer generator.mutex.Unlock()
+	if _, exists := generator.generations[types.NamespacedName{Name: "deleted-component", Namespace: "my-namespace"}]; exists {
+		t.Errorf("removeExpired() kept the expired PipelineRun")
+	}
+}
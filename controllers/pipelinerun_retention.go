@@ -0,0 +1,245 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultPipelineRunRetention is the default age after which build PipelineRuns are deleted
+	DefaultPipelineRunRetention = 7 * 24 * time.Hour
+	// DefaultPipelineRunRetentionInterval is the default period of checking build PipelineRuns age
+	DefaultPipelineRunRetentionInterval = 15 * time.Minute
+
+	// If set to "true", workspace PVCs of the component builds are kept when the retention cleanup deletes the builds
+	KeepWorkspacePVCsAnnotationName = "build.appstudio.openshift.io/keep-workspace-pvcs"
+)
+
+// PipelineRunRetentionCleaner periodically deletes completed component build PipelineRuns older than the retention period.
+// It is independent of the component reconciliation and removes builds of all components in the cluster.
+type PipelineRunRetentionCleaner struct {
+	Client client.Client
+	Log    logr.Logger
+	// Retention is the maximum age of build PipelineRuns
+	Retention time.Duration
+	// Interval is the period of the cleanup
+	Interval time.Duration
+	// KeepWorkspacePVCs keeps PVCs created for volumeClaimTemplate workspaces of the deleted PipelineRuns,
+	// which are otherwise garbage collected together with the PipelineRuns
+	KeepWorkspacePVCs bool
+}
+
+//+kubebuilder:rbac:groups=tekton.dev,resources=pipelineruns,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;update
+
+// Start runs the cleanup periodically until the context is done.
+func (c *PipelineRunRetentionCleaner) Start(ctx context.Context) error {
+	c.Log.Info(fmt.Sprintf("Starting cleanup of build PipelineRuns older than %s every %s", c.Retention, c.Interval))
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := c.deleteExpiredPipelineRuns(ctx, time.Now()); err != nil {
+				c.Log.Error(err, "Failed to delete expired build PipelineRuns")
+			}
+		}
+	}
+}
+
+// NeedLeaderElection returns true as only one replica should delete PipelineRuns.
+func (c *PipelineRunRetentionCleaner) NeedLeaderElection() bool {
+	return true
+}
+
+// deleteExpiredPipelineRuns deletes completed component build PipelineRuns created more than the retention period before now.
+// Builds which are still pending or running are kept, as well as the latest successful build of each component,
+// which is used to find out whether the component has been built, e.g. by dependent components.
+// A failure to delete one PipelineRun doesn't stop the cleanup, all errors are returned together.
+func (c *PipelineRunRetentionCleaner) deleteExpiredPipelineRuns(ctx context.Context, now time.Time) error {
+	pipelineRuns := &tektonapi.PipelineRunList{}
+	if err := c.Client.List(ctx, pipelineRuns, client.HasLabels{ComponentNameLabelName}); err != nil {
+		return err
+	}
+
+	componentPipelineRuns, err := c.getTrustedPipelineRuns(ctx, pipelineRuns.Items)
+	if err != nil {
+		return err
+	}
+	latestSuccessfulPipelineRuns := getLatestSuccessfulPipelineRuns(componentPipelineRuns)
+	var errs []error
+	for i := range pipelineRuns.Items {
+		pipelineRun := &pipelineRuns.Items[i]
+		if !isExpiredPipelineRun(*pipelineRun, now, c.Retention) || !pipelineRun.IsDone() {
+			continue
+		}
+		if latestSuccessfulPipelineRuns[getPipelineRunComponentKey(*pipelineRun)] == pipelineRun.UID {
+			continue
+		}
+		// Kept PVCs are released first, so they are not garbage collected together with the PipelineRun if the release fails
+		if err := c.orphanWorkspacePVCs(ctx, *pipelineRun); err != nil {
+			c.Log.Error(err, fmt.Sprintf("Unable to keep workspace PVCs of expired PipelineRun %s in namespace %s", pipelineRun.Name, pipelineRun.Namespace))
+			errs = append(errs, err)
+			continue
+		}
+		if err := c.Client.Delete(ctx, pipelineRun); err != nil && !errors.IsNotFound(err) {
+			c.Log.Error(err, fmt.Sprintf("Unable to delete expired PipelineRun %s in namespace %s", pipelineRun.Name, pipelineRun.Namespace))
+			errs = append(errs, err)
+			continue
+		}
+		c.Log.Info(fmt.Sprintf("Deleted PipelineRun %s in namespace %s created at %s", pipelineRun.Name, pipelineRun.Namespace, pipelineRun.CreationTimestamp))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// getLatestSuccessfulPipelineRuns returns UIDs of the latest successful PipelineRun of each component
+// keyed by the component namespace and name.
+func getLatestSuccessfulPipelineRuns(pipelineRuns []tektonapi.PipelineRun) map[string]types.UID {
+	latestPipelineRuns := make(map[string]tektonapi.PipelineRun)
+	for _, pipelineRun := range pipelineRuns {
+		if getBuildState(pipelineRun) != BuildStateSucceeded {
+			continue
+		}
+		componentKey := getPipelineRunComponentKey(pipelineRun)
+		if latest, exists := latestPipelineRuns[componentKey]; exists && !latest.CreationTimestamp.Before(&pipelineRun.CreationTimestamp) {
+			continue
+		}
+		latestPipelineRuns[componentKey] = pipelineRun
+	}
+
+	latestPipelineRunUIDs := make(map[string]types.UID, len(latestPipelineRuns))
+	for componentKey, pipelineRun := range latestPipelineRuns {
+		latestPipelineRunUIDs[componentKey] = pipelineRun.UID
+	}
+	return latestPipelineRunUIDs
+}
+
+// getTrustedPipelineRuns returns the PipelineRuns which can be attributed to the components they are labeled with.
+// A PipelineRun in another namespace than its component namespace must not hide the latest build of the component,
+// unless it is in the build namespace of the component.
+func (c *PipelineRunRetentionCleaner) getTrustedPipelineRuns(ctx context.Context, pipelineRuns []tektonapi.PipelineRun) ([]tektonapi.PipelineRun, error) {
+	var trustedPipelineRuns []tektonapi.PipelineRun
+	for _, pipelineRun := range pipelineRuns {
+		if getPipelineRunComponentNamespace(pipelineRun) != pipelineRun.Namespace {
+			_, found, err := getPipelineRunComponent(ctx, c.Client, pipelineRun, pipelineRun.Labels[ComponentNameLabelName])
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				continue
+			}
+		}
+		trustedPipelineRuns = append(trustedPipelineRuns, pipelineRun)
+	}
+	return trustedPipelineRuns, nil
+}
+
+func getPipelineRunComponentKey(pipelineRun tektonapi.PipelineRun) string {
+	return getComponentPipelineRunIndexValue(getPipelineRunComponentNamespace(pipelineRun), pipelineRun.Labels[ComponentNameLabelName])
+}
+
+// isExpiredPipelineRun checks whether the PipelineRun was created more than the retention period before now.
+// Longer build log retention of the PipelineRun extends the retention period.
+func isExpiredPipelineRun(pipelineRun tektonapi.PipelineRun, now time.Time, retention time.Duration) bool {
+	return pipelineRun.CreationTimestamp.Add(getPipelineRunRetention(pipelineRun, retention)).Before(now)
+}
+
+// orphanWorkspacePVCs keeps PVCs which Tekton created for volumeClaimTemplate workspaces of the PipelineRun,
+// if the cleanup or the component built by the PipelineRun keeps workspace PVCs.
+// Tekton sets the PipelineRun as the owner of the PVCs, so the owner reference is removed. Otherwise the PVCs
+// are deleted by the garbage collector together with the PipelineRun.
+// PVCs bound by name, e.g. the shared workspace PVC, are not owned by the PipelineRun and are always kept.
+func (c *PipelineRunRetentionCleaner) orphanWorkspacePVCs(ctx context.Context, pipelineRun tektonapi.PipelineRun) error {
+	if !hasVolumeClaimTemplateWorkspace(pipelineRun) {
+		return nil
+	}
+	if !c.KeepWorkspacePVCs {
+		keepWorkspacePVCs, err := c.isKeepWorkspacePVCsComponent(ctx, pipelineRun)
+		if err != nil || !keepWorkspacePVCs {
+			return err
+		}
+	}
+
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := c.Client.List(ctx, pvcs, client.InNamespace(pipelineRun.Namespace)); err != nil {
+		return err
+	}
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		if !isPipelineRunWorkspacePVC(*pvc, pipelineRun) {
+			continue
+		}
+		removePipelineRunOwnerReference(pvc, pipelineRun)
+		if err := c.Client.Update(ctx, pvc); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		c.Log.Info(fmt.Sprintf("Kept workspace PVC %s of PipelineRun %s in namespace %s", pvc.Name, pipelineRun.Name, pipelineRun.Namespace))
+	}
+	return nil
+}
+
+func removePipelineRunOwnerReference(pvc *corev1.PersistentVolumeClaim, pipelineRun tektonapi.PipelineRun) {
+	ownerReferences := make([]metav1.OwnerReference, 0, len(pvc.OwnerReferences))
+	for _, owner := range pvc.OwnerReferences {
+		if owner.UID != pipelineRun.UID {
+			ownerReferences = append(ownerReferences, owner)
+		}
+	}
+	pvc.OwnerReferences = ownerReferences
+}
+
+// isKeepWorkspacePVCsComponent checks whether the component built by the PipelineRun keeps workspace PVCs of its builds.
+func (c *PipelineRunRetentionCleaner) isKeepWorkspacePVCsComponent(ctx context.Context, pipelineRun tektonapi.PipelineRun) (bool, error) {
+	component, found, err := getPipelineRunComponent(ctx, c.Client, pipelineRun, pipelineRun.Labels[ComponentNameLabelName])
+	if err != nil || !found {
+		return false, err
+	}
+	return component.Annotations[KeepWorkspacePVCsAnnotationName] == "true", nil
+}
+
+func hasVolumeClaimTemplateWorkspace(pipelineRun tektonapi.PipelineRun) bool {
+	for _, workspace := range pipelineRun.Spec.Workspaces {
+		if workspace.VolumeClaimTemplate != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isPipelineRunWorkspacePVC checks whether the PVC has been created by Tekton for a workspace of the PipelineRun.
+func isPipelineRunWorkspacePVC(pvc corev1.PersistentVolumeClaim, pipelineRun tektonapi.PipelineRun) bool {
+	for _, owner := range pvc.OwnerReferences {
+		if owner.Kind == "PipelineRun" && owner.UID == pipelineRun.UID {
+			return true
+		}
+	}
+	return false
+}
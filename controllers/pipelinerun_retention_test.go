@@ -0,0 +1,331 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+type retentionClient struct {
+	client.Client
+	pipelineRuns []tektonapi.PipelineRun
+	pvcs         []corev1.PersistentVolumeClaim
+	component    *appstudiov1alpha1.Component
+	failDelete   map[string]bool
+	deleted      []string
+	updated      []corev1.PersistentVolumeClaim
+}
+
+func (c *retentionClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if c.component == nil || c.component.Name != key.Name {
+		return errors.NewNotFound(schema.GroupResource{Group: "appstudio.redhat.com", Resource: "components"}, key.Name)
+	}
+	c.component.DeepCopyInto(obj.(*appstudiov1alpha1.Component))
+	return nil
+}
+
+func (c *retentionClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	switch list := list.(type) {
+	case *tektonapi.PipelineRunList:
+		list.Items = c.pipelineRuns
+	case *corev1.PersistentVolumeClaimList:
+		list.Items = c.pvcs
+	}
+	return nil
+}
+
+func (c *retentionClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.updated = append(c.updated, *obj.(*corev1.PersistentVolumeClaim))
+	return nil
+}
+
+func (c *retentionClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if c.failDelete[obj.GetName()] {
+		return fmt.Errorf("failed to delete %s", obj.GetName())
+	}
+	c.deleted = append(c.deleted, obj.GetName())
+	return nil
+}
+
+func TestDeleteExpiredPipelineRuns(t *testing.T) {
+	now := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+	expired := now.Add(-DefaultPipelineRunRetention - time.Hour)
+
+	pipelineRun := func(name string, component string, creationTime time.Time, status corev1.ConditionStatus) tektonapi.PipelineRun {
+		pipelineRun := tektonapi.PipelineRun{}
+		if status != "" {
+			pipelineRun = getPipelineRunWithSucceededCondition(status)
+		}
+		pipelineRun.Name = name
+		pipelineRun.Namespace = "my-namespace"
+		pipelineRun.UID = types.UID(name)
+		pipelineRun.Labels = map[string]string{ComponentNameLabelName: component}
+		pipelineRun.CreationTimestamp = metav1.NewTime(creationTime)
+		return pipelineRun
+	}
+
+	tests := []struct {
+		name         string
+		pipelineRuns []tektonapi.PipelineRun
+		failDelete   map[string]bool
+		wantDeleted  []string
+		wantErr      bool
+	}{
+		{
+			name: "keeps builds within the retention period",
+			pipelineRuns: []tektonapi.PipelineRun{
+				pipelineRun("recent-failed", "foo", now.Add(-time.Hour), corev1.ConditionFalse),
+			},
+		},
+		{
+			name: "keeps expired builds which have not completed",
+			pipelineRuns: []tektonapi.PipelineRun{
+				pipelineRun("pending", "foo", expired, ""),
+				pipelineRun("running", "foo", expired, corev1.ConditionUnknown),
+			},
+		},
+		{
+			name: "keeps the latest successful build of each component",
+			pipelineRuns: []tektonapi.PipelineRun{
+				pipelineRun("foo-old-succeeded", "foo", expired.Add(-time.Hour), corev1.ConditionTrue),
+				pipelineRun("foo-succeeded", "foo", expired, corev1.ConditionTrue),
+				pipelineRun("foo-failed", "foo", expired.Add(time.Minute), corev1.ConditionFalse),
+				pipelineRun("bar-succeeded", "bar", expired.Add(-time.Hour), corev1.ConditionTrue),
+			},
+			wantDeleted: []string{"foo-old-succeeded", "foo-failed"},
+		},
+		{
+			name: "continues after a failed deletion",
+			pipelineRuns: []tektonapi.PipelineRun{
+				pipelineRun("first-failed", "foo", expired, corev1.ConditionFalse),
+				pipelineRun("second-failed", "foo", expired, corev1.ConditionFalse),
+			},
+			failDelete:  map[string]bool{"first-failed": true},
+			wantDeleted: []string{"second-failed"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := &retentionClient{pipelineRuns: tt.pipelineRuns, failDelete: tt.failDelete}
+			cleaner := &PipelineRunRetentionCleaner{Client: cli, Log: logr.Discard(), Retention: DefaultPipelineRunRetention}
+			err := cleaner.deleteExpiredPipelineRuns(context.TODO(), now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("deleteExpiredPipelineRuns() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(cli.deleted, tt.wantDeleted) {
+				t.Errorf("deleteExpiredPipelineRuns() deleted = %v, want %v", cli.deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestIsExpiredPipelineRun(t *testing.T) {
+	now := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	createdAt := func(creationTime time.Time) tektonapi.PipelineRun {
+		return tektonapi.PipelineRun{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(creationTime)}}
+	}
+	withLogRetention := func(pipelineRun tektonapi.PipelineRun, logRetention string) tektonapi.PipelineRun {
+		pipelineRun.Annotations = map[string]string{BuildLogRetentionAnnotationName: logRetention}
+		return pipelineRun
+	}
+
+	tests := []struct {
+		name        string
+		pipelineRun tektonapi.PipelineRun
+		want        bool
+	}{
+		{
+			name:        "created just now",
+			pipelineRun: createdAt(now),
+			want:        false,
+		},
+		{
+			name:        "created within the retention period",
+			pipelineRun: createdAt(now.Add(-DefaultPipelineRunRetention + time.Minute)),
+			want:        false,
+		},
+		{
+			name:        "created before the retention period",
+			pipelineRun: createdAt(now.Add(-DefaultPipelineRunRetention - time.Minute)),
+			want:        true,
+		},
+		{
+			name: "build logs kept longer than the retention period",
+			pipelineRun: withLogRetention(createdAt(now.Add(-DefaultPipelineRunRetention-time.Minute)),
+				(DefaultPipelineRunRetention + time.Hour).String()),
+			want: false,
+		},
+		{
+			name:        "build logs kept shorter than the retention period",
+			pipelineRun: withLogRetention(createdAt(now.Add(-DefaultPipelineRunRetention-time.Minute)), "1h"),
+			want:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isExpiredPipelineRun(tt.pipelineRun, now, DefaultPipelineRunRetention); got != tt.want {
+				t.Errorf("isExpiredPipelineRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsPipelineRunWorkspacePVC(t *testing.T) {
+	pipelineRun := tektonapi.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "my-component-abcde", UID: "pipelinerun-uid"}}
+
+	ownedBy := func(kind string, uid string) corev1.PersistentVolumeClaim {
+		return corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+			OwnerReferences: []metav1.OwnerReference{{Kind: kind, Name: pipelineRun.Name, UID: types.UID(uid)}},
+		}}
+	}
+
+	tests := []struct {
+		name string
+		pvc  corev1.PersistentVolumeClaim
+		want bool
+	}{
+		{
+			name: "workspace PVC of the PipelineRun",
+			pvc:  ownedBy("PipelineRun", "pipelinerun-uid"),
+			want: true,
+		},
+		{
+			name: "workspace PVC of another PipelineRun with the same name",
+			pvc:  ownedBy("PipelineRun", "another-uid"),
+			want: false,
+		},
+		{
+			name: "PVC owned by another kind",
+			pvc:  ownedBy("Component", "pipelinerun-uid"),
+			want: false,
+		},
+		{
+			name: "shared workspace PVC",
+			pvc:  corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "appstudio"}},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPipelineRunWorkspacePVC(tt.pvc, pipelineRun); got != tt.want {
+				t.Errorf("isPipelineRunWorkspacePVC() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrphanWorkspacePVCs(t *testing.T) {
+	pipelineRun := tektonapi.PipelineRun{ObjectMeta: metav1.ObjectMeta{
+		Name:      "my-component-abcde",
+		Namespace: "my-namespace",
+		UID:       "pipelinerun-uid",
+		Labels:    map[string]string{ComponentNameLabelName: "my-component"},
+	}}
+	pipelineRun.Spec.Workspaces = []tektonapi.WorkspaceBinding{
+		{Name: "workspace", PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "appstudio"}},
+		{Name: "cache", VolumeClaimTemplate: &corev1.PersistentVolumeClaim{}},
+	}
+	pvcs := []corev1.PersistentVolumeClaim{
+		{ObjectMeta: metav1.ObjectMeta{Name: "appstudio"}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name: "pvc-abcde",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "PipelineRun", Name: pipelineRun.Name, UID: pipelineRun.UID},
+				{Kind: "Other", Name: "other", UID: "other-uid"},
+			},
+		}},
+	}
+	keepingComponent := getGitSourceComponent(map[string]string{KeepWorkspacePVCsAnnotationName: "true"}, "")
+
+	tests := []struct {
+		name              string
+		keepWorkspacePVCs bool
+		component         *appstudiov1alpha1.Component
+		wantOrphaned      bool
+	}{
+		{
+			name:         "PVCs left to the garbage collector",
+			wantOrphaned: false,
+		},
+		{
+			name:              "PVCs kept by the cleanup",
+			keepWorkspacePVCs: true,
+			wantOrphaned:      true,
+		},
+		{
+			name:         "PVCs kept by the component",
+			component:    &keepingComponent,
+			wantOrphaned: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := &retentionClient{pvcs: []corev1.PersistentVolumeClaim{*pvcs[0].DeepCopy(), *pvcs[1].DeepCopy()}, component: tt.component}
+			cleaner := &PipelineRunRetentionCleaner{Client: cli, Log: logr.Discard(), KeepWorkspacePVCs: tt.keepWorkspacePVCs}
+			if err := cleaner.orphanWorkspacePVCs(context.TODO(), pipelineRun); err != nil {
+				t.Fatalf("orphanWorkspacePVCs() error = %v", err)
+			}
+			if !tt.wantOrphaned {
+				if len(cli.updated) > 0 {
+					t.Errorf("orphanWorkspacePVCs() updated %v, want no update", cli.updated)
+				}
+				return
+			}
+			if len(cli.updated) != 1 || cli.updated[0].Name != "pvc-abcde" {
+				t.Fatalf("orphanWorkspacePVCs() updated %v, want only the workspace PVC of the PipelineRun", cli.updated)
+			}
+			wantOwners := []metav1.OwnerReference{{Kind: "Other", Name: "other", UID: "other-uid"}}
+			if !reflect.DeepEqual(cli.updated[0].OwnerReferences, wantOwners) {
+				t.Errorf("orphanWorkspacePVCs() owners = %v, want %v", cli.updated[0].OwnerReferences, wantOwners)
+			}
+		})
+	}
+}
+
+func TestHasVolumeClaimTemplateWorkspace(t *testing.T) {
+	pipelineRun := tektonapi.PipelineRun{}
+	pipelineRun.Spec.Workspaces = []tektonapi.WorkspaceBinding{
+		{Name: "workspace", PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "appstudio"}},
+	}
+	if hasVolumeClaimTemplateWorkspace(pipelineRun) {
+		t.Errorf("hasVolumeClaimTemplateWorkspace() = true, want false for shared PVC workspace")
+	}
+
+	pipelineRun.Spec.Workspaces = append(pipelineRun.Spec.Workspaces,
+		tektonapi.WorkspaceBinding{Name: "cache", VolumeClaimTemplate: &corev1.PersistentVolumeClaim{}})
+	if !hasVolumeClaimTemplateWorkspace(pipelineRun) {
+		t.Errorf("hasVolumeClaimTemplateWorkspace() = false, want true for volumeClaimTemplate workspace")
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// DefaultMaxPipelineRunSize leaves room below the etcd request size limit of 1.5 MiB
+	// for the status and managed fields the PipelineRun gets once it runs
+	DefaultMaxPipelineRunSize = 1024 * 1024
+
+	// PipelineRunTooLargeConditionType is set on components whose build has not been submitted
+	// because the generated PipelineRun exceeds the size limit
+	PipelineRunTooLargeConditionType = "PipelineRunTooLarge"
+
+	PipelineRunTooLargeReasonExceeded = "SizeLimitExceeded"
+	PipelineRunTooLargeReasonWithin   = "SizeWithinLimit"
+
+	// Number of the largest parameters and workspaces listed in the PipelineRunTooLarge condition
+	maxReportedPipelineRunParts = 3
+)
+
+// pipelineRunPart is a parameter or workspace binding of a PipelineRun with its serialized size.
+type pipelineRunPart struct {
+	name string
+	size int
+}
+
+// getPipelineRunSize returns the size of the serialized PipelineRun in bytes.
+func getPipelineRunSize(pipelineRun tektonapi.PipelineRun) (int, error) {
+	data, err := json.Marshal(pipelineRun)
+	if err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// getLargestPipelineRunParts returns the largest parameters and workspace bindings of the PipelineRun, largest first.
+func getLargestPipelineRunParts(pipelineRun tektonapi.PipelineRun) ([]pipelineRunPart, error) {
+	parts := []pipelineRunPart{}
+	for _, param := range pipelineRun.Spec.Params {
+		data, err := json.Marshal(param)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, pipelineRunPart{name: "param " + param.Name, size: len(data)})
+	}
+	for _, workspace := range pipelineRun.Spec.Workspaces {
+		data, err := json.Marshal(workspace)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, pipelineRunPart{name: "workspace " + workspace.Name, size: len(data)})
+	}
+
+	sort.SliceStable(parts, func(i, j int) bool {
+		return parts[i].size > parts[j].size
+	})
+	if len(parts) > maxReportedPipelineRunParts {
+		parts = parts[:maxReportedPipelineRunParts]
+	}
+	return parts, nil
+}
+
+// getPipelineRunTooLargeCondition returns the condition explaining the PipelineRun is not submitted because of its size,
+// nil means the PipelineRun fits the limit. Zero limit disables the check.
+func getPipelineRunTooLargeCondition(pipelineRun tektonapi.PipelineRun, limit int) (*metav1.Condition, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	size, err := getPipelineRunSize(pipelineRun)
+	if err != nil {
+		return nil, err
+	}
+	if size <= limit {
+		return nil, nil
+	}
+
+	parts, err := getLargestPipelineRunParts(pipelineRun)
+	if err != nil {
+		return nil, err
+	}
+	partSizes := []string{}
+	for _, part := range parts {
+		partSizes = append(partSizes, fmt.Sprintf("%s (%d bytes)", part.name, part.size))
+	}
+	message := fmt.Sprintf("The build PipelineRun has %d bytes which exceeds the limit of %d bytes and cannot be stored in the cluster.", size, limit)
+	if len(partSizes) > 0 {
+		message += " The largest parts are " + strings.Join(partSizes, ", ") + "."
+	}
+	message += " Reduce the build parameters and resubmit the build"
+	return &metav1.Condition{
+		Type:    PipelineRunTooLargeConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  PipelineRunTooLargeReasonExceeded,
+		Message: message,
+	}, nil
+}
+
+// clearPipelineRunTooLargeCondition marks the build of the component, which has not been submitted because of its size, as submitted.
+func clearPipelineRunTooLargeCondition(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) error {
+	if !meta.IsStatusConditionTrue(component.Status.Conditions, PipelineRunTooLargeConditionType) {
+		return nil
+	}
+	return setComponentCondition(ctx, cli, component, metav1.Condition{
+		Type:    PipelineRunTooLargeConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  PipelineRunTooLargeReasonWithin,
+		Message: "The build PipelineRun fits the size limit, the build has been submitted",
+	})
+}
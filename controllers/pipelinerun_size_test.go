@@ -0,0 +1,104 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetPipelineRunTooLargeCondition(t *testing.T) {
+	largePipelineRun := getCaptureTestPipelineRun()
+	largePipelineRun.Spec.Params = append(largePipelineRun.Spec.Params,
+		tektonapi.Param{Name: "large-config", Value: *tektonapi.NewArrayOrString(strings.Repeat("a", DefaultMaxPipelineRunSize))})
+	largePipelineRun.Spec.Workspaces = append(largePipelineRun.Spec.Workspaces,
+		tektonapi.WorkspaceBinding{Name: "large-workspace", ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: strings.Repeat("b", 1024)},
+		}})
+
+	tests := []struct {
+		name        string
+		pipelineRun tektonapi.PipelineRun
+		limit       int
+		wantMessage []string
+	}{
+		{
+			name:        "should accept PipelineRun within the limit",
+			pipelineRun: getCaptureTestPipelineRun(),
+			limit:       DefaultMaxPipelineRunSize,
+		},
+		{
+			name:        "should accept any PipelineRun if the check is disabled",
+			pipelineRun: largePipelineRun,
+			limit:       0,
+		},
+		{
+			name:        "should reject PipelineRun over the limit and name its largest parts",
+			pipelineRun: largePipelineRun,
+			limit:       DefaultMaxPipelineRunSize,
+			wantMessage: []string{"param large-config", "workspace large-workspace"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getPipelineRunTooLargeCondition(tt.pipelineRun, tt.limit)
+			if err != nil {
+				t.Fatalf("getPipelineRunTooLargeCondition() error = %v", err)
+			}
+			if tt.wantMessage == nil {
+				if got != nil {
+					t.Errorf("getPipelineRunTooLargeCondition() = %v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.Type != PipelineRunTooLargeConditionType || got.Status != metav1.ConditionTrue {
+				t.Fatalf("getPipelineRunTooLargeCondition() = %v, want %s condition", got, PipelineRunTooLargeConditionType)
+			}
+			for _, part := range tt.wantMessage {
+				if !strings.Contains(got.Message, part) {
+					t.Errorf("getPipelineRunTooLargeCondition() message = %s, want %s", got.Message, part)
+				}
+			}
+		})
+	}
+}
+
+func TestGetLargestPipelineRunParts(t *testing.T) {
+	pipelineRun := getCaptureTestPipelineRun()
+	pipelineRun.Spec.Params = append(pipelineRun.Spec.Params,
+		tektonapi.Param{Name: "large-config", Value: *tektonapi.NewArrayOrString(strings.Repeat("a", 1024))})
+
+	got, err := getLargestPipelineRunParts(pipelineRun)
+	if err != nil {
+		t.Fatalf("getLargestPipelineRunParts() error = %v", err)
+	}
+	if len(got) != maxReportedPipelineRunParts {
+		t.Fatalf("getLargestPipelineRunParts() = %v, want %d parts", got, maxReportedPipelineRunParts)
+	}
+	if got[0].name != "param large-config" {
+		t.Errorf("getLargestPipelineRunParts() = %v, want param large-config first", got)
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].size > got[i-1].size {
+			t.Errorf("getLargestPipelineRunParts() = %v, want parts sorted by size", got)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// RepoSizeAnnotationName is the annotation of a Component with the size of its git repository as a quantity, e.g. 2Gi.
+	// It takes precedence over the size reported by previous builds.
+	RepoSizeAnnotationName = "build.appstudio.openshift.io/repo-size"
+	// RepoSizePipelineResultName is the result of build pipelines with the size of the cloned git repository in bytes
+	RepoSizePipelineResultName = "REPO_SIZE"
+	// RepoSizeScalingConfigMapKey is the key of the repository size scaling ConfigMap with the scaling YAML
+	RepoSizeScalingConfigMapKey = "scaling.yaml"
+)
+
+// RepoSizeScaling describes how builds of large git repositories are scaled.
+// The scaling factor is the repository size divided by the base size, rounded up and bounded by the maximum factor.
+type RepoSizeScaling struct {
+	// BaseSize is the repository size builds are not scaled up to
+	BaseSize resource.Quantity `json:"baseSize"`
+	// MaxFactor is the maximum scaling factor
+	MaxFactor int64 `json:"maxFactor"`
+	// Params are integer pipeline parameters, e.g. parallelism of the clone, with their values for the base size
+	Params map[string]int64 `json:"params,omitempty"`
+	// StepResources are resource requirements of the build pipeline steps for the base size
+	StepResources []BuildTierStepResources `json:"stepResources,omitempty"`
+}
+
+// getRepoSizeScaling returns the repository size scaling from the ConfigMap, nil means builds are not scaled.
+func (r *ComponentBuildReconciler) getRepoSizeScaling(ctx context.Context) (*RepoSizeScaling, error) {
+	if r.RepoSizeScalingConfigMap == nil {
+		return nil, nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, *r.RepoSizeScalingConfigMap, configMap); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	scaling, err := parseRepoSizeScaling(configMap.Data[RepoSizeScalingConfigMapKey])
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository size scaling in ConfigMap %v: %v", *r.RepoSizeScalingConfigMap, err)
+	}
+	return scaling, nil
+}
+
+// parseRepoSizeScaling parses and validates the repository size scaling YAML.
+func parseRepoSizeScaling(scalingYAML string) (*RepoSizeScaling, error) {
+	scaling := &RepoSizeScaling{}
+	if err := yaml.UnmarshalStrict([]byte(scalingYAML), scaling); err != nil {
+		return nil, err
+	}
+	if scaling.BaseSize.Sign() <= 0 {
+		return nil, fmt.Errorf("baseSize must be positive")
+	}
+	if scaling.MaxFactor < 1 {
+		return nil, fmt.Errorf("maxFactor must be at least 1")
+	}
+	for _, stepResources := range scaling.StepResources {
+		if stepResources.PipelineTaskName == "" || stepResources.StepName == "" {
+			return nil, fmt.Errorf("step resources must have pipelineTaskName and stepName")
+		}
+	}
+	return scaling, nil
+}
+
+// getRepoSize returns the git repository size of the component from its annotation or the latest build which reported it.
+// Nil is returned if the size is unknown.
+func (r *ComponentBuildReconciler) getRepoSize(ctx context.Context, component appstudiov1alpha1.Component) (*resource.Quantity, error) {
+	if sizeValue := component.Annotations[RepoSizeAnnotationName]; sizeValue != "" {
+		size, err := resource.ParseQuantity(sizeValue)
+		if err != nil || size.Sign() < 0 {
+			return nil, fmt.Errorf("invalid %s annotation, non-negative quantity expected", RepoSizeAnnotationName)
+		}
+		return &size, nil
+	}
+
+	pipelineRuns, err := listComponentPipelineRuns(ctx, r.Client, component)
+	if err != nil {
+		return nil, err
+	}
+	var size *resource.Quantity
+	var sizePipelineRun *tektonapi.PipelineRun
+	for i, pipelineRun := range pipelineRuns {
+		pipelineRunSize := getRepoSizeResult(pipelineRun)
+		if pipelineRunSize == nil {
+			continue
+		}
+		if sizePipelineRun == nil || sizePipelineRun.CreationTimestamp.Before(&pipelineRun.CreationTimestamp) {
+			size = pipelineRunSize
+			sizePipelineRun = &pipelineRuns[i]
+		}
+	}
+	return size, nil
+}
+
+// getRepoSizeResult returns the repository size reported by the build PipelineRun or nil if it is not reported.
+func getRepoSizeResult(pipelineRun tektonapi.PipelineRun) *resource.Quantity {
+	for _, result := range pipelineRun.Status.PipelineResults {
+		if result.Name != RepoSizePipelineResultName {
+			continue
+		}
+		size, err := resource.ParseQuantity(result.Value)
+		if err != nil || size.Sign() < 0 {
+			return nil
+		}
+		return &size
+	}
+	return nil
+}
+
+// getRepoSizeScalingFactor returns the factor builds of the repository with the given size are scaled by, 1 means no scaling.
+func getRepoSizeScalingFactor(scaling RepoSizeScaling, size resource.Quantity) int64 {
+	baseSize := scaling.BaseSize.Value()
+	factor := (size.Value() + baseSize - 1) / baseSize
+	if factor < 1 {
+		return 1
+	}
+	if factor > scaling.MaxFactor {
+		return scaling.MaxFactor
+	}
+	return factor
+}
+
+// applyRepoSizeScaling scales the parameters and step resources of the build PipelineRun by the factor.
+// The scaled step resources replace resources of the same steps set before, e.g. by the build tier.
+func applyRepoSizeScaling(scaling *RepoSizeScaling, factor int64, pipelineRun *tektonapi.PipelineRun) {
+	if scaling == nil || factor <= 1 {
+		return
+	}
+
+	var params []tektonapi.Param
+	for name, value := range scaling.Params {
+		params = append(params, tektonapi.Param{
+			Name:  name,
+			Value: *tektonapi.NewArrayOrString(strconv.FormatInt(value*factor, 10)),
+		})
+	}
+	mergePipelineParams(pipelineRun, params)
+
+	for _, stepResources := range scaling.StepResources {
+		resources := corev1.ResourceRequirements{
+			Requests: scaleResourceList(stepResources.Resources.Requests, factor),
+			Limits:   scaleResourceList(stepResources.Resources.Limits, factor),
+		}
+		taskRunSpec := getPipelineTaskRunSpec(pipelineRun, stepResources.PipelineTaskName)
+		isOverridden := false
+		for i := range taskRunSpec.StepOverrides {
+			if taskRunSpec.StepOverrides[i].Name == stepResources.StepName {
+				taskRunSpec.StepOverrides[i].Resources = resources
+				isOverridden = true
+			}
+		}
+		if !isOverridden {
+			taskRunSpec.StepOverrides = append(taskRunSpec.StepOverrides, tektonapi.TaskRunStepOverride{
+				Name:      stepResources.StepName,
+				Resources: resources,
+			})
+		}
+	}
+}
+
+func scaleResourceList(resources corev1.ResourceList, factor int64) corev1.ResourceList {
+	if resources == nil {
+		return nil
+	}
+	scaled := corev1.ResourceList{}
+	for name, quantity := range resources {
+		scaled[name] = *resource.NewMilliQuantity(quantity.MilliValue()*factor, quantity.Format)
+	}
+	return scaled
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testRepoSizeScalingYAML = `
+baseSize: 500Mi
+maxFactor: 4
+params:
+  clone-jobs: 2
+stepResources:
+- pipelineTaskName: clone-repository
+  stepName: clone
+  resources:
+    requests:
+      cpu: 500m
+      memory: 256Mi
+`
+
+func TestParseRepoSizeScaling(t *testing.T) {
+	tests := []struct {
+		name        string
+		scalingYAML string
+		wantErr     bool
+	}{
+		{name: "valid", scalingYAML: testRepoSizeScalingYAML},
+		{name: "missing base size", scalingYAML: "maxFactor: 4", wantErr: true},
+		{name: "zero max factor", scalingYAML: "baseSize: 1Gi", wantErr: true},
+		{name: "unknown field", scalingYAML: "baseSize: 1Gi\nmaxFactor: 2\nfoo: bar", wantErr: true},
+		{name: "step without name", scalingYAML: "baseSize: 1Gi\nmaxFactor: 2\nstepResources:\n- pipelineTaskName: clone-repository", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseRepoSizeScaling(tt.scalingYAML)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseRepoSizeScaling() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetRepoSizeScalingFactor(t *testing.T) {
+	scaling, err := parseRepoSizeScaling(testRepoSizeScalingYAML)
+	if err != nil {
+		t.Fatalf("parseRepoSizeScaling() error = %v", err)
+	}
+	tests := []struct {
+		size string
+		want int64
+	}{
+		{size: "0", want: 1},
+		{size: "20Mi", want: 1},
+		{size: "500Mi", want: 1},
+		{size: "501Mi", want: 2},
+		{size: "1200Mi", want: 3},
+		{size: "50Gi", want: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			if got := getRepoSizeScalingFactor(*scaling, resource.MustParse(tt.size)); got != tt.want {
+				t.Errorf("getRepoSizeScalingFactor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyRepoSizeScaling(t *testing.T) {
+	scaling, err := parseRepoSizeScaling(testRepoSizeScalingYAML)
+	if err != nil {
+		t.Fatalf("parseRepoSizeScaling() error = %v", err)
+	}
+
+	// Small repositories are not scaled
+	pipelineRun := getCaptureTestPipelineRun()
+	applyRepoSizeScaling(scaling, 1, &pipelineRun)
+	if !reflect.DeepEqual(pipelineRun, getCaptureTestPipelineRun()) {
+		t.Errorf("applyRepoSizeScaling() changed the PipelineRun of a small repository")
+	}
+
+	// Large repositories are scaled, replacing resources of the same step set before
+	pipelineRun.Spec.TaskRunSpecs = []tektonapi.PipelineTaskRunSpec{{
+		PipelineTaskName: "clone-repository",
+		StepOverrides:    []tektonapi.TaskRunStepOverride{{Name: "clone", Resources: corev1.ResourceRequirements{}}},
+	}}
+	applyRepoSizeScaling(scaling, 3, &pipelineRun)
+	if got := getPipelineRunParam(pipelineRun, "clone-jobs"); got != "6" {
+		t.Errorf("applyRepoSizeScaling() clone-jobs = %v, want 6", got)
+	}
+	stepOverrides := pipelineRun.Spec.TaskRunSpecs[0].StepOverrides
+	if len(stepOverrides) != 1 {
+		t.Fatalf("applyRepoSizeScaling() step overrides = %v, want 1", stepOverrides)
+	}
+	requests := stepOverrides[0].Resources.Requests
+	if cpu := requests[corev1.ResourceCPU]; cpu.Cmp(resource.MustParse("1500m")) != 0 {
+		t.Errorf("applyRepoSizeScaling() cpu = %v, want 1500m", cpu.String())
+	}
+	if memory := requests[corev1.ResourceMemory]; memory.Cmp(resource.MustParse("768Mi")) != 0 {
+		t.Errorf("applyRepoSizeScaling() memory = %v, want 768Mi", memory.String())
+	}
+}
+
+func TestGetRepoSize(t *testing.T) {
+	getSizePipelineRun := func(age time.Duration, size string) tektonapi.PipelineRun {
+		pipelineRun := tektonapi.PipelineRun{}
+		pipelineRun.CreationTimestamp = metav1.NewTime(time.Now().Add(-age))
+		if size != "" {
+			pipelineRun.Status.PipelineResults = []tektonapi.PipelineRunResult{{Name: RepoSizePipelineResultName, Value: size}}
+		}
+		return pipelineRun
+	}
+	pipelineRuns := []tektonapi.PipelineRun{
+		getSizePipelineRun(2*time.Hour, "1073741824"),
+		getSizePipelineRun(time.Hour, "2147483648"),
+		getSizePipelineRun(time.Minute, ""),
+	}
+
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		pipelineRuns []tektonapi.PipelineRun
+		want         string
+		wantErr      bool
+	}{
+		{name: "unknown", want: ""},
+		{name: "latest reported by builds", pipelineRuns: pipelineRuns, want: "2Gi"},
+		{name: "annotation", annotations: map[string]string{RepoSizeAnnotationName: "3Gi"}, pipelineRuns: pipelineRuns, want: "3Gi"},
+		{name: "invalid annotation", annotations: map[string]string{RepoSizeAnnotationName: "large"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ComponentBuildReconciler{Client: &pipelineRunListClient{pipelineRuns: tt.pipelineRuns}}
+			got, err := r.getRepoSize(context.TODO(), getGitSourceComponent(tt.annotations, ""))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getRepoSize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (got == nil) != (tt.want == "") || (got != nil && got.Cmp(resource.MustParse(tt.want)) != 0) {
+				t.Errorf("getRepoSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
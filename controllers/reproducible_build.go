@@ -0,0 +1,74 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// If set to "true", the build gets parameters which make the resulting image reproducible
+	ReproducibleBuildAnnotationName = "build.appstudio.openshift.io/reproducible-build"
+	// RFC 3339 date of the built git commit, used as the timestamp of reproducible builds
+	SourceCommitDateAnnotationName = "build.appstudio.openshift.io/source-commit-date"
+
+	// Pipeline parameter with the timestamp used instead of the current time, see https://reproducible-builds.org/specs/source-date-epoch/
+	sourceDateEpochParamName = "SOURCE_DATE_EPOCH"
+	// Pipeline parameter which disables image layers with timestamps of the build, e.g. history entries
+	reproducibleParamName = "REPRODUCIBLE"
+)
+
+// getReproducibleBuildParams returns pipeline parameters of the reproducible build of the component
+// or nil if the reproducible build is not requested.
+// The source date epoch is the commit date if known, otherwise Unix epoch start to keep the build deterministic.
+func getReproducibleBuildParams(component appstudiov1alpha1.Component) ([]tektonapi.Param, error) {
+	if component.Annotations[ReproducibleBuildAnnotationName] != "true" {
+		return nil, nil
+	}
+
+	var sourceDateEpoch int64
+	if commitDate := component.Annotations[SourceCommitDateAnnotationName]; commitDate != "" {
+		commitTime, err := time.Parse(time.RFC3339, commitDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s annotation, RFC 3339 date expected: %v", SourceCommitDateAnnotationName, err)
+		}
+		sourceDateEpoch = commitTime.Unix()
+	}
+
+	return []tektonapi.Param{
+		{
+			Name: sourceDateEpochParamName,
+			Value: tektonapi.ArrayOrString{
+				Type:      tektonapi.ParamTypeString,
+				StringVal: strconv.FormatInt(sourceDateEpoch, 10),
+			},
+		},
+		{
+			Name: reproducibleParamName,
+			Value: tektonapi.ArrayOrString{
+				Type:      tektonapi.ParamTypeString,
+				StringVal: "true",
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+func TestGetReproducibleBuildParams(t *testing.T) {
+	tests := []struct {
+		name             string
+		annotations      map[string]string
+		wantEpoch        string
+		wantReproducible string
+		wantErr          bool
+	}{
+		{
+			name:        "reproducible build is not requested",
+			annotations: nil,
+		},
+		{
+			name:        "reproducible build is disabled",
+			annotations: map[string]string{ReproducibleBuildAnnotationName: "false"},
+		},
+		{
+			name: "epoch from commit date",
+			annotations: map[string]string{
+				ReproducibleBuildAnnotationName: "true",
+				SourceCommitDateAnnotationName:  "2022-05-04T15:33:08+02:00",
+			},
+			wantEpoch:        "1651671188",
+			wantReproducible: "true",
+		},
+		{
+			name:             "unknown commit date",
+			annotations:      map[string]string{ReproducibleBuildAnnotationName: "true"},
+			wantEpoch:        "0",
+			wantReproducible: "true",
+		},
+		{
+			name: "invalid commit date",
+			annotations: map[string]string{
+				ReproducibleBuildAnnotationName: "true",
+				SourceCommitDateAnnotationName:  "yesterday",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := getReproducibleBuildParams(getGitSourceComponent(tt.annotations, ""))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getReproducibleBuildParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			build := tektonapi.PipelineRun{Spec: tektonapi.PipelineRunSpec{Params: params}}
+			if got := getPipelineRunParam(build, sourceDateEpochParamName); got != tt.wantEpoch {
+				t.Errorf("getReproducibleBuildParams() %s = %v, want %v", sourceDateEpochParamName, got, tt.wantEpoch)
+			}
+			if got := getPipelineRunParam(build, reproducibleParamName); got != tt.wantReproducible {
+				t.Errorf("getReproducibleBuildParams() %s = %v, want %v", reproducibleParamName, got, tt.wantReproducible)
+			}
+		})
+	}
+}
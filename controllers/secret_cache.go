@@ -0,0 +1,113 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultSecretCacheTTL is the default time git Secrets are cached for
+const DefaultSecretCacheTTL = 30 * time.Second
+
+type cachedSecret struct {
+	secret  *corev1.Secret
+	expires time.Time
+}
+
+// secretCache keeps recently read Secrets for a short time, so builds of many components sharing the same git Secret
+// do not read it from the API server over and over. Missing Secrets are not cached.
+type secretCache struct {
+	client client.Client
+	ttl    time.Duration
+	now    func() time.Time
+
+	mutex   sync.Mutex
+	secrets map[types.NamespacedName]cachedSecret
+}
+
+func newSecretCache(cli client.Client, ttl time.Duration) *secretCache {
+	return &secretCache{
+		client:  cli,
+		ttl:     ttl,
+		now:     time.Now,
+		secrets: map[types.NamespacedName]cachedSecret{},
+	}
+}
+
+// Get reads the Secret from the cache or from the client if the cached Secret has expired.
+// A nil cache reads the Secret from the given fallback client.
+func (c *secretCache) Get(ctx context.Context, fallback client.Client, key types.NamespacedName, secret *corev1.Secret) error {
+	if c == nil || c.ttl <= 0 {
+		return fallback.Get(ctx, key, secret)
+	}
+
+	c.mutex.Lock()
+	cached, exists := c.secrets[key]
+	c.mutex.Unlock()
+	if exists && c.now().Before(cached.expires) {
+		cached.secret.DeepCopyInto(secret)
+		return nil
+	}
+
+	if err := c.client.Get(ctx, key, secret); err != nil {
+		c.Invalidate(key)
+		return err
+	}
+	c.Set(secret)
+	return nil
+}
+
+// Set caches the Secret, e.g. after its update, so the next reads get its latest version.
+func (c *secretCache) Set(secret *corev1.Secret) {
+	if c == nil || c.ttl <= 0 {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.secrets[types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}] = cachedSecret{
+		secret:  secret.DeepCopy(),
+		expires: c.now().Add(c.ttl),
+	}
+	c.removeExpired()
+}
+
+// Invalidate removes the Secret from the cache, e.g. after its update failed.
+func (c *secretCache) Invalidate(key types.NamespacedName) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.secrets, key)
+}
+
+// removeExpired drops expired Secrets, so Secrets of deleted namespaces do not stay in memory.
+// The caller must hold the mutex.
+func (c *secretCache) removeExpired() {
+	now := c.now()
+	for key, cached := range c.secrets {
+		if !now.Before(cached.expires) {
+			delete(c.secrets, key)
+		}
+	}
+}
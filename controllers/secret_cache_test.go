@@ -0,0 +1,117 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// countingSecretClient returns the given Secret and counts the reads
+type countingSecretClient struct {
+	client.Client
+	secret *corev1.Secret
+	gets   int
+}
+
+func (c *countingSecretClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	c.gets++
+	if c.secret == nil {
+		return errors.NewNotFound(schema.GroupResource{Resource: "secrets"}, key.Name)
+	}
+	c.secret.DeepCopyInto(obj.(*corev1.Secret))
+	return nil
+}
+
+func TestSecretCache(t *testing.T) {
+	secretKey := types.NamespacedName{Name: "my-git-secret", Namespace: "my-namespace"}
+	apiClient := &countingSecretClient{}
+	now := time.Now()
+	cache := newSecretCache(apiClient, DefaultSecretCacheTTL)
+	cache.now = func() time.Time { return now }
+
+	get := func() (*corev1.Secret, error) {
+		secret := &corev1.Secret{}
+		err := cache.Get(context.TODO(), nil, secretKey, secret)
+		return secret, err
+	}
+
+	// Missing Secrets are not cached
+	if _, err := get(); !errors.IsNotFound(err) {
+		t.Fatalf("Get() error = %v, want not found", err)
+	}
+	apiClient.secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: secretKey.Name, Namespace: secretKey.Namespace, ResourceVersion: "1"},
+	}
+	if _, err := get(); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if apiClient.gets != 2 {
+		t.Errorf("Get() read the Secret %d times, want 2", apiClient.gets)
+	}
+
+	// Cached Secrets are not read again until they expire
+	now = now.Add(DefaultSecretCacheTTL / 2)
+	secret, err := get()
+	if err != nil || secret.ResourceVersion != "1" || apiClient.gets != 2 {
+		t.Errorf("Get() = %v, %v after %d reads, want cached Secret", secret, err, apiClient.gets)
+	}
+
+	// The cached Secret is modified by the caller and set back after its update
+	secret.ResourceVersion = "2"
+	cache.Set(secret)
+	if secret, err := get(); err != nil || secret.ResourceVersion != "2" || apiClient.gets != 2 {
+		t.Errorf("Get() = %v, %v after %d reads, want updated cached Secret", secret, err, apiClient.gets)
+	}
+
+	// Expired Secrets are read again
+	apiClient.secret.ResourceVersion = "3"
+	now = now.Add(DefaultSecretCacheTTL)
+	if secret, err := get(); err != nil || secret.ResourceVersion != "3" || apiClient.gets != 3 {
+		t.Errorf("Get() = %v, %v after %d reads, want Secret read again", secret, err, apiClient.gets)
+	}
+
+	// Invalidated Secrets are read again
+	cache.Invalidate(secretKey)
+	if _, err := get(); err != nil || apiClient.gets != 4 {
+		t.Errorf("Get() error = %v after %d reads, want Secret read again", err, apiClient.gets)
+	}
+}
+
+func TestSecretCacheDisabled(t *testing.T) {
+	apiClient := &countingSecretClient{secret: &corev1.Secret{}}
+	var cache *secretCache
+
+	for i := 0; i < 2; i++ {
+		if err := cache.Get(context.TODO(), apiClient, types.NamespacedName{Name: "my-git-secret"}, &corev1.Secret{}); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+	}
+	cache.Set(&corev1.Secret{})
+	cache.Invalidate(types.NamespacedName{Name: "my-git-secret"})
+	if apiClient.gets != 2 {
+		t.Errorf("Get() read the Secret %d times, want 2", apiClient.gets)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+// SecretLinkingStrategy defines which fields of the pipeline Service Account the build Secrets are linked in.
+// Tekton distributions differ in the field they read the credentials from.
+type SecretLinkingStrategy string
+
+const (
+	// SecretLinkingStrategySecretField links Secrets in the secrets field of the Service Account
+	SecretLinkingStrategySecretField SecretLinkingStrategy = "SecretField"
+	// SecretLinkingStrategyImagePullSecretField links Secrets in the imagePullSecrets field of the Service Account
+	SecretLinkingStrategyImagePullSecretField SecretLinkingStrategy = "ImagePullSecretField"
+	// SecretLinkingStrategyBoth links Secrets in both secrets and imagePullSecrets fields of the Service Account
+	SecretLinkingStrategyBoth SecretLinkingStrategy = "Both"
+)
+
+// ParseSecretLinkingStrategy returns the Secret linking strategy with the given name.
+// Empty name means the default SecretField strategy.
+func ParseSecretLinkingStrategy(name string) (SecretLinkingStrategy, error) {
+	switch strategy := SecretLinkingStrategy(name); strategy {
+	case "":
+		return SecretLinkingStrategySecretField, nil
+	case SecretLinkingStrategySecretField, SecretLinkingStrategyImagePullSecretField, SecretLinkingStrategyBoth:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unknown Secret linking strategy %q, expected one of %s, %s, %s", name,
+			SecretLinkingStrategySecretField, SecretLinkingStrategyImagePullSecretField, SecretLinkingStrategyBoth)
+	}
+}
+
+func (s SecretLinkingStrategy) linksSecretField() bool {
+	return s == "" || s == SecretLinkingStrategySecretField || s == SecretLinkingStrategyBoth
+}
+
+func (s SecretLinkingStrategy) linksImagePullSecretField() bool {
+	return s == SecretLinkingStrategyImagePullSecretField || s == SecretLinkingStrategyBoth
+}
+
+// dedupServiceAccountSecrets removes repeated references to the same Secret from the Service Account.
+// Returns true if the Service Account was modified.
+func dedupServiceAccountSecrets(serviceAccount *corev1.ServiceAccount) bool {
+	return removeServiceAccountSecrets(serviceAccount, func(string) bool { return true })
+}
+
+// removeServiceAccountSecrets removes references to Secrets which are not kept and repeated references
+// from both secrets and imagePullSecrets fields of the Service Account.
+// Returns true if the Service Account was modified.
+func removeServiceAccountSecrets(serviceAccount *corev1.ServiceAccount, isKept func(secretName string) bool) bool {
+	updated := false
+
+	linkedSecrets := map[string]bool{}
+	secrets := serviceAccount.Secrets[:0]
+	for _, secret := range serviceAccount.Secrets {
+		if linkedSecrets[secret.Name] || !isKept(secret.Name) {
+			updated = true
+			continue
+		}
+		linkedSecrets[secret.Name] = true
+		secrets = append(secrets, secret)
+	}
+	serviceAccount.Secrets = secrets
+
+	linkedImagePullSecrets := map[string]bool{}
+	imagePullSecrets := serviceAccount.ImagePullSecrets[:0]
+	for _, imagePullSecret := range serviceAccount.ImagePullSecrets {
+		if linkedImagePullSecrets[imagePullSecret.Name] || !isKept(imagePullSecret.Name) {
+			updated = true
+			continue
+		}
+		linkedImagePullSecrets[imagePullSecret.Name] = true
+		imagePullSecrets = append(imagePullSecrets, imagePullSecret)
+	}
+	serviceAccount.ImagePullSecrets = imagePullSecrets
+
+	return updated
+}
+
+// pruneServiceAccountSecrets removes references to Secrets which do not exist from the Service Account.
+// Secrets of components in the namespace are kept even if they do not exist yet, as they are linked again on the next build anyway.
+// Returns true if the Service Account was modified.
+func (r *ComponentBuildReconciler) pruneServiceAccountSecrets(ctx context.Context, serviceAccount *corev1.ServiceAccount) (bool, error) {
+	keptSecrets := map[string]bool{}
+
+	// Only metadata of the Secrets is read, the Secrets are not cached
+	secrets := &metav1.PartialObjectMetadataList{}
+	secrets.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("SecretList"))
+	if err := r.NonCachingClient.List(ctx, secrets, client.InNamespace(serviceAccount.Namespace)); err != nil {
+		return false, err
+	}
+	for _, secret := range secrets.Items {
+		keptSecrets[secret.Name] = true
+	}
+
+	components := &appstudiov1alpha1.ComponentList{}
+	if err := r.Client.List(ctx, components, client.InNamespace(serviceAccount.Namespace)); err != nil {
+		return false, err
+	}
+	for _, component := range components.Items {
+		if component.Spec.Secret != "" {
+			keptSecrets[component.Spec.Secret] = true
+		}
+	}
+
+	return removeServiceAccountSecrets(serviceAccount, func(secretName string) bool { return keptSecrets[secretName] }), nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// If set to "true", expiring tokens of the pipeline Service Account are refreshed before the build of the component
+	RefreshServiceAccountTokenAnnotationName = "build.appstudio.openshift.io/refresh-sa-token"
+
+	// Tokens which expire sooner are refreshed, so they outlive typical builds
+	serviceAccountTokenRefreshThreshold = time.Hour
+	// Requested lifetime of the refreshed tokens, the API server may shorten it
+	serviceAccountTokenExpiration = 24 * time.Hour
+)
+
+//+kubebuilder:rbac:groups=core,resources=serviceaccounts/token,verbs=create
+
+// ServiceAccountTokenClient requests new tokens of Service Accounts.
+type ServiceAccountTokenClient interface {
+	// CreateToken returns a new token of the Service Account valid for about the given duration
+	CreateToken(ctx context.Context, namespace string, serviceAccountName string, expiration time.Duration) (string, error)
+}
+
+// KubernetesServiceAccountTokenClient requests Service Account tokens with the TokenRequest API.
+type KubernetesServiceAccountTokenClient struct {
+	Client corev1client.ServiceAccountsGetter
+}
+
+func (c *KubernetesServiceAccountTokenClient) CreateToken(ctx context.Context, namespace string, serviceAccountName string, expiration time.Duration) (string, error) {
+	expirationSeconds := int64(expiration.Seconds())
+	tokenRequest := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &expirationSeconds},
+	}
+	tokenRequest, err := c.Client.ServiceAccounts(namespace).CreateToken(ctx, serviceAccountName, tokenRequest, metav1.CreateOptions{})
+	if err != nil {
+		return "", err
+	}
+	return tokenRequest.Status.Token, nil
+}
+
+// refreshServiceAccountTokens replaces tokens of the Service Account which expire soon in its token Secrets with new ones.
+// Tokens without expiration, e.g. legacy Service Account tokens, are kept.
+// Returns names of the Secrets with refreshed tokens.
+// Secrets are read with the non-caching client, so the manager does not cache all Secrets of the cluster.
+func (r *ComponentBuildReconciler) refreshServiceAccountTokens(ctx context.Context, serviceAccount corev1.ServiceAccount) ([]string, error) {
+	var refreshedSecrets []string
+	for _, secretReference := range serviceAccount.Secrets {
+		tokenSecret := &corev1.Secret{}
+		if err := r.NonCachingClient.Get(ctx, types.NamespacedName{Name: secretReference.Name, Namespace: serviceAccount.Namespace}, tokenSecret); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return refreshedSecrets, err
+		}
+		if tokenSecret.Annotations[corev1.ServiceAccountNameKey] != serviceAccount.Name {
+			// Not a token Secret, e.g. the linked git Secret
+			continue
+		}
+
+		expiration, expires := getTokenExpiration(tokenSecret.Data[corev1.ServiceAccountTokenKey])
+		if !expires || time.Until(expiration) > serviceAccountTokenRefreshThreshold {
+			continue
+		}
+
+		token, err := r.ServiceAccountTokenClient.CreateToken(ctx, serviceAccount.Namespace, serviceAccount.Name, serviceAccountTokenExpiration)
+		if err != nil {
+			return refreshedSecrets, fmt.Errorf("unable to request token of Service Account %s: %v", serviceAccount.Name, err)
+		}
+		tokenSecret.Data[corev1.ServiceAccountTokenKey] = []byte(token)
+		if err := r.Client.Update(ctx, tokenSecret); err != nil {
+			return refreshedSecrets, err
+		}
+		refreshedSecrets = append(refreshedSecrets, tokenSecret.Name)
+	}
+	return refreshedSecrets, nil
+}
+
+// getTokenExpiration returns the expiration time from the exp claim of the JWT token.
+// Returns false if the token is not a JWT or it doesn't expire.
+func getTokenExpiration(token []byte) (time.Time, bool) {
+	parts := strings.Split(string(token), ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	claims := struct {
+		Expiration int64 `json:"exp"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Expiration == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Expiration, 0), true
+}
+
+func isServiceAccountTokenRefreshRequested(component appstudiov1alpha1.Component) bool {
+	return component.Annotations[RefreshServiceAccountTokenAnnotationName] == "true"
+}
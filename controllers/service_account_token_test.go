@@ -0,0 +1,169 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// getTestToken returns an unsigned JWT token with the given claims JSON
+func getTestToken(claims string) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256"}`))
+	return header + "." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".signature"
+}
+
+func TestGetTokenExpiration(t *testing.T) {
+	tests := []struct {
+		name        string
+		token       string
+		want        time.Time
+		wantExpires bool
+	}{
+		{
+			name:        "expiring token",
+			token:       getTestToken(`{"sub":"system:serviceaccount:my-namespace:pipeline","exp":1700000000}`),
+			want:        time.Unix(1700000000, 0),
+			wantExpires: true,
+		},
+		{
+			name:  "legacy token without expiration",
+			token: getTestToken(`{"sub":"system:serviceaccount:my-namespace:pipeline"}`),
+		},
+		{
+			name:  "not a JWT",
+			token: "s3cr3t",
+		},
+		{
+			name:  "invalid payload",
+			token: "header.%%%.signature",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, expires := getTokenExpiration([]byte(tt.token))
+			if expires != tt.wantExpires || !got.Equal(tt.want) {
+				t.Errorf("getTokenExpiration() = %v, %v, want %v, %v", got, expires, tt.want, tt.wantExpires)
+			}
+		})
+	}
+}
+
+// tokenSecretClient returns the given Secrets and records their updates
+type tokenSecretClient struct {
+	client.Client
+	secrets map[string]*corev1.Secret
+	updated []string
+}
+
+func (c *tokenSecretClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	secret, exists := c.secrets[key.Name]
+	if !exists {
+		return errors.NewNotFound(schema.GroupResource{Resource: "secrets"}, key.Name)
+	}
+	secret.DeepCopyInto(obj.(*corev1.Secret))
+	return nil
+}
+
+func (c *tokenSecretClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	secret := obj.(*corev1.Secret)
+	c.secrets[secret.Name] = secret
+	c.updated = append(c.updated, secret.Name)
+	return nil
+}
+
+// uncachedSecretsClient fails reads of Secrets, which must not be read through the manager cache
+type uncachedSecretsClient struct {
+	*tokenSecretClient
+}
+
+func (c *uncachedSecretsClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	return fmt.Errorf("Secret %s is not cached", key.Name)
+}
+
+type testServiceAccountTokenClient struct {
+	token string
+}
+
+func (c *testServiceAccountTokenClient) CreateToken(ctx context.Context, namespace string, serviceAccountName string, expiration time.Duration) (string, error) {
+	if serviceAccountName != "pipeline" {
+		return "", fmt.Errorf("unexpected Service Account %s", serviceAccountName)
+	}
+	return c.token, nil
+}
+
+func TestRefreshServiceAccountTokens(t *testing.T) {
+	getTokenSecret := func(name string, token string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "my-namespace",
+				Annotations: map[string]string{corev1.ServiceAccountNameKey: "pipeline"},
+			},
+			Type: corev1.SecretTypeServiceAccountToken,
+			Data: map[string][]byte{corev1.ServiceAccountTokenKey: []byte(token)},
+		}
+	}
+	expiringToken := getTestToken(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(10*time.Minute).Unix()))
+	validToken := getTestToken(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(10*time.Hour).Unix()))
+	legacyToken := getTestToken(`{"sub":"system:serviceaccount:my-namespace:pipeline"}`)
+	newToken := getTestToken(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(24*time.Hour).Unix()))
+
+	cli := &tokenSecretClient{secrets: map[string]*corev1.Secret{
+		"pipeline-token-expiring": getTokenSecret("pipeline-token-expiring", expiringToken),
+		"pipeline-token-valid":    getTokenSecret("pipeline-token-valid", validToken),
+		"pipeline-token-legacy":   getTokenSecret("pipeline-token-legacy", legacyToken),
+		"git-secret": {
+			ObjectMeta: metav1.ObjectMeta{Name: "git-secret", Namespace: "my-namespace"},
+			Data:       map[string][]byte{corev1.ServiceAccountTokenKey: []byte(expiringToken)},
+		},
+	}}
+	r := &ComponentBuildReconciler{
+		Client:                    &uncachedSecretsClient{cli},
+		NonCachingClient:          cli,
+		ServiceAccountTokenClient: &testServiceAccountTokenClient{token: newToken},
+	}
+	serviceAccount := corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "pipeline", Namespace: "my-namespace"},
+		Secrets: []corev1.ObjectReference{
+			{Name: "pipeline-token-expiring"}, {Name: "pipeline-token-valid"}, {Name: "pipeline-token-legacy"},
+			{Name: "git-secret"}, {Name: "deleted-secret"},
+		},
+	}
+
+	got, err := r.refreshServiceAccountTokens(context.TODO(), serviceAccount)
+	if err != nil {
+		t.Fatalf("refreshServiceAccountTokens() error = %v", err)
+	}
+	want := []string{"pipeline-token-expiring"}
+	if !reflect.DeepEqual(got, want) || !reflect.DeepEqual(cli.updated, want) {
+		t.Errorf("refreshServiceAccountTokens() = %v, updated %v, want %v", got, cli.updated, want)
+	}
+	if token := string(cli.secrets["pipeline-token-expiring"].Data[corev1.ServiceAccountTokenKey]); token != newToken {
+		t.Errorf("refreshServiceAccountTokens() token = %v, want %v", token, newToken)
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// ImageSignaturePipelineResultName is the result of build pipelines with the reference of the signature of the built image
+	ImageSignaturePipelineResultName = "IMAGE_SIGNATURE"
+
+	// Label with the name of the component whose image signature is verified by the PipelineRun
+	SignatureVerificationComponentLabelName = "build.appstudio.openshift.io/verify-component"
+	// Label with the name of the build PipelineRun whose image signature is verified by the PipelineRun
+	SignatureVerificationBuildLabelName = "build.appstudio.openshift.io/verify-build"
+	// Annotation with the public key the signature is verified with by the PipelineRun
+	SignatureVerificationKeyAnnotationName = "build.appstudio.openshift.io/verification-key"
+
+	// SignatureVerifiedConditionType is set on components with the outcome of the signature verification of their latest signed image
+	SignatureVerifiedConditionType = "SignatureVerified"
+
+	SignatureVerifiedReasonValid   = "SignatureValid"
+	SignatureVerifiedReasonInvalid = "SignatureInvalid"
+
+	signatureVerificationPipelineRunSuffix = "-verify"
+	cosignImage                            = "gcr.io/projectsigstore/cosign:v1.8.0"
+)
+
+// isSignedBuild returns true if the PipelineRun reports the signature of the built image.
+func isSignedBuild(pipelineRun tektonapi.PipelineRun) bool {
+	for _, result := range pipelineRun.Status.PipelineResults {
+		if result.Name == ImageSignaturePipelineResultName {
+			return strings.TrimSpace(result.Value) != ""
+		}
+	}
+	return false
+}
+
+// submitSignatureVerification creates PipelineRun which verifies the signature of the image built by the given PipelineRun.
+// Nothing is done if the verification has been submitted already.
+func (r *BuildPipelineRunReconciler) submitSignatureVerification(ctx context.Context, component appstudiov1alpha1.Component, buildPipelineRun tektonapi.PipelineRun) error {
+	log := r.Log.WithValues("Namespace", component.Namespace, "Component", component.Name)
+
+	image, imageDigest := getBuiltImage(buildPipelineRun)
+	if image == "" {
+		return fmt.Errorf("unable to verify image signature of build %s: output image is unknown", buildPipelineRun.Name)
+	}
+	if imageDigest != "" {
+		image = getImageRepository(image) + "@" + imageDigest
+	}
+
+	verificationPipelineRun := generateSignatureVerificationPipelineRun(component, buildPipelineRun, image, r.SignatureVerificationKey)
+	if err := controllerutil.SetOwnerReference(&component, &verificationPipelineRun, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Client.Create(ctx, &verificationPipelineRun); err != nil {
+		if errors.IsAlreadyExists(err) {
+			return nil
+		}
+		return err
+	}
+	log.Info(fmt.Sprintf("Submitted signature verification %s of image %s", verificationPipelineRun.Name, image))
+	return nil
+}
+
+// generateSignatureVerificationPipelineRun returns PipelineRun which verifies the signature of the given image with cosign.
+// The PipelineRun fails if the image is not signed with the given key.
+func generateSignatureVerificationPipelineRun(component appstudiov1alpha1.Component, buildPipelineRun tektonapi.PipelineRun, image string, publicKey string) tektonapi.PipelineRun {
+	return tektonapi.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      buildPipelineRun.Name + signatureVerificationPipelineRunSuffix,
+			Namespace: component.Namespace,
+			Labels: map[string]string{
+				"pipelines.appstudio.openshift.io/type": "verify",
+				SignatureVerificationComponentLabelName: component.Name,
+				SignatureVerificationBuildLabelName:     buildPipelineRun.Name,
+			},
+			Annotations: map[string]string{
+				SignatureVerificationKeyAnnotationName: publicKey,
+			},
+		},
+		Spec: tektonapi.PipelineRunSpec{
+			ServiceAccountName: "pipeline",
+			Params: []tektonapi.Param{
+				{Name: "image", Value: tektonapi.ArrayOrString{Type: tektonapi.ParamTypeString, StringVal: image}},
+				{Name: "public-key", Value: tektonapi.ArrayOrString{Type: tektonapi.ParamTypeString, StringVal: publicKey}},
+			},
+			PipelineSpec: &tektonapi.PipelineSpec{
+				Params: []tektonapi.ParamSpec{
+					{Name: "image", Type: tektonapi.ParamTypeString},
+					{Name: "public-key", Type: tektonapi.ParamTypeString},
+				},
+				Tasks: []tektonapi.PipelineTask{
+					{
+						Name: "verify",
+						Params: []tektonapi.Param{
+							{Name: "image", Value: tektonapi.ArrayOrString{Type: tektonapi.ParamTypeString, StringVal: "$(params.image)"}},
+							{Name: "public-key", Value: tektonapi.ArrayOrString{Type: tektonapi.ParamTypeString, StringVal: "$(params.public-key)"}},
+						},
+						TaskSpec: &tektonapi.EmbeddedTask{
+							TaskSpec: tektonapi.TaskSpec{
+								Params: []tektonapi.ParamSpec{
+									{Name: "image", Type: tektonapi.ParamTypeString},
+									{Name: "public-key", Type: tektonapi.ParamTypeString},
+								},
+								Steps: []tektonapi.Step{
+									{
+										Container: corev1.Container{
+											Name:  "cosign",
+											Image: cosignImage,
+											Args:  []string{"verify", "--key", "$(params.public-key)", "$(params.image)"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// updateSignatureVerificationResult records the outcome of the finished signature verification in the component status conditions.
+func (r *BuildPipelineRunReconciler) updateSignatureVerificationResult(ctx context.Context, component appstudiov1alpha1.Component, verificationPipelineRun tektonapi.PipelineRun) error {
+	switch getBuildState(verificationPipelineRun) {
+	case BuildStateSucceeded, BuildStateFailed:
+		return setComponentCondition(ctx, r.Client, component, getSignatureVerifiedCondition(verificationPipelineRun))
+	default:
+		// The verification is still in progress
+		return nil
+	}
+}
+
+func getSignatureVerifiedCondition(verificationPipelineRun tektonapi.PipelineRun) metav1.Condition {
+	buildName := verificationPipelineRun.Labels[SignatureVerificationBuildLabelName]
+	publicKey := verificationPipelineRun.Annotations[SignatureVerificationKeyAnnotationName]
+	if getBuildState(verificationPipelineRun) == BuildStateSucceeded {
+		return metav1.Condition{
+			Type:    SignatureVerifiedConditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  SignatureVerifiedReasonValid,
+			Message: fmt.Sprintf("The image of build %s is signed with public key %s", buildName, publicKey),
+		}
+	}
+	return metav1.Condition{
+		Type:    SignatureVerifiedConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  SignatureVerifiedReasonInvalid,
+		Message: fmt.Sprintf("Signature of the image of build %s could not be verified with public key %s, see %s", buildName, publicKey, verificationPipelineRun.Name),
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+func TestIsSignedBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []tektonapi.PipelineRunResult
+		want    bool
+	}{
+		{
+			name:    "should detect signed image",
+			results: []tektonapi.PipelineRunResult{{Name: ImageSignaturePipelineResultName, Value: "quay.io/org/app:sha256-abcd.sig"}},
+			want:    true,
+		},
+		{
+			name:    "should ignore empty signature",
+			results: []tektonapi.PipelineRunResult{{Name: ImageSignaturePipelineResultName, Value: " \n"}},
+			want:    false,
+		},
+		{
+			name:    "should ignore build without signature",
+			results: []tektonapi.PipelineRunResult{{Name: "IMAGE_DIGEST", Value: "sha256:abcd"}},
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipelineRun := tektonapi.PipelineRun{}
+			pipelineRun.Status.PipelineResults = tt.results
+			if got := isSignedBuild(pipelineRun); got != tt.want {
+				t.Errorf("isSignedBuild() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateSignatureVerificationPipelineRun(t *testing.T) {
+	component := appstudiov1alpha1.Component{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-component",
+			Namespace: "my-namespace",
+		},
+	}
+	buildPipelineRun := tektonapi.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "my-component-abcde",
+		},
+	}
+	image := "quay.io/org/app@sha256:abcd"
+	publicKey := "k8s://build-service/cosign-public-key"
+
+	got := generateSignatureVerificationPipelineRun(component, buildPipelineRun, image, publicKey)
+	if got.Name != "my-component-abcde-verify" || got.Namespace != "my-namespace" {
+		t.Errorf("generateSignatureVerificationPipelineRun() name = %s/%s, want my-namespace/my-component-abcde-verify", got.Namespace, got.Name)
+	}
+	if got.Labels[SignatureVerificationComponentLabelName] != "my-component" || got.Labels[SignatureVerificationBuildLabelName] != "my-component-abcde" {
+		t.Errorf("generateSignatureVerificationPipelineRun() labels = %v", got.Labels)
+	}
+	if _, isBuild := got.Labels[ComponentNameLabelName]; isBuild {
+		t.Errorf("generateSignatureVerificationPipelineRun() must not be labeled as component build")
+	}
+	if got.Annotations[SignatureVerificationKeyAnnotationName] != publicKey {
+		t.Errorf("generateSignatureVerificationPipelineRun() annotations = %v, want key %s", got.Annotations, publicKey)
+	}
+	if getPipelineRunParam(got, "image") != image || getPipelineRunParam(got, "public-key") != publicKey {
+		t.Errorf("generateSignatureVerificationPipelineRun() params = %v", got.Spec.Params)
+	}
+}
+
+func TestGetSignatureVerifiedCondition(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     corev1.ConditionStatus
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{
+			name:       "should report valid signature",
+			status:     corev1.ConditionTrue,
+			wantStatus: metav1.ConditionTrue,
+			wantReason: SignatureVerifiedReasonValid,
+		},
+		{
+			name:       "should report invalid signature",
+			status:     corev1.ConditionFalse,
+			wantStatus: metav1.ConditionFalse,
+			wantReason: SignatureVerifiedReasonInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verificationPipelineRun := getPipelineRunWithSucceededCondition(tt.status)
+			verificationPipelineRun.Name = "my-component-abcde-verify"
+			verificationPipelineRun.Labels = map[string]string{SignatureVerificationBuildLabelName: "my-component-abcde"}
+			verificationPipelineRun.Annotations = map[string]string{SignatureVerificationKeyAnnotationName: "k8s://build-service/cosign-public-key"}
+
+			got := getSignatureVerifiedCondition(verificationPipelineRun)
+			if got.Type != SignatureVerifiedConditionType || got.Status != tt.wantStatus || got.Reason != tt.wantReason {
+				t.Errorf("getSignatureVerifiedCondition() = %v, want status %v and reason %v", got, tt.wantStatus, tt.wantReason)
+			}
+			if !strings.Contains(got.Message, "k8s://build-service/cosign-public-key") {
+				t.Errorf("getSignatureVerifiedCondition() message = %s, want the public key", got.Message)
+			}
+		})
+	}
+}
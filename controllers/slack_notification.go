@@ -0,0 +1,360 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// SlackChannelWebhookAnnotationName is the annotation of a Component or its namespace with the Slack incoming webhook URL
+	// build failures are posted to. The annotation of the Component takes precedence.
+	SlackChannelWebhookAnnotationName = "build.appstudio.openshift.io/slack-channel-webhook"
+
+	slackDefaultMaxAttempts      = 3
+	slackDefaultRetryInterval    = 2 * time.Second
+	slackDefaultFailureThreshold = 5
+	slackDefaultOpenInterval     = 10 * time.Minute
+	slackDefaultMessagesPerHour  = 30
+)
+
+// Hosts of Slack incoming webhooks, webhooks of other hosts are allowed only if the cluster operator lists them
+var slackDefaultAllowedHosts = []string{"hooks.slack.com"}
+
+// SlackMessage is the payload of Slack incoming webhooks.
+type SlackMessage struct {
+	Text string `json:"text"`
+}
+
+// BuildFailureNotification describes a failed build of a component.
+type BuildFailureNotification struct {
+	Component   string
+	Namespace   string
+	GitURL      string
+	PipelineRun string
+	Reason      string
+	Message     string
+}
+
+// slackRetry is a notification waiting for its next attempt
+type slackRetry struct {
+	// attempts is the number of failed attempts to post the notification
+	attempts int
+	// nextAttempt is the time the notification could be posted again
+	nextAttempt time.Time
+}
+
+// slackWebhookState is the circuit breaker and rate limiter state of a Slack webhook
+type slackWebhookState struct {
+	// consecutiveFailures is the number of notifications which failed after all attempts in a row
+	consecutiveFailures int
+	// openUntil is the time the circuit is open until, notifications are dropped while it is open
+	openUntil time.Time
+	// sentTimes are the times of notifications sent within the last hour
+	sentTimes []time.Time
+}
+
+// SlackBuildFailureNotifier posts build failures to Slack incoming webhooks.
+// Each call posts at most once, failed posts are retried by requeueing the failed build.
+// Webhooks which keep failing are not called for a while (the circuit is open),
+// and the number of messages per webhook and hour is limited to prevent floods on mass failures.
+// Retries and limits are kept in memory, so pending retries are dropped when the controller restarts.
+type SlackBuildFailureNotifier struct {
+	// HTTPClient posts the messages, nil means the client which refuses internal addresses
+	HTTPClient *http.Client
+	// AllowedHosts are hosts of the allowed webhooks, *.domain allows subdomains of the domain, empty means hooks.slack.com
+	AllowedHosts []string
+	// MaxAttempts is the number of attempts to post a message, 0 means 3
+	MaxAttempts int
+	// RetryInterval is the interval between attempts, it doubles with each attempt, 0 means 2 seconds
+	RetryInterval time.Duration
+	// FailureThreshold is the number of failed notifications in a row which opens the circuit, 0 means 5
+	FailureThreshold int
+	// OpenInterval is the time the circuit stays open, 0 means 10 minutes
+	OpenInterval time.Duration
+	// MessagesPerHour limits the messages posted to a webhook, 0 means 30
+	MessagesPerHour int
+
+	now      func() time.Time
+	mutex    sync.Mutex
+	webhooks map[string]*slackWebhookState
+	retries  map[string]*slackRetry
+}
+
+// getSlackChannelWebhook returns the Slack webhook URL from the annotation of the component or its namespace.
+func (r *BuildPipelineRunReconciler) getSlackChannelWebhook(ctx context.Context, component appstudiov1alpha1.Component) (string, error) {
+	if webhook := component.Annotations[SlackChannelWebhookAnnotationName]; webhook != "" {
+		return webhook, nil
+	}
+	namespace := &corev1.Namespace{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: component.Namespace}, namespace); err != nil {
+		return "", err
+	}
+	return namespace.Annotations[SlackChannelWebhookAnnotationName], nil
+}
+
+// notifyBuildFailure posts the failure of the build PipelineRun to the Slack webhook of the component, if any.
+// A positive duration is returned if the notification has to be retried after it.
+func (r *BuildPipelineRunReconciler) notifyBuildFailure(ctx context.Context, component appstudiov1alpha1.Component, pipelineRun tektonapi.PipelineRun) (time.Duration, error) {
+	webhook, err := r.getSlackChannelWebhook(ctx, component)
+	if err != nil || webhook == "" {
+		return 0, err
+	}
+
+	notification := BuildFailureNotification{
+		Component:   component.Name,
+		Namespace:   component.Namespace,
+		PipelineRun: pipelineRun.Name,
+		Reason:      buildFailureReasons[pipelineRun.Annotations[BuildFailureCategoryAnnotationName]],
+	}
+	if gitSource := getGitSource(component); gitSource != nil {
+		notification.GitURL = gitSource.URL
+	}
+	_, notification.Message = getFailedTask(pipelineRun)
+	if notification.Message == "" {
+		notification.Message = pipelineRun.Status.GetCondition(apis.ConditionSucceeded).GetMessage()
+	}
+	return r.SlackNotifier.Notify(ctx, getSlackNotificationKey(pipelineRun), webhook, notification)
+}
+
+func getSlackNotificationKey(pipelineRun tektonapi.PipelineRun) string {
+	return types.NamespacedName{Name: pipelineRun.Name, Namespace: pipelineRun.Namespace}.String()
+}
+
+// getSlackMessage formats the build failure as a Slack message.
+func getSlackMessage(notification BuildFailureNotification) SlackMessage {
+	text := fmt.Sprintf(":x: Build of component *%s* in namespace *%s* failed\n", notification.Component, notification.Namespace)
+	if notification.GitURL != "" {
+		text += fmt.Sprintf("Git URL: %s\n", notification.GitURL)
+	}
+	text += fmt.Sprintf("PipelineRun: %s\n", notification.PipelineRun)
+	if notification.Reason != "" {
+		text += fmt.Sprintf("Reason: %s\n", notification.Reason)
+	}
+	if notification.Message != "" {
+		text += fmt.Sprintf("```%s```", notification.Message)
+	}
+	return SlackMessage{Text: text}
+}
+
+// Notify posts the build failure to the Slack webhook. The key identifies the notification across retries.
+// Notifications are dropped with an error if the webhook is not allowed, while the circuit of the webhook is open
+// or its hourly message limit is reached. If a retryable post fails, the notification is kept with the returned delay,
+// calls before the delay passes return the remaining delay without posting.
+func (n *SlackBuildFailureNotifier) Notify(ctx context.Context, key string, webhook string, notification BuildFailureNotification) (time.Duration, error) {
+	if err := n.validateWebhook(webhook); err != nil {
+		n.forgetRetry(key)
+		return 0, err
+	}
+
+	retry := n.getRetry(key)
+	now := n.currentTime()
+	if retry != nil && now.Before(retry.nextAttempt) {
+		return retry.nextAttempt.Sub(now), nil
+	}
+	if retry == nil {
+		// Retries of the notification are not counted as other messages
+		if err := n.reserve(webhook); err != nil {
+			return 0, err
+		}
+		retry = &slackRetry{}
+	}
+
+	body, err := json.Marshal(getSlackMessage(notification))
+	if err != nil {
+		n.forgetRetry(key)
+		return 0, err
+	}
+
+	retryable, err := n.post(ctx, webhook, body)
+	retry.attempts++
+	maxAttempts := n.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = slackDefaultMaxAttempts
+	}
+	if err != nil && retryable && retry.attempts < maxAttempts {
+		retryInterval := n.RetryInterval
+		if retryInterval <= 0 {
+			retryInterval = slackDefaultRetryInterval
+		}
+		retryInterval <<= retry.attempts - 1
+		retry.nextAttempt = now.Add(retryInterval)
+		n.setRetry(key, retry)
+		return retryInterval, err
+	}
+	n.forgetRetry(key)
+	n.recordResult(webhook, err == nil)
+	return 0, err
+}
+
+// isRetryPending checks whether the notification with the given key waits for its next attempt.
+func (n *SlackBuildFailureNotifier) isRetryPending(key string) bool {
+	return n.getRetry(key) != nil
+}
+
+// validateWebhook checks that the webhook is an https URL of an allowed host.
+// The webhook could be set by tenants, so internal addresses are refused by the default HTTP client too.
+func (n *SlackBuildFailureNotifier) validateWebhook(webhook string) error {
+	allowedHosts := n.AllowedHosts
+	if len(allowedHosts) == 0 {
+		allowedHosts = slackDefaultAllowedHosts
+	}
+	if !strings.HasPrefix(webhook, "https://") {
+		return fmt.Errorf("invalid %s annotation, https URL expected", SlackChannelWebhookAnnotationName)
+	}
+	if err := validateTenantURL(webhook, allowedHosts); err != nil {
+		// The webhook URL contains the Slack token, do not include it in the error
+		return fmt.Errorf("invalid %s annotation: webhook host is not allowed", SlackChannelWebhookAnnotationName)
+	}
+	return nil
+}
+
+// post sends the message to the webhook, the returned flag tells whether a failed post could be retried.
+func (n *SlackBuildFailureNotifier) post(ctx context.Context, webhook string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := n.HTTPClient
+	if httpClient == nil {
+		httpClient = tenantHTTPClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		// The webhook URL contains the Slack token, do not include it in the error
+		return retryable, fmt.Errorf("slack webhook responded with status %d", resp.StatusCode)
+	}
+	return false, nil
+}
+
+// reserve checks the circuit and the hourly message limit of the webhook and counts the message being sent.
+func (n *SlackBuildFailureNotifier) reserve(webhook string) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	now := n.currentTime()
+	state := n.getWebhookState(webhook)
+	if now.Before(state.openUntil) {
+		return fmt.Errorf("slack webhook failed %d times in a row, notifications are paused until %s",
+			state.consecutiveFailures, state.openUntil.Format(time.RFC3339))
+	}
+
+	sentTimes := state.sentTimes[:0]
+	for _, sentTime := range state.sentTimes {
+		if now.Sub(sentTime) < time.Hour {
+			sentTimes = append(sentTimes, sentTime)
+		}
+	}
+	state.sentTimes = sentTimes
+
+	messagesPerHour := n.MessagesPerHour
+	if messagesPerHour <= 0 {
+		messagesPerHour = slackDefaultMessagesPerHour
+	}
+	if len(state.sentTimes) >= messagesPerHour {
+		return fmt.Errorf("slack webhook limit of %d messages per hour reached", messagesPerHour)
+	}
+	state.sentTimes = append(state.sentTimes, now)
+	return nil
+}
+
+// recordResult opens the circuit of the webhook after too many failed notifications in a row.
+func (n *SlackBuildFailureNotifier) recordResult(webhook string, succeeded bool) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	state := n.getWebhookState(webhook)
+	if succeeded {
+		state.consecutiveFailures = 0
+		return
+	}
+	state.consecutiveFailures++
+
+	failureThreshold := n.FailureThreshold
+	if failureThreshold <= 0 {
+		failureThreshold = slackDefaultFailureThreshold
+	}
+	if state.consecutiveFailures >= failureThreshold {
+		openInterval := n.OpenInterval
+		if openInterval <= 0 {
+			openInterval = slackDefaultOpenInterval
+		}
+		state.openUntil = n.currentTime().Add(openInterval)
+	}
+}
+
+func (n *SlackBuildFailureNotifier) getWebhookState(webhook string) *slackWebhookState {
+	if n.webhooks == nil {
+		n.webhooks = map[string]*slackWebhookState{}
+	}
+	state, exists := n.webhooks[webhook]
+	if !exists {
+		state = &slackWebhookState{}
+		n.webhooks[webhook] = state
+	}
+	return state
+}
+
+func (n *SlackBuildFailureNotifier) currentTime() time.Time {
+	if n.now != nil {
+		return n.now()
+	}
+	return time.Now()
+}
+
+func (n *SlackBuildFailureNotifier) getRetry(key string) *slackRetry {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.retries[key]
+}
+
+func (n *SlackBuildFailureNotifier) setRetry(key string, retry *slackRetry) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if n.retries == nil {
+		n.retries = map[string]*slackRetry{}
+	}
+	n.retries[key] = retry
+}
+
+func (n *SlackBuildFailureNotifier) forgetRetry(key string) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	delete(n.retries, key)
+}
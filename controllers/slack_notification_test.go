@@ -0,0 +1,296 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// slackNamespaceClient returns the component namespace with the given annotations
+type slackNamespaceClient struct {
+	client.Client
+	namespaceAnnotations map[string]string
+}
+
+func (c *slackNamespaceClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if namespace, ok := obj.(*corev1.Namespace); ok {
+		namespace.Name = key.Name
+		namespace.Annotations = c.namespaceAnnotations
+	}
+	return nil
+}
+
+func TestGetSlackChannelWebhook(t *testing.T) {
+	tests := []struct {
+		name                 string
+		componentAnnotations map[string]string
+		namespaceAnnotations map[string]string
+		want                 string
+	}{
+		{
+			name: "not set",
+			want: "",
+		},
+		{
+			name:                 "namespace",
+			namespaceAnnotations: map[string]string{SlackChannelWebhookAnnotationName: "https://hooks.slack.com/services/ns"},
+			want:                 "https://hooks.slack.com/services/ns",
+		},
+		{
+			name:                 "component takes precedence",
+			componentAnnotations: map[string]string{SlackChannelWebhookAnnotationName: "https://hooks.slack.com/services/component"},
+			namespaceAnnotations: map[string]string{SlackChannelWebhookAnnotationName: "https://hooks.slack.com/services/ns"},
+			want:                 "https://hooks.slack.com/services/component",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &BuildPipelineRunReconciler{Client: &slackNamespaceClient{namespaceAnnotations: tt.namespaceAnnotations}}
+			got, err := r.getSlackChannelWebhook(context.TODO(), getGitSourceComponent(tt.componentAnnotations, ""))
+			if err != nil {
+				t.Fatalf("getSlackChannelWebhook() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("getSlackChannelWebhook() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetSlackMessage(t *testing.T) {
+	message := getSlackMessage(BuildFailureNotification{
+		Component:   "my-component",
+		Namespace:   "my-namespace",
+		GitURL:      "https://github.com/foo/bar",
+		PipelineRun: "my-component-x7b2k",
+		Reason:      "BuildCompileFailed",
+		Message:     "exit status 1",
+	})
+	for _, want := range []string{"my-component", "my-namespace", "https://github.com/foo/bar", "my-component-x7b2k", "BuildCompileFailed", "exit status 1"} {
+		if !strings.Contains(message.Text, want) {
+			t.Errorf("getSlackMessage() = %v, want it to contain %v", message.Text, want)
+		}
+	}
+}
+
+func getSlackTestServer(t *testing.T, statuses *[]int, received *[]SlackMessage) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		message := SlackMessage{}
+		if err := json.NewDecoder(req.Body).Decode(&message); err != nil {
+			t.Errorf("failed to decode Slack message: %v", err)
+		}
+		*received = append(*received, message)
+		status := http.StatusOK
+		if len(*statuses) > 0 {
+			status = (*statuses)[0]
+			*statuses = (*statuses)[1:]
+		}
+		w.WriteHeader(status)
+	}))
+}
+
+func getTestSlackNotifier(server *httptest.Server, now *time.Time) *SlackBuildFailureNotifier {
+	return &SlackBuildFailureNotifier{
+		HTTPClient:       server.Client(),
+		AllowedHosts:     []string{"127.0.0.1"},
+		FailureThreshold: 2,
+		MessagesPerHour:  3,
+		now:              func() time.Time { return *now },
+	}
+}
+
+func TestSlackBuildFailureNotifierRetry(t *testing.T) {
+	var received []SlackMessage
+	statuses := []int{http.StatusInternalServerError, http.StatusTooManyRequests}
+	server := getSlackTestServer(t, &statuses, &received)
+	defer server.Close()
+	now := time.Now()
+	notifier := getTestSlackNotifier(server, &now)
+
+	retryAfter, err := notifier.Notify(context.TODO(), "my-namespace/my-component-a", server.URL, BuildFailureNotification{Component: "my-component"})
+	if err == nil || retryAfter != slackDefaultRetryInterval {
+		t.Fatalf("Notify() = %v, %v, want retry after %v", retryAfter, err, slackDefaultRetryInterval)
+	}
+	if !notifier.isRetryPending("my-namespace/my-component-a") {
+		t.Errorf("isRetryPending() = false, want true")
+	}
+
+	// Nothing is posted before the retry interval passes
+	now = now.Add(time.Second)
+	if retryAfter, err := notifier.Notify(context.TODO(), "my-namespace/my-component-a", server.URL, BuildFailureNotification{Component: "my-component"}); err != nil || retryAfter != time.Second {
+		t.Errorf("Notify() = %v, %v, want the remaining delay", retryAfter, err)
+	}
+	if len(received) != 1 {
+		t.Errorf("Notify() posted %d times, want 1", len(received))
+	}
+
+	// The retry interval doubles with each attempt
+	now = now.Add(time.Second)
+	if retryAfter, err := notifier.Notify(context.TODO(), "my-namespace/my-component-a", server.URL, BuildFailureNotification{Component: "my-component"}); err == nil || retryAfter != 2*slackDefaultRetryInterval {
+		t.Errorf("Notify() = %v, %v, want retry after %v", retryAfter, err, 2*slackDefaultRetryInterval)
+	}
+	now = now.Add(2 * slackDefaultRetryInterval)
+	if retryAfter, err := notifier.Notify(context.TODO(), "my-namespace/my-component-a", server.URL, BuildFailureNotification{Component: "my-component"}); err != nil || retryAfter != 0 {
+		t.Fatalf("Notify() = %v, %v, want success", retryAfter, err)
+	}
+	if len(received) != 3 {
+		t.Errorf("Notify() posted %d times, want 3", len(received))
+	}
+	if notifier.isRetryPending("my-namespace/my-component-a") {
+		t.Errorf("isRetryPending() = true, want false")
+	}
+
+	// Client errors are not retried
+	received = nil
+	statuses = []int{http.StatusNotFound}
+	if retryAfter, err := notifier.Notify(context.TODO(), "my-namespace/my-component-b", server.URL, BuildFailureNotification{Component: "my-component"}); err == nil || retryAfter != 0 {
+		t.Errorf("Notify() = %v, %v, want error without retry", retryAfter, err)
+	}
+	if len(received) != 1 {
+		t.Errorf("Notify() posted %d times, want 1", len(received))
+	}
+}
+
+func TestSlackBuildFailureNotifierMaxAttempts(t *testing.T) {
+	var received []SlackMessage
+	statuses := []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError}
+	server := getSlackTestServer(t, &statuses, &received)
+	defer server.Close()
+	now := time.Now()
+	notifier := getTestSlackNotifier(server, &now)
+
+	for attempt := 1; attempt <= slackDefaultMaxAttempts; attempt++ {
+		retryAfter, err := notifier.Notify(context.TODO(), "my-namespace/my-component-a", server.URL, BuildFailureNotification{})
+		if err == nil {
+			t.Errorf("Notify() #%d error = nil, want error", attempt)
+		}
+		if (retryAfter > 0) != (attempt < slackDefaultMaxAttempts) {
+			t.Errorf("Notify() #%d retry after %v", attempt, retryAfter)
+		}
+		now = now.Add(retryAfter)
+	}
+	if len(received) != slackDefaultMaxAttempts {
+		t.Errorf("Notify() posted %d times, want %d", len(received), slackDefaultMaxAttempts)
+	}
+	if notifier.isRetryPending("my-namespace/my-component-a") {
+		t.Errorf("isRetryPending() = true, want false")
+	}
+}
+
+func TestSlackBuildFailureNotifierCircuitBreaker(t *testing.T) {
+	var received []SlackMessage
+	statuses := []int{http.StatusBadRequest, http.StatusBadRequest}
+	server := getSlackTestServer(t, &statuses, &received)
+	defer server.Close()
+	now := time.Now()
+	notifier := getTestSlackNotifier(server, &now)
+	notifier.MessagesPerHour = 100
+
+	for i := 0; i < 3; i++ {
+		if _, err := notifier.Notify(context.TODO(), fmt.Sprintf("my-namespace/my-component-%d", i), server.URL, BuildFailureNotification{}); err == nil {
+			t.Errorf("Notify() error = nil, want error")
+		}
+	}
+	if len(received) != 2 {
+		t.Errorf("Notify() posted %d times, want 2 before the circuit opens", len(received))
+	}
+
+	now = now.Add(slackDefaultOpenInterval)
+	if _, err := notifier.Notify(context.TODO(), "my-namespace/my-component-3", server.URL, BuildFailureNotification{}); err != nil {
+		t.Errorf("Notify() error = %v, want the circuit closed", err)
+	}
+}
+
+func TestSlackBuildFailureNotifierRateLimit(t *testing.T) {
+	var received []SlackMessage
+	var statuses []int
+	server := getSlackTestServer(t, &statuses, &received)
+	defer server.Close()
+	now := time.Now()
+	notifier := getTestSlackNotifier(server, &now)
+
+	for i := 0; i < 4; i++ {
+		_, err := notifier.Notify(context.TODO(), fmt.Sprintf("my-namespace/my-component-%d", i), server.URL, BuildFailureNotification{})
+		if (err != nil) != (i == 3) {
+			t.Errorf("Notify() #%d error = %v", i, err)
+		}
+	}
+	if len(received) != 3 {
+		t.Errorf("Notify() posted %d times, want 3", len(received))
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := notifier.Notify(context.TODO(), "my-namespace/my-component-4", server.URL, BuildFailureNotification{}); err != nil {
+		t.Errorf("Notify() error = %v, want the limit reset", err)
+	}
+}
+
+func TestSlackBuildFailureNotifierInvalidWebhook(t *testing.T) {
+	tests := []struct {
+		name         string
+		webhook      string
+		allowedHosts []string
+	}{
+		{
+			name:    "plain http",
+			webhook: "http://hooks.slack.com/services/foo",
+		},
+		{
+			name:    "host not allowed by default",
+			webhook: "https://kubernetes.default.svc/api",
+		},
+		{
+			name:         "host not in operator list",
+			webhook:      "https://hooks.slack.com/services/foo",
+			allowedHosts: []string{"*.mattermost.example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &SlackBuildFailureNotifier{AllowedHosts: tt.allowedHosts}
+			if _, err := notifier.Notify(context.TODO(), "my-namespace/my-component-a", tt.webhook, BuildFailureNotification{}); err == nil {
+				t.Errorf("Notify() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestSlackBuildFailureNotifierInternalAddress(t *testing.T) {
+	var received []SlackMessage
+	var statuses []int
+	server := getSlackTestServer(t, &statuses, &received)
+	defer server.Close()
+
+	// The default client refuses loopback addresses even if the operator allows the host
+	notifier := &SlackBuildFailureNotifier{AllowedHosts: []string{"127.0.0.1"}}
+	if _, err := notifier.Notify(context.TODO(), "my-namespace/my-component-a", server.URL, BuildFailureNotification{}); err == nil {
+		t.Errorf("Notify() error = nil, want error")
+	}
+	if len(received) != 0 {
+		t.Errorf("Notify() posted %d times, want 0", len(received))
+	}
+}
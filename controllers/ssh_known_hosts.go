@@ -0,0 +1,68 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// Workspace of the git clone task with SSH configuration
+	sshDirectoryWorkspaceName = "ssh-directory"
+	// Key of the known hosts file in the Secret referred from the known hosts annotation
+	sshKnownHostsSecretKey = "known_hosts"
+)
+
+// addSSHKnownHostsWorkspace binds the Secret with SSH known hosts of the git server to the build.
+// The Secret must contain known_hosts key.
+func (r *ComponentBuildReconciler) addSSHKnownHostsWorkspace(ctx context.Context, build *tektonapi.PipelineRun, secretName string) error {
+	knownHostsSecret := &corev1.Secret{}
+	if err := r.NonCachingClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: build.Namespace}, knownHostsSecret); err != nil {
+		return err
+	}
+	if _, ok := knownHostsSecret.Data[sshKnownHostsSecretKey]; !ok {
+		return fmt.Errorf("secret %s doesn't contain %s key", secretName, sshKnownHostsSecretKey)
+	}
+
+	setSSHKnownHostsWorkspace(build, secretName)
+	return nil
+}
+
+// setSSHKnownHostsWorkspace sets workspace with only known_hosts file from the given Secret, replacing existing one.
+func setSSHKnownHostsWorkspace(build *tektonapi.PipelineRun, secretName string) {
+	workspace := tektonapi.WorkspaceBinding{
+		Name: sshDirectoryWorkspaceName,
+		Secret: &corev1.SecretVolumeSource{
+			SecretName: secretName,
+			Items: []corev1.KeyToPath{
+				{Key: sshKnownHostsSecretKey, Path: sshKnownHostsSecretKey},
+			},
+		},
+	}
+	for i := range build.Spec.Workspaces {
+		if build.Spec.Workspaces[i].Name == sshDirectoryWorkspaceName {
+			build.Spec.Workspaces[i] = workspace
+			return
+		}
+	}
+	build.Spec.Workspaces = append(build.Spec.Workspaces, workspace)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+func TestSetSSHKnownHostsWorkspace(t *testing.T) {
+	tests := []struct {
+		name       string
+		workspaces []tektonapi.WorkspaceBinding
+		want       int
+	}{
+		{
+			name:       "no workspaces",
+			workspaces: nil,
+			want:       1,
+		},
+		{
+			name:       "other workspaces",
+			workspaces: []tektonapi.WorkspaceBinding{{Name: "workspace"}, {Name: "registry-auth"}},
+			want:       3,
+		},
+		{
+			name:       "existing ssh directory workspace",
+			workspaces: []tektonapi.WorkspaceBinding{{Name: "workspace"}, {Name: sshDirectoryWorkspaceName}},
+			want:       2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			build := &tektonapi.PipelineRun{}
+			build.Spec.Workspaces = tt.workspaces
+			setSSHKnownHostsWorkspace(build, "known-hosts")
+
+			if len(build.Spec.Workspaces) != tt.want {
+				t.Errorf("setSSHKnownHostsWorkspace() workspaces = %v, want %d workspaces", build.Spec.Workspaces, tt.want)
+			}
+			found := false
+			for _, workspace := range build.Spec.Workspaces {
+				if workspace.Name != sshDirectoryWorkspaceName {
+					continue
+				}
+				found = true
+				if workspace.Secret == nil || workspace.Secret.SecretName != "known-hosts" ||
+					len(workspace.Secret.Items) != 1 || workspace.Secret.Items[0].Key != sshKnownHostsSecretKey {
+					t.Errorf("setSSHKnownHostsWorkspace() workspace = %v", workspace)
+				}
+			}
+			if !found {
+				t.Errorf("setSSHKnownHostsWorkspace() %s workspace not found", sshDirectoryWorkspaceName)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+// Index of PipelineRuns by the namespace and name of the component they build
+const componentPipelineRunIndexKey = "build.appstudio.openshift.io/component-name"
+
+// indexComponentPipelineRun returns the index value for PipelineRuns labeled with the component name.
+// Indexing allows to list builds of a component without scanning all PipelineRuns,
+// including builds created in another namespace than the namespace of the component.
+func indexComponentPipelineRun(object client.Object) []string {
+	pipelineRun, ok := object.(*tektonapi.PipelineRun)
+	if !ok {
+		return nil
+	}
+	if componentName := pipelineRun.Labels[ComponentNameLabelName]; componentName != "" {
+		return []string{getComponentPipelineRunIndexValue(getPipelineRunComponentNamespace(*pipelineRun), componentName)}
+	}
+	return nil
+}
+
+func getComponentPipelineRunIndexValue(componentNamespace string, componentName string) string {
+	return types.NamespacedName{Namespace: componentNamespace, Name: componentName}.String()
+}
+
+// listComponentPipelineRuns returns PipelineRuns of the given component in all namespaces.
+// PipelineRuns left from a previous component with the same name in the component namespace are not included.
+// The given client must be backed by the manager cache with the component PipelineRun index.
+// The cache lags behind the API server, so a PipelineRun created just now might not be listed yet.
+// PipelineRuns in another namespace than the build namespace of the component are not included either,
+// as anybody allowed to create PipelineRuns in a namespace can label them with the component.
+func listComponentPipelineRuns(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) ([]tektonapi.PipelineRun, error) {
+	pipelineRuns := &tektonapi.PipelineRunList{}
+	if err := cli.List(ctx, pipelineRuns, client.MatchingFields{componentPipelineRunIndexKey: getComponentPipelineRunIndexValue(component.Namespace, component.Name)}); err != nil {
+		return nil, err
+	}
+
+	var componentPipelineRuns []tektonapi.PipelineRun
+	for _, pipelineRun := range pipelineRuns.Items {
+		if isStalePipelineRun(pipelineRun, component) {
+			continue
+		}
+		trusted, err := isTrustedComponentPipelineRun(ctx, cli, pipelineRun, component)
+		if err != nil {
+			return nil, err
+		}
+		if trusted {
+			componentPipelineRuns = append(componentPipelineRuns, pipelineRun)
+		}
+	}
+	return componentPipelineRuns, nil
+}
+
+// cleanupStalePipelineRuns deletes PipelineRuns left from a previous component with the same name.
+// This happens when a component is deleted and recreated before its PipelineRuns are garbage collected.
+func (r *ComponentBuildReconciler) cleanupStalePipelineRuns(ctx context.Context, component appstudiov1alpha1.Component) error {
+	log := r.Log.WithValues("Namespace", component.Namespace, "Component", component.Name)
+
+	pipelineRuns := &tektonapi.PipelineRunList{}
+	if err := r.Client.List(ctx, pipelineRuns, client.MatchingFields{componentPipelineRunIndexKey: getComponentPipelineRunIndexValue(component.Namespace, component.Name)}); err != nil {
+		return err
+	}
+
+	for i := range pipelineRuns.Items {
+		pipelineRun := &pipelineRuns.Items[i]
+		if !isStalePipelineRun(*pipelineRun, component) {
+			continue
+		}
+		if err := r.Client.Delete(ctx, pipelineRun); err != nil && !errors.IsNotFound(err) {
+			log.Error(err, fmt.Sprintf("Unable to delete stale PipelineRun %s", pipelineRun.Name))
+			return err
+		}
+		log.Info(fmt.Sprintf("Deleted PipelineRun %s left from a previous component with the same name", pipelineRun.Name))
+	}
+	return nil
+}
+
+// isStalePipelineRun checks whether the PipelineRun is owned by a component with the same name but different UID.
+// PipelineRuns without a component owner are not considered stale as they might be created manually.
+func isStalePipelineRun(pipelineRun tektonapi.PipelineRun, component appstudiov1alpha1.Component) bool {
+	for _, owner := range pipelineRun.OwnerReferences {
+		if owner.Kind == "Component" && owner.Name == component.Name {
+			return owner.UID != component.UID
+		}
+	}
+	return false
+}
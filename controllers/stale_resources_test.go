@@ -0,0 +1,114 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+func TestIsStalePipelineRun(t *testing.T) {
+	component := appstudiov1alpha1.Component{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "my-component",
+			UID:  "current-uid",
+		},
+	}
+	tests := []struct {
+		name   string
+		owners []metav1.OwnerReference
+		want   bool
+	}{
+		{
+			name:   "owned by the component",
+			owners: []metav1.OwnerReference{{Kind: "Component", Name: "my-component", UID: "current-uid"}},
+			want:   false,
+		},
+		{
+			name:   "owned by previous component with the same name",
+			owners: []metav1.OwnerReference{{Kind: "Component", Name: "my-component", UID: "previous-uid"}},
+			want:   true,
+		},
+		{
+			name:   "owned by another component",
+			owners: []metav1.OwnerReference{{Kind: "Component", Name: "other-component", UID: "other-uid"}},
+			want:   false,
+		},
+		{
+			name:   "no owner",
+			owners: nil,
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipelineRun := tektonapi.PipelineRun{ObjectMeta: metav1.ObjectMeta{OwnerReferences: tt.owners}}
+			if got := isStalePipelineRun(pipelineRun, component); got != tt.want {
+				t.Errorf("isStalePipelineRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexComponentPipelineRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		object client.Object
+		want   []string
+	}{
+		{
+			name:   "component build",
+			object: withLabels(tektonapi.PipelineRun{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace"}}, map[string]string{ComponentNameLabelName: "my-component"}),
+			want:   []string{"my-namespace/my-component"},
+		},
+		{
+			name: "component build in build namespace",
+			object: withLabels(tektonapi.PipelineRun{ObjectMeta: metav1.ObjectMeta{Namespace: "builds"}},
+				map[string]string{ComponentNameLabelName: "my-component", ComponentNamespaceLabelName: "my-namespace"}),
+			want: []string{"my-namespace/my-component"},
+		},
+		{
+			name:   "empty component name",
+			object: withLabels(tektonapi.PipelineRun{}, map[string]string{ComponentNameLabelName: ""}),
+			want:   nil,
+		},
+		{
+			name:   "not a component build",
+			object: withLabels(tektonapi.PipelineRun{}, map[string]string{ImageScanComponentLabelName: "my-component"}),
+			want:   nil,
+		},
+		{
+			name:   "not a PipelineRun",
+			object: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{ComponentNameLabelName: "my-component"}}},
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := indexComponentPipelineRun(tt.object); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("indexComponentPipelineRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
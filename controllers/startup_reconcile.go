@@ -0,0 +1,67 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+// getStartupComponents returns components whose build relevant fields have not been reconciled yet,
+// e.g. components created or updated while the controller was down.
+func (r *ComponentBuildReconciler) getStartupComponents(ctx context.Context) ([]appstudiov1alpha1.Component, error) {
+	components := &appstudiov1alpha1.ComponentList{}
+	if err := r.Client.List(ctx, components,
+		client.MatchingFields{buildSpecChangedIndexKey: buildSpecChangedIndexValue}); err != nil {
+		return nil, err
+	}
+
+	startupComponents := make([]appstudiov1alpha1.Component, 0, len(components.Items))
+	for i := range components.Items {
+		if r.isInSelectedNamespace(&components.Items[i]) {
+			startupComponents = append(startupComponents, components.Items[i])
+		}
+	}
+	return startupComponents, nil
+}
+
+// reconcileStartupComponents enqueues not yet reconciled components once the controller starts.
+// The initial list of the informer produces create events for all components as well,
+// this makes the startup reconcile independent of the component event predicates.
+func (r *ComponentBuildReconciler) reconcileStartupComponents(ctx context.Context) error {
+	components, err := r.getStartupComponents(ctx)
+	if err != nil {
+		// The components are still reconciled on the informer create events
+		r.Log.Error(err, "Failed to list components for the startup reconcile")
+		return nil
+	}
+	r.Log.Info(fmt.Sprintf("Reconciling %d components not reconciled before the controller start", len(components)))
+
+	for i := range components {
+		select {
+		case r.startupEvents <- event.GenericEvent{Object: &components[i]}:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return nil
+}
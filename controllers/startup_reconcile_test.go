@@ -0,0 +1,106 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/fields"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+// startupComponentClient lists the given components, the build spec changed index is evaluated on the fly
+type startupComponentClient struct {
+	client.Client
+	components []appstudiov1alpha1.Component
+}
+
+func (c *startupComponentClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOptions := &client.ListOptions{}
+	listOptions.ApplyOptions(opts)
+	componentList := list.(*appstudiov1alpha1.ComponentList)
+	for i := range c.components {
+		if listOptions.FieldSelector != nil {
+			indexValues := indexBuildSpecChanged(&c.components[i])
+			if len(indexValues) == 0 || !listOptions.FieldSelector.Matches(fields.Set{buildSpecChangedIndexKey: indexValues[0]}) {
+				continue
+			}
+		}
+		componentList.Items = append(componentList.Items, c.components[i])
+	}
+	return nil
+}
+
+func TestReconcileStartupComponents(t *testing.T) {
+	// Components created while the controller was down, only the unbuilt one must be reconciled
+	unbuiltComponent := getGitSourceComponent(nil, "version: 2.2.0")
+	unbuiltComponent.Name = "unbuilt-component"
+	builtComponent := getGitSourceComponent(map[string]string{InitialBuildAnnotationName: "processed"}, "version: 2.2.0")
+	builtComponent.Name = "built-component"
+	setBuildSpecHash(&builtComponent)
+
+	r := &ComponentBuildReconciler{
+		Client:        &startupComponentClient{components: []appstudiov1alpha1.Component{unbuiltComponent, builtComponent}},
+		Log:           logr.Discard(),
+		startupEvents: make(chan event.GenericEvent, 2),
+	}
+	if err := r.reconcileStartupComponents(context.TODO()); err != nil {
+		t.Fatalf("reconcileStartupComponents() error = %v", err)
+	}
+	close(r.startupEvents)
+
+	var reconciled []string
+	for e := range r.startupEvents {
+		reconciled = append(reconciled, e.Object.GetName())
+		component := e.Object.(*appstudiov1alpha1.Component)
+		if decision := getInitialBuildDecision(*component); decision.Reason != BuildDecisionReasonRequired {
+			t.Errorf("getInitialBuildDecision() = %v, want build of pre-existing component %s", decision.Reason, component.Name)
+		}
+	}
+	if len(reconciled) != 1 || reconciled[0] != unbuiltComponent.Name {
+		t.Errorf("reconcileStartupComponents() enqueued %v, want %v", reconciled, []string{unbuiltComponent.Name})
+	}
+}
+
+func TestReconcileStartupComponentsCanceled(t *testing.T) {
+	r := &ComponentBuildReconciler{
+		Client:        &startupComponentClient{components: []appstudiov1alpha1.Component{getGitSourceComponent(nil, "")}},
+		Log:           logr.Discard(),
+		startupEvents: make(chan event.GenericEvent),
+	}
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	done := make(chan error)
+	go func() {
+		done <- r.reconcileStartupComponents(ctx)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("reconcileStartupComponents() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("reconcileStartupComponents() blocked after the controller stopped")
+	}
+}
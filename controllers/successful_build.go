@@ -0,0 +1,119 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+// JSON of the latest successful build of the component, so it is known after its PipelineRun is deleted
+const LastSuccessfulBuildAnnotationName = "build.appstudio.openshift.io/last-successful-build"
+
+// SuccessfulBuild describes the latest successful build of a component.
+type SuccessfulBuild struct {
+	PipelineRun  string      `json:"pipelineRun"`
+	GitURL       string      `json:"gitUrl,omitempty"`
+	Revision     string      `json:"revision,omitempty"`
+	CreationTime metav1.Time `json:"creationTime"`
+}
+
+func getSuccessfulBuild(pipelineRun tektonapi.PipelineRun) SuccessfulBuild {
+	return SuccessfulBuild{
+		PipelineRun:  pipelineRun.Name,
+		GitURL:       getPipelineRunParam(pipelineRun, "git-url"),
+		Revision:     getPipelineRunParam(pipelineRun, "revision"),
+		CreationTime: pipelineRun.CreationTimestamp,
+	}
+}
+
+// getRecordedSuccessfulBuild returns the successful build recorded in the component annotation, nil if there is none.
+func getRecordedSuccessfulBuild(component appstudiov1alpha1.Component) *SuccessfulBuild {
+	recordJSON := component.Annotations[LastSuccessfulBuildAnnotationName]
+	if recordJSON == "" {
+		return nil
+	}
+	successfulBuild := &SuccessfulBuild{}
+	if err := json.Unmarshal([]byte(recordJSON), successfulBuild); err != nil || successfulBuild.PipelineRun == "" {
+		return nil
+	}
+	return successfulBuild
+}
+
+// getLastSuccessfulBuild returns the latest successful build of the component, nil if the component has not been built yet.
+// The build recorded in the component annotation is used, the PipelineRuns are checked for components built
+// before the builds were recorded.
+func getLastSuccessfulBuild(ctx context.Context, cli client.Client, component appstudiov1alpha1.Component) (*SuccessfulBuild, error) {
+	if successfulBuild := getRecordedSuccessfulBuild(component); successfulBuild != nil {
+		return successfulBuild, nil
+	}
+
+	pipelineRuns, err := listComponentPipelineRuns(ctx, cli, component)
+	if err != nil {
+		return nil, err
+	}
+	var lastSuccessfulBuild *SuccessfulBuild
+	for _, pipelineRun := range pipelineRuns {
+		if getBuildState(pipelineRun) != BuildStateSucceeded {
+			continue
+		}
+		if lastSuccessfulBuild == nil || lastSuccessfulBuild.CreationTime.Before(&pipelineRun.CreationTimestamp) {
+			successfulBuild := getSuccessfulBuild(pipelineRun)
+			lastSuccessfulBuild = &successfulBuild
+		}
+	}
+	return lastSuccessfulBuild, nil
+}
+
+// recordSuccessfulBuild records the succeeded build in the component annotation.
+// The disruption retries of the component are reset, so later disrupted builds are retried again.
+// Builds older than the recorded one, which completed later, do not override it.
+func (r *BuildPipelineRunReconciler) recordSuccessfulBuild(ctx context.Context, component appstudiov1alpha1.Component, pipelineRun tektonapi.PipelineRun) error {
+	if getBuildState(pipelineRun) != BuildStateSucceeded {
+		return nil
+	}
+	recordJSON, err := json.Marshal(getSuccessfulBuild(pipelineRun))
+	if err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latestComponent := &appstudiov1alpha1.Component{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: component.Name, Namespace: component.Namespace}, latestComponent); err != nil {
+			return err
+		}
+		if recorded := getRecordedSuccessfulBuild(*latestComponent); recorded != nil &&
+			(recorded.PipelineRun == pipelineRun.Name || pipelineRun.CreationTimestamp.Before(&recorded.CreationTime)) {
+			return nil
+		}
+
+		if latestComponent.Annotations == nil {
+			latestComponent.Annotations = map[string]string{}
+		}
+		latestComponent.Annotations[LastSuccessfulBuildAnnotationName] = string(recordJSON)
+		resetDisruptionRetries(latestComponent)
+		return r.Client.Update(ctx, latestComponent)
+	})
+}
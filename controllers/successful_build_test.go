@@ -0,0 +1,119 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+// recordingComponentClient returns the given component and records its updates
+type recordingComponentClient struct {
+	client.Client
+	component appstudiov1alpha1.Component
+	updated   []appstudiov1alpha1.Component
+}
+
+func (c *recordingComponentClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	c.component.DeepCopyInto(obj.(*appstudiov1alpha1.Component))
+	return nil
+}
+
+func (c *recordingComponentClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	component := obj.(*appstudiov1alpha1.Component)
+	c.updated = append(c.updated, *component.DeepCopy())
+	c.component = *component.DeepCopy()
+	return nil
+}
+
+func getSuccessfulTestBuild(name string, created time.Time) tektonapi.PipelineRun {
+	pipelineRun := getPipelineRunWithSucceededCondition(corev1.ConditionTrue)
+	pipelineRun.Name = name
+	pipelineRun.CreationTimestamp = metav1.NewTime(created)
+	pipelineRun.Spec.Params = []tektonapi.Param{
+		{Name: "git-url", Value: *tektonapi.NewArrayOrString("https://github.com/foo/bar")},
+		{Name: "revision", Value: *tektonapi.NewArrayOrString("main")},
+	}
+	return pipelineRun
+}
+
+func TestRecordSuccessfulBuild(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	cli := &recordingComponentClient{component: getGitSourceComponent(map[string]string{DisruptionRetriesAnnotationName: "1"}, "")}
+	r := &BuildPipelineRunReconciler{Client: cli}
+
+	latestBuild := getSuccessfulTestBuild("my-component-b", now)
+	if err := r.recordSuccessfulBuild(context.TODO(), cli.component, latestBuild); err != nil {
+		t.Fatalf("recordSuccessfulBuild() error = %v", err)
+	}
+	recorded := getRecordedSuccessfulBuild(cli.component)
+	want := SuccessfulBuild{PipelineRun: "my-component-b", GitURL: "https://github.com/foo/bar", Revision: "main", CreationTime: metav1.NewTime(now)}
+	if recorded == nil || !recorded.CreationTime.Equal(&want.CreationTime) || recorded.PipelineRun != want.PipelineRun ||
+		recorded.GitURL != want.GitURL || recorded.Revision != want.Revision {
+		t.Fatalf("recordSuccessfulBuild() recorded %v, want %v", recorded, want)
+	}
+	if getBuildSpecHash(cli.component) != getBuildSpecHash(getGitSourceComponent(nil, "")) {
+		t.Errorf("recordSuccessfulBuild() changed the build spec of the component")
+	}
+	if _, retried := cli.component.Annotations[DisruptionRetriesAnnotationName]; retried {
+		t.Errorf("recordSuccessfulBuild() kept %s annotation", DisruptionRetriesAnnotationName)
+	}
+
+	// Older build completed later and failed builds do not override the record
+	if err := r.recordSuccessfulBuild(context.TODO(), cli.component, getSuccessfulTestBuild("my-component-a", now.Add(-time.Hour))); err != nil {
+		t.Fatalf("recordSuccessfulBuild() error = %v", err)
+	}
+	failedBuild := getPipelineRunWithSucceededCondition(corev1.ConditionFalse)
+	failedBuild.CreationTimestamp = metav1.NewTime(now.Add(time.Hour))
+	if err := r.recordSuccessfulBuild(context.TODO(), cli.component, failedBuild); err != nil {
+		t.Fatalf("recordSuccessfulBuild() error = %v", err)
+	}
+	if err := r.recordSuccessfulBuild(context.TODO(), cli.component, latestBuild); err != nil {
+		t.Fatalf("recordSuccessfulBuild() error = %v", err)
+	}
+	if len(cli.updated) != 1 {
+		t.Errorf("recordSuccessfulBuild() updated the component %d times, want 1", len(cli.updated))
+	}
+}
+
+func TestGetLastSuccessfulBuildFromPipelineRuns(t *testing.T) {
+	now := time.Now()
+	failedBuild := getPipelineRunWithSucceededCondition(corev1.ConditionFalse)
+	failedBuild.Name = "my-component-c"
+	failedBuild.CreationTimestamp = metav1.NewTime(now)
+	cli := &pipelineRunListClient{pipelineRuns: []tektonapi.PipelineRun{
+		getSuccessfulTestBuild("my-component-a", now.Add(-2*time.Hour)),
+		getSuccessfulTestBuild("my-component-b", now.Add(-time.Hour)),
+		failedBuild,
+	}}
+
+	got, err := getLastSuccessfulBuild(context.TODO(), cli, getGitSourceComponent(nil, ""))
+	if err != nil {
+		t.Fatalf("getLastSuccessfulBuild() error = %v", err)
+	}
+	if got == nil || got.PipelineRun != "my-component-b" {
+		t.Errorf("getLastSuccessfulBuild() = %v, want my-component-b", got)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"go/build"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -27,7 +28,9 @@ import (
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
@@ -36,8 +39,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	taskrunapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	triggersapi "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 
 	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	buildv1alpha1 "github.com/redhat-appstudio/build-service/api/v1alpha1"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -49,8 +54,91 @@ var (
 	testEnv   *envtest.Environment
 	ctx       context.Context
 	cancel    context.CancelFunc
+
+	testConfig   *rest.Config
+	testNotifier = &recordingBuildNotifier{}
+	// componentBuildReconciler is the reconciler of the running manager,
+	// tests change its options only by restartComponentBuildReconciler
+	componentBuildReconciler *ComponentBuildReconciler
+	// stopManager stops the running manager and waits until it is stopped
+	stopManager func()
+	// maintenanceConfigMapKey is the ConfigMap which pauses all builds, it does not exist unless a test creates it
+	maintenanceConfigMapKey = types.NamespacedName{Name: "build-service-maintenance", Namespace: "default"}
 )
 
+// recordingBuildNotifier remembers all sent notifications.
+type recordingBuildNotifier struct {
+	mutex         sync.Mutex
+	notifications []BuildSummary
+}
+
+func (n *recordingBuildNotifier) Notify(ctx context.Context, summary BuildSummary) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.notifications = append(n.notifications, summary)
+	return nil
+}
+
+// getComponentNotifications returns notifications sent about the given component.
+func (n *recordingBuildNotifier) getComponentNotifications(componentName string) []BuildSummary {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	var notifications []BuildSummary
+	for _, notification := range n.notifications {
+		if notification.Component == componentName {
+			notifications = append(notifications, notification)
+		}
+	}
+	return notifications
+}
+
+func (n *recordingBuildNotifier) reset() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.notifications = nil
+}
+
+// stubBuildApprover responds to all build approval requests with the configured state.
+type stubBuildApprover struct {
+	mutex sync.Mutex
+	state string
+}
+
+func (a *stubBuildApprover) Approve(ctx context.Context, request BuildApprovalRequest) (BuildApprovalResponse, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return BuildApprovalResponse{State: a.state}, nil
+}
+
+func (a *stubBuildApprover) setState(state string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.state = state
+}
+
+const stubBundleDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// stubBundleVerifier accepts all pipeline bundles unless configured to fail, the bundles are pinned to stubBundleDigest.
+type stubBundleVerifier struct {
+	mutex sync.Mutex
+	err   error
+}
+
+func (v *stubBundleVerifier) Verify(ctx context.Context, bundle string) (string, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if v.err != nil {
+		return "", v.err
+	}
+	return getImageRepository(bundle) + "@" + stubBundleDigest, nil
+}
+
+func (v *stubBundleVerifier) setError(err error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.err = err
+}
+
 func TestAPIs(t *testing.T) {
 	RegisterFailHandler(Fail)
 
@@ -68,6 +156,7 @@ var _ = BeforeSuite(func() {
 	applicationServiceDepVersion := "v0.0.0-20220504153308-f3507a2f91ed"
 	testEnv = &envtest.Environment{
 		CRDDirectoryPaths: []string{
+			filepath.Join("..", "config", "crd", "bases"),
 			filepath.Join(build.Default.GOPATH, "pkg", "mod", "github.com", "redhat-appstudio", "application-service@"+applicationServiceDepVersion, "config", "crd", "bases"),
 			filepath.Join(build.Default.GOPATH, "pkg", "mod", "github.com", "tektoncd", "triggers@v0.19.1", "config"),
 			filepath.Join(build.Default.GOPATH, "pkg", "mod", "github.com", "tektoncd", "pipeline@v0.33.0", "config"),
@@ -86,6 +175,12 @@ var _ = BeforeSuite(func() {
 	err = taskrunapi.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
 
+	err = triggersapi.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
+
+	err = buildv1alpha1.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
+
 	//+kubebuilder:scaffold:scheme
 
 	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
@@ -101,27 +196,80 @@ var _ = BeforeSuite(func() {
 
 	Expect(k8sClient.Create(context.Background(), &svcAccount)).Should(Succeed())
 
-	k8sManager, err := ctrl.NewManager(cfg, ctrl.Options{
-		Scheme: scheme.Scheme,
+	testConfig = cfg
+	startManager(nil)
+}, 60)
+
+// startManager starts a new manager with the build reconcilers.
+// The component build reconciler is configured by the given function before it is set up,
+// so its options are never changed while the manager workers read them.
+func startManager(configure func(r *ComponentBuildReconciler)) {
+	k8sManager, err := ctrl.NewManager(testConfig, ctrl.Options{
+		Scheme:             scheme.Scheme,
+		MetricsBindAddress: "0",
 	})
 	Expect(err).ToNot(HaveOccurred())
 
-	err = (&ComponentBuildReconciler{
+	componentBuildReconciler = &ComponentBuildReconciler{
 		Client:           k8sManager.GetClient(),
 		NonCachingClient: k8sManager.GetClient(),
 		Scheme:           k8sManager.GetScheme(),
 		Log:              ctrl.Log.WithName("controllers").WithName("ComponentInitialBuild"),
+
+		MaintenanceConfigMap:      &maintenanceConfigMapKey,
+		BuildServiceConfigEnabled: true,
+		Recorder:                  k8sManager.GetEventRecorderFor("build-service"),
+		// Audit records do not affect the builds, the build audit specs clean them up
+		BuildAuditEnabled: true,
+	}
+	if configure != nil {
+		configure(componentBuildReconciler)
+	}
+	err = componentBuildReconciler.SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	err = (&BuildPipelineRunReconciler{
+		Client: k8sManager.GetClient(),
+		Scheme: k8sManager.GetScheme(),
+		Log:    ctrl.Log.WithName("controllers").WithName("BuildPipelineRun"),
+
+		BuildSummaryEnabled: true,
+		Notifier:            testNotifier,
+	}).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	err = (&BuildAuditRecordReconciler{
+		Client: k8sManager.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("BuildAuditRecord"),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
+	managerCtx, managerCancel := context.WithCancel(ctx)
+	managerDone := make(chan struct{})
 	go func() {
 		defer GinkgoRecover()
-		err = k8sManager.Start(ctx)
+		defer close(managerDone)
+		err := k8sManager.Start(managerCtx)
 		Expect(err).ToNot(HaveOccurred(), "failed to run manager")
 	}()
-}, 60)
+	Expect(k8sManager.GetCache().WaitForCacheSync(managerCtx)).To(BeTrue())
+
+	stopManager = func() {
+		managerCancel()
+		<-managerDone
+	}
+}
+
+// restartComponentBuildReconciler replaces the running manager with a new one
+// whose component build reconciler is configured by the given function.
+// Passing nil restores the default options.
+func restartComponentBuildReconciler(configure func(r *ComponentBuildReconciler)) {
+	stopManager()
+	startManager(configure)
+}
 
 var _ = AfterSuite(func() {
+	stopManager()
 	cancel()
 	By("tearing down the test environment")
 	err := testEnv.Stop()
@@ -0,0 +1,97 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+)
+
+const (
+	// Storage class of the build workspace PVC, e.g. fast storage for critical builds.
+	// The build gets its own workspace PVC instead of the shared appstudio PVC. Each build uses a new sub path
+	// of the shared PVC, so builds do not share the sources either way, but the per-build PVC is limited by its size.
+	WorkspaceStorageClassAnnotationName = "build.appstudio.openshift.io/workspace-storage-class"
+	// Size of the per-build workspace PVC, e.g. 5Gi for large sources. Used only with the workspace storage class.
+	WorkspaceStorageSizeAnnotationName = "build.appstudio.openshift.io/workspace-storage-size"
+
+	// Name of the build pipeline workspace with the sources
+	sourceWorkspaceName = "workspace"
+	// Default size of the per-build workspace PVC, the same as of the shared workspace PVC
+	workspaceStorageSize = "1Gi"
+)
+
+// getWorkspaceStorageClass returns the storage class of the build workspace requested in the component annotation.
+func getWorkspaceStorageClass(component appstudiov1alpha1.Component) (string, error) {
+	storageClass := strings.TrimSpace(component.Annotations[WorkspaceStorageClassAnnotationName])
+	if storageClass == "" {
+		return "", nil
+	}
+	if errs := validation.IsDNS1123Subdomain(storageClass); len(errs) > 0 {
+		return "", fmt.Errorf("invalid %s annotation %q: %s", WorkspaceStorageClassAnnotationName, storageClass, strings.Join(errs, ", "))
+	}
+	return storageClass, nil
+}
+
+// getWorkspaceStorageSize returns the size of the per-build workspace PVC requested in the component annotation,
+// 1Gi by default.
+func getWorkspaceStorageSize(component appstudiov1alpha1.Component) (resource.Quantity, error) {
+	value := strings.TrimSpace(component.Annotations[WorkspaceStorageSizeAnnotationName])
+	if value == "" {
+		return resource.MustParse(workspaceStorageSize), nil
+	}
+	size, err := resource.ParseQuantity(value)
+	if err != nil || size.Sign() <= 0 {
+		return resource.Quantity{}, fmt.Errorf("invalid %s annotation %q: positive quantity expected, e.g. 5Gi", WorkspaceStorageSizeAnnotationName, value)
+	}
+	return size, nil
+}
+
+// applyWorkspaceStorageClass sets the storage class of volumeClaimTemplate workspaces of the build.
+// The source workspace bound to the shared PVC is replaced by a volumeClaimTemplate of the given size,
+// as the storage class of the existing shared PVC cannot be changed.
+func applyWorkspaceStorageClass(storageClass string, size resource.Quantity, build *tektonapi.PipelineRun) {
+	if storageClass == "" {
+		return
+	}
+	for i := range build.Spec.Workspaces {
+		workspace := &build.Spec.Workspaces[i]
+		if workspace.Name == sourceWorkspaceName && workspace.PersistentVolumeClaim != nil {
+			workspace.PersistentVolumeClaim = nil
+			workspace.VolumeClaimTemplate = &corev1.PersistentVolumeClaim{
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceStorage: size,
+						},
+					},
+				},
+			}
+		}
+		if workspace.VolumeClaimTemplate != nil {
+			workspace.VolumeClaimTemplate.Spec.StorageClassName = &storageClass
+		}
+	}
+}
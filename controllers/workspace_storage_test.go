@@ -0,0 +1,119 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	tektonapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/redhat-appstudio/application-service/gitops"
+	"github.com/redhat-appstudio/application-service/gitops/prepare"
+)
+
+func TestGetWorkspaceStorageClass(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		want       string
+		wantErr    bool
+	}{
+		{name: "not set", annotation: "", want: ""},
+		{name: "storage class", annotation: "fast-nvme", want: "fast-nvme"},
+		{name: "surrounding spaces", annotation: " standard ", want: "standard"},
+		{name: "invalid name", annotation: "Fast NVMe", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := getGitSourceComponent(map[string]string{WorkspaceStorageClassAnnotationName: tt.annotation}, "")
+			got, err := getWorkspaceStorageClass(component)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getWorkspaceStorageClass() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("getWorkspaceStorageClass() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetWorkspaceStorageSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		want       string
+		wantErr    bool
+	}{
+		{name: "not set", annotation: "", want: workspaceStorageSize},
+		{name: "size", annotation: "5Gi", want: "5Gi"},
+		{name: "surrounding spaces", annotation: " 500Mi ", want: "500Mi"},
+		{name: "invalid quantity", annotation: "5 GB", wantErr: true},
+		{name: "zero size", annotation: "0", wantErr: true},
+		{name: "negative size", annotation: "-1Gi", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := getGitSourceComponent(map[string]string{WorkspaceStorageSizeAnnotationName: tt.annotation}, "")
+			got, err := getWorkspaceStorageSize(component)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getWorkspaceStorageSize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.String() != tt.want {
+				t.Errorf("getWorkspaceStorageSize() = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyWorkspaceStorageClass(t *testing.T) {
+	build := gitops.GenerateInitialBuildPipelineRun(getGitSourceComponent(nil, "version: 2.2.0"), prepare.GitopsConfig{})
+	build.Spec.Workspaces = append(build.Spec.Workspaces, tektonapi.WorkspaceBinding{
+		Name:                "cache",
+		VolumeClaimTemplate: &corev1.PersistentVolumeClaim{},
+	})
+
+	applyWorkspaceStorageClass("fast-nvme", resource.MustParse("5Gi"), &build)
+
+	for _, workspace := range build.Spec.Workspaces {
+		switch workspace.Name {
+		case sourceWorkspaceName, "cache":
+			if workspace.PersistentVolumeClaim != nil || workspace.VolumeClaimTemplate == nil ||
+				workspace.VolumeClaimTemplate.Spec.StorageClassName == nil || *workspace.VolumeClaimTemplate.Spec.StorageClassName != "fast-nvme" {
+				t.Errorf("applyWorkspaceStorageClass() workspace %s = %v, want volumeClaimTemplate with fast-nvme storage class", workspace.Name, workspace)
+			}
+		default:
+			if workspace.VolumeClaimTemplate != nil {
+				t.Errorf("applyWorkspaceStorageClass() workspace %s = %v, want it unchanged", workspace.Name, workspace)
+			}
+		}
+	}
+	if got := build.Spec.Workspaces[0].VolumeClaimTemplate.Spec.Resources.Requests.Storage().String(); got != "5Gi" {
+		t.Errorf("applyWorkspaceStorageClass() workspace size = %v, want 5Gi", got)
+	}
+}
+
+func TestApplyWorkspaceStorageClassNotSet(t *testing.T) {
+	build := gitops.GenerateInitialBuildPipelineRun(getGitSourceComponent(nil, "version: 2.2.0"), prepare.GitopsConfig{})
+
+	applyWorkspaceStorageClass("", resource.MustParse(workspaceStorageSize), &build)
+
+	if workspace := build.Spec.Workspaces[0]; workspace.PersistentVolumeClaim == nil || workspace.PersistentVolumeClaim.ClaimName != "appstudio" {
+		t.Errorf("applyWorkspaceStorageClass() workspace = %v, want the shared PVC", workspace)
+	}
+}
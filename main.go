@@ -18,21 +18,29 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	appstudiov1alpha1 "github.com/redhat-appstudio/application-service/api/v1alpha1"
+	buildv1alpha1 "github.com/redhat-appstudio/build-service/api/v1alpha1"
 	"github.com/redhat-appstudio/build-service/controllers"
 	taskrunapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	triggersapi "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
@@ -48,18 +56,243 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(appstudiov1alpha1.AddToScheme(scheme))
+	utilruntime.Must(buildv1alpha1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
+	var leaderElectionID string
 	var probeAddr string
+	var buildStatusAddr string
+	var requireNamespaceLabel string
+	var keepStalePipelineRuns bool
+	var buildSummaryEnabled bool
+	var imageRepositoryAPIURL string
+	var imageRepositoryTokenFile string
+	var autoCreateImageRepository bool
+	var buildNotificationURL string
+	var slackNotificationsEnabled bool
+	var slackWebhookAllowedHosts string
+	var buildProvenanceEnabled bool
+	var signatureVerificationKey string
+	var maxConcurrentBuilds int
+	var legacyComponentLabelName string
+	var maintenanceConfigMap string
+	var buildsDisabled bool
+	var maxPipelineRunSize int
+	var pipelineRunRetentionEnabled bool
+	var pipelineRunRetention time.Duration
+	var buildLogRetention time.Duration
+	var pipelineRunRetentionKeepPVCs bool
+	var tektonNamespace string
+	var skipExistingImageBuild bool
+	var buildAuditEnabled bool
+	var secretLinkingStrategy string
+	var gitSecretRotationPolicy string
+	var pruneServiceAccountSecrets bool
+	var strictGitSecretValidation bool
+	var buildApprovalURL string
+	var buildApprovalPollInterval time.Duration
+	var approvalWebhookAllowedHosts string
+	var devfileWaitTimeout time.Duration
+	var devfileRecheckInterval time.Duration
+	var reconcileAllOnStartup bool
+	var buildSLOConfigMap string
+	var bundleVerificationKey string
+	var buildServiceConfigEnabled bool
+	var buildTiersConfigMap string
+	var repoSizeScalingConfigMap string
+	var clusterBuildLabelsConfigMap string
+	var buildPipelinesConfigMap string
+	var buildEnvironmentsNamespace string
+	var resubmitDeletedBuilds bool
+	var secretCacheTTL time.Duration
+	var pipelineRunGenerationWorkers int
+	var gitStatusEnabled bool
+	var gitStatusSecretName string
+	var gitStatusInternalHosts string
+	var tektonResultsAPIAddress string
+	var componentDeletionWebhookEnabled bool
+	var buildAuditWebhookEnabled bool
+	var retryableBuildFailureMessages string
+	var applicationBuildStatusEnabled bool
+	var unknownApplicationPolicy string
+	var immutableTagPolicy string
+	var pipelineRunAPIVersion string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&buildStatusAddr, "build-status-bind-address", "", "The address the build status API endpoint binds to. Empty value disables the endpoint. "+
+		"Requests are authorized by the bearer token of the caller against the requested Components.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&leaderElectionID, "leader-elect-id", "5483be8f.redhat.com",
+		"Name of the resource lock used for leader election. "+
+			"Set a different name for each build-service instance running in the same namespace, e.g. per tenant.")
+	flag.StringVar(&requireNamespaceLabel, "require-namespace-label", "",
+		"Build only Components in namespaces that have the given label in key=value format. "+
+			"Empty value enables builds in all namespaces.")
+	flag.BoolVar(&keepStalePipelineRuns, "keep-stale-pipelineruns", false,
+		"Do not delete PipelineRuns left from a previously deleted Component with the same name.")
+	flag.BoolVar(&buildSummaryEnabled, "build-summary-configmap", false,
+		"Maintain a ConfigMap with JSON summary of the latest build for each Component.")
+	flag.StringVar(&imageRepositoryAPIURL, "image-repository-api-url", "",
+		"Quay API URL, e.g. https://quay.io/api/v1, used to check that build output image repository exists. "+
+			"Empty value disables the check.")
+	flag.StringVar(&imageRepositoryTokenFile, "image-repository-token-file", "",
+		"Path to the file with Quay API token.")
+	flag.BoolVar(&autoCreateImageRepository, "image-repository-auto-create", false,
+		"Create missing build output image repository before the build. Requires --image-repository-api-url.")
+	flag.StringVar(&buildNotificationURL, "build-notification-webhook-url", "",
+		"URL to post JSON build summary to when a Component is built successfully for the first time. "+
+			"Empty value disables the notifications.")
+	flag.BoolVar(&slackNotificationsEnabled, "slack-notifications", false,
+		"Post Component build failures to the Slack incoming webhook in build.appstudio.openshift.io/slack-channel-webhook "+
+			"annotation of the Component or its namespace. Posts are retried and limited per webhook to prevent floods.")
+	flag.StringVar(&slackWebhookAllowedHosts, "slack-webhook-allowed-hosts", "hooks.slack.com",
+		"Comma separated hosts allowed in build.appstudio.openshift.io/slack-channel-webhook annotation, "+
+			"*.domain allows subdomains of the domain. Webhooks resolving to loopback, link-local or private addresses are refused.")
+	flag.BoolVar(&buildProvenanceEnabled, "build-provenance-status", false,
+		"Record the provenance attestation reference of the latest successful Component build in its BuildProvenance condition. "+
+			"The reference is read from PROVENANCE_REF pipeline result or derived from the image signed by Tekton Chains.")
+	flag.StringVar(&signatureVerificationKey, "image-signature-verification-key", "",
+		"Public key or KMS URI the images of successful builds with IMAGE_SIGNATURE pipeline result are verified with. "+
+			"The signature is verified with cosign in a follow-up PipelineRun and the outcome is recorded in SignatureVerified condition "+
+			"of the Component. Empty value disables the verification.")
+	flag.IntVar(&maxConcurrentBuilds, "max-concurrent-builds", 0,
+		"Maximum number of pending and running Component builds in the whole cluster. "+
+			"Builds over the limit are queued. 0 means no limit.")
+	flag.StringVar(&legacyComponentLabelName, "legacy-component-label-key", "",
+		"Previous key of the PipelineRun label with Component name. "+
+			"PipelineRuns labeled with it get the current label on Component reconcile.")
+	flag.StringVar(&maintenanceConfigMap, "maintenance-configmap", "",
+		"ConfigMap in namespace/name format which pauses submission of all builds when its maintenance-mode key is \"true\". "+
+			"Empty value disables the maintenance mode.")
+	flag.BoolVar(&buildsDisabled, "builds-disabled", false,
+		"Do not submit any Component builds. Components are still reconciled and the builds are submitted "+
+			"after restart of the controller without this flag.")
+	flag.IntVar(&maxPipelineRunSize, "max-pipelinerun-size", controllers.DefaultMaxPipelineRunSize,
+		"Maximum size in bytes of the generated Component build PipelineRun. Larger builds are not submitted "+
+			"and the Component gets PipelineRunTooLarge condition with the largest parameters. 0 disables the check.")
+	flag.BoolVar(&pipelineRunRetentionEnabled, "pipelinerun-retention-cleanup", false,
+		"Periodically delete Component build PipelineRuns older than --pipelinerun-retention.")
+	flag.DurationVar(&pipelineRunRetention, "pipelinerun-retention", controllers.DefaultPipelineRunRetention,
+		"Maximum age of Component build PipelineRuns kept by the retention cleanup.")
+	flag.BoolVar(&pipelineRunRetentionKeepPVCs, "pipelinerun-retention-keep-pvcs", false,
+		"Do not delete PVCs created for volumeClaimTemplate workspaces of the PipelineRuns deleted by the retention cleanup.")
+	flag.DurationVar(&buildLogRetention, "build-log-retention", 0,
+		"Default build log retention stamped in the build.appstudio.openshift.io/log-retention annotation of Component build PipelineRuns "+
+			"for log pruners. The Component annotation with the same key takes precedence. "+
+			"The retention cleanup keeps PipelineRuns at least for their log retention. 0 means no annotation.")
+	flag.StringVar(&tektonNamespace, "tekton-namespace", controllers.DefaultTektonNamespace,
+		"Namespace of the Tekton installation with feature-flags ConfigMap, e.g. openshift-pipelines.")
+	flag.BoolVar(&skipExistingImageBuild, "skip-existing-image-build", false,
+		"Do not submit the initial build of a Component commit if its output image repository has the image tagged "+
+			"with the commit SHA already. Requires --image-repository-api-url, builds of branches are always submitted.")
+	flag.BoolVar(&buildAuditEnabled, "build-audit", false,
+		"Create a BuildAuditRecord for each Component build submitted by the controller. Requires BuildAuditRecord CRD. "+
+			"Use --build-audit-webhook to reject changes of the records.")
+	flag.StringVar(&secretLinkingStrategy, "secret-linking-strategy", string(controllers.SecretLinkingStrategySecretField),
+		"Fields of the pipeline Service Account the build Secrets are linked in: "+
+			"SecretField (secrets), ImagePullSecretField (imagePullSecrets) or Both, depending on the Tekton distribution.")
+	flag.StringVar(&gitSecretRotationPolicy, "git-secret-rotation-policy", string(controllers.GitSecretRotationPolicyNone),
+		"Handling of Components when their git Secret, e.g. shared by the namespace, is rotated: "+
+			"None (not watched), Annotate (annotate the Secret for Tekton again) or Rebuild (annotate and rebuild the built Components).")
+	flag.BoolVar(&pruneServiceAccountSecrets, "prune-service-account-secrets", false,
+		"Remove links to Secrets which do not exist from the pipeline Service Account before each Component build. "+
+			"Secrets of Components in the namespace are kept.")
+	flag.BoolVar(&reconcileAllOnStartup, "reconcile-all-on-startup", false,
+		"Reconcile all Components whose build relevant fields have not been reconciled once the controller starts, "+
+			"e.g. Components created while the controller was down, in addition to the informer initial list events.")
+	flag.BoolVar(&strictGitSecretValidation, "strict-git-secret-validation", false,
+		"Do not build Components whose git Secret lacks the keys required by its type, e.g. username and password of basic-auth Secret. "+
+			"The InvalidGitSecret Component condition is set regardless of this option.")
+	flag.StringVar(&buildApprovalURL, "build-approval-webhook-url", "",
+		"URL to post JSON build approval request to before the build of a Component which requires approval. "+
+			"Empty value disables the approval.")
+	flag.StringVar(&approvalWebhookAllowedHosts, "approval-webhook-allowed-hosts", "",
+		"Comma separated hosts allowed in build.appstudio.openshift.io/approval-webhook-url Component annotation, "+
+			"*.domain allows subdomains of the domain. Builds of Components with other approval webhooks are denied. "+
+			"Webhooks resolving to loopback, link-local or private addresses are refused.")
+	flag.DurationVar(&buildApprovalPollInterval, "build-approval-poll-interval", 30*time.Second,
+		"Interval the approval of a pending build is requested again, e.g. from the webhook "+
+			"in build.appstudio.openshift.io/approval-webhook-url Component annotation.")
+	flag.DurationVar(&devfileWaitTimeout, "devfile-wait-timeout", 10*time.Minute,
+		"Time after creation of a git source Component its missing devfile model is reported as a warning "+
+			"in WaitingForDevfile condition and event. 0 disables the warning.")
+	flag.DurationVar(&devfileRecheckInterval, "devfile-recheck-interval", 0,
+		"Interval Components whose devfile model is not set within the wait timeout are checked again. 0 disables the rechecks.")
+	flag.StringVar(&buildSLOConfigMap, "build-slo-configmap", "",
+		"ConfigMap in namespace/name format with build SLOs in its slos.yaml key. "+
+			"PrometheusRule with alerts of the SLOs is generated next to it. Requires PrometheusRule CRD. "+
+			"Empty value disables the alerts generation.")
+	flag.StringVar(&bundleVerificationKey, "bundle-verification-key", "",
+		"Path to the public key or KMS URI of the key the build pipeline bundles must be signed with. "+
+			"The signatures are verified with cosign CLI shipped in the controller image before each build, "+
+			"and the builds use the verified digest of the bundle. Empty value disables the verification.")
+	flag.BoolVar(&buildServiceConfigEnabled, "build-service-config", false,
+		"Override the build defaults with BuildServiceConfig of the Component namespace. Requires BuildServiceConfig CRD.")
+	flag.StringVar(&buildTiersConfigMap, "build-tiers-configmap", "",
+		"ConfigMap in namespace/name format with build profiles by Component tiers in its tiers.yaml key. "+
+			"The tier is read from the build.appstudio.openshift.io/tier label of the Component or its namespace. "+
+			"Empty value disables the build tiers.")
+	flag.StringVar(&repoSizeScalingConfigMap, "repo-size-scaling-configmap", "",
+		"ConfigMap in namespace/name format with scaling of build parameters and resources by the git repository size "+
+			"in its scaling.yaml key. The size is read from the build.appstudio.openshift.io/repo-size Component annotation "+
+			"or REPO_SIZE result of the previous builds. Empty value disables the scaling.")
+	flag.StringVar(&clusterBuildLabelsConfigMap, "cluster-build-labels-configmap", "",
+		"ConfigMap in namespace/name format whose data are labels added to all Component build PipelineRuns, "+
+			"e.g. the cluster identity. Labels set by the build service are not overridden. Empty value disables the labels.")
+	flag.StringVar(&buildPipelinesConfigMap, "build-pipelines-configmap", "",
+		"ConfigMap in namespace/name format whose data map devfile languages to build pipeline names, e.g. java: java-build-pipeline. "+
+			"Components with a Dockerfile keep the docker-build pipeline. Empty value means the pipeline detected by the build generator.")
+	flag.StringVar(&buildEnvironmentsNamespace, "build-environments-namespace", "",
+		"Namespace with <environment>-build-params ConfigMaps whose data are pipeline parameters and <environment>-build-profile "+
+			"ConfigMaps with registry, pipeline and params of the build profile in profile.yaml key. The environment is read from "+
+			"the build.appstudio.openshift.io/environment Component annotation or namespace label, default otherwise. "+
+			"Empty value disables the build environments.")
+	flag.BoolVar(&resubmitDeletedBuilds, "resubmit-deleted-builds", false,
+		"Resubmit the build of a Component if its build PipelineRun is deleted before completion. "+
+			"Cancelled and completed builds are not resubmitted.")
+	flag.DurationVar(&secretCacheTTL, "secret-cache-ttl", controllers.DefaultSecretCacheTTL,
+		"Time git Secrets read for Component builds are cached for. 0 disables the cache.")
+	flag.IntVar(&pipelineRunGenerationWorkers, "pipelinerun-generation-workers", 0,
+		"Number of workers generating Component build PipelineRuns in background, so large devfiles do not block reconciles. "+
+			"0 means the PipelineRuns are generated within the reconcile.")
+	flag.BoolVar(&gitStatusEnabled, "git-status", false,
+		"Report outcomes of completed Component builds as commit statuses to GitHub, GitLab or Bitbucket. "+
+			"The git provider token is read from --git-status-secret in the Component namespace.")
+	flag.StringVar(&gitStatusSecretName, "git-status-secret", controllers.DefaultGitStatusSecretName,
+		"Name of the Secret in each namespace with the git provider token in its token key, used to report commit statuses.")
+	flag.StringVar(&gitStatusInternalHosts, "git-status-internal-hosts", "",
+		"Comma separated hosts of self-hosted git servers whose API may resolve to loopback, link-local or private addresses, "+
+			"*.domain allows subdomains of the domain. Commit statuses of repositories on other hosts are not posted to internal addresses.")
+	flag.StringVar(&unknownApplicationPolicy, "unknown-application-policy", string(controllers.UnknownApplicationPolicyIgnore),
+		"Handling of Components which reference a nonexistent Application: "+
+			"Ignore (no check), Proceed (set UnknownApplication condition and build) or Block (set the condition and build once the Application is created).")
+	flag.StringVar(&immutableTagPolicy, "immutable-tag-policy", string(controllers.ImmutableTagPolicyIgnore),
+		"Handling of builds whose output image tag has been pushed already and is immutable, checked via the Quay API: "+
+			"Ignore (no check), Block (set the ImageTagCollision condition and skip the build) or Uniquify (push with a unique tag).")
+	flag.StringVar(&tektonResultsAPIAddress, "tekton-results-api-address", "",
+		"The host:port of the Tekton Results API the image digest and log URL of completed Component builds are read from "+
+			"into the BuildResults Component condition. Empty value disables reading of the build results.")
+	flag.StringVar(&pipelineRunAPIVersion, "pipelinerun-api-version", "",
+		"Tekton API version Component build PipelineRuns are created with: v1beta1 or v1. "+
+			"The build.appstudio.openshift.io/pipelinerun-api-version annotation of a Component takes precedence. "+
+			"Empty value means the preferred version served by the cluster.")
+	flag.BoolVar(&componentDeletionWebhookEnabled, "component-deletion-webhook", false,
+		"Serve the validating webhook which rejects deletion of Components with builds in progress. "+
+			"The webhook serving certificate is read from the default controller-runtime certificate directory.")
+	flag.BoolVar(&buildAuditWebhookEnabled, "build-audit-webhook", false,
+		"Serve the validating webhook which rejects changes of BuildAuditRecord specs, so the build audit trail is append-only. "+
+			"The webhook serving certificate is read from the default controller-runtime certificate directory.")
+	flag.StringVar(&retryableBuildFailureMessages, "retryable-build-failure-messages", "",
+		"Comma separated fragments of build failure messages which mark the failures as transient, so the build is retried. "+
+			"Builds failed because of cluster disruptions or common network failures are retried always.")
+	flag.BoolVar(&applicationBuildStatusEnabled, "application-build-status", false,
+		"Maintain the build status ConfigMap of each Application with counts of its Components by the state of their latest build.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -74,7 +307,7 @@ func main() {
 		Port:                   9443,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "5483be8f.redhat.com",
+		LeaderElectionID:       leaderElectionID,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -91,23 +324,290 @@ func main() {
 		os.Exit(1)
 	}
 
+	var namespaceSelector labels.Selector
+	if requireNamespaceLabel != "" {
+		namespaceSelector, err = labels.Parse(requireNamespaceLabel)
+		if err != nil {
+			setupLog.Error(err, "invalid required namespace label", "label", requireNamespaceLabel)
+			os.Exit(1)
+		}
+	}
+
+	var maintenanceConfigMapName *types.NamespacedName
+	if maintenanceConfigMap != "" {
+		maintenanceConfigMapName, err = parseNamespacedName(maintenanceConfigMap)
+		if err != nil {
+			setupLog.Error(err, "invalid maintenance ConfigMap", "configmap", maintenanceConfigMap)
+			os.Exit(1)
+		}
+	}
+
+	var buildTiersConfigMapName *types.NamespacedName
+	if buildTiersConfigMap != "" {
+		buildTiersConfigMapName, err = parseNamespacedName(buildTiersConfigMap)
+		if err != nil {
+			setupLog.Error(err, "invalid build tiers ConfigMap", "configmap", buildTiersConfigMap)
+			os.Exit(1)
+		}
+	}
+
+	var repoSizeScalingConfigMapName *types.NamespacedName
+	if repoSizeScalingConfigMap != "" {
+		repoSizeScalingConfigMapName, err = parseNamespacedName(repoSizeScalingConfigMap)
+		if err != nil {
+			setupLog.Error(err, "invalid repository size scaling ConfigMap", "configmap", repoSizeScalingConfigMap)
+			os.Exit(1)
+		}
+	}
+
+	var clusterBuildLabelsConfigMapName *types.NamespacedName
+	if clusterBuildLabelsConfigMap != "" {
+		clusterBuildLabelsConfigMapName, err = parseNamespacedName(clusterBuildLabelsConfigMap)
+		if err != nil {
+			setupLog.Error(err, "invalid cluster build labels ConfigMap", "configmap", clusterBuildLabelsConfigMap)
+			os.Exit(1)
+		}
+	}
+
+	var buildPipelinesConfigMapName *types.NamespacedName
+	if buildPipelinesConfigMap != "" {
+		buildPipelinesConfigMapName, err = parseNamespacedName(buildPipelinesConfigMap)
+		if err != nil {
+			setupLog.Error(err, "invalid build pipelines ConfigMap", "configmap", buildPipelinesConfigMap)
+			os.Exit(1)
+		}
+	}
+
+	if buildLogRetention < 0 || buildLogRetention > controllers.MaxBuildLogRetention {
+		setupLog.Error(fmt.Errorf("build log retention must be between 0 and %s", controllers.MaxBuildLogRetention),
+			"invalid build log retention", "retention", buildLogRetention)
+		os.Exit(1)
+	}
+
+	serviceAccountSecretLinkingStrategy, err := controllers.ParseSecretLinkingStrategy(secretLinkingStrategy)
+	if err != nil {
+		setupLog.Error(err, "invalid Secret linking strategy", "strategy", secretLinkingStrategy)
+		os.Exit(1)
+	}
+
+	componentGitSecretRotationPolicy, err := controllers.ParseGitSecretRotationPolicy(gitSecretRotationPolicy)
+	if err != nil {
+		setupLog.Error(err, "invalid git Secret rotation policy", "policy", gitSecretRotationPolicy)
+		os.Exit(1)
+	}
+
+	outputImmutableTagPolicy, err := controllers.ParseImmutableTagPolicy(immutableTagPolicy)
+	if err != nil {
+		setupLog.Error(err, "invalid immutable tag policy", "policy", immutableTagPolicy)
+		os.Exit(1)
+	}
+
+	componentUnknownApplicationPolicy, err := controllers.ParseUnknownApplicationPolicy(unknownApplicationPolicy)
+	if err != nil {
+		setupLog.Error(err, "invalid unknown Application policy", "policy", unknownApplicationPolicy)
+		os.Exit(1)
+	}
+
+	buildPipelineRunAPIVersion, err := controllers.ParsePipelineRunAPIVersion(pipelineRunAPIVersion)
+	if err != nil {
+		setupLog.Error(err, "invalid PipelineRun API version", "version", pipelineRunAPIVersion)
+		os.Exit(1)
+	}
+
+	var imageRepositoryClient controllers.ImageRepositoryClient
+	if imageRepositoryAPIURL != "" {
+		quayClient := &controllers.QuayImageRepositoryClient{APIURL: strings.TrimSuffix(imageRepositoryAPIURL, "/")}
+		if imageRepositoryTokenFile != "" {
+			token, err := os.ReadFile(imageRepositoryTokenFile)
+			if err != nil {
+				setupLog.Error(err, "unable to read image repository token", "file", imageRepositoryTokenFile)
+				os.Exit(1)
+			}
+			quayClient.Token = strings.TrimSpace(string(token))
+		}
+		imageRepositoryClient = quayClient
+	}
+
+	var buildNotifier controllers.BuildNotifier
+	if buildNotificationURL != "" {
+		buildNotifier = &controllers.WebhookBuildNotifier{URL: buildNotificationURL}
+	}
+
+	var slackNotifier *controllers.SlackBuildFailureNotifier
+	if slackNotificationsEnabled {
+		slackNotifier = &controllers.SlackBuildFailureNotifier{AllowedHosts: parseHosts(slackWebhookAllowedHosts)}
+	}
+
+	var gitStatusReporter controllers.GitStatusReporter
+	if gitStatusEnabled {
+		gitStatusReporter = controllers.NewGitProviderStatusReporter(parseHosts(gitStatusInternalHosts))
+	}
+
+	var buildApprover controllers.BuildApprover
+	if buildApprovalURL != "" {
+		buildApprover = &controllers.WebhookBuildApprover{URL: buildApprovalURL}
+	}
+
+	var bundleVerifier controllers.BundleVerifier
+	if bundleVerificationKey != "" {
+		bundleVerifier = &controllers.CosignBundleVerifier{KeyPath: bundleVerificationKey}
+	}
+
 	nonCachingClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: scheme})
 	if err != nil {
 		setupLog.Error(err, "unable to initialize non cached client")
 		os.Exit(1)
 	}
 
+	coreClient, err := corev1client.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to initialize core API client")
+		os.Exit(1)
+	}
+
 	if err = (&controllers.ComponentBuildReconciler{
 		Client:           mgr.GetClient(),
 		NonCachingClient: nonCachingClient,
 		Scheme:           mgr.GetScheme(),
 		Log:              ctrl.Log.WithName("controllers").WithName("ComponentInitialBuild"),
+
+		NamespaceSelector:     namespaceSelector,
+		KeepStalePipelineRuns: keepStalePipelineRuns,
+
+		ImageRepositoryClient:        imageRepositoryClient,
+		AutoCreateImageRepository:    autoCreateImageRepository,
+		MaxConcurrentBuilds:          maxConcurrentBuilds,
+		LegacyComponentLabelName:     legacyComponentLabelName,
+		MaintenanceConfigMap:         maintenanceConfigMapName,
+		BuildsDisabled:               buildsDisabled,
+		MaxPipelineRunSize:           maxPipelineRunSize,
+		TektonNamespace:              tektonNamespace,
+		SkipExistingImageBuild:       skipExistingImageBuild,
+		BuildAuditEnabled:            buildAuditEnabled,
+		BuildSubmitter:               getServiceAccountUserName(),
+		BuildServiceConfigEnabled:    buildServiceConfigEnabled,
+		BuildTiersConfigMap:          buildTiersConfigMapName,
+		RepoSizeScalingConfigMap:     repoSizeScalingConfigMapName,
+		ClusterBuildLabels:           clusterBuildLabelsConfigMapName,
+		BuildPipelinesConfigMap:      buildPipelinesConfigMapName,
+		ImmutableTagPolicy:           outputImmutableTagPolicy,
+		BuildEnvironmentsNamespace:   buildEnvironmentsNamespace,
+		BuildLogRetention:            buildLogRetention,
+		ResubmitDeletedBuilds:        resubmitDeletedBuilds,
+		SecretLinkingStrategy:        serviceAccountSecretLinkingStrategy,
+		GitSecretRotationPolicy:      componentGitSecretRotationPolicy,
+		PruneServiceAccountSecrets:   pruneServiceAccountSecrets,
+		StrictGitSecretValidation:    strictGitSecretValidation,
+		ServiceAccountTokenClient:    &controllers.KubernetesServiceAccountTokenClient{Client: coreClient},
+		SecretCacheTTL:               secretCacheTTL,
+		PipelineRunGenerationWorkers: pipelineRunGenerationWorkers,
+		UnknownApplicationPolicy:     componentUnknownApplicationPolicy,
+		TektonResultsAPIAddress:      tektonResultsAPIAddress,
+		PipelineRunAPIVersion:        buildPipelineRunAPIVersion,
+		Recorder:                     mgr.GetEventRecorderFor("build-service"),
+		BuildApprover:                buildApprover,
+		BuildApprovalPollInterval:    buildApprovalPollInterval,
+		ApprovalWebhookAllowedHosts:  parseHosts(approvalWebhookAllowedHosts),
+		DevfileWaitTimeout:           devfileWaitTimeout,
+		DevfileRecheckInterval:       devfileRecheckInterval,
+		ReconcileAllOnStartup:        reconcileAllOnStartup,
+		BundleVerifier:               bundleVerifier,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ComponentInitialBuild")
 		os.Exit(1)
 	}
+	if err = (&controllers.BuildPipelineRunReconciler{
+		Client:           mgr.GetClient(),
+		NonCachingClient: nonCachingClient,
+		Scheme:           mgr.GetScheme(),
+		Log:              ctrl.Log.WithName("controllers").WithName("BuildPipelineRun"),
+
+		BuildSummaryEnabled: buildSummaryEnabled,
+		Notifier:            buildNotifier,
+		GitStatusReporter:   gitStatusReporter,
+		GitStatusSecretName: gitStatusSecretName,
+
+		RetryableFailureMessages: parseRetryableFailureMessages(retryableBuildFailureMessages),
+		SlackNotifier:            slackNotifier,
+		BuildProvenanceEnabled:   buildProvenanceEnabled,
+		SignatureVerificationKey: signatureVerificationKey,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BuildPipelineRun")
+		os.Exit(1)
+	}
+	if applicationBuildStatusEnabled {
+		if err = (&controllers.ApplicationBuildStatusReconciler{
+			Client: mgr.GetClient(),
+			Scheme: mgr.GetScheme(),
+			Log:    ctrl.Log.WithName("controllers").WithName("ApplicationBuildStatus"),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ApplicationBuildStatus")
+			os.Exit(1)
+		}
+	}
+	if buildAuditEnabled {
+		if err = (&controllers.BuildAuditRecordReconciler{
+			Client: mgr.GetClient(),
+			Log:    ctrl.Log.WithName("controllers").WithName("BuildAuditRecord"),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "BuildAuditRecord")
+			os.Exit(1)
+		}
+	}
+	if buildSLOConfigMap != "" {
+		buildSLOConfigMapName, err := parseNamespacedName(buildSLOConfigMap)
+		if err != nil {
+			setupLog.Error(err, "invalid build SLO ConfigMap", "configmap", buildSLOConfigMap)
+			os.Exit(1)
+		}
+		if err = (&controllers.BuildSLOReconciler{
+			Client:    mgr.GetClient(),
+			Scheme:    mgr.GetScheme(),
+			Log:       ctrl.Log.WithName("controllers").WithName("BuildSLO"),
+			ConfigMap: *buildSLOConfigMapName,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "BuildSLO")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
+	if buildStatusAddr != "" {
+		if err := mgr.Add(&controllers.BuildStatusServer{
+			Client:      mgr.GetClient(),
+			Log:         ctrl.Log.WithName("BuildStatusServer"),
+			BindAddress: buildStatusAddr,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up build status server")
+			os.Exit(1)
+		}
+	}
+
+	if componentDeletionWebhookEnabled {
+		mgr.GetWebhookServer().Register(controllers.ComponentDeletionWebhookPath, &webhook.Admission{
+			Handler: &controllers.ComponentDeletionValidator{Client: mgr.GetClient()},
+		})
+	}
+	if buildAuditWebhookEnabled {
+		mgr.GetWebhookServer().Register(controllers.BuildAuditRecordWebhookPath, &webhook.Admission{
+			Handler: &controllers.BuildAuditRecordValidator{},
+		})
+	}
+
+	if pipelineRunRetentionEnabled {
+		if err := mgr.Add(&controllers.PipelineRunRetentionCleaner{
+			Client:    mgr.GetClient(),
+			Log:       ctrl.Log.WithName("PipelineRunRetentionCleaner"),
+			Retention: pipelineRunRetention,
+			Interval:  controllers.DefaultPipelineRunRetentionInterval,
+
+			KeepWorkspacePVCs: pipelineRunRetentionKeepPVCs,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up PipelineRun retention cleanup")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -123,3 +623,45 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// parseNamespacedName parses namespace/name formatted value of a flag.
+func parseNamespacedName(value string) (*types.NamespacedName, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("expected namespace/name format")
+	}
+	return &types.NamespacedName{Namespace: parts[0], Name: parts[1]}, nil
+}
+
+// getServiceAccountUserName returns the user name of the controller service account
+// provided via SERVICE_ACCOUNT_NAME and POD_NAMESPACE environment variables, or empty string if they are not set.
+func getServiceAccountUserName() string {
+	serviceAccount := os.Getenv("SERVICE_ACCOUNT_NAME")
+	namespace := os.Getenv("POD_NAMESPACE")
+	if serviceAccount == "" || namespace == "" {
+		return ""
+	}
+	return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, serviceAccount)
+}
+
+// parseRetryableFailureMessages returns lowercase fragments of the comma separated build failure messages.
+func parseRetryableFailureMessages(value string) []string {
+	var messages []string
+	for _, message := range strings.Split(value, ",") {
+		if message = strings.ToLower(strings.TrimSpace(message)); message != "" {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// parseHosts returns lowercase hosts of the comma separated list.
+func parseHosts(value string) []string {
+	var hosts []string
+	for _, host := range strings.Split(value, ",") {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tektonresults implements a minimal client of the Tekton Results gRPC API.
+// Only the calls needed by the build service are implemented, their messages are encoded
+// directly so the API protobuf definitions are not needed.
+package tektonresults
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	// DefaultTokenFile is the token of the controller Service Account the Results API is called with
+	DefaultTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	// DefaultServiceCAFile is the CA bundle of in-cluster services, it is trusted in addition to the system CAs if present
+	DefaultServiceCAFile = "/var/run/secrets/kubernetes.io/serviceaccount/service-ca.crt"
+
+	getRecordMethod = "/tekton.results.v1alpha2.Results/GetRecord"
+	logsPathPrefix  = "/apis/results.tekton.dev/v1alpha2/parents/"
+)
+
+// Record is a stored object, e.g. a PipelineRun, of a Tekton Results result.
+type Record struct {
+	// Name of the record in <namespace>/results/<result>/records/<record> format
+	Name string
+	ID   string
+	// Type of the stored object, e.g. tekton.dev/v1beta1.PipelineRun
+	Type string
+	// Data is the stored object as JSON
+	Data []byte
+}
+
+// Client calls the Tekton Results API.
+type Client struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewClient returns a client which calls the Results API over the given connection.
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{conn: conn}
+}
+
+// Dial connects to the Results API at the given host:port address over TLS.
+// Each call is authenticated with the token read from the token file.
+func Dial(address string, tokenFile string) (*Client, error) {
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		return nil, err
+	}
+	if serviceCA, err := os.ReadFile(DefaultServiceCAFile); err == nil {
+		rootCAs.AppendCertsFromPEM(serviceCA)
+	}
+
+	conn, err := grpc.Dial(address,
+		grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12})),
+		grpc.WithPerRPCCredentials(tokenFileCredentials(tokenFile)))
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn), nil
+}
+
+// GetRecord returns the record with the given name.
+func (c *Client) GetRecord(ctx context.Context, name string) (*Record, error) {
+	record := &Record{}
+	if err := c.conn.Invoke(ctx, getRecordMethod, &getRecordRequest{Name: name}, record, grpc.ForceCodec(codec{})); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// LogURL returns the URL the log with the given name, in <namespace>/results/<result>/logs/<log> format,
+// is served at by the REST gateway of the Results API at the given address.
+func LogURL(address string, logName string) string {
+	return "https://" + address + logsPathPrefix + strings.TrimPrefix(logName, "/")
+}
+
+// tokenFileCredentials authenticates calls with a bearer token read from the file on each call,
+// so rotated Service Account tokens are picked up.
+type tokenFileCredentials string
+
+func (c tokenFileCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	token, err := os.ReadFile(string(c))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"authorization": "Bearer " + strings.TrimSpace(string(token))}, nil
+}
+
+func (c tokenFileCredentials) RequireTransportSecurity() bool {
+	return true
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tektonresults
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startTestServer starts a Results API server which returns the records with the requested names.
+func startTestServer(t *testing.T, records map[string]Record) *Client {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.ForceServerCodec(codec{}), grpc.UnknownServiceHandler(func(srv interface{}, stream grpc.ServerStream) error {
+		if method, _ := grpc.MethodFromServerStream(stream); method != getRecordMethod {
+			return status.Errorf(codes.Unimplemented, "unknown method %s", method)
+		}
+		request := &getRecordRequest{}
+		if err := stream.RecvMsg(request); err != nil {
+			return err
+		}
+		record, found := records[request.Name]
+		if !found {
+			return status.Errorf(codes.NotFound, "record %s not found", request.Name)
+		}
+		return stream.SendMsg(&record)
+	}))
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			return listener.Dial()
+		}))
+	if err != nil {
+		t.Fatalf("failed to connect to the test server: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+	})
+	return NewClient(conn)
+}
+
+func TestGetRecord(t *testing.T) {
+	record := Record{
+		Name: "my-namespace/results/1234/records/5678",
+		ID:   "5678",
+		Type: "tekton.dev/v1beta1.PipelineRun",
+		Data: []byte(`{"kind":"PipelineRun"}`),
+	}
+	client := startTestServer(t, map[string]Record{record.Name: record})
+
+	got, err := client.GetRecord(context.TODO(), record.Name)
+	if err != nil {
+		t.Fatalf("GetRecord() error = %v", err)
+	}
+	if !reflect.DeepEqual(*got, record) {
+		t.Errorf("GetRecord() = %v, want %v", *got, record)
+	}
+
+	if _, err := client.GetRecord(context.TODO(), "my-namespace/results/1234/records/0000"); status.Code(err) != codes.NotFound {
+		t.Errorf("GetRecord() error = %v, want %v", err, codes.NotFound)
+	}
+}
+
+func TestRecordUnmarshalSkipsUnknownFields(t *testing.T) {
+	record := Record{Name: "my-namespace/results/1234/records/5678", Type: "tekton.dev/v1beta1.PipelineRun", Data: []byte("{}")}
+	// Append an etag string field and a varint field the client does not know
+	data := append(record.marshal(), 0x22, 0x01, 'x', 0x58, 0x01)
+
+	got := Record{}
+	if err := got.unmarshal(data); err != nil {
+		t.Fatalf("unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, record) {
+		t.Errorf("unmarshal() = %v, want %v", got, record)
+	}
+}
+
+func TestLogURL(t *testing.T) {
+	got := LogURL("tekton-results-api-service.tekton-pipelines.svc:8080", "my-namespace/results/1234/logs/5678")
+	want := "https://tekton-results-api-service.tekton-pipelines.svc:8080/apis/results.tekton.dev/v1alpha2/parents/my-namespace/results/1234/logs/5678"
+	if got != want {
+		t.Errorf("LogURL() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tektonresults
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the tekton.results.v1alpha2 messages
+const (
+	getRecordRequestNameField = 1
+
+	recordNameField = 1
+	recordIDField   = 2
+	recordDataField = 3
+
+	anyTypeField  = 1
+	anyValueField = 2
+)
+
+// message is a Results API message encoded in the protobuf wire format.
+type message interface {
+	marshal() []byte
+	unmarshal(data []byte) error
+}
+
+// codec encodes the Results API messages, it is used instead of the default codec which requires generated messages.
+type codec struct{}
+
+func (codec) Name() string {
+	return "proto"
+}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(message)
+	if !ok {
+		return nil, fmt.Errorf("unsupported message type %T", v)
+	}
+	return m.marshal(), nil
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(message)
+	if !ok {
+		return fmt.Errorf("unsupported message type %T", v)
+	}
+	return m.unmarshal(data)
+}
+
+type getRecordRequest struct {
+	Name string
+}
+
+func (r *getRecordRequest) marshal() []byte {
+	data := protowire.AppendTag(nil, getRecordRequestNameField, protowire.BytesType)
+	return protowire.AppendString(data, r.Name)
+}
+
+func (r *getRecordRequest) unmarshal(data []byte) error {
+	return unmarshalFields(data, func(number protowire.Number, value []byte) error {
+		if number == getRecordRequestNameField {
+			r.Name = string(value)
+		}
+		return nil
+	})
+}
+
+func (r *Record) marshal() []byte {
+	var value []byte
+	value = protowire.AppendTag(value, anyTypeField, protowire.BytesType)
+	value = protowire.AppendString(value, r.Type)
+	value = protowire.AppendTag(value, anyValueField, protowire.BytesType)
+	value = protowire.AppendBytes(value, r.Data)
+
+	var data []byte
+	data = protowire.AppendTag(data, recordNameField, protowire.BytesType)
+	data = protowire.AppendString(data, r.Name)
+	data = protowire.AppendTag(data, recordIDField, protowire.BytesType)
+	data = protowire.AppendString(data, r.ID)
+	data = protowire.AppendTag(data, recordDataField, protowire.BytesType)
+	return protowire.AppendBytes(data, value)
+}
+
+func (r *Record) unmarshal(data []byte) error {
+	return unmarshalFields(data, func(number protowire.Number, value []byte) error {
+		switch number {
+		case recordNameField:
+			r.Name = string(value)
+		case recordIDField:
+			r.ID = string(value)
+		case recordDataField:
+			return unmarshalFields(value, func(number protowire.Number, value []byte) error {
+				switch number {
+				case anyTypeField:
+					r.Type = string(value)
+				case anyValueField:
+					r.Data = append([]byte(nil), value...)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+}
+
+// unmarshalFields calls the handler for each length-delimited field of the message, other fields are skipped.
+func unmarshalFields(data []byte, handle func(number protowire.Number, value []byte) error) error {
+	for len(data) > 0 {
+		number, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		if wireType != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(number, wireType, data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			data = data[n:]
+			continue
+		}
+
+		value, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if err := handle(number, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}